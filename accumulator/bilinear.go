@@ -0,0 +1,201 @@
+package accumulator
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// Bilinear accumulator, after Nguyen, over the bn256 pairing. Elements are
+// hashed to scalars y_i and, for the manager's secret s, the accumulator is
+// V = prod(y_i + s) * G1. The membership witness of x is
+// w = prod(y_i + s, y_i != y_x) * G1, checked with
+// e(w, y_x * G2 + s * G2) = e(V, G2).
+//
+// Unlike the RSA accumulator there is no modulus to trust, but the manager
+// knows s, which lets it add, delete and compute witnesses in constant
+// time. Witnesses are a single G1 point, and holders keep them up to date
+// from the published updates alone
+
+// BilinearState is the public state of a bilinear accumulator
+type BilinearState struct {
+	// PublicKey is s * G2
+	PublicKey *bn256.G2
+	Value     *bn256.G1
+}
+
+// BilinearAccumulator is a bilinear accumulator held by its manager
+type BilinearAccumulator struct {
+	BilinearState
+	s        *big.Int
+	elements map[string]*big.Int
+}
+
+// BilinearUpdate is published by the manager for every change of the set,
+// for holders to update their witnesses
+type BilinearUpdate struct {
+	Added   bool
+	Element []byte
+	// Value is the accumulator after the change
+	Value *bn256.G1
+}
+
+// NewBilinearAccumulator returns an empty accumulator with a secret drawn
+// from r, the default source if nil
+func NewBilinearAccumulator(r io.Reader) (*BilinearAccumulator, error) {
+	s, err := rand.Int(rng.Or(r), bn256.Order)
+	if err != nil {
+		return nil, err
+	}
+	if s.Sign() == 0 {
+		return nil, errors.New("invalid accumulator secret")
+	}
+
+	return &BilinearAccumulator{
+		BilinearState: BilinearState{
+			PublicKey: new(bn256.G2).ScalarBaseMult(s),
+			Value:     new(bn256.G1).ScalarBaseMult(big.NewInt(1)),
+		},
+		s:        s,
+		elements: make(map[string]*big.Int),
+	}, nil
+}
+
+// HashToScalar maps an element to a bn256 scalar
+func HashToScalar(x []byte) *big.Int {
+	y := new(big.Int).SetBytes(hash.Sha3512WithDomain("dusk.accumulator.bilinear", x))
+	return y.Mod(y, bn256.Order)
+}
+
+// Len returns the number of accumulated elements
+func (acc *BilinearAccumulator) Len() int {
+	return len(acc.elements)
+}
+
+// shift returns y + s, which must be invertible
+func (acc *BilinearAccumulator) shift(y *big.Int) (*big.Int, error) {
+	t := new(big.Int).Add(y, acc.s)
+	t.Mod(t, bn256.Order)
+	if t.Sign() == 0 {
+		return nil, errors.New("element cannot be accumulated")
+	}
+	return t, nil
+}
+
+// Add accumulates x: V' = (y_x + s) * V
+func (acc *BilinearAccumulator) Add(x []byte) (*BilinearUpdate, error) {
+	if _, ok := acc.elements[string(x)]; ok {
+		return nil, errors.New("element already accumulated")
+	}
+	y := HashToScalar(x)
+	t, err := acc.shift(y)
+	if err != nil {
+		return nil, err
+	}
+
+	acc.elements[string(x)] = y
+	acc.Value = new(bn256.G1).ScalarMult(acc.Value, t)
+	return &BilinearUpdate{Added: true, Element: append([]byte{}, x...), Value: acc.Value}, nil
+}
+
+// Delete removes x: V' = V / (y_x + s)
+func (acc *BilinearAccumulator) Delete(x []byte) (*BilinearUpdate, error) {
+	w, err := acc.MembershipWitness(x)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(acc.elements, string(x))
+	acc.Value = w
+	return &BilinearUpdate{Element: append([]byte{}, x...), Value: acc.Value}, nil
+}
+
+// MembershipWitness returns the witness of an accumulated element,
+// V / (y_x + s)
+func (acc *BilinearAccumulator) MembershipWitness(x []byte) (*bn256.G1, error) {
+	y, ok := acc.elements[string(x)]
+	if !ok {
+		return nil, errors.New("element is not accumulated")
+	}
+	t, err := acc.shift(y)
+	if err != nil {
+		return nil, err
+	}
+	return new(bn256.G1).ScalarMult(acc.Value, t.ModInverse(t, bn256.Order)), nil
+}
+
+// VerifyMembership checks that e(w, y_x * G2 + s * G2) = e(V, G2)
+func (s *BilinearState) VerifyMembership(x []byte, w *bn256.G1) bool {
+	if w == nil || s.Value == nil || s.PublicKey == nil {
+		return false
+	}
+
+	q := new(bn256.G2).ScalarBaseMult(HashToScalar(x))
+	q.Add(q, s.PublicKey)
+	g2 := new(bn256.G2).ScalarBaseMult(big.NewInt(1))
+	return bytes.Equal(bn256.Pair(w, q).Marshal(), bn256.Pair(s.Value, g2).Marshal())
+}
+
+// UpdateWitness brings the witness w of x, valid for the accumulator value
+// prev, up to date with a sequence of updates. Each update is a linear step:
+//
+//	add y:    w' = (y - y_x) * w + V
+//	delete y: w' = (w - V') / (y - y_x)
+//
+// V being the value before the change and V' the one after. The steps are
+// unrolled into a single multi-scalar multiplication, so that catching up
+// with a whole block of updates costs about as much as a single one
+func UpdateWitness(w *bn256.G1, x []byte, prev *bn256.G1, updates []*BilinearUpdate) (*bn256.G1, error) {
+	if w == nil || prev == nil {
+		return nil, errors.New("invalid witness")
+	}
+	yx := HashToScalar(x)
+
+	// w_k = a_k * w_{k-1} + b_k * P_k
+	a := make([]*big.Int, len(updates))
+	b := make([]*big.Int, len(updates))
+	points := make([]*bn256.G1, len(updates))
+	before := prev
+	for k, u := range updates {
+		if u == nil || u.Value == nil {
+			return nil, errors.New("invalid update")
+		}
+		d := HashToScalar(u.Element)
+		d.Sub(d, yx)
+		d.Mod(d, bn256.Order)
+		if d.Sign() == 0 {
+			return nil, errors.New("update concerns the witnessed element")
+		}
+
+		if u.Added {
+			a[k] = d
+			b[k] = big.NewInt(1)
+			points[k] = before
+		} else {
+			inv := new(big.Int).ModInverse(d, bn256.Order)
+			a[k] = inv
+			b[k] = new(big.Int).Sub(bn256.Order, inv)
+			points[k] = u.Value
+		}
+		before = u.Value
+	}
+
+	// w_n = prod(a) * w_0 + sum(b_k * prod(a_j, j > k) * P_k)
+	res := new(bn256.G1).ScalarBaseMult(new(big.Int))
+	scale := big.NewInt(1)
+	for k := len(updates) - 1; k >= 0; k-- {
+		c := new(big.Int).Mul(b[k], scale)
+		c.Mod(c, bn256.Order)
+		res.Add(res, new(bn256.G1).ScalarMult(points[k], c))
+		scale.Mul(scale, a[k])
+		scale.Mod(scale, bn256.Order)
+	}
+	res.Add(res, new(bn256.G1).ScalarMult(w, scale))
+	return res, nil
+}
@@ -0,0 +1,94 @@
+package accumulator
+
+import (
+	"testing"
+
+	"github.com/dusk-network/bn256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBilinearMembership(t *testing.T) {
+	acc, err := NewBilinearAccumulator(nil)
+	require.Nil(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := acc.Add(utxo(i))
+		require.Nil(t, err)
+	}
+	_, err = acc.Add(utxo(0))
+	assert.NotNil(t, err)
+	assert.Equal(t, 5, acc.Len())
+
+	for i := 0; i < 5; i++ {
+		w, err := acc.MembershipWitness(utxo(i))
+		require.Nil(t, err)
+		assert.True(t, acc.VerifyMembership(utxo(i), w))
+		assert.False(t, acc.VerifyMembership(utxo(i+1), w))
+	}
+
+	_, err = acc.MembershipWitness(utxo(5))
+	assert.NotNil(t, err)
+
+	// deleting an element invalidates its witness
+	w, err := acc.MembershipWitness(utxo(2))
+	require.Nil(t, err)
+	_, err = acc.Delete(utxo(2))
+	require.Nil(t, err)
+	assert.False(t, acc.VerifyMembership(utxo(2), w))
+	_, err = acc.Delete(utxo(2))
+	assert.NotNil(t, err)
+}
+
+func TestBilinearWitnessUpdates(t *testing.T) {
+	acc, err := NewBilinearAccumulator(nil)
+	require.Nil(t, err)
+	for i := 0; i < 4; i++ {
+		_, err := acc.Add(utxo(i))
+		require.Nil(t, err)
+	}
+
+	w, err := acc.MembershipWitness(utxo(1))
+	require.Nil(t, err)
+	prev := new(bn256.G1).Set(acc.Value)
+
+	// a block of additions and deletions
+	var updates []*BilinearUpdate
+	for _, op := range []struct {
+		add bool
+		i   int
+	}{{true, 4}, {false, 0}, {true, 5}, {false, 3}, {true, 6}, {false, 4}} {
+		var u *BilinearUpdate
+		if op.add {
+			u, err = acc.Add(utxo(op.i))
+		} else {
+			u, err = acc.Delete(utxo(op.i))
+		}
+		require.Nil(t, err)
+		updates = append(updates, u)
+	}
+	assert.False(t, acc.VerifyMembership(utxo(1), w))
+
+	// one batched update
+	batched, err := UpdateWitness(w, utxo(1), prev, updates)
+	require.Nil(t, err)
+	assert.True(t, acc.VerifyMembership(utxo(1), batched))
+	expected, err := acc.MembershipWitness(utxo(1))
+	require.Nil(t, err)
+	assert.Equal(t, expected.Marshal(), batched.Marshal())
+
+	// or the same updates one at a time
+	step := w
+	before := prev
+	for _, u := range updates {
+		step, err = UpdateWitness(step, utxo(1), before, []*BilinearUpdate{u})
+		require.Nil(t, err)
+		before = u.Value
+	}
+	assert.Equal(t, expected.Marshal(), step.Marshal())
+
+	// the witnessed element cannot be part of the updates
+	u, err := acc.Delete(utxo(1))
+	require.Nil(t, err)
+	_, err = UpdateWitness(batched, utxo(1), before, []*BilinearUpdate{u})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,285 @@
+// Package accumulator implements cryptographic accumulators: constant size
+// commitments to a set, with short proofs that an element is or is not in
+// the set. They let stateless clients check UTXOs against a single value
+// instead of storing the whole set
+package accumulator
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/vdf"
+)
+
+// RSA accumulator, in a group of unknown order. Elements are hashed to
+// primes p_i and the accumulator is A = g^prod(p_i). The membership witness
+// of x is w = g^prod(p_i, p_i != p_x), i.e. w^p_x = A.
+//
+// Without the order of the group, roots cannot be computed, so deleting an
+// element needs its witness or the whole set. The RSA-2048 modulus is used,
+// so that there is no trapdoor to trust anybody with
+
+// rsaGenerator is the base g of the accumulator
+const rsaGenerator = 3
+
+// primeBits is the size of the primes elements are hashed to
+const primeBits = 256
+
+// RSAState is the public state of an RSA accumulator, all that a stateless
+// client needs to verify witnesses and to keep its own witnesses up to date
+type RSAState struct {
+	N     *big.Int
+	Value *big.Int
+}
+
+// RSAAccumulator is an RSA accumulator together with the accumulated set,
+// as maintained by a full node
+type RSAAccumulator struct {
+	RSAState
+	primes map[string]*big.Int
+}
+
+// NonMembershipWitness proves that an element is not accumulated: with u the
+// product of the accumulated primes, a*u + b*p_x = 1 and D = g^b, so that
+// A^a * D^p_x = g
+type NonMembershipWitness struct {
+	A *big.Int
+	D *big.Int
+}
+
+// NewRSAAccumulator returns an empty accumulator modulo RSA-2048
+func NewRSAAccumulator() *RSAAccumulator {
+	return &RSAAccumulator{
+		RSAState: RSAState{
+			N:     new(big.Int).Set(vdf.RSA2048.N),
+			Value: big.NewInt(rsaGenerator),
+		},
+		primes: make(map[string]*big.Int),
+	}
+}
+
+// HashToPrime maps an element to a 256 bit prime
+func HashToPrime(x []byte) *big.Int {
+	var ctr [8]byte
+	p := new(big.Int)
+	for i := uint64(0); ; i++ {
+		binary.BigEndian.PutUint64(ctr[:], i)
+		h := hash.Sha3256WithDomain("dusk.accumulator.prime", x, ctr[:])
+		h[0] |= 0x80
+		p.SetBytes(h)
+		if p.ProbablyPrime(20) {
+			return p
+		}
+	}
+}
+
+// Len returns the number of accumulated elements
+func (acc *RSAAccumulator) Len() int {
+	return len(acc.primes)
+}
+
+// Add accumulates x: A' = A^p_x
+func (acc *RSAAccumulator) Add(x []byte) error {
+	if _, ok := acc.primes[string(x)]; ok {
+		return errors.New("element already accumulated")
+	}
+	p := HashToPrime(x)
+	acc.primes[string(x)] = p
+	acc.Value.Exp(acc.Value, p, acc.N)
+	return nil
+}
+
+// Delete removes x: A' = w_x
+func (acc *RSAAccumulator) Delete(x []byte) error {
+	w, err := acc.MembershipWitness(x)
+	if err != nil {
+		return err
+	}
+	delete(acc.primes, string(x))
+	acc.Value = w
+	return nil
+}
+
+// product returns the product of the accumulated primes, except for the
+// excluded elements
+func (acc *RSAAccumulator) product(exclude map[string]bool) *big.Int {
+	u := big.NewInt(1)
+	for x, p := range acc.primes {
+		if !exclude[x] {
+			u.Mul(u, p)
+		}
+	}
+	return u
+}
+
+// MembershipWitness returns the witness of an accumulated element
+func (acc *RSAAccumulator) MembershipWitness(x []byte) (*big.Int, error) {
+	return acc.BatchMembershipWitness([][]byte{x})
+}
+
+// BatchMembershipWitness returns a single witness for several accumulated
+// elements: w = g^prod(p_i, x_i not in xs), so that w^prod(p_x) = A
+func (acc *RSAAccumulator) BatchMembershipWitness(xs [][]byte) (*big.Int, error) {
+	exclude := make(map[string]bool, len(xs))
+	for _, x := range xs {
+		if _, ok := acc.primes[string(x)]; !ok {
+			return nil, errors.New("element is not accumulated")
+		}
+		if exclude[string(x)] {
+			return nil, errors.New("duplicate element")
+		}
+		exclude[string(x)] = true
+	}
+
+	u := acc.product(exclude)
+	return new(big.Int).Exp(big.NewInt(rsaGenerator), u, acc.N), nil
+}
+
+// NonMembershipWitness returns the witness that x is not accumulated
+func (acc *RSAAccumulator) NonMembershipWitness(x []byte) (*NonMembershipWitness, error) {
+	if _, ok := acc.primes[string(x)]; ok {
+		return nil, errors.New("element is accumulated")
+	}
+
+	px := HashToPrime(x)
+	u := acc.product(nil)
+
+	// a = u^-1 mod p_x keeps a short, and b = (1 - a*u) / p_x
+	a := new(big.Int).ModInverse(new(big.Int).Mod(u, px), px)
+	if a == nil {
+		return nil, errors.New("element prime divides the accumulated product")
+	}
+	b := new(big.Int).Mul(a, u)
+	b.Sub(big.NewInt(1), b)
+	b.Quo(b, px)
+
+	return &NonMembershipWitness{
+		A: a,
+		D: expSigned(big.NewInt(rsaGenerator), b, acc.N),
+	}, nil
+}
+
+// VerifyMembership checks that w^p_x = A
+func (s *RSAState) VerifyMembership(x []byte, w *big.Int) bool {
+	return s.VerifyBatchMembership([][]byte{x}, w)
+}
+
+// VerifyBatchMembership checks that w^prod(p_x) = A
+func (s *RSAState) VerifyBatchMembership(xs [][]byte, w *big.Int) bool {
+	if len(xs) == 0 || !s.contains(w) || !distinct(xs) {
+		return false
+	}
+
+	e := big.NewInt(1)
+	for _, x := range xs {
+		e.Mul(e, HashToPrime(x))
+	}
+	return new(big.Int).Exp(w, e, s.N).Cmp(s.Value) == 0
+}
+
+// VerifyNonMembership checks that A^a * D^p_x = g
+func (s *RSAState) VerifyNonMembership(x []byte, w *NonMembershipWitness) bool {
+	if w == nil || w.A == nil || w.A.Sign() <= 0 || !s.contains(w.D) {
+		return false
+	}
+
+	px := HashToPrime(x)
+	if w.A.Cmp(px) >= 0 {
+		return false
+	}
+
+	lhs := new(big.Int).Exp(s.Value, w.A, s.N)
+	lhs.Mul(lhs, new(big.Int).Exp(w.D, px, s.N))
+	lhs.Mod(lhs, s.N)
+	return lhs.Cmp(big.NewInt(rsaGenerator)) == 0
+}
+
+// UpdateOnAdd returns the witness of x after y was accumulated: w' = w^p_y
+func (s *RSAState) UpdateOnAdd(w *big.Int, y []byte) *big.Int {
+	return new(big.Int).Exp(w, HashToPrime(y), s.N)
+}
+
+// UpdateOnDelete returns the witness of x after y was deleted, s being the
+// state after the deletion. With a*p_x + b*p_y = 1, w' = w^b * A'^a
+func (s *RSAState) UpdateOnDelete(w *big.Int, x, y []byte) (*big.Int, error) {
+	if !s.contains(w) {
+		return nil, errors.New("invalid witness")
+	}
+	a, b, err := bezout(HashToPrime(x), HashToPrime(y))
+	if err != nil {
+		return nil, err
+	}
+
+	res := expSigned(w, b, s.N)
+	res.Mul(res, expSigned(s.Value, a, s.N))
+	return res.Mod(res, s.N), nil
+}
+
+// AggregateWitnesses combines the membership witnesses of distinct elements
+// into one batch witness. With a*p_1 + b*p_2 = 1, the witness of both
+// elements is w_1^b * w_2^a
+func (s *RSAState) AggregateWitnesses(xs [][]byte, ws []*big.Int) (*big.Int, error) {
+	if len(xs) == 0 || len(xs) != len(ws) {
+		return nil, errors.New("invalid number of witnesses")
+	}
+	for _, w := range ws {
+		if !s.contains(w) {
+			return nil, errors.New("invalid witness")
+		}
+	}
+
+	w := new(big.Int).Set(ws[0])
+	e := HashToPrime(xs[0])
+	for i := 1; i < len(xs); i++ {
+		p := HashToPrime(xs[i])
+		a, b, err := bezout(e, p)
+		if err != nil {
+			return nil, err
+		}
+
+		w = expSigned(w, b, s.N)
+		w.Mul(w, expSigned(ws[i], a, s.N))
+		w.Mod(w, s.N)
+		e.Mul(e, p)
+	}
+	return w, nil
+}
+
+func (s *RSAState) contains(x *big.Int) bool {
+	return x != nil && x.Sign() > 0 && x.Cmp(s.N) < 0
+}
+
+// bezout returns a and b such that a*x + b*y = 1
+func bezout(x, y *big.Int) (*big.Int, *big.Int, error) {
+	a, b := new(big.Int), new(big.Int)
+	if new(big.Int).GCD(a, b, x, y).Cmp(big.NewInt(1)) != 0 {
+		return nil, nil, errors.New("elements are not distinct")
+	}
+	return a, b, nil
+}
+
+// expSigned returns x^e mod n for a possibly negative e. It returns zero,
+// which no verification accepts, if x is not invertible
+func expSigned(x, e, n *big.Int) *big.Int {
+	if e.Sign() >= 0 {
+		return new(big.Int).Exp(x, e, n)
+	}
+	inv := new(big.Int).ModInverse(x, n)
+	if inv == nil {
+		return new(big.Int)
+	}
+	return inv.Exp(inv, new(big.Int).Neg(e), n)
+}
+
+func distinct(xs [][]byte) bool {
+	seen := make(map[string]bool, len(xs))
+	for _, x := range xs {
+		if seen[string(x)] {
+			return false
+		}
+		seen[string(x)] = true
+	}
+	return true
+}
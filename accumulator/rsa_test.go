@@ -0,0 +1,122 @@
+package accumulator
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func utxo(i int) []byte {
+	return []byte(fmt.Sprintf("utxo %d", i))
+}
+
+func TestRSAMembership(t *testing.T) {
+	acc := NewRSAAccumulator()
+	for i := 0; i < 5; i++ {
+		require.Nil(t, acc.Add(utxo(i)))
+	}
+	assert.NotNil(t, acc.Add(utxo(0)))
+	assert.Equal(t, 5, acc.Len())
+
+	for i := 0; i < 5; i++ {
+		w, err := acc.MembershipWitness(utxo(i))
+		require.Nil(t, err)
+		assert.True(t, acc.VerifyMembership(utxo(i), w))
+		assert.False(t, acc.VerifyMembership(utxo(i+1), w))
+	}
+
+	_, err := acc.MembershipWitness(utxo(5))
+	assert.NotNil(t, err)
+
+	// deleting an element invalidates its witness
+	w, err := acc.MembershipWitness(utxo(2))
+	require.Nil(t, err)
+	require.Nil(t, acc.Delete(utxo(2)))
+	assert.False(t, acc.VerifyMembership(utxo(2), w))
+	assert.NotNil(t, acc.Delete(utxo(2)))
+}
+
+func TestRSANonMembership(t *testing.T) {
+	acc := NewRSAAccumulator()
+
+	// the empty set contains nothing
+	w, err := acc.NonMembershipWitness(utxo(0))
+	require.Nil(t, err)
+	assert.True(t, acc.VerifyNonMembership(utxo(0), w))
+
+	for i := 0; i < 4; i++ {
+		require.Nil(t, acc.Add(utxo(i)))
+	}
+
+	w, err = acc.NonMembershipWitness(utxo(10))
+	require.Nil(t, err)
+	assert.True(t, acc.VerifyNonMembership(utxo(10), w))
+	assert.False(t, acc.VerifyNonMembership(utxo(11), w))
+
+	_, err = acc.NonMembershipWitness(utxo(1))
+	assert.NotNil(t, err)
+
+	// once added, the old witness no longer verifies
+	require.Nil(t, acc.Add(utxo(10)))
+	assert.False(t, acc.VerifyNonMembership(utxo(10), w))
+}
+
+func TestRSAWitnessUpdates(t *testing.T) {
+	acc := NewRSAAccumulator()
+	for i := 0; i < 3; i++ {
+		require.Nil(t, acc.Add(utxo(i)))
+	}
+
+	// a stateless client tracks the witness of utxo 0
+	w, err := acc.MembershipWitness(utxo(0))
+	require.Nil(t, err)
+
+	require.Nil(t, acc.Add(utxo(3)))
+	w = acc.UpdateOnAdd(w, utxo(3))
+	assert.True(t, acc.VerifyMembership(utxo(0), w))
+
+	require.Nil(t, acc.Delete(utxo(1)))
+	w, err = acc.UpdateOnDelete(w, utxo(0), utxo(1))
+	require.Nil(t, err)
+	assert.True(t, acc.VerifyMembership(utxo(0), w))
+
+	expected, err := acc.MembershipWitness(utxo(0))
+	require.Nil(t, err)
+	assert.Equal(t, 0, expected.Cmp(w))
+
+	_, err = acc.UpdateOnDelete(w, utxo(0), utxo(0))
+	assert.NotNil(t, err)
+}
+
+func TestRSABatchMembership(t *testing.T) {
+	acc := NewRSAAccumulator()
+	for i := 0; i < 6; i++ {
+		require.Nil(t, acc.Add(utxo(i)))
+	}
+
+	xs := [][]byte{utxo(1), utxo(3), utxo(4)}
+	batch, err := acc.BatchMembershipWitness(xs)
+	require.Nil(t, err)
+	assert.True(t, acc.VerifyBatchMembership(xs, batch))
+	assert.False(t, acc.VerifyBatchMembership(xs[:2], batch))
+	assert.False(t, acc.VerifyBatchMembership([][]byte{utxo(1), utxo(3), utxo(5)}, batch))
+	assert.False(t, acc.VerifyBatchMembership([][]byte{utxo(1), utxo(1)}, batch))
+
+	// individual witnesses aggregate into the same batch witness
+	ws := make([]*big.Int, len(xs))
+	for i, x := range xs {
+		ws[i], err = acc.MembershipWitness(x)
+		require.Nil(t, err)
+	}
+	agg, err := acc.AggregateWitnesses(xs, ws)
+	require.Nil(t, err)
+	assert.Equal(t, 0, batch.Cmp(agg))
+
+	_, err = acc.AggregateWitnesses([][]byte{utxo(1), utxo(1)}, []*big.Int{ws[0], ws[0]})
+	assert.NotNil(t, err)
+	_, err = acc.BatchMembershipWitness([][]byte{utxo(1), utxo(1)})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,210 @@
+// Package address encodes public keys and stealth addresses for humans.
+// Every address carries a version byte telling what kind of key it holds,
+// and a checksum, so that typos are caught when decoding instead of
+// reaching the cryptographic layer as a valid but wrong key.
+//
+// Two encodings are supported: Base58Check, as used by Bitcoin, and
+// Bech32m (BIP350) under the human readable part HRP, which is case
+// insensitive and better at detecting errors. In both, the payload is the
+// version byte followed by the key
+package address
+
+import (
+	"errors"
+	"strings"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/stealth"
+)
+
+// HRP is the human readable part of Bech32m addresses
+const HRP = "dusk"
+
+// Version tells what kind of key an address holds
+type Version byte
+
+const (
+	// PublicKey is a Ristretto public key, e.g. for Schnorr signatures
+	PublicKey Version = 0x01
+	// Stealth is a stealth public address: the view key, then the spend key
+	Stealth Version = 0x02
+	// BLS is a BLS public key
+	BLS Version = 0x03
+)
+
+var (
+	// ErrChecksum is returned when the checksum of an address does not match
+	ErrChecksum = errors.New("address: invalid checksum")
+	// ErrInvalidCharacter is returned when an address contains a character
+	// outside of its alphabet
+	ErrInvalidCharacter = errors.New("address: invalid character")
+	// ErrMixedCase is returned when a Bech32m address mixes cases
+	ErrMixedCase = errors.New("address: mixed case")
+	// ErrLength is returned when an address or its payload has the wrong length
+	ErrLength = errors.New("address: invalid length")
+	// ErrVersion is returned for unknown versions, and when an address holds
+	// another kind of key than expected
+	ErrVersion = errors.New("address: unexpected version")
+	// ErrHRP is returned when a Bech32m address has another human readable part
+	ErrHRP = errors.New("address: unexpected human readable part")
+)
+
+// payloadSize returns the size of the key held by addresses of version v
+func payloadSize(v Version) (int, error) {
+	switch v {
+	case PublicKey:
+		return 32, nil
+	case Stealth:
+		return 64, nil
+	case BLS:
+		return 129, nil
+	}
+	return 0, ErrVersion
+}
+
+// Address is a decoded address
+type Address struct {
+	Version Version
+	Payload []byte
+}
+
+// FromPublicKey returns the address of a Ristretto public key
+func FromPublicKey(pk ristretto.Point) Address {
+	return Address{Version: PublicKey, Payload: pk.Bytes()}
+}
+
+// FromStealth returns the address of a stealth public address
+func FromStealth(addr stealth.PublicAddress) Address {
+	payload := append(addr.View.Bytes(), addr.Spend.Bytes()...)
+	return Address{Version: Stealth, Payload: payload}
+}
+
+// FromBLS returns the address of a BLS public key
+func FromBLS(pk *bls.PublicKey) Address {
+	return Address{Version: BLS, Payload: pk.Marshal()}
+}
+
+// check verifies that the payload has the size its version requires
+func (a Address) check() error {
+	size, err := payloadSize(a.Version)
+	if err != nil {
+		return err
+	}
+	if len(a.Payload) != size {
+		return ErrLength
+	}
+	return nil
+}
+
+// Base58 returns the Base58Check encoding of the address
+func (a Address) Base58() (string, error) {
+	if err := a.check(); err != nil {
+		return "", err
+	}
+	return Base58CheckEncode(byte(a.Version), a.Payload), nil
+}
+
+// Bech32 returns the Bech32m encoding of the address
+func (a Address) Bech32() (string, error) {
+	if err := a.check(); err != nil {
+		return "", err
+	}
+	data, err := convertBits(append([]byte{byte(a.Version)}, a.Payload...), 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return Bech32mEncode(HRP, data)
+}
+
+// ParseBase58 decodes a Base58Check address
+func ParseBase58(s string) (Address, error) {
+	v, payload, err := Base58CheckDecode(s)
+	if err != nil {
+		return Address{}, err
+	}
+	a := Address{Version: Version(v), Payload: payload}
+	if err := a.check(); err != nil {
+		return Address{}, err
+	}
+	return a, nil
+}
+
+// ParseBech32 decodes a Bech32m address
+func ParseBech32(s string) (Address, error) {
+	hrp, data, err := Bech32mDecode(s)
+	if err != nil {
+		return Address{}, err
+	}
+	if hrp != HRP {
+		return Address{}, ErrHRP
+	}
+	b, err := convertBits(data, 5, 8, false)
+	if err != nil {
+		return Address{}, err
+	}
+	if len(b) == 0 {
+		return Address{}, ErrLength
+	}
+	a := Address{Version: Version(b[0]), Payload: b[1:]}
+	if err := a.check(); err != nil {
+		return Address{}, err
+	}
+	return a, nil
+}
+
+// Parse decodes an address in either encoding. Bech32m addresses are
+// recognized by their human readable part
+func Parse(s string) (Address, error) {
+	if strings.HasPrefix(strings.ToLower(s), HRP+"1") {
+		return ParseBech32(s)
+	}
+	return ParseBase58(s)
+}
+
+// PublicKey returns the Ristretto public key held by the address
+func (a Address) PublicKey() (ristretto.Point, error) {
+	var pk ristretto.Point
+	if a.Version != PublicKey {
+		return pk, ErrVersion
+	}
+	if err := a.check(); err != nil {
+		return pk, err
+	}
+	return pk, setPoint(&pk, a.Payload)
+}
+
+// Stealth returns the stealth public address held by the address
+func (a Address) Stealth() (stealth.PublicAddress, error) {
+	var addr stealth.PublicAddress
+	if a.Version != Stealth {
+		return addr, ErrVersion
+	}
+	if err := a.check(); err != nil {
+		return addr, err
+	}
+	if err := setPoint(&addr.View, a.Payload[:32]); err != nil {
+		return addr, err
+	}
+	return addr, setPoint(&addr.Spend, a.Payload[32:])
+}
+
+// BLS returns the BLS public key held by the address
+func (a Address) BLS() (*bls.PublicKey, error) {
+	if a.Version != BLS {
+		return nil, ErrVersion
+	}
+	if err := a.check(); err != nil {
+		return nil, err
+	}
+	return bls.UnmarshalPk(a.Payload)
+}
+
+func setPoint(p *ristretto.Point, b []byte) error {
+	var buf [32]byte
+	copy(buf[:], b)
+	if !p.SetBytes(&buf) {
+		return errors.New("address: point not encodable")
+	}
+	return nil
+}
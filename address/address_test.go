@@ -0,0 +1,192 @@
+package address
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/schnorr"
+	"github.com/dusk-network/dusk-crypto/stealth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase58(t *testing.T) {
+	vectors := []struct{ hex, enc string }{
+		{"", ""},
+		{"61", "2g"},
+		{"48656c6c6f20576f726c6421", "2NEpo7TZRRrLZSi2U"},
+		{"000000287fb4cd", "111233QC4"},
+		{"00000000", "1111"},
+	}
+	for _, v := range vectors {
+		b, _ := hex.DecodeString(v.hex)
+		assert.Equal(t, v.enc, Base58Encode(b))
+
+		dec, err := Base58Decode(v.enc)
+		require.NoError(t, err)
+		assert.Equal(t, v.hex, hex.EncodeToString(dec))
+	}
+
+	_, err := Base58Decode("2NEpo7TZRRrLZSi2O")
+	assert.Equal(t, ErrInvalidCharacter, err)
+}
+
+func TestBase58Check(t *testing.T) {
+	payload, _ := hex.DecodeString("010966776006953d5567439e5e39f86a0d273bee")
+	enc := Base58CheckEncode(0, payload)
+	assert.Equal(t, "16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvM", enc)
+
+	v, dec, err := Base58CheckDecode(enc)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0), v)
+	assert.Equal(t, payload, dec)
+
+	_, _, err = Base58CheckDecode("16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvN")
+	assert.Equal(t, ErrChecksum, err)
+}
+
+func TestBech32m(t *testing.T) {
+	// BIP350 test vectors
+	valid := []string{
+		"A1LQFN3A",
+		"a1lqfn3a",
+		"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6",
+		"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx",
+		"11llllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllludsr8",
+		"split1checkupstagehandshakeupstreamerranterredcaperredlc445v",
+		"?1v759aa",
+	}
+	for _, s := range valid {
+		hrp, data, err := Bech32mDecode(s)
+		require.NoError(t, err, s)
+
+		enc, err := Bech32mEncode(hrp, data)
+		require.NoError(t, err)
+		assert.Equal(t, strings.ToLower(s), enc)
+	}
+
+	invalid := []string{
+		"\x201xj0phk",
+		"\x7f1g6xzxy",
+		"an84characterslonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11d6pts4",
+		"qyrz8wqd2c9m",
+		"1qyrz8wqd2c9m",
+		"y1b0jsk6g",
+		"lt1igcx5c0",
+		"in1muywd",
+		"mm1crxm3i",
+		"au1s5cgom",
+		"M1VUXWEZ",
+		"16plkw9",
+		"1p2gdwpf",
+		"A1lqfn3a",
+		// valid Bech32, but not Bech32m
+		"a12uel5l",
+	}
+	for _, s := range invalid {
+		_, _, err := Bech32mDecode(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestAddress(t *testing.T) {
+	_, pk := schnorr.GenerateKey()
+	blsPk, _, err := bls.GenKeyPair(nil)
+	require.NoError(t, err)
+	stealthAddr := stealth.GenerateKey().PublicAddress()
+
+	for _, a := range []Address{FromPublicKey(pk), FromStealth(stealthAddr), FromBLS(blsPk)} {
+		b58, err := a.Base58()
+		require.NoError(t, err)
+		dec, err := Parse(b58)
+		require.NoError(t, err)
+		assert.Equal(t, a, dec)
+
+		b32, err := a.Bech32()
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(b32, HRP+"1"))
+		dec, err = Parse(b32)
+		require.NoError(t, err)
+		assert.Equal(t, a, dec)
+
+		dec, err = Parse(strings.ToUpper(b32))
+		require.NoError(t, err)
+		assert.Equal(t, a, dec)
+	}
+
+	a, err := ParseBech32(mustBech32(t, FromPublicKey(pk)))
+	require.NoError(t, err)
+	got, err := a.PublicKey()
+	require.NoError(t, err)
+	assert.True(t, got.Equals(&pk))
+	_, err = a.Stealth()
+	assert.Equal(t, ErrVersion, err)
+
+	a, err = ParseBech32(mustBech32(t, FromStealth(stealthAddr)))
+	require.NoError(t, err)
+	gotAddr, err := a.Stealth()
+	require.NoError(t, err)
+	assert.True(t, gotAddr.View.Equals(&stealthAddr.View))
+	assert.True(t, gotAddr.Spend.Equals(&stealthAddr.Spend))
+
+	a, err = ParseBech32(mustBech32(t, FromBLS(blsPk)))
+	require.NoError(t, err)
+	gotBLS, err := a.BLS()
+	require.NoError(t, err)
+	assert.Equal(t, blsPk.Marshal(), gotBLS.Marshal())
+}
+
+func mustBech32(t *testing.T, a Address) string {
+	s, err := a.Bech32()
+	require.NoError(t, err)
+	return s
+}
+
+func TestAddressTypos(t *testing.T) {
+	_, pk := schnorr.GenerateKey()
+	a := FromPublicKey(pk)
+
+	b32 := mustBech32(t, a)
+	for i := len(HRP) + 1; i < len(b32); i++ {
+		typo := []byte(b32)
+		if typo[i] == 'q' {
+			typo[i] = 'p'
+		} else {
+			typo[i] = 'q'
+		}
+		_, err := Parse(string(typo))
+		assert.Equal(t, ErrChecksum, err)
+	}
+
+	b58, err := a.Base58()
+	require.NoError(t, err)
+	typo := []byte(b58)
+	if typo[5] == 'z' {
+		typo[5] = 'y'
+	} else {
+		typo[5] = 'z'
+	}
+	_, err = Parse(string(typo))
+	assert.Equal(t, ErrChecksum, err)
+
+	_, err = ParseBech32(strings.Replace(b32, HRP, "dask", 1))
+	assert.Error(t, err)
+
+	// Correct checksums over wrong payloads
+	_, err = ParseBase58(Base58CheckEncode(byte(PublicKey), pk.Bytes()[:31]))
+	assert.Equal(t, ErrLength, err)
+	_, err = ParseBase58(Base58CheckEncode(0x7f, pk.Bytes()))
+	assert.Equal(t, ErrVersion, err)
+
+	data, err := convertBits(append([]byte{byte(PublicKey)}, pk.Bytes()...), 8, 5, true)
+	require.NoError(t, err)
+	other, err := Bech32mEncode("other", data)
+	require.NoError(t, err)
+	_, err = ParseBech32(other)
+	assert.Equal(t, ErrHRP, err)
+
+	_, err = Address{Version: Stealth, Payload: pk.Bytes()}.Base58()
+	assert.Equal(t, ErrLength, err)
+}
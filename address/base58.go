@@ -0,0 +1,117 @@
+package address
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Index = func() [256]int {
+	var idx [256]int
+	for i := range idx {
+		idx[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		idx[base58Alphabet[i]] = i
+	}
+	return idx
+}()
+
+// Base58Encode encodes data with the Bitcoin Base58 alphabet. Leading zero
+// bytes are encoded as leading '1's
+func Base58Encode(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	// log(256) / log(58) < 1.37
+	digits := make([]byte, 0, len(data)*137/100+1)
+	for _, b := range data[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+
+	out := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		out[i] = '1'
+	}
+	for i, d := range digits {
+		out[len(out)-1-i] = base58Alphabet[d]
+	}
+	return string(out)
+}
+
+// Base58Decode decodes a Base58 string
+func Base58Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+
+	bytesLE := make([]byte, 0, len(s)*733/1000+1)
+	for i := zeros; i < len(s); i++ {
+		carry := base58Index[s[i]]
+		if carry < 0 {
+			return nil, ErrInvalidCharacter
+		}
+		for j := range bytesLE {
+			carry += int(bytesLE[j]) * 58
+			bytesLE[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			bytesLE = append(bytesLE, byte(carry))
+			carry >>= 8
+		}
+	}
+
+	out := make([]byte, zeros+len(bytesLE))
+	for i, b := range bytesLE {
+		out[len(out)-1-i] = b
+	}
+	return out, nil
+}
+
+func base58Checksum(data []byte) []byte {
+	h := sha256.Sum256(data)
+	h = sha256.Sum256(h[:])
+	return h[:4]
+}
+
+// Base58CheckEncode encodes version || payload followed by the first four
+// bytes of its double SHA-256, like Bitcoin addresses
+func Base58CheckEncode(version byte, payload []byte) string {
+	data := make([]byte, 0, 1+len(payload)+4)
+	data = append(data, version)
+	data = append(data, payload...)
+	data = append(data, base58Checksum(data)...)
+	return Base58Encode(data)
+}
+
+// Base58CheckDecode decodes and checks the output of Base58CheckEncode
+func Base58CheckDecode(s string) (byte, []byte, error) {
+	data, err := Base58Decode(s)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(data) < 5 {
+		return 0, nil, errors.New("address: base58check string too short")
+	}
+
+	body, checksum := data[:len(data)-4], data[len(data)-4:]
+	if !bytes.Equal(base58Checksum(body), checksum) {
+		return 0, nil, ErrChecksum
+	}
+	return body[0], body[1:], nil
+}
@@ -0,0 +1,167 @@
+package address
+
+import (
+	"errors"
+	"strings"
+)
+
+// Bech32m, as specified by BIP350. Addresses carrying BLS keys are longer
+// than the 90 characters BIP173 allows, so the limit is raised to
+// MaxBech32Length. The checksum still detects any error affecting up to
+// four characters in strings of up to 89 characters, and any other error
+// with probability 1 - 2^-30
+
+// MaxBech32Length is the maximum length of a Bech32m string
+const MaxBech32Length = 1023
+
+const (
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32mConst  = 0x2bc830a3
+)
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func hrpExpand(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+func bech32mChecksum(hrp string, data []byte) []byte {
+	values := append(hrpExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := bech32Polymod(values) ^ bech32mConst
+
+	out := make([]byte, 6)
+	for i := range out {
+		out[i] = byte(mod>>uint(5*(5-i))) & 31
+	}
+	return out
+}
+
+func checkHRP(hrp string) error {
+	if len(hrp) < 1 || len(hrp) > 83 {
+		return errors.New("address: invalid human readable part length")
+	}
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return ErrInvalidCharacter
+		}
+		if hrp[i] >= 'A' && hrp[i] <= 'Z' {
+			return ErrMixedCase
+		}
+	}
+	return nil
+}
+
+// Bech32mEncode encodes 5 bit groups under a lower case human readable part
+func Bech32mEncode(hrp string, data []byte) (string, error) {
+	if err := checkHRP(hrp); err != nil {
+		return "", err
+	}
+	if len(hrp)+1+len(data)+6 > MaxBech32Length {
+		return "", ErrLength
+	}
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range append(append([]byte{}, data...), bech32mChecksum(hrp, data)...) {
+		if d > 31 {
+			return "", errors.New("address: data is not made of 5 bit groups")
+		}
+		sb.WriteByte(bech32Charset[d])
+	}
+	return sb.String(), nil
+}
+
+// Bech32mDecode decodes a Bech32m string into its lower case human readable
+// part and its 5 bit groups. Mixed case strings are rejected
+func Bech32mDecode(s string) (string, []byte, error) {
+	if len(s) > MaxBech32Length {
+		return "", nil, ErrLength
+	}
+	lower, upper := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 33 || c > 126 {
+			return "", nil, ErrInvalidCharacter
+		}
+		lower = lower || (c >= 'a' && c <= 'z')
+		upper = upper || (c >= 'A' && c <= 'Z')
+	}
+	if lower && upper {
+		return "", nil, ErrMixedCase
+	}
+	s = strings.ToLower(s)
+
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, errors.New("address: missing separator or checksum")
+	}
+	hrp := s[:sep]
+	if err := checkHRP(hrp); err != nil {
+		return "", nil, err
+	}
+
+	data := make([]byte, len(s)-sep-1)
+	for i := range data {
+		idx := strings.IndexByte(bech32Charset, s[sep+1+i])
+		if idx < 0 {
+			return "", nil, ErrInvalidCharacter
+		}
+		data[i] = byte(idx)
+	}
+
+	if bech32Polymod(append(hrpExpand(hrp), data...)) != bech32mConst {
+		return "", nil, ErrChecksum
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// convertBits regroups bits, e.g. from bytes to 5 bit groups. When
+// decoding, the padding must be shorter than a group and made of zeros
+func convertBits(data []byte, from, to uint, pad bool) ([]byte, error) {
+	acc, bits := uint32(0), uint(0)
+	maxv := uint32(1)<<to - 1
+	out := make([]byte, 0, len(data)*int(from)/int(to)+1)
+	for _, v := range data {
+		if uint32(v)>>from != 0 {
+			return nil, ErrInvalidCharacter
+		}
+		acc = acc<<from | uint32(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(to-bits)&maxv))
+		}
+	} else if bits >= from || acc<<(to-bits)&maxv != 0 {
+		return nil, errors.New("address: invalid padding")
+	}
+	return out, nil
+}
@@ -0,0 +1,220 @@
+// Package beacon implements a randomness beacon run by a committee holding
+// a threshold BLS key. In every round the members sign the previous beacon
+// value with their key shares. Any threshold of the partial signatures
+// combine into the same group signature, whose hash is the next value, so
+// that fewer than threshold colluding members can neither predict nor bias
+// it. Clients that fall behind catch up by verifying a whole segment of the
+// chain at once.
+//
+// The messages are hashed to G1 points whose discrete logs are unknown, see
+// hash.HashToG1. Were they known, the signature of the next round would be a
+// public multiple of the last one, and every future value could be computed
+// from a single entry
+package beacon
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/hash"
+)
+
+// MaxSegment bounds the number of entries verified at once by VerifyChain
+const MaxSegment = 1 << 16
+
+const signatureSize = 64
+
+// Value is an output of the beacon
+type Value [32]byte
+
+// Genesis returns the value preceding the first round, derived from a seed
+// agreed upon when the committee is set up
+func Genesis(seed []byte) Value {
+	var v Value
+	copy(v[:], hash.Sha3256WithDomain("dusk.beacon.genesis", seed))
+	return v
+}
+
+// Message returns the message signed in a round
+func Message(round uint64, prev Value) []byte {
+	var r [8]byte
+	binary.BigEndian.PutUint64(r[:], round)
+	return hash.Sha3256WithDomain("dusk.beacon.round", r[:], prev[:])
+}
+
+// Group is the public description of a committee
+type Group struct {
+	// Key is the group public key
+	Key *bls.PublicKey
+	// Shares[i] is the public key share of the member with index i+1
+	Shares []*bls.PublicKey
+	// Threshold is the number of partial signatures needed in a round
+	Threshold int
+}
+
+// Entry is the output of a round
+type Entry struct {
+	Round     uint64
+	Signature *bls.UnsafeSignature
+}
+
+// Value returns the beacon value of the entry
+func (e *Entry) Value() Value {
+	var v Value
+	copy(v[:], hash.Sha3256WithDomain("dusk.beacon.value", e.Signature.Marshal()))
+	return v
+}
+
+// PartialSignature is the contribution of a member to a round
+type PartialSignature struct {
+	Index     uint32
+	Signature *bls.UnsafeSignature
+}
+
+// Sign returns the partial signature of the member with the given index and
+// key share for the round following prev
+func Sign(sk *bls.SecretKey, index uint32, round uint64, prev Value) (*PartialSignature, error) {
+	sig, err := bls.UnsafeSign(sk, Message(round, prev))
+	if err != nil {
+		return nil, err
+	}
+	return &PartialSignature{Index: index, Signature: sig}, nil
+}
+
+func (g *Group) check() error {
+	if g.Key == nil || g.Threshold < 1 || g.Threshold > len(g.Shares) {
+		return errors.New("invalid group")
+	}
+	return nil
+}
+
+// VerifyPartial checks the partial signature of a member
+func (g *Group) VerifyPartial(round uint64, prev Value, ps *PartialSignature) error {
+	if ps == nil || ps.Signature == nil {
+		return errors.New("missing partial signature")
+	}
+	if ps.Index == 0 || int(ps.Index) > len(g.Shares) {
+		return errors.New("unknown member")
+	}
+	return bls.VerifyUnsafe(g.Shares[ps.Index-1], Message(round, prev), ps.Signature)
+}
+
+// Aggregate combines the partial signatures of a round into its entry.
+// Invalid and duplicate partial signatures are skipped, and an error is
+// returned if fewer than threshold valid ones remain
+func (g *Group) Aggregate(round uint64, prev Value, partials []*PartialSignature) (*Entry, error) {
+	if err := g.check(); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uint32]bool, g.Threshold)
+	indices := make([]uint32, 0, g.Threshold)
+	sigs := make([]*bls.UnsafeSignature, 0, g.Threshold)
+	for _, ps := range partials {
+		if len(sigs) == g.Threshold {
+			break
+		}
+		if ps == nil || seen[ps.Index] || g.VerifyPartial(round, prev, ps) != nil {
+			continue
+		}
+		seen[ps.Index] = true
+		indices = append(indices, ps.Index)
+		sigs = append(sigs, ps.Signature)
+	}
+	if len(sigs) < g.Threshold {
+		return nil, errors.New("not enough valid partial signatures")
+	}
+
+	sig, err := bls.CombineUnsafe(indices, sigs)
+	if err != nil {
+		return nil, err
+	}
+	e := &Entry{Round: round, Signature: sig}
+	if err := g.Verify(prev, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Verify checks an entry against the value preceding it
+func (g *Group) Verify(prev Value, e *Entry) error {
+	if err := g.check(); err != nil {
+		return err
+	}
+	if e == nil || e.Signature == nil {
+		return errors.New("missing entry")
+	}
+	return bls.VerifyUnsafe(g.Key, Message(e.Round, prev), e.Signature)
+}
+
+// VerifyChain checks a segment of the chain following the trusted value of
+// the given round, and returns the value of its last entry. The entries must
+// be consecutive. Their signatures are aggregated and verified with a single
+// batch, since the messages of distinct rounds are distinct
+func (g *Group) VerifyChain(round uint64, prev Value, entries []*Entry) (Value, error) {
+	if err := g.check(); err != nil {
+		return Value{}, err
+	}
+	if len(entries) == 0 || len(entries) > MaxSegment {
+		return Value{}, errors.New("invalid segment length")
+	}
+
+	pks := make([]*bls.PublicKey, len(entries))
+	msgs := make([][]byte, len(entries))
+	sigs := make([]*bls.UnsafeSignature, len(entries))
+	for i, e := range entries {
+		if e == nil || e.Signature == nil {
+			return Value{}, errors.New("missing entry")
+		}
+		round++
+		if e.Round != round {
+			return Value{}, errors.New("entries are not consecutive")
+		}
+
+		pks[i] = g.Key
+		msgs[i] = Message(e.Round, prev)
+		sigs[i] = e.Signature
+		prev = e.Value()
+	}
+
+	agg, err := bls.UnsafeBatch(sigs...)
+	if err != nil {
+		return Value{}, err
+	}
+	if err := bls.VerifyUnsafeBatch(pks, msgs, agg); err != nil {
+		return Value{}, err
+	}
+	return prev, nil
+}
+
+// Encode an Entry
+func (e *Entry) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, e.Round); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, e.Signature.Marshal())
+}
+
+// Decode an Entry
+func (e *Entry) Decode(r io.Reader) error {
+	if e == nil {
+		return errors.New("struct is nil")
+	}
+
+	if err := binary.Read(r, binary.BigEndian, &e.Round); err != nil {
+		return err
+	}
+
+	buf := make([]byte, signatureSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return err
+	}
+	sig := &bls.UnsafeSignature{}
+	if err := sig.Unmarshal(buf); err != nil {
+		return err
+	}
+	e.Signature = sig
+	return nil
+}
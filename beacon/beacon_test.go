@@ -0,0 +1,152 @@
+package beacon
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type committee struct {
+	group *Group
+	keys  []*bls.SecretKey
+}
+
+func newCommittee(t *testing.T, threshold, n int) *committee {
+	pk, pks, sks, err := bls.GenThresholdKeys(threshold, n, nil)
+	require.Nil(t, err)
+	return &committee{
+		group: &Group{Key: pk, Shares: pks, Threshold: threshold},
+		keys:  sks,
+	}
+}
+
+// run signs a round with the members at the given positions
+func (c *committee) run(t *testing.T, round uint64, prev Value, members ...int) *Entry {
+	var partials []*PartialSignature
+	for _, m := range members {
+		ps, err := Sign(c.keys[m], uint32(m+1), round, prev)
+		require.Nil(t, err)
+		partials = append(partials, ps)
+	}
+	e, err := c.group.Aggregate(round, prev, partials)
+	require.Nil(t, err)
+	return e
+}
+
+func TestRound(t *testing.T) {
+	c := newCommittee(t, 3, 5)
+	genesis := Genesis([]byte("seed"))
+
+	// every quorum produces the same value
+	e1 := c.run(t, 1, genesis, 0, 1, 2)
+	e2 := c.run(t, 1, genesis, 4, 2, 3)
+	assert.Equal(t, e1.Value(), e2.Value())
+	assert.Nil(t, c.group.Verify(genesis, e1))
+
+	// the value depends on the round and on the previous value
+	assert.NotNil(t, c.group.Verify(Genesis([]byte("other")), e1))
+	e1.Round = 2
+	assert.NotNil(t, c.group.Verify(genesis, e1))
+}
+
+func TestUnpredictable(t *testing.T) {
+	c := newCommittee(t, 3, 5)
+	genesis := Genesis([]byte("seed"))
+	e1 := c.run(t, 1, genesis, 0, 1, 2)
+
+	// with h0 = g1^k, the signature of round 2 was k2/k1 times the one of
+	// round 1, for public k1 and k2
+	k1 := hash.HashToBN256Scalar("dusk.bls.h0", Message(1, genesis))
+	k2 := hash.HashToBN256Scalar("dusk.bls.h0", Message(2, e1.Value()))
+	ratio := new(big.Int).ModInverse(k1, bn256.Order)
+	ratio.Mul(ratio, k2).Mod(ratio, bn256.Order)
+
+	p := new(bn256.G1)
+	_, err := p.Unmarshal(e1.Signature.Marshal())
+	require.Nil(t, err)
+	scaled := &bls.UnsafeSignature{}
+	require.Nil(t, scaled.Unmarshal(new(bn256.G1).ScalarMult(p, ratio).Marshal()))
+
+	forged := &Entry{Round: 2, Signature: scaled}
+	assert.NotNil(t, c.group.Verify(e1.Value(), forged))
+
+	e2 := c.run(t, 2, e1.Value(), 1, 3, 4)
+	assert.Nil(t, c.group.Verify(e1.Value(), e2))
+	assert.NotEqual(t, e2.Value(), forged.Value())
+}
+
+func TestAggregateSkipsInvalid(t *testing.T) {
+	c := newCommittee(t, 2, 4)
+	prev := Genesis(nil)
+
+	good0, err := Sign(c.keys[0], 1, 1, prev)
+	require.Nil(t, err)
+	wrongRound, err := Sign(c.keys[1], 2, 2, prev)
+	require.Nil(t, err)
+	wrongIndex, err := Sign(c.keys[2], 4, 1, prev)
+	require.Nil(t, err)
+	good3, err := Sign(c.keys[3], 4, 1, prev)
+	require.Nil(t, err)
+
+	_, err = c.group.Aggregate(1, prev, []*PartialSignature{good0, good0, wrongRound, wrongIndex})
+	assert.NotNil(t, err)
+
+	e, err := c.group.Aggregate(1, prev, []*PartialSignature{good0, good0, wrongRound, wrongIndex, good3})
+	require.Nil(t, err)
+	assert.Nil(t, c.group.Verify(prev, e))
+}
+
+func TestVerifyChain(t *testing.T) {
+	c := newCommittee(t, 2, 3)
+	genesis := Genesis([]byte("seed"))
+
+	prev := genesis
+	var entries []*Entry
+	for round := uint64(1); round <= 5; round++ {
+		e := c.run(t, round, prev, int(round)%3, int(round+1)%3)
+		entries = append(entries, e)
+		prev = e.Value()
+	}
+
+	last, err := c.group.VerifyChain(0, genesis, entries)
+	require.Nil(t, err)
+	assert.Equal(t, prev, last)
+
+	// catching up from the middle of the chain
+	last, err = c.group.VerifyChain(2, entries[1].Value(), entries[2:])
+	require.Nil(t, err)
+	assert.Equal(t, prev, last)
+
+	// gaps, reordering and forged entries are rejected
+	_, err = c.group.VerifyChain(0, genesis, append(entries[:1:1], entries[2:]...))
+	assert.NotNil(t, err)
+	_, err = c.group.VerifyChain(0, genesis, []*Entry{entries[1], entries[0]})
+	assert.NotNil(t, err)
+	forged := &Entry{Round: 3, Signature: entries[3].Signature}
+	_, err = c.group.VerifyChain(2, entries[1].Value(), []*Entry{forged})
+	assert.NotNil(t, err)
+	_, err = c.group.VerifyChain(0, Genesis(nil), entries)
+	assert.NotNil(t, err)
+}
+
+func TestEntryEncodeDecode(t *testing.T) {
+	c := newCommittee(t, 1, 1)
+	e := c.run(t, 7, Genesis(nil), 0)
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, e.Encode(buf))
+
+	decoded := &Entry{}
+	require.Nil(t, decoded.Decode(buf))
+	assert.Equal(t, e.Round, decoded.Round)
+	assert.Equal(t, e.Value(), decoded.Value())
+
+	var nilEntry *Entry
+	assert.NotNil(t, nilEntry.Decode(buf))
+}
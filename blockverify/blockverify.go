@@ -0,0 +1,160 @@
+// Package blockverify verifies the cryptography of a block in one call: the
+// BLS certificates of its committees and the range proofs of its
+// transactions. Both are scheduled as jobs on the same bounded pool of
+// workers, so that a node spends at most a known number of cores on block
+// verification, whatever the mix of the block, and the first failure
+// cancels the jobs still pending
+package blockverify
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/rangeproof"
+)
+
+// DefaultProofsPerJob is the number of range proofs batch verified by a
+// single job. Bigger jobs share more of the multi-exponentiation, smaller
+// ones spread better over the workers
+const DefaultProofsPerJob = 4
+
+// Certificate is the aggregated signature of a committee over a message
+type Certificate struct {
+	Apk       *bls.Apk
+	Msg       []byte
+	Signature *bls.Signature
+}
+
+// Block holds what Verify checks of a block
+type Block struct {
+	Certificates []Certificate
+	RangeProofs  []rangeproof.Proof
+}
+
+// Verifier verifies blocks on a bounded number of workers. The bound holds
+// across concurrent calls to Verify, which share the workers
+type Verifier struct {
+	slots        chan struct{}
+	proofsPerJob int
+}
+
+// NewVerifier returns a Verifier running at most workers jobs at a time,
+// runtime.NumCPU() if workers is not positive, and batching proofsPerJob
+// range proofs per job, DefaultProofsPerJob if it is not positive
+func NewVerifier(workers, proofsPerJob int) *Verifier {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if proofsPerJob <= 0 {
+		proofsPerJob = DefaultProofsPerJob
+	}
+	return &Verifier{
+		slots:        make(chan struct{}, workers),
+		proofsPerJob: proofsPerJob,
+	}
+}
+
+// Workers returns the maximum number of jobs run at a time
+func (v *Verifier) Workers() int {
+	return cap(v.slots)
+}
+
+type job func(ctx context.Context) error
+
+// jobs splits the block into jobs, the range proofs first as they are
+// the longest
+func (v *Verifier) jobs(b *Block) []job {
+	var jobs []job
+	for i := 0; i < len(b.RangeProofs); i += v.proofsPerJob {
+		end := i + v.proofsPerJob
+		if end > len(b.RangeProofs) {
+			end = len(b.RangeProofs)
+		}
+		start, proofs := i, b.RangeProofs[i:end]
+		jobs = append(jobs, func(ctx context.Context) error {
+			ok, err := rangeproof.VerifyBatch(ctx, proofs)
+			if err == nil && !ok {
+				err = errors.New("invalid batch")
+			}
+			return errors.Wrapf(err, "[Verify] - range proofs %d to %d", start, start+len(proofs)-1)
+		})
+	}
+	for i := range b.Certificates {
+		i, c := i, b.Certificates[i]
+		jobs = append(jobs, func(ctx context.Context) error {
+			if c.Apk == nil || c.Signature == nil {
+				return errors.Errorf("[Verify] - certificate %d is incomplete", i)
+			}
+			return errors.Wrapf(bls.Verify(c.Apk, c.Msg, c.Signature), "[Verify] - certificate %d", i)
+		})
+	}
+	return jobs
+}
+
+// Verify checks every certificate and every range proof of the block. It
+// returns the first failure, or ctx.Err() if ctx is done before all the
+// jobs ran. A failed batch of range proofs does not tell which proof of the
+// batch is invalid
+func (v *Verifier) Verify(ctx context.Context, b *Block) error {
+	jobs := v.jobs(b)
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+		mu.Unlock()
+	}
+
+	for _, j := range jobs {
+		// wait for a free worker
+		acquired := false
+		select {
+		case v.slots <- struct{}{}:
+			acquired = true
+		case <-ctx.Done():
+		}
+		if !acquired {
+			break
+		}
+		if ctx.Err() != nil {
+			<-v.slots
+			break
+		}
+
+		wg.Add(1)
+		go func(j job) {
+			defer func() {
+				<-v.slots
+				wg.Done()
+			}()
+			if err := j(ctx); err != nil {
+				fail(err)
+			}
+		}(j)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,69 @@
+package blockverify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/bls"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/rangeproof"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBlock(t *testing.T, numCerts, numProofs int) *Block {
+	b := &Block{}
+	for i := 0; i < numCerts; i++ {
+		pk, sk, err := bls.GenKeyPair(nil)
+		require.Nil(t, err)
+		msg := []byte{byte(i), 'b', 'l', 'o', 'c', 'k'}
+		sig, err := bls.Sign(sk, pk, msg)
+		require.Nil(t, err)
+		b.Certificates = append(b.Certificates, Certificate{Apk: bls.NewApk(pk), Msg: msg, Signature: sig})
+	}
+	for i := 0; i < numProofs; i++ {
+		p, err := rangeproof.ProveUint64([]uint64{uint64(i), 1000}, nil)
+		require.Nil(t, err)
+		b.RangeProofs = append(b.RangeProofs, p)
+	}
+	return b
+}
+
+func TestVerify(t *testing.T) {
+	v := NewVerifier(3, 2)
+	assert.Equal(t, 3, v.Workers())
+
+	b := testBlock(t, 4, 5)
+	assert.Nil(t, v.Verify(context.Background(), b))
+	assert.Nil(t, v.Verify(context.Background(), &Block{}))
+
+	// concurrent calls share the workers
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() { errs <- v.Verify(context.Background(), b) }()
+	}
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, <-errs)
+	}
+}
+
+func TestVerifyFailures(t *testing.T) {
+	v := NewVerifier(0, 0)
+
+	b := testBlock(t, 3, 3)
+	b.Certificates[1].Msg = []byte("other")
+	err := v.Verify(context.Background(), b)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrVerificationFailed))
+
+	b = testBlock(t, 3, 3)
+	b.RangeProofs[2].V[0] = b.RangeProofs[0].V[0]
+	assert.NotNil(t, v.Verify(context.Background(), b))
+
+	b = testBlock(t, 1, 0)
+	b.Certificates[0].Signature = nil
+	assert.NotNil(t, v.Verify(context.Background(), b))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NotNil(t, v.Verify(ctx, testBlock(t, 2, 2)))
+}
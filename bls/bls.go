@@ -6,16 +6,19 @@
 package bls
 
 import (
-	"crypto/rand"
-	"crypto/subtle"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"math/big"
+	"runtime"
 
 	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/ct"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
 	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/securemem"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/sha3"
 )
@@ -84,9 +87,33 @@ func newG2Base(strRepr string) *bn256.G2 {
 	return g2Base
 }
 
-// SecretKey has "x" as secret for the BLS signature
+// SecretKey has "x" as secret for the BLS signature. x is kept in locked
+// memory, see the securemem package, which a finalizer releases: x must only
+// be accessed through withScalar, g1Mult and g2Mult, which keep the key
+// alive while it is in use
 type SecretKey struct {
-	x *big.Int
+	x   *big.Int
+	mem *securemem.Buffer
+}
+
+// withScalar calls f with x. f must not retain x
+func (sk *SecretKey) withScalar(f func(x *big.Int)) {
+	f(sk.x)
+	runtime.KeepAlive(sk)
+}
+
+// g1Mult returns p^x
+func (sk *SecretKey) g1Mult(p *bn256.G1) *bn256.G1 {
+	res := newG1()
+	sk.withScalar(func(x *big.Int) { res.ScalarMult(p, x) })
+	return res
+}
+
+// g2Mult returns p^x
+func (sk *SecretKey) g2Mult(p *bn256.G2) *bn256.G2 {
+	res := newG2()
+	sk.withScalar(func(x *big.Int) { res.ScalarMult(p, x) })
+	return res
 }
 
 // PublicKey is calculated as g^x
@@ -112,7 +139,7 @@ type UnsafeSignature struct {
 // GenKeyPair generates Public and Private Keys
 func GenKeyPair(randReader io.Reader) (*PublicKey, *SecretKey, error) {
 	if randReader == nil {
-		randReader = rand.Reader
+		randReader = rng.Reader
 	}
 	x, gx, err := bn256.RandomG2(randReader)
 
@@ -120,7 +147,7 @@ func GenKeyPair(randReader io.Reader) (*PublicKey, *SecretKey, error) {
 		return nil, nil, err
 	}
 
-	return &PublicKey{gx}, &SecretKey{x}, nil
+	return &PublicKey{gx}, newSecretKey(x), nil
 }
 
 // UnmarshalPk unmarshals a byte array into a BLS PublicKey
@@ -132,19 +159,13 @@ func UnmarshalPk(b []byte) (*PublicKey, error) {
 	return pk, nil
 }
 
-//hashFn is the hash function used to digest a message before mapping it to a point.
-var hashFn = sha3.New256
-
 // h0 is the hash-to-curve-point function
 // Hₒ : M -> Gₒ
-// TODO: implement the Elligator algorithm for deterministic random-looking hashing to BN256 point. See https://eprint.iacr.org/2014/043.pdf
+// The discrete log of the point is unknown: signatures of different messages
+// must not be multiples of one another by known scalars. h0 used to return
+// g1^H(msg), so signatures made before hash.HashToG1 do not verify anymore
 func h0(msg []byte) (*bn256.G1, error) {
-	hashed, err := hash.PerformHash(hashFn(), msg)
-	if err != nil {
-		return nil, err
-	}
-	k := new(big.Int).SetBytes(hashed)
-	return newG1().ScalarBaseMult(k), nil
+	return hash.HashToG1("dusk.bls.h0", msg), nil
 }
 
 // h1 is the hashing function used in the modified BLS multi-signature construction
@@ -153,12 +174,7 @@ func h1(pk *PublicKey) (*big.Int, error) {
 	// marshalling G2 into a []byte
 	pkb := pk.Marshal()
 	// hashing into Z
-	h, err := hash.PerformHash(hashFn(), pkb)
-	if err != nil {
-		return nil, err
-	}
-
-	return new(big.Int).SetBytes(h), nil
+	return hash.HashToBN256Scalar("dusk.bls.h1", pkb), nil
 }
 
 func pkt(pk *PublicKey) (*bn256.G2, error) {
@@ -314,7 +330,7 @@ func (sigma *Signature) Compress() []byte {
 func (sigma *Signature) Decompress(x []byte) error {
 	e, err := bn256.Decompress(x)
 	if err != nil {
-		return err
+		return cryptoerrors.Wrap(cryptoerrors.ErrMalformedPoint, err, "bls: invalid signature")
 	}
 	sigma.e = e
 	return nil
@@ -332,14 +348,13 @@ func (sigma *Signature) Unmarshal(msg []byte) error {
 	if len(msg) == 33 {
 		e, err = bn256.Decompress(msg)
 		if err != nil {
-			return err
+			return cryptoerrors.Wrap(cryptoerrors.ErrMalformedPoint, err, "bls: invalid signature")
 		}
 		sigma.e = e
 		return nil
 	}
 
-	e = newG1()
-	if _, err := e.Unmarshal(msg); err != nil {
+	if e, err = unmarshalG1(msg, "signature"); err != nil {
 		return err
 	}
 	sigma.e = e
@@ -370,7 +385,7 @@ func Verify(apk *Apk, msg []byte, sigma *Signature) error {
 // TODO: consider adding the possibility to handle non distinct messages (at batch level after aggregating APK)
 func VerifyBatch(apks []*Apk, msgs [][]byte, sigma *Signature) error {
 	if len(msgs) != len(apks) {
-		return fmt.Errorf(
+		return cryptoerrors.Newf(cryptoerrors.ErrInvalidLength,
 			"BLS Verify APK Batch: the nr of Public Keys (%d) and the nr. of messages (%d) do not match",
 			len(apks),
 			len(msgs),
@@ -391,9 +406,7 @@ func UnsafeSign(key *SecretKey, msg []byte) (*UnsafeSignature, error) {
 	if err != nil {
 		return nil, err
 	}
-	p := newG1()
-	p.ScalarMult(hash, key.x)
-	return &UnsafeSignature{p}, nil
+	return &UnsafeSignature{key.g1Mult(hash)}, nil
 }
 
 // Compress the signature to the 32 byte form
@@ -405,7 +418,7 @@ func (usig *UnsafeSignature) Compress() []byte {
 func (usig *UnsafeSignature) Decompress(x []byte) error {
 	e, err := bn256.Decompress(x)
 	if err != nil {
-		return err
+		return cryptoerrors.Wrap(cryptoerrors.ErrMalformedPoint, err, "bls: invalid signature")
 	}
 	usig.e = e
 	return nil
@@ -418,8 +431,8 @@ func (usig *UnsafeSignature) Marshal() []byte {
 
 // Unmarshal a byte array into an UnsafeSignature
 func (usig *UnsafeSignature) Unmarshal(msg []byte) error {
-	e := newG1()
-	if _, err := e.Unmarshal(msg); err != nil {
+	e, err := unmarshalG1(msg, "signature")
+	if err != nil {
 		return err
 	}
 	usig.e = e
@@ -473,7 +486,7 @@ func verify(pk *bn256.G2, msg []byte, sigma *bn256.G1) error {
 
 	pairH0mPK := bn256.Pair(h0m, pk).Marshal()
 	pairSigG2 := bn256.Pair(sigma, g2Base).Marshal()
-	if subtle.ConstantTimeCompare(pairH0mPK, pairSigG2) != 1 {
+	if !ct.Equal(pairH0mPK, pairSigG2) {
 		msg := fmt.Sprintf(
 			"bls apk: Invalid Signature.\nG1Sig pair (length %d): %v...\nApk H0(m) pair (length %d): %v...",
 			len(pairSigG2),
@@ -481,7 +494,7 @@ func verify(pk *bn256.G2, msg []byte, sigma *bn256.G1) error {
 			len(pairH0mPK),
 			hex.EncodeToString(pairH0mPK[0:10]),
 		)
-		return errors.New(msg)
+		return cryptoerrors.New(cryptoerrors.ErrVerificationFailed, msg)
 	}
 
 	return nil
@@ -509,8 +522,8 @@ func verifyBatch(pkeys []*bn256.G2, msgList [][]byte, sig *bn256.G1, allowDistin
 
 	pairSigG2 := bn256.Pair(sig, g2Base)
 
-	if subtle.ConstantTimeCompare(pairSigG2.Marshal(), pairH0mPKs.Marshal()) != 1 {
-		return errors.New("bls: Invalid Signature")
+	if !ct.Equal(pairSigG2.Marshal(), pairH0mPKs.Marshal()) {
+		return cryptoerrors.New(cryptoerrors.ErrVerificationFailed, "bls: Invalid Signature")
 	}
 
 	return nil
@@ -520,7 +533,7 @@ func verifyBatch(pkeys []*bn256.G2, msgList [][]byte, sig *bn256.G1, allowDistin
 func VerifyCompressed(pks []*bn256.G2, msgList [][]byte, compressedSig []byte, allowDistinct bool) error {
 	sig, err := bn256.Decompress(compressedSig)
 	if err != nil {
-		return err
+		return cryptoerrors.Wrap(cryptoerrors.ErrMalformedPoint, err, "bls: invalid signature")
 	}
 	return verifyBatch(pks, msgList, sig, allowDistinct)
 }
@@ -556,11 +569,11 @@ func (pk *PublicKey) UnmarshalText(data []byte) error {
 	if err != nil {
 		return err
 	}
-	pk.gx = newG2()
-	_, err = pk.gx.Unmarshal(bs)
+	gx, err := unmarshalG2(bs, "public key")
 	if err != nil {
 		return err
 	}
+	pk.gx = gx
 	return nil
 }
 
@@ -571,14 +584,123 @@ func (pk *PublicKey) Marshal() []byte {
 
 // Unmarshal a public key from a byte array
 func (pk *PublicKey) Unmarshal(data []byte) error {
-	pk.gx = newG2()
-	_, err := pk.gx.Unmarshal(data)
+	gx, err := unmarshalG2(data, "public key")
 	if err != nil {
 		return err
 	}
+	pk.gx = gx
+	return nil
+}
+
+// unmarshalG1 decodes a point of G1 from the whole of b, rejecting the
+// encodings Marshal does not produce, such as coordinates which are not
+// reduced or bytes trailing the point at infinity, so that a point has a
+// single encoding
+func unmarshalG1(b []byte, what string) (*bn256.G1, error) {
+	e := newG1()
+	_, err := e.Unmarshal(b)
+	if err != nil {
+		return nil, cryptoerrors.Wrap(cryptoerrors.ErrMalformedPoint, err, "bls: invalid "+what)
+	}
+	if !ct.Equal(e.Marshal(), b) {
+		return nil, cryptoerrors.New(cryptoerrors.ErrNotCanonical, "bls: "+what+" is not canonically encoded")
+	}
+	return e, nil
+}
+
+// unmarshalG2 is unmarshalG1 for G2
+func unmarshalG2(b []byte, what string) (*bn256.G2, error) {
+	e := newG2()
+	_, err := e.Unmarshal(b)
+	if err != nil {
+		return nil, cryptoerrors.Wrap(cryptoerrors.ErrMalformedPoint, err, "bls: invalid "+what)
+	}
+	if !ct.Equal(e.Marshal(), b) {
+		return nil, cryptoerrors.New(cryptoerrors.ErrNotCanonical, "bls: "+what+" is not canonically encoded")
+	}
+	return e, nil
+}
+
+// secretKeySize is the size of a marshaled SecretKey
+const secretKeySize = 32
+
+// PublicKey returns the public key of the secret key
+func (sk *SecretKey) PublicKey() *PublicKey {
+	return &PublicKey{sk.g2Mult(g2Base)}
+}
+
+// Marshal returns the 32 byte big endian representation of the secret key
+func (sk *SecretKey) Marshal() []byte {
+	b := make([]byte, secretKeySize)
+	sk.withScalar(func(x *big.Int) {
+		xb := x.Bytes()
+		copy(b[secretKeySize-len(xb):], xb)
+		securemem.Wipe(xb)
+	})
+	return b
+}
+
+// Unmarshal a secret key from a byte array
+func (sk *SecretKey) Unmarshal(data []byte) error {
+	if len(data) != secretKeySize {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: invalid secret key size")
+	}
+	// The range check does not depend on the value of the key
+	var order [secretKeySize]byte
+	ob := bn256.Order.Bytes()
+	copy(order[secretKeySize-len(ob):], ob)
+	if ct.IsZero(data)|(1^ct.LessThan(data, order[:])) == 1 {
+		return cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: secret key out of range")
+	}
+	sk.set(new(big.Int).SetBytes(data))
 	return nil
 }
 
+// newSecretKey returns the SecretKey of x, which is wiped
+func newSecretKey(x *big.Int) *SecretKey {
+	sk := &SecretKey{}
+	sk.set(x)
+	return sk
+}
+
+// set moves x to locked memory. The memory is released by Destroy, or once
+// sk is collected
+func (sk *SecretKey) set(x *big.Int) {
+	locked, mem, err := securemem.NewInt(x)
+	if err != nil {
+		// Mapping memory is not expected to fail, but the key is still
+		// usable from the heap if it does
+		sk.Destroy()
+		sk.x = x
+		return
+	}
+
+	sk.Destroy()
+	sk.x, sk.mem = locked, mem
+	runtime.SetFinalizer(sk, (*SecretKey).Destroy)
+}
+
+// Destroy wipes the secret key. The key must not be used afterwards
+func (sk *SecretKey) Destroy() {
+	if sk.mem != nil {
+		// A corrupted canary cannot be acted upon here, the memory is
+		// released anyway
+		_ = sk.mem.Destroy()
+		sk.mem = nil
+		runtime.SetFinalizer(sk, nil)
+	}
+	sk.x = new(big.Int)
+}
+
+// UnmarshalSk unmarshals a byte array into a BLS SecretKey
+func UnmarshalSk(b []byte) (*SecretKey, error) {
+	sk := &SecretKey{}
+	if err := sk.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	return sk, nil
+}
+
 func encodeToText(data []byte) []byte {
 	buf := make([]byte, base64.RawURLEncoding.EncodedLen(len(data)))
 	base64.RawURLEncoding.Encode(buf, data)
@@ -10,6 +10,9 @@ import (
 	"testing"
 
 	"github.com/dusk-network/bn256"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/shamir"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -99,6 +102,24 @@ func TestHashToPoint(t *testing.T) {
 	g1, err := h0(msg)
 	assert.Equal(t, nil, err)
 	assert.NotEqual(t, nil, g1)
+
+	// h0 used to be g1^k for the public k below, which made the signatures
+	// of any two messages multiples of one another by a known scalar
+	k := hash.HashToBN256Scalar("dusk.bls.h0", msg)
+	assert.NotEqual(t, newG1().ScalarBaseMult(k).Marshal(), g1.Marshal())
+
+	// and the signature of another message cannot be derived from sig
+	pk, sk, err := GenKeyPair(rand.Reader)
+	require.Nil(t, err)
+	sig, err := UnsafeSign(sk, msg)
+	require.Nil(t, err)
+
+	other := []byte("other data")
+	kOther := hash.HashToBN256Scalar("dusk.bls.h0", other)
+	ratio := new(big.Int).ModInverse(k, bn256.Order)
+	ratio.Mul(ratio, kOther).Mod(ratio, bn256.Order)
+	scaled := &UnsafeSignature{newG1().ScalarMult(sig.e, ratio)}
+	assert.NotNil(t, VerifyUnsafe(pk, other, scaled))
 }
 
 func randomInt(r io.Reader) *big.Int {
@@ -125,7 +146,7 @@ func TestRogueKey(t *testing.T) {
 	pRogue := newG2()
 	pRogue.Add(g2Alpha, rogueGx)
 
-	sk, pk := &SecretKey{alpha}, &PublicKey{pRogue}
+	sk, pk := &SecretKey{x: alpha}, &PublicKey{pRogue}
 
 	msg := []byte("test data")
 	rogueSignature, err := UnsafeSign(sk, msg)
@@ -427,3 +448,135 @@ func BenchmarkVerifySingleCompressedUnsafeSignature(b *testing.B) {
 		b.StopTimer()
 	}
 }
+
+func TestThresholdSignature(t *testing.T) {
+	require := require.New(t)
+	pk, pks, sks, err := GenThresholdKeys(3, 5, rand.Reader)
+	require.NoError(err)
+	require.Len(pks, 5)
+	require.Len(sks, 5)
+
+	msg := randomMessage()
+	sigs := make([]*UnsafeSignature, 5)
+	for i := range sks {
+		sigs[i], err = UnsafeSign(sks[i], msg)
+		require.NoError(err)
+		require.NoError(VerifyUnsafe(pks[i], msg, sigs[i]))
+	}
+
+	// any 3 partial signatures combine into the same group signature
+	first, err := CombineUnsafe([]uint32{1, 2, 3}, sigs[:3])
+	require.NoError(err)
+	require.NoError(VerifyUnsafe(pk, msg, first))
+
+	second, err := CombineUnsafe([]uint32{5, 2, 4}, []*UnsafeSignature{sigs[4], sigs[1], sigs[3]})
+	require.NoError(err)
+	require.Equal(first.Marshal(), second.Marshal())
+
+	// 2 do not
+	partial, err := CombineUnsafe([]uint32{1, 2}, sigs[:2])
+	require.NoError(err)
+	require.Error(VerifyUnsafe(pk, msg, partial))
+
+	_, err = CombineUnsafe([]uint32{1, 1}, sigs[:2])
+	require.Error(err)
+	_, err = CombineUnsafe([]uint32{1}, sigs[:2])
+	require.Error(err)
+}
+
+func TestSecretKeyMarshal(t *testing.T) {
+	require := require.New(t)
+	pk, sk, err := GenKeyPair(rand.Reader)
+	require.NoError(err)
+	require.Equal(pk.Marshal(), sk.PublicKey().Marshal())
+
+	b := sk.Marshal()
+	require.Len(b, 32)
+	decoded, err := UnmarshalSk(b)
+	require.NoError(err)
+	require.Equal(0, sk.x.Cmp(decoded.x))
+
+	_, err = UnmarshalSk(make([]byte, 32))
+	require.Error(err)
+	_, err = UnmarshalSk(bn256.Order.Bytes())
+	require.Error(err)
+	_, err = UnmarshalSk(b[:31])
+	require.Error(err)
+}
+
+func TestSecretKeyDestroy(t *testing.T) {
+	require := require.New(t)
+	_, sk, err := GenKeyPair(rand.Reader)
+	require.NoError(err)
+	require.NotNil(sk.mem)
+	require.NotEqual(0, sk.x.Sign())
+
+	sk.Destroy()
+	require.Nil(sk.mem)
+	require.Equal(0, sk.x.Sign())
+	require.Equal(make([]byte, 32), sk.Marshal())
+
+	// Destroying twice is harmless
+	sk.Destroy()
+}
+
+func TestErrorKinds(t *testing.T) {
+	pk, sk, err := GenKeyPair(rand.Reader)
+	require.NoError(t, err)
+	sig, err := Sign(sk, pk, []byte("msg"))
+	require.NoError(t, err)
+
+	err = Verify(NewApk(pk), []byte("other"), sig)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrVerificationFailed))
+
+	_, err = UnmarshalSignature(make([]byte, 10))
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrMalformedPoint))
+
+	_, err = UnmarshalPk([]byte{1, 2, 3})
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrMalformedPoint))
+	// the point at infinity encoded as an affine point
+	infinity := make([]byte, publicKeySize)
+	infinity[0] = 1
+	_, err = UnmarshalPk(infinity)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrNotCanonical))
+
+	_, err = UnmarshalSk(make([]byte, 31))
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrInvalidLength))
+	_, err = UnmarshalSk(make([]byte, 32))
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrOutOfRange))
+}
+
+func TestPolicySignature(t *testing.T) {
+	// 2 directors or 3 managers
+	policy := shamir.AnyOf(shamir.ThresholdOf(2, 1, 2), shamir.ThresholdOf(3, 10, 11, 12))
+	pk, keys, err := GenPolicyKeys(policy, rand.Reader)
+	require.Nil(t, err)
+
+	msg := []byte("policy signature")
+	sign := func(parties ...uint32) ([]shamir.ShareID, []*UnsafeSignature) {
+		var ids []shamir.ShareID
+		var sigs []*UnsafeSignature
+		for _, k := range keys {
+			for _, p := range parties {
+				if k.Party == p {
+					sig, err := UnsafeSign(k.SecretKey, msg)
+					require.Nil(t, err)
+					ids = append(ids, k.ID)
+					sigs = append(sigs, sig)
+				}
+			}
+		}
+		return ids, sigs
+	}
+
+	for _, parties := range [][]uint32{{1, 2}, {10, 11, 12}, {2, 10, 11, 12}} {
+		ids, sigs := sign(parties...)
+		sig, err := CombinePolicyUnsafe(policy, ids, sigs)
+		require.Nil(t, err)
+		assert.Nil(t, VerifyUnsafe(pk, msg, sig))
+	}
+
+	ids, sigs := sign(1, 10, 11)
+	_, err = CombinePolicyUnsafe(policy, ids, sigs)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,383 @@
+// Package blstest is a differential testing harness for BLS verification.
+// It runs the same inputs through several verification backends and reports
+// any input on which they disagree, to catch soundness bugs and encoding
+// divergences between implementations early.
+//
+// Inputs are in the uncompressed encodings of the bls package: 129 byte
+// public keys and 64 byte unsafe signatures. Native is the bls package
+// itself, and Reference an independent implementation over bn256, which
+// decodes strictly and checks the pairing equation with a single final
+// exponentiation. Other implementations, e.g. the Rust one behind cgo, join
+// the comparison by implementing Backend.
+//
+// Generate builds a structured corpus of valid signatures and of mutations
+// of them, and FromFuzz maps fuzzer bytes to the same structured inputs, so
+// that fuzzing explores the edges of the encodings rather than mostly
+// malformed points. The go-fuzz entry point is in fuzz.go
+package blstest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// PublicKeySize is the size of an encoded public key
+	PublicKeySize = 129
+	// SignatureSize is the size of an encoded signature
+	SignatureSize = 64
+)
+
+// fieldP is the prime of the base field of bn256
+var fieldP, _ = new(big.Int).SetString("65000549695646603732796438742359905742825358107623003571877145026864184071783", 10)
+
+// Backend is an implementation of BLS verification
+type Backend interface {
+	Name() string
+	// VerifyUnsafe checks the unsafe signature sig of msg by pk, returning
+	// nil if and only if it is valid
+	VerifyUnsafe(pk, msg, sig []byte) error
+}
+
+// Input is an input of the harness. Label describes how it was built
+type Input struct {
+	Label     string
+	PublicKey []byte
+	Message   []byte
+	Signature []byte
+}
+
+// Divergence is an input on which backends disagree, with the result of
+// every backend
+type Divergence struct {
+	Input   Input
+	Results map[string]error
+}
+
+func (d *Divergence) Error() string {
+	return fmt.Sprintf("blstest: backends disagree on %q: %v", d.Input.Label, d.Results)
+}
+
+// Compare runs the input through the backends, and returns a *Divergence if
+// some accept it and others reject it
+func Compare(in Input, backends ...Backend) error {
+	results := make(map[string]error, len(backends))
+	accepted := 0
+	for _, b := range backends {
+		err := b.VerifyUnsafe(in.PublicKey, in.Message, in.Signature)
+		results[b.Name()] = err
+		if err == nil {
+			accepted++
+		}
+	}
+	if accepted != 0 && accepted != len(backends) {
+		return &Divergence{Input: in, Results: results}
+	}
+	return nil
+}
+
+type native struct{}
+
+// Native returns the backend of the bls package
+func Native() Backend {
+	return native{}
+}
+
+func (native) Name() string {
+	return "native"
+}
+
+func (native) VerifyUnsafe(pk, msg, sig []byte) error {
+	key, err := bls.UnmarshalPk(pk)
+	if err != nil {
+		return err
+	}
+	s := &bls.UnsafeSignature{}
+	if err := s.Unmarshal(sig); err != nil {
+		return err
+	}
+	return bls.VerifyUnsafe(key, msg, s)
+}
+
+type reference struct{}
+
+// Reference returns the reference backend. It rejects encodings of the
+// wrong size, coordinates which are not reduced, and points at infinity. It
+// does not check that public keys are in the prime order subgroup of the
+// twist, which the bls package does not either
+func Reference() Backend {
+	return reference{}
+}
+
+func (reference) Name() string {
+	return "reference"
+}
+
+func (reference) VerifyUnsafe(pk, msg, sig []byte) error {
+	if len(pk) != PublicKeySize || len(sig) != SignatureSize {
+		return fmt.Errorf("reference: invalid encoding size")
+	}
+	if pk[0] != 1 || !reduced(pk[1:]) {
+		return fmt.Errorf("reference: invalid public key encoding")
+	}
+	if !reduced(sig) || !onG1(sig) {
+		return fmt.Errorf("reference: invalid signature")
+	}
+
+	key := new(bn256.G2)
+	if _, err := key.Unmarshal(pk); err != nil {
+		return fmt.Errorf("reference: public key is not on the curve")
+	}
+	s := new(bn256.G1)
+	if _, err := s.Unmarshal(sig); err != nil {
+		return fmt.Errorf("reference: invalid signature")
+	}
+
+	// e(s, g2) * e(-H(msg), pk) = 1
+	h := referenceH0(msg)
+	g2 := new(bn256.G2).ScalarBaseMult(big.NewInt(1))
+	gt := bn256.Miller(s, g2)
+	gt.Add(gt, bn256.Miller(new(bn256.G1).Neg(h), key))
+	gt.Finalize()
+
+	one := new(bn256.GT).ScalarBaseMult(new(big.Int))
+	if string(gt.Marshal()) != string(one.Marshal()) {
+		return fmt.Errorf("reference: invalid signature")
+	}
+	return nil
+}
+
+// referenceH0 is the point a message hashes to: the Blake2b-512 digest of
+// the length prefixed domain "dusk.bls.h0", message and a 4 byte counter,
+// reduced modulo the order of the group, is the x coordinate of the point
+// for the first counter for which it is on the curve
+func referenceH0(msg []byte) *bn256.G1 {
+	const domain = "dusk.bls.h0"
+	for ctr := uint32(0); ; ctr++ {
+		h, _ := blake2b.New512(nil)
+		var l [4]byte
+		for _, d := range [][]byte{[]byte(domain), msg} {
+			binary.BigEndian.PutUint32(l[:], uint32(len(d)))
+			_, _ = h.Write(l[:])
+			_, _ = h.Write(d)
+		}
+		binary.BigEndian.PutUint32(l[:], 4)
+		_, _ = h.Write(l[:])
+		binary.BigEndian.PutUint32(l[:], ctr)
+		_, _ = h.Write(l[:])
+
+		x := new(big.Int).SetBytes(h.Sum(nil))
+		x.Mod(x, bn256.Order)
+
+		// compressed encoding: 32 byte big endian x, then the sign byte
+		var buf [33]byte
+		xb := x.Bytes()
+		copy(buf[32-len(xb):32], xb)
+		if p, err := bn256.Decompress(buf[:]); err == nil {
+			return p
+		}
+	}
+}
+
+// reduced returns true if b is a sequence of 32 byte big endian field
+// elements
+func reduced(b []byte) bool {
+	for len(b) > 0 {
+		if new(big.Int).SetBytes(b[:32]).Cmp(fieldP) >= 0 {
+			return false
+		}
+		b = b[32:]
+	}
+	return true
+}
+
+// onG1 returns true if b encodes a point of G1 other than the point at
+// infinity: y^2 = x^3 + 3. G1 has no cofactor
+func onG1(b []byte) bool {
+	x := new(big.Int).SetBytes(b[:32])
+	y := new(big.Int).SetBytes(b[32:])
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return false
+	}
+	lhs := new(big.Int).Mul(y, y)
+	rhs := new(big.Int).Exp(x, big.NewInt(3), fieldP)
+	rhs.Add(rhs, big.NewInt(3))
+	return lhs.Sub(lhs, rhs).Mod(lhs, fieldP).Sign() == 0
+}
+
+// mutations build inputs from a valid one, in, and another valid one by
+// another key, other. pos is a position chosen by the caller. The mutations
+// which do not apply to in return false
+var mutations = []struct {
+	label string
+	apply func(in, other Input, pos int) (Input, bool)
+}{
+	{"valid", func(in, other Input, pos int) (Input, bool) {
+		return in, true
+	}},
+	{"other message", func(in, other Input, pos int) (Input, bool) {
+		in.Message = append(append([]byte{}, in.Message...), byte(pos))
+		return in, true
+	}},
+	{"other key", func(in, other Input, pos int) (Input, bool) {
+		in.PublicKey = other.PublicKey
+		return in, true
+	}},
+	{"other signature", func(in, other Input, pos int) (Input, bool) {
+		in.Signature = other.Signature
+		return in, true
+	}},
+	{"signature bit flip", func(in, other Input, pos int) (Input, bool) {
+		in.Signature = flip(in.Signature, pos)
+		return in, true
+	}},
+	{"public key bit flip", func(in, other Input, pos int) (Input, bool) {
+		in.PublicKey = flip(in.PublicKey, pos)
+		return in, true
+	}},
+	{"signature at infinity", func(in, other Input, pos int) (Input, bool) {
+		in.Signature = make([]byte, SignatureSize)
+		return in, true
+	}},
+	{"negated signature", func(in, other Input, pos int) (Input, bool) {
+		// -(x, y) = (x, p - y)
+		y := new(big.Int).SetBytes(in.Signature[32:])
+		in.Signature = append([]byte{}, in.Signature...)
+		put(in.Signature[32:], y.Sub(fieldP, y))
+		return in, true
+	}},
+	{"unreduced signature", func(in, other Input, pos int) (Input, bool) {
+		// x + p, when it fits in 32 bytes
+		x := new(big.Int).SetBytes(in.Signature[:32])
+		x.Add(x, fieldP)
+		if x.BitLen() > 256 {
+			return in, false
+		}
+		in.Signature = append([]byte{}, in.Signature...)
+		put(in.Signature[:32], x)
+		return in, true
+	}},
+	{"unreduced public key", func(in, other Input, pos int) (Input, bool) {
+		// the first coordinate of the key from pos which still fits in 32
+		// bytes once p is added
+		for i := 0; i < 4; i++ {
+			off := 1 + 32*((pos+i)%4)
+			c := new(big.Int).SetBytes(in.PublicKey[off : off+32])
+			c.Add(c, fieldP)
+			if c.BitLen() <= 256 {
+				in.PublicKey = append([]byte{}, in.PublicKey...)
+				put(in.PublicKey[off:off+32], c)
+				return in, true
+			}
+		}
+		return in, false
+	}},
+}
+
+func flip(b []byte, pos int) []byte {
+	b = append([]byte{}, b...)
+	bit := pos % (8 * len(b))
+	b[bit/8] ^= 1 << uint(bit%8)
+	return b
+}
+
+func put(dst []byte, v *big.Int) {
+	for i := range dst {
+		dst[i] = 0
+	}
+	vb := v.Bytes()
+	copy(dst[len(dst)-len(vb):], vb)
+}
+
+// signed returns a valid input, by a key drawn from r
+func signed(r io.Reader, msg []byte) (Input, error) {
+	pk, sk, err := bls.GenKeyPair(r)
+	if err != nil {
+		return Input{}, err
+	}
+	sig, err := bls.UnsafeSign(sk, msg)
+	if err != nil {
+		return Input{}, err
+	}
+	return Input{PublicKey: pk.Marshal(), Message: msg, Signature: sig.Marshal()}, nil
+}
+
+// build returns every mutation of a valid input derived from seed which
+// applies to it
+func build(seed []byte) ([]Input, error) {
+	r := rng.Deterministic(seed)
+	msg := hash.Sha3256WithDomain("dusk.blstest.message", seed)
+	in, err := signed(r, msg)
+	if err != nil {
+		return nil, err
+	}
+	other, err := signed(r, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var b [4]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return nil, err
+	}
+	pos := int(binary.BigEndian.Uint32(b[:]) >> 1)
+
+	res := make([]Input, 0, len(mutations))
+	for _, m := range mutations {
+		if mutated, ok := m.apply(in, other, pos); ok {
+			mutated.Label = m.label
+			res = append(res, mutated)
+		}
+	}
+	return res, nil
+}
+
+// Generate returns a corpus of n valid inputs derived from seed, each
+// followed by those of its mutations which apply
+func Generate(seed []byte, n int) ([]Input, error) {
+	var res []Input
+	var idx [4]byte
+	for i := 0; i < n; i++ {
+		binary.BigEndian.PutUint32(idx[:], uint32(i))
+		inputs, err := build(hash.Sha3256WithDomain("dusk.blstest.seed", seed, idx[:]))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, inputs...)
+	}
+	return res, nil
+}
+
+// FromFuzz maps fuzzer bytes to an input: the first byte picks a mutation,
+// and the rest seeds the valid input it is applied to. Inputs of more than
+// PublicKeySize+SignatureSize bytes whose first byte is 0xff are instead
+// split raw into a key, a signature and a message
+func FromFuzz(data []byte) (Input, error) {
+	if len(data) > 1+PublicKeySize+SignatureSize && data[0] == 0xff {
+		data = data[1:]
+		return Input{
+			Label:     "raw",
+			PublicKey: data[:PublicKeySize],
+			Signature: data[PublicKeySize : PublicKeySize+SignatureSize],
+			Message:   data[PublicKeySize+SignatureSize:],
+		}, nil
+	}
+
+	var kind byte
+	if len(data) > 0 {
+		kind, data = data[0], data[1:]
+	}
+	inputs, err := build(data)
+	if err != nil {
+		return Input{}, err
+	}
+	return inputs[int(kind)%len(inputs)], nil
+}
@@ -0,0 +1,22 @@
+package blstest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDifferential(t *testing.T) {
+	corpus, err := Generate([]byte("blstest"), 4)
+	require.Nil(t, err)
+	require.True(t, len(corpus) > 3*len(mutations))
+
+	for _, in := range corpus {
+		if err := Compare(in, Native(), Reference()); err != nil {
+			t.Error(err)
+		}
+		valid := Reference().VerifyUnsafe(in.PublicKey, in.Message, in.Signature) == nil
+		assert.Equal(t, in.Label == "valid", valid, in.Label)
+	}
+}
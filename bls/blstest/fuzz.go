@@ -0,0 +1,20 @@
+//go:build gofuzz
+// +build gofuzz
+
+package blstest
+
+// Fuzz is the go-fuzz entry point of the differential harness. It panics on
+// any input on which the backends disagree
+func Fuzz(data []byte) int {
+	in, err := FromFuzz(data)
+	if err != nil {
+		return 0
+	}
+	if err := Compare(in, Native(), Reference()); err != nil {
+		panic(err)
+	}
+	if Reference().VerifyUnsafe(in.PublicKey, in.Message, in.Signature) == nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,137 @@
+package bls
+
+import (
+	"encoding/binary"
+
+	"github.com/dusk-network/dusk-crypto/ct"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/hash"
+)
+
+// checkpointDomain separates the digests of the key lists
+const checkpointDomain = "dusk.bls.checkpoint"
+
+// Checkpoint is the partial aggregation of the first Count keys of a
+// committee: their Apk, and a running digest of the keys themselves. A
+// light client holding a trusted checkpoint verifies a claimed Apk for the
+// whole committee by aggregating the keys added since, instead of all of
+// them. The digest ties the checkpoint to one ordered list of keys
+type Checkpoint struct {
+	Count  uint64
+	Digest [32]byte
+	Apk    *Apk
+}
+
+// Extend returns the checkpoint after aggregating pks. The zero Checkpoint
+// is the one of the empty committee
+func (c *Checkpoint) Extend(pks []*PublicKey) (*Checkpoint, error) {
+	res := &Checkpoint{Count: c.Count, Digest: c.Digest}
+	if c.Apk != nil {
+		res.Apk = c.Apk.Copy()
+	}
+
+	var count [8]byte
+	for _, pk := range pks {
+		if res.Apk == nil {
+			res.Apk = NewApk(pk)
+		} else if err := res.Apk.Aggregate(pk); err != nil {
+			return nil, err
+		}
+		res.Count++
+		binary.BigEndian.PutUint64(count[:], res.Count)
+		copy(res.Digest[:], hash.Sha3256WithDomain(checkpointDomain, res.Digest[:], count[:], pk.Marshal()))
+	}
+	return res, nil
+}
+
+// Checkpoints aggregates pks and returns a checkpoint after every `every`
+// keys
+func Checkpoints(pks []*PublicKey, every int) ([]*Checkpoint, error) {
+	if every <= 0 {
+		return nil, cryptoerrors.Newf(cryptoerrors.ErrOutOfRange,
+			"bls: invalid checkpoint interval %d", every)
+	}
+
+	res := make([]*Checkpoint, 0, len(pks)/every)
+	c := &Checkpoint{}
+	for i := every; i <= len(pks); i += every {
+		next, err := c.Extend(pks[i-every : i])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, next)
+		c = next
+	}
+	return res, nil
+}
+
+// VerifyCheckpoint checks that to is the checkpoint reached from the
+// trusted checkpoint from by aggregating added
+func VerifyCheckpoint(from *Checkpoint, added []*PublicKey, to *Checkpoint) error {
+	c, err := from.Extend(added)
+	if err != nil {
+		return err
+	}
+	if c.Count != to.Count || !ct.Equal(c.Digest[:], to.Digest[:]) || !equalApk(c.Apk, to.Apk) {
+		return cryptoerrors.New(cryptoerrors.ErrVerificationFailed,
+			"bls: checkpoint does not follow from the added keys")
+	}
+	return nil
+}
+
+// VerifyApk checks that apk aggregates the keys of the trusted checkpoint
+// from followed by added
+func VerifyApk(from *Checkpoint, added []*PublicKey, apk *Apk) error {
+	c, err := from.Extend(added)
+	if err != nil {
+		return err
+	}
+	if !equalApk(c.Apk, apk) {
+		return cryptoerrors.New(cryptoerrors.ErrVerificationFailed,
+			"bls: apk does not follow from the checkpoint")
+	}
+	return nil
+}
+
+func equalApk(a, b *Apk) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return ct.Equal(a.Marshal(), b.Marshal())
+}
+
+// Marshal a Checkpoint: the count as big endian uint64, the digest, then
+// the Apk unless the checkpoint is empty
+func (c *Checkpoint) Marshal() []byte {
+	buf := make([]byte, 40, 40+publicKeySize)
+	binary.BigEndian.PutUint64(buf[0:8], c.Count)
+	copy(buf[8:40], c.Digest[:])
+	if c.Apk != nil {
+		buf = append(buf, c.Apk.Marshal()...)
+	}
+	return buf
+}
+
+// Unmarshal a Checkpoint
+func (c *Checkpoint) Unmarshal(b []byte) error {
+	if len(b) < 40 {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: checkpoint is too short")
+	}
+	count := binary.BigEndian.Uint64(b[0:8])
+	if (count == 0 && len(b) != 40) || (count > 0 && len(b) != 40+publicKeySize) {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: invalid checkpoint length")
+	}
+
+	var apk *Apk
+	if count > 0 {
+		var err error
+		if apk, err = UnmarshalApk(b[40:]); err != nil {
+			return err
+		}
+	}
+
+	c.Count = count
+	copy(c.Digest[:], b[8:40])
+	c.Apk = apk
+	return nil
+}
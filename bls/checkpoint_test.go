@@ -0,0 +1,51 @@
+package bls
+
+import (
+	"testing"
+
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoints(t *testing.T) {
+	var pks []*PublicKey
+	for i := 0; i < 11; i++ {
+		pk, _, err := GenKeyPair(nil)
+		require.Nil(t, err)
+		pks = append(pks, pk)
+	}
+	apk, err := AggregateApk(pks)
+	require.Nil(t, err)
+
+	cps, err := Checkpoints(pks, 4)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(cps))
+	assert.Equal(t, uint64(4), cps[0].Count)
+	assert.Equal(t, uint64(8), cps[1].Count)
+
+	// a light client trusting the last checkpoint only checks the 3 new keys
+	assert.Nil(t, VerifyApk(cps[1], pks[8:], apk))
+	assert.Nil(t, VerifyApk(&Checkpoint{}, pks, apk))
+	assert.Nil(t, VerifyCheckpoint(cps[0], pks[4:8], cps[1]))
+	assert.Nil(t, VerifyCheckpoint(&Checkpoint{}, pks[:4], cps[0]))
+
+	err = VerifyApk(cps[1], pks[9:], apk)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrVerificationFailed))
+
+	// the same keys in another order give the same Apk, not the same digest
+	swapped := []*PublicKey{pks[5], pks[4], pks[6], pks[7]}
+	assert.NotNil(t, VerifyCheckpoint(cps[0], swapped, cps[1]))
+
+	for _, c := range []*Checkpoint{{}, cps[0], cps[1]} {
+		var dec Checkpoint
+		require.Nil(t, dec.Unmarshal(c.Marshal()))
+		assert.Nil(t, VerifyCheckpoint(&dec, nil, c))
+	}
+
+	var dec Checkpoint
+	assert.NotNil(t, dec.Unmarshal(cps[0].Marshal()[:40]))
+
+	_, err = Checkpoints(pks, 0)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,187 @@
+package bls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/dusk-network/bn256"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/pkg/errors"
+)
+
+// publicKeySize is the size of a marshaled PublicKey
+const publicKeySize = 129
+
+// signatureSize is the size of a marshaled Signature
+const signatureSize = 64
+
+// MaxDeltaKeys bounds the number of keys accepted when unmarshaling a Delta
+const MaxDeltaKeys = 1 << 16
+
+// Delta is the difference between two aggregate signatures on the same
+// message by overlapping committees: the keys that joined, the keys that
+// left, and the difference of the signature points. Whoever holds the first
+// aggregate rebuilds the second one from it, so that evidence about two
+// competing aggregates only carries bytes proportional to their difference
+type Delta struct {
+	Added   []*PublicKey
+	Removed []*PublicKey
+	diff    *bn256.G1
+}
+
+// NewDelta returns the delta turning sigFrom, the aggregate of the
+// committee from, into sigTo, the aggregate of the committee to
+func NewDelta(from, to []*PublicKey, sigFrom, sigTo *Signature) (*Delta, error) {
+	if sigFrom == nil || sigTo == nil {
+		return nil, errors.New("bls: missing aggregate signature")
+	}
+	fromKeys, err := keySet(from)
+	if err != nil {
+		return nil, err
+	}
+	toKeys, err := keySet(to)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Delta{
+		diff: newG1().Add(sigTo.e, newG1().Neg(sigFrom.e)),
+	}
+	for k, pk := range toKeys {
+		if _, ok := fromKeys[k]; !ok {
+			d.Added = append(d.Added, pk)
+		}
+	}
+	for k, pk := range fromKeys {
+		if _, ok := toKeys[k]; !ok {
+			d.Removed = append(d.Removed, pk)
+		}
+	}
+	sortKeys(d.Added)
+	sortKeys(d.Removed)
+	return d, nil
+}
+
+func keySet(pks []*PublicKey) (map[string]*PublicKey, error) {
+	set := make(map[string]*PublicKey, len(pks))
+	for _, pk := range pks {
+		k := string(pk.Marshal())
+		if _, ok := set[k]; ok {
+			return nil, errors.New("bls: duplicate public key in committee")
+		}
+		set[k] = pk
+	}
+	return set, nil
+}
+
+// sortKeys orders keys by their encoding, for deltas to be deterministic
+func sortKeys(pks []*PublicKey) {
+	sort.Slice(pks, func(i, j int) bool {
+		return bytes.Compare(pks[i].Marshal(), pks[j].Marshal()) < 0
+	})
+}
+
+// Apply returns the aggregate signature the delta leads to from sig
+func (d *Delta) Apply(sig *Signature) *Signature {
+	return &Signature{e: newG1().Add(sig.e, d.diff)}
+}
+
+// ApplyApk returns the aggregated public key of the committee the delta
+// leads to, from the one of the original committee
+func (d *Delta) ApplyApk(apk *Apk) (*Apk, error) {
+	res := apk.Copy()
+	for _, pk := range d.Added {
+		if err := res.Aggregate(pk); err != nil {
+			return nil, err
+		}
+	}
+	for _, pk := range d.Removed {
+		gxt, err := pkt(pk)
+		if err != nil {
+			return nil, err
+		}
+		res.gx.Add(res.gx, newG2().Neg(gxt))
+	}
+	return res, nil
+}
+
+// ApplyKeys returns the committee the delta leads to from committee
+func (d *Delta) ApplyKeys(committee []*PublicKey) ([]*PublicKey, error) {
+	set, err := keySet(committee)
+	if err != nil {
+		return nil, err
+	}
+	for _, pk := range d.Removed {
+		k := string(pk.Marshal())
+		if _, ok := set[k]; !ok {
+			return nil, errors.New("bls: removed key is not in the committee")
+		}
+		delete(set, k)
+	}
+	for _, pk := range d.Added {
+		k := string(pk.Marshal())
+		if _, ok := set[k]; ok {
+			return nil, errors.New("bls: added key is already in the committee")
+		}
+		set[k] = pk
+	}
+
+	res := make([]*PublicKey, 0, len(set))
+	for _, pk := range set {
+		res = append(res, pk)
+	}
+	sortKeys(res)
+	return res, nil
+}
+
+// Marshal a Delta: the number of added and removed keys as big endian
+// uint32, the keys, then the signature difference
+func (d *Delta) Marshal() []byte {
+	buf := make([]byte, 8, 8+(len(d.Added)+len(d.Removed))*publicKeySize+signatureSize)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(d.Added)))
+	binary.BigEndian.PutUint32(buf[4:8], uint32(len(d.Removed)))
+	for _, pk := range d.Added {
+		buf = append(buf, pk.Marshal()...)
+	}
+	for _, pk := range d.Removed {
+		buf = append(buf, pk.Marshal()...)
+	}
+	return append(buf, d.diff.Marshal()...)
+}
+
+// Unmarshal a Delta
+func (d *Delta) Unmarshal(b []byte) error {
+	if len(b) < 8 {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: delta is too short")
+	}
+	nAdded := binary.BigEndian.Uint32(b[0:4])
+	nRemoved := binary.BigEndian.Uint32(b[4:8])
+	if nAdded > MaxDeltaKeys || nRemoved > MaxDeltaKeys {
+		return cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: too many keys in delta")
+	}
+	n := int(nAdded + nRemoved)
+	if len(b) != 8+n*publicKeySize+signatureSize {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: invalid delta length")
+	}
+
+	keys := make([]*PublicKey, n)
+	for i := range keys {
+		off := 8 + i*publicKeySize
+		pk, err := UnmarshalPk(b[off : off+publicKeySize])
+		if err != nil {
+			return err
+		}
+		keys[i] = pk
+	}
+
+	diff := newG1()
+	if _, err := diff.Unmarshal(b[8+n*publicKeySize:]); err != nil {
+		return cryptoerrors.Wrap(cryptoerrors.ErrMalformedPoint, err, "bls: invalid delta")
+	}
+
+	d.Added = keys[:nAdded]
+	d.Removed = keys[nAdded:]
+	d.diff = diff
+	return nil
+}
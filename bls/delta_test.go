@@ -0,0 +1,95 @@
+package bls
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func committeeAggregate(t *testing.T, pks []*PublicKey, sks []*SecretKey, msg []byte) (*Apk, *Signature) {
+	var sig *Signature
+	for i := range pks {
+		s, err := Sign(sks[i], pks[i], msg)
+		require.Nil(t, err)
+		if sig == nil {
+			sig = s
+		} else {
+			sig = sig.Aggregate(s)
+		}
+	}
+	apk, err := AggregateApk(pks)
+	require.Nil(t, err)
+	return apk, sig
+}
+
+func TestDelta(t *testing.T) {
+	var pks []*PublicKey
+	var sks []*SecretKey
+	for i := 0; i < 6; i++ {
+		pk, sk, err := GenKeyPair(nil)
+		require.Nil(t, err)
+		pks = append(pks, pk)
+		sks = append(sks, sk)
+	}
+	msg := randomMessage()
+
+	// the committees overlap on keys 2 and 3
+	apkFrom, sigFrom := committeeAggregate(t, pks[0:4], sks[0:4], msg)
+	apkTo, sigTo := committeeAggregate(t, pks[2:6], sks[2:6], msg)
+
+	d, err := NewDelta(pks[0:4], pks[2:6], sigFrom, sigTo)
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(d.Added))
+	assert.Equal(t, 2, len(d.Removed))
+
+	b := d.Marshal()
+	assert.Equal(t, 8+4*publicKeySize+signatureSize, len(b))
+	received := &Delta{}
+	require.Nil(t, received.Unmarshal(b))
+
+	sig := received.Apply(sigFrom)
+	assert.Equal(t, sigTo.Marshal(), sig.Marshal())
+	apk, err := received.ApplyApk(apkFrom)
+	require.Nil(t, err)
+	assert.Equal(t, apkTo.Marshal(), apk.Marshal())
+	assert.Nil(t, Verify(apk, msg, sig))
+
+	committee, err := received.ApplyKeys(pks[0:4])
+	require.Nil(t, err)
+	expected := append([]*PublicKey{}, pks[2:6]...)
+	sortKeys(expected)
+	assert.Equal(t, len(expected), len(committee))
+	for i := range expected {
+		assert.Equal(t, expected[i].Marshal(), committee[i].Marshal())
+	}
+
+	// the delta only applies to the committee it was computed from
+	_, err = received.ApplyKeys(pks[1:5])
+	assert.NotNil(t, err)
+}
+
+func TestDeltaIdentical(t *testing.T) {
+	pk, sk, err := GenKeyPair(nil)
+	require.Nil(t, err)
+	_, sig := committeeAggregate(t, []*PublicKey{pk}, []*SecretKey{sk}, randomMessage())
+
+	d, err := NewDelta([]*PublicKey{pk}, []*PublicKey{pk}, sig, sig)
+	require.Nil(t, err)
+	b := d.Marshal()
+	assert.Equal(t, 8+signatureSize, len(b))
+
+	received := &Delta{}
+	require.Nil(t, received.Unmarshal(b))
+	assert.Equal(t, sig.Marshal(), received.Apply(sig).Marshal())
+}
+
+func TestDeltaUnmarshalInvalid(t *testing.T) {
+	d := &Delta{}
+	assert.NotNil(t, d.Unmarshal(nil))
+	assert.NotNil(t, d.Unmarshal(make([]byte, 8)))
+	assert.NotNil(t, d.Unmarshal([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}))
+
+	_, err := NewDelta([]*PublicKey{}, []*PublicKey{}, nil, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,68 @@
+package bls
+
+import (
+	"encoding/binary"
+
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+)
+
+// epochDomain separates messages bound to a validity window from plain ones
+const epochDomain = "dusk.bls.epoch"
+
+// Validity is the window in which a signature is accepted: from Epoch, the
+// height or time it was produced at, up to and including Expiry. Both are
+// in the caller's units, block heights or seconds, as long as the signer
+// and the verifier agree on them
+type Validity struct {
+	Epoch  uint64
+	Expiry uint64
+}
+
+// Check returns an error unless now lies within the window
+func (v Validity) Check(now uint64) error {
+	if v.Expiry < v.Epoch {
+		return cryptoerrors.Newf(cryptoerrors.ErrOutOfRange,
+			"bls: expiry %d precedes epoch %d", v.Expiry, v.Epoch)
+	}
+	if now < v.Epoch {
+		return cryptoerrors.Newf(cryptoerrors.ErrOutOfRange,
+			"bls: signature is not valid before %d, now is %d", v.Epoch, now)
+	}
+	if now > v.Expiry {
+		return cryptoerrors.Newf(cryptoerrors.ErrOutOfRange,
+			"bls: signature expired at %d, now is %d", v.Expiry, now)
+	}
+	return nil
+}
+
+// EpochMessage returns the message actually signed for msg within the
+// window v. Signers of the same vote must agree on v for their signatures
+// to aggregate
+func EpochMessage(msg []byte, v Validity) []byte {
+	buf := make([]byte, 0, len(epochDomain)+16+len(msg))
+	buf = append(buf, epochDomain...)
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[0:8], v.Epoch)
+	binary.BigEndian.PutUint64(b[8:16], v.Expiry)
+	buf = append(buf, b[:]...)
+	return append(buf, msg...)
+}
+
+// SignEpoch signs msg bound to the validity window v
+func SignEpoch(sk *SecretKey, pk *PublicKey, msg []byte, v Validity) (*Signature, error) {
+	if v.Expiry < v.Epoch {
+		return nil, cryptoerrors.Newf(cryptoerrors.ErrOutOfRange,
+			"bls: expiry %d precedes epoch %d", v.Expiry, v.Epoch)
+	}
+	return Sign(sk, pk, EpochMessage(msg, v))
+}
+
+// VerifyEpoch verifies a signature produced by SignEpoch, and rejects it
+// unless now, the current height or time, lies within the window v. The
+// window is checked first, so that stale votes cost no pairing
+func VerifyEpoch(apk *Apk, msg []byte, v Validity, sigma *Signature, now uint64) error {
+	if err := v.Check(now); err != nil {
+		return err
+	}
+	return Verify(apk, EpochMessage(msg, v), sigma)
+}
@@ -0,0 +1,46 @@
+package bls
+
+import (
+	"testing"
+
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEpochSignature(t *testing.T) {
+	pk1, sk1, err := GenKeyPair(nil)
+	require.Nil(t, err)
+	pk2, sk2, err := GenKeyPair(nil)
+	require.Nil(t, err)
+	msg := randomMessage()
+	v := Validity{Epoch: 100, Expiry: 110}
+
+	s1, err := SignEpoch(sk1, pk1, msg, v)
+	require.Nil(t, err)
+	s2, err := SignEpoch(sk2, pk2, msg, v)
+	require.Nil(t, err)
+	sig := s1.Aggregate(s2)
+	apk, err := AggregateApk([]*PublicKey{pk1, pk2})
+	require.Nil(t, err)
+
+	for _, now := range []uint64{100, 105, 110} {
+		assert.Nil(t, VerifyEpoch(apk, msg, v, sig, now))
+	}
+	for _, now := range []uint64{0, 99, 111} {
+		err := VerifyEpoch(apk, msg, v, sig, now)
+		assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrOutOfRange))
+	}
+
+	// the window is bound to the signature
+	err = VerifyEpoch(apk, msg, Validity{Epoch: 100, Expiry: 200}, sig, 150)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrVerificationFailed))
+
+	// and a plain signature of the message is not an epoch signature
+	plain, err := Sign(sk1, pk1, msg)
+	require.Nil(t, err)
+	assert.NotNil(t, VerifyEpoch(NewApk(pk1), msg, v, plain, 105))
+
+	_, err = SignEpoch(sk1, pk1, msg, Validity{Epoch: 10, Expiry: 9})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,420 @@
+package bls
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/ct"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// Forward secure signatures, after Pixel (Drijvers et al.), which builds on
+// the hierarchical identity based encryption of Boneh, Boyen and Goh. The
+// lifetime of a key is cut into 2^depth epochs, the leaves of a binary tree,
+// and the secret key of epoch t only holds the keys of the leaf t and of the
+// subtrees right of its path. Updating the key derives the keys of the next
+// epoch and erases the others, so that a key compromised at epoch t cannot
+// produce signatures for the epochs before t: finalized history cannot be
+// rewritten with a stolen validator key. The public key is a plain BLS
+// PublicKey, g2^x.
+//
+// A node w = w_1...w_k of the tree has the key
+//
+//	(g2^r, h^x * F(w)^r, h_{k+1}^r, ..., h_{depth+1}^r)
+//
+// where F(w) = h_0 * prod(h_i^(w_i+1)), for fresh randomness r. The key of a
+// node derives the keys of its children, and the key of a leaf signs, with
+// h_{depth+1} as the base of the hash of the message. All the h are hashed to
+// G1, so that nobody knows their discrete logs
+
+// MaxForwardDepth bounds the depth of the tree of epochs of a
+// ForwardSecretKey
+const MaxForwardDepth = 32
+
+// forwardSignatureSize is the size of a marshaled ForwardSignature
+const forwardSignatureSize = publicKeySize + signatureSize
+
+var (
+	// forwardH is h, the base of the secret key
+	forwardH = hash.HashToG1("dusk.bls.forward.h")
+	// forwardH0 is h_0, the base of F
+	forwardH0 = hash.HashToG1("dusk.bls.forward.h0")
+	// forwardLevels holds h_1 to h_MaxForwardDepth, the bases of the levels
+	// of the tree
+	forwardLevels = func() []*bn256.G1 {
+		res := make([]*bn256.G1, MaxForwardDepth)
+		var idx [4]byte
+		for i := range res {
+			binary.BigEndian.PutUint32(idx[:], uint32(i+1))
+			res[i] = hash.HashToG1("dusk.bls.forward.level", idx[:])
+		}
+		return res
+	}()
+	// forwardMsg is the base of the hash of the message, h_{depth+1}
+	forwardMsg = hash.HashToG1("dusk.bls.forward.msg")
+)
+
+// forwardBase returns h_j, for j from 1 to depth+1
+func forwardBase(depth, j int) *bn256.G1 {
+	if j > depth {
+		return forwardMsg
+	}
+	return forwardLevels[j-1]
+}
+
+// forwardF returns F(w) for the node at level whose path, read from the most
+// significant bit, is path
+func forwardF(depth, level int, path uint64) *bn256.G1 {
+	res := newG1().Set(forwardH0)
+	for i := 1; i <= level; i++ {
+		bit := (path >> uint(level-i)) & 1
+		res.Add(res, newG1().ScalarMult(forwardBase(depth, i), big.NewInt(int64(bit+1))))
+	}
+	return res
+}
+
+// forwardSignBase returns F(epoch) * h_{depth+1}^m, the base of the
+// randomness of the signatures of msg at epoch
+func forwardSignBase(depth int, epoch uint64, msg []byte) *bn256.G1 {
+	f := forwardF(depth, depth, epoch)
+	return f.Add(f, newG1().ScalarMult(forwardMsg, forwardHash(msg)))
+}
+
+// forwardHash returns the hash of msg, the exponent of h_{depth+1}
+func forwardHash(msg []byte) *big.Int {
+	return hash.HashToBN256Scalar("dusk.bls.forward.sign", msg)
+}
+
+func randomScalar(r io.Reader) (*big.Int, error) {
+	if r == nil {
+		r = rng.Reader
+	}
+	return rand.Int(r, bn256.Order)
+}
+
+// forwardNode is the key of the node at level whose path is path. e[i] is
+// h_{level+1+i}^r
+type forwardNode struct {
+	level int
+	path  uint64
+	c     *bn256.G2
+	d     *bn256.G1
+	e     []*bn256.G1
+}
+
+// child derives the key of the child of n on the side of bit
+func (n *forwardNode) child(depth int, bit uint64, r io.Reader) (*forwardNode, error) {
+	s, err := randomScalar(r)
+	if err != nil {
+		return nil, err
+	}
+
+	child := &forwardNode{
+		level: n.level + 1,
+		path:  n.path<<1 | bit,
+		c:     newG2().Add(n.c, newG2().ScalarBaseMult(s)),
+		e:     make([]*bn256.G1, len(n.e)-1),
+	}
+	f := forwardF(depth, child.level, child.path)
+	child.d = newG1().ScalarMult(n.e[0], big.NewInt(int64(bit+1)))
+	child.d.Add(child.d, n.d)
+	child.d.Add(child.d, f.ScalarMult(f, s))
+	for i := range child.e {
+		child.e[i] = newG1().ScalarMult(forwardBase(depth, child.level+1+i), s)
+		child.e[i].Add(child.e[i], n.e[i+1])
+	}
+	return child, nil
+}
+
+// covers returns true if epoch is a leaf below n
+func (n *forwardNode) covers(depth int, epoch uint64) bool {
+	return epoch>>uint(depth-n.level) == n.path
+}
+
+// wipe overwrites the points of the key with the identity, the memory of
+// the results of ScalarBaseMult being reused
+func (n *forwardNode) wipe() {
+	zero := new(big.Int)
+	n.c.ScalarBaseMult(zero)
+	n.d.ScalarBaseMult(zero)
+	for _, e := range n.e {
+		e.ScalarBaseMult(zero)
+	}
+}
+
+// ForwardSecretKey is the evolving secret key of a forward secure signer. It
+// is not safe for concurrent use
+type ForwardSecretKey struct {
+	depth int
+	epoch uint64
+	// nodes is a stack of keys, whose top is the key of the leaf of the
+	// current epoch. The subtrees of the nodes are disjoint and cover the
+	// epochs from the current to the last, from the top down
+	nodes []*forwardNode
+}
+
+// ForwardSignature is a signature produced by a ForwardSecretKey at an epoch
+type ForwardSignature struct {
+	s1 *bn256.G2
+	s2 *bn256.G1
+}
+
+// GenForwardKeyPair generates a key pair valid for 2^depth epochs, starting
+// at epoch 0. The plain secret key x is erased once the key of the root of
+// the tree is derived, since it signs for every epoch
+func GenForwardKeyPair(depth int, randReader io.Reader) (*PublicKey, *ForwardSecretKey, error) {
+	if depth < 1 || depth > MaxForwardDepth {
+		return nil, nil, cryptoerrors.Newf(cryptoerrors.ErrOutOfRange,
+			"bls: forward key depth must be between 1 and %d", MaxForwardDepth)
+	}
+
+	pk, sk, err := GenKeyPair(randReader)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer sk.Destroy()
+
+	r, err := randomScalar(randReader)
+	if err != nil {
+		return nil, nil, err
+	}
+	root := &forwardNode{
+		c: newG2().ScalarBaseMult(r),
+		d: sk.g1Mult(forwardH),
+		e: make([]*bn256.G1, depth+1),
+	}
+	root.d.Add(root.d, newG1().ScalarMult(forwardH0, r))
+	for i := range root.e {
+		root.e[i] = newG1().ScalarMult(forwardBase(depth, i+1), r)
+	}
+
+	fsk := &ForwardSecretKey{depth: depth, nodes: []*forwardNode{root}}
+	if err := fsk.descend(0, randReader); err != nil {
+		return nil, nil, err
+	}
+	return pk, fsk, nil
+}
+
+// Depth returns the depth of the tree of epochs of the key
+func (fsk *ForwardSecretKey) Depth() int {
+	return fsk.depth
+}
+
+// Epoch returns the current epoch of the key
+func (fsk *ForwardSecretKey) Epoch() uint64 {
+	return fsk.epoch
+}
+
+// Update moves the key to the next epoch
+func (fsk *ForwardSecretKey) Update(randReader io.Reader) error {
+	return fsk.UpdateTo(fsk.epoch+1, randReader)
+}
+
+// UpdateTo moves the key forward to epoch, skipping the epochs in between.
+// The keys of the epochs before epoch are erased
+func (fsk *ForwardSecretKey) UpdateTo(epoch uint64, randReader io.Reader) error {
+	if epoch <= fsk.epoch {
+		return cryptoerrors.Newf(cryptoerrors.ErrOutOfRange,
+			"bls: cannot update a forward key from epoch %d back to %d", fsk.epoch, epoch)
+	}
+	if epoch>>uint(fsk.depth) != 0 {
+		return cryptoerrors.Newf(cryptoerrors.ErrOutOfRange,
+			"bls: epoch %d is past the last epoch of the key", epoch)
+	}
+
+	// the nodes above the one covering epoch only cover earlier epochs
+	for !fsk.nodes[len(fsk.nodes)-1].covers(fsk.depth, epoch) {
+		fsk.pop().wipe()
+	}
+	if err := fsk.descend(epoch, randReader); err != nil {
+		return err
+	}
+	fsk.epoch = epoch
+	return nil
+}
+
+func (fsk *ForwardSecretKey) pop() *forwardNode {
+	n := fsk.nodes[len(fsk.nodes)-1]
+	fsk.nodes = fsk.nodes[:len(fsk.nodes)-1]
+	return n
+}
+
+// descend replaces the top of the stack, which covers epoch, with the leaf
+// of epoch and the right siblings of its path
+func (fsk *ForwardSecretKey) descend(epoch uint64, randReader io.Reader) error {
+	for fsk.nodes[len(fsk.nodes)-1].level < fsk.depth {
+		n := fsk.nodes[len(fsk.nodes)-1]
+		bit := (epoch >> uint(fsk.depth-n.level-1)) & 1
+
+		children := make([]*forwardNode, 0, 2)
+		right, err := n.child(fsk.depth, 1, randReader)
+		if err != nil {
+			return err
+		}
+		children = append(children, right)
+		if bit == 0 {
+			left, err := n.child(fsk.depth, 0, randReader)
+			if err != nil {
+				return err
+			}
+			children = append(children, left)
+		}
+
+		fsk.pop().wipe()
+		fsk.nodes = append(fsk.nodes, children...)
+	}
+	return nil
+}
+
+// Sign signs msg for the current epoch of the key
+func (fsk *ForwardSecretKey) Sign(msg []byte, randReader io.Reader) (*ForwardSignature, error) {
+	leaf := fsk.nodes[len(fsk.nodes)-1]
+	if leaf.level != fsk.depth {
+		// an update failed half way
+		return nil, cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: forward key is not at a leaf")
+	}
+	// the signature is re-randomized, as two signatures sharing the
+	// randomness of the leaf would reveal its key
+	s, err := randomScalar(randReader)
+	if err != nil {
+		return nil, err
+	}
+	m := forwardHash(msg)
+
+	// the key of the leaf extended with the message, at a new randomness
+	f := forwardSignBase(fsk.depth, leaf.path, msg)
+	s2 := newG1().ScalarMult(leaf.e[0], m)
+	s2.Add(s2, leaf.d)
+	s2.Add(s2, f.ScalarMult(f, s))
+
+	return &ForwardSignature{
+		s1: newG2().Add(leaf.c, newG2().ScalarBaseMult(s)),
+		s2: s2,
+	}, nil
+}
+
+// VerifyForward checks a signature of msg at epoch, by the key pk with a tree
+// of the given depth
+func VerifyForward(pk *PublicKey, depth int, epoch uint64, msg []byte, sig *ForwardSignature) error {
+	if depth < 1 || depth > MaxForwardDepth || epoch>>uint(depth) != 0 {
+		return cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: invalid forward signature epoch")
+	}
+
+	// e(s2, g2) = e(h, pk) * e(F(epoch) * h_{depth+1}^m, s1)
+	f := forwardSignBase(depth, epoch, msg)
+	rhs := bn256.Pair(forwardH, pk.gx)
+	rhs.Add(rhs, bn256.Pair(f, sig.s1))
+	if !ct.Equal(bn256.Pair(sig.s2, g2Base).Marshal(), rhs.Marshal()) {
+		return cryptoerrors.New(cryptoerrors.ErrVerificationFailed, "bls: invalid forward signature")
+	}
+	return nil
+}
+
+// Marshal a ForwardSignature: the G2 point, then the G1 point
+func (sig *ForwardSignature) Marshal() []byte {
+	return append(sig.s1.Marshal(), sig.s2.Marshal()...)
+}
+
+// Unmarshal a ForwardSignature
+func (sig *ForwardSignature) Unmarshal(b []byte) error {
+	if len(b) != forwardSignatureSize {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: invalid forward signature length")
+	}
+	s1, err := unmarshalG2(b[:publicKeySize], "forward signature")
+	if err != nil {
+		return err
+	}
+	s2, err := unmarshalG1(b[publicKeySize:], "forward signature")
+	if err != nil {
+		return err
+	}
+	sig.s1, sig.s2 = s1, s2
+	return nil
+}
+
+// Marshal a ForwardSecretKey: the depth and the number of nodes as bytes,
+// the epoch as big endian uint64, then every node from the bottom of the
+// stack up, as its level, its path as big endian uint64 and its points. The
+// result must be stored as safely as the key, and the copies of past epochs
+// erased
+func (fsk *ForwardSecretKey) Marshal() []byte {
+	buf := make([]byte, 10)
+	buf[0] = byte(fsk.depth)
+	buf[1] = byte(len(fsk.nodes))
+	binary.BigEndian.PutUint64(buf[2:10], fsk.epoch)
+	for _, n := range fsk.nodes {
+		var hdr [9]byte
+		hdr[0] = byte(n.level)
+		binary.BigEndian.PutUint64(hdr[1:], n.path)
+		buf = append(buf, hdr[:]...)
+		buf = append(buf, n.c.Marshal()...)
+		buf = append(buf, n.d.Marshal()...)
+		for _, e := range n.e {
+			buf = append(buf, e.Marshal()...)
+		}
+	}
+	return buf
+}
+
+// Unmarshal a ForwardSecretKey
+func (fsk *ForwardSecretKey) Unmarshal(b []byte) error {
+	if len(b) < 10 {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: forward key is too short")
+	}
+	depth, count := int(b[0]), int(b[1])
+	epoch := binary.BigEndian.Uint64(b[2:10])
+	if depth < 1 || depth > MaxForwardDepth || epoch>>uint(depth) != 0 {
+		return cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: invalid forward key epoch")
+	}
+	if count < 1 || count > depth+1 {
+		return cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: invalid number of forward key nodes")
+	}
+
+	nodes := make([]*forwardNode, count)
+	b = b[10:]
+	for i := range nodes {
+		if len(b) < 9+publicKeySize+signatureSize {
+			return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: forward key is too short")
+		}
+		n := &forwardNode{level: int(b[0]), path: binary.BigEndian.Uint64(b[1:9])}
+		if n.level > depth || n.path>>uint(n.level) != 0 {
+			return cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: invalid forward key node")
+		}
+		b = b[9:]
+		size := publicKeySize + (depth+2-n.level)*signatureSize
+		if len(b) < size {
+			return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: forward key is too short")
+		}
+
+		var err error
+		if n.c, err = unmarshalG2(b[:publicKeySize], "forward key"); err != nil {
+			return err
+		}
+		b = b[publicKeySize:]
+		points := make([]*bn256.G1, depth+2-n.level)
+		for j := range points {
+			if points[j], err = unmarshalG1(b[:signatureSize], "forward key"); err != nil {
+				return err
+			}
+			b = b[signatureSize:]
+		}
+		n.d, n.e = points[0], points[1:]
+		nodes[i] = n
+	}
+	if len(b) != 0 {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: invalid forward key length")
+	}
+
+	top := nodes[count-1]
+	if top.level != depth || top.path != epoch {
+		return cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: forward key does not hold the key of its epoch")
+	}
+
+	fsk.depth, fsk.epoch, fsk.nodes = depth, epoch, nodes
+	return nil
+}
@@ -0,0 +1,123 @@
+package bls
+
+import (
+	"testing"
+
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardSignature(t *testing.T) {
+	const depth = 3
+	pk, sk, err := GenForwardKeyPair(depth, nil)
+	require.Nil(t, err)
+	msg := randomMessage()
+
+	for epoch := uint64(0); epoch < 1<<depth; epoch++ {
+		require.Equal(t, epoch, sk.Epoch())
+		sig, err := sk.Sign(msg, nil)
+		require.Nil(t, err)
+		assert.Nil(t, VerifyForward(pk, depth, epoch, msg, sig))
+
+		// the signature is bound to its epoch and its message
+		err = VerifyForward(pk, depth, epoch^1, msg, sig)
+		assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrVerificationFailed))
+		err = VerifyForward(pk, depth, epoch, randomMessage(), sig)
+		assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrVerificationFailed))
+
+		if epoch < 1<<depth-1 {
+			require.Nil(t, sk.Update(nil))
+		}
+	}
+
+	// the key is exhausted, and never goes back
+	assert.NotNil(t, sk.Update(nil))
+	assert.NotNil(t, sk.UpdateTo(2, nil))
+}
+
+func TestForwardSecurity(t *testing.T) {
+	const depth = 4
+	pk, sk, err := GenForwardKeyPair(depth, nil)
+	require.Nil(t, err)
+	require.Nil(t, sk.UpdateTo(9, nil))
+	msg := randomMessage()
+
+	// a key stolen at epoch 9 holds the keys of epochs 9 to 15 only
+	assert.Equal(t, 3, len(sk.nodes))
+	for _, n := range sk.nodes {
+		first := n.path << uint(depth-n.level)
+		assert.True(t, first >= 9)
+	}
+
+	sig, err := sk.Sign(msg, nil)
+	require.Nil(t, err)
+	for epoch := uint64(0); epoch < 9; epoch++ {
+		err := VerifyForward(pk, depth, epoch, msg, sig)
+		assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrVerificationFailed))
+	}
+
+	// skipping epochs works the same as updating through them
+	require.Nil(t, sk.UpdateTo(14, nil))
+	sig, err = sk.Sign(msg, nil)
+	require.Nil(t, err)
+	assert.Nil(t, VerifyForward(pk, depth, 14, msg, sig))
+}
+
+func TestForwardMarshal(t *testing.T) {
+	const depth = 5
+	pk, sk, err := GenForwardKeyPair(depth, nil)
+	require.Nil(t, err)
+	require.Nil(t, sk.UpdateTo(11, nil))
+
+	other := &ForwardSecretKey{}
+	require.Nil(t, other.Unmarshal(sk.Marshal()))
+	assert.Equal(t, uint64(11), other.Epoch())
+	assert.Equal(t, depth, other.Depth())
+
+	msg := randomMessage()
+	require.Nil(t, other.Update(nil))
+	sig, err := other.Sign(msg, nil)
+	require.Nil(t, err)
+
+	dec := &ForwardSignature{}
+	require.Nil(t, dec.Unmarshal(sig.Marshal()))
+	assert.Nil(t, VerifyForward(pk, depth, 12, msg, dec))
+
+	b := sk.Marshal()
+	assert.NotNil(t, other.Unmarshal(b[:len(b)-1]))
+	assert.NotNil(t, dec.Unmarshal(sig.Marshal()[1:]))
+}
+
+func TestForwardNonCanonical(t *testing.T) {
+	_, sk, err := GenForwardKeyPair(3, nil)
+	require.Nil(t, err)
+	sig, err := sk.Sign(randomMessage(), nil)
+	require.Nil(t, err)
+
+	// the point at infinity, encoded as an affine point and as its single
+	// byte followed by anything, in place of a G2 point
+	affine := make([]byte, publicKeySize)
+	affine[0] = 1
+	padded := make([]byte, publicKeySize)
+	padded[1] = 1
+	for _, inf := range [][]byte{affine, padded} {
+		b := sig.Marshal()
+		copy(b, inf)
+		err = (&ForwardSignature{}).Unmarshal(b)
+		assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrNotCanonical))
+
+		// the G2 point of the first node follows the key and node headers
+		b = sk.Marshal()
+		copy(b[19:], inf)
+		err = (&ForwardSecretKey{}).Unmarshal(b)
+		assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrNotCanonical))
+	}
+}
+
+func TestForwardKeyDepth(t *testing.T) {
+	_, _, err := GenForwardKeyPair(0, nil)
+	assert.NotNil(t, err)
+	_, _, err = GenForwardKeyPair(MaxForwardDepth+1, nil)
+	assert.NotNil(t, err)
+}
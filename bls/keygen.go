@@ -0,0 +1,146 @@
+package bls
+
+import (
+	"io"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/ct"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// MaxKeyPairs bounds the number of key pairs of a batch
+const MaxKeyPairs = 1 << 20
+
+// g2TableThreshold is the size of the batches from which the public keys
+// are computed from g2Table rather than with ScalarBaseMult. Building the
+// table costs about as much as a dozen scalar multiplications, and every
+// key then costs 64 additions
+const g2TableThreshold = 16
+
+var (
+	g2TableOnce sync.Once
+	// g2Table holds the encoding of (j * 16^i + c) * g2Base at [i][j]. The
+	// offset c keeps the entries away from the point at infinity, so that
+	// every window adds a point, whatever its digit. Since c is a hash, the
+	// running sum does not meet the added point or its opposite either,
+	// which the incomplete addition of bn256 does not handle
+	g2Table [64][16][]byte
+	// g2TableOffset is -64c * g2Base, which cancels the offsets of the
+	// 64 windows
+	g2TableOffset *bn256.G2
+)
+
+func buildG2Table() {
+	c := hash.HashToBN256Scalar("dusk.bls.g2table")
+	offset := newG2().ScalarMult(g2Base, c)
+	negOffset := newG2().Neg(offset)
+	// base is 16^i * g2Base
+	base := newG2().Set(g2Base)
+	for i := range g2Table {
+		p := newG2().Set(offset)
+		for j := range g2Table[i] {
+			g2Table[i][j] = p.Marshal()
+			p = newG2().Add(p, base)
+		}
+		// p is 16 * base + offset
+		base = newG2().Add(p, negOffset)
+	}
+	c.Mul(c, big.NewInt(64))
+	g2TableOffset = newG2().Neg(newG2().ScalarMult(g2Base, c))
+}
+
+// g2BaseMult returns x * g2Base from g2Table, for 0 < x < Order. The entry of
+// every window is selected with a scan of its whole row, and added whatever
+// the digit, so that neither the memory accesses nor the sequence of
+// additions depend on the secret digits
+func g2BaseMult(x *big.Int) *bn256.G2 {
+	res := newG2().Set(g2TableOffset)
+	entry := make([]byte, publicKeySize)
+	for i := range g2Table {
+		var j int
+		for b := 3; b >= 0; b-- {
+			j = j<<1 | int(x.Bit(4*i+b))
+		}
+		ct.LookupBytes(entry, g2Table[i][:], j)
+		// bn256 decodes by adding to the coordinates, so the point is fresh
+		p := newG2()
+		if _, err := p.Unmarshal(entry); err != nil {
+			panic("bls: corrupted g2 table")
+		}
+		res.Add(res, p)
+	}
+	for i := range entry {
+		entry[i] = 0
+	}
+	return res
+}
+
+// GenKeyPairs generates n key pairs, like n calls to GenKeyPair but faster:
+// the randomness of all the keys is read at once, and large batches share a
+// table of multiples of the G2 base
+func GenKeyPairs(randReader io.Reader, n int) ([]*PublicKey, []*SecretKey, error) {
+	return GenKeyPairsParallel(randReader, n, 1)
+}
+
+// GenKeyPairsParallel is GenKeyPairs over workers goroutines, or
+// runtime.NumCPU() if workers is not positive. The keys only depend on the
+// randomness read, not on the number of workers
+func GenKeyPairsParallel(randReader io.Reader, n, workers int) ([]*PublicKey, []*SecretKey, error) {
+	if n < 1 || n > MaxKeyPairs {
+		return nil, nil, cryptoerrors.Newf(cryptoerrors.ErrOutOfRange,
+			"bls: number of key pairs must be between 1 and %d", MaxKeyPairs)
+	}
+
+	// 64 bytes per key make the bias of the reduction negligible
+	buf := make([]byte, 64*n)
+	if _, err := io.ReadFull(rng.Or(randReader), buf); err != nil {
+		return nil, nil, err
+	}
+	xs := make([]*big.Int, n)
+	orderMinusOne := new(big.Int).Sub(bn256.Order, big.NewInt(1))
+	for i := range xs {
+		// x is in [1, Order)
+		xs[i] = new(big.Int).SetBytes(buf[64*i : 64*(i+1)])
+		xs[i].Mod(xs[i], orderMinusOne).Add(xs[i], big.NewInt(1))
+	}
+	for i := range buf {
+		buf[i] = 0
+	}
+
+	useTable := n >= g2TableThreshold
+	if useTable {
+		g2TableOnce.Do(buildG2Table)
+	}
+
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+
+	pks := make([]*PublicKey, n)
+	sks := make([]*SecretKey, n)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < n; i += workers {
+				if useTable {
+					pks[i] = &PublicKey{g2BaseMult(xs[i])}
+				} else {
+					pks[i] = &PublicKey{newG2().ScalarMult(g2Base, xs[i])}
+				}
+				sks[i] = newSecretKey(xs[i])
+			}
+		}(w)
+	}
+	wg.Wait()
+	return pks, sks, nil
+}
@@ -0,0 +1,77 @@
+package bls
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenKeyPairs(t *testing.T) {
+	// below and above the size from which the table is used
+	for _, n := range []int{3, g2TableThreshold + 5} {
+		pks, sks, err := GenKeyPairs(rng.Deterministic([]byte("keys")), n)
+		require.Nil(t, err)
+		require.Equal(t, n, len(pks))
+
+		for i := range pks {
+			assert.Equal(t, sks[i].PublicKey().Marshal(), pks[i].Marshal())
+		}
+
+		msg := randomMessage()
+		sig, err := UnsafeSign(sks[n-1], msg)
+		require.Nil(t, err)
+		assert.Nil(t, VerifyUnsafe(pks[n-1], msg, sig))
+
+		// the keys do not depend on the number of workers
+		ppks, psks, err := GenKeyPairsParallel(rng.Deterministic([]byte("keys")), n, 0)
+		require.Nil(t, err)
+		for i := range pks {
+			assert.Equal(t, pks[i].Marshal(), ppks[i].Marshal())
+			assert.Equal(t, sks[i].Marshal(), psks[i].Marshal())
+		}
+	}
+
+	_, _, err := GenKeyPairs(nil, 0)
+	assert.NotNil(t, err)
+}
+
+func TestG2BaseMult(t *testing.T) {
+	g2TableOnce.Do(buildG2Table)
+
+	// windows of zero digits still add a point from the table
+	xs := []*big.Int{
+		big.NewInt(1),
+		big.NewInt(16),
+		// with offsets of g2Base, the running sum would meet the added point
+		big.NewInt(63),
+		new(big.Int).Lsh(big.NewInt(15), 4*63),
+		new(big.Int).Sub(bn256.Order, big.NewInt(1)),
+		randomInt(rand.Reader),
+	}
+	for _, x := range xs {
+		assert.Equal(t, newG2().ScalarMult(g2Base, x).Marshal(), g2BaseMult(x).Marshal())
+	}
+}
+
+func BenchmarkGenKeyPairs(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, _, err := GenKeyPairs(nil, 100); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGenKeyPair100(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 100; j++ {
+			if _, _, err := GenKeyPair(nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
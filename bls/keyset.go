@@ -0,0 +1,85 @@
+package bls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/hash"
+)
+
+// Canonical hashing of sets of public keys, to identify committees. Every
+// implementation must compute the same digest for the same set, whatever
+// the order it holds the keys in:
+//
+//  1. the keys are sorted by their encodings, Marshal, bytewise
+//  2. the leaves are Blake2b256WithDomain("dusk.bls.keyset.leaf", key)
+//  3. each level pairs its nodes into
+//     Blake2b256WithDomain("dusk.bls.keyset.node", left, right), and an odd
+//     node at the end of a level moves up unchanged, until one node is left
+//  4. the digest is Blake2b256WithDomain("dusk.bls.keyset", n, root), where
+//     n is the number of keys as a big endian uint64
+//
+// Blake2b256WithDomain length prefixes the domain and every input, see the
+// hash package
+
+// SortKeys returns the keys sorted in the canonical order of HashKeys. The
+// slice passed is left untouched
+func SortKeys(pks []*PublicKey) []*PublicKey {
+	sorted := append([]*PublicKey{}, pks...)
+	encoded := make(map[*PublicKey][]byte, len(pks))
+	for _, pk := range sorted {
+		encoded[pk] = pk.Marshal()
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return bytes.Compare(encoded[sorted[i]], encoded[sorted[j]]) < 0
+	})
+	return sorted
+}
+
+// HashKeys returns the canonical digest of a set of public keys. The set
+// must not be empty, nor hold the identity or the same key twice
+func HashKeys(pks []*PublicKey) ([32]byte, error) {
+	var res [32]byte
+	if len(pks) == 0 {
+		return res, cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: empty key set")
+	}
+
+	encoded := make([][]byte, len(pks))
+	for i, pk := range pks {
+		if pk == nil || pk.gx == nil {
+			return res, cryptoerrors.New(cryptoerrors.ErrMalformedPoint, "bls: nil key in set")
+		}
+		if isIdentityG2(pk.gx) {
+			return res, cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: public key is the identity")
+		}
+		encoded[i] = pk.Marshal()
+	}
+	sort.Slice(encoded, func(i, j int) bool {
+		return bytes.Compare(encoded[i], encoded[j]) < 0
+	})
+
+	level := make([][]byte, len(encoded))
+	for i, e := range encoded {
+		if i > 0 && bytes.Equal(e, encoded[i-1]) {
+			return res, cryptoerrors.New(cryptoerrors.ErrNotCanonical, "bls: duplicate key in set")
+		}
+		level[i] = hash.Blake2b256WithDomain("dusk.bls.keyset.leaf", e)
+	}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i+1 < len(level); i += 2 {
+			next = append(next, hash.Blake2b256WithDomain("dusk.bls.keyset.node", level[i], level[i+1]))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		level = next
+	}
+
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(pks)))
+	copy(res[:], hash.Blake2b256WithDomain("dusk.bls.keyset", n[:], level[0]))
+	return res, nil
+}
@@ -0,0 +1,105 @@
+package bls
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomKeys(t *testing.T, n int) []*PublicKey {
+	pks := make([]*PublicKey, n)
+	for i := range pks {
+		pk, _, err := GenKeyPair(nil)
+		require.Nil(t, err)
+		pks[i] = pk
+	}
+	return pks
+}
+
+func TestHashKeysOrder(t *testing.T) {
+	pks := randomKeys(t, 7)
+	h, err := HashKeys(pks)
+	require.Nil(t, err)
+
+	// any order hashes the same
+	reversed := make([]*PublicKey, len(pks))
+	for i, pk := range pks {
+		reversed[len(pks)-1-i] = pk
+	}
+	r, err := HashKeys(reversed)
+	require.Nil(t, err)
+	assert.Equal(t, h, r)
+
+	sorted := SortKeys(reversed)
+	for i := 1; i < len(sorted); i++ {
+		assert.True(t, bytes.Compare(sorted[i-1].Marshal(), sorted[i].Marshal()) < 0)
+	}
+	assert.Equal(t, pks[0], reversed[len(pks)-1])
+
+	// a subset, or a superset, hashes differently
+	sub, err := HashKeys(pks[1:])
+	require.Nil(t, err)
+	assert.NotEqual(t, h, sub)
+	super, err := HashKeys(append(randomKeys(t, 1), pks...))
+	require.Nil(t, err)
+	assert.NotEqual(t, h, super)
+}
+
+func TestHashKeysConstruction(t *testing.T) {
+	pks := SortKeys(randomKeys(t, 3))
+	leaf := func(pk *PublicKey) []byte {
+		return hash.Blake2b256WithDomain("dusk.bls.keyset.leaf", pk.Marshal())
+	}
+
+	// three leaves: the last moves up unchanged
+	node := hash.Blake2b256WithDomain("dusk.bls.keyset.node", leaf(pks[0]), leaf(pks[1]))
+	root := hash.Blake2b256WithDomain("dusk.bls.keyset.node", node, leaf(pks[2]))
+	want := hash.Blake2b256WithDomain("dusk.bls.keyset", []byte{0, 0, 0, 0, 0, 0, 0, 3}, root)
+
+	got, err := HashKeys(pks)
+	require.Nil(t, err)
+	assert.Equal(t, want, got[:])
+
+	single, err := HashKeys(pks[:1])
+	require.Nil(t, err)
+	want = hash.Blake2b256WithDomain("dusk.bls.keyset", []byte{0, 0, 0, 0, 0, 0, 0, 1}, leaf(pks[0]))
+	assert.Equal(t, want, single[:])
+}
+
+// TestHashKeysVector pins the digest of the keys of the secret keys 1, 2
+// and 3, for other implementations to check against
+func TestHashKeysVector(t *testing.T) {
+	pks := make([]*PublicKey, 3)
+	for i := range pks {
+		pks[i] = newSecretKey(big.NewInt(int64(i + 1))).PublicKey()
+	}
+	h, err := HashKeys(pks)
+	require.Nil(t, err)
+	assert.Equal(t, "c17513ae626e68cd0ea94ff6ebd6fd42f25d6d2311eb2816cddca445b5f299d4", hex.EncodeToString(h[:]))
+}
+
+func TestHashKeysErrors(t *testing.T) {
+	_, err := HashKeys(nil)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrInvalidLength))
+
+	pks := randomKeys(t, 2)
+	_, err = HashKeys(append(pks, pks[0]))
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrNotCanonical))
+
+	// the same key in another struct is still a duplicate
+	dup := &PublicKey{}
+	require.Nil(t, dup.Unmarshal(pks[1].Marshal()))
+	_, err = HashKeys(append(pks, dup))
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrNotCanonical))
+
+	_, err = HashKeys([]*PublicKey{pks[0], nil})
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrMalformedPoint))
+	_, err = HashKeys([]*PublicKey{{newG2().ScalarBaseMult(big.NewInt(0))}})
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrOutOfRange))
+}
@@ -0,0 +1,82 @@
+package bls
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/dusk-network/bn256"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+)
+
+// Re-randomization, where the schemes permit it. A plain BLS signature
+// e(sig, g2) = e(H(msg), pk) stays valid when both the signature and the key
+// are raised to the same t: a relayer re-randomizes them together, so that
+// the key and the signature seen at two observation points cannot be
+// linked, and hands t over to the owner of the key, whose secret key for
+// pk^t is x * t. Signature and UniqueSignature hash the key, so they cannot
+// be re-randomized this way. A ForwardSignature re-randomizes on its own,
+// under the same key, like Sign does.
+
+// randomNonZeroScalar returns a scalar in [1, Order)
+func randomNonZeroScalar(r io.Reader) (*big.Int, error) {
+	for {
+		t, err := randomScalar(r)
+		if err != nil {
+			return nil, err
+		}
+		if t.Sign() != 0 {
+			return t, nil
+		}
+	}
+}
+
+// RerandomizeUnsafe returns pk^t and sig^t for a random t, and t. The
+// result verifies with VerifyUnsafe whenever sig verifies under pk
+func RerandomizeUnsafe(pk *PublicKey, sig *UnsafeSignature, randReader io.Reader) (*PublicKey, *UnsafeSignature, *big.Int, error) {
+	t, err := randomNonZeroScalar(randReader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rpk, rsig, err := RerandomizeUnsafeWith(pk, sig, t)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return rpk, rsig, t, nil
+}
+
+// RerandomizeUnsafeWith returns pk^t and sig^t
+func RerandomizeUnsafeWith(pk *PublicKey, sig *UnsafeSignature, t *big.Int) (*PublicKey, *UnsafeSignature, error) {
+	if t.Sign() <= 0 || t.Cmp(bn256.Order) >= 0 {
+		return nil, nil, cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: re-randomization scalar out of range")
+	}
+	return &PublicKey{newG2().ScalarMult(pk.gx, t)}, &UnsafeSignature{newG1().ScalarMult(sig.e, t)}, nil
+}
+
+// Rerandomize returns the secret key x * t of the public key re-randomized
+// with t
+func (sk *SecretKey) Rerandomize(t *big.Int) (*SecretKey, error) {
+	if t.Sign() <= 0 || t.Cmp(bn256.Order) >= 0 {
+		return nil, cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: re-randomization scalar out of range")
+	}
+	res := new(big.Int)
+	sk.withScalar(func(x *big.Int) { res.Mul(x, t) })
+	return newSecretKey(res.Mod(res, bn256.Order)), nil
+}
+
+// Rerandomize returns a fresh signature of msg at epoch, unlinkable to sig.
+// It needs no secret: anyone holding a signature can re-randomize it. The
+// result is only valid if sig is
+func (sig *ForwardSignature) Rerandomize(depth int, epoch uint64, msg []byte, randReader io.Reader) (*ForwardSignature, error) {
+	if depth < 1 || depth > MaxForwardDepth || epoch>>uint(depth) != 0 {
+		return nil, cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: invalid forward signature epoch")
+	}
+	s, err := randomScalar(randReader)
+	if err != nil {
+		return nil, err
+	}
+	f := forwardSignBase(depth, epoch, msg)
+	return &ForwardSignature{
+		s1: newG2().Add(sig.s1, newG2().ScalarBaseMult(s)),
+		s2: newG1().Add(sig.s2, f.ScalarMult(f, s)),
+	}, nil
+}
@@ -0,0 +1,67 @@
+package bls
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dusk-network/bn256"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRerandomizeUnsafe(t *testing.T) {
+	pk, sk, err := GenKeyPair(nil)
+	require.Nil(t, err)
+	msg := randomMessage()
+	sig, err := UnsafeSign(sk, msg)
+	require.Nil(t, err)
+
+	rpk, rsig, delta, err := RerandomizeUnsafe(pk, sig, nil)
+	require.Nil(t, err)
+	assert.NotEqual(t, pk.Marshal(), rpk.Marshal())
+	assert.NotEqual(t, sig.Marshal(), rsig.Marshal())
+	assert.Nil(t, VerifyUnsafe(rpk, msg, rsig))
+	assert.NotNil(t, VerifyUnsafe(rpk, randomMessage(), rsig))
+	assert.NotNil(t, VerifyUnsafe(pk, msg, rsig))
+
+	// the owner of the key signs for the re-randomized key
+	rsk, err := sk.Rerandomize(delta)
+	require.Nil(t, err)
+	assert.Equal(t, rpk.Marshal(), rsk.PublicKey().Marshal())
+	other := randomMessage()
+	osig, err := UnsafeSign(rsk, other)
+	require.Nil(t, err)
+	assert.Nil(t, VerifyUnsafe(rpk, other, osig))
+
+	for _, bad := range []*big.Int{big.NewInt(0), big.NewInt(-1), bn256.Order} {
+		_, _, err = RerandomizeUnsafeWith(pk, sig, bad)
+		assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrOutOfRange))
+		_, err = sk.Rerandomize(bad)
+		assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrOutOfRange))
+	}
+}
+
+func TestRerandomizeForward(t *testing.T) {
+	const depth = 3
+	pk, sk, err := GenForwardKeyPair(depth, nil)
+	require.Nil(t, err)
+	require.Nil(t, sk.UpdateTo(5, nil))
+	msg := randomMessage()
+	sig, err := sk.Sign(msg, nil)
+	require.Nil(t, err)
+
+	rsig, err := sig.Rerandomize(depth, 5, msg, nil)
+	require.Nil(t, err)
+	assert.NotEqual(t, sig.Marshal(), rsig.Marshal())
+	assert.Nil(t, VerifyForward(pk, depth, 5, msg, rsig))
+
+	// re-randomizing for another epoch or message does not forge anything
+	wrong, err := sig.Rerandomize(depth, 4, msg, nil)
+	require.Nil(t, err)
+	assert.NotNil(t, VerifyForward(pk, depth, 4, msg, wrong))
+	assert.NotNil(t, VerifyForward(pk, depth, 5, msg, wrong))
+
+	_, err = sig.Rerandomize(depth, 8, msg, nil)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrOutOfRange))
+}
@@ -0,0 +1,128 @@
+package bls
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/dusk-network/bn256"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/shamir"
+	"github.com/pkg/errors"
+)
+
+// GenThresholdKeys generates a group key pair whose secret key is shared
+// among n parties, so that any threshold of them can produce signatures
+// verifiable with the group public key. The i-th secret key share and public
+// key share belong to the party with index i+1.
+// Only the UnsafeSignature of the group key is unique, since the Signature
+// of the plain public key model depends on the public key of the signer
+func GenThresholdKeys(threshold, n int, randReader io.Reader) (*PublicKey, []*PublicKey, []*SecretKey, error) {
+	pk, sk, err := GenKeyPair(randReader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// Only the shares of the group secret key are kept
+	defer sk.Destroy()
+
+	var shares []shamir.Share
+	sk.withScalar(func(x *big.Int) {
+		shares, err = shamir.BN256.Split(x, threshold, n, randReader)
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	pks := make([]*PublicKey, n)
+	sks := make([]*SecretKey, n)
+	for i, s := range shares {
+		pks[i] = &PublicKey{newG2().ScalarMult(g2Base, s.Value)}
+		sks[i] = newSecretKey(s.Value)
+	}
+	return pk, pks, sks, nil
+}
+
+// CombineUnsafe recombines the UnsafeSignatures of the parties with the
+// given indices into the UnsafeSignature of the group key. At least
+// threshold valid signatures on the same message are needed, which
+// CombineUnsafe cannot check
+func CombineUnsafe(indices []uint32, sigs []*UnsafeSignature) (*UnsafeSignature, error) {
+	if len(indices) != len(sigs) {
+		return nil, cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: the number of indices and signatures differ")
+	}
+
+	coeffs, err := shamir.BN256.Lagrange(indices)
+	if err != nil {
+		return nil, errors.Wrap(err, "bls: invalid signer indices")
+	}
+	return combine(coeffs, sigs), nil
+}
+
+// KeyShare is a share of a group key held by Party, see GenPolicyKeys
+type KeyShare struct {
+	Party     uint32
+	ID        shamir.ShareID
+	PublicKey *PublicKey
+	SecretKey *SecretKey
+}
+
+// GenPolicyKeys generates a group key pair whose secret key is shared
+// according to policy, e.g. a weighted threshold or a hierarchy of
+// thresholds. A party holds one KeyShare per share of the policy it was
+// given, and signs with all of them
+func GenPolicyKeys(policy *shamir.Policy, randReader io.Reader) (*PublicKey, []KeyShare, error) {
+	pk, sk, err := GenKeyPair(randReader)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Only the shares of the group secret key are kept
+	defer sk.Destroy()
+
+	var shares []shamir.PolicyShare
+	sk.withScalar(func(x *big.Int) {
+		shares, err = shamir.BN256.SplitPolicy(x, policy, randReader)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := make([]KeyShare, len(shares))
+	for i, s := range shares {
+		keys[i] = KeyShare{
+			Party:     s.Party,
+			ID:        s.ID,
+			PublicKey: &PublicKey{newG2().ScalarMult(g2Base, s.Value)},
+			SecretKey: newSecretKey(s.Value),
+		}
+	}
+	return pk, keys, nil
+}
+
+// CombinePolicyUnsafe recombines the UnsafeSignatures made with the key
+// shares with the given ids into the UnsafeSignature of the group key. The
+// shares must satisfy the policy, and the signatures be valid signatures
+// on the same message, which CombinePolicyUnsafe cannot check
+func CombinePolicyUnsafe(policy *shamir.Policy, ids []shamir.ShareID, sigs []*UnsafeSignature) (*UnsafeSignature, error) {
+	if len(ids) != len(sigs) {
+		return nil, cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: the number of share ids and signatures differ")
+	}
+
+	coeffs, err := shamir.BN256.PolicyCoefficients(policy, ids)
+	if err != nil {
+		return nil, errors.Wrap(err, "bls: invalid signer shares")
+	}
+	return combine(coeffs, sigs), nil
+}
+
+// combine returns the sum of the signatures weighted by coeffs
+func combine(coeffs []*big.Int, sigs []*UnsafeSignature) *UnsafeSignature {
+	var sum *bn256.G1
+	for i, sig := range sigs {
+		term := newG1().ScalarMult(sig.e, coeffs[i])
+		if sum == nil {
+			sum = term
+		} else {
+			sum.Add(sum, term)
+		}
+	}
+	return &UnsafeSignature{sum}
+}
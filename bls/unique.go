@@ -0,0 +1,134 @@
+package bls
+
+import (
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/ct"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/hash"
+)
+
+// uniqueDomain separates the hash of unique signatures from the other
+// hashes to G1
+const uniqueDomain = "dusk.bls.unique"
+
+// UniqueSignature is a BLS signature H(pk, msg)^x, where H hashes the public
+// key and the message to G1 with hash.HashToG1.
+//
+// For a public key and a message there is exactly one UniqueSignature which
+// VerifyUnique accepts, and exactly one encoding of it which Unmarshal
+// accepts: the pairing with g2 is injective on G1. Signers cannot grind
+// signatures, and anyone can use Token as a lottery ticket or a
+// deduplication key. Hashing the public key with the message makes
+// aggregation safe without proofs of possession of the keys, and the
+// aggregate of the signatures of a set of keys and messages is as unique as
+// its parts
+type UniqueSignature struct {
+	e *bn256.G1
+}
+
+// uniqueHash returns H(pk, msg)
+func uniqueHash(pk *PublicKey, msg []byte) *bn256.G1 {
+	return hash.HashToG1(uniqueDomain, pk.Marshal(), msg)
+}
+
+// isIdentityG2 returns true if p is the point at infinity, which G2 marshals
+// to a single byte
+func isIdentityG2(p *bn256.G2) bool {
+	return len(p.Marshal()) == 1
+}
+
+// SignUnique returns the unique signature of msg by the key pair sk, pk
+func SignUnique(sk *SecretKey, pk *PublicKey, msg []byte) (*UniqueSignature, error) {
+	if isIdentityG2(pk.gx) {
+		return nil, cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: public key is the identity")
+	}
+	return &UniqueSignature{sk.g1Mult(uniqueHash(pk, msg))}, nil
+}
+
+// VerifyUnique checks the unique signature of msg by pk
+func VerifyUnique(pk *PublicKey, msg []byte, sig *UniqueSignature) error {
+	return VerifyUniqueAggregate([]*PublicKey{pk}, [][]byte{msg}, sig)
+}
+
+// AggregateUnique returns the aggregate of unique signatures
+func AggregateUnique(sigs ...*UniqueSignature) (*UniqueSignature, error) {
+	if len(sigs) == 0 {
+		return nil, cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: no signatures to aggregate")
+	}
+	res := newG1().Set(sigs[0].e)
+	for _, sig := range sigs[1:] {
+		res.Add(res, sig.e)
+	}
+	return &UniqueSignature{res}, nil
+}
+
+// VerifyUniqueAggregate checks the aggregate of the unique signatures of
+// msgs[i] by pks[i]. A key may sign several messages, but every pair of key
+// and message must be distinct, or the aggregate would count a signature
+// more than once
+func VerifyUniqueAggregate(pks []*PublicKey, msgs [][]byte, sig *UniqueSignature) error {
+	if len(pks) == 0 || len(pks) != len(msgs) {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: number of keys and messages do not match")
+	}
+	if sig.e == nil || ct.IsZero(sig.e.Marshal()) == 1 {
+		return cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: unique signature is the identity")
+	}
+
+	seen := make(map[string]bool, len(pks))
+	var pair *bn256.GT
+	for i, pk := range pks {
+		if isIdentityG2(pk.gx) {
+			return cryptoerrors.New(cryptoerrors.ErrOutOfRange, "bls: public key is the identity")
+		}
+		// keys have a fixed size
+		k := string(pk.Marshal()) + string(msgs[i])
+		if seen[k] {
+			return cryptoerrors.New(cryptoerrors.ErrVerificationFailed, "bls: duplicate key and message")
+		}
+		seen[k] = true
+
+		p := bn256.Pair(uniqueHash(pk, msgs[i]), pk.gx)
+		if pair == nil {
+			pair = p
+		} else {
+			pair.Add(pair, p)
+		}
+	}
+
+	if !ct.Equal(pair.Marshal(), bn256.Pair(sig.e, g2Base).Marshal()) {
+		return cryptoerrors.New(cryptoerrors.ErrVerificationFailed, "bls: invalid unique signature")
+	}
+	return nil
+}
+
+// Token returns the deterministic value of the signature, for use as a
+// lottery ticket or a deduplication key. It is only meaningful for verified
+// signatures
+func (sig *UniqueSignature) Token() [32]byte {
+	var res [32]byte
+	copy(res[:], hash.Sha3256WithDomain("dusk.bls.unique.token", sig.e.Marshal()))
+	return res
+}
+
+// Marshal a UniqueSignature as the uncompressed G1 point
+func (sig *UniqueSignature) Marshal() []byte {
+	return sig.e.Marshal()
+}
+
+// Unmarshal a UniqueSignature. Only the encoding Marshal produces is
+// accepted, so that the bytes of a signature are as unique as the point
+func (sig *UniqueSignature) Unmarshal(b []byte) error {
+	if len(b) != signatureSize {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "bls: invalid unique signature length")
+	}
+	e := newG1()
+	if _, err := e.Unmarshal(b); err != nil {
+		return cryptoerrors.Wrap(cryptoerrors.ErrMalformedPoint, err, "bls: invalid unique signature")
+	}
+	// coordinates are not checked to be reduced by Unmarshal
+	if !ct.Equal(e.Marshal(), b) || ct.IsZero(b) == 1 {
+		return cryptoerrors.New(cryptoerrors.ErrNotCanonical, "bls: unique signature is not canonical")
+	}
+	sig.e = e
+	return nil
+}
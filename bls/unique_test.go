@@ -0,0 +1,87 @@
+package bls
+
+import (
+	"testing"
+
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueSignature(t *testing.T) {
+	pk, sk, err := GenKeyPair(nil)
+	require.Nil(t, err)
+	msg := randomMessage()
+
+	sig, err := SignUnique(sk, pk, msg)
+	require.Nil(t, err)
+	require.Nil(t, VerifyUnique(pk, msg, sig))
+
+	// signing again gives the same signature, and the same token
+	again, err := SignUnique(sk, pk, msg)
+	require.Nil(t, err)
+	assert.Equal(t, sig.Marshal(), again.Marshal())
+	assert.Equal(t, sig.Token(), again.Token())
+
+	// any other point is rejected, even a multiple of the signature
+	other := &UniqueSignature{newG1().Add(sig.e, sig.e)}
+	err = VerifyUnique(pk, msg, other)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrVerificationFailed))
+	err = VerifyUnique(pk, randomMessage(), sig)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrVerificationFailed))
+
+	// the signature is bound to the key
+	pk2, _, err := GenKeyPair(nil)
+	require.Nil(t, err)
+	assert.NotNil(t, VerifyUnique(pk2, msg, sig))
+}
+
+func TestUniqueSignatureAggregate(t *testing.T) {
+	var pks []*PublicKey
+	var msgs [][]byte
+	var sigs []*UniqueSignature
+	for i := 0; i < 3; i++ {
+		pk, sk, err := GenKeyPair(nil)
+		require.Nil(t, err)
+		// the first key signs two messages
+		n := 1
+		if i == 0 {
+			n = 2
+		}
+		for j := 0; j < n; j++ {
+			msg := randomMessage()
+			sig, err := SignUnique(sk, pk, msg)
+			require.Nil(t, err)
+			pks, msgs, sigs = append(pks, pk), append(msgs, msg), append(sigs, sig)
+		}
+	}
+
+	agg, err := AggregateUnique(sigs...)
+	require.Nil(t, err)
+	assert.Nil(t, VerifyUniqueAggregate(pks, msgs, agg))
+
+	// a signature counted twice is rejected
+	agg2, err := AggregateUnique(append(sigs, sigs[0])...)
+	require.Nil(t, err)
+	err = VerifyUniqueAggregate(append(pks, pks[0]), append(msgs, msgs[0]), agg2)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrVerificationFailed))
+
+	assert.NotNil(t, VerifyUniqueAggregate(pks[1:], msgs, agg))
+	assert.NotNil(t, VerifyUniqueAggregate(pks[1:], msgs[1:], agg))
+}
+
+func TestUniqueSignatureMarshal(t *testing.T) {
+	pk, sk, err := GenKeyPair(nil)
+	require.Nil(t, err)
+	msg := randomMessage()
+	sig, err := SignUnique(sk, pk, msg)
+	require.Nil(t, err)
+
+	dec := &UniqueSignature{}
+	require.Nil(t, dec.Unmarshal(sig.Marshal()))
+	assert.Nil(t, VerifyUnique(pk, msg, dec))
+
+	// the identity and short encodings are rejected
+	assert.NotNil(t, dec.Unmarshal(make([]byte, signatureSize)))
+	assert.NotNil(t, dec.Unmarshal(sig.Marshal()[1:]))
+}
@@ -0,0 +1,175 @@
+// Package certificate signs batches of messages with a single BLS signature
+// over their Merkle root. Each message can then be proven on its own, with
+// its inclusion proof and the aggregate signature of the committee, which is
+// what light clients check without downloading the whole batch
+package certificate
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/dusk-network/dusk-crypto/bls"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/merkletree"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	leafDomain = []byte("dusk.certificate.leaf")
+	rootDomain = []byte("dusk.certificate.root")
+)
+
+const (
+	rootSize = 32
+	sigSize  = 64
+)
+
+type leaf []byte
+
+// CalculateHash domain separates the messages from the other trees
+func (l leaf) CalculateHash() ([]byte, error) {
+	h := sha3.New256()
+	_, _ = h.Write(leafDomain)
+	_, _ = h.Write(l)
+	return h.Sum(nil), nil
+}
+
+func newTree(msgs [][]byte) (*merkletree.Tree, error) {
+	if len(msgs) == 0 {
+		return nil, errors.New("certificate: no messages")
+	}
+	pl := make([]merkletree.Payload, len(msgs))
+	for i, m := range msgs {
+		pl[i] = leaf(m)
+	}
+	return merkletree.NewTree(pl)
+}
+
+// Root returns the Merkle root of a batch of messages
+func Root(msgs [][]byte) ([]byte, error) {
+	tree, err := newTree(msgs)
+	if err != nil {
+		return nil, err
+	}
+	return tree.MerkleRoot, nil
+}
+
+// signedMessage is what the committee signs for a given root
+func signedMessage(root []byte) []byte {
+	return append(append([]byte{}, rootDomain...), root...)
+}
+
+// Sign signs the root of a batch of messages. The signatures of the members
+// of a committee aggregate with bls.Signature.Aggregate
+func Sign(sk *bls.SecretKey, pk *bls.PublicKey, msgs [][]byte) (*bls.Signature, error) {
+	root, err := Root(msgs)
+	if err != nil {
+		return nil, err
+	}
+	return bls.Sign(sk, pk, signedMessage(root))
+}
+
+// VerifyRoot checks the aggregate signature of a root
+func VerifyRoot(apk *bls.Apk, root []byte, sig *bls.Signature) error {
+	if len(root) != rootSize {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "certificate: invalid root")
+	}
+	return bls.Verify(apk, signedMessage(root), sig)
+}
+
+// Proof certifies a single message of a batch
+type Proof struct {
+	Root      []byte
+	Signature *bls.Signature
+	Inclusion *merkletree.MultiProof
+}
+
+// Proofs returns the proof of every message of a batch signed with sig
+func Proofs(msgs [][]byte, sig *bls.Signature) ([]*Proof, error) {
+	tree, err := newTree(msgs)
+	if err != nil {
+		return nil, err
+	}
+
+	proofs := make([]*Proof, len(msgs))
+	for i := range msgs {
+		inclusion, err := tree.MultiProof([]int{i})
+		if err != nil {
+			return nil, err
+		}
+		proofs[i] = &Proof{
+			Root:      tree.MerkleRoot,
+			Signature: sig,
+			Inclusion: inclusion,
+		}
+	}
+	return proofs, nil
+}
+
+// Verify checks that msg is part of a batch whose root the committee with
+// the aggregate key apk signed
+func Verify(apk *bls.Apk, msg []byte, proof *Proof) error {
+	if proof == nil || proof.Signature == nil || proof.Inclusion == nil {
+		return errors.New("certificate: incomplete proof")
+	}
+
+	h, _ := leaf(msg).CalculateHash()
+	ok, err := proof.Inclusion.Verify(proof.Root, [][]byte{h})
+	if err != nil {
+		return cryptoerrors.Wrap(cryptoerrors.ErrVerificationFailed, err, "certificate: invalid inclusion proof")
+	}
+	if !ok {
+		return cryptoerrors.New(cryptoerrors.ErrVerificationFailed, "certificate: message is not in the batch")
+	}
+
+	return VerifyRoot(apk, proof.Root, proof.Signature)
+}
+
+// Encode a Proof
+func (p *Proof) Encode(w io.Writer) error {
+	if len(p.Root) != rootSize {
+		return errors.New("certificate: invalid root")
+	}
+	if err := binary.Write(w, binary.BigEndian, p.Root); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, p.Signature.Marshal()); err != nil {
+		return err
+	}
+	return p.Inclusion.Encode(w)
+}
+
+// Decode a Proof
+func (p *Proof) Decode(r io.Reader) error {
+	if p == nil {
+		return errors.New("struct is nil")
+	}
+
+	root := make([]byte, rootSize)
+	if _, err := io.ReadFull(r, root); err != nil {
+		return err
+	}
+
+	sigBytes := make([]byte, sigSize)
+	if _, err := io.ReadFull(r, sigBytes); err != nil {
+		return err
+	}
+	sig, err := bls.UnmarshalSignature(sigBytes)
+	if err != nil {
+		return err
+	}
+
+	inclusion := &merkletree.MultiProof{}
+	if err := inclusion.Decode(r); err != nil {
+		return err
+	}
+	if len(inclusion.Indices) != 1 {
+		return errors.New("certificate: proof must cover a single message")
+	}
+
+	p.Root = root
+	p.Signature = sig
+	p.Inclusion = inclusion
+	return nil
+}
@@ -0,0 +1,94 @@
+package certificate
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/bls"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// committeeSign has a committee of n members sign msgs
+func committeeSign(t *testing.T, n int, msgs [][]byte) (*bls.Apk, *bls.Signature) {
+	var pks []*bls.PublicKey
+	var sig *bls.Signature
+	for i := 0; i < n; i++ {
+		pk, sk, err := bls.GenKeyPair(nil)
+		require.Nil(t, err)
+		pks = append(pks, pk)
+
+		s, err := Sign(sk, pk, msgs)
+		require.Nil(t, err)
+		if sig == nil {
+			sig = s
+		} else {
+			sig = sig.Aggregate(s)
+		}
+	}
+
+	apk, err := bls.AggregateApk(pks)
+	require.Nil(t, err)
+	return apk, sig
+}
+
+func batch(n int) [][]byte {
+	msgs := make([][]byte, n)
+	for i := range msgs {
+		msgs[i] = []byte{'t', 'x', byte(i)}
+	}
+	return msgs
+}
+
+func TestCertificate(t *testing.T) {
+	msgs := batch(5)
+	apk, sig := committeeSign(t, 3, msgs)
+
+	root, err := Root(msgs)
+	require.Nil(t, err)
+	require.Nil(t, VerifyRoot(apk, root, sig))
+
+	proofs, err := Proofs(msgs, sig)
+	require.Nil(t, err)
+	require.Equal(t, len(msgs), len(proofs))
+	for i, proof := range proofs {
+		assert.Nil(t, Verify(apk, msgs[i], proof))
+
+		// a proof only certifies its own message
+		err := Verify(apk, msgs[(i+1)%len(msgs)], proof)
+		assert.True(t, errors.Is(err, cryptoerrors.ErrVerificationFailed))
+	}
+
+	// another committee did not sign the batch
+	otherApk, _ := committeeSign(t, 1, msgs)
+	assert.NotNil(t, Verify(otherApk, msgs[0], proofs[0]))
+
+	// nor a batch with another root
+	otherProofs, err := Proofs(batch(4), sig)
+	require.Nil(t, err)
+	assert.NotNil(t, Verify(apk, msgs[0], otherProofs[0]))
+
+	_, err = Root(nil)
+	assert.NotNil(t, err)
+}
+
+func TestProofEncoding(t *testing.T) {
+	msgs := batch(3)
+	apk, sig := committeeSign(t, 2, msgs)
+	proofs, err := Proofs(msgs, sig)
+	require.Nil(t, err)
+
+	buf := new(bytes.Buffer)
+	require.Nil(t, proofs[2].Encode(buf))
+	decoded := &Proof{}
+	require.Nil(t, decoded.Decode(buf))
+	assert.Equal(t, 0, buf.Len())
+	assert.Equal(t, proofs[2].Root, decoded.Root)
+	assert.Nil(t, Verify(apk, msgs[2], decoded))
+
+	var p *Proof
+	assert.NotNil(t, p.Decode(bytes.NewReader(nil)))
+	assert.NotNil(t, (&Proof{}).Decode(bytes.NewReader(make([]byte, 10))))
+}
@@ -0,0 +1,197 @@
+// Package cipher wraps ChaCha20-Poly1305 and AES-GCM into key committing
+// authenticated encryption, with helpers to manage nonces and to encrypt
+// streams in chunks.
+//
+// Plain AEADs let a ciphertext decrypt correctly under several keys, which
+// breaks protocols where the key is not fixed in advance, e.g. a file
+// encrypted to several recipients. Here every (key, nonce) pair derives a
+// subkey and a commitment, and the commitment is checked before decrypting,
+// so that a ciphertext only ever opens under the key it was sealed with. The
+// subkey derivation also makes the nonces 24 bytes long, long enough to be
+// picked at random
+package cipher
+
+import (
+	"crypto/aes"
+	stdcipher "crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Algorithm identifies the underlying AEAD
+type Algorithm byte
+
+const (
+	// ChaCha20Poly1305 is ChaCha20-Poly1305
+	ChaCha20Poly1305 Algorithm = iota + 1
+	// AESGCM is AES-256-GCM
+	AESGCM
+)
+
+const (
+	// KeySize is the size of the keys
+	KeySize = 32
+	// NonceSize is the size of the nonces
+	NonceSize = 24
+	// CommitmentSize is the size of the key commitment
+	CommitmentSize = 32
+	// Overhead is the difference between the size of a ciphertext and the
+	// size of its plaintext
+	Overhead = CommitmentSize + tagSize
+
+	tagSize = 16
+)
+
+// ErrOpen is returned when a ciphertext does not authenticate
+var ErrOpen = errors.New("cipher: message authentication failed")
+
+// AEAD is a key committing AEAD
+type AEAD struct {
+	alg Algorithm
+	key [KeySize]byte
+}
+
+// New returns an AEAD for the given algorithm and key
+func New(alg Algorithm, key []byte) (*AEAD, error) {
+	if alg != ChaCha20Poly1305 && alg != AESGCM {
+		return nil, errors.New("cipher: unknown algorithm")
+	}
+	if len(key) != KeySize {
+		return nil, errors.New("cipher: invalid key size")
+	}
+
+	a := &AEAD{alg: alg}
+	copy(a.key[:], key)
+	return a, nil
+}
+
+// derive returns the AEAD keyed with the subkey of nonce, and the key
+// commitment
+func (a *AEAD) derive(nonce []byte) (stdcipher.AEAD, []byte, error) {
+	if len(nonce) != NonceSize {
+		return nil, nil, errors.New("cipher: invalid nonce size")
+	}
+
+	out := hash.Sha3512WithDomain("dusk.cipher", []byte{byte(a.alg)}, a.key[:], nonce)
+	subkey, commitment := out[:KeySize], out[KeySize:]
+
+	var aead stdcipher.AEAD
+	var err error
+	switch a.alg {
+	case ChaCha20Poly1305:
+		aead, err = chacha20poly1305.New(subkey)
+	default:
+		var block stdcipher.Block
+		if block, err = aes.NewCipher(subkey); err == nil {
+			aead, err = stdcipher.NewGCM(block)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, commitment, nil
+}
+
+// Seal encrypts and authenticates plaintext and authenticates ad. A nonce
+// must never be used twice with the same key
+func (a *AEAD) Seal(nonce, plaintext, ad []byte) ([]byte, error) {
+	aead, commitment, err := a.derive(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// every subkey is used once, so the inner nonce is constant
+	inner := make([]byte, aead.NonceSize())
+	out := make([]byte, 0, Overhead+len(plaintext))
+	out = append(out, commitment...)
+	return aead.Seal(out, inner, plaintext, ad), nil
+}
+
+// Open checks the key commitment, then decrypts and authenticates ciphertext
+func (a *AEAD) Open(nonce, ciphertext, ad []byte) ([]byte, error) {
+	aead, commitment, err := a.derive(nonce)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < Overhead {
+		return nil, ErrOpen
+	}
+	if subtle.ConstantTimeCompare(commitment, ciphertext[:CommitmentSize]) != 1 {
+		return nil, ErrOpen
+	}
+
+	inner := make([]byte, aead.NonceSize())
+	pt, err := aead.Open(nil, inner, ciphertext[CommitmentSize:], ad)
+	if err != nil {
+		return nil, ErrOpen
+	}
+	return pt, nil
+}
+
+// SealRandom seals plaintext with a random nonce, which is prepended to the
+// ciphertext
+func (a *AEAD) SealRandom(plaintext, ad []byte) ([]byte, error) {
+	nonce, err := RandomNonce()
+	if err != nil {
+		return nil, err
+	}
+	ct, err := a.Seal(nonce[:], plaintext, ad)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce[:], ct...), nil
+}
+
+// OpenRandom opens the output of SealRandom
+func (a *AEAD) OpenRandom(ciphertext, ad []byte) ([]byte, error) {
+	if len(ciphertext) < NonceSize {
+		return nil, ErrOpen
+	}
+	return a.Open(ciphertext[:NonceSize], ciphertext[NonceSize:], ad)
+}
+
+// RandomNonce returns a random nonce. Random nonces can safely be used for
+// up to 2^64 messages per key
+func RandomNonce() ([NonceSize]byte, error) {
+	var nonce [NonceSize]byte
+	_, err := io.ReadFull(rng.Reader, nonce[:])
+	return nonce, err
+}
+
+// NonceSequence produces unique nonces made of a random prefix and a
+// counter, for senders that cannot afford to read randomness per message
+type NonceSequence struct {
+	prefix [NonceSize - 8]byte
+	n      uint64
+	done   bool
+}
+
+// NewNonceSequence returns a sequence with a random prefix
+func NewNonceSequence() (*NonceSequence, error) {
+	s := &NonceSequence{}
+	if _, err := io.ReadFull(rng.Reader, s.prefix[:]); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Next returns the next nonce of the sequence
+func (s *NonceSequence) Next() ([]byte, error) {
+	if s.done {
+		return nil, errors.New("cipher: nonce sequence exhausted")
+	}
+
+	nonce := make([]byte, NonceSize)
+	copy(nonce, s.prefix[:])
+	binary.BigEndian.PutUint64(nonce[len(s.prefix):], s.n)
+
+	s.n++
+	s.done = s.n == 0
+	return nonce, nil
+}
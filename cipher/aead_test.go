@@ -0,0 +1,105 @@
+package cipher
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomKey(t *testing.T) []byte {
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	require.Nil(t, err)
+	return key
+}
+
+func TestSealOpen(t *testing.T) {
+	for _, alg := range []Algorithm{ChaCha20Poly1305, AESGCM} {
+		a, err := New(alg, randomKey(t))
+		require.Nil(t, err)
+
+		nonce, err := RandomNonce()
+		require.Nil(t, err)
+		ct, err := a.Seal(nonce[:], []byte("wallet"), []byte("ad"))
+		require.Nil(t, err)
+		assert.Len(t, ct, len("wallet")+Overhead)
+
+		pt, err := a.Open(nonce[:], ct, []byte("ad"))
+		require.Nil(t, err)
+		assert.Equal(t, "wallet", string(pt))
+
+		_, err = a.Open(nonce[:], ct, []byte("other"))
+		assert.Equal(t, ErrOpen, err)
+		other, _ := RandomNonce()
+		_, err = a.Open(other[:], ct, []byte("ad"))
+		assert.Equal(t, ErrOpen, err)
+		for _, i := range []int{0, CommitmentSize, len(ct) - 1} {
+			tampered := append([]byte{}, ct...)
+			tampered[i] ^= 1
+			_, err = a.Open(nonce[:], tampered, []byte("ad"))
+			assert.Equal(t, ErrOpen, err)
+		}
+
+		rct, err := a.SealRandom([]byte("gossip"), nil)
+		require.Nil(t, err)
+		pt, err = a.OpenRandom(rct, nil)
+		require.Nil(t, err)
+		assert.Equal(t, "gossip", string(pt))
+	}
+}
+
+func TestKeyCommitment(t *testing.T) {
+	key := randomKey(t)
+	a, err := New(ChaCha20Poly1305, key)
+	require.Nil(t, err)
+	ct, err := a.SealRandom([]byte("message"), nil)
+	require.Nil(t, err)
+
+	// another key, or the same key for another algorithm, fails on the
+	// commitment
+	b, err := New(ChaCha20Poly1305, randomKey(t))
+	require.Nil(t, err)
+	_, err = b.OpenRandom(ct, nil)
+	assert.Equal(t, ErrOpen, err)
+
+	c, err := New(AESGCM, key)
+	require.Nil(t, err)
+	_, err = c.OpenRandom(ct, nil)
+	assert.Equal(t, ErrOpen, err)
+}
+
+func TestInvalidParameters(t *testing.T) {
+	_, err := New(Algorithm(0), randomKey(t))
+	assert.NotNil(t, err)
+	_, err = New(AESGCM, make([]byte, 16))
+	assert.NotNil(t, err)
+
+	a, err := New(AESGCM, randomKey(t))
+	require.Nil(t, err)
+	_, err = a.Seal(make([]byte, 12), nil, nil)
+	assert.NotNil(t, err)
+	_, err = a.OpenRandom(make([]byte, NonceSize+Overhead-1), nil)
+	assert.Equal(t, ErrOpen, err)
+}
+
+func TestNonceSequence(t *testing.T) {
+	s, err := NewNonceSequence()
+	require.Nil(t, err)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		n, err := s.Next()
+		require.Nil(t, err)
+		assert.Len(t, n, NonceSize)
+		assert.False(t, seen[string(n)])
+		seen[string(n)] = true
+	}
+
+	s.n = ^uint64(0)
+	_, err = s.Next()
+	require.Nil(t, err)
+	_, err = s.Next()
+	assert.NotNil(t, err)
+}
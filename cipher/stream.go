@@ -0,0 +1,202 @@
+package cipher
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// Streams are encrypted in chunks with the STREAM construction: the nonce of
+// every chunk is made of a random salt, the chunk counter and a flag marking
+// the last chunk, so that chunks cannot be reordered, dropped or truncated
+// without detection. The stream starts with a header
+//
+//   version (1 byte) || algorithm (1 byte) || salt (15 bytes)
+//
+// which every chunk authenticates as associated data
+
+// StreamVersion is the version of the stream framing
+const StreamVersion byte = 1
+
+// ChunkSize is the size of the plaintext chunks
+const ChunkSize = 64 << 10
+
+const (
+	saltSize   = NonceSize - 9
+	headerSize = 2 + saltSize
+)
+
+func chunkNonce(salt []byte, n uint64, last bool) []byte {
+	nonce := make([]byte, NonceSize)
+	copy(nonce, salt)
+	binary.BigEndian.PutUint64(nonce[saltSize:], n)
+	if last {
+		nonce[NonceSize-1] = 1
+	}
+	return nonce
+}
+
+type streamWriter struct {
+	w      io.Writer
+	aead   *AEAD
+	header []byte
+	buf    []byte
+	n      uint64
+	closed bool
+}
+
+// NewWriter returns a writer encrypting to w. Close must be called to
+// write the last chunk, without which the stream does not decrypt
+func NewWriter(w io.Writer, alg Algorithm, key []byte) (io.WriteCloser, error) {
+	aead, err := New(alg, key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, headerSize)
+	header[0] = StreamVersion
+	header[1] = byte(alg)
+	if _, err := io.ReadFull(rng.Reader, header[2:]); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &streamWriter{
+		w:      w,
+		aead:   aead,
+		header: header,
+		buf:    make([]byte, 0, ChunkSize),
+	}, nil
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	if sw.closed {
+		return 0, errors.New("cipher: write to closed stream")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// a full buffer is only flushed once more data comes, since the
+		// last chunk must be flagged as such
+		if len(sw.buf) == ChunkSize {
+			if err := sw.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(sw.buf[len(sw.buf):ChunkSize], p)
+		sw.buf = sw.buf[:len(sw.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (sw *streamWriter) flush(last bool) error {
+	if sw.n == ^uint64(0) {
+		return errors.New("cipher: stream too long")
+	}
+
+	ct, err := sw.aead.Seal(chunkNonce(sw.header[2:], sw.n, last), sw.buf, sw.header)
+	if err != nil {
+		return err
+	}
+	if _, err := sw.w.Write(ct); err != nil {
+		return err
+	}
+	sw.n++
+	sw.buf = sw.buf[:0]
+	return nil
+}
+
+// Close writes the last chunk. It does not close the underlying writer
+func (sw *streamWriter) Close() error {
+	if sw.closed {
+		return nil
+	}
+	sw.closed = true
+	return sw.flush(true)
+}
+
+type streamReader struct {
+	r      *bufio.Reader
+	aead   *AEAD
+	header []byte
+	buf    []byte
+	out    []byte
+	n      uint64
+	done   bool
+}
+
+// NewReader returns a reader decrypting the stream read from r. It returns
+// an error, rather than io.EOF, if the stream is truncated or tampered with
+func NewReader(r io.Reader, key []byte) (io.Reader, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[0] != StreamVersion {
+		return nil, errors.New("cipher: unsupported stream version")
+	}
+
+	aead, err := New(Algorithm(header[1]), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamReader{
+		r:      bufio.NewReader(r),
+		aead:   aead,
+		header: header,
+		buf:    make([]byte, ChunkSize+Overhead),
+	}, nil
+}
+
+func (sr *streamReader) Read(p []byte) (int, error) {
+	for len(sr.out) == 0 {
+		if sr.done {
+			return 0, io.EOF
+		}
+		if err := sr.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, sr.out)
+	sr.out = sr.out[n:]
+	return n, nil
+}
+
+// next decrypts the next chunk. The chunk is the last one if nothing
+// follows it
+func (sr *streamReader) next() error {
+	n, err := io.ReadFull(sr.r, sr.buf)
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	last := n < len(sr.buf)
+	if !last {
+		if _, err := sr.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	pt, err := sr.aead.Open(chunkNonce(sr.header[2:], sr.n, last), sr.buf[:n], sr.header)
+	if err != nil {
+		return err
+	}
+	sr.n++
+	sr.out = pt
+	sr.done = last
+	return nil
+}
@@ -0,0 +1,87 @@
+package cipher
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encryptStream(t *testing.T, key, data []byte, writes int) []byte {
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, ChaCha20Poly1305, key)
+	require.Nil(t, err)
+
+	step := len(data)/writes + 1
+	for i := 0; i < len(data); i += step {
+		end := i + step
+		if end > len(data) {
+			end = len(data)
+		}
+		_, err := w.Write(data[i:end])
+		require.Nil(t, err)
+	}
+	require.Nil(t, w.Close())
+	return buf.Bytes()
+}
+
+func decryptStream(key, ct []byte) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(ct), key)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func TestStream(t *testing.T) {
+	key := randomKey(t)
+	for _, size := range []int{0, 1, ChunkSize - 1, ChunkSize, ChunkSize + 1, 3*ChunkSize + 17} {
+		data := make([]byte, size)
+		_, err := rand.Read(data)
+		require.Nil(t, err)
+
+		for _, writes := range []int{1, 7} {
+			ct := encryptStream(t, key, data, writes)
+			pt, err := decryptStream(key, ct)
+			require.Nil(t, err, "size %d", size)
+			assert.True(t, bytes.Equal(data, pt), "size %d", size)
+		}
+	}
+}
+
+func TestStreamTampering(t *testing.T) {
+	key := randomKey(t)
+	data := make([]byte, 2*ChunkSize+100)
+	ct := encryptStream(t, key, data, 1)
+	chunk := ChunkSize + Overhead
+
+	// truncation at a chunk boundary
+	_, err := decryptStream(key, ct[:headerSize+chunk])
+	assert.NotNil(t, err)
+	_, err = decryptStream(key, ct[:headerSize+2*chunk])
+	assert.NotNil(t, err)
+	_, err = decryptStream(key, ct[:headerSize])
+	assert.NotNil(t, err)
+
+	// swapped chunks
+	swapped := append([]byte{}, ct[:headerSize]...)
+	swapped = append(swapped, ct[headerSize+chunk:headerSize+2*chunk]...)
+	swapped = append(swapped, ct[headerSize:headerSize+chunk]...)
+	swapped = append(swapped, ct[headerSize+2*chunk:]...)
+	_, err = decryptStream(key, swapped)
+	assert.NotNil(t, err)
+
+	// header and body bits
+	for _, i := range []int{1, 5, headerSize + 3, len(ct) - 1} {
+		tampered := append([]byte{}, ct...)
+		tampered[i] ^= 1
+		_, err = decryptStream(key, tampered)
+		assert.NotNil(t, err, "byte %d", i)
+	}
+
+	_, err = decryptStream(randomKey(t), ct)
+	assert.NotNil(t, err)
+}
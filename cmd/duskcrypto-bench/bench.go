@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"runtime"
+	"time"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/rangeproof"
+)
+
+// benchmark is an operation measured for several sizes. setup prepares the
+// operation for a size, outside of the measurement, and returns the size in
+// bytes of what it produces, if it is relevant
+type benchmark struct {
+	name  string
+	param string
+	setup func(size int) (op func() error, bytes int, err error)
+}
+
+// Result is a measurement of a benchmark for a size
+type Result struct {
+	Benchmark   string `json:"benchmark"`
+	Param       string `json:"param"`
+	Size        int    `json:"size"`
+	Iterations  int    `json:"iterations"`
+	NsPerOp     int64  `json:"ns_per_op"`
+	AllocsPerOp uint64 `json:"allocs_per_op"`
+	BytesPerOp  uint64 `json:"bytes_per_op"`
+	// OutputBytes is the size of the signature or proof, if relevant
+	OutputBytes int `json:"output_bytes,omitempty"`
+}
+
+var benchmarks = []benchmark{
+	{"bls-aggregate", "signatures", blsAggregate},
+	{"bls-verify-committee", "committee", blsVerifyCommittee},
+	{"bls-verify-batch", "messages", blsVerifyBatch},
+	{"rangeproof-prove", "values", rangeproofProve},
+	{"rangeproof-verify", "values", rangeproofVerify},
+	{"rangeproof-verify-batch", "proofs", rangeproofVerifyBatch},
+}
+
+// run measures op until it ran for at least d, and for at least once
+func run(b benchmark, size int, d time.Duration) (Result, error) {
+	op, n, err := b.setup(size)
+	if err != nil {
+		return Result{}, err
+	}
+	// Warm up caches, e.g. the generators of the range proofs
+	if err := op(); err != nil {
+		return Result{}, err
+	}
+
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	iterations := 0
+	start := time.Now()
+	for iterations == 0 || time.Since(start) < d {
+		if err := op(); err != nil {
+			return Result{}, err
+		}
+		iterations++
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return Result{
+		Benchmark:   b.name,
+		Param:       b.param,
+		Size:        size,
+		Iterations:  iterations,
+		NsPerOp:     elapsed.Nanoseconds() / int64(iterations),
+		AllocsPerOp: (after.Mallocs - before.Mallocs) / uint64(iterations),
+		BytesPerOp:  (after.TotalAlloc - before.TotalAlloc) / uint64(iterations),
+		OutputBytes: n,
+	}, nil
+}
+
+// committee returns size key pairs and their signatures on msg
+func committee(size int, msg []byte) ([]*bls.PublicKey, []*bls.Signature, error) {
+	pks := make([]*bls.PublicKey, size)
+	sigs := make([]*bls.Signature, size)
+	for i := range pks {
+		pk, sk, err := bls.GenKeyPair(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sigs[i], err = bls.Sign(sk, pk, msg); err != nil {
+			return nil, nil, err
+		}
+		pks[i] = pk
+	}
+	return pks, sigs, nil
+}
+
+func blsAggregate(size int) (func() error, int, error) {
+	_, sigs, err := committee(size, []byte("dusk.bench"))
+	if err != nil {
+		return nil, 0, err
+	}
+	op := func() error {
+		agg := sigs[0].Copy()
+		for _, sig := range sigs[1:] {
+			agg.Aggregate(sig)
+		}
+		return nil
+	}
+	return op, len(sigs[0].Marshal()), nil
+}
+
+// blsVerifyCommittee aggregates the keys of a committee and verifies its
+// aggregated signature, as done for every block
+func blsVerifyCommittee(size int) (func() error, int, error) {
+	msg := []byte("dusk.bench")
+	pks, sigs, err := committee(size, msg)
+	if err != nil {
+		return nil, 0, err
+	}
+	agg := sigs[0].Copy()
+	for _, sig := range sigs[1:] {
+		agg.Aggregate(sig)
+	}
+
+	op := func() error {
+		apk, err := bls.AggregateApk(pks)
+		if err != nil {
+			return err
+		}
+		return bls.Verify(apk, msg, agg)
+	}
+	return op, len(agg.Marshal()), nil
+}
+
+// blsVerifyBatch verifies one aggregated signature over distinct messages
+func blsVerifyBatch(size int) (func() error, int, error) {
+	apks := make([]*bls.Apk, size)
+	msgs := make([][]byte, size)
+	var agg *bls.Signature
+	for i := range apks {
+		msgs[i] = []byte{byte(i), byte(i >> 8), byte(i >> 16), byte(i >> 24)}
+		pks, sigs, err := committee(1, msgs[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		apks[i] = bls.NewApk(pks[0])
+		if agg == nil {
+			agg = sigs[0]
+		} else {
+			agg.Aggregate(sigs[0])
+		}
+	}
+
+	op := func() error {
+		return bls.VerifyBatch(apks, msgs, agg)
+	}
+	return op, len(agg.Marshal()), nil
+}
+
+func amounts(size int) []ristretto.Scalar {
+	v := make([]ristretto.Scalar, size)
+	for i := range v {
+		var buf [32]byte
+		buf[0] = byte(i + 1)
+		v[i].SetBytes(&buf)
+	}
+	return v
+}
+
+func proofSize(p rangeproof.Proof) (int, error) {
+	buf := new(bytes.Buffer)
+	if err := p.Encode(buf, false); err != nil {
+		return 0, err
+	}
+	return buf.Len(), nil
+}
+
+func rangeproofProve(size int) (func() error, int, error) {
+	v := amounts(size)
+	p, err := rangeproof.Prove(v, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	n, err := proofSize(p)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	op := func() error {
+		_, err := rangeproof.Prove(amounts(size), false)
+		return err
+	}
+	return op, n, nil
+}
+
+func rangeproofVerify(size int) (func() error, int, error) {
+	p, err := rangeproof.Prove(amounts(size), false)
+	if err != nil {
+		return nil, 0, err
+	}
+	n, err := proofSize(p)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	op := func() error {
+		_, err := rangeproof.Verify(p)
+		return err
+	}
+	return op, n, nil
+}
+
+// rangeproofVerifyBatch verifies size single value proofs at once
+func rangeproofVerifyBatch(size int) (func() error, int, error) {
+	proofs := make([]rangeproof.Proof, size)
+	for i := range proofs {
+		p, err := rangeproof.Prove(amounts(1), false)
+		if err != nil {
+			return nil, 0, err
+		}
+		proofs[i] = p
+	}
+	n, err := proofSize(proofs[0])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	op := func() error {
+		_, err := rangeproof.VerifyBatch(context.Background(), proofs)
+		return err
+	}
+	return op, n, nil
+}
@@ -0,0 +1,180 @@
+// Command duskcrypto-bench measures the pairing and bulletproof code for
+// a range of sizes, and writes the results as JSON or CSV so that they can
+// be compared from one release to the next:
+//
+//	duskcrypto-bench -run 'bls-' -sizes 1,16,64,256 -format csv -o bls.csv
+//
+// Every benchmark runs for each of the sizes, e.g. committee sizes,
+// aggregation counts or the number of values of a range proof. CPU and
+// memory profiles of the whole run can be written with -cpuprofile and
+// -memprofile
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSizes are the sizes of each benchmark when -sizes is not set.
+// Range proofs aggregate at most 64 values
+var defaultSizes = map[string][]int{
+	"signatures": {1, 16, 64, 256},
+	"committee":  {1, 16, 64, 256},
+	"messages":   {1, 4, 16, 64},
+	"values":     {1, 2, 4, 8, 16, 64},
+	"proofs":     {1, 4, 16, 64},
+}
+
+func main() {
+	var (
+		pattern    = flag.String("run", ".", "regexp selecting the benchmarks to run")
+		sizesFlag  = flag.String("sizes", "", "comma separated sizes, overriding the defaults of every benchmark")
+		duration   = flag.Duration("time", time.Second, "minimum duration of each measurement")
+		format     = flag.String("format", "json", "output format, json or csv")
+		output     = flag.String("o", "", "output file, standard output if empty")
+		cpuprofile = flag.String("cpuprofile", "", "write a CPU profile to this file")
+		memprofile = flag.String("memprofile", "", "write a memory profile to this file")
+		list       = flag.Bool("list", false, "list the benchmarks and exit")
+	)
+	flag.Parse()
+
+	if *list {
+		for _, b := range benchmarks {
+			fmt.Printf("%s\t%s\t%v\n", b.name, b.param, defaultSizes[b.param])
+		}
+		return
+	}
+
+	if err := benchmain(*pattern, *sizesFlag, *duration, *format, *output, *cpuprofile, *memprofile); err != nil {
+		fmt.Fprintln(os.Stderr, "duskcrypto-bench:", err)
+		os.Exit(1)
+	}
+}
+
+func benchmain(pattern, sizesFlag string, d time.Duration, format, output, cpuprofile, memprofile string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	sizes, err := parseSizes(sizesFlag)
+	if err != nil {
+		return err
+	}
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	w := io.Writer(os.Stdout)
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if cpuprofile != "" {
+		f, err := os.Create(cpuprofile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	var results []Result
+	for _, b := range benchmarks {
+		if !re.MatchString(b.name) {
+			continue
+		}
+		bs := sizes
+		if bs == nil {
+			bs = defaultSizes[b.param]
+		}
+		for _, size := range bs {
+			r, err := run(b, size, d)
+			if err != nil {
+				return fmt.Errorf("%s with %d %s: %v", b.name, size, b.param, err)
+			}
+			fmt.Fprintf(os.Stderr, "%s\t%s=%d\t%d ns/op\n", r.Benchmark, r.Param, r.Size, r.NsPerOp)
+			results = append(results, r)
+		}
+	}
+
+	if memprofile != "" {
+		f, err := os.Create(memprofile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			return err
+		}
+	}
+
+	if format == "csv" {
+		return writeCSV(w, results)
+	}
+	return writeJSON(w, results)
+}
+
+func parseSizes(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var sizes []int
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid size %q", f)
+		}
+		sizes = append(sizes, n)
+	}
+	return sizes, nil
+}
+
+func writeJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+func writeCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	header := []string{"benchmark", "param", "size", "iterations", "ns_per_op", "allocs_per_op", "bytes_per_op", "output_bytes"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, r := range results {
+		record := []string{
+			r.Benchmark,
+			r.Param,
+			strconv.Itoa(r.Size),
+			strconv.Itoa(r.Iterations),
+			strconv.FormatInt(r.NsPerOp, 10),
+			strconv.FormatUint(r.AllocsPerOp, 10),
+			strconv.FormatUint(r.BytesPerOp, 10),
+			strconv.Itoa(r.OutputBytes),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSizes(t *testing.T) {
+	sizes, err := parseSizes("1, 16,64")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 16, 64}, sizes)
+
+	sizes, err = parseSizes("")
+	require.NoError(t, err)
+	assert.Nil(t, sizes)
+
+	_, err = parseSizes("1,0")
+	assert.Error(t, err)
+	_, err = parseSizes("a")
+	assert.Error(t, err)
+}
+
+func TestRunAndWrite(t *testing.T) {
+	var results []Result
+	for _, b := range benchmarks {
+		if b.name == "bls-verify-committee" || b.name == "rangeproof-verify" {
+			r, err := run(b, 2, 0)
+			require.NoError(t, err)
+			assert.Equal(t, 2, r.Size)
+			assert.True(t, r.Iterations > 0)
+			assert.True(t, r.OutputBytes > 0)
+			results = append(results, r)
+		}
+	}
+	require.Len(t, results, 2)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, writeJSON(buf, results))
+	var decoded []Result
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, results, decoded)
+
+	buf.Reset()
+	require.NoError(t, writeCSV(buf, results))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[1], "bls-verify-committee,committee,2,"))
+}
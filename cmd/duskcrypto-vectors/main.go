@@ -0,0 +1,72 @@
+// Command duskcrypto-vectors generates the cross package test vectors of
+// the vectors package, and checks vector files, e.g. the ones produced by
+// another implementation:
+//
+//	duskcrypto-vectors -seed dusk -o corpus.json
+//	duskcrypto-vectors -verify corpus.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dusk-network/dusk-crypto/vectors"
+)
+
+func main() {
+	var (
+		seed   = flag.String("seed", "dusk", "seed of the generated corpus")
+		output = flag.String("o", "", "output file, standard output if empty")
+		verify = flag.String("verify", "", "verify this corpus instead of generating one")
+	)
+	flag.Parse()
+
+	var err error
+	if *verify != "" {
+		err = verifyFile(*verify)
+	} else {
+		err = generate(*seed, *output)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "duskcrypto-vectors:", err)
+		os.Exit(1)
+	}
+}
+
+func generate(seed, output string) error {
+	c, err := vectors.Generate([]byte(seed))
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return vectors.Write(w, c)
+}
+
+func verifyFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	c, err := vectors.Read(f)
+	if err != nil {
+		return err
+	}
+	if err := vectors.Verify(c); err != nil {
+		return err
+	}
+	fmt.Println("all vectors verified")
+	return nil
+}
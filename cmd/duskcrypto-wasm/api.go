@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/envelope"
+	"github.com/dusk-network/dusk-crypto/rangeproof"
+)
+
+// The functions below are the API exposed to JavaScript. They take and
+// return hex strings, public keys, signatures and proofs being hex encoded
+// envelopes like the ones of the duskcrypto command
+
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(s)
+}
+
+func unmarshal(s string, t envelope.Type) (interface{}, error) {
+	b, err := decodeHex(s)
+	if err != nil {
+		return nil, err
+	}
+	got, v, err := envelope.Unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+	if got != t {
+		reg, _ := envelope.Lookup(t)
+		return nil, fmt.Errorf("expected a %s", reg.Name)
+	}
+	return v, nil
+}
+
+func blsPublicKeys(pks []string) ([]*bls.PublicKey, error) {
+	if len(pks) == 0 {
+		return nil, errors.New("no public keys")
+	}
+	res := make([]*bls.PublicKey, len(pks))
+	for i, s := range pks {
+		v, err := unmarshal(s, envelope.BLSPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = v.(*bls.PublicKey)
+	}
+	return res, nil
+}
+
+// verifyRangeProof verifies a range proof, encoded with its commitments
+func verifyRangeProof(proof string) (bool, error) {
+	v, err := unmarshal(proof, envelope.RangeProof)
+	if err != nil {
+		return false, err
+	}
+	ok, err := rangeproof.Verify(*v.(*rangeproof.Proof))
+	if err != nil {
+		// Invalid proofs are reported as such, not as failures of the call
+		return false, nil
+	}
+	return ok, nil
+}
+
+// verifyBLS verifies the signature of a committee on a hex encoded message
+func verifyBLS(pks []string, msg, sig string) (bool, error) {
+	keys, err := blsPublicKeys(pks)
+	if err != nil {
+		return false, err
+	}
+	m, err := decodeHex(msg)
+	if err != nil {
+		return false, err
+	}
+	v, err := unmarshal(sig, envelope.BLSSignature)
+	if err != nil {
+		return false, err
+	}
+
+	apk, err := bls.AggregateApk(keys)
+	if err != nil {
+		return false, err
+	}
+	return bls.Verify(apk, m, v.(*bls.Signature)) == nil, nil
+}
+
+// aggregatePublicKeys returns the aggregated public key of a committee
+func aggregatePublicKeys(pks []string) (string, error) {
+	keys, err := blsPublicKeys(pks)
+	if err != nil {
+		return "", err
+	}
+	apk, err := bls.AggregateApk(keys)
+	if err != nil {
+		return "", err
+	}
+	b, err := envelope.Marshal(envelope.BLSPublicKey, apk.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
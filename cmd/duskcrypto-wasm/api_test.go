@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/envelope"
+	"github.com/dusk-network/dusk-crypto/rangeproof"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func marshalHex(t *testing.T, typ envelope.Type, v interface{}) string {
+	b, err := envelope.Marshal(typ, v)
+	require.NoError(t, err)
+	return hex.EncodeToString(b)
+}
+
+func TestVerifyRangeProof(t *testing.T) {
+	p, err := rangeproof.ProveUint64([]uint64{42}, nil)
+	require.NoError(t, err)
+	proof := marshalHex(t, envelope.RangeProof, &p)
+
+	ok, err := verifyRangeProof(proof)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// A proof for other commitments
+	p.V[0].Value.Add(&p.V[0].Value, &p.A)
+	ok, err = verifyRangeProof(marshalHex(t, envelope.RangeProof, &p))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = verifyRangeProof("zz")
+	assert.Error(t, err)
+}
+
+func TestVerifyBLS(t *testing.T) {
+	msg := []byte("block hash")
+	var pks []string
+	var agg *bls.Signature
+	for i := 0; i < 3; i++ {
+		pk, sk, err := bls.GenKeyPair(rand.Reader)
+		require.NoError(t, err)
+		sig, err := bls.Sign(sk, pk, msg)
+		require.NoError(t, err)
+		if agg == nil {
+			agg = sig
+		} else {
+			agg.Aggregate(sig)
+		}
+		pks = append(pks, marshalHex(t, envelope.BLSPublicKey, pk))
+	}
+	sig := marshalHex(t, envelope.BLSSignature, agg)
+
+	ok, err := verifyBLS(pks, hex.EncodeToString(msg), sig)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = verifyBLS(pks[:2], hex.EncodeToString(msg), sig)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Keys where signatures are expected
+	_, err = verifyBLS(pks, hex.EncodeToString(msg), pks[0])
+	assert.Error(t, err)
+
+	apk, err := aggregatePublicKeys(pks)
+	require.NoError(t, err)
+	assert.NotEmpty(t, apk)
+	_, err = aggregatePublicKeys(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Command duskcrypto-wasm exposes proof and signature verification to
+// JavaScript, for light clients running in browsers. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o duskcrypto.wasm ./cmd/duskcrypto-wasm
+//
+// and load it with the wasm_exec.js of the Go distribution. Once started,
+// it defines a global duskcrypto object, whose functions return
+// {result: ...} or {error: "..."}:
+//
+//	duskcrypto.verifyRangeProof(proof)
+//	duskcrypto.verifyBLS([pk, ...], message, signature)
+//	duskcrypto.aggregatePublicKeys([pk, ...])
+//
+// Arguments and results are hex strings, see api.go
+package main
+
+import (
+	"errors"
+	"syscall/js"
+)
+
+func main() {
+	js.Global().Set("duskcrypto", js.ValueOf(map[string]interface{}{
+		"verifyRangeProof": wrap(func(args []js.Value) (interface{}, error) {
+			if len(args) != 1 {
+				return nil, errors.New("verifyRangeProof(proof)")
+			}
+			return verifyRangeProof(args[0].String())
+		}),
+		"verifyBLS": wrap(func(args []js.Value) (interface{}, error) {
+			if len(args) != 3 {
+				return nil, errors.New("verifyBLS(publicKeys, message, signature)")
+			}
+			return verifyBLS(stringSlice(args[0]), args[1].String(), args[2].String())
+		}),
+		"aggregatePublicKeys": wrap(func(args []js.Value) (interface{}, error) {
+			if len(args) != 1 {
+				return nil, errors.New("aggregatePublicKeys(publicKeys)")
+			}
+			return aggregatePublicKeys(stringSlice(args[0]))
+		}),
+	}))
+
+	// The functions must outlive main
+	select {}
+}
+
+// wrap turns f into a JavaScript function, reporting errors, including
+// panics, to the caller instead of aborting the module
+func wrap(f func(args []js.Value) (interface{}, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) (res interface{}) {
+		defer func() {
+			if r := recover(); r != nil {
+				res = map[string]interface{}{"error": "internal error"}
+			}
+		}()
+		v, err := f(args)
+		if err != nil {
+			return map[string]interface{}{"error": err.Error()}
+		}
+		return map[string]interface{}{"result": v}
+	})
+}
+
+// stringSlice converts a JavaScript array of strings
+func stringSlice(v js.Value) []string {
+	res := make([]string, v.Length())
+	for i := range res {
+		res[i] = v.Index(i).String()
+	}
+	return res
+}
@@ -0,0 +1,16 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// main explains how to build the module: this command only runs as
+// WebAssembly, see main_js.go
+func main() {
+	fmt.Fprintln(os.Stderr, "duskcrypto-wasm: build with GOOS=js GOARCH=wasm")
+	os.Exit(2)
+}
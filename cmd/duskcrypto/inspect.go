@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/envelope"
+	"github.com/dusk-network/dusk-crypto/rangeproof"
+	"github.com/dusk-network/dusk-crypto/schnorr"
+)
+
+func cmdInspect(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("inspect needs a hex blob, or a file holding one")
+	}
+
+	t, v, err := unmarshalArg(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	reg, _ := envelope.Lookup(t)
+	fmt.Fprintf(stdout, "type:    %#02x (%s)\n", byte(t), reg.Name)
+	fmt.Fprintf(stdout, "version: %d\n", reg.Version)
+	fmt.Fprintf(stdout, "value:   %T\n", v)
+
+	switch v := v.(type) {
+	case *bls.PublicKey:
+		fmt.Fprintf(stdout, "key:     %s\n", hex.EncodeToString(v.Marshal()))
+	case *bls.Signature:
+		fmt.Fprintf(stdout, "compressed: %s\n", hex.EncodeToString(v.Compress()))
+	case *ristretto.Point:
+		fmt.Fprintf(stdout, "point:   %s\n", hex.EncodeToString(v.Bytes()))
+	case *schnorr.Signature:
+		fmt.Fprintf(stdout, "R:       %s\n", hex.EncodeToString(v.R.Bytes()))
+		fmt.Fprintf(stdout, "S:       %s\n", hex.EncodeToString(v.S.Bytes()))
+	case *rangeproof.Proof:
+		fmt.Fprintf(stdout, "commitments: %d\n", len(v.V))
+		for i := range v.V {
+			fmt.Fprintf(stdout, "  V[%d]: %s\n", i, hex.EncodeToString(v.V[i].Value.Bytes()))
+		}
+	}
+	return nil
+}
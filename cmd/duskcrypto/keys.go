@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/envelope"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/schnorr"
+)
+
+const (
+	schemeBLS     = "bls"
+	schemeSchnorr = "schnorr"
+)
+
+// keyFile is the content of a secret key file
+type keyFile struct {
+	Scheme string `json:"scheme"`
+	// Secret is the hex encoded secret key
+	Secret string `json:"secret"`
+	// Public is the hex encoded envelope of the public key
+	Public string `json:"public"`
+}
+
+func cmdKeygen(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	scheme := fs.String("scheme", schemeBLS, "signature scheme, bls or schnorr")
+	out := fs.String("o", "", "write the secret key to <o>.key and the public key to <o>.pub")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var kf keyFile
+	var err error
+	switch *scheme {
+	case schemeBLS:
+		pk, sk, err := bls.GenKeyPair(rng.Reader)
+		if err != nil {
+			return err
+		}
+		kf = keyFile{Scheme: schemeBLS, Secret: hex.EncodeToString(sk.Marshal())}
+		kf.Public, err = marshalHex(envelope.BLSPublicKey, pk)
+		if err != nil {
+			return err
+		}
+	case schemeSchnorr:
+		sk, pk := schnorr.GenerateKey()
+		kf = keyFile{Scheme: schemeSchnorr, Secret: hex.EncodeToString(sk.Bytes())}
+		if kf.Public, err = marshalHex(envelope.RistrettoPoint, &pk); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown scheme %q", *scheme)
+	}
+
+	b, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = fmt.Fprintln(stdout, string(b))
+		return err
+	}
+	if err := ioutil.WriteFile(*out+".key", append(b, '\n'), 0600); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(*out+".pub", []byte(kf.Public+"\n"), 0644); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, kf.Public)
+	return err
+}
+
+// signer is a secret key read from a key file
+type signer struct {
+	scheme    string
+	blsSK     *bls.SecretKey
+	blsPK     *bls.PublicKey
+	schnorrSK ristretto.Scalar
+}
+
+func readKeyFile(path string) (*signer, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var kf keyFile
+	if err := json.Unmarshal(b, &kf); err != nil {
+		return nil, err
+	}
+	secret, err := hex.DecodeString(kf.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &signer{scheme: kf.Scheme}
+	switch kf.Scheme {
+	case schemeBLS:
+		if s.blsSK, err = bls.UnmarshalSk(secret); err != nil {
+			return nil, err
+		}
+		s.blsPK = s.blsSK.PublicKey()
+	case schemeSchnorr:
+		var buf [32]byte
+		if len(secret) != len(buf) {
+			return nil, errors.New("invalid schnorr secret key")
+		}
+		copy(buf[:], secret)
+		s.schnorrSK.SetBytes(&buf)
+	default:
+		return nil, fmt.Errorf("unknown scheme %q", kf.Scheme)
+	}
+	return s, nil
+}
+
+func cmdSign(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("sign", flag.ContinueOnError)
+	key := fs.String("key", "", "secret key file written by keygen")
+	in := fs.String("in", "", "file to sign")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *key == "" || *in == "" {
+		return errors.New("sign needs -key and -in")
+	}
+
+	s, err := readKeyFile(*key)
+	if err != nil {
+		return err
+	}
+	msg, err := ioutil.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+
+	var sig string
+	switch s.scheme {
+	case schemeBLS:
+		blsSig, err := bls.Sign(s.blsSK, s.blsPK, msg)
+		if err != nil {
+			return err
+		}
+		sig, err = marshalHex(envelope.BLSSignature, blsSig)
+		if err != nil {
+			return err
+		}
+	case schemeSchnorr:
+		schnorrSig := schnorr.Sign(s.schnorrSK, msg)
+		if sig, err = marshalHex(envelope.SchnorrSignature, &schnorrSig); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(stdout, sig)
+	return err
+}
+
+func cmdVerify(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	pubs := fs.String("pub", "", "public key, or comma separated BLS public keys of an aggregated signature")
+	sigArg := fs.String("sig", "", "signature")
+	in := fs.String("in", "", "signed file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *pubs == "" || *sigArg == "" || *in == "" {
+		return errors.New("verify needs -pub, -sig and -in")
+	}
+
+	msg, err := ioutil.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	_, sig, err := unmarshalArg(*sigArg)
+	if err != nil {
+		return err
+	}
+
+	var keys []interface{}
+	for _, p := range strings.Split(*pubs, ",") {
+		_, k, err := unmarshalArg(p)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, k)
+	}
+
+	switch sig := sig.(type) {
+	case *bls.Signature:
+		pks := make([]*bls.PublicKey, len(keys))
+		for i, k := range keys {
+			pk, ok := k.(*bls.PublicKey)
+			if !ok {
+				return errors.New("a bls signature needs bls public keys")
+			}
+			pks[i] = pk
+		}
+		apk, err := bls.AggregateApk(pks)
+		if err != nil {
+			return err
+		}
+		if err := bls.Verify(apk, msg, sig); err != nil {
+			return errors.New("invalid signature")
+		}
+	case *schnorr.Signature:
+		pk, ok := keys[0].(*ristretto.Point)
+		if len(keys) != 1 || !ok {
+			return errors.New("a schnorr signature needs one schnorr public key")
+		}
+		if !schnorr.Verify(*pk, msg, *sig) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return fmt.Errorf("%T is not a signature", sig)
+	}
+
+	_, err = fmt.Fprintln(stdout, "valid")
+	return err
+}
+
+func cmdAggregate(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("aggregate", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("aggregate needs at least one signature")
+	}
+
+	var agg *bls.Signature
+	for _, arg := range fs.Args() {
+		_, v, err := unmarshalArg(arg)
+		if err != nil {
+			return err
+		}
+		sig, ok := v.(*bls.Signature)
+		if !ok {
+			return fmt.Errorf("%T is not a bls signature", v)
+		}
+		if agg == nil {
+			agg = sig.Copy()
+		} else {
+			agg.Aggregate(sig)
+		}
+	}
+
+	s, err := marshalHex(envelope.BLSSignature, agg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, s)
+	return err
+}
+
+func marshalHex(t envelope.Type, v interface{}) (string, error) {
+	b, err := envelope.Marshal(t, v)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// unmarshalArg decodes an envelope given in hex, or the hex envelope held
+// by the file of that name
+func unmarshalArg(arg string) (envelope.Type, interface{}, error) {
+	b, err := hex.DecodeString(strings.TrimSpace(arg))
+	if err != nil {
+		content, ferr := ioutil.ReadFile(arg)
+		if ferr != nil {
+			return 0, nil, fmt.Errorf("%q is neither hex nor a readable file", arg)
+		}
+		if b, err = hex.DecodeString(strings.TrimSpace(string(content))); err != nil {
+			return 0, nil, fmt.Errorf("%s does not hold a hex encoded blob", arg)
+		}
+	}
+	return envelope.Unmarshal(b)
+}
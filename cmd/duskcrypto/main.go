@@ -0,0 +1,73 @@
+// Command duskcrypto performs the everyday key and proof operations of the
+// repository from the command line:
+//
+//	duskcrypto keygen -scheme bls -o validator
+//	duskcrypto sign -key validator.key -in block.bin
+//	duskcrypto verify -pub <public key> -sig <signature> -in block.bin
+//	duskcrypto aggregate <signature> <signature>...
+//	duskcrypto prove -in amounts.json
+//	duskcrypto verify-proof -in proof.json
+//	duskcrypto inspect <blob>
+//
+// Public keys, signatures and proofs are printed as hex encoded envelopes,
+// see the envelope package, so that every blob says what it holds and can
+// be inspected. Secret keys are written to JSON files readable only by
+// their owner
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout io.Writer) error
+}
+
+var commands = []command{
+	{"keygen", "generate a BLS or Schnorr key pair", cmdKeygen},
+	{"sign", "sign a file with a secret key", cmdSign},
+	{"verify", "verify the signature of a file", cmdVerify},
+	{"aggregate", "aggregate BLS signatures", cmdAggregate},
+	{"prove", "create a range proof for the amounts of a JSON file", cmdProve},
+	{"verify-proof", "verify a range proof", cmdVerifyProof},
+	{"inspect", "describe a serialized blob", cmdInspect},
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: duskcrypto <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-13s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "run duskcrypto <command> -h for the arguments of a command")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	if err := dispatch(os.Args[1], os.Args[2:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "duskcrypto:", err)
+		os.Exit(1)
+	}
+}
+
+func dispatch(name string, args []string, stdout io.Writer) error {
+	if name == "help" || name == "-h" || name == "--help" {
+		usage(stdout)
+		return nil
+	}
+	for _, c := range commands {
+		if c.name == name {
+			return c.run(args, stdout)
+		}
+	}
+	return fmt.Errorf("unknown command %q", name)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func run(t *testing.T, args ...string) string {
+	out := new(bytes.Buffer)
+	require.NoError(t, dispatch(args[0], args[1:], out))
+	return strings.TrimSpace(out.String())
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "duskcrypto")
+	require.NoError(t, err)
+	return dir
+}
+
+func TestSignVerify(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	msg := filepath.Join(dir, "msg")
+	require.NoError(t, ioutil.WriteFile(msg, []byte("block"), 0644))
+	other := filepath.Join(dir, "other")
+	require.NoError(t, ioutil.WriteFile(other, []byte("another block"), 0644))
+
+	for _, scheme := range []string{schemeBLS, schemeSchnorr} {
+		prefix := filepath.Join(dir, scheme)
+		pub := run(t, "keygen", "-scheme", scheme, "-o", prefix)
+		sig := run(t, "sign", "-key", prefix+".key", "-in", msg)
+
+		assert.Equal(t, "valid", run(t, "verify", "-pub", pub, "-sig", sig, "-in", msg))
+		// Public keys are also read from files
+		assert.Equal(t, "valid", run(t, "verify", "-pub", prefix+".pub", "-sig", sig, "-in", msg))
+		assert.Error(t, dispatch("verify", []string{"-pub", pub, "-sig", sig, "-in", other}, ioutil.Discard))
+
+		assert.Contains(t, run(t, "inspect", sig), "signature")
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	msg := filepath.Join(dir, "msg")
+	require.NoError(t, ioutil.WriteFile(msg, []byte("block"), 0644))
+
+	var pubs, sigs []string
+	for _, name := range []string{"a", "b", "c"} {
+		prefix := filepath.Join(dir, name)
+		pubs = append(pubs, run(t, "keygen", "-o", prefix))
+		sigs = append(sigs, run(t, "sign", "-key", prefix+".key", "-in", msg))
+	}
+
+	agg := run(t, append([]string{"aggregate"}, sigs...)...)
+	assert.Equal(t, "valid", run(t, "verify", "-pub", strings.Join(pubs, ","), "-sig", agg, "-in", msg))
+	assert.Error(t, dispatch("verify", []string{"-pub", pubs[0], "-sig", agg, "-in", msg}, ioutil.Discard))
+}
+
+func TestRangeProof(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "amounts.json")
+	require.NoError(t, ioutil.WriteFile(in, []byte(`{"amounts": [10, 20, 30]}`), 0644))
+
+	proof := filepath.Join(dir, "proof.json")
+	require.NoError(t, ioutil.WriteFile(proof, []byte(run(t, "prove", "-in", in)), 0644))
+	assert.Equal(t, "valid proof for 4 commitments", run(t, "verify-proof", "-in", proof))
+
+	b, err := ioutil.ReadFile(proof)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"blinds"`)
+}
+
+func TestErrors(t *testing.T) {
+	assert.Error(t, dispatch("unknown", nil, ioutil.Discard))
+	assert.Error(t, dispatch("keygen", []string{"-scheme", "rsa"}, ioutil.Discard))
+	assert.Error(t, dispatch("inspect", []string{"zz"}, ioutil.Discard))
+	assert.NoError(t, dispatch("help", nil, ioutil.Discard))
+}
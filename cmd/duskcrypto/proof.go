@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/dusk-network/dusk-crypto/envelope"
+	"github.com/dusk-network/dusk-crypto/rangeproof"
+)
+
+// proveInput is the input of prove
+type proveInput struct {
+	Amounts []uint64 `json:"amounts"`
+}
+
+// proofFile is the output of prove, and the input of verify-proof
+type proofFile struct {
+	// Proof is the hex encoded envelope of the proof and its commitments
+	Proof string `json:"proof"`
+	// Blinds are the hex encoded blinding factors of the commitments to the
+	// amounts, which their owner needs to spend them
+	Blinds []string `json:"blinds,omitempty"`
+}
+
+func cmdProve(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("prove", flag.ContinueOnError)
+	in := fs.String("in", "", `JSON file of the form {"amounts": [1, 2]}`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		return errors.New("prove needs -in")
+	}
+
+	b, err := ioutil.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	var input proveInput
+	if err := json.Unmarshal(b, &input); err != nil {
+		return err
+	}
+	if len(input.Amounts) == 0 {
+		return errors.New("no amounts to prove")
+	}
+
+	p, err := rangeproof.ProveUint64(input.Amounts, nil)
+	if err != nil {
+		return err
+	}
+
+	var out proofFile
+	if out.Proof, err = marshalHex(envelope.RangeProof, &p); err != nil {
+		return err
+	}
+	for i := range input.Amounts {
+		out.Blinds = append(out.Blinds, hex.EncodeToString(p.V[i].BlindingFactor.Bytes()))
+	}
+
+	res, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, string(res))
+	return err
+}
+
+func cmdVerifyProof(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify-proof", flag.ContinueOnError)
+	in := fs.String("in", "", "JSON file written by prove, or file of a hex encoded proof")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		return errors.New("verify-proof needs -in")
+	}
+
+	b, err := ioutil.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	arg := *in
+	var pf proofFile
+	if json.Unmarshal(b, &pf) == nil && pf.Proof != "" {
+		arg = pf.Proof
+	}
+
+	_, v, err := unmarshalArg(arg)
+	if err != nil {
+		return err
+	}
+	p, ok := v.(*rangeproof.Proof)
+	if !ok {
+		return fmt.Errorf("%T is not a range proof", v)
+	}
+	if ok, err := rangeproof.Verify(*p); !ok || err != nil {
+		return errors.New("invalid proof")
+	}
+
+	_, err = fmt.Fprintf(stdout, "valid proof for %d commitments\n", len(p.V))
+	return err
+}
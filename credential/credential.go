@@ -0,0 +1,673 @@
+// Package credential implements keyed-verification anonymous credentials,
+// with the algebraic MAC of Chase, Meiklejohn and Zaverucha over Ristretto.
+//
+// A credential on attributes m_1..m_n is a pair (U, U') with
+// U' = (x0 + sum(x_i * m_i)) * U, where x0, x_i are the secret key of the
+// issuer. Only the issuer can check a MAC, so credentials are presented
+// back to the issuer, or to a verifier sharing its key: this is what
+// access gating needs, and it avoids pairings entirely.
+//
+// Issuance is blind for the attributes the user chooses to hide: the user
+// sends them ElGamal encrypted under a throwaway key, the issuer computes
+// the encryption of U' homomorphically and proves that it used the key of
+// its public parameters, so that it cannot tag users with per-user keys.
+//
+// A presentation rerandomizes the credential, reveals the disclosed
+// attributes and commits to the others as m_i * U + z_i * H. The issuer
+// recomputes V = x0 * U + sum(x_i * m_i * U) + sum(x_i * C_i) - C_U' from
+// the presentation and its key, and the user proves knowledge of the
+// openings with V = sum(z_i * X_i) - r * G, which only holds for a valid
+// MAC. Two presentations of the same credential are unlinkable
+package credential
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/sigma"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// MaxAttributes bounds the number of attributes of a credential
+const MaxAttributes = 16
+
+// ErrInvalidCredential is returned by Verify for presentations of a
+// credential that was not issued with the key
+var ErrInvalidCredential = errors.New("credential: invalid presentation")
+
+// generators returns G, the Ristretto base, and H, whose discrete log with
+// respect to G is unknown
+func generators() (G, H ristretto.Point) {
+	G.SetBase()
+	H = hash.HashToPoint("dusk.credential.H")
+	return G, H
+}
+
+// Attribute maps arbitrary data to an attribute
+func Attribute(data []byte) ristretto.Scalar {
+	return hash.HashToScalar("dusk.credential.attribute", data)
+}
+
+// SecretKey is the key of an issuer
+type SecretKey struct {
+	x0, x0Blind ristretto.Scalar
+	x           []ristretto.Scalar
+}
+
+// PublicKey are the public parameters of an issuer, which users check
+// issuance against
+type PublicKey struct {
+	// Cx0 = x0 * G + x0Blind * H
+	Cx0 ristretto.Point
+	// X[i] = x_i * H
+	X []ristretto.Point
+}
+
+// GenerateKey returns a random key for credentials with n attributes
+func GenerateKey(n int) (*SecretKey, error) {
+	if n < 1 || n > MaxAttributes {
+		return nil, errors.New("credential: invalid number of attributes")
+	}
+	sk := &SecretKey{x: make([]ristretto.Scalar, n)}
+	rng.Scalar(&sk.x0)
+	rng.Scalar(&sk.x0Blind)
+	for i := range sk.x {
+		rng.Scalar(&sk.x[i])
+	}
+	return sk, nil
+}
+
+// PublicKey returns the public parameters of the key
+func (sk *SecretKey) PublicKey() *PublicKey {
+	G, H := generators()
+	pk := &PublicKey{X: make([]ristretto.Point, len(sk.x))}
+
+	var t ristretto.Point
+	pk.Cx0.ScalarMult(&G, &sk.x0)
+	t.ScalarMult(&H, &sk.x0Blind)
+	pk.Cx0.Add(&pk.Cx0, &t)
+	for i := range sk.x {
+		pk.X[i].ScalarMult(&H, &sk.x[i])
+	}
+	return pk
+}
+
+// Ciphertext is the ElGamal encryption m * G + r * D, r * G of a hidden
+// attribute under the key D of the request
+type Ciphertext struct {
+	C1, C2 ristretto.Point
+}
+
+// Request asks the issuer for a credential on Attributes, the entries of
+// which are zero where Hidden is set. Hidden attributes are encrypted in
+// Ciphertexts, in order
+type Request struct {
+	Attributes  []ristretto.Scalar
+	Hidden      []bool
+	D           ristretto.Point
+	Ciphertexts []Ciphertext
+	Proof       sigma.Proof
+}
+
+// Pending is the state a user keeps between a request and the response of
+// the issuer
+type Pending struct {
+	attrs  []ristretto.Scalar
+	hidden []bool
+	d      ristretto.Scalar
+	req    *Request
+}
+
+// NewRequest requests a credential on attrs, hiding from the issuer the
+// attributes for which hidden is set
+func NewRequest(pk *PublicKey, attrs []ristretto.Scalar, hidden []bool) (*Request, *Pending, error) {
+	if len(attrs) != len(pk.X) || len(hidden) != len(attrs) {
+		return nil, nil, errors.New("credential: wrong number of attributes")
+	}
+
+	G, _ := generators()
+	p := &Pending{
+		attrs:  append([]ristretto.Scalar(nil), attrs...),
+		hidden: append([]bool(nil), hidden...),
+	}
+	rng.Scalar(&p.d)
+
+	req := &Request{
+		Attributes: make([]ristretto.Scalar, len(attrs)),
+		Hidden:     p.hidden,
+	}
+	req.D.ScalarMult(&G, &p.d)
+
+	// witness: d, then r and m of every hidden attribute
+	witness := []ristretto.Scalar{p.d}
+	for i := range attrs {
+		if !hidden[i] {
+			req.Attributes[i] = attrs[i]
+			continue
+		}
+		var r ristretto.Scalar
+		rng.Scalar(&r)
+		var c Ciphertext
+		var t ristretto.Point
+		c.C1.ScalarMult(&G, &r)
+		c.C2.ScalarMult(&G, &attrs[i])
+		t.ScalarMult(&req.D, &r)
+		c.C2.Add(&c.C2, &t)
+		req.Ciphertexts = append(req.Ciphertexts, c)
+		witness = append(witness, r, attrs[i])
+	}
+
+	proof, err := sigma.Prove(requestTranscript(pk, req), req.statement(), witness)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Proof = proof
+	p.req = req
+	return req, p, nil
+}
+
+func (req *Request) statement() sigma.Statement {
+	G, _ := generators()
+	st := sigma.Statement{
+		Vars:      1 + 2*len(req.Ciphertexts),
+		Equations: []sigma.Equation{{Image: req.D, Terms: []sigma.Term{{Var: 0, Base: G}}}},
+	}
+	for k, c := range req.Ciphertexts {
+		r, m := 1+2*k, 2+2*k
+		st.Equations = append(st.Equations,
+			sigma.Equation{Image: c.C1, Terms: []sigma.Term{{Var: r, Base: G}}},
+			sigma.Equation{Image: c.C2, Terms: []sigma.Term{{Var: m, Base: G}, {Var: r, Base: req.D}}},
+		)
+	}
+	return st
+}
+
+func (req *Request) check(pk *PublicKey) error {
+	if len(req.Attributes) != len(pk.X) || len(req.Hidden) != len(pk.X) {
+		return errors.New("credential: wrong number of attributes")
+	}
+	hidden := 0
+	for i, h := range req.Hidden {
+		if h {
+			if req.Attributes[i].IsNonZeroI() != 0 {
+				return errors.New("credential: hidden attribute in the clear")
+			}
+			hidden++
+		}
+	}
+	if hidden != len(req.Ciphertexts) {
+		return errors.New("credential: wrong number of ciphertexts")
+	}
+	return nil
+}
+
+func requestTranscript(pk *PublicKey, req *Request) *transcript.Transcript {
+	t := transcript.New("dusk.credential.request")
+	appendPublicKey(t, pk)
+	for i := range req.Attributes {
+		if req.Hidden[i] {
+			t.AppendUint64("hidden", uint64(i))
+			continue
+		}
+		t.AppendScalar("attribute", req.Attributes[i])
+	}
+	return t
+}
+
+func appendPublicKey(t *transcript.Transcript, pk *PublicKey) {
+	t.AppendPoint("Cx0", pk.Cx0)
+	for i := range pk.X {
+		t.AppendPoint("X", pk.X[i])
+	}
+}
+
+// Response is the answer of the issuer to a request. (E1, E2) encrypts U'
+// under the key of the request
+type Response struct {
+	U      ristretto.Point
+	T      []ristretto.Point
+	E1, E2 ristretto.Point
+	Proof  sigma.Proof
+}
+
+// Issue checks a request and issues a credential on its attributes. The
+// issuer decides beforehand whether it accepts the disclosed attributes
+func (sk *SecretKey) Issue(req *Request) (*Response, error) {
+	pk := sk.PublicKey()
+	if err := req.check(pk); err != nil {
+		return nil, err
+	}
+	if !sigma.Verify(requestTranscript(pk, req), req.statement(), req.Proof) {
+		return nil, errors.New("credential: invalid request proof")
+	}
+
+	G, _ := generators()
+	var b, r ristretto.Scalar
+	rng.Scalar(&b)
+	rng.Scalar(&r)
+
+	resp := &Response{}
+	resp.U.ScalarMult(&G, &b)
+
+	// witness: x0, x0Blind, x_i, b, r, then b * x_i for every hidden
+	// attribute
+	witness := []ristretto.Scalar{sk.x0, sk.x0Blind}
+	witness = append(witness, sk.x...)
+	witness = append(witness, b, r)
+
+	var t ristretto.Point
+	var e ristretto.Scalar
+	resp.E1.ScalarMult(&G, &r)
+	resp.E2.ScalarMult(&req.D, &r)
+	// public part of the MAC: (x0 + sum(x_i * m_i)) * U
+	e.Set(&sk.x0)
+	for i := range sk.x {
+		if !req.Hidden[i] {
+			e.MulAdd(&sk.x[i], &req.Attributes[i], &e)
+		}
+	}
+	t.ScalarMult(&resp.U, &e)
+	resp.E2.Add(&resp.E2, &t)
+
+	k := 0
+	for i := range sk.x {
+		if !req.Hidden[i] {
+			continue
+		}
+		var bx ristretto.Scalar
+		bx.Mul(&b, &sk.x[i])
+		witness = append(witness, bx)
+
+		var T ristretto.Point
+		T.ScalarMult(&pk.X[i], &b)
+		resp.T = append(resp.T, T)
+
+		c := &req.Ciphertexts[k]
+		t.ScalarMult(&c.C1, &bx)
+		resp.E1.Add(&resp.E1, &t)
+		t.ScalarMult(&c.C2, &bx)
+		resp.E2.Add(&resp.E2, &t)
+		k++
+	}
+
+	st, err := resp.statement(pk, req)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := sigma.Prove(responseTranscript(pk, req, resp), st, witness)
+	if err != nil {
+		return nil, err
+	}
+	resp.Proof = proof
+	return resp, nil
+}
+
+func (resp *Response) statement(pk *PublicKey, req *Request) (sigma.Statement, error) {
+	if len(resp.T) != len(req.Ciphertexts) {
+		return sigma.Statement{}, errors.New("credential: wrong number of response points")
+	}
+
+	G, H := generators()
+	n := len(pk.X)
+	x0, x0Blind, b, r := 0, 1, 2+n, 3+n
+	x := func(i int) int { return 2 + i }
+	bx := func(k int) int { return 4 + n + k }
+
+	st := sigma.Statement{Vars: 4 + n + len(resp.T)}
+	st.Equations = append(st.Equations,
+		sigma.Equation{Image: pk.Cx0, Terms: []sigma.Term{{Var: x0, Base: G}, {Var: x0Blind, Base: H}}},
+		sigma.Equation{Image: resp.U, Terms: []sigma.Term{{Var: b, Base: G}}},
+	)
+	for i := range pk.X {
+		st.Equations = append(st.Equations, sigma.Equation{Image: pk.X[i], Terms: []sigma.Term{{Var: x(i), Base: H}}})
+	}
+
+	e1 := sigma.Equation{Image: resp.E1, Terms: []sigma.Term{{Var: r, Base: G}}}
+	e2 := sigma.Equation{Image: resp.E2, Terms: []sigma.Term{{Var: r, Base: req.D}, {Var: x0, Base: resp.U}}}
+	k := 0
+	for i := range pk.X {
+		if !req.Hidden[i] {
+			var mU ristretto.Point
+			mU.ScalarMult(&resp.U, &req.Attributes[i])
+			e2.Terms = append(e2.Terms, sigma.Term{Var: x(i), Base: mU})
+			continue
+		}
+		// T_k = b * X_i = (b * x_i) * H binds the product to the key
+		st.Equations = append(st.Equations,
+			sigma.Equation{Image: resp.T[k], Terms: []sigma.Term{{Var: b, Base: pk.X[i]}}},
+			sigma.Equation{Image: resp.T[k], Terms: []sigma.Term{{Var: bx(k), Base: H}}},
+		)
+		c := &req.Ciphertexts[k]
+		e1.Terms = append(e1.Terms, sigma.Term{Var: bx(k), Base: c.C1})
+		e2.Terms = append(e2.Terms, sigma.Term{Var: bx(k), Base: c.C2})
+		k++
+	}
+	st.Equations = append(st.Equations, e1, e2)
+	return st, nil
+}
+
+func responseTranscript(pk *PublicKey, req *Request, resp *Response) *transcript.Transcript {
+	t := requestTranscript(pk, req)
+	t.Append("dom-sep", []byte("dusk.credential.issue"))
+	t.AppendPoint("D", req.D)
+	for _, c := range req.Ciphertexts {
+		t.AppendPoint("C1", c.C1)
+		t.AppendPoint("C2", c.C2)
+	}
+	return t
+}
+
+// Credential is a MAC on attributes, held by the user
+type Credential struct {
+	Attributes []ristretto.Scalar
+	U, V       ristretto.Point
+}
+
+// Finalize checks the response of the issuer and decrypts the credential
+func (p *Pending) Finalize(pk *PublicKey, resp *Response) (*Credential, error) {
+	if isIdentity(resp.U) {
+		return nil, errors.New("credential: identity MAC base")
+	}
+	st, err := resp.statement(pk, p.req)
+	if err != nil {
+		return nil, err
+	}
+	if !sigma.Verify(responseTranscript(pk, p.req, resp), st, resp.Proof) {
+		return nil, errors.New("credential: invalid issuance proof")
+	}
+
+	cred := &Credential{
+		Attributes: append([]ristretto.Scalar(nil), p.attrs...),
+		U:          resp.U,
+	}
+	var t ristretto.Point
+	t.ScalarMult(&resp.E1, &p.d)
+	cred.V.Sub(&resp.E2, &t)
+	return cred, nil
+}
+
+// Presentation shows a credential, disclosing Attributes where Disclosed
+// is set. Commitments hold m_i * U + z_i * H for the other attributes, in
+// order, and can be related to other proofs by the caller
+type Presentation struct {
+	U, CV       ristretto.Point
+	Disclosed   []bool
+	Attributes  []ristretto.Scalar
+	Commitments []ristretto.Point
+	Proof       sigma.Proof
+}
+
+// Present shows the credential to the issuer, disclosing the attributes
+// for which disclose is set. msg is bound to the presentation, e.g. a
+// nonce of the verifier preventing replays
+func (cred *Credential) Present(pk *PublicKey, disclose []bool, msg []byte) (*Presentation, error) {
+	if len(cred.Attributes) != len(pk.X) || len(disclose) != len(pk.X) {
+		return nil, errors.New("credential: wrong number of attributes")
+	}
+
+	G, H := generators()
+	var a, r ristretto.Scalar
+	rng.Scalar(&a)
+	rng.Scalar(&r)
+
+	p := &Presentation{
+		Disclosed:  append([]bool(nil), disclose...),
+		Attributes: make([]ristretto.Scalar, len(disclose)),
+	}
+	var t ristretto.Point
+	p.U.ScalarMult(&cred.U, &a)
+	p.CV.ScalarMult(&cred.V, &a)
+	t.ScalarMult(&G, &r)
+	p.CV.Add(&p.CV, &t)
+
+	// witness: r, then z and m of every hidden attribute
+	witness := []ristretto.Scalar{r}
+	for i := range disclose {
+		if disclose[i] {
+			p.Attributes[i] = cred.Attributes[i]
+			continue
+		}
+		var z ristretto.Scalar
+		rng.Scalar(&z)
+		var C ristretto.Point
+		C.ScalarMult(&p.U, &cred.Attributes[i])
+		t.ScalarMult(&H, &z)
+		C.Add(&C, &t)
+		p.Commitments = append(p.Commitments, C)
+		witness = append(witness, z, cred.Attributes[i])
+	}
+
+	// V = sum(z_i * X_i) - r * G, as the issuer recomputes it
+	var V ristretto.Point
+	V.ScalarMult(&G, &r)
+	V.Neg(&V)
+	k := 0
+	for i := range disclose {
+		if !disclose[i] {
+			t.ScalarMult(&pk.X[i], &witness[1+2*k])
+			V.Add(&V, &t)
+			k++
+		}
+	}
+
+	proof, err := sigma.Prove(presentationTranscript(pk, p, msg), p.statement(pk, V), witness)
+	if err != nil {
+		return nil, err
+	}
+	p.Proof = proof
+	return p, nil
+}
+
+func (p *Presentation) statement(pk *PublicKey, V ristretto.Point) sigma.Statement {
+	G, H := generators()
+	var negG ristretto.Point
+	negG.Neg(&G)
+
+	st := sigma.Statement{Vars: 1 + 2*len(p.Commitments)}
+	eqV := sigma.Equation{Image: V, Terms: []sigma.Term{{Var: 0, Base: negG}}}
+	k := 0
+	for i := range p.Disclosed {
+		if p.Disclosed[i] {
+			continue
+		}
+		z, m := 1+2*k, 2+2*k
+		eqV.Terms = append(eqV.Terms, sigma.Term{Var: z, Base: pk.X[i]})
+		st.Equations = append(st.Equations, sigma.Equation{
+			Image: p.Commitments[k],
+			Terms: []sigma.Term{{Var: m, Base: p.U}, {Var: z, Base: H}},
+		})
+		k++
+	}
+	st.Equations = append(st.Equations, eqV)
+	return st
+}
+
+func presentationTranscript(pk *PublicKey, p *Presentation, msg []byte) *transcript.Transcript {
+	t := transcript.New("dusk.credential.present")
+	appendPublicKey(t, pk)
+	t.Append("msg", msg)
+	for i := range p.Disclosed {
+		if p.Disclosed[i] {
+			t.AppendUint64("disclosed", uint64(i))
+			t.AppendScalar("attribute", p.Attributes[i])
+		}
+	}
+	t.AppendPoint("CV", p.CV)
+	return t
+}
+
+func (p *Presentation) check(n int) error {
+	if len(p.Disclosed) != n || len(p.Attributes) != n {
+		return errors.New("credential: wrong number of attributes")
+	}
+	hidden := 0
+	for _, d := range p.Disclosed {
+		if !d {
+			hidden++
+		}
+	}
+	if hidden != len(p.Commitments) {
+		return errors.New("credential: wrong number of commitments")
+	}
+	if isIdentity(p.U) {
+		return errors.New("credential: identity MAC base")
+	}
+	return nil
+}
+
+// Verify checks a presentation bound to msg. The disclosed attributes are
+// then known to be certified, and the caller checks them against its policy
+func (sk *SecretKey) Verify(p *Presentation, msg []byte) error {
+	if err := p.check(len(sk.x)); err != nil {
+		return err
+	}
+
+	// V = x0 * U + sum(x_i * m_i * U) + sum(x_i * C_i) - CV
+	var V, t ristretto.Point
+	var e ristretto.Scalar
+	V.SetZero()
+	e.Set(&sk.x0)
+	k := 0
+	for i := range sk.x {
+		if p.Disclosed[i] {
+			e.MulAdd(&sk.x[i], &p.Attributes[i], &e)
+			continue
+		}
+		t.ScalarMult(&p.Commitments[k], &sk.x[i])
+		V.Add(&V, &t)
+		k++
+	}
+	t.ScalarMult(&p.U, &e)
+	V.Add(&V, &t)
+	V.Sub(&V, &p.CV)
+
+	pk := sk.PublicKey()
+	if !sigma.Verify(presentationTranscript(pk, p, msg), p.statement(pk, V), p.Proof) {
+		return ErrInvalidCredential
+	}
+	return nil
+}
+
+func isIdentity(p ristretto.Point) bool {
+	var zero ristretto.Point
+	zero.SetZero()
+	return p.Equals(&zero)
+}
+
+// Encode a Presentation
+func (p *Presentation) Encode(w io.Writer) error {
+	if len(p.Disclosed) > MaxAttributes {
+		return errors.New("credential: too many attributes")
+	}
+	if err := writePoints(w, p.U, p.CV); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint8(len(p.Disclosed))); err != nil {
+		return err
+	}
+	k := 0
+	for i, d := range p.Disclosed {
+		if d {
+			if err := binary.Write(w, binary.BigEndian, uint8(1)); err != nil {
+				return err
+			}
+			if err := binary.Write(w, binary.BigEndian, p.Attributes[i].Bytes()); err != nil {
+				return err
+			}
+			continue
+		}
+		if k >= len(p.Commitments) {
+			return errors.New("credential: wrong number of commitments")
+		}
+		if err := binary.Write(w, binary.BigEndian, uint8(0)); err != nil {
+			return err
+		}
+		if err := writePoints(w, p.Commitments[k]); err != nil {
+			return err
+		}
+		k++
+	}
+	return p.Proof.Encode(w)
+}
+
+// Decode a Presentation
+func (p *Presentation) Decode(r io.Reader) error {
+	if p == nil {
+		return errors.New("struct is nil")
+	}
+
+	if err := readPoints(r, &p.U, &p.CV); err != nil {
+		return err
+	}
+	var n uint8
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return err
+	}
+	if n > MaxAttributes {
+		return errors.New("credential: too many attributes")
+	}
+	p.Disclosed = make([]bool, n)
+	p.Attributes = make([]ristretto.Scalar, n)
+	p.Commitments = nil
+	for i := range p.Disclosed {
+		var flag uint8
+		if err := binary.Read(r, binary.BigEndian, &flag); err != nil {
+			return err
+		}
+		switch flag {
+		case 1:
+			p.Disclosed[i] = true
+			if err := readScalar(r, &p.Attributes[i]); err != nil {
+				return err
+			}
+		case 0:
+			var C ristretto.Point
+			if err := readPoints(r, &C); err != nil {
+				return err
+			}
+			p.Commitments = append(p.Commitments, C)
+		default:
+			return errors.New("credential: invalid disclosure flag")
+		}
+	}
+	return p.Proof.Decode(r)
+}
+
+func writePoints(w io.Writer, points ...ristretto.Point) error {
+	for _, p := range points {
+		if err := binary.Write(w, binary.BigEndian, p.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readPoints(r io.Reader, points ...*ristretto.Point) error {
+	var x [32]byte
+	for _, p := range points {
+		if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+			return err
+		}
+		if !p.SetBytes(&x) {
+			return errors.New("point not encodable")
+		}
+	}
+	return nil
+}
+
+func readScalar(r io.Reader, s *ristretto.Scalar) error {
+	var x [32]byte
+	if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+		return err
+	}
+	s.SetBytes(&x)
+	if !bytes.Equal(s.Bytes(), x[:]) {
+		return errors.New("scalar is not canonically encoded")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package credential
+
+import (
+	"bytes"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func issue(t *testing.T, sk *SecretKey, attrs []ristretto.Scalar, hidden []bool) *Credential {
+	pk := sk.PublicKey()
+	req, pending, err := NewRequest(pk, attrs, hidden)
+	require.Nil(t, err)
+	resp, err := sk.Issue(req)
+	require.Nil(t, err)
+	cred, err := pending.Finalize(pk, resp)
+	require.Nil(t, err)
+	return cred
+}
+
+func testAttributes() []ristretto.Scalar {
+	return []ristretto.Scalar{
+		Attribute([]byte("alice")),
+		Attribute([]byte("tier: gold")),
+		Attribute([]byte("expires: 2030")),
+	}
+}
+
+func TestIssuePresent(t *testing.T) {
+	sk, err := GenerateKey(3)
+	require.Nil(t, err)
+	pk := sk.PublicKey()
+	attrs := testAttributes()
+
+	for _, hidden := range [][]bool{{false, false, false}, {true, false, false}, {true, true, true}} {
+		cred := issue(t, sk, attrs, hidden)
+
+		for _, disclose := range [][]bool{{false, true, false}, {true, true, true}, {false, false, false}} {
+			p, err := cred.Present(pk, disclose, []byte("nonce"))
+			require.Nil(t, err)
+			assert.Nil(t, sk.Verify(p, []byte("nonce")))
+			assert.Equal(t, ErrInvalidCredential, sk.Verify(p, []byte("other nonce")))
+		}
+	}
+}
+
+func TestForgeries(t *testing.T) {
+	sk, err := GenerateKey(3)
+	require.Nil(t, err)
+	pk := sk.PublicKey()
+	cred := issue(t, sk, testAttributes(), []bool{true, false, false})
+
+	// a disclosed attribute cannot be changed
+	p, err := cred.Present(pk, []bool{false, true, false}, nil)
+	require.Nil(t, err)
+	p.Attributes[1] = Attribute([]byte("tier: platinum"))
+	assert.NotNil(t, sk.Verify(p, nil))
+
+	// nor can the credential be shown to another issuer
+	other, err := GenerateKey(3)
+	require.Nil(t, err)
+	p, err = cred.Present(other.PublicKey(), []bool{false, true, false}, nil)
+	require.Nil(t, err)
+	assert.NotNil(t, other.Verify(p, nil))
+
+	// a credential on other attributes does not verify
+	forged := *cred
+	forged.Attributes = append([]ristretto.Scalar(nil), cred.Attributes...)
+	forged.Attributes[2] = Attribute([]byte("expires: 2099"))
+	p, err = forged.Present(pk, []bool{false, false, true}, nil)
+	require.Nil(t, err)
+	assert.NotNil(t, sk.Verify(p, nil))
+}
+
+func TestIssuanceChecks(t *testing.T) {
+	sk, err := GenerateKey(3)
+	require.Nil(t, err)
+	pk := sk.PublicKey()
+
+	req, pending, err := NewRequest(pk, testAttributes(), []bool{true, false, true})
+	require.Nil(t, err)
+
+	// the issuer rejects requests whose ciphertexts are not proven
+	tampered := *req
+	tampered.Ciphertexts = append([]Ciphertext(nil), req.Ciphertexts...)
+	tampered.Ciphertexts[0].C2 = req.Ciphertexts[1].C2
+	_, err = sk.Issue(&tampered)
+	assert.NotNil(t, err)
+
+	// requests are bound to the public key of the issuer
+	other, err := GenerateKey(3)
+	require.Nil(t, err)
+	_, err = other.Issue(req)
+	assert.NotNil(t, err)
+
+	// the user rejects a response that does not match the public key
+	resp, err := sk.Issue(req)
+	require.Nil(t, err)
+	tag := *resp
+	tag.E2.Add(&tag.E2, &tag.U)
+	_, err = pending.Finalize(pk, &tag)
+	assert.NotNil(t, err)
+	_, err = pending.Finalize(other.PublicKey(), resp)
+	assert.NotNil(t, err)
+	_, err = pending.Finalize(pk, resp)
+	assert.Nil(t, err)
+
+	_, _, err = NewRequest(pk, testAttributes()[:2], []bool{false, false})
+	assert.NotNil(t, err)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	sk, err := GenerateKey(3)
+	require.Nil(t, err)
+	pk := sk.PublicKey()
+	cred := issue(t, sk, testAttributes(), []bool{false, false, false})
+
+	p, err := cred.Present(pk, []bool{true, false, true}, []byte("nonce"))
+	require.Nil(t, err)
+	buf := new(bytes.Buffer)
+	require.Nil(t, p.Encode(buf))
+
+	var dec Presentation
+	require.Nil(t, dec.Decode(buf))
+	assert.Equal(t, p.Disclosed, dec.Disclosed)
+	assert.Nil(t, sk.Verify(&dec, []byte("nonce")))
+}
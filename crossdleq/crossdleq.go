@@ -0,0 +1,435 @@
+// Package crossdleq proves that a Ristretto public key x * G and a BLS
+// public key x * G2 share the same secret x, so that a node can bind its
+// consensus identity to its wallet identity.
+//
+// The groups have different orders, so x cannot be proven equal with a
+// plain DLEQ proof. Instead, x is restricted to Bits bits and committed to
+// bit by bit in both Ristretto and bn256 G1, with commitments that add up
+// to x * G and to X1 = x * G1. A two member ring signature per bit proves
+// that both commitments hide the same bit. X1 is finally tied to the BLS
+// key with the pairing: e(X1, G2) = e(G1, x * G2).
+//
+// Proofs are large, about 64KB, and are meant to be verified once, when an
+// identity is registered
+package crossdleq
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// Bits is the size of the secrets, which must be below both group orders
+const Bits = 252
+
+// challengeSize is the size of the ring challenges, which are used as
+// integers in both groups and must be below both orders
+const challengeSize = 31
+
+var (
+	// hR is the Ristretto blinding base
+	hR = hash.HashToPoint("dusk.crossdleq.h")
+	// h1 is the G1 blinding base
+	h1 = hash.HashToG1("dusk.crossdleq.h")
+	// g1 is the generator of G1
+	g1 = new(bn256.G1).ScalarBaseMult(big.NewInt(1))
+	// g2 is the generator of G2, which BLS public keys are multiples of
+	g2 = new(bn256.G2).ScalarBaseMult(big.NewInt(1))
+)
+
+// GenerateSecret returns a random secret of Bits bits
+func GenerateSecret(r io.Reader) (*big.Int, error) {
+	if r == nil {
+		r = rng.Reader
+	}
+	max := new(big.Int).Lsh(big.NewInt(1), Bits)
+	for {
+		x, err := rand.Int(r, max)
+		if err != nil {
+			return nil, err
+		}
+		if x.Sign() != 0 {
+			return x, nil
+		}
+	}
+}
+
+// Keys returns the Ristretto and BLS keys of the secret x
+func Keys(x *big.Int) (ristretto.Scalar, *bls.SecretKey, error) {
+	var s ristretto.Scalar
+	if err := checkSecret(x); err != nil {
+		return s, nil, err
+	}
+	s.SetBigInt(x)
+
+	b := make([]byte, 32)
+	xb := x.Bytes()
+	copy(b[32-len(xb):], xb)
+	sk, err := bls.UnmarshalSk(b)
+	if err != nil {
+		return s, nil, err
+	}
+	return s, sk, nil
+}
+
+func checkSecret(x *big.Int) error {
+	if x == nil || x.Sign() <= 0 || x.BitLen() > Bits {
+		return errors.New("secret out of range")
+	}
+	return nil
+}
+
+// Proof proves that a Ristretto and a BLS public key share their secret
+type Proof struct {
+	// X1 = x * G1
+	X1 *bn256.G1
+	// C[i] = b_i * G + r_i * H, with sum(2^i * r_i) = 0
+	C []ristretto.Point
+	// D[i] = b_i * G1 + s_i * H1, with sum(2^i * s_i) = 0
+	D []*bn256.G1
+	// E[i] is the first challenge of the ring of bit i
+	E [][challengeSize]byte
+	// S[i] and T[i] are the responses of the ring of bit i, in each group
+	S [][2]ristretto.Scalar
+	T [][2]*big.Int
+}
+
+// Prove proves that x * G and x * G2 share x. The proof is bound to context
+func Prove(x *big.Int, context []byte) (*Proof, error) {
+	xs, sk, err := Keys(x)
+	if err != nil {
+		return nil, err
+	}
+	var P ristretto.Point
+	P.ScalarMultBase(&xs)
+	Q := sk.PublicKey()
+
+	proof := &Proof{
+		X1: new(bn256.G1).ScalarMult(g1, x),
+		C:  make([]ristretto.Point, Bits),
+		D:  make([]*bn256.G1, Bits),
+		E:  make([][challengeSize]byte, Bits),
+		S:  make([][2]ristretto.Scalar, Bits),
+		T:  make([][2]*big.Int, Bits),
+	}
+
+	r, s, err := blinders()
+	if err != nil {
+		return nil, err
+	}
+
+	var G ristretto.Point
+	G.SetBase()
+	bits := make([]int, Bits)
+	for i := range bits {
+		bits[i] = int(x.Bit(i))
+
+		proof.C[i].ScalarMult(&hR, &r[i])
+		proof.D[i] = new(bn256.G1).ScalarMult(h1, s[i])
+		if bits[i] == 1 {
+			proof.C[i].Add(&proof.C[i], &G)
+			proof.D[i].Add(proof.D[i], g1)
+		}
+	}
+
+	digest := statementDigest(P, Q, context, proof)
+	for i := range bits {
+		if err := proof.proveBit(digest, i, bits[i], r[i], s[i]); err != nil {
+			return nil, err
+		}
+	}
+	return proof, nil
+}
+
+// blinders returns random r_i mod l and s_i mod Order such that
+// sum(2^i * r_i) = 0 and sum(2^i * s_i) = 0
+func blinders() ([]ristretto.Scalar, []*big.Int, error) {
+	r := make([]ristretto.Scalar, Bits)
+	s := make([]*big.Int, Bits)
+
+	var sumR, pow, two ristretto.Scalar
+	sumR.SetZero()
+	pow.SetOne()
+	two.SetBigInt(big.NewInt(2))
+	sumS := new(big.Int)
+	for i := 0; i < Bits-1; i++ {
+		rng.Scalar(&r[i])
+		var tmp ristretto.Scalar
+		tmp.Mul(&pow, &r[i])
+		sumR.Add(&sumR, &tmp)
+		pow.Mul(&pow, &two)
+
+		var err error
+		if s[i], err = rng.Int(bn256.Order); err != nil {
+			return nil, nil, err
+		}
+		sumS.Add(sumS, new(big.Int).Lsh(s[i], uint(i)))
+	}
+
+	// r_last = -sumR / 2^(Bits-1)
+	var inv ristretto.Scalar
+	inv.Inverse(&pow)
+	r[Bits-1].Mul(&sumR, &inv)
+	r[Bits-1].Neg(&r[Bits-1])
+
+	invS := new(big.Int).Lsh(big.NewInt(1), Bits-1)
+	invS.ModInverse(invS, bn256.Order)
+	s[Bits-1] = new(big.Int).Neg(sumS)
+	s[Bits-1].Mul(s[Bits-1], invS)
+	s[Bits-1].Mod(s[Bits-1], bn256.Order)
+	return r, s, nil
+}
+
+func statementDigest(P ristretto.Point, Q *bls.PublicKey, context []byte, proof *Proof) []byte {
+	var cs, ds []byte
+	for i := range proof.C {
+		cs = append(cs, proof.C[i].Bytes()...)
+		ds = append(ds, proof.D[i].Marshal()...)
+	}
+	return hash.Sha3256WithDomain("dusk.crossdleq", P.Bytes(), Q.Marshal(), proof.X1.Marshal(), context, cs, ds)
+}
+
+// challenge returns the challenge of the ring member following m
+func challenge(digest []byte, i, m int, R ristretto.Point, R1 *bn256.G1) [challengeSize]byte {
+	var idx [5]byte
+	binary.BigEndian.PutUint32(idx[:4], uint32(i))
+	idx[4] = byte(m)
+
+	var e [challengeSize]byte
+	copy(e[:], hash.Sha3256WithDomain("dusk.crossdleq.bit", digest, idx[:], R.Bytes(), R1.Marshal()))
+	return e
+}
+
+// memberImages returns C - m * G and D - m * G1, which are r * H and
+// s * H1 for the member m matching the committed bit
+func (p *Proof) memberImages(i, m int) (ristretto.Point, *bn256.G1) {
+	c := p.C[i]
+	d := new(bn256.G1).Set(p.D[i])
+	if m == 1 {
+		var G ristretto.Point
+		G.SetBase()
+		c.Sub(&c, &G)
+		d.Add(d, new(bn256.G1).Neg(g1))
+	}
+	return c, d
+}
+
+// ringCommitments returns s * H - e * (C - m * G) and t * H1 - e * (D - m * G1)
+func (p *Proof) ringCommitments(i, m int, e [challengeSize]byte) (ristretto.Point, *bn256.G1) {
+	c, d := p.memberImages(i, m)
+	eInt := new(big.Int).SetBytes(e[:])
+
+	var eR ristretto.Scalar
+	eR.SetBigInt(eInt)
+	var R, tmp ristretto.Point
+	R.ScalarMult(&hR, &p.S[i][m])
+	tmp.ScalarMult(&c, &eR)
+	R.Sub(&R, &tmp)
+
+	R1 := new(bn256.G1).ScalarMult(h1, p.T[i][m])
+	R1.Add(R1, new(bn256.G1).Neg(new(bn256.G1).ScalarMult(d, eInt)))
+	return R, R1
+}
+
+// proveBit signs the ring of bit i, whose member b is the real one
+func (p *Proof) proveBit(digest []byte, i, b int, r ristretto.Scalar, s *big.Int) error {
+	var k ristretto.Scalar
+	rng.Scalar(&k)
+	k1, err := rng.Int(bn256.Order)
+	if err != nil {
+		return err
+	}
+
+	var R ristretto.Point
+	R.ScalarMult(&hR, &k)
+	eOther := challenge(digest, i, b, R, new(bn256.G1).ScalarMult(h1, k1))
+
+	// Simulate the other member
+	other := 1 - b
+	rng.Scalar(&p.S[i][other])
+	if p.T[i][other], err = rng.Int(bn256.Order); err != nil {
+		return err
+	}
+	Ro, R1o := p.ringCommitments(i, other, eOther)
+	eReal := challenge(digest, i, other, Ro, R1o)
+
+	if b == 0 {
+		p.E[i] = eReal
+	} else {
+		p.E[i] = eOther
+	}
+
+	// Close the ring
+	eInt := new(big.Int).SetBytes(eReal[:])
+	var eR ristretto.Scalar
+	eR.SetBigInt(eInt)
+	p.S[i][b].MulAdd(&eR, &r, &k)
+
+	t := new(big.Int).Mul(eInt, s)
+	t.Add(t, k1)
+	p.T[i][b] = t.Mod(t, bn256.Order)
+	return nil
+}
+
+// Verify checks that the Ristretto public key P and the BLS public key Q
+// share their secret
+func Verify(P ristretto.Point, Q *bls.PublicKey, context []byte, proof *Proof) bool {
+	if proof == nil || proof.X1 == nil || Q == nil {
+		return false
+	}
+	if len(proof.C) != Bits || len(proof.D) != Bits || len(proof.E) != Bits ||
+		len(proof.S) != Bits || len(proof.T) != Bits {
+		return false
+	}
+	for i := range proof.D {
+		if proof.D[i] == nil || proof.T[i][0] == nil || proof.T[i][1] == nil {
+			return false
+		}
+	}
+
+	// e(X1, G2) = e(G1, Q)
+	q := new(bn256.G2)
+	if _, err := q.Unmarshal(Q.Marshal()); err != nil {
+		return false
+	}
+	if !bytes.Equal(bn256.Pair(proof.X1, g2).Marshal(), bn256.Pair(g1, q).Marshal()) {
+		return false
+	}
+
+	// sum(2^i * C[i]) = P and sum(2^i * D[i]) = X1
+	var c ristretto.Point
+	c.SetZero()
+	d := new(bn256.G1).ScalarBaseMult(new(big.Int))
+	for i := Bits - 1; i >= 0; i-- {
+		c.Add(&c, &c)
+		c.Add(&c, &proof.C[i])
+		// bn256 does not double a point added to itself in place
+		d.Add(new(bn256.G1).Set(d), d)
+		d.Add(d, proof.D[i])
+	}
+	if !c.Equals(&P) || !bytes.Equal(d.Marshal(), proof.X1.Marshal()) {
+		return false
+	}
+
+	digest := statementDigest(P, Q, context, proof)
+	for i := 0; i < Bits; i++ {
+		e := proof.E[i]
+		for m := 0; m < 2; m++ {
+			R, R1 := proof.ringCommitments(i, m, e)
+			e = challenge(digest, i, m, R, R1)
+		}
+		if e != proof.E[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Encode a Proof
+func (p *Proof) Encode(w io.Writer) error {
+	if len(p.C) != Bits || len(p.D) != Bits || len(p.E) != Bits || len(p.S) != Bits || len(p.T) != Bits {
+		return errors.New("malformed proof")
+	}
+	if err := binary.Write(w, binary.BigEndian, p.X1.Marshal()); err != nil {
+		return err
+	}
+	for i := 0; i < Bits; i++ {
+		if err := binary.Write(w, binary.BigEndian, p.C[i].Bytes()); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, p.D[i].Marshal()); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, p.E[i]); err != nil {
+			return err
+		}
+		for m := 0; m < 2; m++ {
+			if err := binary.Write(w, binary.BigEndian, p.S[i][m].Bytes()); err != nil {
+				return err
+			}
+			t := make([]byte, 32)
+			tb := p.T[i][m].Bytes()
+			copy(t[32-len(tb):], tb)
+			if err := binary.Write(w, binary.BigEndian, t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Decode a Proof
+func (p *Proof) Decode(r io.Reader) error {
+	if p == nil {
+		return errors.New("struct is nil")
+	}
+
+	var g1Buf [64]byte
+	if _, err := io.ReadFull(r, g1Buf[:]); err != nil {
+		return err
+	}
+	x1 := new(bn256.G1)
+	if _, err := x1.Unmarshal(g1Buf[:]); err != nil {
+		return err
+	}
+
+	dec := Proof{
+		X1: x1,
+		C:  make([]ristretto.Point, Bits),
+		D:  make([]*bn256.G1, Bits),
+		E:  make([][challengeSize]byte, Bits),
+		S:  make([][2]ristretto.Scalar, Bits),
+		T:  make([][2]*big.Int, Bits),
+	}
+	var x [32]byte
+	for i := 0; i < Bits; i++ {
+		if _, err := io.ReadFull(r, x[:]); err != nil {
+			return err
+		}
+		if !dec.C[i].SetBytes(&x) {
+			return errors.New("point not encodable")
+		}
+
+		if _, err := io.ReadFull(r, g1Buf[:]); err != nil {
+			return err
+		}
+		dec.D[i] = new(bn256.G1)
+		if _, err := dec.D[i].Unmarshal(g1Buf[:]); err != nil {
+			return err
+		}
+
+		if _, err := io.ReadFull(r, dec.E[i][:]); err != nil {
+			return err
+		}
+
+		for m := 0; m < 2; m++ {
+			if _, err := io.ReadFull(r, x[:]); err != nil {
+				return err
+			}
+			dec.S[i][m].SetBytes(&x)
+			if !bytes.Equal(dec.S[i][m].Bytes(), x[:]) {
+				return errors.New("scalar is not canonically encoded")
+			}
+
+			if _, err := io.ReadFull(r, x[:]); err != nil {
+				return err
+			}
+			dec.T[i][m] = new(big.Int).SetBytes(x[:])
+			if dec.T[i][m].Cmp(bn256.Order) >= 0 {
+				return errors.New("scalar is not canonically encoded")
+			}
+		}
+	}
+
+	*p = dec
+	return nil
+}
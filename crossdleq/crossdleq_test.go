@@ -0,0 +1,78 @@
+package crossdleq
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func publicKeys(t *testing.T, x *big.Int) (ristretto.Point, *bls.PublicKey) {
+	xs, sk, err := Keys(x)
+	require.NoError(t, err)
+	var P ristretto.Point
+	P.ScalarMultBase(&xs)
+	return P, sk.PublicKey()
+}
+
+func TestProveVerify(t *testing.T) {
+	x, err := GenerateSecret(nil)
+	require.NoError(t, err)
+	P, Q := publicKeys(t, x)
+
+	proof, err := Prove(x, []byte("node 1"))
+	require.NoError(t, err)
+	assert.True(t, Verify(P, Q, []byte("node 1"), proof))
+	assert.False(t, Verify(P, Q, []byte("node 2"), proof))
+
+	// Keys of another secret
+	y, err := GenerateSecret(nil)
+	require.NoError(t, err)
+	P2, Q2 := publicKeys(t, y)
+	assert.False(t, Verify(P2, Q, []byte("node 1"), proof))
+	assert.False(t, Verify(P, Q2, []byte("node 1"), proof))
+
+	// Tampered ring
+	proof.S[7][0], proof.S[7][1] = proof.S[7][1], proof.S[7][0]
+	assert.False(t, Verify(P, Q, []byte("node 1"), proof))
+}
+
+func TestSmallSecret(t *testing.T) {
+	x := big.NewInt(5)
+	P, Q := publicKeys(t, x)
+	proof, err := Prove(x, nil)
+	require.NoError(t, err)
+	assert.True(t, Verify(P, Q, nil, proof))
+}
+
+func TestSecretRange(t *testing.T) {
+	_, err := Prove(new(big.Int), nil)
+	assert.Error(t, err)
+	_, err = Prove(new(big.Int).Lsh(big.NewInt(1), Bits), nil)
+	assert.Error(t, err)
+	_, _, err = Keys(big.NewInt(-1))
+	assert.Error(t, err)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	x, err := GenerateSecret(nil)
+	require.NoError(t, err)
+	P, Q := publicKeys(t, x)
+	proof, err := Prove(x, nil)
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, proof.Encode(buf))
+	assert.Equal(t, 64+Bits*(32+64+challengeSize+4*32), buf.Len())
+
+	var dec Proof
+	require.NoError(t, dec.Decode(buf))
+	assert.True(t, Verify(P, Q, nil, &dec))
+
+	var nilProof *Proof
+	assert.Error(t, nilProof.Decode(buf))
+}
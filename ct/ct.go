@@ -0,0 +1,89 @@
+// Package ct gathers the constant time helpers used where secrets are
+// handled: comparisons, selections and table lookups whose timing and
+// memory accesses do not depend on secret data.
+//
+// Like crypto/subtle, which it builds upon, choices are ints that must be
+// 0 or 1. Lengths are considered public
+package ct
+
+import (
+	"crypto/subtle"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// Compare returns 1 if a and b are equal and 0 otherwise. Slices of
+// different lengths are unequal
+func Compare(a, b []byte) int {
+	return subtle.ConstantTimeCompare(a, b)
+}
+
+// Equal reports whether a and b are equal
+func Equal(a, b []byte) bool {
+	return Compare(a, b) == 1
+}
+
+// IsZero returns 1 if every byte of b is zero and 0 otherwise
+func IsZero(b []byte) int {
+	var acc byte
+	for _, x := range b {
+		acc |= x
+	}
+	return subtle.ConstantTimeByteEq(acc, 0)
+}
+
+// LessThan returns 1 if a < b and 0 otherwise, a and b being big endian
+// integers of the same length. It panics if the lengths differ
+func LessThan(a, b []byte) int {
+	if len(a) != len(b) {
+		panic("ct: slices have different lengths")
+	}
+	lt, eq := 0, 1
+	for i := range a {
+		// a[i] - b[i] is negative iff a[i] < b[i]
+		lt |= eq & int(uint32(int32(a[i])-int32(b[i]))>>31)
+		eq &= subtle.ConstantTimeByteEq(a[i], b[i])
+	}
+	return lt
+}
+
+// SelectBytes sets dst to x if v is 1, and to y if v is 0. It panics if the
+// slices have different lengths
+func SelectBytes(v int, dst, x, y []byte) {
+	if len(dst) != len(x) || len(x) != len(y) {
+		panic("ct: slices have different lengths")
+	}
+	mask := byte(-v)
+	for i := range dst {
+		dst[i] = y[i] ^ (mask & (x[i] ^ y[i]))
+	}
+}
+
+// SelectScalar returns x if v is 1, and y if v is 0
+func SelectScalar(v int, x, y *ristretto.Scalar) ristretto.Scalar {
+	mask := uint32(-v)
+	var res ristretto.Scalar
+	for i := range res {
+		res[i] = y[i] ^ (mask & (x[i] ^ y[i]))
+	}
+	return res
+}
+
+// LookupBytes copies table[idx] to dst. Every entry is read, so that the
+// memory accesses do not reveal idx. It panics if an entry and dst have
+// different lengths
+func LookupBytes(dst []byte, table [][]byte, idx int) {
+	for i := range table {
+		SelectBytes(subtle.ConstantTimeEq(int32(i), int32(idx)), dst, table[i], dst)
+	}
+}
+
+// LookupScalar returns table[idx], or zero if idx is out of range. Every
+// entry is read, so that the memory accesses do not reveal idx
+func LookupScalar(table []ristretto.Scalar, idx int) ristretto.Scalar {
+	var res ristretto.Scalar
+	for i := range table {
+		res = SelectScalar(subtle.ConstantTimeEq(int32(i), int32(idx)), &table[i], &res)
+	}
+	return res
+}
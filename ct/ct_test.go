@@ -0,0 +1,79 @@
+package ct
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	assert.Equal(t, 1, Compare([]byte("abc"), []byte("abc")))
+	assert.Equal(t, 0, Compare([]byte("abc"), []byte("abd")))
+	assert.Equal(t, 0, Compare([]byte("abc"), []byte("ab")))
+	assert.True(t, Equal(nil, []byte{}))
+	assert.False(t, Equal([]byte{0}, []byte{1}))
+}
+
+func TestIsZero(t *testing.T) {
+	assert.Equal(t, 1, IsZero(nil))
+	assert.Equal(t, 1, IsZero(make([]byte, 32)))
+	b := make([]byte, 32)
+	b[31] = 0x80
+	assert.Equal(t, 0, IsZero(b))
+}
+
+func TestLessThan(t *testing.T) {
+	assert.Equal(t, 1, LessThan([]byte{0, 1}, []byte{0, 2}))
+	assert.Equal(t, 1, LessThan([]byte{0, 0xff}, []byte{1, 0}))
+	assert.Equal(t, 0, LessThan([]byte{1, 0}, []byte{0, 0xff}))
+	assert.Equal(t, 0, LessThan([]byte{1, 2}, []byte{1, 2}))
+	assert.Equal(t, 0, LessThan(nil, nil))
+	assert.Panics(t, func() { LessThan([]byte{1}, []byte{1, 2}) })
+}
+
+func TestSelectBytes(t *testing.T) {
+	x, y := []byte{1, 2, 3}, []byte{4, 5, 6}
+	dst := make([]byte, 3)
+
+	SelectBytes(1, dst, x, y)
+	assert.Equal(t, x, dst)
+	SelectBytes(0, dst, x, y)
+	assert.Equal(t, y, dst)
+
+	assert.Panics(t, func() { SelectBytes(1, dst, x, y[:2]) })
+}
+
+func TestSelectScalar(t *testing.T) {
+	var x, y ristretto.Scalar
+	x.Rand()
+	y.Rand()
+
+	s := SelectScalar(1, &x, &y)
+	assert.True(t, s.Equals(&x))
+	s = SelectScalar(0, &x, &y)
+	assert.True(t, s.Equals(&y))
+}
+
+func TestLookup(t *testing.T) {
+	table := [][]byte{{1, 1}, {2, 2}, {3, 3}}
+	dst := make([]byte, 2)
+	for i := range table {
+		LookupBytes(dst, table, i)
+		assert.Equal(t, table[i], dst)
+	}
+
+	scalars := make([]ristretto.Scalar, 5)
+	for i := range scalars {
+		scalars[i].Rand()
+	}
+	for i := range scalars {
+		s := LookupScalar(scalars, i)
+		assert.True(t, s.Equals(&scalars[i]))
+	}
+
+	var zero ristretto.Scalar
+	zero.SetZero()
+	s := LookupScalar(scalars, len(scalars))
+	assert.True(t, s.Equals(&zero))
+}
@@ -0,0 +1,155 @@
+// Package custody implements proofs of custody over chunks of data. In every
+// epoch a node derives its custody secret, its unique BLS signature of the
+// epoch, and answers challenges on the chunks it stores with a MAC of the chunk
+// keyed by the secret. The node cannot hand the computation over to someone
+// holding the data without handing over the secret, and the secret is
+// published at the end of the epoch, when it becomes checkable against the
+// public key of the node: anyone then recomputes the MACs from the data and
+// catches the node that answered without the chunk.
+//
+// Nodes must keep their secret private until the end of the epoch: whoever
+// learns it earlier can answer on their behalf. The epoch is hashed with the
+// public key to a G1 point whose discrete log is unknown, so that a revealed
+// secret says nothing about the secrets of the following epochs
+package custody
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/ct"
+	"github.com/dusk-network/dusk-crypto/hash"
+)
+
+// MaxChallenges bounds the number of challenges drawn at once
+const MaxChallenges = 1 << 12
+
+// epochMessage returns the message signed to derive the custody secret
+func epochMessage(epoch uint64) []byte {
+	var e [8]byte
+	binary.BigEndian.PutUint64(e[:], epoch)
+	return hash.Sha3256WithDomain("dusk.custody.epoch", e[:])
+}
+
+// NewSecret returns the custody secret of the key pair for epoch
+func NewSecret(sk *bls.SecretKey, pk *bls.PublicKey, epoch uint64) (*bls.UniqueSignature, error) {
+	return bls.SignUnique(sk, pk, epochMessage(epoch))
+}
+
+// VerifySecret checks a revealed custody secret against the public key
+func VerifySecret(pk *bls.PublicKey, epoch uint64, secret *bls.UniqueSignature) error {
+	return bls.VerifyUnique(pk, epochMessage(epoch), secret)
+}
+
+// Challenge asks for the MAC of the chunk Index of the data with Merkle
+// root Root
+type Challenge struct {
+	Epoch uint64
+	Root  [32]byte
+	Index uint64
+	Nonce [32]byte
+}
+
+// NewChallenges derives n challenges on the data with root, made of
+// numChunks chunks, from seed, e.g. a beacon value produced after the data
+// was stored
+func NewChallenges(seed []byte, epoch uint64, root [32]byte, numChunks uint64, n int) ([]Challenge, error) {
+	if numChunks == 0 {
+		return nil, errors.New("custody: no chunks")
+	}
+	if n <= 0 || n > MaxChallenges {
+		return nil, errors.New("custody: invalid number of challenges")
+	}
+
+	var e [8]byte
+	binary.BigEndian.PutUint64(e[:], epoch)
+	res := make([]Challenge, n)
+	var ctr [4]byte
+	for i := range res {
+		binary.BigEndian.PutUint32(ctr[:], uint32(i))
+		h := hash.Sha3512WithDomain("dusk.custody.challenge", seed, e[:], root[:], ctr[:])
+		res[i].Epoch = epoch
+		res[i].Root = root
+		// the bias of the reduction is negligible for any realistic number
+		// of chunks
+		res[i].Index = binary.BigEndian.Uint64(h[:8]) % numChunks
+		copy(res[i].Nonce[:], h[32:])
+	}
+	return res, nil
+}
+
+// key derives the MAC key from the secret
+func key(secret *bls.UniqueSignature) []byte {
+	return hash.Sha3256WithDomain("dusk.custody.key", secret.Marshal())
+}
+
+func (c *Challenge) mac(k, chunk []byte) [32]byte {
+	var buf [48]byte
+	binary.BigEndian.PutUint64(buf[0:8], c.Epoch)
+	binary.BigEndian.PutUint64(buf[8:16], c.Index)
+	copy(buf[16:48], c.Root[:])
+
+	var res [32]byte
+	copy(res[:], hash.Sha3256WithDomain("dusk.custody.mac", k, buf[:], c.Nonce[:], chunk))
+	return res
+}
+
+// Respond answers the challenges, chunks[i] being the chunk challenged by
+// challenges[i]
+func Respond(secret *bls.UniqueSignature, challenges []Challenge, chunks [][]byte) ([][32]byte, error) {
+	if len(challenges) != len(chunks) {
+		return nil, errors.New("custody: number of chunks and challenges do not match")
+	}
+
+	k := key(secret)
+	res := make([][32]byte, len(challenges))
+	for i := range challenges {
+		res[i] = challenges[i].mac(k, chunks[i])
+	}
+	return res, nil
+}
+
+// Check verifies the responses of a node once its secret is revealed:
+// the secret against the public key of the node, then every response
+// against the chunk it answers. It returns the indices of the wrong
+// responses, nil if they are all right
+func Check(pk *bls.PublicKey, secret *bls.UniqueSignature, challenges []Challenge, chunks [][]byte, responses [][32]byte) ([]int, error) {
+	if len(challenges) != len(chunks) || len(challenges) != len(responses) {
+		return nil, errors.New("custody: number of responses, chunks and challenges do not match")
+	}
+	for i := range challenges {
+		if challenges[i].Epoch != challenges[0].Epoch {
+			return nil, errors.New("custody: challenges of different epochs")
+		}
+	}
+	if len(challenges) > 0 {
+		if err := VerifySecret(pk, challenges[0].Epoch, secret); err != nil {
+			return nil, err
+		}
+	}
+
+	k := key(secret)
+	var wrong []int
+	for i := range challenges {
+		expected := challenges[i].mac(k, chunks[i])
+		if !ct.Equal(expected[:], responses[i][:]) {
+			wrong = append(wrong, i)
+		}
+	}
+	return wrong, nil
+}
+
+// Encode a Challenge
+func (c *Challenge) Encode(w io.Writer) error {
+	return binary.Write(w, binary.BigEndian, c)
+}
+
+// Decode a Challenge
+func (c *Challenge) Decode(r io.Reader) error {
+	if c == nil {
+		return errors.New("struct is nil")
+	}
+	return binary.Read(r, binary.BigEndian, c)
+}
@@ -0,0 +1,101 @@
+package custody
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustody(t *testing.T) {
+	pk, sk, err := bls.GenKeyPair(nil)
+	require.Nil(t, err)
+
+	data := make([][]byte, 20)
+	for i := range data {
+		data[i] = bytes.Repeat([]byte{byte(i)}, 64)
+	}
+	root := [32]byte{1, 2, 3}
+
+	challenges, err := NewChallenges([]byte("beacon"), 5, root, uint64(len(data)), 4)
+	require.Nil(t, err)
+	chunks := make([][]byte, len(challenges))
+	for i := range challenges {
+		assert.True(t, challenges[i].Index < uint64(len(data)))
+		chunks[i] = data[challenges[i].Index]
+	}
+
+	secret, err := NewSecret(sk, pk, 5)
+	require.Nil(t, err)
+	responses, err := Respond(secret, challenges, chunks)
+	require.Nil(t, err)
+
+	wrong, err := Check(pk, secret, challenges, chunks, responses)
+	require.Nil(t, err)
+	assert.Empty(t, wrong)
+
+	// a node answering without the chunk is caught
+	chunks[2] = []byte("guess")
+	forged, err := Respond(secret, challenges, chunks)
+	require.Nil(t, err)
+	chunks[2] = data[challenges[2].Index]
+	wrong, err = Check(pk, secret, challenges, chunks, forged)
+	require.Nil(t, err)
+	assert.Equal(t, []int{2}, wrong)
+
+	// the secret of another epoch is rejected
+	other, err := NewSecret(sk, pk, 6)
+	require.Nil(t, err)
+	_, err = Check(pk, other, challenges, chunks, responses)
+	assert.NotNil(t, err)
+
+	buf := new(bytes.Buffer)
+	require.Nil(t, challenges[0].Encode(buf))
+	var dec Challenge
+	require.Nil(t, dec.Decode(buf))
+	assert.Equal(t, challenges[0], dec)
+}
+
+func TestChallenges(t *testing.T) {
+	root := [32]byte{9}
+	a, err := NewChallenges([]byte("seed"), 1, root, 1000, 8)
+	require.Nil(t, err)
+	b, err := NewChallenges([]byte("seed"), 1, root, 1000, 8)
+	require.Nil(t, err)
+	assert.Equal(t, a, b)
+
+	c, err := NewChallenges([]byte("other seed"), 1, root, 1000, 8)
+	require.Nil(t, err)
+	assert.NotEqual(t, a, c)
+
+	_, err = NewChallenges([]byte("seed"), 1, root, 0, 8)
+	assert.NotNil(t, err)
+}
+
+func TestSecretNotRescalable(t *testing.T) {
+	pk, sk, err := bls.GenKeyPair(nil)
+	require.Nil(t, err)
+
+	revealed, err := NewSecret(sk, pk, 5)
+	require.Nil(t, err)
+	require.Nil(t, VerifySecret(pk, 5, revealed))
+
+	// with h0 = g1^k, the secret of epoch 6 was k6/k5 times the one of
+	// epoch 5, for public k5 and k6
+	k5 := hash.HashToBN256Scalar("dusk.bls.h0", epochMessage(5))
+	k6 := hash.HashToBN256Scalar("dusk.bls.h0", epochMessage(6))
+	ratio := new(big.Int).ModInverse(k5, bn256.Order)
+	ratio.Mul(ratio, k6).Mod(ratio, bn256.Order)
+
+	p := new(bn256.G1)
+	_, err = p.Unmarshal(revealed.Marshal())
+	require.Nil(t, err)
+	scaled := &bls.UniqueSignature{}
+	require.Nil(t, scaled.Unmarshal(new(bn256.G1).ScalarMult(p, ratio).Marshal()))
+	assert.NotNil(t, VerifySecret(pk, 6, scaled))
+}
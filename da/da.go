@@ -0,0 +1,349 @@
+// Package da erasure codes data for data availability sampling, and commits
+// to the chunks of the code either with KZG or with a Merkle tree.
+//
+// The data is cut into symbols of SymbolSize bytes, read as elements of the
+// bn256 scalar field. The symbols are the evaluations at 1, 2, ... of a
+// polynomial of degree below the number of data symbols, and the code
+// extends them with its evaluations at the next points: a Reed-Solomon code
+// in systematic form. Chunks group SymbolsPerChunk consecutive symbols, and
+// any DataChunks distinct chunks decode the data.
+//
+// A KZG commitment is a commitment to the polynomial itself, so every chunk
+// that verifies against it belongs to the same codeword, and sampling enough
+// chunks convinces a light client that the data can be recovered. A Merkle
+// root only binds the chunks, not the fact that they form a codeword:
+// Decode detects inconsistent chunks when it gets more than it needs, but
+// light clients sampling against a Merkle root must rely on fraud proofs
+package da
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"math/bits"
+
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/kzg"
+	"golang.org/x/crypto/sha3"
+)
+
+// SymbolSize is the number of bytes of data held by a symbol, small enough
+// for every symbol to be a field element
+const SymbolSize = 31
+
+// MaxSymbols bounds the total number of symbols of a code
+const MaxSymbols = 1 << 14
+
+// Params are the dimensions of the code
+type Params struct {
+	// DataChunks is the number of chunks holding the data
+	DataChunks int
+	// Chunks is the total number of chunks, DataChunks of which hold the
+	// data and the others the redundancy
+	Chunks int
+	// SymbolsPerChunk is the number of symbols of a chunk
+	SymbolsPerChunk int
+}
+
+// Check returns an error if the dimensions are invalid
+func (p Params) Check() error {
+	if p.DataChunks < 1 || p.Chunks < p.DataChunks || p.SymbolsPerChunk < 1 {
+		return errors.New("da: invalid code dimensions")
+	}
+	if p.Chunks > MaxSymbols/p.SymbolsPerChunk {
+		return errors.New("da: too many symbols")
+	}
+	return nil
+}
+
+// Capacity returns the maximum number of bytes of data the code holds
+func (p Params) Capacity() int {
+	return p.DataChunks * p.SymbolsPerChunk * SymbolSize
+}
+
+// points returns the evaluation points of the symbols of chunk i
+func (p Params) points(i int) []*big.Int {
+	res := make([]*big.Int, p.SymbolsPerChunk)
+	for j := range res {
+		res[j] = big.NewInt(int64(i*p.SymbolsPerChunk + j + 1))
+	}
+	return res
+}
+
+// depth returns the depth of the Merkle tree over the chunks
+func (p Params) depth() int {
+	d := bits.Len(uint(p.Chunks - 1))
+	if d == 0 {
+		d = 1
+	}
+	return d
+}
+
+// Chunk is a piece of the code
+type Chunk struct {
+	Index   int
+	Symbols []*big.Int
+}
+
+func (p Params) checkChunk(c *Chunk) bool {
+	if c.Index < 0 || c.Index >= p.Chunks || len(c.Symbols) != p.SymbolsPerChunk {
+		return false
+	}
+	for _, s := range c.Symbols {
+		if s == nil || s.Sign() < 0 || s.Cmp(bn256.Order) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// leaf returns the Merkle leaf of the chunk
+func (c *Chunk) leaf() [32]byte {
+	var idx [4]byte
+	binary.BigEndian.PutUint32(idx[:], uint32(c.Index))
+	buf := make([]byte, 0, 32*len(c.Symbols))
+	for _, s := range c.Symbols {
+		var b [32]byte
+		putBytes(b[:], s)
+		buf = append(buf, b[:]...)
+	}
+
+	var res [32]byte
+	copy(res[:], hash.Sha3256WithDomain("dusk.da.chunk", idx[:], buf))
+	return res
+}
+
+// putBytes writes s big endian into dst, which must be large enough
+func putBytes(dst []byte, s *big.Int) {
+	b := s.Bytes()
+	copy(dst[len(dst)-len(b):], b)
+}
+
+// Encoding is the erasure code of some data
+type Encoding struct {
+	Params Params
+	// Size is the length of the data
+	Size   int
+	Chunks []Chunk
+
+	poly kzg.Polynomial
+}
+
+// Encode erasure codes data
+func Encode(p Params, data []byte) (*Encoding, error) {
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+	if len(data) > p.Capacity() {
+		return nil, errors.New("da: data exceeds the capacity of the code")
+	}
+
+	numData := p.DataChunks * p.SymbolsPerChunk
+	points := make([]*big.Int, numData)
+	values := make([]*big.Int, numData)
+	for i := range values {
+		points[i] = big.NewInt(int64(i + 1))
+		values[i] = new(big.Int)
+		if start := i * SymbolSize; start < len(data) {
+			end := start + SymbolSize
+			if end > len(data) {
+				end = len(data)
+			}
+			// symbols are zero padded on the right
+			var sym [SymbolSize]byte
+			copy(sym[:], data[start:end])
+			values[i].SetBytes(sym[:])
+		}
+	}
+
+	poly, err := kzg.Interpolate(points, values)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Encoding{Params: p, Size: len(data), Chunks: make([]Chunk, p.Chunks), poly: poly}
+	for i := range e.Chunks {
+		e.Chunks[i].Index = i
+		pts := p.points(i)
+		e.Chunks[i].Symbols = make([]*big.Int, len(pts))
+		for j, x := range pts {
+			if i < p.DataChunks {
+				e.Chunks[i].Symbols[j] = values[x.Int64()-1]
+			} else {
+				e.Chunks[i].Symbols[j] = poly.Eval(x)
+			}
+		}
+	}
+	return e, nil
+}
+
+// Decode recovers the data of the given size from any DataChunks distinct
+// chunks of its code. Extra chunks are checked against the code, and Decode
+// fails if they do not belong to the same codeword
+func Decode(p Params, chunks []Chunk, size int) ([]byte, error) {
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+	if size < 0 || size > p.Capacity() {
+		return nil, errors.New("da: invalid data size")
+	}
+
+	seen := make(map[int]bool)
+	var unique []Chunk
+	for i := range chunks {
+		if !p.checkChunk(&chunks[i]) {
+			return nil, errors.New("da: invalid chunk")
+		}
+		if !seen[chunks[i].Index] {
+			seen[chunks[i].Index] = true
+			unique = append(unique, chunks[i])
+		}
+	}
+	if len(unique) < p.DataChunks {
+		return nil, errors.New("da: not enough chunks to decode")
+	}
+
+	var points, values []*big.Int
+	for _, c := range unique[:p.DataChunks] {
+		points = append(points, p.points(c.Index)...)
+		values = append(values, c.Symbols...)
+	}
+	poly, err := kzg.Interpolate(points, values)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range unique[p.DataChunks:] {
+		for j, x := range p.points(c.Index) {
+			if poly.Eval(x).Cmp(c.Symbols[j]) != 0 {
+				return nil, errors.New("da: chunks do not belong to the same codeword")
+			}
+		}
+	}
+
+	data := make([]byte, 0, p.Capacity())
+	for i := 0; i < p.DataChunks*p.SymbolsPerChunk; i++ {
+		s := poly.Eval(big.NewInt(int64(i + 1)))
+		if s.BitLen() > 8*SymbolSize {
+			return nil, errors.New("da: chunks do not encode data")
+		}
+		var sym [SymbolSize]byte
+		putBytes(sym[:], s)
+		data = append(data, sym[:]...)
+	}
+	return data[:size], nil
+}
+
+// Commit returns the KZG commitment to the code. The SRS must allow
+// polynomials of degree DataChunks * SymbolsPerChunk - 1
+func (e *Encoding) Commit(srs *kzg.SRS) (*bn256.G1, error) {
+	return kzg.Commit(srs, e.poly)
+}
+
+// Prove returns the KZG proof of chunk i. The SRS must allow batched
+// openings at SymbolsPerChunk points
+func (e *Encoding) Prove(srs *kzg.SRS, i int) (*kzg.Proof, error) {
+	if i < 0 || i >= len(e.Chunks) {
+		return nil, errors.New("da: no chunk with this index")
+	}
+	_, proof, err := kzg.OpenBatch(srs, e.poly, e.Params.points(i))
+	return proof, err
+}
+
+// VerifyChunk checks a chunk against the KZG commitment to the code
+func VerifyChunk(srs *kzg.SRS, p Params, c *bn256.G1, chunk *Chunk, proof *kzg.Proof) bool {
+	if p.Check() != nil || !p.checkChunk(chunk) {
+		return false
+	}
+	return kzg.VerifyBatch(srs, c, p.points(chunk.Index), chunk.Symbols, proof)
+}
+
+// merkleLevels returns the levels of the Merkle tree over the chunks,
+// leaves first. Missing leaves are zero, as in merkletree.IncrementalTree
+func (e *Encoding) merkleLevels() [][][32]byte {
+	depth := e.Params.depth()
+	level := make([][32]byte, 1<<uint(depth))
+	for i := range e.Chunks {
+		level[i] = e.Chunks[i].leaf()
+	}
+
+	levels := [][][32]byte{level}
+	for len(level) > 1 {
+		next := make([][32]byte, len(level)/2)
+		for i := range next {
+			next[i] = hashPair(level[2*i], level[2*i+1])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+func hashPair(left, right [32]byte) [32]byte {
+	var buf [64]byte
+	copy(buf[:32], left[:])
+	copy(buf[32:], right[:])
+	return sha3.Sum256(buf[:])
+}
+
+// MerkleRoot returns the root of the Merkle tree over the chunks
+func (e *Encoding) MerkleRoot() [32]byte {
+	levels := e.merkleLevels()
+	return levels[len(levels)-1][0]
+}
+
+// MerklePath returns the path of chunk i, to be checked with
+// VerifyChunkPath
+func (e *Encoding) MerklePath(i int) ([][32]byte, error) {
+	if i < 0 || i >= len(e.Chunks) {
+		return nil, errors.New("da: no chunk with this index")
+	}
+	levels := e.merkleLevels()
+	path := make([][32]byte, len(levels)-1)
+	for l := range path {
+		path[l] = levels[l][(i>>uint(l))^1]
+	}
+	return path, nil
+}
+
+// VerifyChunkPath checks a chunk against the Merkle root of the code
+func VerifyChunkPath(p Params, root [32]byte, chunk *Chunk, path [][32]byte) bool {
+	if p.Check() != nil || !p.checkChunk(chunk) || len(path) != p.depth() {
+		return false
+	}
+	node := chunk.leaf()
+	for l := range path {
+		if (chunk.Index>>uint(l))&1 == 0 {
+			node = hashPair(node, path[l])
+		} else {
+			node = hashPair(path[l], node)
+		}
+	}
+	return node == root
+}
+
+// SampleIndices derives n distinct chunk indices to sample from seed, e.g.
+// local randomness of the light client, so that the publisher cannot tell
+// in advance which chunks are going to be requested
+func SampleIndices(seed []byte, p Params, n int) ([]int, error) {
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+	if n < 1 || n > p.Chunks {
+		return nil, errors.New("da: invalid number of samples")
+	}
+
+	res := make([]int, 0, n)
+	seen := make(map[int]bool)
+	var ctr [4]byte
+	for i := uint32(0); len(res) < n; i++ {
+		binary.BigEndian.PutUint32(ctr[:], i)
+		h := hash.Sha3256WithDomain("dusk.da.sample", seed, ctr[:])
+		idx := int(binary.BigEndian.Uint64(h[:8]) % uint64(p.Chunks))
+		if !seen[idx] {
+			seen[idx] = true
+			res = append(res, idx)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,107 @@
+package da
+
+import (
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/kzg"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testParams = Params{DataChunks: 4, Chunks: 8, SymbolsPerChunk: 2}
+
+func testData(t *testing.T, n int) []byte {
+	data := make([]byte, n)
+	err := rng.Read(data)
+	require.Nil(t, err)
+	return data
+}
+
+func TestEncodeDecode(t *testing.T) {
+	data := testData(t, testParams.Capacity()-10)
+	e, err := Encode(testParams, data)
+	require.Nil(t, err)
+	require.Equal(t, testParams.Chunks, len(e.Chunks))
+
+	// any DataChunks chunks decode the data
+	for _, idx := range [][]int{{0, 1, 2, 3}, {4, 5, 6, 7}, {7, 0, 5, 2}} {
+		var chunks []Chunk
+		for _, i := range idx {
+			chunks = append(chunks, e.Chunks[i])
+		}
+		dec, err := Decode(testParams, chunks, len(data))
+		require.Nil(t, err)
+		assert.Equal(t, data, dec)
+	}
+
+	dec, err := Decode(testParams, e.Chunks, len(data))
+	require.Nil(t, err)
+	assert.Equal(t, data, dec)
+
+	// too few chunks, or chunks of another codeword
+	_, err = Decode(testParams, e.Chunks[:3], len(data))
+	assert.NotNil(t, err)
+
+	other, err := Encode(testParams, testData(t, 40))
+	require.Nil(t, err)
+	mixed := append(append([]Chunk{}, e.Chunks[:4]...), other.Chunks[5])
+	_, err = Decode(testParams, mixed, len(data))
+	assert.NotNil(t, err)
+
+	_, err = Encode(testParams, testData(t, testParams.Capacity()+1))
+	assert.NotNil(t, err)
+}
+
+func TestKZGSampling(t *testing.T) {
+	srs, err := kzg.NewInsecureSRS(testParams.DataChunks*testParams.SymbolsPerChunk, testParams.SymbolsPerChunk, nil)
+	require.Nil(t, err)
+
+	e, err := Encode(testParams, testData(t, 100))
+	require.Nil(t, err)
+	c, err := e.Commit(srs)
+	require.Nil(t, err)
+
+	samples, err := SampleIndices([]byte("light client"), testParams, 3)
+	require.Nil(t, err)
+	for _, i := range samples {
+		proof, err := e.Prove(srs, i)
+		require.Nil(t, err)
+		assert.True(t, VerifyChunk(srs, testParams, c, &e.Chunks[i], proof))
+
+		wrong := Chunk{Index: (i + 1) % testParams.Chunks, Symbols: e.Chunks[i].Symbols}
+		assert.False(t, VerifyChunk(srs, testParams, c, &wrong, proof))
+	}
+}
+
+func TestMerkleSampling(t *testing.T) {
+	e, err := Encode(testParams, testData(t, 100))
+	require.Nil(t, err)
+	root := e.MerkleRoot()
+
+	for i := range e.Chunks {
+		path, err := e.MerklePath(i)
+		require.Nil(t, err)
+		assert.True(t, VerifyChunkPath(testParams, root, &e.Chunks[i], path))
+
+		other := e.Chunks[(i+1)%len(e.Chunks)]
+		assert.False(t, VerifyChunkPath(testParams, root, &other, path))
+	}
+}
+
+func TestSampleIndices(t *testing.T) {
+	a, err := SampleIndices([]byte("seed"), testParams, testParams.Chunks)
+	require.Nil(t, err)
+	seen := make(map[int]bool)
+	for _, i := range a {
+		assert.False(t, seen[i])
+		seen[i] = true
+	}
+
+	b, err := SampleIndices([]byte("seed"), testParams, testParams.Chunks)
+	require.Nil(t, err)
+	assert.Equal(t, a, b)
+
+	_, err = SampleIndices([]byte("seed"), testParams, testParams.Chunks+1)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,107 @@
+// Package dh implements Diffie-Hellman key agreement over Ristretto and
+// X25519, and the derivation of labeled keys from the shared secrets.
+//
+// SharedSecret refuses to return a secret the peer could have forced, such
+// as the identity for Ristretto or the all-zero output of X25519 for points
+// of small order, so that both parties always contribute to the secret
+package dh
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+)
+
+// SecretSize is the size of a shared secret
+const SecretSize = 32
+
+// MaxExpand is the maximum number of bytes Expand can derive
+const MaxExpand = 255 * 32
+
+// ErrNonContributory is returned when a shared secret does not depend on the
+// secret key, because the peer's public key is degenerate
+var ErrNonContributory = errors.New("non contributory key agreement")
+
+// GenerateKeyPair returns a random Ristretto secret key and its public key
+func GenerateKeyPair() (ristretto.Scalar, ristretto.Point) {
+	var sk ristretto.Scalar
+	rng.Scalar(&sk)
+
+	var pk ristretto.Point
+	pk.ScalarMultBase(&sk)
+	return sk, pk
+}
+
+// SharedSecret returns the encoding of sk * peer
+func SharedSecret(sk ristretto.Scalar, peer ristretto.Point) ([SecretSize]byte, error) {
+	var out [SecretSize]byte
+
+	var zero, shared ristretto.Point
+	zero.SetZero()
+	shared.ScalarMult(&peer, &sk)
+	if shared.Equals(&zero) {
+		return out, ErrNonContributory
+	}
+
+	shared.BytesInto(&out)
+	return out, nil
+}
+
+// GenerateX25519KeyPair returns a random X25519 secret key and its public
+// key. The randomness is read from r, or from rng.Reader if r is nil
+func GenerateX25519KeyPair(r io.Reader) ([32]byte, [32]byte, error) {
+	var sk, pk [32]byte
+	if r == nil {
+		r = rng.Reader
+	}
+	if _, err := io.ReadFull(r, sk[:]); err != nil {
+		return sk, pk, err
+	}
+
+	curve25519.ScalarBaseMult(&pk, &sk)
+	return sk, pk, nil
+}
+
+// X25519SharedSecret returns the X25519 function of sk and peer
+func X25519SharedSecret(sk, peer [32]byte) ([SecretSize]byte, error) {
+	var out [SecretSize]byte
+
+	shared, err := curve25519.X25519(sk[:], peer[:])
+	if err != nil {
+		return out, ErrNonContributory
+	}
+	copy(out[:], shared)
+	return out, nil
+}
+
+// Expand derives n bytes from a shared secret with HKDF-SHA3-256. The label
+// names the purpose of the key, e.g. "dusk.handshake.key", and the context
+// binds it to the session, e.g. to the transcript of the handshake. Keys
+// with different labels or contexts are independent
+func Expand(secret []byte, label string, context []byte, n int) ([]byte, error) {
+	if n < 1 || n > MaxExpand {
+		return nil, errors.New("invalid output length")
+	}
+
+	var l [4]byte
+	info := make([]byte, 0, 8+len(label)+len(context))
+	binary.BigEndian.PutUint32(l[:], uint32(len(label)))
+	info = append(info, l[:]...)
+	info = append(info, label...)
+	binary.BigEndian.PutUint32(l[:], uint32(len(context)))
+	info = append(info, l[:]...)
+	info = append(info, context...)
+
+	out := make([]byte, n)
+	kdf := hkdf.New(sha3.New256, secret, []byte("dusk.dh"), info)
+	if _, err := io.ReadFull(kdf, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,99 @@
+package dh
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedSecret(t *testing.T) {
+	skA, pkA := GenerateKeyPair()
+	skB, pkB := GenerateKeyPair()
+
+	ab, err := SharedSecret(skA, pkB)
+	require.Nil(t, err)
+	ba, err := SharedSecret(skB, pkA)
+	require.Nil(t, err)
+	assert.Equal(t, ab, ba)
+
+	var zero ristretto.Point
+	zero.SetZero()
+	_, err = SharedSecret(skA, zero)
+	assert.Equal(t, ErrNonContributory, err)
+}
+
+func TestX25519SharedSecret(t *testing.T) {
+	skA, pkA, err := GenerateX25519KeyPair(nil)
+	require.Nil(t, err)
+	skB, pkB, err := GenerateX25519KeyPair(nil)
+	require.Nil(t, err)
+
+	ab, err := X25519SharedSecret(skA, pkB)
+	require.Nil(t, err)
+	ba, err := X25519SharedSecret(skB, pkA)
+	require.Nil(t, err)
+	assert.Equal(t, ab, ba)
+
+	// RFC 7748, section 6.1
+	a, _ := hex.DecodeString("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	b, _ := hex.DecodeString("5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb")
+	alice, alicePk, err := GenerateX25519KeyPair(bytes.NewReader(a))
+	require.Nil(t, err)
+	bob, bobPk, err := GenerateX25519KeyPair(bytes.NewReader(b))
+	require.Nil(t, err)
+	assert.Equal(t, "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f", hex.EncodeToString(bobPk[:]))
+
+	ab, err = X25519SharedSecret(alice, bobPk)
+	require.Nil(t, err)
+	ba, err = X25519SharedSecret(bob, alicePk)
+	require.Nil(t, err)
+	assert.Equal(t, ab, ba)
+	assert.Equal(t, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742", hex.EncodeToString(ab[:]))
+
+	// points of small order are rejected
+	var lowOrder [32]byte
+	_, err = X25519SharedSecret(skA, lowOrder)
+	assert.Equal(t, ErrNonContributory, err)
+	lowOrder[0] = 1
+	_, err = X25519SharedSecret(skA, lowOrder)
+	assert.Equal(t, ErrNonContributory, err)
+}
+
+func TestExpand(t *testing.T) {
+	secret := []byte("shared secret")
+
+	k1, err := Expand(secret, "dusk.test.key", []byte("session"), 32)
+	require.Nil(t, err)
+	assert.Len(t, k1, 32)
+
+	again, err := Expand(secret, "dusk.test.key", []byte("session"), 32)
+	require.Nil(t, err)
+	assert.Equal(t, k1, again)
+
+	// labels, contexts and their boundaries all matter
+	for _, other := range []struct {
+		label   string
+		context string
+	}{
+		{"dusk.test.iv", "session"},
+		{"dusk.test.key", "other"},
+		{"dusk.test.keys", "ession"},
+	} {
+		k, err := Expand(secret, other.label, []byte(other.context), 32)
+		require.Nil(t, err)
+		assert.NotEqual(t, k1, k)
+	}
+
+	long, err := Expand(secret, "dusk.test.key", []byte("session"), 64)
+	require.Nil(t, err)
+	assert.Equal(t, k1, long[:32])
+
+	_, err = Expand(secret, "dusk.test.key", nil, 0)
+	assert.NotNil(t, err)
+	_, err = Expand(secret, "dusk.test.key", nil, MaxExpand+1)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,121 @@
+// Package ecies encrypts messages to Ristretto public keys. The sender
+// picks an ephemeral key r and derives a symmetric key from r * P with HKDF,
+// which seals the message with ChaCha20-Poly1305. The ciphertext is framed as
+//
+//   version (1 byte) || R = r * G (32 bytes) || AEAD ciphertext
+//
+// The version and R are authenticated along with the associated data, and
+// the key is bound to both public keys
+package ecies
+
+import (
+	"errors"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+)
+
+// Version is the version of the ciphertext framing
+const Version byte = 1
+
+// tagSize is the size of the Poly1305 authentication tag
+const tagSize = 16
+
+// Overhead is the difference between the size of a ciphertext and the size
+// of its plaintext
+const Overhead = 1 + 32 + tagSize
+
+// Every key encrypts a single message, so the nonce can be constant
+var nonce [chacha20poly1305.NonceSize]byte
+
+// Encrypt encrypts plaintext to the public key pk. The associated data is
+// authenticated but not encrypted, and must be given to Decrypt as well
+func Encrypt(pk ristretto.Point, plaintext, aad []byte) ([]byte, error) {
+	var zero ristretto.Point
+	zero.SetZero()
+	if pk.Equals(&zero) {
+		return nil, errors.New("invalid public key")
+	}
+
+	var r ristretto.Scalar
+	rng.Scalar(&r)
+
+	var R, shared ristretto.Point
+	R.ScalarMultBase(&r)
+	shared.ScalarMult(&pk, &r)
+
+	key, err := deriveKey(shared, R, pk)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, Overhead+len(plaintext))
+	header = append(header, Version)
+	header = append(header, R.Bytes()...)
+	return seal(key, header, plaintext, aad)
+}
+
+// Decrypt decrypts a ciphertext produced by Encrypt with the secret key sk
+func Decrypt(sk ristretto.Scalar, ciphertext, aad []byte) ([]byte, error) {
+	if len(ciphertext) < Overhead {
+		return nil, errors.New("ciphertext too short")
+	}
+	if ciphertext[0] != Version {
+		return nil, errors.New("unsupported ciphertext version")
+	}
+
+	var buf [32]byte
+	copy(buf[:], ciphertext[1:33])
+	var R ristretto.Point
+	if !R.SetBytes(&buf) {
+		return nil, errors.New("invalid ephemeral key")
+	}
+
+	var pk, shared ristretto.Point
+	pk.ScalarMultBase(&sk)
+	shared.ScalarMult(&R, &sk)
+
+	key, err := deriveKey(shared, R, pk)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, nonce[:], ciphertext[33:], additionalData(ciphertext[:33], aad))
+}
+
+// deriveKey derives the AEAD key from the shared point and both public keys
+func deriveKey(shared, R, pk ristretto.Point) ([]byte, error) {
+	info := make([]byte, 0, 64)
+	info = append(info, R.Bytes()...)
+	info = append(info, pk.Bytes()...)
+
+	key := make([]byte, chacha20poly1305.KeySize)
+	kdf := hkdf.New(sha3.New256, shared.Bytes(), []byte("dusk.ecies"), info)
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func seal(key, header, plaintext, aad []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(header, nonce[:], plaintext, additionalData(header, aad)), nil
+}
+
+// additionalData authenticates the header along with the caller's data
+func additionalData(header, aad []byte) []byte {
+	ad := make([]byte, 0, len(header)+len(aad))
+	ad = append(ad, header...)
+	return append(ad, aad...)
+}
@@ -0,0 +1,72 @@
+package ecies
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func keyPair() (ristretto.Scalar, ristretto.Point) {
+	var sk ristretto.Scalar
+	sk.Rand()
+	var pk ristretto.Point
+	pk.ScalarMultBase(&sk)
+	return sk, pk
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	sk, pk := keyPair()
+	msg := []byte("peer payload")
+	aad := []byte("topic")
+
+	ct, err := Encrypt(pk, msg, aad)
+	require.Nil(t, err)
+	assert.Len(t, ct, len(msg)+Overhead)
+	assert.Equal(t, Version, ct[0])
+
+	pt, err := Decrypt(sk, ct, aad)
+	require.Nil(t, err)
+	assert.Equal(t, msg, pt)
+
+	// encryption is randomized
+	other, err := Encrypt(pk, msg, aad)
+	require.Nil(t, err)
+	assert.NotEqual(t, ct, other)
+
+	// empty messages are fine
+	ct, err = Encrypt(pk, nil, nil)
+	require.Nil(t, err)
+	pt, err = Decrypt(sk, ct, nil)
+	require.Nil(t, err)
+	assert.Empty(t, pt)
+}
+
+func TestDecryptFailures(t *testing.T) {
+	sk, pk := keyPair()
+	ct, err := Encrypt(pk, []byte("message"), []byte("aad"))
+	require.Nil(t, err)
+
+	otherSk, _ := keyPair()
+	_, err = Decrypt(otherSk, ct, []byte("aad"))
+	assert.NotNil(t, err)
+
+	_, err = Decrypt(sk, ct, []byte("other aad"))
+	assert.NotNil(t, err)
+
+	for _, i := range []int{0, 1, 20, len(ct) - 1} {
+		tampered := append([]byte{}, ct...)
+		tampered[i] ^= 1
+		_, err = Decrypt(sk, tampered, []byte("aad"))
+		assert.NotNil(t, err, "byte %d", i)
+	}
+
+	_, err = Decrypt(sk, ct[:Overhead-1], []byte("aad"))
+	assert.NotNil(t, err)
+
+	var zero ristretto.Point
+	zero.SetZero()
+	_, err = Encrypt(zero, []byte("message"), nil)
+	assert.NotNil(t, err)
+}
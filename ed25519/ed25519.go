@@ -0,0 +1,374 @@
+// Package ed25519 wraps standard Ed25519 signatures (RFC 8032) so that
+// signatures made outside of Dusk, e.g. by other chains or in tokens, can be
+// verified here, one by one or in batches. It also converts keys between
+// Ed25519 and Ristretto, which share the same curve and the same base point.
+//
+// Verify is the cofactorless verification of the standard library.
+// VerifyCofactored and the batch verifier check [8](s * B) = [8](R + k * A)
+// instead, which is the only equation a batch can check soundly. Both
+// accept every signature made by an honest signer; they only differ on
+// signatures crafted with small order components
+package ed25519
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"math/big"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/bwesterb/go-ristretto/edwards25519"
+	"github.com/dusk-network/dusk-crypto/rng"
+	ed "golang.org/x/crypto/ed25519"
+)
+
+const (
+	// PublicKeySize is the size of a public key
+	PublicKeySize = ed.PublicKeySize
+	// PrivateKeySize is the size of a private key
+	PrivateKeySize = ed.PrivateKeySize
+	// SignatureSize is the size of a signature
+	SignatureSize = ed.SignatureSize
+)
+
+// PublicKey is an Ed25519 public key
+type PublicKey = ed.PublicKey
+
+// PrivateKey is an Ed25519 private key: the seed followed by the public key
+type PrivateKey = ed.PrivateKey
+
+// ErrInvalidPoint is returned when bytes do not encode a point of the curve
+var ErrInvalidPoint = errors.New("ed25519: invalid point encoding")
+
+var (
+	// feD = -121665 / 121666
+	feD edwards25519.FieldElement
+
+	// inv8 = 8^-1 mod l
+	inv8 ristretto.Scalar
+)
+
+func init() {
+	d, _ := new(big.Int).SetString("37095705934669439343138083508754565189542113879843219016388785533085940283555", 10)
+	feD.SetBigInt(d)
+
+	var eight ristretto.Scalar
+	eight.SetBigInt(big.NewInt(8))
+	inv8.Inverse(&eight)
+}
+
+// GenerateKey returns a key pair read from rand, or from crypto/rand if nil
+func GenerateKey(rand io.Reader) (PublicKey, PrivateKey, error) {
+	return ed.GenerateKey(rand)
+}
+
+// Sign signs msg with priv
+func Sign(priv PrivateKey, msg []byte) []byte {
+	return ed.Sign(priv, msg)
+}
+
+// Verify checks the signature of msg against pub, as RFC 8032 does
+func Verify(pub PublicKey, msg, sig []byte) bool {
+	return ed.Verify(pub, msg, sig)
+}
+
+// VerifyCofactored checks the signature of msg against pub with the
+// cofactored equation. It accepts exactly the signatures BatchVerifier does
+func VerifyCofactored(pub PublicKey, msg, sig []byte) bool {
+	e, ok := newEntry(pub, msg, sig)
+	if !ok {
+		return false
+	}
+
+	// [8](R + k * A - s * B) = 0
+	var p, q edwards25519.ExtendedPoint
+	k, s := e.k.Bytes(), e.s.Bytes()
+	p.VarTimeScalarMult(&e.a, toArray(k))
+	p.Add(&p, &e.r)
+	q.SetBase()
+	q.VarTimeScalarMult(&q, toArray(s))
+	p.Sub(&p, &q)
+	return isSmallOrder(&p)
+}
+
+// entry is a decoded signature
+type entry struct {
+	a, r edwards25519.ExtendedPoint
+	s, k ristretto.Scalar
+}
+
+func newEntry(pub PublicKey, msg, sig []byte) (*entry, bool) {
+	if len(pub) != PublicKeySize || len(sig) != SignatureSize {
+		return nil, false
+	}
+
+	e := &entry{}
+	if decompress(&e.a, pub) != nil || decompress(&e.r, sig[:32]) != nil {
+		return nil, false
+	}
+
+	var s [32]byte
+	copy(s[:], sig[32:])
+	e.s.SetBytes(&s)
+	if !bytes.Equal(e.s.Bytes(), s[:]) {
+		return nil, false
+	}
+
+	// k = SHA-512(R || A || msg) mod l
+	h := sha512.New()
+	_, _ = h.Write(sig[:32])
+	_, _ = h.Write(pub)
+	_, _ = h.Write(msg)
+	var wide [64]byte
+	copy(wide[:], h.Sum(nil))
+	e.k.SetReduced(&wide)
+	return e, true
+}
+
+// BatchVerifier accumulates signatures and verifies them at once, which is
+// much faster than one by one for large batches
+type BatchVerifier struct {
+	pubs, msgs, sigs [][]byte
+}
+
+// NewBatchVerifier returns an empty BatchVerifier
+func NewBatchVerifier() *BatchVerifier {
+	return &BatchVerifier{}
+}
+
+// Add queues the signature of msg under pub for verification
+func (b *BatchVerifier) Add(pub PublicKey, msg, sig []byte) {
+	b.pubs = append(b.pubs, pub)
+	b.msgs = append(b.msgs, msg)
+	b.sigs = append(b.sigs, sig)
+}
+
+// Len returns the number of queued signatures
+func (b *BatchVerifier) Len() int {
+	return len(b.sigs)
+}
+
+// Verify returns whether every queued signature is valid, and the validity
+// of each of them. The batch is checked first with a random linear
+// combination of the equations; when it fails, the signatures are checked
+// one by one to find the invalid ones
+func (b *BatchVerifier) Verify(rand io.Reader) (bool, []bool, error) {
+	valid := make([]bool, len(b.sigs))
+	if len(b.sigs) == 0 {
+		return true, valid, nil
+	}
+
+	ok, err := b.verifyBatch(rand)
+	if err != nil {
+		return false, nil, err
+	}
+	if ok {
+		for i := range valid {
+			valid[i] = true
+		}
+		return true, valid, nil
+	}
+
+	for i := range b.sigs {
+		valid[i] = VerifyCofactored(b.pubs[i], b.msgs[i], b.sigs[i])
+	}
+	return false, valid, nil
+}
+
+// verifyBatch checks [8](sum(z_i * R_i) + sum(z_i * k_i * A_i) - sum(z_i * s_i) * B) = 0
+// for random 128 bit z_i
+func (b *BatchVerifier) verifyBatch(rand io.Reader) (bool, error) {
+	if rand == nil {
+		rand = rng.Reader
+	}
+
+	points := make([]*edwards25519.ExtendedPoint, 0, 2*len(b.sigs)+1)
+	scalars := make([][32]byte, 0, 2*len(b.sigs)+1)
+
+	var sum ristretto.Scalar
+	sum.SetZero()
+	for i := range b.sigs {
+		e, ok := newEntry(b.pubs[i], b.msgs[i], b.sigs[i])
+		if !ok {
+			return false, nil
+		}
+
+		var buf [32]byte
+		if _, err := io.ReadFull(rand, buf[:16]); err != nil {
+			return false, err
+		}
+		var z, zk ristretto.Scalar
+		z.SetBytes(&buf)
+		zk.Mul(&z, &e.k)
+		sum.MulAdd(&z, &e.s, &sum)
+
+		points = append(points, &e.r, &e.a)
+		scalars = append(scalars, *toArray(z.Bytes()), *toArray(zk.Bytes()))
+	}
+
+	var base edwards25519.ExtendedPoint
+	base.SetBase()
+	sum.Neg(&sum)
+	points = append(points, &base)
+	scalars = append(scalars, *toArray(sum.Bytes()))
+
+	res := multiScalarMult(points, scalars)
+	return isSmallOrder(res), nil
+}
+
+// multiScalarMult returns sum(scalars[i] * points[i]) in variable time,
+// with 4 bit windows and the doublings shared by all the points
+func multiScalarMult(points []*edwards25519.ExtendedPoint, scalars [][32]byte) *edwards25519.ExtendedPoint {
+	tables := make([][16]edwards25519.ExtendedPoint, len(points))
+	for i, p := range points {
+		tables[i][0].SetZero()
+		for j := 1; j < 16; j++ {
+			tables[i][j].Add(&tables[i][j-1], p)
+		}
+	}
+
+	res := new(edwards25519.ExtendedPoint).SetZero()
+	for w := 63; w >= 0; w-- {
+		if w != 63 {
+			for j := 0; j < 4; j++ {
+				res.Double(res)
+			}
+		}
+		for i := range points {
+			nibble := (scalars[i][w/2] >> (4 * uint(w%2))) & 15
+			if nibble != 0 {
+				res.Add(res, &tables[i][nibble])
+			}
+		}
+	}
+	return res
+}
+
+// isSmallOrder returns whether [8]p is the identity
+func isSmallOrder(p *edwards25519.ExtendedPoint) bool {
+	var q edwards25519.ExtendedPoint
+	q.Double(p)
+	q.Double(&q)
+	q.Double(&q)
+	return isIdentity(&q)
+}
+
+func isIdentity(p *edwards25519.ExtendedPoint) bool {
+	return p.X.IsNonZeroI() == 0 && p.Y.Equals(&p.Z)
+}
+
+// decompress sets p to the point encoded in b as RFC 8032 does, rejecting
+// non canonical encodings
+func decompress(p *edwards25519.ExtendedPoint, b []byte) error {
+	if len(b) != 32 {
+		return ErrInvalidPoint
+	}
+	var buf [32]byte
+	copy(buf[:], b)
+	sign := int32(buf[31] >> 7)
+	buf[31] &= 0x7f
+
+	var y edwards25519.FieldElement
+	y.SetBytes(&buf)
+	if y.Bytes() != buf {
+		return ErrInvalidPoint
+	}
+
+	// x^2 = (y^2 - 1) / (d * y^2 + 1)
+	var one, y2, u, v, x, x2 edwards25519.FieldElement
+	one.SetOne()
+	y2.Square(&y)
+	u.Sub(&y2, &one)
+	v.Mul(&feD, &y2)
+	v.Add(&v, &one)
+	v.Inverse(&v)
+	u.Mul(&u, &v)
+
+	x.Sqrt(&u)
+	x2.Square(&x)
+	if !x2.Equals(&u) {
+		return ErrInvalidPoint
+	}
+	if x.IsNonZeroI() == 0 && sign == 1 {
+		return ErrInvalidPoint
+	}
+	if x.IsNegativeI() != sign {
+		x.Neg(&x)
+	}
+
+	p.X.Set(&x)
+	p.Y.Set(&y)
+	p.Z.SetOne()
+	p.T.Mul(&x, &y)
+	return nil
+}
+
+// compress returns the RFC 8032 encoding of p
+func compress(p *edwards25519.ExtendedPoint) []byte {
+	var zInv, x, y edwards25519.FieldElement
+	zInv.Inverse(&p.Z)
+	x.Mul(&p.X, &zInv)
+	y.Mul(&p.Y, &zInv)
+
+	b := y.Bytes()
+	b[31] |= byte(x.IsNegativeI()) << 7
+	return b[:]
+}
+
+// torsionFree returns the representative of p in the subgroup of order l,
+// inv8 * [8]p. It is p itself iff p has no small order component
+func torsionFree(p *edwards25519.ExtendedPoint) *edwards25519.ExtendedPoint {
+	var q edwards25519.ExtendedPoint
+	q.Double(p)
+	q.Double(&q)
+	q.Double(&q)
+	return q.VarTimeScalarMult(&q, toArray(inv8.Bytes()))
+}
+
+// PublicKeyToRistretto returns the Ristretto point of an Ed25519 public
+// key. Keys with a small order component have no such point and are rejected
+func PublicKeyToRistretto(pub PublicKey) (ristretto.Point, error) {
+	var p ristretto.Point
+	var e edwards25519.ExtendedPoint
+	if err := decompress(&e, pub); err != nil {
+		return p, err
+	}
+	if !bytes.Equal(compress(torsionFree(&e)), pub) {
+		return p, errors.New("ed25519: public key has a small order component")
+	}
+	if isIdentity(&e) {
+		return p, errors.New("ed25519: public key is the identity")
+	}
+	return ristretto.Point(e), nil
+}
+
+// PublicKeyFromRistretto returns the Ed25519 public key of a Ristretto
+// point, i.e. the encoding of its representative of order l
+func PublicKeyFromRistretto(p ristretto.Point) PublicKey {
+	e := edwards25519.ExtendedPoint(p)
+	return PublicKey(compress(torsionFree(&e)))
+}
+
+// ScalarFromPrivateKey returns the secret scalar of an Ed25519 private
+// key, so that multiplying the Ristretto base point by it gives the point
+// of its public key. The converse is impossible: Ed25519 private keys are
+// seeds the scalar is hashed from
+func ScalarFromPrivateKey(priv PrivateKey) ristretto.Scalar {
+	h := sha512.Sum512(priv.Seed())
+	h[0] &= 248
+	h[31] &= 127
+	h[31] |= 64
+
+	var wide [64]byte
+	copy(wide[:], h[:32])
+	var s ristretto.Scalar
+	s.SetReduced(&wide)
+	return s
+}
+
+func toArray(b []byte) *[32]byte {
+	var a [32]byte
+	copy(a[:], b)
+	return &a
+}
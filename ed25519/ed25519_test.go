@@ -0,0 +1,146 @@
+package ed25519
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/bwesterb/go-ristretto/edwards25519"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestRFC8032(t *testing.T) {
+	seed := unhex("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	pub := PublicKey(unhex("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"))
+	sig := unhex("e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b")
+
+	priv := PrivateKey(append(append([]byte{}, seed...), pub...))
+	assert.Equal(t, sig, Sign(priv, nil))
+	assert.True(t, Verify(pub, nil, sig))
+	assert.True(t, VerifyCofactored(pub, nil, sig))
+	assert.False(t, VerifyCofactored(pub, []byte{0}, sig))
+}
+
+func TestVerifyCofactoredRejectsNonCanonicalS(t *testing.T) {
+	pub, priv, err := GenerateKey(nil)
+	require.NoError(t, err)
+	msg := []byte("message")
+	sig := Sign(priv, msg)
+
+	// s + l
+	l, _ := new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+	s := new(big.Int).SetBytes(reverse(sig[32:]))
+	s.Add(s, l)
+	bad := append([]byte{}, sig[:32]...)
+	bad = append(bad, reverse(leftPad(s.Bytes(), 32))...)
+
+	assert.False(t, Verify(pub, msg, bad))
+	assert.False(t, VerifyCofactored(pub, msg, bad))
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+func leftPad(b []byte, n int) []byte {
+	return append(make([]byte, n-len(b)), b...)
+}
+
+func TestBatchVerifier(t *testing.T) {
+	b := NewBatchVerifier()
+	ok, valid, err := b.Verify(nil)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Empty(t, valid)
+
+	for i := 0; i < 40; i++ {
+		pub, priv, err := GenerateKey(nil)
+		require.NoError(t, err)
+		msg := []byte(fmt.Sprintf("message %d", i))
+		b.Add(pub, msg, Sign(priv, msg))
+	}
+	assert.Equal(t, 40, b.Len())
+
+	ok, valid, err = b.Verify(nil)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	for _, v := range valid {
+		assert.True(t, v)
+	}
+
+	// Tamper with two of the messages
+	b.msgs[3] = []byte("forged")
+	b.msgs[17] = []byte("forged")
+	ok, valid, err = b.Verify(nil)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	for i, v := range valid {
+		assert.Equal(t, i != 3 && i != 17, v)
+	}
+
+	// An undecodable signature
+	b.msgs[3] = []byte("message 3")
+	b.msgs[17] = []byte("message 17")
+	b.Add(b.pubs[0], b.msgs[0], b.sigs[0][:10])
+	ok, valid, err = b.Verify(nil)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, valid[40])
+	assert.True(t, valid[0])
+}
+
+func TestRistrettoConversion(t *testing.T) {
+	pub, priv, err := GenerateKey(nil)
+	require.NoError(t, err)
+
+	p, err := PublicKeyToRistretto(pub)
+	require.NoError(t, err)
+
+	s := ScalarFromPrivateKey(priv)
+	var q ristretto.Point
+	q.ScalarMultBase(&s)
+	assert.True(t, p.Equals(&q))
+
+	assert.Equal(t, pub, PublicKeyFromRistretto(q))
+
+	// Any representative of the Ristretto point gives the same key
+	var r ristretto.Point
+	r.SetBytes(toArray(q.Bytes()))
+	assert.Equal(t, pub, PublicKeyFromRistretto(r))
+	var torsion edwards25519.ExtendedPoint
+	torsion.SetTorsion1()
+	e := edwards25519.ExtendedPoint(q)
+	e.Add(&e, &torsion)
+	assert.True(t, q.Equals((*ristretto.Point)(&e)))
+	assert.Equal(t, pub, PublicKeyFromRistretto(ristretto.Point(e)))
+
+	// A key with a small order component has no Ristretto point
+	var a edwards25519.ExtendedPoint
+	require.NoError(t, decompress(&a, pub))
+	var order2 edwards25519.ExtendedPoint
+	require.NoError(t, decompress(&order2, unhex("ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")))
+	a.Add(&a, &order2)
+	_, err = PublicKeyToRistretto(PublicKey(compress(&a)))
+	assert.Error(t, err)
+
+	// Identity and undecodable keys
+	_, err = PublicKeyToRistretto(PublicKey(unhex("0100000000000000000000000000000000000000000000000000000000000000")))
+	assert.Error(t, err)
+	_, err = PublicKeyToRistretto(PublicKey(unhex("edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")))
+	assert.Equal(t, ErrInvalidPoint, err)
+}
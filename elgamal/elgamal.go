@@ -0,0 +1,207 @@
+// Package elgamal implements additively homomorphic ElGamal encryption over
+// Ristretto. An amount m is encrypted under the public key P = x * G as
+// (r * G, m * G + r * P), so that ciphertexts under the same key can be
+// added and scaled without being decrypted.
+//
+// Unlike a Pedersen commitment, a ciphertext is only computationally
+// hiding, but whoever holds the secret key, e.g. an auditor, can open it.
+// Decryption yields m * G; recovering m takes a discrete log, which Table
+// solves for small amounts
+package elgamal
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// Ciphertext is an ElGamal ciphertext
+type Ciphertext struct {
+	// C1 = r * G
+	C1 ristretto.Point
+	// C2 = m * G + r * P
+	C2 ristretto.Point
+}
+
+// GenerateKey returns a random secret key and its public key
+func GenerateKey() (ristretto.Scalar, ristretto.Point) {
+	var sk ristretto.Scalar
+	rng.Scalar(&sk)
+	var pk ristretto.Point
+	pk.ScalarMultBase(&sk)
+	return sk, pk
+}
+
+// Encrypt encrypts the amount m under pk. It returns the randomness used,
+// which proofs about the ciphertext need
+func Encrypt(pk ristretto.Point, m uint64) (Ciphertext, ristretto.Scalar) {
+	var r ristretto.Scalar
+	rng.Scalar(&r)
+	return EncryptWithRandomness(pk, scalarFromUint64(m), r), r
+}
+
+// EncryptWithRandomness encrypts the scalar m under pk with the randomness r
+func EncryptWithRandomness(pk ristretto.Point, m, r ristretto.Scalar) Ciphertext {
+	var ct Ciphertext
+	ct.C1.ScalarMultBase(&r)
+
+	var mG ristretto.Point
+	mG.ScalarMultBase(&m)
+	ct.C2.ScalarMult(&pk, &r)
+	ct.C2.Add(&ct.C2, &mG)
+	return ct
+}
+
+// Decrypt returns m * G
+func Decrypt(sk ristretto.Scalar, ct Ciphertext) ristretto.Point {
+	var xC1, mG ristretto.Point
+	xC1.ScalarMult(&ct.C1, &sk)
+	mG.Sub(&ct.C2, &xC1)
+	return mG
+}
+
+// DecryptAmount decrypts ct and solves the discrete log of the result with t
+func DecryptAmount(sk ristretto.Scalar, ct Ciphertext, t *Table) (uint64, error) {
+	return t.Solve(Decrypt(sk, ct))
+}
+
+// Add returns the encryption of the sum of the amounts of a and b
+func Add(a, b Ciphertext) Ciphertext {
+	var ct Ciphertext
+	ct.C1.Add(&a.C1, &b.C1)
+	ct.C2.Add(&a.C2, &b.C2)
+	return ct
+}
+
+// Sub returns the encryption of the difference of the amounts of a and b.
+// The amount wraps around modulo the group order if it is negative, and
+// then cannot be decrypted with a Table
+func Sub(a, b Ciphertext) Ciphertext {
+	var ct Ciphertext
+	ct.C1.Sub(&a.C1, &b.C1)
+	ct.C2.Sub(&a.C2, &b.C2)
+	return ct
+}
+
+// Mul returns the encryption of the amount of a times k
+func Mul(a Ciphertext, k ristretto.Scalar) Ciphertext {
+	var ct Ciphertext
+	ct.C1.ScalarMult(&a.C1, &k)
+	ct.C2.ScalarMult(&a.C2, &k)
+	return ct
+}
+
+// AddPlain returns the encryption of the amount of a plus m
+func AddPlain(a Ciphertext, m uint64) Ciphertext {
+	s := scalarFromUint64(m)
+	var mG ristretto.Point
+	mG.ScalarMultBase(&s)
+
+	ct := a
+	ct.C2.Add(&ct.C2, &mG)
+	return ct
+}
+
+// Rerandomize returns a fresh encryption of the amount of a under pk, which
+// cannot be linked to a without the secret key
+func Rerandomize(pk ristretto.Point, a Ciphertext) Ciphertext {
+	var zero, r ristretto.Scalar
+	zero.SetZero()
+	rng.Scalar(&r)
+	return Add(a, EncryptWithRandomness(pk, zero, r))
+}
+
+// Table solves discrete logs of amounts below 2^bits with the baby step,
+// giant step algorithm: m = i * n + j, with j * G looked up in a table of
+// n = 2^(bits/2) entries, for at most 2^(bits - bits/2) values of i
+type Table struct {
+	bits  uint
+	baby  map[[32]byte]uint64
+	giant ristretto.Point
+}
+
+// MaxTableBits bounds the amounts a Table can solve
+const MaxTableBits = 48
+
+// NewTable precomputes a Table for amounts below 2^bits
+func NewTable(bits uint) (*Table, error) {
+	if bits == 0 || bits > MaxTableBits {
+		return nil, errors.New("invalid number of bits")
+	}
+
+	n := uint64(1) << (bits / 2)
+	t := &Table{bits: bits, baby: make(map[[32]byte]uint64, n)}
+
+	var p, g ristretto.Point
+	p.SetZero()
+	g.SetBase()
+	var key [32]byte
+	for j := uint64(0); j < n; j++ {
+		p.BytesInto(&key)
+		t.baby[key] = j
+		p.Add(&p, &g)
+	}
+
+	// p = n * G
+	t.giant.Neg(&p)
+	return t, nil
+}
+
+// Solve returns m such that mG = m * G, if m is below 2^bits
+func (t *Table) Solve(mG ristretto.Point) (uint64, error) {
+	n := uint64(1) << (t.bits / 2)
+	steps := uint64(1) << (t.bits - t.bits/2)
+
+	var key [32]byte
+	p := mG
+	for i := uint64(0); i < steps; i++ {
+		p.BytesInto(&key)
+		if j, ok := t.baby[key]; ok {
+			return i*n + j, nil
+		}
+		p.Add(&p, &t.giant)
+	}
+	return 0, errors.New("amount out of the range of the table")
+}
+
+func scalarFromUint64(m uint64) ristretto.Scalar {
+	var buf [32]byte
+	binary.LittleEndian.PutUint64(buf[:8], m)
+	var s ristretto.Scalar
+	s.SetBytes(&buf)
+	return s
+}
+
+// Encode a Ciphertext
+func (ct *Ciphertext) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, ct.C1.Bytes()); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, ct.C2.Bytes())
+}
+
+// Decode a Ciphertext
+func (ct *Ciphertext) Decode(r io.Reader) error {
+	if ct == nil {
+		return errors.New("struct is nil")
+	}
+
+	var x [32]byte
+	if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+		return err
+	}
+	if !ct.C1.SetBytes(&x) {
+		return errors.New("point not encodable")
+	}
+
+	if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+		return err
+	}
+	if !ct.C2.SetBytes(&x) {
+		return errors.New("point not encodable")
+	}
+	return nil
+}
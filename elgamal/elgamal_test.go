@@ -0,0 +1,112 @@
+package elgamal
+
+import (
+	"bytes"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	table, err := NewTable(20)
+	require.NoError(t, err)
+
+	sk, pk := GenerateKey()
+	for _, m := range []uint64{0, 1, 2, 1023, 1024, 1<<20 - 1} {
+		ct, _ := Encrypt(pk, m)
+		got, err := DecryptAmount(sk, ct, table)
+		require.NoError(t, err)
+		assert.Equal(t, m, got)
+	}
+
+	ct, _ := Encrypt(pk, 1<<20)
+	_, err = DecryptAmount(sk, ct, table)
+	assert.Error(t, err)
+
+	// Wrong key
+	other, _ := GenerateKey()
+	ct, _ = Encrypt(pk, 42)
+	_, err = DecryptAmount(other, ct, table)
+	assert.Error(t, err)
+}
+
+func TestHomomorphism(t *testing.T) {
+	table, err := NewTable(16)
+	require.NoError(t, err)
+
+	sk, pk := GenerateKey()
+	a, _ := Encrypt(pk, 1200)
+	b, _ := Encrypt(pk, 34)
+
+	m, err := DecryptAmount(sk, Add(a, b), table)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1234), m)
+
+	m, err = DecryptAmount(sk, Sub(a, b), table)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1166), m)
+
+	m, err = DecryptAmount(sk, AddPlain(a, 5), table)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1205), m)
+
+	m, err = DecryptAmount(sk, Mul(b, scalarFromUint64(3)), table)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(102), m)
+
+	r := Rerandomize(pk, a)
+	assert.False(t, r.C1.Equals(&a.C1))
+	m, err = DecryptAmount(sk, r, table)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1200), m)
+}
+
+func TestEncryptWithRandomness(t *testing.T) {
+	sk, pk := GenerateKey()
+	ct, r := Encrypt(pk, 7)
+
+	again := EncryptWithRandomness(pk, scalarFromUint64(7), r)
+	assert.True(t, ct.C1.Equals(&again.C1))
+	assert.True(t, ct.C2.Equals(&again.C2))
+
+	m := scalarFromUint64(7)
+	var mG ristretto.Point
+	mG.ScalarMultBase(&m)
+	dec := Decrypt(sk, ct)
+	assert.True(t, dec.Equals(&mG))
+}
+
+func TestNewTable(t *testing.T) {
+	_, err := NewTable(0)
+	assert.Error(t, err)
+	_, err = NewTable(MaxTableBits + 1)
+	assert.Error(t, err)
+
+	// Odd number of bits
+	table, err := NewTable(5)
+	require.NoError(t, err)
+	sk, pk := GenerateKey()
+	ct, _ := Encrypt(pk, 31)
+	m, err := DecryptAmount(sk, ct, table)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(31), m)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	_, pk := GenerateKey()
+	ct, _ := Encrypt(pk, 99)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, ct.Encode(buf))
+	assert.Equal(t, 64, buf.Len())
+
+	var dec Ciphertext
+	require.NoError(t, dec.Decode(buf))
+	assert.True(t, ct.C1.Equals(&dec.C1))
+	assert.True(t, ct.C2.Equals(&dec.C2))
+
+	var nilCt *Ciphertext
+	assert.Error(t, nilCt.Decode(bytes.NewReader(make([]byte, 64))))
+}
@@ -0,0 +1,307 @@
+package elgamal
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/cipher"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/shamir"
+	"github.com/dusk-network/dusk-crypto/sigma"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// Threshold decryption. A committee shares its secret key x with Feldman
+// VSS, so that its public key is P = x * B and the public key of member i is
+// P_i = x_i * B, B being shamir.FeldmanBase. Ciphertexts addressed to the
+// committee use B for their first component, C1 = r * B. Each member
+// publishes D_i = x_i * C1 together with a DLEQ proof that it used its share,
+// and any t valid shares give x * C1 by Lagrange interpolation in the
+// exponent. Nobody ever holds x.
+//
+// Payloads are sealed with a key derived from r * P, for sealed transactions
+// that the committee only opens once they are ordered. As in TDH2, a sealed
+// payload proves knowledge of r, bound to the box and the additional data:
+// members check the proof before issuing their shares, so that nobody can
+// copy the C1 of a pending payload into their own and have the committee
+// decrypt it early
+
+// MaxSealedSize bounds the payloads accepted when decoding a SealedPayload
+const MaxSealedSize = 1 << 24
+
+// DecryptionShare is the contribution of a committee member to the
+// decryption of a ciphertext
+type DecryptionShare struct {
+	Index uint32
+	// D = x_i * C1
+	D     ristretto.Point
+	Proof sigma.Proof
+}
+
+// SealedPayload is a payload encrypted to a committee
+type SealedPayload struct {
+	// C1 = r * B
+	C1  ristretto.Point
+	Box []byte
+	// Proof proves knowledge of r, bound to Box and the additional data
+	Proof sigma.Proof
+}
+
+// EncryptToCommittee encrypts the amount m to the committee whose key was
+// dealt in d. It returns the randomness used
+func EncryptToCommittee(d *shamir.Dealing, m uint64) (Ciphertext, ristretto.Scalar, error) {
+	pk, err := d.PublicKey()
+	if err != nil {
+		return Ciphertext{}, ristretto.Scalar{}, err
+	}
+
+	var r ristretto.Scalar
+	rng.Scalar(&r)
+	ct := EncryptWithRandomness(pk, scalarFromUint64(m), r)
+	base := shamir.FeldmanBase()
+	ct.C1.ScalarMult(&base, &r)
+	return ct, r, nil
+}
+
+func shareTranscript(index uint32) *transcript.Transcript {
+	t := transcript.New("dusk.elgamal.threshold")
+	t.AppendUint64("index", uint64(index))
+	return t
+}
+
+// NewDecryptionShare returns the decryption share of c1, the first
+// component of a ciphertext, for the member holding share. The shares of
+// sealed payloads are issued with NewSealedDecryptionShare
+func NewDecryptionShare(share shamir.Share, c1 ristretto.Point) (DecryptionShare, error) {
+	if share.Index == 0 || share.Value == nil {
+		return DecryptionShare{}, errors.New("invalid share")
+	}
+
+	var x ristretto.Scalar
+	x.SetBigInt(share.Value)
+	base := shamir.FeldmanBase()
+	var pub ristretto.Point
+	pub.ScalarMult(&base, &x)
+
+	s := DecryptionShare{Index: share.Index}
+	s.D.ScalarMult(&c1, &x)
+	proof, err := sigma.Prove(shareTranscript(share.Index), sigma.DLEQ(base, pub, c1, s.D), []ristretto.Scalar{x})
+	if err != nil {
+		return DecryptionShare{}, err
+	}
+	s.Proof = proof
+	return s, nil
+}
+
+// VerifyDecryptionShare checks that s was computed with the share of its
+// member in the dealing d
+func VerifyDecryptionShare(d *shamir.Dealing, c1 ristretto.Point, s DecryptionShare) error {
+	pub, err := d.PublicShare(s.Index)
+	if err != nil {
+		return err
+	}
+	if !sigma.Verify(shareTranscript(s.Index), sigma.DLEQ(shamir.FeldmanBase(), pub, c1, s.D), s.Proof) {
+		return errors.New("invalid decryption share")
+	}
+	return nil
+}
+
+// CombineShares checks the decryption shares and combines a threshold of
+// them into x * C1
+func CombineShares(d *shamir.Dealing, c1 ristretto.Point, shares []DecryptionShare) (ristretto.Point, error) {
+	if len(shares) < d.Threshold() {
+		return ristretto.Point{}, errors.New("not enough decryption shares")
+	}
+	shares = shares[:d.Threshold()]
+
+	indices := make([]uint32, len(shares))
+	for i, s := range shares {
+		if err := VerifyDecryptionShare(d, c1, s); err != nil {
+			return ristretto.Point{}, err
+		}
+		indices[i] = s.Index
+	}
+	lambdas, err := shamir.Ristretto.Lagrange(indices)
+	if err != nil {
+		return ristretto.Point{}, err
+	}
+
+	var res, tmp ristretto.Point
+	res.SetZero()
+	for i, s := range shares {
+		var l ristretto.Scalar
+		l.SetBigInt(lambdas[i])
+		tmp.ScalarMult(&s.D, &l)
+		res.Add(&res, &tmp)
+	}
+	return res, nil
+}
+
+// ThresholdDecrypt returns m * G for a ciphertext encrypted to the committee
+func ThresholdDecrypt(d *shamir.Dealing, ct Ciphertext, shares []DecryptionShare) (ristretto.Point, error) {
+	xC1, err := CombineShares(d, ct.C1, shares)
+	if err != nil {
+		return ristretto.Point{}, err
+	}
+	var mG ristretto.Point
+	mG.Sub(&ct.C2, &xC1)
+	return mG, nil
+}
+
+func sealTranscript(box, ad []byte) *transcript.Transcript {
+	t := transcript.New("dusk.elgamal.seal")
+	t.Append("box", box)
+	t.Append("ad", ad)
+	return t
+}
+
+func sealKey(shared, c1 ristretto.Point) []byte {
+	return hash.Sha3256WithDomain("dusk.elgamal.seal", shared.Bytes(), c1.Bytes())
+}
+
+// Seal encrypts a payload to the committee whose key was dealt in d. The
+// additional data ad is authenticated but not encrypted
+func Seal(d *shamir.Dealing, payload, ad []byte) (*SealedPayload, error) {
+	pk, err := d.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > MaxSealedSize {
+		return nil, errors.New("payload is too large")
+	}
+
+	var r ristretto.Scalar
+	rng.Scalar(&r)
+	base := shamir.FeldmanBase()
+	sealed := &SealedPayload{}
+	sealed.C1.ScalarMult(&base, &r)
+	var shared ristretto.Point
+	shared.ScalarMult(&pk, &r)
+
+	aead, err := cipher.New(cipher.ChaCha20Poly1305, sealKey(shared, sealed.C1))
+	if err != nil {
+		return nil, err
+	}
+	sealed.Box, err = aead.SealRandom(payload, ad)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed.Proof, err = sigma.Prove(sealTranscript(sealed.Box, ad), sigma.DLog(base, sealed.C1), []ristretto.Scalar{r})
+	if err != nil {
+		return nil, err
+	}
+	return sealed, nil
+}
+
+// VerifySealed checks that the sender of the sealed payload knows the
+// randomness of its C1, and sealed it with the additional data ad
+func VerifySealed(sealed *SealedPayload, ad []byte) error {
+	if !sigma.Verify(sealTranscript(sealed.Box, ad), sigma.DLog(shamir.FeldmanBase(), sealed.C1), sealed.Proof) {
+		return errors.New("invalid sealed payload proof")
+	}
+	return nil
+}
+
+// NewSealedDecryptionShare returns the decryption share of a sealed payload
+// for the member holding share, once its proof is checked against ad
+func NewSealedDecryptionShare(share shamir.Share, sealed *SealedPayload, ad []byte) (DecryptionShare, error) {
+	if err := VerifySealed(sealed, ad); err != nil {
+		return DecryptionShare{}, err
+	}
+	return NewDecryptionShare(share, sealed.C1)
+}
+
+// Open decrypts a sealed payload with a threshold of decryption shares of
+// its C1
+func Open(d *shamir.Dealing, sealed *SealedPayload, ad []byte, shares []DecryptionShare) ([]byte, error) {
+	if err := VerifySealed(sealed, ad); err != nil {
+		return nil, err
+	}
+
+	shared, err := CombineShares(d, sealed.C1, shares)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.New(cipher.ChaCha20Poly1305, sealKey(shared, sealed.C1))
+	if err != nil {
+		return nil, err
+	}
+	return aead.OpenRandom(sealed.Box, ad)
+}
+
+// Encode a DecryptionShare
+func (s *DecryptionShare) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, s.Index); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, s.D.Bytes()); err != nil {
+		return err
+	}
+	return s.Proof.Encode(w)
+}
+
+// Decode a DecryptionShare
+func (s *DecryptionShare) Decode(r io.Reader) error {
+	if s == nil {
+		return errors.New("struct is nil")
+	}
+
+	if err := binary.Read(r, binary.BigEndian, &s.Index); err != nil {
+		return err
+	}
+	var x [32]byte
+	if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+		return err
+	}
+	if !s.D.SetBytes(&x) {
+		return errors.New("point not encodable")
+	}
+	return s.Proof.Decode(r)
+}
+
+// Encode a SealedPayload
+func (p *SealedPayload) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, p.C1.Bytes()); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(p.Box))); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, p.Box); err != nil {
+		return err
+	}
+	return p.Proof.Encode(w)
+}
+
+// Decode a SealedPayload
+func (p *SealedPayload) Decode(r io.Reader) error {
+	if p == nil {
+		return errors.New("struct is nil")
+	}
+
+	var x [32]byte
+	if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+		return err
+	}
+	if !p.C1.SetBytes(&x) {
+		return errors.New("point not encodable")
+	}
+
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return err
+	}
+	if n > MaxSealedSize+cipher.NonceSize+cipher.Overhead {
+		return errors.New("sealed payload is too large")
+	}
+	p.Box = make([]byte, n)
+	if _, err := io.ReadFull(r, p.Box); err != nil {
+		return err
+	}
+	return p.Proof.Decode(r)
+}
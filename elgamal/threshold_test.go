@@ -0,0 +1,157 @@
+package elgamal
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/shamir"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func committee(t *testing.T, threshold, n int) (*shamir.Dealing, []shamir.Share) {
+	d, vshares, err := shamir.DealFeldman(big.NewInt(987654321), threshold, n, nil)
+	require.Nil(t, err)
+
+	shares := make([]shamir.Share, n)
+	for i := range shares {
+		shares[i] = vshares[i].Share
+	}
+	return &d, shares
+}
+
+func decryptionShares(t *testing.T, shares []shamir.Share, ct Ciphertext) []DecryptionShare {
+	res := make([]DecryptionShare, len(shares))
+	for i, s := range shares {
+		ds, err := NewDecryptionShare(s, ct.C1)
+		require.Nil(t, err)
+		res[i] = ds
+	}
+	return res
+}
+
+func TestThresholdDecrypt(t *testing.T) {
+	d, shares := committee(t, 3, 5)
+	table, err := NewTable(16)
+	require.Nil(t, err)
+
+	ct, _, err := EncryptToCommittee(d, 1234)
+	require.Nil(t, err)
+
+	// any three members decrypt
+	for _, members := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}} {
+		var subset []shamir.Share
+		for _, i := range members {
+			subset = append(subset, shares[i])
+		}
+		mG, err := ThresholdDecrypt(d, ct, decryptionShares(t, subset, ct))
+		require.Nil(t, err)
+		m, err := table.Solve(mG)
+		require.Nil(t, err)
+		assert.Equal(t, uint64(1234), m)
+	}
+
+	// two do not
+	_, err = ThresholdDecrypt(d, ct, decryptionShares(t, shares[:2], ct))
+	assert.NotNil(t, err)
+
+	// ciphertexts to the committee stay homomorphic
+	other, _, err := EncryptToCommittee(d, 66)
+	require.Nil(t, err)
+	sum := Add(ct, other)
+	mG, err := ThresholdDecrypt(d, sum, decryptionShares(t, shares[1:4], sum))
+	require.Nil(t, err)
+	m, err := table.Solve(mG)
+	require.Nil(t, err)
+	assert.Equal(t, uint64(1300), m)
+}
+
+func TestInvalidDecryptionShare(t *testing.T) {
+	d, shares := committee(t, 2, 3)
+	ct, _, err := EncryptToCommittee(d, 7)
+	require.Nil(t, err)
+	ds := decryptionShares(t, shares, ct)
+
+	for _, s := range ds {
+		assert.Nil(t, VerifyDecryptionShare(d, ct.C1, s))
+	}
+
+	// a share claimed by another member
+	moved := ds[0]
+	moved.Index = 3
+	assert.NotNil(t, VerifyDecryptionShare(d, ct.C1, moved))
+
+	// a share computed with a wrong secret
+	wrong := shares[1]
+	wrong.Value = new(big.Int).Add(wrong.Value, big.NewInt(1))
+	bad, err := NewDecryptionShare(wrong, ct.C1)
+	require.Nil(t, err)
+	assert.NotNil(t, VerifyDecryptionShare(d, ct.C1, bad))
+	_, err = ThresholdDecrypt(d, ct, []DecryptionShare{ds[0], bad})
+	assert.NotNil(t, err)
+
+	// a share of another ciphertext
+	other, _, err := EncryptToCommittee(d, 7)
+	require.Nil(t, err)
+	assert.NotNil(t, VerifyDecryptionShare(d, other.C1, ds[0]))
+}
+
+func TestSealedPayload(t *testing.T) {
+	d, shares := committee(t, 2, 4)
+	payload := []byte("sealed transaction")
+	ad := []byte("block 42")
+
+	sealed, err := Seal(d, payload, ad)
+	require.Nil(t, err)
+
+	buf := new(bytes.Buffer)
+	require.Nil(t, sealed.Encode(buf))
+	decoded := &SealedPayload{}
+	require.Nil(t, decoded.Decode(buf))
+
+	ds := make([]DecryptionShare, 0, 2)
+	for _, s := range shares[2:] {
+		share, err := NewSealedDecryptionShare(s, decoded, ad)
+		require.Nil(t, err)
+
+		// shares travel over the wire
+		buf := new(bytes.Buffer)
+		require.Nil(t, share.Encode(buf))
+		var received DecryptionShare
+		require.Nil(t, received.Decode(buf))
+		ds = append(ds, received)
+	}
+
+	opened, err := Open(d, decoded, ad, ds)
+	require.Nil(t, err)
+	assert.Equal(t, payload, opened)
+
+	_, err = Open(d, decoded, []byte("block 43"), ds)
+	assert.NotNil(t, err)
+	_, err = Open(d, decoded, ad, ds[:1])
+	assert.NotNil(t, err)
+}
+
+func TestSealedPayloadCopiedC1(t *testing.T) {
+	d, shares := committee(t, 2, 4)
+	ad := []byte("block 42")
+	victim, err := Seal(d, []byte("sealed transaction"), ad)
+	require.Nil(t, err)
+
+	// the attacker reuses the victim's C1 in a payload of their own, ordered
+	// first, to have the committee decrypt the victim's payload early
+	own, err := Seal(d, []byte("front runner"), []byte("block 41"))
+	require.Nil(t, err)
+	for _, copied := range []*SealedPayload{
+		{C1: victim.C1, Box: own.Box, Proof: own.Proof},
+		{C1: victim.C1, Box: own.Box, Proof: victim.Proof},
+		{C1: victim.C1, Box: victim.Box, Proof: victim.Proof},
+	} {
+		_, err = NewSealedDecryptionShare(shares[0], copied, []byte("block 41"))
+		assert.NotNil(t, err)
+	}
+
+	_, err = NewSealedDecryptionShare(shares[0], victim, ad)
+	assert.Nil(t, err)
+}
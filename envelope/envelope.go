@@ -0,0 +1,192 @@
+// Package envelope frames the keys, signatures, commitments and proofs of
+// the repository for the wire. An envelope is a type byte, a version byte
+// and the length prefixed encoding of the value, so that messages mixing
+// several schemes can be parsed without any out of band context:
+//
+//	type (1) || version (1) || length (4, big endian) || payload
+//
+// Every type of the repository is registered by this package, see
+// types.go. Applications can register their own types with Register,
+// from an init function, using the values from 0x80 on
+package envelope
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Type identifies what an envelope holds
+type Type byte
+
+// MaxPayloadSize bounds the size of decoded payloads
+const MaxPayloadSize = 1 << 24
+
+var (
+	// ErrUnknownType is returned for envelopes of unregistered types
+	ErrUnknownType = errors.New("envelope: unknown type")
+	// ErrVersion is returned for envelopes of an unsupported version
+	ErrVersion = errors.New("envelope: unsupported version")
+	// ErrTrailingData is returned when a payload is longer than its value
+	ErrTrailingData = errors.New("envelope: trailing data in payload")
+)
+
+// Envelope is a framed value
+type Envelope struct {
+	Type    Type
+	Version byte
+	Payload []byte
+}
+
+// Registration describes how to encode and decode the values of a type
+type Registration struct {
+	Name string
+	// Version is written into envelopes, and the only one accepted when
+	// decoding
+	Version byte
+	Encode  func(w io.Writer, v interface{}) error
+	Decode  func(r io.Reader) (interface{}, error)
+}
+
+var (
+	mu       sync.RWMutex
+	registry = make(map[Type]Registration)
+)
+
+// Register adds a type to the registry. It panics if the type is
+// registered twice or the registration is incomplete
+func Register(t Type, r Registration) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if r.Encode == nil || r.Decode == nil {
+		panic("envelope: incomplete registration")
+	}
+	if _, ok := registry[t]; ok {
+		panic(fmt.Sprintf("envelope: type %#x registered twice", byte(t)))
+	}
+	registry[t] = r
+}
+
+// Lookup returns the registration of a type
+func Lookup(t Type) (Registration, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	r, ok := registry[t]
+	return r, ok
+}
+
+// Wrap encodes v, of type t, into an envelope
+func Wrap(t Type, v interface{}) (*Envelope, error) {
+	r, ok := Lookup(t)
+	if !ok {
+		return nil, ErrUnknownType
+	}
+
+	buf := new(bytes.Buffer)
+	if err := r.Encode(buf, v); err != nil {
+		return nil, err
+	}
+	if buf.Len() > MaxPayloadSize {
+		return nil, errors.New("envelope: payload too long")
+	}
+	return &Envelope{Type: t, Version: r.Version, Payload: buf.Bytes()}, nil
+}
+
+// Open decodes the value held by the envelope. The value has the type the
+// package of the scheme uses, e.g. *schnorr.Signature
+func (e *Envelope) Open() (interface{}, error) {
+	r, ok := Lookup(e.Type)
+	if !ok {
+		return nil, ErrUnknownType
+	}
+	if e.Version != r.Version {
+		return nil, ErrVersion
+	}
+
+	rd := bytes.NewReader(e.Payload)
+	v, err := r.Decode(rd)
+	if err != nil {
+		return nil, err
+	}
+	if rd.Len() != 0 {
+		return nil, ErrTrailingData
+	}
+	return v, nil
+}
+
+// Encode an Envelope
+func (e *Envelope) Encode(w io.Writer) error {
+	if len(e.Payload) > MaxPayloadSize {
+		return errors.New("envelope: payload too long")
+	}
+	if err := binary.Write(w, binary.BigEndian, []byte{byte(e.Type), e.Version}); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(e.Payload))); err != nil {
+		return err
+	}
+	_, err := w.Write(e.Payload)
+	return err
+}
+
+// Decode an Envelope. The payload is not decoded, see Open
+func (e *Envelope) Decode(r io.Reader) error {
+	if e == nil {
+		return errors.New("struct is nil")
+	}
+
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return err
+	}
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return err
+	}
+	if n > MaxPayloadSize {
+		return errors.New("envelope: payload too long")
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return err
+	}
+
+	e.Type = Type(header[0])
+	e.Version = header[1]
+	e.Payload = payload
+	return nil
+}
+
+// Marshal returns the encoded envelope of v, of type t
+func Marshal(t Type, v interface{}) ([]byte, error) {
+	e, err := Wrap(t, v)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err := e.Encode(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes an envelope produced by Marshal, and the value it holds
+func Unmarshal(b []byte) (Type, interface{}, error) {
+	rd := bytes.NewReader(b)
+	var e Envelope
+	if err := e.Decode(rd); err != nil {
+		return 0, nil, err
+	}
+	if rd.Len() != 0 {
+		return 0, nil, ErrTrailingData
+	}
+	v, err := e.Open()
+	if err != nil {
+		return 0, nil, err
+	}
+	return e.Type, v, nil
+}
@@ -0,0 +1,147 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/elgamal"
+	"github.com/dusk-network/dusk-crypto/schnorr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ed "golang.org/x/crypto/ed25519"
+)
+
+func TestSchnorr(t *testing.T) {
+	sk, pk := schnorr.GenerateKey()
+	sig := schnorr.Sign(sk, []byte("msg"))
+
+	b, err := Marshal(SchnorrSignature, &sig)
+	require.NoError(t, err)
+	typ, v, err := Unmarshal(b)
+	require.NoError(t, err)
+	assert.Equal(t, SchnorrSignature, typ)
+	assert.True(t, schnorr.Verify(pk, []byte("msg"), *v.(*schnorr.Signature)))
+
+	b, err = Marshal(RistrettoPoint, &pk)
+	require.NoError(t, err)
+	typ, v, err = Unmarshal(b)
+	require.NoError(t, err)
+	assert.Equal(t, RistrettoPoint, typ)
+	assert.True(t, pk.Equals(v.(*ristretto.Point)))
+}
+
+func TestBLS(t *testing.T) {
+	pk, sk, err := bls.GenKeyPair(rand.Reader)
+	require.NoError(t, err)
+	sig, err := bls.Sign(sk, pk, []byte("msg"))
+	require.NoError(t, err)
+
+	b, err := Marshal(BLSSignature, sig)
+	require.NoError(t, err)
+	_, v, err := Unmarshal(b)
+	require.NoError(t, err)
+	assert.Equal(t, sig.Marshal(), v.(*bls.Signature).Marshal())
+
+	b, err = Marshal(BLSPublicKey, pk)
+	require.NoError(t, err)
+	_, v, err = Unmarshal(b)
+	require.NoError(t, err)
+	assert.NoError(t, bls.Verify(bls.NewApk(v.(*bls.PublicKey)), []byte("msg"), sig))
+}
+
+func TestEd25519(t *testing.T) {
+	pk, sk, err := ed.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sig := ed.Sign(sk, []byte("msg"))
+
+	b, err := Marshal(Ed25519PublicKey, pk)
+	require.NoError(t, err)
+	_, v, err := Unmarshal(b)
+	require.NoError(t, err)
+
+	b, err = Marshal(Ed25519Signature, sig)
+	require.NoError(t, err)
+	_, s, err := Unmarshal(b)
+	require.NoError(t, err)
+	assert.True(t, ed.Verify(v.(ed.PublicKey), []byte("msg"), s.([]byte)))
+
+	_, err = Marshal(Ed25519Signature, sig[:10])
+	assert.Error(t, err)
+}
+
+func TestMixedStream(t *testing.T) {
+	_, pk := elgamal.GenerateKey()
+	ct, _ := elgamal.Encrypt(pk, 42)
+	sk, _ := schnorr.GenerateKey()
+	sig := schnorr.Sign(sk, []byte("msg"))
+
+	buf := new(bytes.Buffer)
+	for _, item := range []struct {
+		t Type
+		v interface{}
+	}{{ElGamalCiphertext, &ct}, {SchnorrSignature, &sig}} {
+		e, err := Wrap(item.t, item.v)
+		require.NoError(t, err)
+		require.NoError(t, e.Encode(buf))
+	}
+
+	var e Envelope
+	require.NoError(t, e.Decode(buf))
+	v, err := e.Open()
+	require.NoError(t, err)
+	got := v.(*elgamal.Ciphertext)
+	assert.True(t, ct.C1.Equals(&got.C1) && ct.C2.Equals(&got.C2))
+
+	require.NoError(t, e.Decode(buf))
+	v, err = e.Open()
+	require.NoError(t, err)
+	gotSig := v.(*schnorr.Signature)
+	assert.True(t, sig.R.Equals(&gotSig.R) && sig.S.Equals(&gotSig.S))
+}
+
+func TestErrors(t *testing.T) {
+	sk, _ := schnorr.GenerateKey()
+	sig := schnorr.Sign(sk, []byte("msg"))
+
+	// Wrong Go type for the scheme
+	_, err := Marshal(ElGamalCiphertext, &sig)
+	assert.Error(t, err)
+
+	_, err = Marshal(0xff, &sig)
+	assert.Equal(t, ErrUnknownType, err)
+
+	b, err := Marshal(SchnorrSignature, &sig)
+	require.NoError(t, err)
+
+	unknown := append([]byte{}, b...)
+	unknown[0] = 0xff
+	_, _, err = Unmarshal(unknown)
+	assert.Equal(t, ErrUnknownType, err)
+
+	version := append([]byte{}, b...)
+	version[1] = 2
+	_, _, err = Unmarshal(version)
+	assert.Equal(t, ErrVersion, err)
+
+	_, _, err = Unmarshal(b[:len(b)-1])
+	assert.Error(t, err)
+
+	_, _, err = Unmarshal(append(b, 0))
+	assert.Equal(t, ErrTrailingData, err)
+
+	// A payload longer than the signature it holds
+	e, err := Wrap(SchnorrSignature, &sig)
+	require.NoError(t, err)
+	e.Payload = append(e.Payload, 0)
+	_, err = e.Open()
+	assert.Equal(t, ErrTrailingData, err)
+}
+
+func TestRegisterTwice(t *testing.T) {
+	assert.Panics(t, func() {
+		registerCodec(SchnorrSignature, "schnorr signature", func() codec { return new(schnorr.Signature) })
+	})
+}
@@ -0,0 +1,327 @@
+package envelope
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/beacon"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/crossdleq"
+	"github.com/dusk-network/dusk-crypto/elgamal"
+	"github.com/dusk-network/dusk-crypto/kzg"
+	"github.com/dusk-network/dusk-crypto/merkletree"
+	"github.com/dusk-network/dusk-crypto/mlsag"
+	"github.com/dusk-network/dusk-crypto/rangeproof"
+	"github.com/dusk-network/dusk-crypto/rangeproof/innerproduct"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
+	"github.com/dusk-network/dusk-crypto/rangeproof/r1cs"
+	"github.com/dusk-network/dusk-crypto/schnorr"
+	"github.com/dusk-network/dusk-crypto/shamir"
+	"github.com/dusk-network/dusk-crypto/sigma"
+	"github.com/dusk-network/dusk-crypto/stealth"
+	"github.com/dusk-network/dusk-crypto/timedcommit"
+	"github.com/dusk-network/dusk-crypto/vrf"
+	ed "golang.org/x/crypto/ed25519"
+)
+
+// Types of the repository. Values from 0x80 on are left to applications
+const (
+	// Signatures and keys
+	SchnorrSignature Type = 0x01 // *schnorr.Signature
+	RistrettoPoint   Type = 0x02 // *ristretto.Point, e.g. a Schnorr public key
+	BLSSignature     Type = 0x03 // *bls.Signature
+	BLSPublicKey     Type = 0x04 // *bls.PublicKey
+	BLSUnsafeSig     Type = 0x05 // *bls.UnsafeSignature
+	Ed25519PublicKey Type = 0x06 // ed25519.PublicKey
+	Ed25519Signature Type = 0x07 // []byte
+	VRFPublicKey     Type = 0x08 // *vrf.PublicKey
+	MLSAGSignature   Type = 0x09 // *mlsag.Signature, with its keys
+	CLSAGSignature   Type = 0x0a // *mlsag.CLSAGSignature, with its keys
+
+	// Commitments and ciphertexts
+	PedersenCommitment Type = 0x10 // *pedersen.Commitment
+	ElGamalCiphertext  Type = 0x11 // *elgamal.Ciphertext
+	TimedCommitment    Type = 0x12 // *timedcommit.Commitment
+
+	// Proofs
+	RangeProof     Type = 0x20 // *rangeproof.Proof, with its commitments
+	R1CSProof      Type = 0x21 // *r1cs.Proof
+	InnerProduct   Type = 0x22 // *innerproduct.Proof
+	SwitchProof    Type = 0x23 // *pedersen.SwitchProof
+	ZeroProof      Type = 0x24 // *pedersen.ZeroProof
+	SigmaProof     Type = 0x25 // *sigma.Proof
+	SigmaOrProof   Type = 0x26 // *sigma.OrProof
+	CrossDLEQProof Type = 0x27 // *crossdleq.Proof
+	VRFProof       Type = 0x28 // *vrf.Proof
+	MerkleProof    Type = 0x29 // *merkletree.MultiProof
+	RangeChunk     Type = 0x2a // *rangeproof.Chunk
+
+	// Other objects
+	StealthAddress Type = 0x40 // *stealth.PublicAddress
+	StealthOutput  Type = 0x41 // *stealth.Output
+	StealthTxOut   Type = 0x42 // *stealth.TxOutput
+	ShamirShare    Type = 0x43 // *shamir.Share
+	VSSDealing     Type = 0x44 // *shamir.Dealing
+	BeaconEntry    Type = 0x45 // *beacon.Entry
+	KZGSRS         Type = 0x46 // *kzg.SRS
+)
+
+// codec is implemented by the types encoding themselves to a stream
+type codec interface {
+	Encode(w io.Writer) error
+	Decode(r io.Reader) error
+}
+
+// registerCodec registers a type implementing codec. newValue returns a
+// value to decode into
+func registerCodec(t Type, name string, newValue func() codec) {
+	typ := reflect.TypeOf(newValue())
+	Register(t, Registration{
+		Name:    name,
+		Version: 1,
+		Encode: func(w io.Writer, v interface{}) error {
+			c, ok := v.(codec)
+			if !ok || reflect.TypeOf(v) != typ {
+				return typeError(name, v)
+			}
+			return c.Encode(w)
+		},
+		Decode: func(r io.Reader) (interface{}, error) {
+			c := newValue()
+			if err := c.Decode(r); err != nil {
+				return nil, err
+			}
+			return c, nil
+		},
+	})
+}
+
+// registerBytes registers a type marshaled to a byte slice. Since the
+// payload is framed, unmarshal gets all of it
+func registerBytes(t Type, name string, marshal func(interface{}) ([]byte, error), unmarshal func([]byte) (interface{}, error)) {
+	Register(t, Registration{
+		Name:    name,
+		Version: 1,
+		Encode: func(w io.Writer, v interface{}) error {
+			b, err := marshal(v)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(b)
+			return err
+		},
+		Decode: func(r io.Reader) (interface{}, error) {
+			b, err := ioutil.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			return unmarshal(b)
+		},
+	})
+}
+
+func typeError(name string, v interface{}) error {
+	return fmt.Errorf("envelope: %T is not a %s", v, name)
+}
+
+func init() {
+	registerCodec(SchnorrSignature, "schnorr signature", func() codec { return new(schnorr.Signature) })
+	registerCodec(PedersenCommitment, "pedersen commitment", func() codec { return new(pedersen.Commitment) })
+	registerCodec(ElGamalCiphertext, "elgamal ciphertext", func() codec { return new(elgamal.Ciphertext) })
+	registerCodec(TimedCommitment, "timed commitment", func() codec { return new(timedcommit.Commitment) })
+	registerCodec(R1CSProof, "r1cs proof", func() codec { return new(r1cs.Proof) })
+	registerCodec(InnerProduct, "inner product proof", func() codec { return new(innerproduct.Proof) })
+	registerCodec(SwitchProof, "switch proof", func() codec { return new(pedersen.SwitchProof) })
+	registerCodec(ZeroProof, "zero proof", func() codec { return new(pedersen.ZeroProof) })
+	registerCodec(SigmaProof, "sigma proof", func() codec { return new(sigma.Proof) })
+	registerCodec(SigmaOrProof, "sigma or proof", func() codec { return new(sigma.OrProof) })
+	registerCodec(CrossDLEQProof, "cross group dleq proof", func() codec { return new(crossdleq.Proof) })
+	registerCodec(MerkleProof, "merkle multiproof", func() codec { return new(merkletree.MultiProof) })
+	registerCodec(RangeChunk, "range proof chunk", func() codec { return new(rangeproof.Chunk) })
+	registerCodec(StealthAddress, "stealth address", func() codec { return new(stealth.PublicAddress) })
+	registerCodec(StealthOutput, "stealth output", func() codec { return new(stealth.Output) })
+	registerCodec(StealthTxOut, "stealth transaction output", func() codec { return new(stealth.TxOutput) })
+	registerCodec(ShamirShare, "shamir share", func() codec { return new(shamir.Share) })
+	registerCodec(VSSDealing, "vss dealing", func() codec { return new(shamir.Dealing) })
+	registerCodec(BeaconEntry, "beacon entry", func() codec { return new(beacon.Entry) })
+	registerCodec(KZGSRS, "kzg srs", func() codec { return new(kzg.SRS) })
+
+	// Proofs and ring signatures carry the keys or commitments they are
+	// about, so that envelopes are self contained
+	Register(RangeProof, Registration{
+		Name:    "range proof",
+		Version: 1,
+		Encode: func(w io.Writer, v interface{}) error {
+			p, ok := v.(*rangeproof.Proof)
+			if !ok {
+				return typeError("range proof", v)
+			}
+			return p.Encode(w, true)
+		},
+		Decode: func(r io.Reader) (interface{}, error) {
+			p := new(rangeproof.Proof)
+			if err := p.Decode(r, true); err != nil {
+				return nil, err
+			}
+			return p, nil
+		},
+	})
+	Register(MLSAGSignature, Registration{
+		Name:    "mlsag signature",
+		Version: 1,
+		Encode: func(w io.Writer, v interface{}) error {
+			s, ok := v.(*mlsag.Signature)
+			if !ok {
+				return typeError("mlsag signature", v)
+			}
+			return s.Encode(w, true)
+		},
+		Decode: func(r io.Reader) (interface{}, error) {
+			s := new(mlsag.Signature)
+			if err := s.Decode(r, true); err != nil {
+				return nil, err
+			}
+			return s, nil
+		},
+	})
+	Register(CLSAGSignature, Registration{
+		Name:    "clsag signature",
+		Version: 1,
+		Encode: func(w io.Writer, v interface{}) error {
+			s, ok := v.(*mlsag.CLSAGSignature)
+			if !ok {
+				return typeError("clsag signature", v)
+			}
+			return s.Encode(w, true)
+		},
+		Decode: func(r io.Reader) (interface{}, error) {
+			s := new(mlsag.CLSAGSignature)
+			if err := s.Decode(r, true); err != nil {
+				return nil, err
+			}
+			return s, nil
+		},
+	})
+
+	registerBytes(RistrettoPoint, "ristretto point",
+		func(v interface{}) ([]byte, error) {
+			p, ok := v.(*ristretto.Point)
+			if !ok {
+				return nil, typeError("ristretto point", v)
+			}
+			return p.Bytes(), nil
+		},
+		func(b []byte) (interface{}, error) {
+			var buf [32]byte
+			if len(b) != len(buf) {
+				return nil, errors.New("invalid point size")
+			}
+			copy(buf[:], b)
+			p := new(ristretto.Point)
+			if !p.SetBytes(&buf) {
+				return nil, errors.New("point not encodable")
+			}
+			return p, nil
+		})
+	registerBytes(BLSSignature, "bls signature",
+		func(v interface{}) ([]byte, error) {
+			s, ok := v.(*bls.Signature)
+			if !ok {
+				return nil, typeError("bls signature", v)
+			}
+			return s.Marshal(), nil
+		},
+		func(b []byte) (interface{}, error) {
+			return bls.UnmarshalSignature(b)
+		})
+	registerBytes(BLSUnsafeSig, "bls unsafe signature",
+		func(v interface{}) ([]byte, error) {
+			s, ok := v.(*bls.UnsafeSignature)
+			if !ok {
+				return nil, typeError("bls unsafe signature", v)
+			}
+			return s.Marshal(), nil
+		},
+		func(b []byte) (interface{}, error) {
+			s := new(bls.UnsafeSignature)
+			if err := s.Unmarshal(b); err != nil {
+				return nil, err
+			}
+			return s, nil
+		})
+	registerBytes(BLSPublicKey, "bls public key",
+		func(v interface{}) ([]byte, error) {
+			pk, ok := v.(*bls.PublicKey)
+			if !ok {
+				return nil, typeError("bls public key", v)
+			}
+			return pk.Marshal(), nil
+		},
+		func(b []byte) (interface{}, error) {
+			return bls.UnmarshalPk(b)
+		})
+	registerBytes(Ed25519PublicKey, "ed25519 public key",
+		func(v interface{}) ([]byte, error) {
+			pk, ok := v.(ed.PublicKey)
+			if !ok || len(pk) != ed.PublicKeySize {
+				return nil, typeError("ed25519 public key", v)
+			}
+			return pk, nil
+		},
+		func(b []byte) (interface{}, error) {
+			if len(b) != ed.PublicKeySize {
+				return nil, errors.New("invalid public key size")
+			}
+			return ed.PublicKey(b), nil
+		})
+	registerBytes(Ed25519Signature, "ed25519 signature",
+		func(v interface{}) ([]byte, error) {
+			sig, ok := v.([]byte)
+			if !ok || len(sig) != ed.SignatureSize {
+				return nil, typeError("ed25519 signature", v)
+			}
+			return sig, nil
+		},
+		func(b []byte) (interface{}, error) {
+			if len(b) != ed.SignatureSize {
+				return nil, errors.New("invalid signature size")
+			}
+			return b, nil
+		})
+	registerBytes(VRFPublicKey, "vrf public key",
+		func(v interface{}) ([]byte, error) {
+			pk, ok := v.(*vrf.PublicKey)
+			if !ok {
+				return nil, typeError("vrf public key", v)
+			}
+			return pk[:], nil
+		},
+		func(b []byte) (interface{}, error) {
+			pk := new(vrf.PublicKey)
+			if len(b) != len(pk) {
+				return nil, errors.New("invalid public key size")
+			}
+			copy(pk[:], b)
+			return pk, nil
+		})
+	registerBytes(VRFProof, "vrf proof",
+		func(v interface{}) ([]byte, error) {
+			pi, ok := v.(*vrf.Proof)
+			if !ok {
+				return nil, typeError("vrf proof", v)
+			}
+			return pi[:], nil
+		},
+		func(b []byte) (interface{}, error) {
+			pi := new(vrf.Proof)
+			if len(b) != len(pi) {
+				return nil, errors.New("invalid proof size")
+			}
+			copy(pi[:], b)
+			return pi, nil
+		})
+}
@@ -0,0 +1,111 @@
+// Package errors defines the kinds of failures shared by the packages of
+// the repository, so that callers can handle them uniformly:
+//
+//	if errors.Is(err, cryptoerrors.ErrVerificationFailed) { ... }
+//
+// Packages return an *Error, which keeps their own message and unwraps to
+// one of the kinds below. Is also follows the Cause chains of
+// github.com/pkg/errors, which predates Unwrap
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of failures
+var (
+	// ErrMalformedPoint is returned for encodings of points off the curve
+	// or otherwise invalid
+	ErrMalformedPoint = errors.New("malformed point")
+	// ErrNotCanonical is returned for encodings that decode to a valid value
+	// but are not its canonical encoding
+	ErrNotCanonical = errors.New("non canonical encoding")
+	// ErrInvalidLength is returned for inputs of the wrong size, and lists
+	// of inconsistent lengths
+	ErrInvalidLength = errors.New("invalid length")
+	// ErrOutOfRange is returned for values outside of their valid range
+	ErrOutOfRange = errors.New("value out of range")
+	// ErrVerificationFailed is returned when a signature or a proof does not
+	// verify
+	ErrVerificationFailed = errors.New("verification failed")
+)
+
+// Error is a failure of a given kind
+type Error struct {
+	// Kind is one of the kinds above
+	Kind error
+	// Msg is the message of the package returning the error
+	Msg string
+	// Err is the underlying error, if any
+	Err error
+}
+
+// New returns an error of the given kind
+func New(kind error, msg string) error {
+	return &Error{Kind: kind, Msg: msg}
+}
+
+// Newf returns an error of the given kind, formatting its message
+func Newf(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Wrap returns an error of the given kind caused by err
+func Wrap(kind error, err error, msg string) error {
+	return &Error{Kind: kind, Msg: msg, Err: err}
+}
+
+// Error returns the message, followed by the one of the underlying error
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Msg + ": " + e.Err.Error()
+	}
+	return e.Msg
+}
+
+// Unwrap returns the kind of the error
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Is reports whether err is of the given kind, or is kind itself
+func Is(err, kind error) bool {
+	for err != nil {
+		if err == kind {
+			return true
+		}
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Cause() error }:
+			err = e.Cause()
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// KindOf returns the kind of err, or nil if it has none
+func KindOf(err error) error {
+	var e *Error
+	for err != nil {
+		if x, ok := err.(*Error); ok {
+			e = x
+			break
+		}
+		switch w := err.(type) {
+		case interface{ Unwrap() error }:
+			err = w.Unwrap()
+		case interface{ Cause() error }:
+			err = w.Cause()
+		default:
+			err = nil
+		}
+	}
+	if e == nil {
+		return nil
+	}
+	return e.Kind
+}
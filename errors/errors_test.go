@@ -0,0 +1,40 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIs(t *testing.T) {
+	err := New(ErrVerificationFailed, "bls: Invalid Signature")
+	assert.Equal(t, "bls: Invalid Signature", err.Error())
+	assert.True(t, Is(err, ErrVerificationFailed))
+	assert.False(t, Is(err, ErrMalformedPoint))
+	assert.True(t, errors.Is(err, ErrVerificationFailed))
+
+	var e *Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, ErrVerificationFailed, e.Kind)
+
+	// Through the wrappers of github.com/pkg/errors
+	wrapped := pkgerrors.Wrap(err, "context")
+	assert.True(t, Is(wrapped, ErrVerificationFailed))
+	assert.Equal(t, ErrVerificationFailed, KindOf(wrapped))
+
+	assert.False(t, Is(nil, ErrVerificationFailed))
+	assert.Nil(t, KindOf(errors.New("other")))
+}
+
+func TestWrap(t *testing.T) {
+	cause := errors.New("bad point")
+	err := Wrap(ErrMalformedPoint, cause, "bls: invalid public key")
+	assert.Equal(t, "bls: invalid public key: bad point", err.Error())
+	assert.True(t, Is(err, ErrMalformedPoint))
+
+	err = Newf(ErrInvalidLength, "got %d bytes", 3)
+	assert.Equal(t, "got 3 bytes", err.Error())
+	assert.Equal(t, ErrInvalidLength, KindOf(err))
+}
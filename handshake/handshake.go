@@ -0,0 +1,335 @@
+// Package handshake implements the Noise XX handshake over Ristretto, so
+// that two nodes authenticate each other with their identity keys and agree
+// on transport keys:
+//
+//   -> e
+//   <- e, ee, s, es
+//   -> s, se
+//
+// Neither party needs to know the other's key in advance, and the static
+// keys are encrypted. A node can additionally bind its BLS key to the
+// session, by sending it with a signature of the handshake hash along with
+// its static key.
+//
+// The key derivation uses dh.Expand and the hash is SHA3-256, so the
+// handshake does not interoperate with other Noise implementations
+package handshake
+
+import (
+	"bytes"
+	"errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/dh"
+	"github.com/dusk-network/dusk-crypto/hash"
+)
+
+const protocolName = "Noise_XX_Ristretto_ChaChaPoly_SHA3-256"
+
+const (
+	pointSize        = 32
+	blsPublicKeySize = 129
+	blsSignatureSize = 64
+)
+
+// Config configures one side of a handshake
+type Config struct {
+	// Initiator is true for the party sending the first message
+	Initiator bool
+	// Static is the identity key of the node
+	Static ristretto.Scalar
+	// Prologue is data both parties must agree on, e.g. a network identifier
+	Prologue []byte
+	// BLSPublic and BLSSecret optionally bind a BLS key to the session
+	BLSPublic *bls.PublicKey
+	BLSSecret *bls.SecretKey
+}
+
+// Handshake is the state of one side of a handshake
+type Handshake struct {
+	ss        *symmetricState
+	initiator bool
+	step      int
+
+	s, e       ristretto.Scalar
+	sPub, ePub ristretto.Point
+	rs, re     ristretto.Point
+
+	blsPublic *bls.PublicKey
+	blsSecret *bls.SecretKey
+	peerBLS   *bls.PublicKey
+}
+
+// Session holds the transport keys produced by a completed handshake
+type Session struct {
+	Send *CipherState
+	Recv *CipherState
+	// Hash is the handshake hash, which identifies the session, e.g. for
+	// channel binding
+	Hash [32]byte
+}
+
+// New starts a handshake
+func New(cfg Config) (*Handshake, error) {
+	if (cfg.BLSPublic == nil) != (cfg.BLSSecret == nil) {
+		return nil, errors.New("incomplete BLS key pair")
+	}
+
+	hs := &Handshake{
+		ss:        newSymmetricState(protocolName),
+		initiator: cfg.Initiator,
+		s:         cfg.Static,
+		blsPublic: cfg.BLSPublic,
+		blsSecret: cfg.BLSSecret,
+	}
+	hs.sPub.ScalarMultBase(&hs.s)
+	hs.ss.mixHash(cfg.Prologue)
+	return hs, nil
+}
+
+// Complete returns whether all three messages were processed
+func (hs *Handshake) Complete() bool {
+	return hs.step == 3
+}
+
+// PeerStatic returns the identity key of the peer, once it was received
+func (hs *Handshake) PeerStatic() ristretto.Point {
+	return hs.rs
+}
+
+// PeerBLS returns the BLS key the peer bound to the session, if any
+func (hs *Handshake) PeerBLS() *bls.PublicKey {
+	return hs.peerBLS
+}
+
+// writing returns whether it is the turn of this party to send
+func (hs *Handshake) writing() bool {
+	return (hs.step%2 == 0) == hs.initiator
+}
+
+// WriteMessage returns the next handshake message, carrying payload
+func (hs *Handshake) WriteMessage(payload []byte) ([]byte, error) {
+	if hs.Complete() || !hs.writing() {
+		return nil, errors.New("not our turn to write")
+	}
+
+	var msg []byte
+	switch hs.step {
+	case 0:
+		// -> e
+		msg = hs.writeEphemeral()
+	case 1:
+		// <- e, ee, s, es
+		msg = hs.writeEphemeral()
+		if err := hs.mixDH(hs.e, hs.re); err != nil {
+			return nil, err
+		}
+		ct, err := hs.ss.encryptAndHash(hs.sPub.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		msg = append(msg, ct...)
+		if err := hs.mixDH(hs.s, hs.re); err != nil {
+			return nil, err
+		}
+	case 2:
+		// -> s, se
+		ct, err := hs.ss.encryptAndHash(hs.sPub.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		msg = ct
+		if err := hs.mixDH(hs.s, hs.re); err != nil {
+			return nil, err
+		}
+	}
+
+	if hs.step > 0 {
+		id, err := hs.identity()
+		if err != nil {
+			return nil, err
+		}
+		payload = append(id, payload...)
+	}
+	ct, err := hs.ss.encryptAndHash(payload)
+	if err != nil {
+		return nil, err
+	}
+	hs.step++
+	return append(msg, ct...), nil
+}
+
+// ReadMessage processes the next message of the peer and returns its payload
+func (hs *Handshake) ReadMessage(msg []byte) ([]byte, error) {
+	if hs.Complete() || hs.writing() {
+		return nil, errors.New("not our turn to read")
+	}
+
+	var err error
+	switch hs.step {
+	case 0:
+		// -> e
+		if msg, err = hs.readEphemeral(msg); err != nil {
+			return nil, err
+		}
+	case 1:
+		// <- e, ee, s, es
+		if msg, err = hs.readEphemeral(msg); err != nil {
+			return nil, err
+		}
+		if err := hs.mixDH(hs.e, hs.re); err != nil {
+			return nil, err
+		}
+		if msg, err = hs.readStatic(msg); err != nil {
+			return nil, err
+		}
+		if err := hs.mixDH(hs.e, hs.rs); err != nil {
+			return nil, err
+		}
+	case 2:
+		// -> s, se
+		if msg, err = hs.readStatic(msg); err != nil {
+			return nil, err
+		}
+		if err := hs.mixDH(hs.e, hs.rs); err != nil {
+			return nil, err
+		}
+	}
+
+	// the identity is signed over the hash before the payload
+	h := hs.ss.h
+	payload, err := hs.ss.decryptAndHash(msg)
+	if err != nil {
+		return nil, err
+	}
+	if hs.step > 0 {
+		if payload, err = hs.readIdentity(h, payload); err != nil {
+			return nil, err
+		}
+	}
+	hs.step++
+	return payload, nil
+}
+
+// Session returns the transport keys of a completed handshake
+func (hs *Handshake) Session() (*Session, error) {
+	if !hs.Complete() {
+		return nil, errors.New("handshake is not complete")
+	}
+	c1, c2, err := hs.ss.split()
+	if err != nil {
+		return nil, err
+	}
+	if hs.initiator {
+		return &Session{Send: c1, Recv: c2, Hash: hs.ss.h}, nil
+	}
+	return &Session{Send: c2, Recv: c1, Hash: hs.ss.h}, nil
+}
+
+func (hs *Handshake) writeEphemeral() []byte {
+	hs.e, hs.ePub = dh.GenerateKeyPair()
+	e := hs.ePub.Bytes()
+	hs.ss.mixHash(e)
+	return e
+}
+
+func (hs *Handshake) readEphemeral(msg []byte) ([]byte, error) {
+	if len(msg) < pointSize {
+		return nil, errors.New("message too short")
+	}
+	if err := setPoint(&hs.re, msg[:pointSize]); err != nil {
+		return nil, err
+	}
+	hs.ss.mixHash(msg[:pointSize])
+	return msg[pointSize:], nil
+}
+
+func (hs *Handshake) readStatic(msg []byte) ([]byte, error) {
+	n := pointSize + hs.ss.overhead()
+	if len(msg) < n {
+		return nil, errors.New("message too short")
+	}
+	s, err := hs.ss.decryptAndHash(msg[:n])
+	if err != nil {
+		return nil, err
+	}
+	if err := setPoint(&hs.rs, s); err != nil {
+		return nil, err
+	}
+	return msg[n:], nil
+}
+
+func (hs *Handshake) mixDH(sk ristretto.Scalar, pk ristretto.Point) error {
+	shared, err := dh.SharedSecret(sk, pk)
+	if err != nil {
+		return err
+	}
+	return hs.ss.mixKey(shared[:])
+}
+
+// bindingMessage is the message signed with the BLS key, made of the
+// handshake hash and the static key of the signer. It is signed with a unique
+// signature, which hashes it with the BLS key to a G1 point whose discrete log
+// is unknown: a binding seen in one session cannot be rescaled into a binding
+// of the same BLS key to another static key or session
+func bindingMessage(h [32]byte, static []byte) []byte {
+	return hash.Sha3256WithDomain("dusk.handshake.bls", h[:], static)
+}
+
+// identity returns the payload prefix that binds the BLS key, if any:
+// a flag byte, followed by the key and the signature
+func (hs *Handshake) identity() ([]byte, error) {
+	if hs.blsPublic == nil {
+		return []byte{0}, nil
+	}
+
+	sig, err := bls.SignUnique(hs.blsSecret, hs.blsPublic, bindingMessage(hs.ss.h, hs.sPub.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	id := []byte{1}
+	id = append(id, hs.blsPublic.Marshal()...)
+	return append(id, sig.Marshal()...), nil
+}
+
+func (hs *Handshake) readIdentity(h [32]byte, payload []byte) ([]byte, error) {
+	if len(payload) < 1 {
+		return nil, errors.New("missing identity")
+	}
+	switch payload[0] {
+	case 0:
+		return payload[1:], nil
+	case 1:
+	default:
+		return nil, errors.New("invalid identity")
+	}
+
+	payload = payload[1:]
+	if len(payload) < blsPublicKeySize+blsSignatureSize {
+		return nil, errors.New("identity too short")
+	}
+	pk := &bls.PublicKey{}
+	if err := pk.Unmarshal(payload[:blsPublicKeySize]); err != nil {
+		return nil, err
+	}
+	sig := &bls.UniqueSignature{}
+	if err := sig.Unmarshal(payload[blsPublicKeySize : blsPublicKeySize+blsSignatureSize]); err != nil {
+		return nil, err
+	}
+	if err := bls.VerifyUnique(pk, bindingMessage(h, hs.rs.Bytes()), sig); err != nil {
+		return nil, err
+	}
+
+	hs.peerBLS = pk
+	return payload[blsPublicKeySize+blsSignatureSize:], nil
+}
+
+func setPoint(p *ristretto.Point, b []byte) error {
+	var buf [pointSize]byte
+	copy(buf[:], b)
+	if !p.SetBytes(&buf) || !bytes.Equal(p.Bytes(), b) {
+		return errors.New("invalid point")
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package handshake
+
+import (
+	"math/big"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/dh"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run performs a handshake and returns both sides
+func run(t *testing.T, icfg, rcfg Config) (*Handshake, *Handshake) {
+	icfg.Initiator = true
+	i, err := New(icfg)
+	require.Nil(t, err)
+	r, err := New(rcfg)
+	require.Nil(t, err)
+
+	msg, err := i.WriteMessage([]byte("hello"))
+	require.Nil(t, err)
+	payload, err := r.ReadMessage(msg)
+	require.Nil(t, err)
+	assert.Equal(t, []byte("hello"), payload)
+
+	msg, err = r.WriteMessage([]byte("from responder"))
+	require.Nil(t, err)
+	payload, err = i.ReadMessage(msg)
+	require.Nil(t, err)
+	assert.Equal(t, []byte("from responder"), payload)
+
+	msg, err = i.WriteMessage(nil)
+	require.Nil(t, err)
+	payload, err = r.ReadMessage(msg)
+	require.Nil(t, err)
+	assert.Empty(t, payload)
+
+	assert.True(t, i.Complete())
+	assert.True(t, r.Complete())
+	return i, r
+}
+
+func TestHandshake(t *testing.T) {
+	is, ip := dh.GenerateKeyPair()
+	rs, rp := dh.GenerateKeyPair()
+
+	i, r := run(t, Config{Static: is, Prologue: []byte("net")}, Config{Static: rs, Prologue: []byte("net")})
+	ipeer, rpeer := i.PeerStatic(), r.PeerStatic()
+	assert.True(t, ipeer.Equals(&rp))
+	assert.True(t, rpeer.Equals(&ip))
+	assert.Nil(t, i.PeerBLS())
+
+	isess, err := i.Session()
+	require.Nil(t, err)
+	rsess, err := r.Session()
+	require.Nil(t, err)
+	assert.Equal(t, isess.Hash, rsess.Hash)
+
+	for _, msg := range []string{"one", "two", "three"} {
+		ct, err := isess.Send.Encrypt(nil, []byte(msg))
+		require.Nil(t, err)
+		pt, err := rsess.Recv.Decrypt(nil, ct)
+		require.Nil(t, err)
+		assert.Equal(t, msg, string(pt))
+	}
+	ct, err := rsess.Send.Encrypt([]byte("ad"), []byte("reply"))
+	require.Nil(t, err)
+	_, err = isess.Recv.Decrypt(nil, ct)
+	assert.NotNil(t, err)
+	pt, err := isess.Recv.Decrypt([]byte("ad"), ct)
+	require.Nil(t, err)
+	assert.Equal(t, "reply", string(pt))
+
+	// the directions use different keys
+	ct, err = isess.Send.Encrypt(nil, []byte("loop"))
+	require.Nil(t, err)
+	_, err = isess.Recv.Decrypt(nil, ct)
+	assert.NotNil(t, err)
+}
+
+func TestBLSBinding(t *testing.T) {
+	is, _ := dh.GenerateKeyPair()
+	rs, _ := dh.GenerateKeyPair()
+	pk, sk, err := bls.GenKeyPair(nil)
+	require.Nil(t, err)
+
+	i, r := run(t, Config{Static: is}, Config{Static: rs, BLSPublic: pk, BLSSecret: sk})
+	require.NotNil(t, i.PeerBLS())
+	assert.Equal(t, pk.Marshal(), i.PeerBLS().Marshal())
+	assert.Nil(t, r.PeerBLS())
+
+	_, err = New(Config{Static: is, BLSPublic: pk})
+	assert.NotNil(t, err)
+}
+
+func TestBLSBindingTransplant(t *testing.T) {
+	vs, vp := dh.GenerateKeyPair()
+	_, ap := dh.GenerateKeyPair()
+	rs, _ := dh.GenerateKeyPair()
+	pk, sk, err := bls.GenKeyPair(nil)
+	require.Nil(t, err)
+
+	victim, err := New(Config{Static: vs, BLSPublic: pk, BLSSecret: sk})
+	require.Nil(t, err)
+	id, err := victim.identity()
+	require.Nil(t, err)
+
+	// with h0 = g1^k, the binding of the attacker's static key in another
+	// session was k2/k1 times the victim's binding, for public k1 and k2
+	var h2 [32]byte
+	copy(h2[:], "another session")
+	k1 := hash.HashToBN256Scalar("dusk.bls.h0", bindingMessage(victim.ss.h, vp.Bytes()))
+	k2 := hash.HashToBN256Scalar("dusk.bls.h0", bindingMessage(h2, ap.Bytes()))
+	ratio := new(big.Int).ModInverse(k1, bn256.Order)
+	ratio.Mul(ratio, k2).Mod(ratio, bn256.Order)
+
+	p := new(bn256.G1)
+	_, err = p.Unmarshal(id[1+blsPublicKeySize:])
+	require.Nil(t, err)
+	transplanted := append(append([]byte{}, id[:1+blsPublicKeySize]...), new(bn256.G1).ScalarMult(p, ratio).Marshal()...)
+
+	r, err := New(Config{Static: rs})
+	require.Nil(t, err)
+	r.rs = ap
+	_, err = r.readIdentity(h2, transplanted)
+	assert.NotNil(t, err)
+	assert.Nil(t, r.PeerBLS())
+
+	// the binding only holds in the session and for the key it was made for
+	_, err = r.readIdentity(victim.ss.h, id)
+	assert.NotNil(t, err)
+	r.rs = vp
+	_, err = r.readIdentity(victim.ss.h, id)
+	require.Nil(t, err)
+	assert.Equal(t, pk.Marshal(), r.PeerBLS().Marshal())
+}
+
+func TestHandshakeFailures(t *testing.T) {
+	is, _ := dh.GenerateKeyPair()
+	rs, _ := dh.GenerateKeyPair()
+
+	// different prologues
+	i, err := New(Config{Initiator: true, Static: is, Prologue: []byte("a")})
+	require.Nil(t, err)
+	r, err := New(Config{Static: rs, Prologue: []byte("b")})
+	require.Nil(t, err)
+	msg, err := i.WriteMessage(nil)
+	require.Nil(t, err)
+	_, err = r.ReadMessage(msg)
+	require.Nil(t, err)
+	msg, err = r.WriteMessage(nil)
+	require.Nil(t, err)
+	_, err = i.ReadMessage(msg)
+	assert.NotNil(t, err)
+
+	// out of turn and tampered messages
+	i, err = New(Config{Initiator: true, Static: is})
+	require.Nil(t, err)
+	r, err = New(Config{Static: rs})
+	require.Nil(t, err)
+	_, err = r.WriteMessage(nil)
+	assert.NotNil(t, err)
+	_, err = i.ReadMessage(nil)
+	assert.NotNil(t, err)
+
+	msg, err = i.WriteMessage(nil)
+	require.Nil(t, err)
+	_, err = r.ReadMessage(msg)
+	require.Nil(t, err)
+	msg, err = r.WriteMessage(nil)
+	require.Nil(t, err)
+	msg[len(msg)-1] ^= 1
+	_, err = i.ReadMessage(msg)
+	assert.NotNil(t, err)
+	_, err = i.Session()
+	assert.NotNil(t, err)
+
+	// the identity ephemeral key is rejected
+	r, err = New(Config{Static: rs})
+	require.Nil(t, err)
+	var zero ristretto.Point
+	zero.SetZero()
+	_, err = r.ReadMessage(zero.Bytes())
+	require.Nil(t, err)
+	_, err = r.WriteMessage(nil)
+	assert.NotNil(t, err)
+}
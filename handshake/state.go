@@ -0,0 +1,139 @@
+package handshake
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/dusk-network/dusk-crypto/dh"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/sha3"
+)
+
+// tagSize is the size of the Poly1305 authentication tag
+const tagSize = 16
+
+// maxNonce is reserved, so that a nonce is never reused
+const maxNonce = ^uint64(0)
+
+// CipherState encrypts messages with a key and a counter nonce, like the
+// Noise CipherState. Both directions of a session have their own
+type CipherState struct {
+	key []byte
+	n   uint64
+}
+
+func (cs *CipherState) nonce() ([]byte, error) {
+	if cs.n == maxNonce {
+		return nil, errors.New("nonces exhausted")
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.LittleEndian.PutUint64(nonce[4:], cs.n)
+	return nonce, nil
+}
+
+// Encrypt seals plaintext with the next nonce
+func (cs *CipherState) Encrypt(ad, plaintext []byte) ([]byte, error) {
+	if cs.key == nil {
+		return append([]byte{}, plaintext...), nil
+	}
+	nonce, err := cs.nonce()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(cs.key)
+	if err != nil {
+		return nil, err
+	}
+	cs.n++
+	return aead.Seal(nil, nonce, plaintext, ad), nil
+}
+
+// Decrypt opens ciphertext with the next nonce. The nonce only advances if
+// the ciphertext is authentic
+func (cs *CipherState) Decrypt(ad, ciphertext []byte) ([]byte, error) {
+	if cs.key == nil {
+		return append([]byte{}, ciphertext...), nil
+	}
+	nonce, err := cs.nonce()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(cs.key)
+	if err != nil {
+		return nil, err
+	}
+	pt, err := aead.Open(nil, nonce, ciphertext, ad)
+	if err != nil {
+		return nil, err
+	}
+	cs.n++
+	return pt, nil
+}
+
+// symmetricState holds the chaining key and the handshake hash, like the
+// Noise SymmetricState. Keys are derived with dh.Expand instead of HKDF with
+// the chaining key as salt, the chaining key being passed as context
+type symmetricState struct {
+	ck [32]byte
+	h  [32]byte
+	cs CipherState
+}
+
+func newSymmetricState(protocol string) *symmetricState {
+	ss := &symmetricState{}
+	ss.h = sha3.Sum256([]byte(protocol))
+	ss.ck = ss.h
+	return ss
+}
+
+func (ss *symmetricState) mixHash(data []byte) {
+	buf := make([]byte, 0, len(ss.h)+len(data))
+	buf = append(buf, ss.h[:]...)
+	buf = append(buf, data...)
+	ss.h = sha3.Sum256(buf)
+}
+
+func (ss *symmetricState) mixKey(ikm []byte) error {
+	out, err := dh.Expand(ikm, "dusk.handshake.mixkey", ss.ck[:], 64)
+	if err != nil {
+		return err
+	}
+	copy(ss.ck[:], out[:32])
+	ss.cs = CipherState{key: out[32:]}
+	return nil
+}
+
+func (ss *symmetricState) encryptAndHash(plaintext []byte) ([]byte, error) {
+	ct, err := ss.cs.Encrypt(ss.h[:], plaintext)
+	if err != nil {
+		return nil, err
+	}
+	ss.mixHash(ct)
+	return ct, nil
+}
+
+func (ss *symmetricState) decryptAndHash(ciphertext []byte) ([]byte, error) {
+	pt, err := ss.cs.Decrypt(ss.h[:], ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	ss.mixHash(ciphertext)
+	return pt, nil
+}
+
+// overhead returns the size added by encryptAndHash
+func (ss *symmetricState) overhead() int {
+	if ss.cs.key == nil {
+		return 0
+	}
+	return tagSize
+}
+
+// split derives the transport keys of the initiator and of the responder
+func (ss *symmetricState) split() (*CipherState, *CipherState, error) {
+	out, err := dh.Expand(nil, "dusk.handshake.split", ss.ck[:], 64)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &CipherState{key: out[:32]}, &CipherState{key: out[32:]}, nil
+}
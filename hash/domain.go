@@ -0,0 +1,120 @@
+package hash
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/bn256"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// The helpers below hash a domain and a list of byte slices. The domain and
+// every slice are prefixed with their length, so that neither the domain nor
+// the boundaries between the slices can be shifted to produce a collision.
+// Every protocol should use its own domain, e.g. "dusk.schnorr"
+
+// writeWithDomain writes the length prefixed domain and data to h
+func writeWithDomain(h Hash, domain string, data [][]byte) {
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(len(domain)))
+	_, _ = h.Write(l[:])
+	_, _ = h.Write([]byte(domain))
+
+	for _, d := range data {
+		binary.BigEndian.PutUint32(l[:], uint32(len(d)))
+		_, _ = h.Write(l[:])
+		_, _ = h.Write(d)
+	}
+}
+
+func sumWithDomain(h Hash, domain string, data [][]byte) []byte {
+	writeWithDomain(h, domain, data)
+	return h.Sum(nil)
+}
+
+// Blake2b256WithDomain returns the domain separated Blake2b-256 hash of data
+func Blake2b256WithDomain(domain string, data ...[]byte) []byte {
+	h, _ := blake2b.New256(nil)
+	return sumWithDomain(h, domain, data)
+}
+
+// NewBlake2b256WithDomain returns a Blake2b-256 hash which has absorbed the
+// length prefixed domain. What is written to it is hashed as a single
+// message, without a length prefix, which suits data streamed in pieces of
+// unknown total size
+func NewBlake2b256WithDomain(domain string) Hash {
+	h, _ := blake2b.New256(nil)
+	writeWithDomain(h, domain, nil)
+	return h
+}
+
+// Blake2b512WithDomain returns the domain separated Blake2b-512 hash of data
+func Blake2b512WithDomain(domain string, data ...[]byte) []byte {
+	h, _ := blake2b.New512(nil)
+	return sumWithDomain(h, domain, data)
+}
+
+// Sha3256WithDomain returns the domain separated SHA3-256 hash of data
+func Sha3256WithDomain(domain string, data ...[]byte) []byte {
+	return sumWithDomain(sha3.New256(), domain, data)
+}
+
+// Sha3512WithDomain returns the domain separated SHA3-512 hash of data
+func Sha3512WithDomain(domain string, data ...[]byte) []byte {
+	return sumWithDomain(sha3.New512(), domain, data)
+}
+
+// HashToScalar hashes data to a scalar of the Ristretto group. The 512 bit
+// digest is reduced, so that the result is uniform
+func HashToScalar(domain string, data ...[]byte) ristretto.Scalar {
+	var wide [64]byte
+	copy(wide[:], Blake2b512WithDomain(domain, data...))
+
+	var s ristretto.Scalar
+	s.SetReduced(&wide)
+	return s
+}
+
+// HashToBN256Scalar hashes data to an integer modulo the order of the
+// bn256 groups. The 512 bit digest is reduced, so that the result is uniform
+func HashToBN256Scalar(domain string, data ...[]byte) *big.Int {
+	s := new(big.Int).SetBytes(Blake2b512WithDomain(domain, data...))
+	return s.Mod(s, bn256.Order)
+}
+
+// HashToPoint hashes data to a point of the Ristretto group whose discrete
+// log is unknown. Each half of the 512 bit digest is mapped with Elligator
+// and the two points are added, so that the result is uniform
+func HashToPoint(domain string, data ...[]byte) ristretto.Point {
+	digest := Blake2b512WithDomain(domain, data...)
+
+	var lo, hi [32]byte
+	copy(lo[:], digest[:32])
+	copy(hi[:], digest[32:])
+
+	var p, q ristretto.Point
+	p.SetElligator(&lo)
+	q.SetElligator(&hi)
+	p.Add(&p, &q)
+	return p
+}
+
+// HashToG1 hashes data to a point of bn256 G1 whose discrete log is unknown.
+// The digest, with a counter appended, is taken as an x coordinate until one
+// is on the curve. G1 has no cofactor, so any such point is in the group
+func HashToG1(domain string, data ...[]byte) *bn256.G1 {
+	var ctr [4]byte
+	for i := uint32(0); ; i++ {
+		binary.BigEndian.PutUint32(ctr[:], i)
+		x := HashToBN256Scalar(domain, append(data[:len(data):len(data)], ctr[:])...)
+
+		buf := make([]byte, 33)
+		xb := x.Bytes()
+		copy(buf[32-len(xb):32], xb)
+		if p, err := bn256.Decompress(buf); err == nil {
+			return p
+		}
+	}
+}
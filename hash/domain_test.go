@@ -0,0 +1,92 @@
+package hash
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dusk-network/bn256"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDomain(t *testing.T) {
+	helpers := []func(string, ...[]byte) []byte{
+		Blake2b256WithDomain,
+		Blake2b512WithDomain,
+		Sha3256WithDomain,
+		Sha3512WithDomain,
+	}
+
+	for _, h := range helpers {
+		a := h("dusk.test", []byte("ab"), []byte("c"))
+		assert.Equal(t, a, h("dusk.test", []byte("ab"), []byte("c")))
+
+		// the boundaries between the inputs and the domain matter
+		assert.NotEqual(t, a, h("dusk.test", []byte("a"), []byte("bc")))
+		assert.NotEqual(t, a, h("dusk.test", []byte("abc")))
+		assert.NotEqual(t, a, h("dusk.testab", []byte("c")))
+		assert.NotEqual(t, a, h("dusk.other", []byte("ab"), []byte("c")))
+	}
+
+	assert.Len(t, Blake2b256WithDomain("d"), 32)
+	assert.Len(t, Sha3512WithDomain("d"), 64)
+}
+
+func TestNewBlake2b256WithDomain(t *testing.T) {
+	h := NewBlake2b256WithDomain("dusk.test")
+	_, _ = h.Write([]byte("ab"))
+	_, _ = h.Write([]byte("c"))
+	a := h.Sum(nil)
+
+	// writes are not delimited
+	h = NewBlake2b256WithDomain("dusk.test")
+	_, _ = h.Write([]byte("abc"))
+	assert.Equal(t, a, h.Sum(nil))
+
+	h = NewBlake2b256WithDomain("dusk.other")
+	_, _ = h.Write([]byte("abc"))
+	assert.NotEqual(t, a, h.Sum(nil))
+}
+
+func TestHashToScalar(t *testing.T) {
+	s1 := HashToScalar("dusk.test", []byte("msg"))
+	s2 := HashToScalar("dusk.test", []byte("msg"))
+	s3 := HashToScalar("dusk.other", []byte("msg"))
+	assert.True(t, s1.Equals(&s2))
+	assert.False(t, s1.Equals(&s3))
+}
+
+func TestHashToBN256Scalar(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		s := HashToBN256Scalar("dusk.test", randomMessage(32))
+		assert.True(t, s.Sign() >= 0)
+		assert.True(t, s.Cmp(bn256.Order) < 0)
+	}
+
+	a := HashToBN256Scalar("dusk.test", []byte("msg"))
+	b := HashToBN256Scalar("dusk.test", []byte("msg"))
+	assert.Equal(t, 0, a.Cmp(b))
+}
+
+func TestHashToPoint(t *testing.T) {
+	p1 := HashToPoint("dusk.test", []byte("msg"))
+	p2 := HashToPoint("dusk.test", []byte("msg"))
+	p3 := HashToPoint("dusk.test", []byte("msg2"))
+	assert.True(t, p1.Equals(&p2))
+	assert.False(t, p1.Equals(&p3))
+
+	var zero [32]byte
+	assert.False(t, bytes.Equal(p1.Bytes(), zero[:]))
+}
+
+func TestHashToG1(t *testing.T) {
+	p1 := HashToG1("dusk.test", []byte("msg"))
+	p2 := HashToG1("dusk.test", []byte("msg"))
+	p3 := HashToG1("dusk.test", []byte("msg2"))
+	assert.Equal(t, p1.Marshal(), p2.Marshal())
+	assert.NotEqual(t, p1.Marshal(), p3.Marshal())
+	assert.NotEqual(t, p1.Marshal(), HashToG1("dusk.other", []byte("msg")).Marshal())
+
+	// the point is not the multiple of the base by the hash of the input
+	k := HashToBN256Scalar("dusk.test", []byte("msg"))
+	assert.NotEqual(t, new(bn256.G1).ScalarBaseMult(k).Marshal(), p1.Marshal())
+}
@@ -1,13 +1,14 @@
 package hash
 
 import (
-	"crypto/rand"
 	"encoding/binary"
 	"errors"
 	"hash"
+	"io"
 	"strconv"
 
 	"github.com/OneOfOne/xxhash"
+	"github.com/dusk-network/dusk-crypto/rng"
 	"golang.org/x/crypto/blake2b"
 	"golang.org/x/crypto/sha3"
 )
@@ -64,7 +65,7 @@ func Xxhash(bs []byte) ([]byte, error) {
 func RandEntropy(n uint32) ([]byte, error) {
 
 	b := make([]byte, n)
-	a, err := rand.Read(b)
+	a, err := io.ReadFull(rng.Reader, b)
 
 	if err != nil {
 		return nil, errors.New("Error generating entropy " + err.Error())
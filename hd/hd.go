@@ -0,0 +1,277 @@
+// Package hd implements hierarchical deterministic derivation of Ristretto
+// keys, in the style of BIP32 and SLIP-0010. A seed, e.g. from a mnemonic,
+// determines a master key, from which a tree of keys is derived along paths
+// such as "m/44'/0'/0/1".
+//
+// Hardened children (index >= 2^31, written with a ') are derived from the
+// parent secret key, like SLIP-0010 does for Ed25519. Non-hardened children
+// add a tweak to the parent key, k_i = k + t and P_i = P + t * G, like BIP32
+// does for secp256k1, which is sound in a prime order group such as
+// Ristretto. They can be derived from an extended public key alone, e.g. by
+// a watch-only wallet, but leaking one non-hardened child secret key along
+// with the parent extended public key reveals the parent secret key
+package hd
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/hash"
+)
+
+// HardenedOffset is the index of the first hardened child
+const HardenedOffset uint32 = 1 << 31
+
+// SerializedSize is the size of a serialized extended key
+const SerializedSize = 78
+
+const (
+	minSeedSize = 16
+	maxSeedSize = 64
+)
+
+// the versions of serialized extended keys
+var (
+	privateVersion = [4]byte{'d', 'h', 'd', 's'}
+	publicVersion  = [4]byte{'d', 'h', 'd', 'p'}
+)
+
+// masterKey is the HMAC key of the master key derivation
+var masterKey = []byte("dusk ristretto seed")
+
+// ExtendedKey is a key of the tree along with the chain code needed to
+// derive its children
+type ExtendedKey struct {
+	Depth             uint8
+	ParentFingerprint [4]byte
+	Index             uint32
+	ChainCode         [32]byte
+
+	// secret is nil for extended public keys
+	secret *ristretto.Scalar
+	public ristretto.Point
+}
+
+// NewMaster derives the master key of a seed
+func NewMaster(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < minSeedSize || len(seed) > maxSeedSize {
+		return nil, errors.New("hd: seed must be 16 to 64 bytes")
+	}
+
+	mac := hmac.New(sha512.New, masterKey)
+	_, _ = mac.Write(seed)
+	return newPrivate(mac.Sum(nil), 0, [4]byte{}, 0, nil), nil
+}
+
+// newPrivate builds an extended private key from an HMAC output I, whose
+// left half is reduced to a scalar and added to tweak, if any
+func newPrivate(I []byte, depth uint8, parent [4]byte, index uint32, tweak *ristretto.Scalar) *ExtendedKey {
+	k := toScalar(I[:32])
+	if tweak != nil {
+		k.Add(&k, tweak)
+	}
+
+	key := &ExtendedKey{Depth: depth, ParentFingerprint: parent, Index: index, secret: &k}
+	copy(key.ChainCode[:], I[32:])
+	key.public.ScalarMultBase(&k)
+	return key
+}
+
+// toScalar maps 32 bytes to a uniform scalar
+func toScalar(b []byte) ristretto.Scalar {
+	return hash.HashToScalar("dusk.hd.scalar", b)
+}
+
+// IsPrivate returns whether the key holds a secret key
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.secret != nil
+}
+
+// SecretKey returns the secret key of an extended private key
+func (k *ExtendedKey) SecretKey() (ristretto.Scalar, error) {
+	if k.secret == nil {
+		return ristretto.Scalar{}, errors.New("hd: public key has no secret")
+	}
+	return *k.secret, nil
+}
+
+// PublicKey returns the public key
+func (k *ExtendedKey) PublicKey() ristretto.Point {
+	return k.public
+}
+
+// Fingerprint identifies the key, and its children refer to it with it
+func (k *ExtendedKey) Fingerprint() [4]byte {
+	var fp [4]byte
+	copy(fp[:], hash.Blake2b256WithDomain("dusk.hd.fingerprint", k.public.Bytes()))
+	return fp
+}
+
+// Neuter returns the extended public key
+func (k *ExtendedKey) Neuter() *ExtendedKey {
+	pub := *k
+	pub.secret = nil
+	return &pub
+}
+
+// Child derives the child with the given index
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if k.Depth == 255 {
+		return nil, errors.New("hd: maximum depth reached")
+	}
+
+	var ib [4]byte
+	binary.BigEndian.PutUint32(ib[:], index)
+	mac := hmac.New(sha512.New, k.ChainCode[:])
+
+	if index >= HardenedOffset {
+		if k.secret == nil {
+			return nil, errors.New("hd: hardened child of a public key")
+		}
+		_, _ = mac.Write([]byte{0})
+		_, _ = mac.Write(k.secret.Bytes())
+		_, _ = mac.Write(ib[:])
+		return newPrivate(mac.Sum(nil), k.Depth+1, k.Fingerprint(), index, nil), nil
+	}
+
+	_, _ = mac.Write([]byte{1})
+	_, _ = mac.Write(k.public.Bytes())
+	_, _ = mac.Write(ib[:])
+	I := mac.Sum(nil)
+
+	if k.secret != nil {
+		return newPrivate(I, k.Depth+1, k.Fingerprint(), index, k.secret), nil
+	}
+
+	t := toScalar(I[:32])
+	child := &ExtendedKey{Depth: k.Depth + 1, ParentFingerprint: k.Fingerprint(), Index: index}
+	copy(child.ChainCode[:], I[32:])
+	var tG ristretto.Point
+	tG.ScalarMultBase(&t)
+	child.public.Add(&k.public, &tG)
+	return child, nil
+}
+
+// Derive derives the key at the given path, relative to k. The path starts
+// with "m" if k is a master key
+func (k *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	indices, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(path, "m") && k.Depth != 0 {
+		return nil, errors.New("hd: absolute path from a child key")
+	}
+
+	key := k
+	for _, i := range indices {
+		if key, err = key.Child(i); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// ParsePath parses a derivation path such as "m/44'/0'/0/1" into indices.
+// Hardened indices are marked with ', h or H. The leading "m" is optional
+func ParsePath(path string) ([]uint32, error) {
+	if path == "m" || path == "" {
+		return nil, nil
+	}
+	parts := strings.Split(path, "/")
+	if parts[0] == "m" {
+		parts = parts[1:]
+	}
+	if len(parts) > 255 {
+		return nil, errors.New("hd: path too long")
+	}
+
+	indices := make([]uint32, len(parts))
+	for i, p := range parts {
+		hardened := false
+		if strings.HasSuffix(p, "'") || strings.HasSuffix(p, "h") || strings.HasSuffix(p, "H") {
+			hardened = true
+			p = p[:len(p)-1]
+		}
+		// digits only, without sign or leading zeros
+		if p == "" || p[0] == '+' || p[0] == '-' || (len(p) > 1 && p[0] == '0') {
+			return nil, errors.New("hd: invalid path component")
+		}
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil || uint32(n) >= HardenedOffset {
+			return nil, errors.New("hd: invalid path component")
+		}
+		indices[i] = uint32(n)
+		if hardened {
+			indices[i] += HardenedOffset
+		}
+	}
+	return indices, nil
+}
+
+// Marshal serializes the extended key:
+// version (4) || depth (1) || parent fingerprint (4) || index (4) ||
+// chain code (32) || 0x00 || secret key, or 0x01 || public key (33)
+func (k *ExtendedKey) Marshal() []byte {
+	buf := make([]byte, 0, SerializedSize)
+	if k.secret != nil {
+		buf = append(buf, privateVersion[:]...)
+	} else {
+		buf = append(buf, publicVersion[:]...)
+	}
+	buf = append(buf, k.Depth)
+	buf = append(buf, k.ParentFingerprint[:]...)
+
+	var ib [4]byte
+	binary.BigEndian.PutUint32(ib[:], k.Index)
+	buf = append(buf, ib[:]...)
+	buf = append(buf, k.ChainCode[:]...)
+
+	if k.secret != nil {
+		buf = append(buf, 0)
+		return append(buf, k.secret.Bytes()...)
+	}
+	buf = append(buf, 1)
+	return append(buf, k.public.Bytes()...)
+}
+
+// Unmarshal parses a serialized extended key
+func Unmarshal(b []byte) (*ExtendedKey, error) {
+	if len(b) != SerializedSize {
+		return nil, errors.New("hd: invalid serialized key size")
+	}
+
+	k := &ExtendedKey{Depth: b[4]}
+	copy(k.ParentFingerprint[:], b[5:9])
+	k.Index = binary.BigEndian.Uint32(b[9:13])
+	copy(k.ChainCode[:], b[13:45])
+	if k.Depth == 0 && (k.Index != 0 || k.ParentFingerprint != [4]byte{}) {
+		return nil, errors.New("hd: invalid master key")
+	}
+
+	var data [32]byte
+	copy(data[:], b[46:])
+	switch {
+	case bytes.Equal(b[:4], privateVersion[:]) && b[45] == 0:
+		var s ristretto.Scalar
+		s.SetBytes(&data)
+		if !bytes.Equal(s.Bytes(), data[:]) {
+			return nil, errors.New("hd: non canonical secret key")
+		}
+		k.secret = &s
+		k.public.ScalarMultBase(&s)
+	case bytes.Equal(b[:4], publicVersion[:]) && b[45] == 1:
+		if !k.public.SetBytes(&data) {
+			return nil, errors.New("hd: invalid public key")
+		}
+	default:
+		return nil, errors.New("hd: invalid version")
+	}
+	return k, nil
+}
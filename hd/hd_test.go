@@ -0,0 +1,137 @@
+package hd
+
+import (
+	"bytes"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var seed = bytes.Repeat([]byte{0x42}, 32)
+
+func TestDerivation(t *testing.T) {
+	master, err := NewMaster(seed)
+	require.Nil(t, err)
+	again, err := NewMaster(seed)
+	require.Nil(t, err)
+	assert.Equal(t, master.Marshal(), again.Marshal())
+
+	key, err := master.Derive("m/44'/0'/0/1")
+	require.Nil(t, err)
+	assert.Equal(t, uint8(4), key.Depth)
+	assert.Equal(t, uint32(1), key.Index)
+	assert.True(t, key.IsPrivate())
+
+	// the public key matches the secret key
+	sk, err := key.SecretKey()
+	require.Nil(t, err)
+	var pk ristretto.Point
+	pk.ScalarMultBase(&sk)
+	expected := key.PublicKey()
+	assert.True(t, pk.Equals(&expected))
+
+	// step by step derivation gives the same key
+	step := master
+	for _, i := range []uint32{44 + HardenedOffset, HardenedOffset, 0, 1} {
+		step, err = step.Child(i)
+		require.Nil(t, err)
+	}
+	assert.Equal(t, key.Marshal(), step.Marshal())
+
+	account, err := master.Derive("m/44'/0'")
+	require.Nil(t, err)
+	assert.Equal(t, account.Fingerprint(), mustDerive(t, account, "0").ParentFingerprint)
+
+	// different seeds and paths give different keys
+	other, err := NewMaster(bytes.Repeat([]byte{0x43}, 32))
+	require.Nil(t, err)
+	assert.NotEqual(t, master.Marshal(), other.Marshal())
+	assert.NotEqual(t, mustDerive(t, master, "m/0").Marshal(), mustDerive(t, master, "m/0'").Marshal())
+
+	_, err = NewMaster(seed[:15])
+	assert.NotNil(t, err)
+	_, err = key.Derive("m/0")
+	assert.NotNil(t, err)
+}
+
+func mustDerive(t *testing.T, k *ExtendedKey, path string) *ExtendedKey {
+	child, err := k.Derive(path)
+	require.Nil(t, err)
+	return child
+}
+
+func TestPublicDerivation(t *testing.T) {
+	master, err := NewMaster(seed)
+	require.Nil(t, err)
+	account := mustDerive(t, master, "m/44'/1'")
+	watch := account.Neuter()
+	assert.False(t, watch.IsPrivate())
+	_, err = watch.SecretKey()
+	assert.NotNil(t, err)
+
+	// non-hardened children of the public key match the private ones
+	priv := mustDerive(t, account, "0/7")
+	pub := mustDerive(t, watch, "0/7")
+	assert.False(t, pub.IsPrivate())
+	assert.Equal(t, priv.Neuter().Marshal(), pub.Marshal())
+
+	_, err = watch.Child(HardenedOffset)
+	assert.NotNil(t, err)
+}
+
+func TestParsePath(t *testing.T) {
+	indices, err := ParsePath("m/44'/0h/1H/2")
+	require.Nil(t, err)
+	assert.Equal(t, []uint32{44 + HardenedOffset, HardenedOffset, 1 + HardenedOffset, 2}, indices)
+
+	indices, err = ParsePath("m")
+	require.Nil(t, err)
+	assert.Empty(t, indices)
+
+	indices, err = ParsePath("3/4")
+	require.Nil(t, err)
+	assert.Equal(t, []uint32{3, 4}, indices)
+
+	for _, p := range []string{"m/", "m//1", "m/-1", "m/+1", "m/01", "m/2147483648", "m/1''", "m/x", "n/1"} {
+		_, err := ParsePath(p)
+		assert.NotNil(t, err, p)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	master, err := NewMaster(seed)
+	require.Nil(t, err)
+	key := mustDerive(t, master, "m/1'/2")
+
+	for _, k := range []*ExtendedKey{master, key, key.Neuter()} {
+		b := k.Marshal()
+		assert.Len(t, b, SerializedSize)
+		decoded, err := Unmarshal(b)
+		require.Nil(t, err)
+		assert.Equal(t, b, decoded.Marshal())
+		assert.Equal(t, k.IsPrivate(), decoded.IsPrivate())
+	}
+
+	b := key.Marshal()
+	_, err = Unmarshal(b[:SerializedSize-1])
+	assert.NotNil(t, err)
+
+	wrongVersion := append([]byte{}, b...)
+	wrongVersion[3] = 'p'
+	_, err = Unmarshal(wrongVersion)
+	assert.NotNil(t, err)
+
+	nonCanonical := append([]byte{}, b...)
+	for i := 46; i < SerializedSize; i++ {
+		nonCanonical[i] = 0xff
+	}
+	_, err = Unmarshal(nonCanonical)
+	assert.NotNil(t, err)
+
+	badMaster := master.Marshal()
+	badMaster[12] = 1
+	_, err = Unmarshal(badMaster)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,184 @@
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// BackupVersion is the version of the backup format
+const BackupVersion = 1
+
+// backupType tells backups from keystore files, which have the same header
+const backupType = "dusk-identity-backup"
+
+type backupHeader struct {
+	Type string `json:"type"`
+	header
+}
+
+type backupFile struct {
+	backupHeader
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type backupPayload struct {
+	Created  time.Time         `json:"created"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Entries  []*Entry          `json:"entries"`
+}
+
+// Backup is a decrypted backup: the keys making up the identity of a node,
+// its BLS consensus keys and its Ristretto wallet keys, with their metadata
+// and the metadata of the node
+type Backup struct {
+	// Version of the format of the backup
+	Version  int
+	Created  time.Time
+	Metadata map[string]string
+	entries  map[string]*Entry
+}
+
+// Export returns a single encrypted bundle holding the named keys, or every
+// key if names is empty, along with metadata about the node, e.g. its name
+// and network. The bundle is encrypted under its own passphrase and salt, so
+// it can be stored apart from the keystore and restored anywhere with
+// OpenBackup
+func (ks *Keystore) Export(passphrase []byte, params Params, metadata map[string]string, names ...string) ([]byte, error) {
+	if len(names) == 0 {
+		names = ks.Names()
+	}
+	payload := backupPayload{
+		Created:  time.Now().UTC(),
+		Metadata: copyMetadata(metadata),
+		Entries:  make([]*Entry, 0, len(names)),
+	}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		e, ok := ks.entries[name]
+		if !ok {
+			return nil, ErrNotFound
+		}
+		if !seen[name] {
+			seen[name] = true
+			payload.Entries = append(payload.Entries, e)
+		}
+	}
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := newKDF(params)
+	if err != nil {
+		return nil, err
+	}
+	h := backupHeader{
+		Type:   backupType,
+		header: header{Version: BackupVersion, KDF: k, Cipher: cipherName},
+	}
+	ct, err := seal(k.derive(passphrase), h, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(backupFile{backupHeader: h, Ciphertext: ct}, "", "  ")
+}
+
+// OpenBackup decrypts a bundle made by Export. Every key in it is checked
+func OpenBackup(data, passphrase []byte) (*Backup, error) {
+	var f backupFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Type != backupType {
+		return nil, errors.New("keystore: not a backup")
+	}
+	if f.Version < 1 || f.Version > BackupVersion {
+		return nil, errors.New("keystore: unsupported backup version")
+	}
+	if f.KDF.Name != kdfName || f.Cipher != cipherName {
+		return nil, errors.New("keystore: unsupported file format")
+	}
+	if err := f.KDF.check(); err != nil {
+		return nil, err
+	}
+
+	plaintext, err := open(f.KDF.derive(passphrase), f.backupHeader, f.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	var payload backupPayload
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return nil, err
+	}
+	entries, err := entryMap(payload.Entries)
+	if err != nil {
+		return nil, err
+	}
+	return &Backup{
+		Version:  f.Version,
+		Created:  payload.Created,
+		Metadata: payload.Metadata,
+		entries:  entries,
+	}, nil
+}
+
+// Names returns the names of the keys in the backup, sorted
+func (b *Backup) Names() []string {
+	return sortedNames(b.entries)
+}
+
+// Entry returns a copy of the entry of a key in the backup
+func (b *Backup) Entry(name string) (*Entry, error) {
+	e, ok := b.entries[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyEntry(e), nil
+}
+
+// RestoreOptions select the keys Restore restores. The zero value restores
+// every key of the backup, and fails if any of them exists
+type RestoreOptions struct {
+	// Names of the keys to restore, every key if empty
+	Names []string
+	// Types of the keys to restore, every type if empty
+	Types []KeyType
+	// Overwrite replaces the keys of the keystore with the same names
+	Overwrite bool
+}
+
+// Restore adds keys of a backup to the keystore, keeping their creation
+// times and metadata. Either all the selected keys are restored, or none is.
+// It returns the names of the restored keys, sorted. The file is only
+// updated by Save
+func (ks *Keystore) Restore(b *Backup, opts RestoreOptions) ([]string, error) {
+	names := opts.Names
+	if len(names) == 0 {
+		names = b.Names()
+	}
+	types := make(map[KeyType]bool, len(opts.Types))
+	for _, t := range opts.Types {
+		types[t] = true
+	}
+
+	selected := make(map[string]*Entry, len(names))
+	for _, name := range names {
+		e, ok := b.entries[name]
+		if !ok {
+			return nil, ErrNotFound
+		}
+		if len(types) > 0 && !types[e.Type] {
+			continue
+		}
+		if _, ok := ks.entries[name]; ok && !opts.Overwrite {
+			return nil, ErrExists
+		}
+		selected[name] = e
+	}
+
+	for name, e := range selected {
+		ks.entries[name] = copyEntry(e)
+	}
+	return sortedNames(selected), nil
+}
@@ -0,0 +1,142 @@
+package keystore
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/schnorr"
+	"github.com/dusk-network/dusk-crypto/stealth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// identity returns a keystore holding the keys of a node
+func identity(t *testing.T, dir string) *Keystore {
+	ks, err := Create(filepath.Join(dir, "keys.json"), []byte("passphrase"), testParams)
+	require.Nil(t, err)
+	_, blsSk, err := bls.GenKeyPair(nil)
+	require.Nil(t, err)
+	schnorrSk, _ := schnorr.GenerateKey()
+	require.Nil(t, ks.AddBLS("consensus", blsSk, map[string]string{"role": "provisioner"}))
+	require.Nil(t, ks.AddSchnorr("node", schnorrSk, nil))
+	require.Nil(t, ks.AddStealth("wallet", stealth.GenerateKey(), nil))
+	return ks
+}
+
+func TestBackup(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	ks := identity(t, dir)
+
+	data, err := ks.Export([]byte("backup passphrase"), testParams, map[string]string{"network": "testnet"})
+	require.Nil(t, err)
+
+	_, err = OpenBackup(data, []byte("passphrase"))
+	assert.Equal(t, ErrPassphrase, err)
+	b, err := OpenBackup(data, []byte("backup passphrase"))
+	require.Nil(t, err)
+	assert.Equal(t, BackupVersion, b.Version)
+	assert.Equal(t, "testnet", b.Metadata["network"])
+	assert.Equal(t, ks.Names(), b.Names())
+
+	restored, err := Create(filepath.Join(dir, "restored.json"), []byte("other"), testParams)
+	require.Nil(t, err)
+	names, err := restored.Restore(b, RestoreOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, ks.Names(), names)
+	for _, name := range names {
+		want, err := ks.Entry(name)
+		require.Nil(t, err)
+		got, err := restored.Entry(name)
+		require.Nil(t, err)
+		assert.Equal(t, want.Secret, got.Secret)
+		assert.Equal(t, want.Metadata, got.Metadata)
+		assert.True(t, want.Created.Equal(got.Created))
+	}
+
+	// restoring twice fails without changing anything
+	_, err = restored.Restore(b, RestoreOptions{})
+	assert.Equal(t, ErrExists, err)
+	names, err = restored.Restore(b, RestoreOptions{Overwrite: true})
+	require.Nil(t, err)
+	assert.Equal(t, 3, len(names))
+}
+
+func TestPartialBackup(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	ks := identity(t, dir)
+
+	data, err := ks.Export([]byte("pp"), testParams, nil, "consensus", "node", "node")
+	require.Nil(t, err)
+	b, err := OpenBackup(data, []byte("pp"))
+	require.Nil(t, err)
+	assert.Equal(t, []string{"consensus", "node"}, b.Names())
+	_, err = b.Entry("wallet")
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = ks.Export([]byte("pp"), testParams, nil, "missing")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = ks.Export([]byte("pp"), Params{}, nil)
+	assert.NotNil(t, err)
+
+	// restore the consensus keys only, by type or by name
+	restored, err := Create(filepath.Join(dir, "restored.json"), []byte("other"), testParams)
+	require.Nil(t, err)
+	names, err := restored.Restore(b, RestoreOptions{Types: []KeyType{BLS}})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"consensus"}, names)
+	assert.Equal(t, []string{"consensus"}, restored.Names())
+
+	names, err = restored.Restore(b, RestoreOptions{Names: []string{"node"}})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"node"}, names)
+	_, err = restored.Restore(b, RestoreOptions{Names: []string{"wallet"}})
+	assert.Equal(t, ErrNotFound, err)
+
+	require.Nil(t, restored.Save())
+	opened, err := Open(filepath.Join(dir, "restored.json"), []byte("other"))
+	require.Nil(t, err)
+	assert.Equal(t, []string{"consensus", "node"}, opened.Names())
+}
+
+func TestBackupFormat(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	ks := identity(t, dir)
+	data, err := ks.Export([]byte("pp"), testParams, nil)
+	require.Nil(t, err)
+
+	var f map[string]interface{}
+	require.Nil(t, json.Unmarshal(data, &f))
+
+	tamper := func(key string, value interface{}) []byte {
+		cpy := make(map[string]interface{}, len(f))
+		for k, v := range f {
+			cpy[k] = v
+		}
+		cpy[key] = value
+		b, err := json.Marshal(cpy)
+		require.Nil(t, err)
+		return b
+	}
+
+	// newer versions are refused, and the header is authenticated
+	_, err = OpenBackup(tamper("version", BackupVersion+1), []byte("pp"))
+	assert.NotNil(t, err)
+	_, err = OpenBackup(tamper("type", "other"), []byte("pp"))
+	assert.NotNil(t, err)
+	_, err = OpenBackup(tamper("cipher", "aes"), []byte("pp"))
+	assert.NotNil(t, err)
+
+	// a keystore file is not a backup
+	require.Nil(t, ks.Save())
+	b, err := ioutil.ReadFile(filepath.Join(dir, "keys.json"))
+	require.Nil(t, err)
+	_, err = OpenBackup(b, []byte("passphrase"))
+	assert.NotNil(t, err)
+}
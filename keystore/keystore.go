@@ -0,0 +1,522 @@
+// Package keystore stores BLS, Schnorr and stealth keys in a single file
+// encrypted with a passphrase. The passphrase is stretched with Argon2id and
+// the keys, along with their metadata, are sealed with a key committing
+// AEAD. The file is rewritten atomically, so that a crash never leaves a
+// truncated keystore behind.
+//
+// Export bundles the keys of a node into a single encrypted backup, which
+// Restore adds back to a keystore, in part or in full
+package keystore
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/cipher"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/stealth"
+	"golang.org/x/crypto/argon2"
+)
+
+// Version is the version of the file format
+const Version = 1
+
+const (
+	kdfName    = "argon2id"
+	cipherName = "chacha20poly1305"
+	saltSize   = 16
+)
+
+// KeyType is the type of a stored key
+type KeyType string
+
+const (
+	// BLS keys are bls.SecretKey, stored as 32 bytes
+	BLS KeyType = "bls"
+	// Schnorr keys are Ristretto scalars, stored as 32 bytes
+	Schnorr KeyType = "schnorr"
+	// Stealth keys are stealth.SecretKey, stored as the view key followed by
+	// the spend key
+	Stealth KeyType = "stealth"
+)
+
+var (
+	// ErrNotFound is returned for unknown key names
+	ErrNotFound = errors.New("keystore: key not found")
+	// ErrExists is returned when adding a key under a name already in use
+	ErrExists = errors.New("keystore: key already exists")
+	// ErrPassphrase is returned when the file does not decrypt
+	ErrPassphrase = errors.New("keystore: wrong passphrase or corrupted file")
+)
+
+// Params are the Argon2id parameters
+type Params struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+// DefaultParams are the Argon2id parameters recommended by RFC 9106 for
+// memory constrained environments: 64 MiB, 3 passes
+var DefaultParams = Params{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// MaxParams bound the Argon2id parameters, so that a tampered file cannot
+// make opening it exhaust memory or run for hours before the passphrase is
+// even checked: 1 GiB, 48 passes
+var MaxParams = Params{Time: 16 * 3, Memory: 16 * 64 * 1024, Threads: 16}
+
+// Entry is a stored key
+type Entry struct {
+	Name     string            `json:"name"`
+	Type     KeyType           `json:"type"`
+	Created  time.Time         `json:"created"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Secret   []byte            `json:"secret"`
+}
+
+type kdf struct {
+	Name string `json:"name"`
+	Salt []byte `json:"salt"`
+	Params
+}
+
+// header is authenticated as the associated data of the ciphertext
+type header struct {
+	Version int    `json:"version"`
+	KDF     kdf    `json:"kdf"`
+	Cipher  string `json:"cipher"`
+}
+
+type file struct {
+	header
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Keystore is an open keystore. Changes are only written by Save
+type Keystore struct {
+	path    string
+	header  header
+	key     []byte
+	entries map[string]*Entry
+}
+
+// Create returns a new empty keystore, which is written to path by Save.
+// It fails if the file exists
+func Create(path string, passphrase []byte, params Params) (*Keystore, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, errors.New("keystore: file already exists")
+	}
+	k, err := newKDF(params)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &Keystore{
+		path: path,
+		header: header{
+			Version: Version,
+			KDF:     k,
+			Cipher:  cipherName,
+		},
+		entries: make(map[string]*Entry),
+	}
+	ks.key = ks.header.KDF.derive(passphrase)
+	return ks, nil
+}
+
+// Open decrypts the keystore at path
+func Open(path string, passphrase []byte) (*Keystore, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Version != Version || f.KDF.Name != kdfName || f.Cipher != cipherName {
+		return nil, errors.New("keystore: unsupported file format")
+	}
+	if err := f.KDF.check(); err != nil {
+		return nil, err
+	}
+
+	ks := &Keystore{path: path, header: f.header}
+	ks.key = ks.header.KDF.derive(passphrase)
+
+	plaintext, err := open(ks.key, ks.header, f.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	if err := json.Unmarshal(plaintext, &entries); err != nil {
+		return nil, err
+	}
+	if ks.entries, err = entryMap(entries); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// newKDF returns the parameters of the derivation of a new key, with a fresh
+// salt
+func newKDF(params Params) (kdf, error) {
+	if err := params.check(); err != nil {
+		return kdf{}, err
+	}
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rng.Reader, salt); err != nil {
+		return kdf{}, err
+	}
+	return kdf{Name: kdfName, Salt: salt, Params: params}, nil
+}
+
+// check validates the parameters of a decoded file
+func (k *kdf) check() error {
+	if len(k.Salt) != saltSize {
+		return errors.New("keystore: invalid Argon2id parameters")
+	}
+	return k.Params.check()
+}
+
+// check makes sure the parameters are within the bounds Argon2id requires
+// and within MaxParams
+func (p Params) check() error {
+	if p.Time < 1 || p.Threads < 1 || p.Memory < 8*uint32(p.Threads) {
+		return errors.New("keystore: invalid Argon2id parameters")
+	}
+	if p.Time > MaxParams.Time || p.Memory > MaxParams.Memory || p.Threads > MaxParams.Threads {
+		return errors.New("keystore: Argon2id parameters exceed MaxParams")
+	}
+	return nil
+}
+
+func (k *kdf) derive(passphrase []byte) []byte {
+	return argon2.IDKey(passphrase, k.Salt, k.Time, k.Memory, k.Threads, cipher.KeySize)
+}
+
+// seal encrypts plaintext, authenticating the JSON encoding of header
+func seal(key []byte, header interface{}, plaintext []byte) ([]byte, error) {
+	aead, err := cipher.New(cipher.ChaCha20Poly1305, key)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	return aead.SealRandom(plaintext, ad)
+}
+
+// open decrypts what seal encrypted
+func open(key []byte, header interface{}, ciphertext []byte) ([]byte, error) {
+	aead, err := cipher.New(cipher.ChaCha20Poly1305, key)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.OpenRandom(ciphertext, ad)
+	if err != nil {
+		return nil, ErrPassphrase
+	}
+	return plaintext, nil
+}
+
+// entryMap checks decoded entries and indexes them by name
+func entryMap(entries []*Entry) (map[string]*Entry, error) {
+	res := make(map[string]*Entry, len(entries))
+	for _, e := range entries {
+		if err := checkSecret(e.Type, e.Secret); err != nil {
+			return nil, err
+		}
+		if _, ok := res[e.Name]; ok {
+			return nil, errors.New("keystore: corrupted file, duplicate key name")
+		}
+		res[e.Name] = e
+	}
+	return res, nil
+}
+
+// Save encrypts the keystore and atomically replaces the file: the new
+// content is written and synced to a temporary file in the same directory,
+// which is then renamed over the old one
+func (ks *Keystore) Save() error {
+	entries := make([]*Entry, 0, len(ks.entries))
+	for _, name := range ks.Names() {
+		entries = append(entries, ks.entries[name])
+	}
+	plaintext, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	ct, err := seal(ks.key, ks.header, plaintext)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(file{header: ks.header, Ciphertext: ct}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeAtomic(ks.path, data)
+}
+
+func writeAtomic(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".keystore-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// no-op once renamed
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ChangePassphrase re-keys the keystore with a new passphrase and salt. The
+// file is only updated by Save
+func (ks *Keystore) ChangePassphrase(passphrase []byte) error {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rng.Reader, salt); err != nil {
+		return err
+	}
+	ks.header.KDF.Salt = salt
+	ks.key = ks.header.KDF.derive(passphrase)
+	return nil
+}
+
+// Names returns the names of the stored keys, sorted
+func (ks *Keystore) Names() []string {
+	return sortedNames(ks.entries)
+}
+
+func sortedNames(entries map[string]*Entry) []string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Entry returns a copy of the entry of a key
+func (ks *Keystore) Entry(name string) (*Entry, error) {
+	e, ok := ks.entries[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyEntry(e), nil
+}
+
+func copyEntry(e *Entry) *Entry {
+	cpy := *e
+	cpy.Secret = append([]byte{}, e.Secret...)
+	cpy.Metadata = make(map[string]string, len(e.Metadata))
+	for k, v := range e.Metadata {
+		cpy.Metadata[k] = v
+	}
+	return &cpy
+}
+
+// SetMetadata replaces the metadata of a key
+func (ks *Keystore) SetMetadata(name string, metadata map[string]string) error {
+	e, ok := ks.entries[name]
+	if !ok {
+		return ErrNotFound
+	}
+	e.Metadata = copyMetadata(metadata)
+	return nil
+}
+
+// Remove deletes a key
+func (ks *Keystore) Remove(name string) error {
+	if _, ok := ks.entries[name]; !ok {
+		return ErrNotFound
+	}
+	delete(ks.entries, name)
+	return nil
+}
+
+// Add stores a key in its raw form, which is checked against the type
+func (ks *Keystore) Add(name string, t KeyType, secret []byte, metadata map[string]string) error {
+	if name == "" {
+		return errors.New("keystore: empty key name")
+	}
+	if _, ok := ks.entries[name]; ok {
+		return ErrExists
+	}
+	if err := checkSecret(t, secret); err != nil {
+		return err
+	}
+
+	ks.entries[name] = &Entry{
+		Name:     name,
+		Type:     t,
+		Created:  time.Now().UTC(),
+		Metadata: copyMetadata(metadata),
+		Secret:   append([]byte{}, secret...),
+	}
+	return nil
+}
+
+func copyMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	cpy := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		cpy[k] = v
+	}
+	return cpy
+}
+
+// secret returns the raw key stored under name, which must have type t
+func (ks *Keystore) secret(name string, t KeyType) ([]byte, error) {
+	e, ok := ks.entries[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if e.Type != t {
+		return nil, errors.New("keystore: key has another type")
+	}
+	return e.Secret, nil
+}
+
+// AddBLS stores a BLS secret key
+func (ks *Keystore) AddBLS(name string, sk *bls.SecretKey, metadata map[string]string) error {
+	return ks.Add(name, BLS, sk.Marshal(), metadata)
+}
+
+// BLS returns a BLS secret key
+func (ks *Keystore) BLS(name string) (*bls.SecretKey, error) {
+	b, err := ks.secret(name, BLS)
+	if err != nil {
+		return nil, err
+	}
+	return bls.UnmarshalSk(b)
+}
+
+// AddSchnorr stores a Ristretto secret key
+func (ks *Keystore) AddSchnorr(name string, sk ristretto.Scalar, metadata map[string]string) error {
+	return ks.Add(name, Schnorr, sk.Bytes(), metadata)
+}
+
+// Schnorr returns a Ristretto secret key
+func (ks *Keystore) Schnorr(name string) (ristretto.Scalar, error) {
+	b, err := ks.secret(name, Schnorr)
+	if err != nil {
+		return ristretto.Scalar{}, err
+	}
+	return toScalar(b)
+}
+
+// AddStealth stores stealth view and spend keys
+func (ks *Keystore) AddStealth(name string, sk *stealth.SecretKey, metadata map[string]string) error {
+	return ks.Add(name, Stealth, append(sk.View.Bytes(), sk.Spend.Bytes()...), metadata)
+}
+
+// Stealth returns stealth view and spend keys
+func (ks *Keystore) Stealth(name string) (*stealth.SecretKey, error) {
+	b, err := ks.secret(name, Stealth)
+	if err != nil {
+		return nil, err
+	}
+
+	sk := &stealth.SecretKey{}
+	if sk.View, err = toScalar(b[:32]); err != nil {
+		return nil, err
+	}
+	if sk.Spend, err = toScalar(b[32:]); err != nil {
+		return nil, err
+	}
+	return sk, nil
+}
+
+// ImportFile migrates a plain key file, holding a key in its raw form
+// either as binary or as hex text. The plain file is left in place, for the
+// caller to delete once the keystore is saved
+func (ks *Keystore) ImportFile(path, name string, t KeyType, metadata map[string]string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	secret := data
+	if checkSecret(t, data) != nil {
+		decoded, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+		if err != nil {
+			return errors.New("keystore: unrecognized key file")
+		}
+		secret = decoded
+	}
+	return ks.Add(name, t, secret, metadata)
+}
+
+func checkSecret(t KeyType, secret []byte) error {
+	switch t {
+	case BLS:
+		_, err := bls.UnmarshalSk(secret)
+		return err
+	case Schnorr:
+		_, err := toScalar(secret)
+		return err
+	case Stealth:
+		if len(secret) != 64 {
+			return errors.New("keystore: invalid stealth key size")
+		}
+		if _, err := toScalar(secret[:32]); err != nil {
+			return err
+		}
+		_, err := toScalar(secret[32:])
+		return err
+	default:
+		return errors.New("keystore: unknown key type")
+	}
+}
+
+// toScalar decodes a canonical scalar
+func toScalar(b []byte) (ristretto.Scalar, error) {
+	var s ristretto.Scalar
+	if len(b) != 32 {
+		return s, errors.New("keystore: invalid scalar size")
+	}
+
+	var buf [32]byte
+	copy(buf[:], b)
+	s.SetBytes(&buf)
+	if !bytes.Equal(s.Bytes(), b) {
+		return s, errors.New("keystore: non canonical scalar")
+	}
+	return s, nil
+}
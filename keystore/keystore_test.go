@@ -0,0 +1,189 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/schnorr"
+	"github.com/dusk-network/dusk-crypto/stealth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testParams keep the tests fast
+var testParams = Params{Time: 1, Memory: 64, Threads: 1}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "keystore")
+	require.Nil(t, err)
+	return dir
+}
+
+func TestKeystore(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keys.json")
+
+	ks, err := Create(path, []byte("passphrase"), testParams)
+	require.Nil(t, err)
+
+	blsPk, blsSk, err := bls.GenKeyPair(nil)
+	require.Nil(t, err)
+	schnorrSk, _ := schnorr.GenerateKey()
+	stealthSk := stealth.GenerateKey()
+
+	require.Nil(t, ks.AddBLS("consensus", blsSk, map[string]string{"role": "provisioner"}))
+	require.Nil(t, ks.AddSchnorr("node", schnorrSk, nil))
+	require.Nil(t, ks.AddStealth("wallet", stealthSk, nil))
+	assert.Equal(t, ErrExists, ks.AddSchnorr("node", schnorrSk, nil))
+	require.Nil(t, ks.Save())
+
+	info, err := os.Stat(path)
+	require.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	opened, err := Open(path, []byte("passphrase"))
+	require.Nil(t, err)
+	assert.Equal(t, []string{"consensus", "node", "wallet"}, opened.Names())
+
+	gotBLS, err := opened.BLS("consensus")
+	require.Nil(t, err)
+	assert.Equal(t, blsPk.Marshal(), gotBLS.PublicKey().Marshal())
+
+	gotSchnorr, err := opened.Schnorr("node")
+	require.Nil(t, err)
+	assert.True(t, schnorrSk.Equals(&gotSchnorr))
+
+	gotStealth, err := opened.Stealth("wallet")
+	require.Nil(t, err)
+	assert.True(t, stealthSk.View.Equals(&gotStealth.View))
+	assert.True(t, stealthSk.Spend.Equals(&gotStealth.Spend))
+
+	e, err := opened.Entry("consensus")
+	require.Nil(t, err)
+	assert.Equal(t, BLS, e.Type)
+	assert.Equal(t, "provisioner", e.Metadata["role"])
+	assert.False(t, e.Created.IsZero())
+
+	_, err = opened.Schnorr("consensus")
+	assert.NotNil(t, err)
+	_, err = opened.BLS("missing")
+	assert.Equal(t, ErrNotFound, err)
+
+	// the file cannot be created twice
+	_, err = Create(path, []byte("passphrase"), testParams)
+	assert.NotNil(t, err)
+}
+
+func TestWrongPassphrase(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keys.json")
+
+	ks, err := Create(path, []byte("old"), testParams)
+	require.Nil(t, err)
+	sk, _ := schnorr.GenerateKey()
+	require.Nil(t, ks.AddSchnorr("node", sk, nil))
+	require.Nil(t, ks.Save())
+
+	_, err = Open(path, []byte("wrong"))
+	assert.Equal(t, ErrPassphrase, err)
+
+	require.Nil(t, ks.ChangePassphrase([]byte("new")))
+	require.Nil(t, ks.Save())
+	_, err = Open(path, []byte("old"))
+	assert.Equal(t, ErrPassphrase, err)
+	opened, err := Open(path, []byte("new"))
+	require.Nil(t, err)
+	require.Nil(t, opened.Remove("node"))
+	assert.Empty(t, opened.Names())
+
+	// no temporary file is left behind
+	files, err := ioutil.ReadDir(dir)
+	require.Nil(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestTamperedHeader(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keys.json")
+
+	ks, err := Create(path, []byte("passphrase"), testParams)
+	require.Nil(t, err)
+	require.Nil(t, ks.Save())
+
+	// lowering the Argon2id cost is detected, even with the right passphrase
+	data, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+	require.Nil(t, ioutil.WriteFile(path, []byte(strings.Replace(string(data), `"time": 1`, `"time": 2`, 1)), 0600))
+	_, err = Open(path, []byte("passphrase"))
+	assert.Equal(t, ErrPassphrase, err)
+}
+
+func TestKDFBounds(t *testing.T) {
+	k, err := newKDF(testParams)
+	require.Nil(t, err)
+	require.Nil(t, k.check())
+
+	// a header asking for more memory, passes or threads than MaxParams is
+	// rejected before anything is derived
+	for _, p := range []Params{
+		{Time: 1, Memory: MaxParams.Memory + 1, Threads: 1},
+		{Time: MaxParams.Time + 1, Memory: 64, Threads: 1},
+		{Time: 1, Memory: 1024, Threads: MaxParams.Threads + 1},
+	} {
+		tampered := k
+		tampered.Params = p
+		assert.NotNil(t, tampered.check())
+
+		_, err := newKDF(p)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestDuplicateEntries(t *testing.T) {
+	secret := make([]byte, 32)
+	entries := []*Entry{
+		{Name: "node", Type: Schnorr, Secret: secret},
+		{Name: "node", Type: Schnorr, Secret: secret},
+	}
+	_, err := entryMap(entries)
+	assert.NotNil(t, err)
+
+	_, err = entryMap(entries[:1])
+	assert.Nil(t, err)
+}
+
+func TestImportFile(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	ks, err := Create(filepath.Join(dir, "keys.json"), []byte("passphrase"), testParams)
+	require.Nil(t, err)
+
+	_, blsSk, err := bls.GenKeyPair(nil)
+	require.Nil(t, err)
+	binPath := filepath.Join(dir, "bls.key")
+	require.Nil(t, ioutil.WriteFile(binPath, blsSk.Marshal(), 0600))
+	require.Nil(t, ks.ImportFile(binPath, "consensus", BLS, nil))
+
+	sk, _ := schnorr.GenerateKey()
+	hexPath := filepath.Join(dir, "node.key")
+	require.Nil(t, ioutil.WriteFile(hexPath, []byte(hex.EncodeToString(sk.Bytes())+"\n"), 0600))
+	require.Nil(t, ks.ImportFile(hexPath, "node", Schnorr, nil))
+
+	got, err := ks.Schnorr("node")
+	require.Nil(t, err)
+	assert.True(t, sk.Equals(&got))
+
+	garbage := filepath.Join(dir, "garbage.key")
+	require.Nil(t, ioutil.WriteFile(garbage, []byte("not a key"), 0600))
+	assert.NotNil(t, ks.ImportFile(garbage, "garbage", Schnorr, nil))
+	assert.NotNil(t, ks.ImportFile(binPath, "wrong type", Stealth, nil))
+}
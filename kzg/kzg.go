@@ -0,0 +1,277 @@
+// Package kzg implements the KZG polynomial commitment scheme over the
+// bn256 pairing. A commitment to a polynomial p is the single point
+// p(tau) * G1. Its evaluations can be opened with a single point as well,
+// at one or at many positions at once.
+//
+// Polynomials and evaluations are integers modulo bn256.Order
+package kzg
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/dusk-network/bn256"
+)
+
+// Polynomial holds the coefficients of a polynomial, lowest degree first
+type Polynomial []*big.Int
+
+// Proof is an evaluation proof: the commitment to the quotient polynomial
+type Proof struct {
+	W *bn256.G1
+}
+
+// Eval returns p(z)
+func (p Polynomial) Eval(z *big.Int) *big.Int {
+	res := new(big.Int)
+	for i := len(p) - 1; i >= 0; i-- {
+		res.Mul(res, z)
+		res.Add(res, p[i])
+		res.Mod(res, bn256.Order)
+	}
+	return res
+}
+
+// Commit returns the commitment to p
+func Commit(srs *SRS, p Polynomial) (*bn256.G1, error) {
+	if len(p) == 0 {
+		return nil, errors.New("empty polynomial")
+	}
+	if len(p) > len(srs.G1) {
+		return nil, errors.New("polynomial degree exceeds the SRS")
+	}
+	for _, c := range p {
+		if c == nil || c.Sign() < 0 || c.Cmp(bn256.Order) >= 0 {
+			return nil, errors.New("coefficient is not a field element")
+		}
+	}
+
+	return msm(srs.G1, p), nil
+}
+
+// msm returns sum(scalars[i] * points[i])
+func msm(points []*bn256.G1, scalars []*big.Int) *bn256.G1 {
+	res := new(bn256.G1).ScalarBaseMult(new(big.Int))
+	for i, s := range scalars {
+		if s.Sign() == 0 {
+			continue
+		}
+		res.Add(res, new(bn256.G1).ScalarMult(points[i], s))
+	}
+	return res
+}
+
+// Open evaluates p at z and proves the evaluation
+func Open(srs *SRS, p Polynomial, z *big.Int) (*big.Int, *Proof, error) {
+	values, proof, err := OpenBatch(srs, p, []*big.Int{z})
+	if err != nil {
+		return nil, nil, err
+	}
+	return values[0], proof, nil
+}
+
+// Verify checks that the polynomial committed to in c evaluates to y at z,
+// i.e. that e(c - y * G1, G2) = e(W, (tau - z) * G2)
+func Verify(srs *SRS, c *bn256.G1, z, y *big.Int, proof *Proof) bool {
+	return VerifyBatch(srs, c, []*big.Int{z}, []*big.Int{y}, proof)
+}
+
+// OpenBatch evaluates p at every point and proves all the evaluations with
+// a single proof: the commitment to q = (p - I) / Z, where I interpolates
+// the evaluations and Z vanishes on the points
+func OpenBatch(srs *SRS, p Polynomial, points []*big.Int) ([]*big.Int, *Proof, error) {
+	if len(points) == 0 || len(points) >= len(srs.G2) {
+		return nil, nil, errors.New("invalid number of points")
+	}
+	if err := checkDistinct(points); err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]*big.Int, len(points))
+	for i := range points {
+		values[i] = p.Eval(points[i])
+	}
+
+	numerator := sub(p, interpolate(points, values))
+	q, rem := divide(numerator, vanishing(points))
+	for _, r := range rem {
+		if r.Sign() != 0 {
+			return nil, nil, errors.New("the evaluations do not divide the polynomial")
+		}
+	}
+
+	if len(q) == 0 {
+		q = Polynomial{new(big.Int)}
+	}
+	w, err := Commit(srs, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return values, &Proof{W: w}, nil
+}
+
+// VerifyBatch checks a proof produced by OpenBatch:
+// e(c - I(tau) * G1, G2) = e(W, Z(tau) * G2)
+func VerifyBatch(srs *SRS, c *bn256.G1, points, values []*big.Int, proof *Proof) bool {
+	if proof == nil || proof.W == nil || c == nil {
+		return false
+	}
+	if len(points) == 0 || len(points) != len(values) || len(points) >= len(srs.G2) {
+		return false
+	}
+	if checkDistinct(points) != nil {
+		return false
+	}
+	for _, v := range values {
+		if v == nil || v.Sign() < 0 || v.Cmp(bn256.Order) >= 0 {
+			return false
+		}
+	}
+
+	i := interpolate(points, values)
+	lhs := new(bn256.G1).Neg(msm(srs.G1, i))
+	lhs.Add(lhs, c)
+
+	z := vanishing(points)
+	zTau := new(bn256.G2).ScalarBaseMult(new(big.Int))
+	for k, coeff := range z {
+		if coeff.Sign() != 0 {
+			zTau.Add(zTau, new(bn256.G2).ScalarMult(srs.G2[k], coeff))
+		}
+	}
+
+	return pairingsEqual(lhs, srs.G2[0], proof.W, zTau)
+}
+
+// Interpolate returns the polynomial of lowest degree that evaluates to
+// values[i] at points[i]. It lets callers commit to vectors in evaluation
+// form
+func Interpolate(points, values []*big.Int) (Polynomial, error) {
+	if len(points) == 0 || len(points) != len(values) {
+		return nil, errors.New("invalid number of points")
+	}
+	if err := checkDistinct(points); err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		if v == nil || v.Sign() < 0 || v.Cmp(bn256.Order) >= 0 {
+			return nil, errors.New("value is not a field element")
+		}
+	}
+	return interpolate(points, values), nil
+}
+
+func checkDistinct(points []*big.Int) error {
+	seen := make(map[string]bool, len(points))
+	for _, z := range points {
+		if z == nil || z.Sign() < 0 || z.Cmp(bn256.Order) >= 0 {
+			return errors.New("point is not a field element")
+		}
+		if seen[z.String()] {
+			return errors.New("duplicate evaluation point")
+		}
+		seen[z.String()] = true
+	}
+	return nil
+}
+
+// vanishing returns prod(X - z_i)
+func vanishing(points []*big.Int) Polynomial {
+	res := Polynomial{big.NewInt(1)}
+	for _, z := range points {
+		res = mul(res, Polynomial{new(big.Int).Sub(bn256.Order, z), big.NewInt(1)})
+	}
+	return res
+}
+
+// interpolate returns the Lagrange interpolation of the values at the points
+func interpolate(points, values []*big.Int) Polynomial {
+	res := Polynomial{new(big.Int)}
+	for i := range points {
+		// l_i = prod(X - z_j) / prod(z_i - z_j) for j != i
+		num := Polynomial{big.NewInt(1)}
+		den := big.NewInt(1)
+		for j := range points {
+			if i == j {
+				continue
+			}
+			num = mul(num, Polynomial{new(big.Int).Sub(bn256.Order, points[j]), big.NewInt(1)})
+			d := new(big.Int).Sub(points[i], points[j])
+			den.Mul(den, d)
+			den.Mod(den, bn256.Order)
+		}
+
+		scale := den.ModInverse(den, bn256.Order)
+		scale.Mul(scale, values[i])
+		for k := range num {
+			num[k].Mul(num[k], scale)
+			num[k].Mod(num[k], bn256.Order)
+		}
+		res = add(res, num)
+	}
+	return res
+}
+
+func add(a, b Polynomial) Polynomial {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	res := make(Polynomial, len(a))
+	for i := range a {
+		res[i] = new(big.Int).Set(a[i])
+		if i < len(b) {
+			res[i].Add(res[i], b[i])
+			res[i].Mod(res[i], bn256.Order)
+		}
+	}
+	return res
+}
+
+func sub(a, b Polynomial) Polynomial {
+	neg := make(Polynomial, len(b))
+	for i := range b {
+		neg[i] = new(big.Int).Sub(bn256.Order, b[i])
+		neg[i].Mod(neg[i], bn256.Order)
+	}
+	return add(a, neg)
+}
+
+func mul(a, b Polynomial) Polynomial {
+	res := make(Polynomial, len(a)+len(b)-1)
+	for i := range res {
+		res[i] = new(big.Int)
+	}
+	tmp := new(big.Int)
+	for i := range a {
+		for j := range b {
+			tmp.Mul(a[i], b[j])
+			res[i+j].Add(res[i+j], tmp)
+			res[i+j].Mod(res[i+j], bn256.Order)
+		}
+	}
+	return res
+}
+
+// divide returns the quotient and the remainder of a / b, b being monic
+func divide(a, b Polynomial) (Polynomial, Polynomial) {
+	rem := make(Polynomial, len(a))
+	for i := range a {
+		rem[i] = new(big.Int).Set(a[i])
+	}
+	if len(a) < len(b) {
+		return nil, rem
+	}
+
+	q := make(Polynomial, len(a)-len(b)+1)
+	tmp := new(big.Int)
+	for i := len(q) - 1; i >= 0; i-- {
+		c := new(big.Int).Set(rem[i+len(b)-1])
+		q[i] = c
+		for j := range b {
+			tmp.Mul(c, b[j])
+			rem[i+j].Sub(rem[i+j], tmp)
+			rem[i+j].Mod(rem[i+j], bn256.Order)
+		}
+	}
+	return q, rem[:len(b)-1]
+}
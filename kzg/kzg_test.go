@@ -0,0 +1,117 @@
+package kzg
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/dusk-network/bn256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomPolynomial(t *testing.T, n int) Polynomial {
+	p := make(Polynomial, n)
+	for i := range p {
+		c, err := rand.Int(rand.Reader, bn256.Order)
+		require.Nil(t, err)
+		p[i] = c
+	}
+	return p
+}
+
+func TestOpenVerify(t *testing.T) {
+	srs, err := NewInsecureSRS(16, 4, nil)
+	require.Nil(t, err)
+
+	p := randomPolynomial(t, 17)
+	c, err := Commit(srs, p)
+	require.Nil(t, err)
+
+	z := big.NewInt(12345)
+	y, proof, err := Open(srs, p, z)
+	require.Nil(t, err)
+	assert.Equal(t, 0, p.Eval(z).Cmp(y))
+	assert.True(t, Verify(srs, c, z, y, proof))
+
+	// wrong value, point, commitment or proof
+	assert.False(t, Verify(srs, c, z, new(big.Int).Add(y, big.NewInt(1)), proof))
+	assert.False(t, Verify(srs, c, big.NewInt(54321), y, proof))
+	other, err := Commit(srs, randomPolynomial(t, 17))
+	require.Nil(t, err)
+	assert.False(t, Verify(srs, other, z, y, proof))
+	_, otherProof, err := Open(srs, p, big.NewInt(1))
+	require.Nil(t, err)
+	assert.False(t, Verify(srs, c, z, y, otherProof))
+
+	// the polynomial must fit in the SRS
+	_, err = Commit(srs, randomPolynomial(t, 18))
+	assert.NotNil(t, err)
+}
+
+func TestOpenBatch(t *testing.T) {
+	srs, err := NewInsecureSRS(8, 4, nil)
+	require.Nil(t, err)
+
+	for _, deg := range []int{1, 3, 9} {
+		p := randomPolynomial(t, deg)
+		c, err := Commit(srs, p)
+		require.Nil(t, err)
+
+		points := []*big.Int{big.NewInt(1), big.NewInt(7), big.NewInt(100), big.NewInt(3)}
+		values, proof, err := OpenBatch(srs, p, points)
+		require.Nil(t, err)
+		for i := range points {
+			assert.Equal(t, 0, p.Eval(points[i]).Cmp(values[i]))
+		}
+		assert.True(t, VerifyBatch(srs, c, points, values, proof))
+
+		tampered := append([]*big.Int{}, values...)
+		tampered[2] = new(big.Int).Add(values[2], big.NewInt(1))
+		assert.False(t, VerifyBatch(srs, c, points, tampered, proof))
+		if deg > len(points) {
+			// a low degree polynomial is its own interpolation, so only
+			// here does the proof differ from a proof for fewer points
+			assert.False(t, VerifyBatch(srs, c, points[:3], values[:3], proof))
+		}
+	}
+
+	p := randomPolynomial(t, 5)
+	_, _, err = OpenBatch(srs, p, []*big.Int{big.NewInt(1), big.NewInt(1)})
+	assert.NotNil(t, err)
+	_, _, err = OpenBatch(srs, p, []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)})
+	assert.NotNil(t, err)
+}
+
+func TestSRSEncodeDecode(t *testing.T) {
+	srs, err := NewInsecureSRS(8, 2, nil)
+	require.Nil(t, err)
+	require.Nil(t, srs.Check())
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, srs.Encode(buf))
+	encoded := append([]byte{}, buf.Bytes()...)
+
+	loaded, err := ReadSRS(buf)
+	require.Nil(t, err)
+	assert.Equal(t, srs.Degree(), loaded.Degree())
+
+	p := randomPolynomial(t, 9)
+	c1, err := Commit(srs, p)
+	require.Nil(t, err)
+	c2, err := Commit(loaded, p)
+	require.Nil(t, err)
+	assert.Equal(t, c1.Marshal(), c2.Marshal())
+
+	_, err = ReadSRS(bytes.NewReader(encoded[:len(encoded)-1]))
+	assert.NotNil(t, err)
+
+	// swapping two powers breaks the consistency check
+	broken := &SRS{G1: append([]*bn256.G1{}, srs.G1...), G2: srs.G2}
+	broken.G1[2], broken.G1[3] = broken.G1[3], broken.G1[2]
+	assert.NotNil(t, broken.Check())
+
+	brokenG2 := &SRS{G1: srs.G1, G2: []*bn256.G2{srs.G2[0], srs.G2[2], srs.G2[1]}}
+	assert.NotNil(t, brokenG2.Check())
+}
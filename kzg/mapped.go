@@ -0,0 +1,218 @@
+package kzg
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/dusk-network/bn256"
+)
+
+// headerSize is the size of the header of an encoded SRS, the number of
+// points of both groups
+const headerSize = 8
+
+// MappedSRS is an encoded SRS, e.g. the output of a powers of tau ceremony,
+// mapped in memory from a file. Its points are only decoded when they are
+// used, so that a large setup needs not be resident: Load decodes the prefix
+// a deployment needs, which is itself an SRS.
+// On platforms without mmap the file is read in memory, and only the
+// decoding is lazy
+type MappedSRS struct {
+	data   []byte
+	n1, n2 int
+	unmap  func() error
+}
+
+// OpenSRS maps the SRS encoded in the file at path. Only the size of the
+// file is checked: Check or Load verify the points
+func OpenSRS(path string) (*MappedSRS, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < headerSize {
+		return nil, errors.New("SRS file is too short")
+	}
+	var hdr [headerSize]byte
+	if _, err := io.ReadFull(f, hdr[:]); err != nil {
+		return nil, err
+	}
+	n1, n2 := binary.BigEndian.Uint32(hdr[:4]), binary.BigEndian.Uint32(hdr[4:])
+	if !validSize(n1, n2) {
+		return nil, errors.New("invalid SRS size")
+	}
+	if info.Size() != encodedSize(int(n1), int(n2)) {
+		return nil, errors.New("SRS file size does not match its header")
+	}
+
+	data, unmap, err := mapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, err
+	}
+	return &MappedSRS{data: data, n1: int(n1), n2: int(n2), unmap: unmap}, nil
+}
+
+// encodedSize returns the size of the encoding of an SRS of n1 points of G1
+// and n2 of G2
+func encodedSize(n1, n2 int) int64 {
+	return headerSize + int64(n1)*g1Size + int64(n2)*g2Size
+}
+
+// Close unmaps the file. The SRS returned by Load remain valid
+func (m *MappedSRS) Close() error {
+	if m.unmap == nil {
+		return nil
+	}
+	err := m.unmap()
+	m.data, m.unmap = nil, nil
+	return err
+}
+
+// Degree returns the maximum degree of the polynomials the SRS can commit to
+func (m *MappedSRS) Degree() int {
+	return m.n1 - 1
+}
+
+// Points returns the maximum number of points of a batched opening
+func (m *MappedSRS) Points() int {
+	return m.n2 - 1
+}
+
+// G1 decodes tau^i * G1
+func (m *MappedSRS) G1(i int) (*bn256.G1, error) {
+	if m.data == nil {
+		return nil, errors.New("SRS is closed")
+	}
+	if i < 0 || i >= m.n1 {
+		return nil, errors.New("no such SRS point")
+	}
+	off := headerSize + i*g1Size
+	p := new(bn256.G1)
+	if _, err := p.Unmarshal(m.data[off : off+g1Size]); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// G2 decodes tau^i * G2
+func (m *MappedSRS) G2(i int) (*bn256.G2, error) {
+	if m.data == nil {
+		return nil, errors.New("SRS is closed")
+	}
+	if i < 0 || i >= m.n2 {
+		return nil, errors.New("no such SRS point")
+	}
+	off := headerSize + m.n1*g1Size + i*g2Size
+	p := new(bn256.G2)
+	if _, err := p.Unmarshal(m.data[off : off+g2Size]); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Check verifies the whole SRS like SRS.Check, decoding the points as it
+// goes, so that they are never all resident
+func (m *MappedSRS) Check() error {
+	return checkPowers(m.n1, m.n2, m.G1, m.G2)
+}
+
+// Load decodes the SRS for polynomials of degree up to degree and batched
+// openings at up to points points, and checks it. A prefix of a valid SRS is
+// valid, so a deployment only verifies what it uses
+func (m *MappedSRS) Load(degree, points int) (*SRS, error) {
+	if degree < 1 || degree > m.Degree() || points < 1 || points > m.Points() || points > degree {
+		return nil, fmt.Errorf("SRS of degree %d and %d points cannot be loaded from one of degree %d and %d points",
+			degree, points, m.Degree(), m.Points())
+	}
+
+	srs := &SRS{G1: make([]*bn256.G1, degree+1), G2: make([]*bn256.G2, points+1)}
+	var err error
+	for i := range srs.G1 {
+		if srs.G1[i], err = m.G1(i); err != nil {
+			return nil, err
+		}
+	}
+	for i := range srs.G2 {
+		if srs.G2[i], err = m.G2(i); err != nil {
+			return nil, err
+		}
+	}
+	if err := srs.Check(); err != nil {
+		return nil, err
+	}
+	return srs, nil
+}
+
+// FetchSRS downloads the SRS encoded at url to the file at path, which is
+// only replaced once the download completes. The size announced by the
+// header of the SRS is enforced, and the points are left to OpenSRS and
+// Check
+func FetchSRS(ctx context.Context, url, path string) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching SRS: %s", resp.Status)
+	}
+
+	var hdr [headerSize]byte
+	if _, err := io.ReadFull(resp.Body, hdr[:]); err != nil {
+		return err
+	}
+	n1, n2 := binary.BigEndian.Uint32(hdr[:4]), binary.BigEndian.Uint32(hdr[4:])
+	if !validSize(n1, n2) {
+		return errors.New("invalid SRS size")
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := tmp.Write(hdr[:]); err != nil {
+		return err
+	}
+	rest := encodedSize(int(n1), int(n2)) - headerSize
+	if n, err := io.Copy(tmp, io.LimitReader(resp.Body, rest)); err != nil {
+		return err
+	} else if n != rest {
+		return errors.New("SRS download is truncated")
+	}
+	// trailing data means that the header is not the one of the file. A
+	// single Read may return no byte before the end of the body, so the body
+	// is read until one byte or EOF
+	if n, err := io.CopyN(ioutil.Discard, resp.Body, 1); n > 0 {
+		return errors.New("SRS download is longer than its header announces")
+	} else if err != io.EOF {
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,124 @@
+package kzg
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSRS(t *testing.T, dir string, srs *SRS) (string, []byte) {
+	buf := &bytes.Buffer{}
+	require.Nil(t, srs.Encode(buf))
+	path := filepath.Join(dir, "srs")
+	require.Nil(t, ioutil.WriteFile(path, buf.Bytes(), 0600))
+	return path, buf.Bytes()
+}
+
+func TestMappedSRS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srs")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	srs, err := NewInsecureSRS(16, 4, nil)
+	require.Nil(t, err)
+	path, encoded := writeSRS(t, dir, srs)
+
+	m, err := OpenSRS(path)
+	require.Nil(t, err)
+	assert.Equal(t, 16, m.Degree())
+	assert.Equal(t, 4, m.Points())
+	assert.Nil(t, m.Check())
+
+	// a prefix commits like the whole SRS
+	small, err := m.Load(8, 2)
+	require.Nil(t, err)
+	p := randomPolynomial(t, 9)
+	c1, err := Commit(small, p)
+	require.Nil(t, err)
+	c2, err := Commit(srs, p)
+	require.Nil(t, err)
+	assert.Equal(t, c1.Marshal(), c2.Marshal())
+
+	_, err = m.Load(17, 2)
+	assert.NotNil(t, err)
+	_, err = m.G1(17)
+	assert.NotNil(t, err)
+
+	require.Nil(t, m.Close())
+	_, err = m.G1(0)
+	assert.NotNil(t, err)
+	// the loaded SRS outlives the mapping
+	_, err = Commit(small, p)
+	assert.Nil(t, err)
+
+	// a file whose powers are not consistent
+	other, err := NewInsecureSRS(16, 4, nil)
+	require.Nil(t, err)
+	tampered := append([]byte{}, encoded...)
+	off := headerSize + 10*g1Size
+	copy(tampered[off:off+g1Size], other.G1[10].Marshal())
+	require.Nil(t, ioutil.WriteFile(path, tampered, 0600))
+	m, err = OpenSRS(path)
+	require.Nil(t, err)
+	defer m.Close()
+	assert.NotNil(t, m.Check())
+	_, err = m.Load(16, 2)
+	assert.NotNil(t, err)
+	// the prefix before the tampered point is still valid
+	_, err = m.Load(9, 2)
+	assert.Nil(t, err)
+
+	// the size must match the header
+	require.Nil(t, ioutil.WriteFile(path, encoded[:len(encoded)-1], 0600))
+	_, err = OpenSRS(path)
+	assert.NotNil(t, err)
+}
+
+func TestFetchSRS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "srs")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	srs, err := NewInsecureSRS(8, 2, nil)
+	require.Nil(t, err)
+	buf := &bytes.Buffer{}
+	require.Nil(t, srs.Encode(buf))
+	encoded := buf.Bytes()
+
+	served, trailing := encoded, []byte(nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(served)
+		if trailing != nil {
+			// the trailing data comes in a chunk of its own
+			w.(http.Flusher).Flush()
+			_, _ = w.Write(trailing)
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(dir, "srs")
+	require.Nil(t, FetchSRS(context.Background(), server.URL, path))
+	m, err := OpenSRS(path)
+	require.Nil(t, err)
+	defer m.Close()
+	assert.Nil(t, m.Check())
+
+	// truncated and overlong downloads leave the file untouched
+	served = encoded[:len(encoded)-1]
+	assert.NotNil(t, FetchSRS(context.Background(), server.URL, path))
+	served = append(append([]byte{}, encoded...), 0)
+	assert.NotNil(t, FetchSRS(context.Background(), server.URL, path))
+	served, trailing = encoded, []byte{0}
+	assert.NotNil(t, FetchSRS(context.Background(), server.URL, path))
+	b, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, encoded, b)
+}
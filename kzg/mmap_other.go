@@ -0,0 +1,19 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package kzg
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile reads the first size bytes of f in memory, where files cannot be
+// mapped, e.g. js/wasm
+func mapFile(f *os.File, size int) ([]byte, func() error, error) {
+	data := make([]byte, size)
+	if _, err := f.ReadAt(data, 0); err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
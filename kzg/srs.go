@@ -0,0 +1,250 @@
+package kzg
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// MaxDegree bounds the size of the SRS accepted when decoding
+const MaxDegree = 1 << 20
+
+const (
+	g1Size = 64
+	g2Size = 129
+)
+
+// SRS is the structured reference string of the scheme: the powers of a
+// secret tau in both groups. Nobody must know tau, which is why the SRS
+// comes out of a trusted setup ceremony
+type SRS struct {
+	// G1[i] = tau^i * G1, for polynomials of degree up to len(G1)-1
+	G1 []*bn256.G1
+	// G2[i] = tau^i * G2, for batched openings at up to len(G2)-1 points
+	G2 []*bn256.G2
+}
+
+// NewInsecureSRS returns an SRS for a tau read from r, which is forgotten
+// afterwards. Since whoever runs it could keep tau, it must only be used in
+// tests and by single party deployments
+func NewInsecureSRS(degree, points int, r io.Reader) (*SRS, error) {
+	if degree < 1 || degree > MaxDegree || points < 1 || points > degree {
+		return nil, errors.New("invalid SRS size")
+	}
+	if r == nil {
+		r = rng.Reader
+	}
+
+	tau, err := rand.Int(r, bn256.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	srs := &SRS{
+		G1: make([]*bn256.G1, degree+1),
+		G2: make([]*bn256.G2, points+1),
+	}
+
+	pow := big.NewInt(1)
+	for i := range srs.G1 {
+		srs.G1[i] = new(bn256.G1).ScalarBaseMult(pow)
+		if i < len(srs.G2) {
+			srs.G2[i] = new(bn256.G2).ScalarBaseMult(pow)
+		}
+		pow.Mul(pow, tau)
+		pow.Mod(pow, bn256.Order)
+	}
+	return srs, nil
+}
+
+// Degree returns the maximum degree of the polynomials the SRS can commit to
+func (srs *SRS) Degree() int {
+	return len(srs.G1) - 1
+}
+
+// Check verifies that the SRS is made of successive powers of the same tau,
+// starting with the generators. It tests e(G1[i+1], G2[0]) = e(G1[i], G2[1])
+// for a random linear combination of all i at once, and likewise for G2
+func (srs *SRS) Check() error {
+	return checkPowers(len(srs.G1), len(srs.G2),
+		func(i int) (*bn256.G1, error) { return srs.G1[i], nil },
+		func(i int) (*bn256.G2, error) { return srs.G2[i], nil })
+}
+
+// checkPowers is Check for an SRS of n1 points of G1 and n2 of G2, read with
+// g1 and g2, so that the points need not all be resident
+func checkPowers(n1, n2 int, g1 func(int) (*bn256.G1, error), g2 func(int) (*bn256.G2, error)) error {
+	if n1 < 2 || n2 < 2 {
+		return errors.New("SRS is too short")
+	}
+	g1s := make([]*bn256.G1, 2)
+	g2s := make([]*bn256.G2, 2)
+	for i := range g1s {
+		var err error
+		if g1s[i], err = g1(i); err != nil {
+			return err
+		}
+		if g2s[i], err = g2(i); err != nil {
+			return err
+		}
+	}
+
+	one := big.NewInt(1)
+	if !bytes.Equal(g1s[0].Marshal(), new(bn256.G1).ScalarBaseMult(one).Marshal()) ||
+		!bytes.Equal(g2s[0].Marshal(), new(bn256.G2).ScalarBaseMult(one).Marshal()) {
+		return errors.New("SRS does not start with the generators")
+	}
+
+	hi, lo, err := randomCombination(n1, g1)
+	if err != nil {
+		return err
+	}
+	if !pairingsEqual(hi, g2s[0], lo, g2s[1]) {
+		return errors.New("G1 powers are inconsistent")
+	}
+
+	// e(G1[1], G2[i+1]) = e(G1[0], G2[i]) for every i
+	prev := g2s[0]
+	for i := 0; i+1 < n2; i++ {
+		next, err := g2(i + 1)
+		if err != nil {
+			return err
+		}
+		if !pairingsEqual(g1s[1], prev, g1s[0], next) {
+			return errors.New("G2 powers are inconsistent")
+		}
+		prev = next
+	}
+	return nil
+}
+
+// randomCombination returns sum(r_i p(i+1)) and sum(r_i p(i)) for random r_i,
+// over the n points p returns
+func randomCombination(n int, p func(int) (*bn256.G1, error)) (*bn256.G1, *bn256.G1, error) {
+	hi := new(bn256.G1).ScalarBaseMult(new(big.Int))
+	lo := new(bn256.G1).ScalarBaseMult(new(big.Int))
+	cur, err := p(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := 0; i+1 < n; i++ {
+		next, err := p(i + 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		r, err := rng.Int(bn256.Order)
+		if err != nil {
+			return nil, nil, err
+		}
+		hi.Add(hi, new(bn256.G1).ScalarMult(next, r))
+		lo.Add(lo, new(bn256.G1).ScalarMult(cur, r))
+		cur = next
+	}
+	return hi, lo, nil
+}
+
+// pairingsEqual returns e(a1, b1) == e(a2, b2)
+func pairingsEqual(a1 *bn256.G1, b1 *bn256.G2, a2 *bn256.G1, b2 *bn256.G2) bool {
+	return bytes.Equal(bn256.Pair(a1, b1).Marshal(), bn256.Pair(a2, b2).Marshal())
+}
+
+// Encode an SRS
+func (srs *SRS) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(srs.G1))); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(srs.G2))); err != nil {
+		return err
+	}
+	for _, p := range srs.G1 {
+		if err := binary.Write(w, binary.BigEndian, p.Marshal()); err != nil {
+			return err
+		}
+	}
+	for _, p := range srs.G2 {
+		if err := binary.Write(w, binary.BigEndian, marshalG2(p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validSize returns true if an SRS of n1 points of G1 and n2 of G2 may be
+// decoded
+func validSize(n1, n2 uint32) bool {
+	return n1 >= 2 && n1 <= MaxDegree+1 && n2 >= 2 && n2 <= n1
+}
+
+// Decode an SRS. The points are checked to be on the curve, but the SRS
+// itself is not checked, see ReadSRS
+func (srs *SRS) Decode(r io.Reader) error {
+	if srs == nil {
+		return errors.New("struct is nil")
+	}
+
+	var n1, n2 uint32
+	if err := binary.Read(r, binary.BigEndian, &n1); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.BigEndian, &n2); err != nil {
+		return err
+	}
+	if !validSize(n1, n2) {
+		return errors.New("invalid SRS size")
+	}
+
+	g1 := make([]*bn256.G1, n1)
+	buf := make([]byte, g2Size)
+	for i := range g1 {
+		if _, err := io.ReadFull(r, buf[:g1Size]); err != nil {
+			return err
+		}
+		g1[i] = new(bn256.G1)
+		if _, err := g1[i].Unmarshal(buf[:g1Size]); err != nil {
+			return err
+		}
+	}
+
+	g2 := make([]*bn256.G2, n2)
+	for i := range g2 {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return err
+		}
+		g2[i] = new(bn256.G2)
+		if _, err := g2[i].Unmarshal(buf); err != nil {
+			return err
+		}
+	}
+
+	srs.G1 = g1
+	srs.G2 = g2
+	return nil
+}
+
+// ReadSRS decodes the output of a trusted setup and checks it
+func ReadSRS(r io.Reader) (*SRS, error) {
+	srs := &SRS{}
+	if err := srs.Decode(r); err != nil {
+		return nil, err
+	}
+	if err := srs.Check(); err != nil {
+		return nil, err
+	}
+	return srs, nil
+}
+
+// marshalG2 returns the fixed size encoding of p. Marshal encodes the point
+// at infinity with a single byte, which is padded here
+func marshalG2(p *bn256.G2) []byte {
+	m := p.Marshal()
+	if len(m) == g2Size {
+		return m
+	}
+	return make([]byte, g2Size)
+}
@@ -0,0 +1,89 @@
+package merkletree
+
+// PathProof is the authentication path of a single leaf of an
+// IncrementalTree, as checked by VerifyPath
+type PathProof struct {
+	Leaf     [32]byte
+	Position uint64
+	Path     [][32]byte
+}
+
+// BatchVerifyPaths checks that all the proofs are valid against the same
+// root. The proofs are walked up the tree together: every node is hashed
+// once, and the siblings computable from other proofs are checked against
+// them instead of being trusted, so verifying the proofs of neighbouring
+// leaves costs a fraction of verifying them one by one
+func BatchVerifyPaths(root [32]byte, proofs []PathProof) bool {
+	ok, _ := batchVerifyPaths(root, proofs)
+	return ok
+}
+
+// batchVerifyPaths also returns the number of hashes computed
+func batchVerifyPaths(root [32]byte, proofs []PathProof) (bool, int) {
+	if len(proofs) == 0 {
+		return false, 0
+	}
+
+	depth := len(proofs[0].Path)
+	if depth == 0 || depth > MaxDepth {
+		return false, 0
+	}
+
+	nodes := make(map[uint64][32]byte, len(proofs))
+	for _, p := range proofs {
+		if len(p.Path) != depth || p.Position>>uint(depth) != 0 {
+			return false, 0
+		}
+		if n, ok := nodes[p.Position]; ok && n != p.Leaf {
+			return false, 0
+		}
+		nodes[p.Position] = p.Leaf
+	}
+
+	hashes := 0
+	for level := 0; level < depth; level++ {
+		// siblings supplied by the proofs, checked for consistency
+		siblings := make(map[uint64][32]byte)
+		for _, p := range proofs {
+			pos := p.Position >> uint(level)
+			sibling := pos ^ 1
+			if n, ok := nodes[sibling]; ok {
+				if n != p.Path[level] {
+					return false, hashes
+				}
+				continue
+			}
+			if s, ok := siblings[sibling]; ok && s != p.Path[level] {
+				return false, hashes
+			}
+			siblings[sibling] = p.Path[level]
+		}
+
+		next := make(map[uint64][32]byte, len(nodes))
+		for pos, node := range nodes {
+			parent := pos >> 1
+			if _, ok := next[parent]; ok {
+				continue
+			}
+
+			left, right := node, node
+			if pos&1 == 0 {
+				right = nodeAt(nodes, siblings, pos^1)
+			} else {
+				left = nodeAt(nodes, siblings, pos^1)
+			}
+			next[parent] = hashPair(left, right)
+			hashes++
+		}
+		nodes = next
+	}
+
+	return nodes[0] == root, hashes
+}
+
+func nodeAt(nodes, siblings map[uint64][32]byte, pos uint64) [32]byte {
+	if n, ok := nodes[pos]; ok {
+		return n
+	}
+	return siblings[pos]
+}
@@ -0,0 +1,93 @@
+package merkletree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fullTreeProofs returns the root of a full tree of random leaves and the
+// proofs of all of them
+func fullTreeProofs(t *testing.T, depth int) ([32]byte, []PathProof) {
+	tree, err := NewIncrementalTree(depth)
+	require.Nil(t, err)
+
+	levels := make([][][32]byte, depth+1)
+	for i := 0; i < 1<<uint(depth); i++ {
+		leaf := randomLeaf(t)
+		_, err := tree.Append(leaf)
+		require.Nil(t, err)
+		levels[0] = append(levels[0], leaf)
+	}
+	for l := 1; l <= depth; l++ {
+		for i := 0; i < len(levels[l-1]); i += 2 {
+			levels[l] = append(levels[l], hashPair(levels[l-1][i], levels[l-1][i+1]))
+		}
+	}
+
+	proofs := make([]PathProof, len(levels[0]))
+	for i := range proofs {
+		proofs[i] = PathProof{Leaf: levels[0][i], Position: uint64(i)}
+		for l := 0; l < depth; l++ {
+			proofs[i].Path = append(proofs[i].Path, levels[l][(i>>uint(l))^1])
+		}
+		require.True(t, VerifyPath(tree.Root(), proofs[i].Leaf, proofs[i].Position, proofs[i].Path))
+	}
+	return tree.Root(), proofs
+}
+
+func TestBatchVerifyPaths(t *testing.T) {
+	const depth = 6
+	root, proofs := fullTreeProofs(t, depth)
+
+	ok, hashes := batchVerifyPaths(root, proofs)
+	assert.True(t, ok)
+	// every internal node is hashed once
+	assert.Equal(t, 1<<depth-1, hashes)
+
+	// a contiguous range shares most of its ancestors
+	ok, hashes = batchVerifyPaths(root, proofs[8:24])
+	assert.True(t, ok)
+	assert.True(t, hashes <= 16*depth/2)
+
+	assert.True(t, BatchVerifyPaths(root, proofs[5:6]))
+	assert.True(t, BatchVerifyPaths(root, []PathProof{proofs[3], proofs[40], proofs[3]}))
+}
+
+func TestBatchVerifyPathsInvalid(t *testing.T) {
+	root, proofs := fullTreeProofs(t, 4)
+
+	assert.False(t, BatchVerifyPaths(root, nil))
+	assert.False(t, BatchVerifyPaths(randomLeaf(t), proofs))
+
+	// a wrong leaf
+	bad := append([]PathProof{}, proofs...)
+	bad[7].Leaf = randomLeaf(t)
+	assert.False(t, BatchVerifyPaths(root, bad))
+
+	// a sibling disagreeing with a node computed from another proof
+	bad = append([]PathProof{}, proofs[0], proofs[1])
+	bad[1].Path = append([][32]byte{}, proofs[1].Path...)
+	bad[1].Path[0] = randomLeaf(t)
+	assert.False(t, BatchVerifyPaths(root, bad))
+
+	// two proofs supplying different values for the same sibling
+	bad = append([]PathProof{}, proofs[0], proofs[1])
+	bad[1].Path = append([][32]byte{}, proofs[1].Path...)
+	bad[1].Path[2] = randomLeaf(t)
+	assert.False(t, BatchVerifyPaths(root, bad))
+
+	// the same position with two leaves
+	dup := proofs[2]
+	dup.Leaf = randomLeaf(t)
+	assert.False(t, BatchVerifyPaths(root, []PathProof{proofs[2], dup}))
+
+	// mismatched depths and out of range positions
+	short := proofs[4]
+	short.Path = short.Path[:3]
+	assert.False(t, BatchVerifyPaths(root, []PathProof{proofs[3], short}))
+	out := proofs[4]
+	out.Position = 1 << 4
+	assert.False(t, BatchVerifyPaths(root, []PathProof{out}))
+}
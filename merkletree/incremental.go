@@ -0,0 +1,209 @@
+package merkletree
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// MaxDepth is the maximum depth of an IncrementalTree
+const MaxDepth = 63
+
+// IncrementalTree is an append-only Merkle tree of fixed depth. Empty leaves
+// are zero, and nodes hash like the ones of Tree: SHA3-256(left || right).
+// It only stores the frontier of the tree, so appending a leaf and computing
+// the root cost O(depth) regardless of the number of leaves
+type IncrementalTree struct {
+	depth int
+	size  uint64
+
+	// filled[i] is the last left node completed at level i
+	filled [][32]byte
+	root   [32]byte
+}
+
+// emptyRoots[i] is the root of an empty subtree of depth i
+var emptyRoots = func() [][32]byte {
+	roots := make([][32]byte, MaxDepth+1)
+	for i := 1; i <= MaxDepth; i++ {
+		roots[i] = hashPair(roots[i-1], roots[i-1])
+	}
+	return roots
+}()
+
+func hashPair(left, right [32]byte) [32]byte {
+	var buf [64]byte
+	copy(buf[:32], left[:])
+	copy(buf[32:], right[:])
+	return sha3.Sum256(buf[:])
+}
+
+// NewIncrementalTree returns an empty tree with room for 2^depth leaves
+func NewIncrementalTree(depth int) (*IncrementalTree, error) {
+	if depth < 1 || depth > MaxDepth {
+		return nil, errors.New("invalid tree depth")
+	}
+	return newIncrementalTree(depth), nil
+}
+
+func newIncrementalTree(depth int) *IncrementalTree {
+	return &IncrementalTree{
+		depth:  depth,
+		filled: make([][32]byte, depth),
+		root:   emptyRoots[depth],
+	}
+}
+
+// Depth returns the depth of the tree
+func (t *IncrementalTree) Depth() int {
+	return t.depth
+}
+
+// Size returns the number of leaves appended so far
+func (t *IncrementalTree) Size() uint64 {
+	return t.size
+}
+
+// Root returns the current root of the tree
+func (t *IncrementalTree) Root() [32]byte {
+	return t.root
+}
+
+// Append adds a leaf to the tree and returns its position
+func (t *IncrementalTree) Append(leaf [32]byte) (uint64, error) {
+	if t.size == uint64(1)<<uint(t.depth) {
+		return 0, errors.New("tree is full")
+	}
+
+	pos := t.size
+	node := leaf
+	for i := 0; i < t.depth; i++ {
+		if (pos>>uint(i))&1 == 0 {
+			t.filled[i] = node
+			node = hashPair(node, emptyRoots[i])
+		} else {
+			node = hashPair(t.filled[i], node)
+		}
+	}
+
+	t.root = node
+	t.size++
+	return pos, nil
+}
+
+// Witness returns the authentication path of the last appended leaf. The
+// witness must be fed every leaf appended to the tree afterwards to follow
+// the root
+func (t *IncrementalTree) Witness(leaf [32]byte) (*Witness, error) {
+	if t.size == 0 {
+		return nil, errors.New("tree is empty")
+	}
+
+	w := &Witness{
+		position: t.size - 1,
+		leaf:     leaf,
+		path:     make([][32]byte, t.depth),
+		pending:  make([]*IncrementalTree, t.depth),
+	}
+
+	for i := 0; i < t.depth; i++ {
+		if (w.position>>uint(i))&1 == 1 {
+			w.path[i] = t.filled[i]
+		} else {
+			w.path[i] = emptyRoots[i]
+		}
+	}
+
+	if w.Root() != t.root {
+		return nil, errors.New("leaf is not the last appended leaf")
+	}
+	return w, nil
+}
+
+// Witness is the authentication path of a leaf of an IncrementalTree
+type Witness struct {
+	position uint64
+	leaf     [32]byte
+	size     uint64
+
+	// path[i] is the sibling of the leaf's ancestor at level i
+	path [][32]byte
+
+	// pending[i] accumulates the leaves of the right sibling subtree at
+	// level i, while it fills up
+	pending []*IncrementalTree
+}
+
+// Position returns the position of the witnessed leaf
+func (w *Witness) Position() uint64 {
+	return w.position
+}
+
+// Leaf returns the witnessed leaf
+func (w *Witness) Leaf() [32]byte {
+	return w.leaf
+}
+
+// Path returns the siblings of the leaf's ancestors, from the leaf up
+func (w *Witness) Path() [][32]byte {
+	return append([][32]byte{}, w.path...)
+}
+
+// Append updates the witness with the next leaf appended to the tree
+func (w *Witness) Append(leaf [32]byte) error {
+	pos := w.position + w.size + 1
+	if pos>>uint(len(w.path)) != 0 {
+		return errors.New("tree is full")
+	}
+
+	// the leaf belongs to the right sibling subtree at the level of the
+	// highest bit in which its position differs from the witnessed one
+	level := len(w.path) - 1
+	for ; level >= 0; level-- {
+		if (pos^w.position)>>uint(level)&1 == 1 {
+			break
+		}
+	}
+
+	if w.pending[level] == nil {
+		w.pending[level] = newIncrementalTree(level)
+	}
+	sub := w.pending[level]
+	if level == 0 {
+		sub.root = leaf
+	} else if _, err := sub.Append(leaf); err != nil {
+		return err
+	}
+
+	w.path[level] = sub.root
+	if level == 0 || sub.size == uint64(1)<<uint(level) {
+		w.pending[level] = nil
+	}
+	w.size++
+	return nil
+}
+
+// Root returns the root of the tree the witness is currently up to date with
+func (w *Witness) Root() [32]byte {
+	return rootFromPath(w.leaf, w.position, w.path)
+}
+
+// VerifyPath checks that leaf is at position in the tree with the given root
+func VerifyPath(root, leaf [32]byte, position uint64, path [][32]byte) bool {
+	if len(path) == 0 || len(path) > MaxDepth || position>>uint(len(path)) != 0 {
+		return false
+	}
+	return rootFromPath(leaf, position, path) == root
+}
+
+func rootFromPath(leaf [32]byte, position uint64, path [][32]byte) [32]byte {
+	node := leaf
+	for i := range path {
+		if (position>>uint(i))&1 == 0 {
+			node = hashPair(node, path[i])
+		} else {
+			node = hashPair(path[i], node)
+		}
+	}
+	return node
+}
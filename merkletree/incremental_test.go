@@ -0,0 +1,114 @@
+package merkletree
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// naiveRoot computes the root of a tree of the given depth holding leaves
+func naiveRoot(leaves [][32]byte, depth int) [32]byte {
+	level := make([][32]byte, 1<<uint(depth))
+	copy(level, leaves)
+	for len(level) > 1 {
+		next := make([][32]byte, len(level)/2)
+		for i := range next {
+			next[i] = hashPair(level[2*i], level[2*i+1])
+		}
+		level = next
+	}
+	return level[0]
+}
+
+func randomLeaf(t *testing.T) [32]byte {
+	var leaf [32]byte
+	_, err := rand.Read(leaf[:])
+	require.Nil(t, err)
+	return leaf
+}
+
+func TestIncrementalRoot(t *testing.T) {
+	const depth = 5
+	tree, err := NewIncrementalTree(depth)
+	require.Nil(t, err)
+	assert.Equal(t, naiveRoot(nil, depth), tree.Root())
+
+	var leaves [][32]byte
+	for i := 0; i < 1<<depth; i++ {
+		leaf := randomLeaf(t)
+		pos, err := tree.Append(leaf)
+		require.Nil(t, err)
+		assert.Equal(t, uint64(i), pos)
+
+		leaves = append(leaves, leaf)
+		assert.Equal(t, naiveRoot(leaves, depth), tree.Root())
+	}
+
+	_, err = tree.Append(randomLeaf(t))
+	assert.NotNil(t, err)
+	assert.Equal(t, uint64(1<<depth), tree.Size())
+}
+
+func TestIncrementalWitness(t *testing.T) {
+	const depth = 4
+	tree, err := NewIncrementalTree(depth)
+	require.Nil(t, err)
+
+	var witnesses []*Witness
+	for i := 0; i < 1<<depth; i++ {
+		leaf := randomLeaf(t)
+		_, err := tree.Append(leaf)
+		require.Nil(t, err)
+
+		for _, w := range witnesses {
+			require.Nil(t, w.Append(leaf))
+		}
+
+		// witness every third leaf
+		if i%3 == 0 {
+			w, err := tree.Witness(leaf)
+			require.Nil(t, err)
+			witnesses = append(witnesses, w)
+		}
+
+		for _, w := range witnesses {
+			assert.Equal(t, tree.Root(), w.Root())
+			assert.True(t, VerifyPath(tree.Root(), w.Leaf(), w.Position(), w.Path()))
+		}
+	}
+
+	w := witnesses[0]
+	assert.NotNil(t, w.Append(randomLeaf(t)))
+
+	// a path does not authenticate another leaf or position
+	assert.False(t, VerifyPath(tree.Root(), randomLeaf(t), w.Position(), w.Path()))
+	assert.False(t, VerifyPath(tree.Root(), w.Leaf(), w.Position()+1, w.Path()))
+	assert.False(t, VerifyPath(tree.Root(), w.Leaf(), 1<<depth, w.Path()))
+}
+
+func TestIncrementalWitnessWrongLeaf(t *testing.T) {
+	tree, err := NewIncrementalTree(3)
+	require.Nil(t, err)
+
+	_, err = tree.Witness(randomLeaf(t))
+	assert.NotNil(t, err)
+
+	_, err = tree.Append(randomLeaf(t))
+	require.Nil(t, err)
+	_, err = tree.Witness(randomLeaf(t))
+	assert.NotNil(t, err)
+}
+
+func TestNewIncrementalTreeDepth(t *testing.T) {
+	_, err := NewIncrementalTree(0)
+	assert.NotNil(t, err)
+	_, err = NewIncrementalTree(MaxDepth + 1)
+	assert.NotNil(t, err)
+
+	tree, err := NewIncrementalTree(MaxDepth)
+	require.Nil(t, err)
+	_, err = tree.Append(randomLeaf(t))
+	assert.Nil(t, err)
+}
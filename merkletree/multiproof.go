@@ -0,0 +1,267 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/dusk-network/dusk-crypto/hash"
+)
+
+// MaxMultiProofHashes bounds the number of hashes accepted when decoding a
+// MultiProof
+const MaxMultiProofHashes = 1 << 20
+
+// MultiProof proves the inclusion of several leaves of a Tree at once.
+// The internal nodes shared by the authentication paths of the leaves, as
+// well as the ones computable from the leaves themselves, are only included
+// once or not at all
+type MultiProof struct {
+	// LeafCount is the number of leaves of the tree, including the
+	// duplicate of the last leaf when their number is odd
+	LeafCount uint32
+	// Indices are the positions of the proven leaves, in increasing order
+	Indices []uint32
+	// Hashes are the missing nodes, level by level from the leaves up
+	Hashes [][]byte
+}
+
+// MultiProof returns the proof of inclusion of the leaves at the given
+// positions
+func (t *Tree) MultiProof(indices []int) (*MultiProof, error) {
+	if len(indices) == 0 {
+		return nil, errors.New("no leaves to prove")
+	}
+
+	known := make([]uint32, 0, len(indices))
+	for _, i := range indices {
+		if i < 0 || i >= len(t.Leaves) {
+			return nil, errors.New("leaf index out of range")
+		}
+		known = append(known, uint32(i))
+	}
+	known = sortUnique(known)
+
+	level := make([][]byte, len(t.Leaves))
+	for i, leaf := range t.Leaves {
+		level[i] = leaf.Hash
+	}
+
+	proof := &MultiProof{
+		LeafCount: uint32(len(t.Leaves)),
+		Indices:   append([]uint32{}, known...),
+	}
+
+	for len(level) > 1 {
+		for _, sibling := range missingSiblings(known, len(level)) {
+			proof.Hashes = append(proof.Hashes, level[sibling])
+		}
+
+		next, err := nextLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		level = next
+		known = parents(known)
+	}
+
+	return proof, nil
+}
+
+// Verify checks that leaves, the hashes of the leaves at p.Indices, are in
+// the tree with the given root
+func (p *MultiProof) Verify(root []byte, leaves [][]byte) (bool, error) {
+	if len(leaves) != len(p.Indices) || len(leaves) == 0 {
+		return false, errors.New("leaves do not match the proven indices")
+	}
+
+	width := int(p.LeafCount)
+	nodes := make(map[uint32][]byte, len(leaves))
+	for i, idx := range p.Indices {
+		if i > 0 && idx <= p.Indices[i-1] {
+			return false, errors.New("indices are not in increasing order")
+		}
+		if int(idx) >= width {
+			return false, errors.New("leaf index out of range")
+		}
+		nodes[idx] = leaves[i]
+	}
+
+	known := p.Indices
+	hashes := p.Hashes
+	for width > 1 {
+		for _, sibling := range missingSiblings(known, width) {
+			if len(hashes) == 0 {
+				return false, errors.New("proof is too short")
+			}
+			nodes[sibling] = hashes[0]
+			hashes = hashes[1:]
+		}
+
+		next := make(map[uint32][]byte, len(known))
+		for _, parent := range parents(known) {
+			left := 2 * parent
+			right := left + 1
+			if int(right) == width {
+				right = left
+			}
+
+			h, err := hashChildren(nodes[left], nodes[right])
+			if err != nil {
+				return false, err
+			}
+			next[parent] = h
+		}
+
+		nodes = next
+		known = parents(known)
+		width = (width + 1) / 2
+	}
+
+	if len(hashes) != 0 {
+		return false, errors.New("proof has unused hashes")
+	}
+
+	return bytes.Equal(nodes[0], root), nil
+}
+
+// missingSiblings returns the siblings of the known nodes of a level of the
+// given width that are not known themselves
+func missingSiblings(known []uint32, width int) []uint32 {
+	isKnown := make(map[uint32]bool, len(known))
+	for _, k := range known {
+		isKnown[k] = true
+	}
+
+	var missing []uint32
+	for _, k := range known {
+		sibling := k ^ 1
+		if int(sibling) >= width || isKnown[sibling] {
+			continue
+		}
+		missing = append(missing, sibling)
+		isKnown[sibling] = true
+	}
+	return missing
+}
+
+func parents(known []uint32) []uint32 {
+	res := make([]uint32, 0, len(known))
+	for _, k := range known {
+		if len(res) == 0 || res[len(res)-1] != k/2 {
+			res = append(res, k/2)
+		}
+	}
+	return res
+}
+
+// nextLevel hashes a level of the tree like createIntermediate
+func nextLevel(level [][]byte) ([][]byte, error) {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		right := i + 1
+		if right == len(level) {
+			right = i
+		}
+		h, err := hashChildren(level[i], level[right])
+		if err != nil {
+			return nil, err
+		}
+		next = append(next, h)
+	}
+	return next, nil
+}
+
+func hashChildren(left, right []byte) ([]byte, error) {
+	buf := make([]byte, 0, len(left)+len(right))
+	buf = append(buf, left...)
+	buf = append(buf, right...)
+	return hash.Sha3256(buf)
+}
+
+func sortUnique(a []uint32) []uint32 {
+	sort.Slice(a, func(i, j int) bool { return a[i] < a[j] })
+	res := a[:0]
+	for i, v := range a {
+		if i == 0 || v != a[i-1] {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Encode a MultiProof
+func (p *MultiProof) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, p.LeafCount); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(p.Indices))); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, p.Indices); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(p.Hashes))); err != nil {
+		return err
+	}
+	for _, h := range p.Hashes {
+		if len(h) != 32 {
+			return errors.New("invalid hash length")
+		}
+		if err := binary.Write(w, binary.BigEndian, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode a MultiProof
+func (p *MultiProof) Decode(r io.Reader) error {
+	if p == nil {
+		return errors.New("struct is nil")
+	}
+
+	var leafCount, nIndices uint32
+	if err := binary.Read(r, binary.BigEndian, &leafCount); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.BigEndian, &nIndices); err != nil {
+		return err
+	}
+	if nIndices == 0 || nIndices > leafCount {
+		return errors.New("invalid number of indices")
+	}
+	if nIndices > MaxMultiProofHashes {
+		return errors.New("too many indices")
+	}
+
+	indices := make([]uint32, nIndices)
+	if err := binary.Read(r, binary.BigEndian, indices); err != nil {
+		return err
+	}
+
+	var nHashes uint32
+	if err := binary.Read(r, binary.BigEndian, &nHashes); err != nil {
+		return err
+	}
+	if nHashes > MaxMultiProofHashes {
+		return errors.New("too many hashes")
+	}
+
+	hashes := make([][]byte, nHashes)
+	for i := range hashes {
+		hashes[i] = make([]byte, 32)
+		if _, err := io.ReadFull(r, hashes[i]); err != nil {
+			return err
+		}
+	}
+
+	p.LeafCount = leafCount
+	p.Indices = indices
+	p.Hashes = hashes
+	return nil
+}
@@ -0,0 +1,139 @@
+package merkletree
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildTree(t *testing.T, n int) *Tree {
+	var pl []Payload
+	for i := 0; i < n; i++ {
+		pl = append(pl, TestPayload{x: fmt.Sprintf("leaf %d", i)})
+	}
+	tree, err := NewTree(pl)
+	require.Nil(t, err)
+	return tree
+}
+
+func leafHashes(tree *Tree, p *MultiProof) [][]byte {
+	var leaves [][]byte
+	for _, i := range p.Indices {
+		leaves = append(leaves, tree.Leaves[i].Hash)
+	}
+	return leaves
+}
+
+func TestMultiProof(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8, 13, 64, 100} {
+		tree := buildTree(t, n)
+
+		sets := [][]int{{0}, {n - 1}, {0, n - 1}, {n / 2, 0, n / 2}}
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		sets = append(sets, all)
+
+		for _, set := range sets {
+			p, err := tree.MultiProof(set)
+			require.Nil(t, err)
+
+			ok, err := p.Verify(tree.MerkleRoot, leafHashes(tree, p))
+			require.Nil(t, err)
+			assert.True(t, ok, "n=%d set=%v", n, set)
+		}
+
+		// proving every leaf needs no extra hash
+		p, err := tree.MultiProof(all)
+		require.Nil(t, err)
+		if n%2 == 0 {
+			assert.Empty(t, p.Hashes)
+		}
+	}
+}
+
+func TestMultiProofIsCompact(t *testing.T) {
+	tree := buildTree(t, 1024)
+
+	// 16 adjacent leaves share all but the lowest 4 levels of their paths
+	var set []int
+	for i := 32; i < 48; i++ {
+		set = append(set, i)
+	}
+	p, err := tree.MultiProof(set)
+	require.Nil(t, err)
+	assert.Equal(t, 10-4, len(p.Hashes))
+}
+
+func TestMultiProofRejects(t *testing.T) {
+	tree := buildTree(t, 20)
+	p, err := tree.MultiProof([]int{1, 7, 12})
+	require.Nil(t, err)
+	leaves := leafHashes(tree, p)
+
+	// wrong leaf
+	wrongLeaves := [][]byte{leaves[0], tree.Leaves[8].Hash, leaves[2]}
+	ok, err := p.Verify(tree.MerkleRoot, wrongLeaves)
+	require.Nil(t, err)
+	assert.False(t, ok)
+
+	// wrong root
+	other := buildTree(t, 21)
+	ok, err = p.Verify(other.MerkleRoot, leaves)
+	require.Nil(t, err)
+	assert.False(t, ok)
+
+	// tampered hashes
+	tampered := *p
+	tampered.Hashes = append([][]byte{}, p.Hashes...)
+	tampered.Hashes[0] = tree.Leaves[5].Hash
+	ok, _ = tampered.Verify(tree.MerkleRoot, leaves)
+	assert.False(t, ok)
+
+	short := *p
+	short.Hashes = p.Hashes[:len(p.Hashes)-1]
+	_, err = short.Verify(tree.MerkleRoot, leaves)
+	assert.NotNil(t, err)
+
+	long := *p
+	long.Hashes = append(append([][]byte{}, p.Hashes...), p.Hashes[0])
+	_, err = long.Verify(tree.MerkleRoot, leaves)
+	assert.NotNil(t, err)
+
+	_, err = p.Verify(tree.MerkleRoot, leaves[:2])
+	assert.NotNil(t, err)
+
+	unordered := *p
+	unordered.Indices = []uint32{7, 1, 12}
+	_, err = unordered.Verify(tree.MerkleRoot, leaves)
+	assert.NotNil(t, err)
+
+	_, err = tree.MultiProof(nil)
+	assert.NotNil(t, err)
+	_, err = tree.MultiProof([]int{20})
+	assert.NotNil(t, err)
+}
+
+func TestMultiProofEncodeDecode(t *testing.T) {
+	tree := buildTree(t, 50)
+	p, err := tree.MultiProof([]int{3, 4, 30, 49})
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, p.Encode(buf))
+	encoded := append([]byte{}, buf.Bytes()...)
+
+	var decoded MultiProof
+	require.Nil(t, decoded.Decode(buf))
+	assert.Equal(t, *p, decoded)
+
+	ok, err := decoded.Verify(tree.MerkleRoot, leafHashes(tree, &decoded))
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	assert.NotNil(t, decoded.Decode(bytes.NewReader(encoded[:len(encoded)-1])))
+}
@@ -0,0 +1,161 @@
+package merkletree
+
+import (
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// DefaultChunkSize is the leaf size used by StreamRoot when none is given
+const DefaultChunkSize = 4096
+
+// Streamer computes the root of a Tree whose leaves are the consecutive
+// chunks of a byte stream. Every chunk but the last one is exactly chunkSize
+// bytes long, and each leaf is the SHA3-256 of its chunk, so the root is the
+// same NewTree would compute over the chunks. Only one pending node per level
+// is kept, so memory stays bounded by the chunk size and the height of the
+// tree regardless of the length of the stream
+type Streamer struct {
+	chunkSize int
+	buf       []byte
+	leaves    uint64
+	size      uint64
+
+	// pending[i] is the last completed left node at level i, if any
+	pending []*[32]byte
+}
+
+// NewStreamer returns a Streamer cutting its input into chunkSize leaves
+func NewStreamer(chunkSize int) (*Streamer, error) {
+	if chunkSize <= 0 {
+		return nil, errors.New("invalid chunk size")
+	}
+	return &Streamer{
+		chunkSize: chunkSize,
+		buf:       make([]byte, 0, chunkSize),
+	}, nil
+}
+
+// Write appends p to the stream. It never fails
+func (s *Streamer) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		k := s.chunkSize - len(s.buf)
+		if k > len(p) {
+			k = len(p)
+		}
+		s.buf = append(s.buf, p[:k]...)
+		p = p[k:]
+		if len(s.buf) == s.chunkSize {
+			s.push(sha3.Sum256(s.buf))
+			s.buf = s.buf[:0]
+		}
+	}
+	s.size += uint64(n)
+	return n, nil
+}
+
+// push adds a full leaf, merging completed pairs up the levels
+func (s *Streamer) push(leaf [32]byte) {
+	s.leaves++
+	node := leaf
+	for level := 0; ; level++ {
+		if level == len(s.pending) {
+			s.pending = append(s.pending, nil)
+		}
+		if s.pending[level] == nil {
+			s.pending[level] = &node
+			return
+		}
+		node = hashPair(*s.pending[level], node)
+		s.pending[level] = nil
+	}
+}
+
+// Leaves returns the number of leaves written so far, including a trailing
+// partial chunk
+func (s *Streamer) Leaves() uint64 {
+	if len(s.buf) > 0 {
+		return s.leaves + 1
+	}
+	return s.leaves
+}
+
+// Size returns the number of bytes written so far
+func (s *Streamer) Size() uint64 {
+	return s.size
+}
+
+// Root returns the root of the tree over the data written so far. A trailing
+// partial chunk becomes the last leaf. The Streamer is left untouched, so
+// more data can be written afterwards
+func (s *Streamer) Root() ([]byte, error) {
+	n := s.Leaves()
+	if n == 0 {
+		return nil, errors.New("cannot construct tree with no content")
+	}
+
+	pending := s.pending
+	if len(s.buf) > 0 {
+		// fold the partial chunk into a copy of the frontier
+		leaf := sha3.Sum256(s.buf)
+		c := *s
+		c.pending = make([]*[32]byte, len(s.pending))
+		copy(c.pending, s.pending)
+		c.push(leaf)
+		pending = c.pending
+	}
+
+	// Tree pairs the last node of an odd level with itself, including the
+	// single leaf of a one leaf tree
+	var carry *[32]byte
+	for level := 0; ; level++ {
+		count := (n + (1 << uint(level)) - 1) >> uint(level)
+		if count == 1 && level > 0 {
+			if carry == nil {
+				carry = pending[level]
+			}
+			root := *carry
+			return root[:], nil
+		}
+
+		var p *[32]byte
+		if level < len(pending) {
+			p = pending[level]
+		}
+		var node [32]byte
+		switch {
+		case p != nil && carry != nil:
+			node = hashPair(*p, *carry)
+		case p != nil:
+			node = hashPair(*p, *p)
+		case carry != nil:
+			node = hashPair(*carry, *carry)
+		default:
+			continue
+		}
+		carry = &node
+	}
+}
+
+// StreamRoot reads r until EOF and returns the root of the tree whose leaves
+// are its chunkSize chunks, along with the number of bytes read. A zero
+// chunkSize selects DefaultChunkSize
+func StreamRoot(r io.Reader, chunkSize int) ([]byte, uint64, error) {
+	if chunkSize == 0 {
+		chunkSize = DefaultChunkSize
+	}
+	s, err := NewStreamer(chunkSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := io.Copy(s, r); err != nil {
+		return nil, 0, err
+	}
+	root, err := s.Root()
+	if err != nil {
+		return nil, 0, err
+	}
+	return root, s.Size(), nil
+}
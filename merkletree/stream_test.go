@@ -0,0 +1,85 @@
+package merkletree
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type chunkPayload []byte
+
+func (c chunkPayload) CalculateHash() ([]byte, error) {
+	return hash.Sha3256(c)
+}
+
+func treeRoot(t *testing.T, data []byte, chunkSize int) []byte {
+	var pl []Payload
+	for len(data) > 0 {
+		k := chunkSize
+		if k > len(data) {
+			k = len(data)
+		}
+		pl = append(pl, chunkPayload(data[:k]))
+		data = data[k:]
+	}
+	tree, err := NewTree(pl)
+	require.Nil(t, err)
+	return tree.MerkleRoot
+}
+
+func TestStreamRootMatchesTree(t *testing.T) {
+	const chunkSize = 16
+	data := make([]byte, 40*chunkSize)
+	_, err := rand.Read(data)
+	require.Nil(t, err)
+
+	for _, size := range []int{1, chunkSize - 1, chunkSize, chunkSize + 1, 3 * chunkSize, 5*chunkSize + 7, 16 * chunkSize, 17 * chunkSize, len(data)} {
+		root, n, err := StreamRoot(bytes.NewReader(data[:size]), chunkSize)
+		require.Nil(t, err)
+		assert.Equal(t, uint64(size), n)
+		assert.Equal(t, treeRoot(t, data[:size], chunkSize), root, "size %d", size)
+	}
+}
+
+func TestStreamerWrites(t *testing.T) {
+	data := make([]byte, 1000)
+	_, err := rand.Read(data)
+	require.Nil(t, err)
+
+	expected, _, err := StreamRoot(bytes.NewReader(data), 64)
+	require.Nil(t, err)
+
+	s, err := NewStreamer(64)
+	require.Nil(t, err)
+	for i := 0; i < len(data); i += 7 {
+		end := i + 7
+		if end > len(data) {
+			end = len(data)
+		}
+		_, err := s.Write(data[i:end])
+		require.Nil(t, err)
+
+		// computing an intermediate root leaves the stream untouched
+		root, err := s.Root()
+		require.Nil(t, err)
+		assert.Equal(t, treeRoot(t, data[:end], 64), root)
+	}
+
+	root, err := s.Root()
+	require.Nil(t, err)
+	assert.Equal(t, expected, root)
+	assert.Equal(t, uint64(16), s.Leaves())
+	assert.Equal(t, uint64(len(data)), s.Size())
+}
+
+func TestStreamRootInvalid(t *testing.T) {
+	_, _, err := StreamRoot(bytes.NewReader(nil), 0)
+	assert.NotNil(t, err)
+
+	_, err = NewStreamer(-1)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,473 @@
+package mlsag
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rangeproof/vector"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// Every challenge of a ring signature is the hash of the commitments of the
+// previous ring member, so Verify has to compute the commitments exactly, one
+// member after the other, and they cannot be folded into a single equation.
+// The signer knows the commitments though: once they are published with the
+// signature, all the challenges are recomputed upfront by hashing, and what
+// is left are linear equations binding the commitments to the responses.
+// The batch verifiers below combine these equations, across all the
+// signatures of a block, with random weights and check them with a single
+// multi-scalar multiplication.
+
+// Commitments are the round commitments of a ring signature: for every ring
+// member, L against its public keys and R against the key images. For a
+// CLSAG signature, every member has a single L and a single R
+type Commitments struct {
+	L [][]ristretto.Point
+	R [][]ristretto.Point
+}
+
+func newCommitments(members int) *Commitments {
+	return &Commitments{
+		L: make([][]ristretto.Point, members),
+		R: make([][]ristretto.Point, members),
+	}
+}
+
+func (c *Commitments) set(i int, L, R []ristretto.Point) {
+	c.L[i] = L
+	c.R[i] = R
+}
+
+// check verifies that there is one set of commitments per ring member
+func (c *Commitments) check(members int) error {
+	if len(c.L) != members || len(c.R) != members {
+		return errors.New("number of commitments must match the number of ring members")
+	}
+	return nil
+}
+
+// Encode the Commitments, prefixed with the number of ring members
+func (c *Commitments) Encode(w io.Writer) error {
+	if len(c.L) != len(c.R) {
+		return errors.New("number of L and R commitments do not match")
+	}
+
+	err := binary.Write(w, binary.BigEndian, uint32(len(c.L)))
+	if err != nil {
+		return err
+	}
+
+	for i := range c.L {
+		err = encodePoints(w, c.L[i])
+		if err != nil {
+			return err
+		}
+		err = encodePoints(w, c.R[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode Commitments
+func (c *Commitments) Decode(r io.Reader) error {
+	if c == nil {
+		return errors.New("struct is nil")
+	}
+
+	var members uint32
+	err := binary.Read(r, binary.BigEndian, &members)
+	if err != nil {
+		return err
+	}
+
+	// members comes from the wire, so the commitments are appended as they
+	// are read
+	c.L, c.R = nil, nil
+	for i := uint32(0); i < members; i++ {
+		L, err := decodePoints(r)
+		if err != nil {
+			return err
+		}
+		R, err := decodePoints(r)
+		if err != nil {
+			return err
+		}
+		c.L = append(c.L, L)
+		c.R = append(c.R, R)
+	}
+	return nil
+}
+
+func encodePoints(w io.Writer, points []ristretto.Point) error {
+	err := binary.Write(w, binary.BigEndian, uint32(len(points)))
+	if err != nil {
+		return err
+	}
+	for i := range points {
+		err = binary.Write(w, binary.BigEndian, points[i].Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodePoints(r io.Reader) ([]ristretto.Point, error) {
+	var n uint32
+	err := binary.Read(r, binary.BigEndian, &n)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []ristretto.Point
+	for i := uint32(0); i < n; i++ {
+		var p ristretto.Point
+		err = readerToPoint(r, &p)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// VerifyBatch verifies MLSAG signatures, sigs[i] against keyImages[i], and
+// rejects the batch if two of them share a key image. The signatures
+// carrying their Commitments are checked together with random weights, the
+// others are verified on their own. Signatures are processed concurrently
+// and ctx is checked between them: once it is cancelled, VerifyBatch stops
+// and returns ctx.Err(). A failed weighted check does not tell which
+// signature is invalid
+func VerifyBatch(ctx context.Context, sigs []*Signature, keyImages [][]ristretto.Point) (bool, error) {
+	if len(sigs) != len(keyImages) {
+		return false, errors.New("number of signatures and key image sets do not match")
+	}
+
+	var all []ristretto.Point
+	for i := range keyImages {
+		all = append(all, keyImages[i]...)
+	}
+	if err := checkKeyImages(all); err != nil {
+		return false, fmt.Errorf("[VerifyBatch] - %s", err)
+	}
+
+	eqs := make([]*equations, len(sigs))
+	ok, err := verifyConcurrently(ctx, len(sigs), func(i int) (bool, error) {
+		if sigs[i] == nil {
+			return false, errors.New("missing signature")
+		}
+		if sigs[i].Commitments == nil {
+			return sigs[i].Verify(keyImages[i])
+		}
+
+		var err error
+		eqs[i], err = sigs[i].equations(keyImages[i])
+		return err == nil, err
+	})
+	if !ok {
+		return ok, err
+	}
+
+	return checkEquations(ctx, eqs)
+}
+
+// VerifyCLSAGBatch verifies CLSAG signatures, sigs[i] against keyImages[i],
+// like VerifyBatch
+func VerifyCLSAGBatch(ctx context.Context, sigs []*CLSAGSignature, keyImages []ristretto.Point) (bool, error) {
+	if len(sigs) != len(keyImages) {
+		return false, errors.New("number of signatures and key images do not match")
+	}
+	if err := checkKeyImages(keyImages); err != nil {
+		return false, fmt.Errorf("[VerifyCLSAGBatch] - %s", err)
+	}
+
+	eqs := make([]*equations, len(sigs))
+	ok, err := verifyConcurrently(ctx, len(sigs), func(i int) (bool, error) {
+		if sigs[i] == nil {
+			return false, errors.New("missing signature")
+		}
+		if sigs[i].Commitments == nil {
+			return sigs[i].Verify(keyImages[i])
+		}
+
+		var err error
+		eqs[i], err = sigs[i].equations(keyImages[i])
+		return err == nil, err
+	})
+	if !ok {
+		return ok, err
+	}
+
+	return checkEquations(ctx, eqs)
+}
+
+// equations recomputes the challenges of the signature from its commitments
+// and returns the weighted equations binding the commitments to the responses
+func (sig *Signature) equations(keyImages []ristretto.Point) (*equations, error) {
+	n := len(sig.r)
+	if n == 0 || len(keyImages) == 0 {
+		return nil, errors.New("cannot have zero length for responses or key images")
+	}
+	if len(sig.PubKeys) != n {
+		return nil, errors.New("number of responses must match the number of ring members")
+	}
+	if err := sig.Commitments.check(n); err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		keys := sig.PubKeys[i].Len()
+		if keys < len(keyImages) || sig.r[i].Len() != keys ||
+			len(sig.Commitments.L[i]) != keys || len(sig.Commitments.R[i]) != len(keyImages) {
+			return nil, fmt.Errorf("ring member %d does not match its responses or commitments", i)
+		}
+	}
+	if err := checkKeyImages(keyImages); err != nil {
+		return nil, err
+	}
+
+	t := ringTranscript("dusk.mlsag", sig.Context, sig.Msg, sig.PubKeys, keyImages)
+
+	c := make([]ristretto.Scalar, n+1)
+	c[0] = sig.c
+	for i := 0; i < n; i++ {
+		c[i+1] = roundChallenge(t, sig.Commitments.L[i], sig.Commitments.R[i])
+	}
+	if !c[n].Equals(&sig.c) {
+		return nil, fmt.Errorf("c'0 does not equal c0, %s != %s", c[n].String(), sig.c.String())
+	}
+
+	e := newEquations()
+
+	// the key images are shared by all the members, so their weights are
+	// summed up before being added
+	images := make([]ristretto.Scalar, len(keyImages))
+	for j := range images {
+		images[j].SetZero()
+	}
+
+	var w, tmp ristretto.Scalar
+	for i := 0; i < n; i++ {
+		keys := sig.PubKeys[i].keys
+		r := sig.r[i]
+
+		// r * G + c * P = L
+		for j := range keys {
+			rng.Scalar(&w)
+			e.addBase(tmp.Mul(&w, &r[j]))
+			e.add(tmp.Mul(&w, &c[i]), &keys[j])
+			e.add(tmp.Neg(&w), &sig.Commitments.L[i][j])
+		}
+
+		// r * H(P) + c * I = R
+		for j := range keyImages {
+			var hP ristretto.Point
+			hP.Derive(keys[j].Bytes())
+
+			rng.Scalar(&w)
+			e.add(tmp.Mul(&w, &r[j]), &hP)
+			images[j].MulAdd(&w, &c[i], &images[j])
+			e.add(tmp.Neg(&w), &sig.Commitments.R[i][j])
+		}
+	}
+
+	for j := range keyImages {
+		e.add(&images[j], &keyImages[j])
+	}
+	return e, nil
+}
+
+// equations recomputes the challenges of the signature from its commitments
+// and returns the weighted equations binding the commitments to the responses
+func (sig *CLSAGSignature) equations(keyImage ristretto.Point) (*equations, error) {
+	n := len(sig.s)
+	if n == 0 || len(sig.PubKeys) != n {
+		return nil, errors.New("number of responses must match the number of ring members")
+	}
+	if err := sig.Commitments.check(n); err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		if sig.PubKeys[i].Len() != 2 {
+			return nil, errors.New("every ring member must have two public keys")
+		}
+		if len(sig.Commitments.L[i]) != 1 || len(sig.Commitments.R[i]) != 1 {
+			return nil, fmt.Errorf("ring member %d must have one L and one R commitment", i)
+		}
+	}
+	if err := checkKeyImages([]ristretto.Point{keyImage}); err != nil {
+		return nil, err
+	}
+
+	muP, muC := clsagAggregation(sig.PubKeys, keyImage, sig.D)
+	t := ringTranscript("dusk.clsag", sig.Context, sig.Msg, sig.PubKeys, []ristretto.Point{keyImage, sig.D})
+
+	c := make([]ristretto.Scalar, n+1)
+	c[0] = sig.c
+	for i := 0; i < n; i++ {
+		c[i+1] = clsagRound(t, sig.Commitments.L[i][0], sig.Commitments.R[i][0])
+	}
+	if !c[n].Equals(&sig.c) {
+		return nil, fmt.Errorf("c'0 does not equal c0, %s != %s", c[n].String(), sig.c.String())
+	}
+
+	e := newEquations()
+
+	// the weights of I and D are summed up over all the members
+	var image, aux ristretto.Scalar
+	image.SetZero()
+	aux.SetZero()
+
+	var w, tmp, cP, cC ristretto.Scalar
+	for i := 0; i < n; i++ {
+		P, C := &sig.PubKeys[i].keys[0], &sig.PubKeys[i].keys[1]
+		cP.Mul(&c[i], &muP)
+		cC.Mul(&c[i], &muC)
+
+		// s * G + c * (muP * P + muC * C) = L
+		rng.Scalar(&w)
+		e.addBase(tmp.Mul(&w, &sig.s[i]))
+		e.add(tmp.Mul(&w, &cP), P)
+		e.add(tmp.Mul(&w, &cC), C)
+		e.add(tmp.Neg(&w), &sig.Commitments.L[i][0])
+
+		// s * H(P) + c * (muP * I + muC * D) = R
+		var hP ristretto.Point
+		hP.Derive(P.Bytes())
+
+		rng.Scalar(&w)
+		e.add(tmp.Mul(&w, &sig.s[i]), &hP)
+		image.MulAdd(&w, &cP, &image)
+		aux.MulAdd(&w, &cC, &aux)
+		e.add(tmp.Neg(&w), &sig.Commitments.R[i][0])
+	}
+
+	e.add(&image, &keyImage)
+	e.add(&aux, &sig.D)
+	return e, nil
+}
+
+// equations holds a sum of weighted equations over points, which is the
+// identity when all of them hold. The base point is kept apart, since
+// multiplying it is cheaper than multiplying an arbitrary point
+type equations struct {
+	base    ristretto.Scalar
+	scalars []ristretto.Scalar
+	points  []ristretto.Point
+}
+
+func newEquations() *equations {
+	e := &equations{}
+	e.base.SetZero()
+	return e
+}
+
+func (e *equations) addBase(s *ristretto.Scalar) {
+	e.base.Add(&e.base, s)
+}
+
+func (e *equations) add(s *ristretto.Scalar, p *ristretto.Point) {
+	e.scalars = append(e.scalars, *s)
+	e.points = append(e.points, *p)
+}
+
+// checkEquations checks the equations of all the signatures of a batch
+// with a single multi-scalar multiplication. Signatures verified on their
+// own leave a nil entry
+func checkEquations(ctx context.Context, eqs []*equations) (bool, error) {
+	all := newEquations()
+	for _, e := range eqs {
+		if e == nil {
+			continue
+		}
+		all.addBase(&e.base)
+		all.scalars = append(all.scalars, e.scalars...)
+		all.points = append(all.points, e.points...)
+	}
+	if len(all.points) == 0 {
+		return true, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	var base ristretto.Point
+	sum := vector.Backend().MultiScalarMult(all.scalars, all.points)
+	base.ScalarMultBase(&all.base)
+	sum.Add(&sum, &base)
+
+	var zero ristretto.Point
+	zero.SetZero()
+	if !sum.Equals(&zero) {
+		return false, errors.New("batch verification failed")
+	}
+	return true, nil
+}
+
+// verifyConcurrently runs verify on 0..n-1 over runtime.NumCPU() workers,
+// and returns the first failure
+func verifyConcurrently(ctx context.Context, n int, verify func(i int) (bool, error)) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		once     sync.Once
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	indices := make(chan int)
+	workers := runtime.NumCPU()
+	if workers > n {
+		workers = n
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				ok, err := verify(i)
+				if err == nil && !ok {
+					err = errors.New("invalid signature")
+				}
+				if err != nil {
+					fail(fmt.Errorf("signature %d: %s", i, err))
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	if firstErr != nil {
+		return false, firstErr
+	}
+	// the parent context may have been cancelled while feeding
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,139 @@
+package mlsag
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyBatch(t *testing.T) {
+	var sigs []*Signature
+	var images [][]ristretto.Point
+	for i := 0; i < 6; i++ {
+		sig, keyImages, err := generateRandProof(4, 2).Prove()
+		require.Nil(t, err)
+		sigs = append(sigs, sig)
+		images = append(images, keyImages)
+	}
+
+	ok, err := VerifyBatch(context.Background(), sigs, images)
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	// one bad signature fails the batch
+	sigs[3].Msg = []byte("tampered")
+	ok, err = VerifyBatch(context.Background(), sigs, images)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ok, err = VerifyBatch(ctx, sigs[:2], images[:2])
+	assert.Equal(t, context.Canceled, err)
+	assert.False(t, ok)
+
+	_, err = VerifyBatch(context.Background(), sigs, images[:2])
+	assert.NotNil(t, err)
+}
+
+func TestVerifyBatchCommitments(t *testing.T) {
+	var sigs []*Signature
+	var images [][]ristretto.Point
+	for i := 0; i < 4; i++ {
+		sig, keyImages, err := generateRandProof(5, 3).Prove()
+		require.Nil(t, err)
+		require.NotNil(t, sig.Commitments)
+		sigs = append(sigs, sig)
+		images = append(images, keyImages)
+	}
+
+	// signatures without commitments are verified on their own
+	sigs[1].Commitments = nil
+	ok, err := VerifyBatch(context.Background(), sigs, images)
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	// a bad response leaves the challenges untouched, only the weighted
+	// equations catch it
+	var one ristretto.Scalar
+	one.SetOne()
+	sigs[2].r[0][0].Add(&sigs[2].r[0][0], &one)
+	ok, err = VerifyBatch(context.Background(), sigs, images)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+	sigs[2].r[0][0].Sub(&sigs[2].r[0][0], &one)
+
+	// a bad commitment changes the challenges
+	sigs[3].Commitments.L[1][0], sigs[3].Commitments.L[2][0] = sigs[3].Commitments.L[2][0], sigs[3].Commitments.L[1][0]
+	ok, err = VerifyBatch(context.Background(), sigs, images)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	sigs[3].Commitments.L = sigs[3].Commitments.L[1:]
+	_, err = VerifyBatch(context.Background(), sigs, images)
+	assert.NotNil(t, err)
+}
+
+func TestCommitmentsEncodeDecode(t *testing.T) {
+	sig, keyImage, err := generateDualKey(4).ProveCLSAG()
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, sig.Commitments.Encode(buf))
+	encoded := append([]byte{}, buf.Bytes()...)
+
+	var decoded Commitments
+	require.Nil(t, decoded.Decode(buf))
+
+	reencoded := &bytes.Buffer{}
+	require.Nil(t, decoded.Encode(reencoded))
+	assert.Equal(t, encoded, reencoded.Bytes())
+
+	sig.Commitments = &decoded
+	ok, err := VerifyCLSAGBatch(context.Background(), []*CLSAGSignature{sig}, []ristretto.Point{keyImage})
+	require.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestVerifyCLSAGBatch(t *testing.T) {
+	var sigs []*CLSAGSignature
+	var images []ristretto.Point
+	for i := 0; i < 6; i++ {
+		sig, keyImage, err := generateDualKey(4).ProveCLSAG()
+		require.Nil(t, err)
+		sigs = append(sigs, sig)
+		images = append(images, keyImage)
+	}
+
+	ok, err := VerifyCLSAGBatch(context.Background(), sigs, images)
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	// a key image spent twice in the batch
+	ok, err = VerifyCLSAGBatch(context.Background(), append(sigs, sigs[0]), append(images, images[0]))
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	images[1], images[2] = images[2], images[1]
+	ok, err = VerifyCLSAGBatch(context.Background(), sigs, images)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+	images[1], images[2] = images[2], images[1]
+
+	var one ristretto.Scalar
+	one.SetOne()
+	sigs[4].s[1].Add(&sigs[4].s[1], &one)
+	ok, err = VerifyCLSAGBatch(context.Background(), sigs, images)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	// verified on its own, the bad signature is still caught
+	sigs[4].Commitments = nil
+	ok, err = VerifyCLSAGBatch(context.Background(), sigs, images)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,284 @@
+package mlsag
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// CLSAGSignature is a Concise Linkable Spontaneous Anonymous Group signature
+// over a ring where every member has two keys: the output key and the
+// commitment to zero. The two layers are aggregated, so the signature only
+// holds one response per member, against two for MLSAG.
+// Only the output key has a key image
+type CLSAGSignature struct {
+	c ristretto.Scalar
+	s []ristretto.Scalar
+	// D is the auxiliary image of the commitment key. It is needed to verify
+	// the signature but must not be used for linking
+	D       ristretto.Point
+	PubKeys []PubKeys
+	Msg     []byte
+	// Context is the transaction context the signature is bound to. Like
+	// Msg, it is not encoded: verifiers set it from the transaction
+	Context []byte
+	// Commitments are the round commitments recorded by the signer, see
+	// Signature
+	Commitments *Commitments
+}
+
+// ProveCLSAG creates a CLSAG signature. The proof must hold exactly two
+// secret keys and every ring member two public keys. It returns the
+// signature and the key image of the first secret key
+func (proof *Proof) ProveCLSAG() (*CLSAGSignature, ristretto.Point, error) {
+	if len(proof.privKeys) != 2 {
+		return nil, ristretto.Point{}, errors.New("clsag needs exactly two secret keys")
+	}
+
+	proof.addSignerPubKey()
+
+	if err := proof.shuffleSet(); err != nil {
+		return nil, ristretto.Point{}, err
+	}
+
+	for i := range proof.pubKeysMatrix {
+		if proof.pubKeysMatrix[i].Len() != 2 {
+			return nil, ristretto.Point{}, errors.New("every ring member must have two public keys")
+		}
+	}
+
+	ring := proof.pubKeysMatrix
+	n := len(ring)
+	l := proof.index
+	p, z := proof.privKeys[0], proof.privKeys[1]
+
+	var hP ristretto.Point
+	hP.Derive(proof.signerPubKeys.keys[0].Bytes())
+
+	// I = p * H(P), D = z * H(P)
+	var I, D ristretto.Point
+	I.ScalarMult(&hP, &p)
+	D.ScalarMult(&hP, &z)
+
+	muP, muC := clsagAggregation(ring, I, D)
+	t := ringTranscript("dusk.clsag", proof.context, proof.msg, ring, []ristretto.Point{I, D})
+
+	// alpha first, then the fake responses
+	scalars := proof.deriveScalars("dusk.clsag", n, []ristretto.Point{I, D})
+	alpha := scalars[0]
+	var aG, aH ristretto.Point
+	aG.ScalarMultBase(&alpha)
+	aH.ScalarMult(&hP, &alpha)
+
+	c := make([]ristretto.Scalar, n)
+	s := make([]ristretto.Scalar, n)
+
+	commitments := newCommitments(n)
+	commitments.set(l, []ristretto.Point{aG}, []ristretto.Point{aH})
+	c[(l+1)%n] = clsagRound(t, aG, aH)
+
+	for k := 1; k < n; k++ {
+		i := (l + k) % n
+		s[i] = scalars[k]
+		L, R := clsagCommitments(ring, i, s[i], c[i], muP, muC, I, D)
+		commitments.set(i, []ristretto.Point{L}, []ristretto.Point{R})
+		c[(i+1)%n] = clsagRound(t, L, R)
+	}
+
+	// s_l = alpha - c_l * (muP * p + muC * z)
+	var w ristretto.Scalar
+	w.Mul(&muP, &p)
+	w.MulAdd(&muC, &z, &w)
+	s[l].Mul(&c[l], &w)
+	s[l].Sub(&alpha, &s[l])
+
+	return &CLSAGSignature{
+		c:           c[0],
+		s:           s,
+		D:           D,
+		PubKeys:     ring,
+		Msg:         proof.msg,
+		Context:     proof.context,
+		Commitments: commitments,
+	}, I, nil
+}
+
+// Verify checks the signature against the key image of the output key
+func (sig *CLSAGSignature) Verify(keyImage ristretto.Point) (bool, error) {
+	n := len(sig.s)
+	if n == 0 || len(sig.PubKeys) != n {
+		return false, errors.New("number of responses must match the number of ring members")
+	}
+
+	for i := range sig.PubKeys {
+		if sig.PubKeys[i].Len() != 2 {
+			return false, errors.New("every ring member must have two public keys")
+		}
+	}
+
+	if err := checkKeyImages([]ristretto.Point{keyImage}); err != nil {
+		return false, err
+	}
+
+	muP, muC := clsagAggregation(sig.PubKeys, keyImage, sig.D)
+	t := ringTranscript("dusk.clsag", sig.Context, sig.Msg, sig.PubKeys, []ristretto.Point{keyImage, sig.D})
+
+	c := sig.c
+	for i := 0; i < n; i++ {
+		c = clsagChallenge(t, sig.PubKeys, i, sig.s[i], c, muP, muC, keyImage, sig.D)
+	}
+
+	if !c.Equals(&sig.c) {
+		return false, fmt.Errorf("c'0 does not equal c0, %s != %s", c.String(), sig.c.String())
+	}
+
+	return true, nil
+}
+
+// clsagChallenge computes the challenge of member i+1 from the response and
+// the challenge of member i
+func clsagChallenge(t *transcript.Transcript, ring []PubKeys, i int, s, c, muP, muC ristretto.Scalar, I, D ristretto.Point) ristretto.Scalar {
+	L, R := clsagCommitments(ring, i, s, c, muP, muC, I, D)
+	return clsagRound(t, L, R)
+}
+
+// clsagCommitments computes the commitments of member i from its response
+// and its challenge
+func clsagCommitments(ring []PubKeys, i int, s, c, muP, muC ristretto.Scalar, I, D ristretto.Point) (ristretto.Point, ristretto.Point) {
+	var L, R, W, tmp, hP ristretto.Point
+
+	// L = s * G + c * (muP * P_i + muC * C_i)
+	W.ScalarMult(&ring[i].keys[0], &muP)
+	tmp.ScalarMult(&ring[i].keys[1], &muC)
+	W.Add(&W, &tmp)
+	W.ScalarMult(&W, &c)
+	L.ScalarMultBase(&s)
+	L.Add(&L, &W)
+
+	// R = s * H(P_i) + c * (muP * I + muC * D)
+	W.ScalarMult(&I, &muP)
+	tmp.ScalarMult(&D, &muC)
+	W.Add(&W, &tmp)
+	W.ScalarMult(&W, &c)
+	hP.Derive(ring[i].keys[0].Bytes())
+	R.ScalarMult(&hP, &s)
+	R.Add(&R, &W)
+
+	return L, R
+}
+
+func clsagRound(t *transcript.Transcript, L, R ristretto.Point) ristretto.Scalar {
+	return roundChallenge(t, []ristretto.Point{L}, []ristretto.Point{R})
+}
+
+// clsagAggregation returns the coefficients aggregating the output keys and
+// the commitment keys
+func clsagAggregation(ring []PubKeys, I, D ristretto.Point) (ristretto.Scalar, ristretto.Scalar) {
+	t := transcript.New("dusk.clsag.aggregation")
+	appendRing(t, ring)
+	t.AppendPoint("I", I)
+	t.AppendPoint("D", D)
+	return t.ChallengeScalar("mu_P"), t.ChallengeScalar("mu_C")
+}
+
+// Encode a CLSAGSignature
+func (sig *CLSAGSignature) Encode(w io.Writer, encodeKeys bool) error {
+	err := binary.Write(w, binary.BigEndian, sig.c.Bytes())
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.BigEndian, uint32(len(sig.s)))
+	if err != nil {
+		return err
+	}
+
+	for i := range sig.s {
+		err = binary.Write(w, binary.BigEndian, sig.s[i].Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	err = binary.Write(w, binary.BigEndian, sig.D.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if !encodeKeys {
+		return nil
+	}
+
+	for i := range sig.PubKeys {
+		err = sig.PubKeys[i].Encode(w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode a CLSAGSignature
+func (sig *CLSAGSignature) Decode(r io.Reader, decodeKeys bool) error {
+	if sig == nil {
+		return errors.New("struct is nil")
+	}
+
+	err := readerToScalar(r, &sig.c)
+	if err != nil {
+		return err
+	}
+
+	var lenS uint32
+	err = binary.Read(r, binary.BigEndian, &lenS)
+	if err != nil {
+		return err
+	}
+
+	// lenS comes from the wire, so the responses are appended as they are read
+	sig.s = nil
+	for i := uint32(0); i < lenS; i++ {
+		var s ristretto.Scalar
+		err = readerToScalar(r, &s)
+		if err != nil {
+			return err
+		}
+		sig.s = append(sig.s, s)
+	}
+
+	err = readerToPoint(r, &sig.D)
+	if err != nil {
+		return err
+	}
+
+	if !decodeKeys {
+		return nil
+	}
+
+	sig.PubKeys = make([]PubKeys, lenS)
+	for i := range sig.PubKeys {
+		err = sig.PubKeys[i].Decode(r, 2)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProveCLSAG creates a CLSAG signature with the primary key and the
+// commitment to zero
+func (d *DualKey) ProveCLSAG() (*CLSAGSignature, ristretto.Point, error) {
+
+	if (d.dualkeys[0].IsNonZeroI() == 0) || (d.dualkeys[1].IsNonZeroI() == 0) {
+		return nil, ristretto.Point{}, errors.New("primary key or commitment to zero cannot be zero")
+	}
+
+	d.AddSecret(d.dualkeys[0])
+	d.AddSecret(d.dualkeys[1])
+
+	return d.Proof.ProveCLSAG()
+}
@@ -0,0 +1,100 @@
+package mlsag
+
+import (
+	"bytes"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateDualKey(numDecoys int) *DualKey {
+	dk := NewDualKey()
+
+	var primary, commToZero ristretto.Scalar
+	primary.Rand()
+	commToZero.Rand()
+	dk.SetPrimaryKey(primary)
+	dk.SetCommToZero(commToZero)
+	dk.SetMsg([]byte("hello world"))
+	dk.AddDecoys(generateDecoys(numDecoys, 2))
+
+	return dk
+}
+
+func TestCLSAGProveVerify(t *testing.T) {
+	dk := generateDualKey(10)
+
+	sig, keyImage, err := dk.ProveCLSAG()
+	require.Nil(t, err)
+	assert.Len(t, sig.s, 11)
+
+	ok, err := sig.Verify(keyImage)
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	// the key image is the same as the one of an MLSAG signature
+	assert.True(t, keyImage.Equals(&dk.calculateKeyImages(true)[0]))
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, sig.Encode(buf, true))
+	var decoded CLSAGSignature
+	require.Nil(t, decoded.Decode(buf, true))
+	decoded.Msg = sig.Msg
+	ok, err = decoded.Verify(keyImage)
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	sig.Msg = []byte("something random")
+	ok, err = sig.Verify(keyImage)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
+
+func TestCLSAGWrongKeyImage(t *testing.T) {
+	sig, _, err := generateDualKey(4).ProveCLSAG()
+	require.Nil(t, err)
+
+	var other ristretto.Point
+	other.Rand()
+	ok, err := sig.Verify(other)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
+
+func TestVersionedSignature(t *testing.T) {
+	mlsagSig, mlsagKeyImage, err := generateDualKey(4).Prove()
+	require.Nil(t, err)
+	clsagSig, clsagKeyImage, err := generateDualKey(4).ProveCLSAG()
+	require.Nil(t, err)
+
+	sigs := []VersionedSignature{
+		{Version: VersionMLSAG, MLSAG: mlsagSig},
+		{Version: VersionCLSAG, CLSAG: clsagSig},
+	}
+	keyImages := []ristretto.Point{mlsagKeyImage, clsagKeyImage}
+
+	for i := range sigs {
+		buf := &bytes.Buffer{}
+		require.Nil(t, sigs[i].Encode(buf, true))
+
+		var decoded VersionedSignature
+		require.Nil(t, decoded.Decode(buf, true))
+		assert.Equal(t, sigs[i].Version, decoded.Version)
+
+		if decoded.MLSAG != nil {
+			decoded.MLSAG.Msg = mlsagSig.Msg
+		} else {
+			decoded.CLSAG.Msg = clsagSig.Msg
+		}
+
+		ok, err := decoded.Verify(keyImages[i])
+		require.Nil(t, err)
+		assert.True(t, ok)
+	}
+
+	unknown := VersionedSignature{Version: 7}
+	_, err = unknown.Verify(mlsagKeyImage)
+	assert.NotNil(t, err)
+}
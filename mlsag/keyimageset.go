@@ -0,0 +1,129 @@
+package mlsag
+
+import (
+	"errors"
+	"sync"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// ErrDoubleSpend is returned when a key image has already been spent
+var ErrDoubleSpend = errors.New("key image has already been spent")
+
+// KeyImageStore persists spent key images. Implementations can be backed by
+// the node's database; MemoryStore keeps them in memory
+type KeyImageStore interface {
+	// Has returns true if the key image is stored
+	Has(keyImage [32]byte) (bool, error)
+	// Put stores all the key images, or none of them if it fails
+	Put(keyImages [][32]byte) error
+}
+
+// MemoryStore is a KeyImageStore keeping the key images in a map
+type MemoryStore struct {
+	keyImages map[[32]byte]struct{}
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keyImages: make(map[[32]byte]struct{})}
+}
+
+// Has implements KeyImageStore
+func (m *MemoryStore) Has(keyImage [32]byte) (bool, error) {
+	_, ok := m.keyImages[keyImage]
+	return ok, nil
+}
+
+// Put implements KeyImageStore
+func (m *MemoryStore) Put(keyImages [][32]byte) error {
+	for _, k := range keyImages {
+		m.keyImages[k] = struct{}{}
+	}
+	return nil
+}
+
+// KeyImageSet tracks the key images of the verified signatures, to reject
+// double spends. It is safe for concurrent use, provided the store is only
+// written through the set
+type KeyImageSet struct {
+	lock  sync.RWMutex
+	store KeyImageStore
+}
+
+// NewKeyImageSet returns a KeyImageSet persisting the key images in store.
+// If store is nil, the key images are kept in memory
+func NewKeyImageSet(store KeyImageStore) *KeyImageSet {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &KeyImageSet{store: store}
+}
+
+// Add marks the key image as spent. It returns ErrDoubleSpend if it already was
+func (s *KeyImageSet) Add(keyImage ristretto.Point) error {
+	return s.AddBatch([]ristretto.Point{keyImage})
+}
+
+// AddBatch marks all the key images as spent, e.g. the key images of a block.
+// If any of them was already spent, or appears twice in the batch, none of
+// them is added and ErrDoubleSpend is returned
+func (s *KeyImageSet) AddBatch(keyImages []ristretto.Point) error {
+	keys := make([][32]byte, len(keyImages))
+	seen := make(map[[32]byte]struct{}, len(keyImages))
+	for i := range keyImages {
+		keyImages[i].BytesInto(&keys[i])
+		if _, ok := seen[keys[i]]; ok {
+			return ErrDoubleSpend
+		}
+		seen[keys[i]] = struct{}{}
+	}
+
+	// the batch has no duplicates, so this only rejects the identity
+	if err := checkKeyImages(keyImages); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i := range keys {
+		spent, err := s.store.Has(keys[i])
+		if err != nil {
+			return err
+		}
+		if spent {
+			return ErrDoubleSpend
+		}
+	}
+
+	return s.store.Put(keys)
+}
+
+// Contains returns true if the key image has been spent
+func (s *KeyImageSet) Contains(keyImage ristretto.Point) (bool, error) {
+	res, err := s.ContainsBatch([]ristretto.Point{keyImage})
+	if err != nil {
+		return false, err
+	}
+	return res[0], nil
+}
+
+// ContainsBatch tells for every key image whether it has been spent
+func (s *KeyImageSet) ContainsBatch(keyImages []ristretto.Point) ([]bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	res := make([]bool, len(keyImages))
+	for i := range keyImages {
+		var key [32]byte
+		keyImages[i].BytesInto(&key)
+
+		spent, err := s.store.Has(key)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = spent
+	}
+	return res, nil
+}
@@ -0,0 +1,58 @@
+package mlsag
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomKeyImages(n int) []ristretto.Point {
+	keyImages := make([]ristretto.Point, n)
+	for i := range keyImages {
+		keyImages[i].Rand()
+	}
+	return keyImages
+}
+
+func TestKeyImageSet(t *testing.T) {
+	s := NewKeyImageSet(nil)
+	keyImages := randomKeyImages(3)
+
+	require.Nil(t, s.Add(keyImages[0]))
+	assert.Equal(t, ErrDoubleSpend, s.Add(keyImages[0]))
+
+	ok, err := s.Contains(keyImages[0])
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	// the batch is rejected as a whole
+	assert.Equal(t, ErrDoubleSpend, s.AddBatch(keyImages))
+	assert.Equal(t, ErrDoubleSpend, s.AddBatch([]ristretto.Point{keyImages[1], keyImages[1]}))
+
+	res, err := s.ContainsBatch(keyImages)
+	require.Nil(t, err)
+	assert.Equal(t, []bool{true, false, false}, res)
+
+	require.Nil(t, s.AddBatch(keyImages[1:]))
+	res, err = s.ContainsBatch(keyImages)
+	require.Nil(t, err)
+	assert.Equal(t, []bool{true, true, true}, res)
+
+	var identity ristretto.Point
+	identity.SetZero()
+	assert.NotNil(t, s.Add(identity))
+}
+
+func TestKeyImageSetStore(t *testing.T) {
+	store := NewMemoryStore()
+	keyImages := randomKeyImages(2)
+
+	require.Nil(t, NewKeyImageSet(store).AddBatch(keyImages))
+
+	// a new set over the same store sees the spent key images
+	ok, err := NewKeyImageSet(store).Contains(keyImages[1])
+	require.Nil(t, err)
+	assert.True(t, ok)
+}
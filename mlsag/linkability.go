@@ -0,0 +1,40 @@
+package mlsag
+
+import (
+	"errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// Linked returns true if the two sets of key images share a key image, i.e.
+// if the signatures they belong to were made with the same secret key.
+// A node uses it to detect double spends
+func Linked(a, b []ristretto.Point) bool {
+	for i := range a {
+		for j := range b {
+			if a[i].Equals(&b[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkKeyImages rejects the identity key image, which does not belong to
+// any secret key, and duplicate key images within a signature
+func checkKeyImages(keyImages []ristretto.Point) error {
+	var identity ristretto.Point
+	identity.SetZero()
+
+	for i := range keyImages {
+		if keyImages[i].Equals(&identity) {
+			return errors.New("key image is the identity point")
+		}
+		for j := i + 1; j < len(keyImages); j++ {
+			if keyImages[i].Equals(&keyImages[j]) {
+				return errors.New("duplicate key image")
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package mlsag
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveLinked(t *testing.T) {
+	privKeys := generatePrivKeys(2)
+
+	sign := func(msg string) (*Signature, []ristretto.Point) {
+		proof := &Proof{}
+		proof.AddDecoys(generateDecoys(5, 2))
+		for i := range privKeys {
+			proof.AddSecret(privKeys[i])
+		}
+		proof.SetMsg([]byte(msg))
+
+		sig, keyImages, err := proof.Prove()
+		require.Nil(t, err)
+		require.Len(t, keyImages, 2)
+
+		ok, err := sig.Verify(keyImages)
+		require.Nil(t, err)
+		require.True(t, ok)
+		return sig, keyImages
+	}
+
+	_, ki1 := sign("first spend")
+	_, ki2 := sign("second spend")
+	assert.True(t, Linked(ki1, ki2))
+
+	other := generateRandProof(6, 2)
+	_, ki3, err := other.Prove()
+	require.Nil(t, err)
+	assert.False(t, Linked(ki1, ki3))
+}
+
+func TestVerifyRejectsBadKeyImages(t *testing.T) {
+	proof := generateRandProof(4, 2)
+	sig, keyImages, err := proof.Prove()
+	require.Nil(t, err)
+
+	var identity ristretto.Point
+	identity.SetZero()
+	ok, err := sig.Verify([]ristretto.Point{identity, keyImages[1]})
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	ok, err = sig.Verify([]ristretto.Point{keyImages[0], keyImages[0]})
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	_, _, err = (&Proof{}).Prove()
+	assert.NotNil(t, err)
+}
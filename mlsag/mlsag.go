@@ -1,13 +1,18 @@
+// Package mlsag implements Multilayered Linkable Spontaneous Anonymous Group
+// signatures over Ristretto. A signer proves knowledge of a column of secret
+// keys in a matrix of public keys without revealing which column is theirs.
+// Every secret key yields a key image, which is the same for any signature
+// made with that key: two signatures sharing a key image spend the same output
 package mlsag
 
 import (
-	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 
 	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/transcript"
 )
 
 type Signature struct {
@@ -15,6 +20,13 @@ type Signature struct {
 	r       []Responses
 	PubKeys []PubKeys
 	Msg     []byte
+	// Context is the transaction context the signature is bound to. Like
+	// Msg, it is not encoded: verifiers set it from the transaction
+	Context []byte
+	// Commitments are the round commitments recorded by the signer. They are
+	// encoded separately and let VerifyBatch check the signature together
+	// with others
+	Commitments *Commitments
 }
 
 func (s *Signature) Encode(w io.Writer, encodeKeys bool) error {
@@ -158,51 +170,42 @@ func (proof *Proof) prove(skipLastKeyImage bool) (*Signature, []ristretto.Point,
 	}
 
 	keyImages := proof.calculateKeyImages(skipLastKeyImage)
-	nonces := generateNonces(len(proof.privKeys))
 
 	numUsers := len(proof.pubKeysMatrix)
 	numKeysPerUser := len(proof.privKeys)
 
+	// the signer's nonces come first, then the fake responses
+	scalars := proof.deriveScalars("dusk.mlsag", numUsers*numKeysPerUser, keyImages)
+	nonces := scalars[:numKeysPerUser]
+
 	// We will overwrite the signers responses
-	responses := generateResponses(numUsers, numKeysPerUser, proof.index)
+	responses := generateResponses(numUsers, numKeysPerUser, proof.index, scalars[numKeysPerUser:])
 
 	// Let secretIndex = index of signer
 	secretIndex := proof.index
 
 	// Generate C_{secretIndex+1}
-	buf := &bytes.Buffer{}
-	buf.Write(proof.msg)
+	t := ringTranscript("dusk.mlsag", proof.context, proof.msg, proof.pubKeysMatrix, keyImages)
 	signersPubKeys := proof.pubKeysMatrix[secretIndex]
 
+	L := make([]ristretto.Point, len(nonces))
 	for i := 0; i < len(nonces); i++ {
-
-		nonce := nonces[i]
-
-		// P = nonce * G
-		var P ristretto.Point
-		P.ScalarMultBase(&nonce)
-		_, err = buf.Write(P.Bytes())
-		if err != nil {
-			return nil, nil, err
-		}
+		// L = nonce * G
+		L[i].ScalarMultBase(&nonces[i])
 	}
 
+	R := make([]ristretto.Point, len(keyImages))
 	for i := 0; i < len(keyImages); i++ {
-
-		nonce := nonces[i]
-
-		// P = nonce * H(K)
-		var P, hK ristretto.Point
+		// R = nonce * H(K)
+		var hK ristretto.Point
 		hK.Derive(signersPubKeys.keys[i].Bytes())
-		P.ScalarMult(&hK, &nonce)
-		_, err = buf.Write(P.Bytes())
-		if err != nil {
-			return nil, nil, err
-		}
+		R[i].ScalarMult(&hK, &nonces[i])
 	}
 
-	var CjPlusOne ristretto.Scalar
-	CjPlusOne.Derive(buf.Bytes())
+	commitments := newCommitments(numUsers)
+	commitments.set(secretIndex, L, R)
+
+	CjPlusOne := roundChallenge(t, L, R)
 
 	// generate challenges
 	challenges := make([]ristretto.Scalar, numUsers)
@@ -221,10 +224,9 @@ func (proof *Proof) prove(skipLastKeyImage bool) (*Signature, []ristretto.Point,
 		fakeResponses := responses[prevIndex]
 		decoyPubKeys := proof.pubKeysMatrix[prevIndex]
 
-		c, err := generateChallenge(proof.msg, fakeResponses, keyImages, decoyPubKeys, prevChallenge)
-		if err != nil {
-			return nil, nil, err
-		}
+		L, R := roundCommitments(fakeResponses, keyImages, decoyPubKeys, prevChallenge)
+		commitments.set(prevIndex, L, R)
+		c := roundChallenge(t, L, R)
 
 		challenges[i].Set(&c)
 		prevChallenge.Set(&c)
@@ -249,10 +251,12 @@ func (proof *Proof) prove(skipLastKeyImage bool) (*Signature, []ristretto.Point,
 	responses[proof.index] = realResponse
 
 	sig := &Signature{
-		c:       challenges[0],
-		r:       responses,
-		PubKeys: proof.pubKeysMatrix,
-		Msg:     proof.msg,
+		c:           challenges[0],
+		r:           responses,
+		PubKeys:     proof.pubKeysMatrix,
+		Msg:         proof.msg,
+		Context:     proof.context,
+		Commitments: commitments,
 	}
 
 	return sig, keyImages, nil
@@ -264,9 +268,18 @@ func (sig *Signature) Verify(keyImages []ristretto.Point) (bool, error) {
 		return false, errors.New("cannot have zero length for responses, pubkeys or key images")
 	}
 
+	if err := checkKeyImages(keyImages); err != nil {
+		return false, err
+	}
+
 	numUsers := len(sig.r)
 	index := 0
 
+	if len(sig.PubKeys) != numUsers {
+		return false, errors.New("number of responses must match the number of ring members")
+	}
+	t := ringTranscript("dusk.mlsag", sig.Context, sig.Msg, sig.PubKeys, keyImages)
+
 	var prevChallenge = sig.c
 
 	for k := index + 1; k != (index)%numUsers; k = (k + 1) % numUsers {
@@ -278,11 +291,7 @@ func (sig *Signature) Verify(keyImages []ristretto.Point) (bool, error) {
 
 		fakeResponses := sig.r[prevIndex]
 		decoyPubKeys := sig.PubKeys[prevIndex]
-		challenge, err := generateChallenge(sig.Msg, fakeResponses, keyImages, decoyPubKeys, prevChallenge)
-		if err != nil {
-			return false, err
-		}
-		prevChallenge = challenge
+		prevChallenge = generateChallenge(t, fakeResponses, keyImages, decoyPubKeys, prevChallenge)
 	}
 
 	// Calculate c'
@@ -293,10 +302,7 @@ func (sig *Signature) Verify(keyImages []ristretto.Point) (bool, error) {
 	fakeResponses := sig.r[prevIndex]
 	decoyPubKeys := sig.PubKeys[prevIndex]
 
-	challenge, err := generateChallenge(sig.Msg, fakeResponses, keyImages, decoyPubKeys, prevChallenge)
-	if err != nil {
-		return false, err
-	}
+	challenge := generateChallenge(t, fakeResponses, keyImages, decoyPubKeys, prevChallenge)
 
 	if !challenge.Equals(&sig.c) {
 		return false, fmt.Errorf("c'0 does not equal c0, %s != %s", challenge.String(), sig.c.String())
@@ -305,21 +311,11 @@ func (sig *Signature) Verify(keyImages []ristretto.Point) (bool, error) {
 	return true, nil
 }
 
-func generateNonces(n int) []ristretto.Scalar {
-	var nonces []ristretto.Scalar
-	for i := 0; i < n; i++ {
-		var nonce ristretto.Scalar
-		nonce.Rand()
-		nonces = append(nonces, nonce)
-	}
-	return nonces
-}
-
 // XXX: Test should check that random numbers are not all zero
 //A bug in ristretto lib that may not be fixed
 // Check the same for points too
-// skip skips the singers responses
-func generateResponses(m int, n, skip int) []Responses {
+// skip skips the singers responses, fake holds the (m-1)*n fake ones
+func generateResponses(m int, n, skip int, fake []ristretto.Scalar) []Responses {
 	var matrixResponses []Responses
 	for i := 0; i < m; i++ {
 		if i == skip {
@@ -328,64 +324,59 @@ func generateResponses(m int, n, skip int) []Responses {
 		}
 		var resp Responses
 		for i := 0; i < n; i++ {
-			var r ristretto.Scalar
-			r.Rand()
-			resp.AddResponse(r)
+			resp.AddResponse(fake[0])
+			fake = fake[1:]
 		}
 		matrixResponses = append(matrixResponses, resp)
 	}
 	return matrixResponses
 }
 
+// generateChallenge computes the challenge of the next ring member from the
+// responses of pubKeys and their challenge, prevChallenge
 func generateChallenge(
-	msg []byte,
+	t *transcript.Transcript,
 	respsonses Responses,
 	keyImages []ristretto.Point,
 	pubKeys PubKeys,
-	prevChallenge ristretto.Scalar) (ristretto.Scalar, error) {
+	prevChallenge ristretto.Scalar) ristretto.Scalar {
 
-	buf := &bytes.Buffer{}
-	_, err := buf.Write(msg)
-	if err != nil {
-		return ristretto.Scalar{}, err
-	}
+	L, R := roundCommitments(respsonses, keyImages, pubKeys, prevChallenge)
+	return roundChallenge(t, L, R)
+}
 
-	for i := 0; i < pubKeys.Len(); i++ {
+// roundCommitments computes the commitments of a ring member from its
+// responses and its challenge, prevChallenge
+func roundCommitments(
+	respsonses Responses,
+	keyImages []ristretto.Point,
+	pubKeys PubKeys,
+	prevChallenge ristretto.Scalar) ([]ristretto.Point, []ristretto.Point) {
 
+	L := make([]ristretto.Point, pubKeys.Len())
+	for i := 0; i < pubKeys.Len(); i++ {
 		r := respsonses[i]
 
-		// P = r * G + c * PubKey
-		var P, cK ristretto.Point
-		P.ScalarMultBase(&r)
+		// L = r * G + c * PubKey
+		var cK ristretto.Point
+		L[i].ScalarMultBase(&r)
 		cK.ScalarMult(&pubKeys.keys[i], &prevChallenge)
-		P.Add(&P, &cK)
-		_, err = buf.Write(P.Bytes())
-		if err != nil {
-			return ristretto.Scalar{}, err
-		}
-
+		L[i].Add(&L[i], &cK)
 	}
 
+	R := make([]ristretto.Point, len(keyImages))
 	for i := 0; i < len(keyImages); i++ {
 		r := respsonses[i]
 
-		// P = r * H(K) + c * Ki
-		var P, cK ristretto.Point
-		var hK ristretto.Point
+		// R = r * H(K) + c * Ki
+		var cK, hK ristretto.Point
 		hK.Derive(pubKeys.keys[i].Bytes())
-		P.ScalarMult(&hK, &r)
+		R[i].ScalarMult(&hK, &r)
 		cK.ScalarMult(&keyImages[i], &prevChallenge)
-		P.Add(&P, &cK)
-		_, err = buf.Write(P.Bytes())
-		if err != nil {
-			return ristretto.Scalar{}, err
-		}
+		R[i].Add(&R[i], &cK)
 	}
 
-	var challenge ristretto.Scalar
-	challenge.Derive(buf.Bytes())
-
-	return challenge, nil
+	return L, R
 }
 
 func (proof *Proof) calculateKeyImages(skipLastKeyImage bool) []ristretto.Point {
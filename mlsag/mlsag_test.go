@@ -38,10 +38,24 @@ func encodeDecodeSig(t *testing.T, sig *Signature, includeKeys bool) {
 	assert.True(t, ok)
 }
 func TestGenNonces(t *testing.T) {
+	proof := generateRandProof(3, 2)
+	proof.addSignerPubKey()
 	for i := 1; i < 20; i++ {
-		nonces := generateNonces(i)
+		nonces := proof.deriveScalars("dusk.mlsag", i, nil)
 		assert.Equal(t, i, len(nonces))
 	}
+
+	// the nonces depend on the message, the ring and the key images
+	nonces := proof.deriveScalars("dusk.mlsag", 1, nil)
+	again := proof.deriveScalars("dusk.mlsag", 1, nil)
+	assert.True(t, nonces[0].Equals(&again[0]))
+
+	images := proof.deriveScalars("dusk.mlsag", 1, proof.calculateKeyImages(false))
+	assert.False(t, nonces[0].Equals(&images[0]))
+
+	proof.SetMsg([]byte("another message"))
+	msg := proof.deriveScalars("dusk.mlsag", 1, nil)
+	assert.False(t, nonces[0].Equals(&msg[0]))
 }
 
 func TestShuffleSet(t *testing.T) {
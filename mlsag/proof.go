@@ -2,12 +2,14 @@ package mlsag
 
 import (
 	"errors"
-	"math/rand"
-	"time"
+	"math/big"
 
 	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/nonce"
+	"github.com/dusk-network/dusk-crypto/rng"
 )
 
+// Proof holds the ring being signed over and the signer's secret keys
 type Proof struct {
 	// index indicating the column of the secret keys
 	index int
@@ -26,6 +28,9 @@ type Proof struct {
 
 	// message to be signed
 	msg []byte
+
+	// transaction context the signature is bound to
+	context []byte
 }
 
 func (p *Proof) addPubKeys(keys PubKeys) {
@@ -33,6 +38,28 @@ func (p *Proof) addPubKeys(keys PubKeys) {
 	p.pubKeysMatrix = append(p.pubKeysMatrix, keys)
 }
 
+// SetMsg sets the message to be signed
+func (p *Proof) SetMsg(msg []byte) {
+	p.msg = msg
+}
+
+// SetContext binds the signature to the transaction it belongs to, e.g. the
+// hash of its inputs and outputs. A signature only verifies with the same
+// context, so it cannot be transplanted into another transaction over the
+// same ring
+func (p *Proof) SetContext(context []byte) {
+	p.context = context
+}
+
+// Prove signs the message with a key image for every secret key. The
+// signer's keys are placed at a random position of the ring
+func (p *Proof) Prove() (*Signature, []ristretto.Point, error) {
+	if len(p.privKeys) == 0 {
+		return nil, nil, errors.New("no secret keys were added to the proof")
+	}
+	return p.prove(false)
+}
+
 func (p *Proof) AddDecoy(keys PubKeys) {
 	keys.decoy = true
 	p.addPubKeys(keys)
@@ -69,10 +96,15 @@ func privKeyToPubKey(privkey ristretto.Scalar) ristretto.Point {
 
 // shuffle all pubkeys and sets the index
 func (p *Proof) shuffleSet() error {
-	r := rand.New(rand.NewSource(time.Now().Unix()))
+	// the position of the signer must not be predictable, so the shuffle
+	// uses a cryptographically secure source
 	for i := len(p.pubKeysMatrix) - 1; i > 0; i-- {
-		j := r.Intn(i + 1)
-		p.pubKeysMatrix[i], p.pubKeysMatrix[j] = p.pubKeysMatrix[j], p.pubKeysMatrix[i]
+		j, err := rng.Int(big.NewInt(int64(i + 1)))
+		if err != nil {
+			return err
+		}
+		k := int(j.Int64())
+		p.pubKeysMatrix[i], p.pubKeysMatrix[k] = p.pubKeysMatrix[k], p.pubKeysMatrix[i]
 	}
 	// XXX: Optimise away the below for loop by storing the index when appended
 	// and following it in the first loop. We can also get rid of the decoy flag too
@@ -93,3 +125,27 @@ func (p *Proof) shuffleSet() error {
 func (p *Proof) LenMembers() int {
 	return len(p.pubKeysMatrix)
 }
+
+// deriveScalars derives the n nonces and fake responses of a signature from
+// the secret keys, the message, the shuffled ring and the key images, see
+// the nonce package. Any change of the challenges changes them as well
+func (p *Proof) deriveScalars(domain string, n int, images []ristretto.Point) []ristretto.Scalar {
+	var secret []byte
+	for _, k := range p.privKeys {
+		secret = append(secret, k.Bytes()...)
+	}
+
+	var ring []byte
+	for _, member := range p.pubKeysMatrix {
+		for _, k := range member.keys {
+			ring = append(ring, k.Bytes()...)
+		}
+	}
+
+	var imageBytes []byte
+	for _, I := range images {
+		imageBytes = append(imageBytes, I.Bytes()...)
+	}
+
+	return nonce.Scalars(domain, secret, n, p.context, p.msg, ring, imageBytes)
+}
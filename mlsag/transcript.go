@@ -0,0 +1,45 @@
+package mlsag
+
+import (
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// ringTranscript returns the transcript every challenge of a signature is
+// derived from. It binds the scheme, the transaction context, the message,
+// the whole ring and the key images, so that a signature verifies in no
+// other transaction, even over an identical ring
+func ringTranscript(domain string, context, msg []byte, ring []PubKeys, keyImages []ristretto.Point) *transcript.Transcript {
+	t := transcript.New(domain)
+	t.Append("context", context)
+	t.Append("msg", msg)
+	appendRing(t, ring)
+	t.AppendUint64("key images", uint64(len(keyImages)))
+	for _, I := range keyImages {
+		t.AppendPoint("I", I)
+	}
+	return t
+}
+
+func appendRing(t *transcript.Transcript, ring []PubKeys) {
+	t.AppendUint64("members", uint64(len(ring)))
+	for i := range ring {
+		t.AppendUint64("keys", uint64(ring[i].Len()))
+		for _, k := range ring[i].keys {
+			t.AppendPoint("P", k)
+		}
+	}
+}
+
+// roundChallenge derives the challenge following a ring member from its
+// commitments, L against the public keys and R against the key images
+func roundChallenge(t *transcript.Transcript, L, R []ristretto.Point) ristretto.Scalar {
+	t = t.Clone()
+	for i := range L {
+		t.AppendPoint("L", L[i])
+	}
+	for i := range R {
+		t.AppendPoint("R", R[i])
+	}
+	return t.ChallengeScalar("c")
+}
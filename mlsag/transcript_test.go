@@ -0,0 +1,40 @@
+package mlsag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextBinding(t *testing.T) {
+	proof := generateRandProof(5, 2)
+	proof.SetContext([]byte("tx 1"))
+	sig, keyImages, err := proof.Prove()
+	require.Nil(t, err)
+
+	ok, err := sig.Verify(keyImages)
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	// the same ring and message in another transaction
+	sig.Context = []byte("tx 2")
+	ok, _ = sig.Verify(keyImages)
+	assert.False(t, ok)
+	sig.Context = nil
+	ok, _ = sig.Verify(keyImages)
+	assert.False(t, ok)
+
+	dk := generateDualKey(4)
+	dk.SetContext([]byte("tx 1"))
+	clsag, keyImage, err := dk.ProveCLSAG()
+	require.Nil(t, err)
+
+	ok, err = clsag.Verify(keyImage)
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	clsag.Context = []byte("tx 2")
+	ok, _ = clsag.Verify(keyImage)
+	assert.False(t, ok)
+}
@@ -0,0 +1,96 @@
+package mlsag
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// Version identifies the scheme of a VersionedSignature
+type Version uint8
+
+const (
+	// VersionMLSAG is a dual key MLSAG signature
+	VersionMLSAG Version = iota
+	// VersionCLSAG is a CLSAG signature
+	VersionCLSAG
+)
+
+// VersionedSignature carries either a dual key MLSAG signature or a CLSAG
+// signature, so that verifiers accept both while the network migrates to CLSAG
+type VersionedSignature struct {
+	Version Version
+	MLSAG   *Signature
+	CLSAG   *CLSAGSignature
+}
+
+// Verify checks the signature against the key image of the output key,
+// whatever its version
+func (v *VersionedSignature) Verify(keyImage ristretto.Point) (bool, error) {
+	switch v.Version {
+	case VersionMLSAG:
+		if v.MLSAG == nil {
+			return false, errors.New("missing mlsag signature")
+		}
+		return v.MLSAG.Verify([]ristretto.Point{keyImage})
+	case VersionCLSAG:
+		if v.CLSAG == nil {
+			return false, errors.New("missing clsag signature")
+		}
+		return v.CLSAG.Verify(keyImage)
+	default:
+		return false, fmt.Errorf("unknown signature version %d", v.Version)
+	}
+}
+
+// Encode a VersionedSignature, prefixing the signature with its version
+func (v *VersionedSignature) Encode(w io.Writer, encodeKeys bool) error {
+	err := binary.Write(w, binary.BigEndian, uint8(v.Version))
+	if err != nil {
+		return err
+	}
+
+	switch v.Version {
+	case VersionMLSAG:
+		if v.MLSAG == nil {
+			return errors.New("missing mlsag signature")
+		}
+		return v.MLSAG.Encode(w, encodeKeys)
+	case VersionCLSAG:
+		if v.CLSAG == nil {
+			return errors.New("missing clsag signature")
+		}
+		return v.CLSAG.Encode(w, encodeKeys)
+	default:
+		return fmt.Errorf("unknown signature version %d", v.Version)
+	}
+}
+
+// Decode a VersionedSignature
+func (v *VersionedSignature) Decode(r io.Reader, decodeKeys bool) error {
+	if v == nil {
+		return errors.New("struct is nil")
+	}
+
+	var version uint8
+	err := binary.Read(r, binary.BigEndian, &version)
+	if err != nil {
+		return err
+	}
+	v.Version = Version(version)
+	v.MLSAG, v.CLSAG = nil, nil
+
+	switch v.Version {
+	case VersionMLSAG:
+		v.MLSAG = &Signature{}
+		return v.MLSAG.Decode(r, decodeKeys)
+	case VersionCLSAG:
+		v.CLSAG = &CLSAGSignature{}
+		return v.CLSAG.Decode(r, decodeKeys)
+	default:
+		return fmt.Errorf("unknown signature version %d", v.Version)
+	}
+}
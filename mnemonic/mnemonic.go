@@ -0,0 +1,202 @@
+// Package mnemonic implements BIP39 mnemonic sentences: the encoding of
+// entropy as a list of words with a checksum, and the derivation of a seed
+// from a mnemonic and an optional passphrase. The seed is the root of the
+// BLS and Ristretto key hierarchies of a wallet.
+//
+// Only the word lists made of ASCII words are provided, since the others
+// need Unicode normalization to produce the same seed as other
+// implementations. For the same reason passphrases must be ASCII
+package mnemonic
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"strings"
+
+	"github.com/dusk-network/dusk-crypto/rng"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// SeedSize is the size of the seed derived from a mnemonic
+const SeedSize = 64
+
+const (
+	bitsPerWord     = 11
+	seedIterations  = 2048
+	minEntropyBits  = 128
+	maxEntropyBits  = 256
+	entropyBitsStep = 32
+)
+
+var (
+	// ErrChecksum is returned for mnemonics whose checksum is wrong
+	ErrChecksum = errors.New("mnemonic: invalid checksum")
+	// ErrUnknownWord is returned for mnemonics with words not in the list
+	ErrUnknownWord = errors.New("mnemonic: unknown word")
+)
+
+// Wordlist is a list of 2048 words
+type Wordlist struct {
+	Name  string
+	words []string
+	index map[string]int
+}
+
+var (
+	// English is the English word list
+	English = newWordlist("english", englishWords)
+	// Italian is the Italian word list
+	Italian = newWordlist("italian", italianWords)
+	// Czech is the Czech word list
+	Czech = newWordlist("czech", czechWords)
+)
+
+// Wordlists are all the supported word lists
+var Wordlists = []*Wordlist{English, Italian, Czech}
+
+func newWordlist(name, words string) *Wordlist {
+	wl := &Wordlist{Name: name, words: strings.Fields(words)}
+	if len(wl.words) != 1<<bitsPerWord {
+		panic("mnemonic: invalid word list " + name)
+	}
+	wl.index = make(map[string]int, len(wl.words))
+	for i, w := range wl.words {
+		wl.index[w] = i
+	}
+	return wl
+}
+
+// Word returns the i-th word of the list
+func (wl *Wordlist) Word(i int) string {
+	return wl.words[i]
+}
+
+// Contains returns whether the word is in the list
+func (wl *Wordlist) Contains(word string) bool {
+	_, ok := wl.index[word]
+	return ok
+}
+
+func checkEntropyBits(bits int) error {
+	if bits < minEntropyBits || bits > maxEntropyBits || bits%entropyBitsStep != 0 {
+		return errors.New("mnemonic: entropy must be 128 to 256 bits, in steps of 32")
+	}
+	return nil
+}
+
+// Generate returns a random mnemonic encoding the given number of bits of
+// entropy, which must be 128, 160, 192, 224 or 256
+func Generate(bits int, wl *Wordlist) (string, error) {
+	if err := checkEntropyBits(bits); err != nil {
+		return "", err
+	}
+
+	entropy := make([]byte, bits/8)
+	if err := rng.Read(entropy); err != nil {
+		return "", err
+	}
+	return FromEntropy(entropy, wl)
+}
+
+// FromEntropy returns the mnemonic encoding entropy: the entropy followed
+// by the first len(entropy)/4 bits of its SHA-256 hash, in groups of 11 bits
+func FromEntropy(entropy []byte, wl *Wordlist) (string, error) {
+	if err := checkEntropyBits(len(entropy) * 8); err != nil {
+		return "", err
+	}
+
+	checksum := sha256.Sum256(entropy)
+	data := append(append([]byte{}, entropy...), checksum[0])
+	n := (len(entropy)*8 + len(entropy)/4) / bitsPerWord
+
+	words := make([]string, n)
+	for i := range words {
+		words[i] = wl.words[readBits(data, i*bitsPerWord)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// ToEntropy decodes a mnemonic and checks its checksum
+func ToEntropy(mnemonic string, wl *Wordlist) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	n := len(words)
+	if n%3 != 0 || n < 12 || n > 24 {
+		return nil, errors.New("mnemonic: invalid number of words")
+	}
+
+	// 11 bits per word, the last entropy/32 bits being the checksum
+	total := n * bitsPerWord
+	csBits := total / 33
+	data := make([]byte, (total+7)/8)
+	for i, w := range words {
+		idx, ok := wl.index[w]
+		if !ok {
+			return nil, ErrUnknownWord
+		}
+		writeBits(data, i*bitsPerWord, idx)
+	}
+
+	entropy := data[:(total-csBits)/8]
+	checksum := sha256.Sum256(entropy)
+	got := data[len(entropy)] >> uint(8-csBits)
+	want := checksum[0] >> uint(8-csBits)
+	if got != want {
+		return nil, ErrChecksum
+	}
+	return append([]byte{}, entropy...), nil
+}
+
+// Validate checks that the mnemonic is made of words of the list and that
+// its checksum is correct
+func Validate(mnemonic string, wl *Wordlist) error {
+	_, err := ToEntropy(mnemonic, wl)
+	return err
+}
+
+// Detect returns the word list of a mnemonic
+func Detect(mnemonic string) (*Wordlist, error) {
+	for _, wl := range Wordlists {
+		if Validate(mnemonic, wl) == nil {
+			return wl, nil
+		}
+	}
+	return nil, errors.New("mnemonic: no matching word list")
+}
+
+// Seed validates the mnemonic and derives the seed with PBKDF2-HMAC-SHA512
+// over the words separated by single spaces, salted with "mnemonic" and the
+// passphrase
+func Seed(mnemonic, passphrase string, wl *Wordlist) ([]byte, error) {
+	if err := Validate(mnemonic, wl); err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(passphrase); i++ {
+		if passphrase[i] >= 0x80 {
+			return nil, errors.New("mnemonic: passphrase must be ASCII")
+		}
+	}
+
+	normalized := strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(normalized), []byte("mnemonic"+passphrase), seedIterations, SeedSize, sha512.New), nil
+}
+
+// readBits returns the 11 bits of data starting at bit offset
+func readBits(data []byte, offset int) int {
+	v := 0
+	for i := 0; i < bitsPerWord; i++ {
+		bit := offset + i
+		v = v<<1 | int(data[bit/8]>>uint(7-bit%8)&1)
+	}
+	return v
+}
+
+// writeBits writes the 11 bits of v at bit offset
+func writeBits(data []byte, offset, v int) {
+	for i := 0; i < bitsPerWord; i++ {
+		if v>>uint(bitsPerWord-1-i)&1 == 1 {
+			bit := offset + i
+			data[bit/8] |= 1 << uint(7-bit%8)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package mnemonic
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Vectors from https://github.com/trezor/python-mnemonic, with the
+// passphrase "TREZOR"
+var vectors = []struct {
+	entropy  string
+	mnemonic string
+	seed     string
+}{
+	{
+		"00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		"ffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		"ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+	},
+	{
+		"808080808080808080808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter always",
+		"107d7c02a5aa6f38c58083ff74f04c607c2d2c0ecc55501dadd72d025b751bc27fe913ffb796f841c49b1d33b610cf0e91d3aa239027f5e99fe4ce9e5088cd65",
+	},
+	{
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title",
+		"bc09fca1804f7e69da93c2f2028eb238c227f2e9dda30cd63699232578480a4021b146ad717fbb7e451ce9eb835f43620bf5c514db0f8add49f5d121449d3e87",
+	},
+	{
+		"77c2b00716cec7213839159e404db50d",
+		"jelly better achieve collect unaware mountain thought cargo oxygen act hood bridge",
+		"b5b6d0127db1a9d2226af0c3346031d77af31e918dba64287a1b44b8ebf63cdd52676f672a290aae502472cf2d602c051f3e6f18055e84e4c43897fc4e51a6ff",
+	},
+	{
+		"3e141609b97933b66a060dcddc71fad1d91677db872031e85f4c015c5e7e8982",
+		"dignity pass list indicate nasty swamp pool script soccer toe leaf photo multiply desk host tomato cradle drill spread actor shine dismiss champion exotic",
+		"ff7f3184df8696d8bef94b6c03114dbee0ef89ff938712301d27ed8336ca89ef9635da20af07d4175f2bf5f3de130f39c9d9e8dd0472489c19b1a020a940da67",
+	},
+	{
+		"18a2e1d81b8ecfb2a333adcb0c17a5b9eb76cc5d05db91a4",
+		"board blade invite damage undo sun mimic interest slam gaze truly inherit resist great inject rocket museum chief",
+		"f84521c777a13b61564234bf8f8b62b3afce27fc4062b51bb5e62bdfecb23864ee6ecf07c1d5a97c0834307c5c852d8ceb88e7c97923c0a3b496bedd4e5f88a9",
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+
+		m, err := FromEntropy(entropy, English)
+		require.Nil(t, err)
+		assert.Equal(t, v.mnemonic, m)
+
+		decoded, err := ToEntropy(v.mnemonic, English)
+		require.Nil(t, err)
+		assert.Equal(t, entropy, decoded)
+
+		seed, err := Seed(v.mnemonic, "TREZOR", English)
+		require.Nil(t, err)
+		assert.Equal(t, v.seed, hex.EncodeToString(seed))
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	for _, wl := range Wordlists {
+		for _, bits := range []int{128, 160, 192, 224, 256} {
+			m, err := Generate(bits, wl)
+			require.Nil(t, err)
+			assert.Len(t, strings.Fields(m), bits*33/32/11)
+			assert.Nil(t, Validate(m, wl))
+
+			detected, err := Detect(m)
+			require.Nil(t, err)
+			assert.Equal(t, wl.Name, detected.Name)
+		}
+	}
+
+	for _, bits := range []int{0, 96, 129, 288} {
+		_, err := Generate(bits, English)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestInvalidMnemonics(t *testing.T) {
+	valid := vectors[0].mnemonic
+
+	assert.Equal(t, ErrChecksum, Validate(strings.Replace(valid, "about", "above", 1), English))
+	assert.Equal(t, ErrUnknownWord, Validate(strings.Replace(valid, "about", "aboot", 1), English))
+	assert.NotNil(t, Validate(valid+" about", English))
+	assert.NotNil(t, Validate("", English))
+	assert.Equal(t, ErrUnknownWord, Validate(valid, Italian))
+
+	_, err := Seed(strings.Replace(valid, "about", "above", 1), "", English)
+	assert.NotNil(t, err)
+	_, err = Seed(valid, "pässword", English)
+	assert.NotNil(t, err)
+	_, err = Detect("zoo zoo zoo")
+	assert.NotNil(t, err)
+
+	// extra whitespace does not change the seed
+	s1, err := Seed(valid, "", English)
+	require.Nil(t, err)
+	s2, err := Seed("  "+strings.Replace(valid, " ", "\t ", -1)+"\n", "", English)
+	require.Nil(t, err)
+	assert.Equal(t, s1, s2)
+}
+
+func TestWordlists(t *testing.T) {
+	for _, wl := range Wordlists {
+		assert.Len(t, wl.index, 2048, wl.Name)
+		assert.True(t, wl.Contains(wl.Word(2047)))
+	}
+	assert.Equal(t, "abandon", English.Word(0))
+	assert.Equal(t, "zoo", English.Word(2047))
+}
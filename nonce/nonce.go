@@ -0,0 +1,97 @@
+// Package nonce derives signing nonces deterministically, in the spirit of
+// RFC 6979. A nonce is an HMAC-SHA512 of everything the signature depends
+// on, keyed with the secret key, and reduced to a Ristretto scalar. Signing
+// the same message twice yields the same nonce, and any change of the
+// message or of the public inputs yields an unrelated one, so that no two
+// signatures ever share a nonce for different challenges, whatever the
+// quality of the system randomness.
+//
+// Extra entropy can be mixed in with SetEntropy. The nonces are then hedged:
+// still safe with a broken random source, and no longer reproducible, which
+// defeats fault attacks that rely on signing the same message twice
+package nonce
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"io"
+	"sync"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// entropySize is the number of bytes mixed in per derivation when hedging
+const entropySize = 32
+
+var (
+	mu      sync.RWMutex
+	entropy io.Reader
+)
+
+// SetEntropy mixes bytes read from r into every nonce derived afterwards,
+// typically rng.Reader, and returns a function which restores the previous
+// setting. A nil r restores purely deterministic nonces
+func SetEntropy(r io.Reader) (restore func()) {
+	mu.Lock()
+	prev := entropy
+	entropy = r
+	mu.Unlock()
+
+	return func() {
+		mu.Lock()
+		entropy = prev
+		mu.Unlock()
+	}
+}
+
+// Scalar derives a nonce for the scheme identified by domain, from the
+// secret key material and the public data being signed. Like rng.Scalar,
+// it panics if the entropy source fails
+func Scalar(domain string, secret []byte, data ...[]byte) ristretto.Scalar {
+	return Scalars(domain, secret, 1, data...)[0]
+}
+
+// Scalars derives n independent nonces at once, for schemes which need
+// several of them per signature
+func Scalars(domain string, secret []byte, n int, data ...[]byte) []ristretto.Scalar {
+	mac := hmac.New(sha512.New, secret)
+	write(mac, []byte("dusk.nonce"))
+	write(mac, []byte(domain))
+	for _, d := range data {
+		write(mac, d)
+	}
+
+	mu.RLock()
+	r := entropy
+	mu.RUnlock()
+	if r != nil {
+		var aux [entropySize]byte
+		if _, err := io.ReadFull(r, aux[:]); err != nil {
+			panic(err)
+		}
+		write(mac, aux[:])
+	}
+	seed := mac.Sum(nil)
+
+	res := make([]ristretto.Scalar, n)
+	var ctr [4]byte
+	var wide [64]byte
+	for i := range res {
+		mac := hmac.New(sha512.New, seed)
+		binary.BigEndian.PutUint32(ctr[:], uint32(i))
+		_, _ = mac.Write(ctr[:])
+		copy(wide[:], mac.Sum(nil))
+		res[i].SetReduced(&wide)
+	}
+	return res
+}
+
+// write absorbs b with its length, so that the inputs cannot be shifted
+// from one to the next
+func write(w io.Writer, b []byte) {
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(b)))
+	_, _ = w.Write(l[:])
+	_, _ = w.Write(b)
+}
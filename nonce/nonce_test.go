@@ -0,0 +1,64 @@
+package nonce
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeterministic(t *testing.T) {
+	secret := []byte("secret key")
+	k := Scalar("dusk.test", secret, []byte("msg"))
+	k2 := Scalar("dusk.test", secret, []byte("msg"))
+	assert.True(t, k.Equals(&k2))
+
+	// every input changes the nonce
+	for _, other := range []ristretto.Scalar{
+		Scalar("dusk.other", secret, []byte("msg")),
+		Scalar("dusk.test", []byte("other key"), []byte("msg")),
+		Scalar("dusk.test", secret, []byte("msg2")),
+		// shifting bytes across inputs
+		Scalar("dusk.test", secret, []byte("ms"), []byte("g")),
+		Scalar("dusk.tes", secret, []byte("tmsg")),
+	} {
+		assert.False(t, k.Equals(&other))
+	}
+}
+
+func TestScalars(t *testing.T) {
+	ks := Scalars("dusk.test", []byte("secret"), 4, []byte("msg"))
+	assert.Equal(t, 4, len(ks))
+	for i := range ks {
+		for j := i + 1; j < len(ks); j++ {
+			assert.False(t, ks[i].Equals(&ks[j]))
+		}
+	}
+
+	first := Scalar("dusk.test", []byte("secret"), []byte("msg"))
+	assert.True(t, first.Equals(&ks[0]))
+}
+
+func TestEntropy(t *testing.T) {
+	secret := []byte("secret")
+	k := Scalar("dusk.test", secret, []byte("msg"))
+
+	restore := SetEntropy(rng.Reader)
+	h1 := Scalar("dusk.test", secret, []byte("msg"))
+	h2 := Scalar("dusk.test", secret, []byte("msg"))
+	assert.False(t, h1.Equals(&h2))
+	assert.False(t, h1.Equals(&k))
+
+	// the same entropy gives the same nonce back
+	restore2 := SetEntropy(rng.Deterministic([]byte("seed")))
+	d1 := Scalar("dusk.test", secret, []byte("msg"))
+	SetEntropy(rng.Deterministic([]byte("seed")))
+	d2 := Scalar("dusk.test", secret, []byte("msg"))
+	assert.True(t, d1.Equals(&d2))
+	restore2()
+	restore()
+
+	k2 := Scalar("dusk.test", secret, []byte("msg"))
+	assert.True(t, k.Equals(&k2))
+}
@@ -0,0 +1,176 @@
+// Package oprf implements an oblivious pseudorandom function over
+// Ristretto. A client learns F(k, input) = H(input, k * H(input)) for the
+// key k of a server, while the server learns neither the input nor the
+// output. It is the building block of password hardening and of anonymous
+// tokens.
+//
+// The client blinds its input as r * H(input), the server multiplies the
+// blinded element by k, and the client unblinds the result with r^-1. In
+// the verifiable mode, the server also proves with a DLEQ proof that it
+// used the key of its public key, so that it cannot tag clients with
+// per-client keys
+package oprf
+
+import (
+	"errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/sigma"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// OutputSize is the size of the PRF output
+const OutputSize = 64
+
+// ErrIdentity is returned for blinded or evaluated elements equal to the
+// identity, which carry no information about the key
+var ErrIdentity = errors.New("oprf: identity element")
+
+// Server holds the key of the PRF
+type Server struct {
+	sk ristretto.Scalar
+	pk ristretto.Point
+}
+
+// NewServer returns a server with a random key
+func NewServer() *Server {
+	var sk ristretto.Scalar
+	rng.Scalar(&sk)
+	return NewServerFromKey(sk)
+}
+
+// NewServerFromKey returns a server with the key sk
+func NewServerFromKey(sk ristretto.Scalar) *Server {
+	s := &Server{sk: sk}
+	s.pk.ScalarMultBase(&sk)
+	return s
+}
+
+// PublicKey returns k * G, which clients verify evaluations against
+func (s *Server) PublicKey() ristretto.Point {
+	return s.pk
+}
+
+// Evaluate multiplies the blinded elements by the key
+func (s *Server) Evaluate(blinded []ristretto.Point) ([]ristretto.Point, error) {
+	evaluated := make([]ristretto.Point, len(blinded))
+	for i := range blinded {
+		if isIdentity(blinded[i]) {
+			return nil, ErrIdentity
+		}
+		evaluated[i].ScalarMult(&blinded[i], &s.sk)
+	}
+	return evaluated, nil
+}
+
+// EvaluateVerifiable evaluates the blinded elements and proves that they
+// were multiplied by the key of the public key
+func (s *Server) EvaluateVerifiable(blinded []ristretto.Point) ([]ristretto.Point, sigma.Proof, error) {
+	evaluated, err := s.Evaluate(blinded)
+	if err != nil {
+		return nil, sigma.Proof{}, err
+	}
+
+	t := transcript.New("dusk.oprf")
+	m, z := composites(t, s.pk, blinded, evaluated)
+
+	var G ristretto.Point
+	G.SetBase()
+	proof, err := sigma.Prove(t, sigma.DLEQ(G, s.pk, m, z), []ristretto.Scalar{s.sk})
+	if err != nil {
+		return nil, sigma.Proof{}, err
+	}
+	return evaluated, proof, nil
+}
+
+// FullEvaluate computes the PRF on input directly, e.g. for a server to
+// check an output presented by a client
+func (s *Server) FullEvaluate(input []byte) []byte {
+	p := hashToGroup(input)
+	p.ScalarMult(&p, &s.sk)
+	return output(input, p)
+}
+
+// composites binds the elements to the transcript and returns
+// sum(d_i * blinded_i) and sum(d_i * evaluated_i) for challenges d_i, so
+// that a single DLEQ proof covers the whole batch
+func composites(t *transcript.Transcript, pk ristretto.Point, blinded, evaluated []ristretto.Point) (ristretto.Point, ristretto.Point) {
+	t.AppendPoint("pk", pk)
+	t.AppendUint64("n", uint64(len(blinded)))
+	for i := range blinded {
+		t.AppendPoint("blinded", blinded[i])
+		t.AppendPoint("evaluated", evaluated[i])
+	}
+
+	var m, z, tmp ristretto.Point
+	m.SetZero()
+	z.SetZero()
+	for i := range blinded {
+		d := t.ChallengeScalar("d")
+		tmp.ScalarMult(&blinded[i], &d)
+		m.Add(&m, &tmp)
+		tmp.ScalarMult(&evaluated[i], &d)
+		z.Add(&z, &tmp)
+	}
+	return m, z
+}
+
+// Blind returns the blinded element of input to send to the server, and
+// the blind to finalize its evaluation with
+func Blind(input []byte) (ristretto.Scalar, ristretto.Point) {
+	var r ristretto.Scalar
+	rng.Scalar(&r)
+
+	p := hashToGroup(input)
+	p.ScalarMult(&p, &r)
+	return r, p
+}
+
+// Finalize unblinds the evaluation of the blinded element of input, and
+// returns the output of the PRF
+func Finalize(input []byte, blind ristretto.Scalar, evaluated ristretto.Point) ([]byte, error) {
+	if isIdentity(evaluated) {
+		return nil, ErrIdentity
+	}
+
+	var inv ristretto.Scalar
+	inv.Inverse(&blind)
+	var n ristretto.Point
+	n.ScalarMult(&evaluated, &inv)
+	return output(input, n), nil
+}
+
+// VerifyEvaluation checks the proof returned by EvaluateVerifiable
+func VerifyEvaluation(pk ristretto.Point, blinded, evaluated []ristretto.Point, proof sigma.Proof) bool {
+	if len(blinded) == 0 || len(blinded) != len(evaluated) {
+		return false
+	}
+	for i := range evaluated {
+		if isIdentity(blinded[i]) || isIdentity(evaluated[i]) {
+			return false
+		}
+	}
+
+	t := transcript.New("dusk.oprf")
+	m, z := composites(t, pk, blinded, evaluated)
+
+	var G ristretto.Point
+	G.SetBase()
+	return sigma.Verify(t, sigma.DLEQ(G, pk, m, z), proof)
+}
+
+func hashToGroup(input []byte) ristretto.Point {
+	return hash.HashToPoint("dusk.oprf.input", input)
+}
+
+func output(input []byte, n ristretto.Point) []byte {
+	return hash.Sha3512WithDomain("dusk.oprf.output", input, n.Bytes())
+}
+
+func isIdentity(p ristretto.Point) bool {
+	var zero ristretto.Point
+	zero.SetZero()
+	return p.Equals(&zero)
+}
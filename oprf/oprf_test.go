@@ -0,0 +1,81 @@
+package oprf
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOPRF(t *testing.T) {
+	server := NewServer()
+	input := []byte("correct horse battery staple")
+
+	blind, blinded := Blind(input)
+	evaluated, err := server.Evaluate([]ristretto.Point{blinded})
+	require.NoError(t, err)
+
+	out, err := Finalize(input, blind, evaluated[0])
+	require.NoError(t, err)
+	assert.Len(t, out, OutputSize)
+	assert.Equal(t, server.FullEvaluate(input), out)
+
+	// Blinding is random, the output is not
+	blind2, blinded2 := Blind(input)
+	assert.False(t, blinded.Equals(&blinded2))
+	evaluated, err = server.Evaluate([]ristretto.Point{blinded2})
+	require.NoError(t, err)
+	out2, err := Finalize(input, blind2, evaluated[0])
+	require.NoError(t, err)
+	assert.Equal(t, out, out2)
+
+	// Other inputs and keys give other outputs
+	assert.NotEqual(t, out, server.FullEvaluate([]byte("other")))
+	assert.NotEqual(t, out, NewServer().FullEvaluate(input))
+}
+
+func TestVerifiable(t *testing.T) {
+	server := NewServer()
+	inputs := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+
+	blinds := make([]ristretto.Scalar, len(inputs))
+	blinded := make([]ristretto.Point, len(inputs))
+	for i, in := range inputs {
+		blinds[i], blinded[i] = Blind(in)
+	}
+
+	evaluated, proof, err := server.EvaluateVerifiable(blinded)
+	require.NoError(t, err)
+	assert.True(t, VerifyEvaluation(server.PublicKey(), blinded, evaluated, proof))
+
+	for i, in := range inputs {
+		out, err := Finalize(in, blinds[i], evaluated[i])
+		require.NoError(t, err)
+		assert.Equal(t, server.FullEvaluate(in), out)
+	}
+
+	// Another key
+	assert.False(t, VerifyEvaluation(NewServer().PublicKey(), blinded, evaluated, proof))
+
+	// One element evaluated with another key
+	other, err := NewServer().Evaluate(blinded[1:2])
+	require.NoError(t, err)
+	tampered := append([]ristretto.Point{}, evaluated...)
+	tampered[1] = other[0]
+	assert.False(t, VerifyEvaluation(server.PublicKey(), blinded, tampered, proof))
+
+	assert.False(t, VerifyEvaluation(server.PublicKey(), blinded[:2], evaluated[:2], proof))
+}
+
+func TestIdentity(t *testing.T) {
+	var zero ristretto.Point
+	zero.SetZero()
+
+	_, err := NewServer().Evaluate([]ristretto.Point{zero})
+	assert.Equal(t, ErrIdentity, err)
+
+	blind, _ := Blind([]byte("x"))
+	_, err = Finalize([]byte("x"), blind, zero)
+	assert.Equal(t, ErrIdentity, err)
+}
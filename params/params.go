@@ -0,0 +1,442 @@
+// Package params bundles the fixed public parameters of the repository:
+// the generators of the commitment schemes and the domains they are derived
+// from, the constants of the Poseidon instances, the generators of bn256 and,
+// optionally, a reference to the KZG SRS a deployment loads.
+//
+// Changing any of them silently breaks compatibility with every proof,
+// commitment and signature produced before, so binaries pin the hash of the
+// bundle and check it at startup: Check compares the parameters compiled in
+// with Pinned, the hash of the parameters of this release, and Verify with a
+// hash pinned by the binary itself. Binaries keeping the encoded bundle they
+// were built against learn what drifted with Diff
+package params
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/kzg"
+	"github.com/dusk-network/dusk-crypto/poseidon"
+	"github.com/dusk-network/dusk-crypto/rangeproof"
+	generator "github.com/dusk-network/dusk-crypto/rangeproof/generators"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
+)
+
+// Version is the version of the bundle. It changes whenever the parameters
+// or their encoding do
+const Version = 2
+
+// Pinned is the hash of the bundle of this release, without SRS
+var Pinned = [32]byte{
+	0x18, 0x9d, 0x53, 0x3a, 0xf9, 0x3d, 0x75, 0x48, 0x9f, 0x31, 0x55, 0x75, 0x3b, 0x27, 0x43, 0xe0,
+	0x32, 0x4f, 0xbb, 0x9f, 0x4a, 0x0b, 0x6c, 0x51, 0x17, 0x54, 0x7a, 0xff, 0xd4, 0x2e, 0x18, 0xcb,
+}
+
+// maxEntries bounds the number of entries of every list of a decoded Bundle
+const maxEntries = 1 << 10
+
+// maxNameSize bounds the size of the names and seeds of a decoded Bundle
+const maxNameSize = 1 << 10
+
+// bulletproofGenerators is the number of generators of every vector of the
+// range proofs, for the largest aggregated proof
+const bulletproofGenerators = 64 * 64
+
+// Bundle is the set of fixed public parameters
+type Bundle struct {
+	Version    uint32
+	Generators []GeneratorSet
+	Poseidon   []PoseidonInstance
+	// SRS is the SRS loaded by the binary, if it pins one
+	SRS *SRSReference
+}
+
+// GeneratorSet is a set of Count points derived from Seed, e.g. a hash to
+// curve domain. Digest is the hash of their encodings
+type GeneratorSet struct {
+	Name   string
+	Seed   []byte
+	Count  uint32
+	Digest [32]byte
+}
+
+// PoseidonInstance is the shape of a Poseidon instance. Digest is the hash
+// of its round constants and MDS matrix
+type PoseidonInstance struct {
+	Name    string
+	Modulus *big.Int
+	T       uint32
+	RF, RP  uint32
+	Digest  [32]byte
+}
+
+// SRSReference identifies a KZG SRS by its size and the hash of its
+// encoding
+type SRSReference struct {
+	Degree uint32
+	Points uint32
+	Digest [32]byte
+}
+
+// Current returns the bundle of the parameters compiled in
+func Current() *Bundle {
+	ped := pedersen.New(nil)
+	vec1 := []byte("dusk.BulletProof.vec1")
+	vec2 := append(append([]byte{}, vec1...), 1)
+
+	var G ristretto.Point
+	G.SetBase()
+
+	return &Bundle{
+		Version: Version,
+		Generators: []GeneratorSet{
+			ristrettoSet("ristretto.base", nil, []ristretto.Point{G}),
+			ristrettoSet("pedersen", []byte("blindPoint"), []ristretto.Point{ped.BasePoint, ped.BlindPoint}),
+			vectorSet("bulletproof.vec1", vec1),
+			vectorSet("bulletproof.vec2", vec2),
+			bytesSet("rangeproof.H", nil, [][]byte{rangeproof.H[:]}),
+			hashSet("credential.H", "dusk.credential.H"),
+			hashSet("crossdleq.h", "dusk.crossdleq.h"),
+			bytesSet("bn256.generators", nil, [][]byte{
+				new(bn256.G1).ScalarBaseMult(big.NewInt(1)).Marshal(),
+				new(bn256.G2).ScalarBaseMult(big.NewInt(1)).Marshal(),
+			}),
+		},
+		Poseidon: []PoseidonInstance{
+			poseidonInstance("bn254.t3", poseidon.BN254T3),
+			poseidonInstance("bn254.t5", poseidon.BN254T5),
+			poseidonInstance("ristretto.t3", poseidon.RistrettoT3),
+			poseidonInstance("ristretto.t5", poseidon.RistrettoT5),
+		},
+	}
+}
+
+func bytesSet(name string, seed []byte, points [][]byte) GeneratorSet {
+	s := GeneratorSet{Name: name, Seed: seed, Count: uint32(len(points))}
+	copy(s.Digest[:], hash.Sha3256WithDomain("dusk.params.points", points...))
+	return s
+}
+
+func ristrettoSet(name string, seed []byte, points []ristretto.Point) GeneratorSet {
+	b := make([][]byte, len(points))
+	for i := range points {
+		b[i] = points[i].Bytes()
+	}
+	return bytesSet(name, seed, b)
+}
+
+// hashSet returns the set of the point the domain hashes to
+func hashSet(name, domain string) GeneratorSet {
+	return ristrettoSet(name, []byte(domain), []ristretto.Point{hash.HashToPoint(domain)})
+}
+
+// vectorSet returns the set of the generators of a range proof vector
+func vectorSet(name string, seed []byte) GeneratorSet {
+	g := generator.New(seed)
+	g.Compute(bulletproofGenerators)
+	return ristrettoSet(name, seed, g.Bases)
+}
+
+func poseidonInstance(name string, p *poseidon.Params) PoseidonInstance {
+	var consts [][]byte
+	for _, c := range p.RoundConstants {
+		consts = append(consts, c.Bytes())
+	}
+	for _, row := range p.MDS {
+		for _, c := range row {
+			consts = append(consts, c.Bytes())
+		}
+	}
+
+	inst := PoseidonInstance{
+		Name:    name,
+		Modulus: new(big.Int).Set(p.Modulus),
+		T:       uint32(p.T),
+		RF:      uint32(p.RF),
+		RP:      uint32(p.RP),
+	}
+	copy(inst.Digest[:], hash.Sha3256WithDomain("dusk.params.poseidon", consts...))
+	return inst
+}
+
+// NewSRSReference returns the reference of an SRS, for binaries to pin it
+// in their bundle
+func NewSRSReference(srs *kzg.SRS) (*SRSReference, error) {
+	buf := &bytes.Buffer{}
+	if err := srs.Encode(buf); err != nil {
+		return nil, err
+	}
+	ref := &SRSReference{Degree: uint32(srs.Degree()), Points: uint32(len(srs.G2) - 1)}
+	copy(ref.Digest[:], hash.Sha3256WithDomain("dusk.params.srs", buf.Bytes()))
+	return ref, nil
+}
+
+// CheckSRS returns an error unless srs is the SRS the bundle references
+func (b *Bundle) CheckSRS(srs *kzg.SRS) error {
+	if b.SRS == nil {
+		return errors.New("params: the bundle references no SRS")
+	}
+	ref, err := NewSRSReference(srs)
+	if err != nil {
+		return err
+	}
+	if *ref != *b.SRS {
+		return errors.New("params: the SRS is not the one of the bundle")
+	}
+	return nil
+}
+
+// Hash returns the hash of the encoding of the bundle
+func (b *Bundle) Hash() [32]byte {
+	buf := &bytes.Buffer{}
+	// encoding into a buffer only fails for bundles Decode rejects
+	if err := b.Encode(buf); err != nil {
+		panic(err)
+	}
+	var res [32]byte
+	copy(res[:], hash.Sha3256WithDomain("dusk.params", buf.Bytes()))
+	return res
+}
+
+// Verify returns an error unless the hash of the bundle is pinned. Diff
+// against a bundle of the pinned parameters names what drifted
+func (b *Bundle) Verify(pinned [32]byte) error {
+	if h := b.Hash(); h != pinned {
+		return fmt.Errorf("params: bundle hash %x does not match the pinned hash %x", h, pinned)
+	}
+	return nil
+}
+
+// Check verifies the parameters compiled in against those of the release
+func Check() error {
+	return Current().Verify(Pinned)
+}
+
+// Diff returns the names of the parameters which differ between b and other
+func (b *Bundle) Diff(other *Bundle) []string {
+	var res []string
+	if b.Version != other.Version {
+		res = append(res, "version")
+	}
+
+	sets := make(map[string]GeneratorSet)
+	for _, s := range other.Generators {
+		sets[s.Name] = s
+	}
+	for _, s := range b.Generators {
+		o, ok := sets[s.Name]
+		if !ok || !bytes.Equal(s.Seed, o.Seed) || s.Count != o.Count || s.Digest != o.Digest {
+			res = append(res, "generators "+s.Name)
+		}
+		delete(sets, s.Name)
+	}
+	for name := range sets {
+		res = append(res, "generators "+name)
+	}
+
+	insts := make(map[string]PoseidonInstance)
+	for _, p := range other.Poseidon {
+		insts[p.Name] = p
+	}
+	for _, p := range b.Poseidon {
+		o, ok := insts[p.Name]
+		if !ok || p.Modulus.Cmp(o.Modulus) != 0 || p.T != o.T || p.RF != o.RF || p.RP != o.RP || p.Digest != o.Digest {
+			res = append(res, "poseidon "+p.Name)
+		}
+		delete(insts, p.Name)
+	}
+	for name := range insts {
+		res = append(res, "poseidon "+name)
+	}
+
+	if (b.SRS == nil) != (other.SRS == nil) || (b.SRS != nil && *b.SRS != *other.SRS) {
+		res = append(res, "srs")
+	}
+	return res
+}
+
+// Encode a Bundle. Integers are big endian, and byte strings are prefixed
+// with their length as a uint32
+func (b *Bundle) Encode(w io.Writer) error {
+	if len(b.Generators) > maxEntries || len(b.Poseidon) > maxEntries {
+		return errors.New("params: too many entries")
+	}
+	if err := binary.Write(w, binary.BigEndian, b.Version); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(b.Generators))); err != nil {
+		return err
+	}
+	for _, s := range b.Generators {
+		if err := writeBytes(w, []byte(s.Name)); err != nil {
+			return err
+		}
+		if err := writeBytes(w, s.Seed); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, s.Count); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, s.Digest); err != nil {
+			return err
+		}
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(b.Poseidon))); err != nil {
+		return err
+	}
+	for _, p := range b.Poseidon {
+		if p.Modulus == nil || p.Modulus.Sign() <= 0 {
+			return errors.New("params: invalid Poseidon modulus")
+		}
+		if err := writeBytes(w, []byte(p.Name)); err != nil {
+			return err
+		}
+		if err := writeBytes(w, p.Modulus.Bytes()); err != nil {
+			return err
+		}
+		for _, v := range []uint32{p.T, p.RF, p.RP} {
+			if err := binary.Write(w, binary.BigEndian, v); err != nil {
+				return err
+			}
+		}
+		if err := binary.Write(w, binary.BigEndian, p.Digest); err != nil {
+			return err
+		}
+	}
+
+	if b.SRS == nil {
+		return binary.Write(w, binary.BigEndian, uint8(0))
+	}
+	if err := binary.Write(w, binary.BigEndian, uint8(1)); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, b.SRS)
+}
+
+// Decode a Bundle
+func (b *Bundle) Decode(r io.Reader) error {
+	if b == nil {
+		return errors.New("struct is nil")
+	}
+
+	var version, n uint32
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return err
+	}
+
+	if err := readCount(r, &n); err != nil {
+		return err
+	}
+	sets := make([]GeneratorSet, n)
+	for i := range sets {
+		name, err := readBytes(r)
+		if err != nil {
+			return err
+		}
+		sets[i].Name = string(name)
+		if sets[i].Seed, err = readBytes(r); err != nil {
+			return err
+		}
+		if err := binary.Read(r, binary.BigEndian, &sets[i].Count); err != nil {
+			return err
+		}
+		if err := binary.Read(r, binary.BigEndian, &sets[i].Digest); err != nil {
+			return err
+		}
+	}
+
+	if err := readCount(r, &n); err != nil {
+		return err
+	}
+	insts := make([]PoseidonInstance, n)
+	for i := range insts {
+		name, err := readBytes(r)
+		if err != nil {
+			return err
+		}
+		insts[i].Name = string(name)
+		modulus, err := readBytes(r)
+		if err != nil {
+			return err
+		}
+		insts[i].Modulus = new(big.Int).SetBytes(modulus)
+		if insts[i].Modulus.Sign() == 0 {
+			return errors.New("params: invalid Poseidon modulus")
+		}
+		for _, v := range []*uint32{&insts[i].T, &insts[i].RF, &insts[i].RP} {
+			if err := binary.Read(r, binary.BigEndian, v); err != nil {
+				return err
+			}
+		}
+		if err := binary.Read(r, binary.BigEndian, &insts[i].Digest); err != nil {
+			return err
+		}
+	}
+
+	var hasSRS uint8
+	if err := binary.Read(r, binary.BigEndian, &hasSRS); err != nil {
+		return err
+	}
+	var srs *SRSReference
+	switch hasSRS {
+	case 0:
+	case 1:
+		srs = &SRSReference{}
+		if err := binary.Read(r, binary.BigEndian, srs); err != nil {
+			return err
+		}
+	default:
+		return errors.New("params: invalid SRS flag")
+	}
+
+	b.Version = version
+	b.Generators = sets
+	b.Poseidon = insts
+	b.SRS = srs
+	return nil
+}
+
+func writeBytes(w io.Writer, b []byte) error {
+	if len(b) > maxNameSize {
+		return errors.New("params: name too long")
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(b))); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func readBytes(r io.Reader) ([]byte, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+	if n > maxNameSize {
+		return nil, errors.New("params: name too long")
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func readCount(r io.Reader, n *uint32) error {
+	if err := binary.Read(r, binary.BigEndian, n); err != nil {
+		return err
+	}
+	if *n > maxEntries {
+		return errors.New("params: too many entries")
+	}
+	return nil
+}
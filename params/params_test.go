@@ -0,0 +1,53 @@
+package params
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/kzg"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinned(t *testing.T) {
+	// a failure here means that a public parameter changed: if it is on
+	// purpose, bump Version and update Pinned
+	h := Current().Hash()
+	require.Equal(t, Pinned, h, "current hash %x", h)
+	assert.Nil(t, Check())
+}
+
+func TestBundleEncode(t *testing.T) {
+	b := Current()
+	srs, err := kzg.NewInsecureSRS(8, 2, rng.Deterministic([]byte("srs")))
+	require.Nil(t, err)
+	b.SRS, err = NewSRSReference(srs)
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, b.Encode(buf))
+	dec := &Bundle{}
+	require.Nil(t, dec.Decode(bytes.NewReader(buf.Bytes())))
+	assert.Equal(t, b.Hash(), dec.Hash())
+	assert.Empty(t, b.Diff(dec))
+	assert.Nil(t, dec.CheckSRS(srs))
+
+	// pinning the SRS changes the hash
+	assert.NotNil(t, b.Verify(Pinned))
+	assert.Nil(t, b.Verify(b.Hash()))
+
+	other, err := kzg.NewInsecureSRS(8, 2, rng.Deterministic([]byte("other")))
+	require.Nil(t, err)
+	assert.NotNil(t, dec.CheckSRS(other))
+}
+
+func TestBundleDiff(t *testing.T) {
+	b := Current()
+	drifted := Current()
+	drifted.Generators[2].Digest[0] ^= 1
+	drifted.Poseidon = drifted.Poseidon[1:]
+
+	assert.Equal(t, []string{"generators " + b.Generators[2].Name, "poseidon " + b.Poseidon[0].Name}, b.Diff(drifted))
+	assert.NotNil(t, drifted.Verify(Pinned))
+}
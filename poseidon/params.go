@@ -0,0 +1,195 @@
+package poseidon
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Alpha is the exponent of the S-box
+const Alpha = 5
+
+// Params is an instance of the Poseidon permutation over a prime field
+type Params struct {
+	Modulus *big.Int
+	// T is the width of the state
+	T int
+	// RF is the number of full rounds, RP the number of partial rounds
+	RF, RP int
+
+	RoundConstants []*big.Int
+	// MDS is the T x T matrix of the linear layer
+	MDS [][]*big.Int
+}
+
+// The standard instances, with the round numbers of the Poseidon paper for
+// 128 bits of security and the x^5 S-box
+var (
+	BN254T3     = mustParams(bn254Order(), 3, 8, 57)
+	BN254T5     = mustParams(bn254Order(), 5, 8, 60)
+	RistrettoT3 = mustParams(ristrettoOrder(), 3, 8, 57)
+	RistrettoT5 = mustParams(ristrettoOrder(), 5, 8, 60)
+)
+
+// bn254Order is the scalar field of BN254, the curve SNARKs are commonly
+// built on. It is not the field of the bn256 package, whose curve is a
+// different, 256 bit Barreto-Naehrig curve
+func bn254Order() *big.Int {
+	r, _ := new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+	return r
+}
+
+func ristrettoOrder() *big.Int {
+	l, _ := new(big.Int).SetString("27742317777372353535851937790883648493", 10)
+	return l.Add(l, new(big.Int).Lsh(big.NewInt(1), 252))
+}
+
+func mustParams(p *big.Int, t, rf, rp int) *Params {
+	params, err := NewParams(p, t, rf, rp)
+	if err != nil {
+		panic(err)
+	}
+	return params
+}
+
+// NewParams generates the round constants and the MDS matrix of an instance
+// like the reference implementation of Poseidon: both are drawn from the
+// Grain LFSR seeded with the parameters, the constants by rejection sampling
+// and the matrix as a Cauchy matrix. The reference implementation also
+// rejects matrices admitting invariant subspaces, which NewParams does not
+// check, so new instances should be compared against it
+func NewParams(p *big.Int, t, rf, rp int) (*Params, error) {
+	if t < 2 || t > 16 {
+		return nil, errors.New("invalid state width")
+	}
+	if rf < 2 || rf%2 != 0 || rp < 0 {
+		return nil, errors.New("invalid number of rounds")
+	}
+	pMinusOne := new(big.Int).Sub(p, big.NewInt(1))
+	if new(big.Int).GCD(nil, nil, big.NewInt(Alpha), pMinusOne).Cmp(big.NewInt(1)) != 0 {
+		return nil, errors.New("x^5 is not a permutation of the field")
+	}
+
+	n := p.BitLen()
+	g := newGrain(n, t, rf, rp)
+
+	params := &Params{
+		Modulus:        new(big.Int).Set(p),
+		T:              t,
+		RF:             rf,
+		RP:             rp,
+		RoundConstants: make([]*big.Int, (rf+rp)*t),
+	}
+
+	for i := range params.RoundConstants {
+		c := g.nextInt(n)
+		for c.Cmp(p) >= 0 {
+			c = g.nextInt(n)
+		}
+		params.RoundConstants[i] = c
+	}
+
+	params.MDS = cauchyMatrix(g, p, t)
+	return params, nil
+}
+
+// cauchyMatrix returns the matrix M[i][j] = 1 / (x_i + y_j) for distinct
+// random x and y
+func cauchyMatrix(g *grain, p *big.Int, t int) [][]*big.Int {
+	n := p.BitLen()
+	for {
+		values := make([]*big.Int, 2*t)
+		for distinct := false; !distinct; {
+			distinct = true
+			seen := make(map[string]bool, 2*t)
+			for i := range values {
+				values[i] = g.nextInt(n)
+				values[i].Mod(values[i], p)
+				if seen[values[i].String()] {
+					distinct = false
+				}
+				seen[values[i].String()] = true
+			}
+		}
+
+		xs, ys := values[:t], values[t:]
+		m := make([][]*big.Int, t)
+		ok := true
+		for i := 0; i < t && ok; i++ {
+			m[i] = make([]*big.Int, t)
+			for j := 0; j < t; j++ {
+				sum := new(big.Int).Add(xs[i], ys[j])
+				sum.Mod(sum, p)
+				if sum.Sign() == 0 {
+					ok = false
+					break
+				}
+				m[i][j] = sum.ModInverse(sum, p)
+			}
+		}
+		if ok {
+			return m
+		}
+	}
+}
+
+// grain is the self-shrinking Grain LFSR of the Poseidon reference
+// implementation
+type grain struct {
+	state [80]byte
+	pos   int
+}
+
+func newGrain(n, t, rf, rp int) *grain {
+	g := &grain{}
+	i := 0
+	for _, f := range []struct{ v, bits int }{
+		{1, 2},  // prime field
+		{0, 4},  // x^alpha S-box
+		{n, 12}, // field size
+		{t, 12},
+		{rf, 10},
+		{rp, 10},
+	} {
+		for b := f.bits - 1; b >= 0; b-- {
+			g.state[i] = byte(f.v>>uint(b)) & 1
+			i++
+		}
+	}
+	for ; i < 80; i++ {
+		g.state[i] = 1
+	}
+
+	for j := 0; j < 160; j++ {
+		g.update()
+	}
+	return g
+}
+
+func (g *grain) update() byte {
+	s := func(i int) byte { return g.state[(g.pos+i)%80] }
+	bit := s(62) ^ s(51) ^ s(38) ^ s(23) ^ s(13) ^ s(0)
+	g.state[g.pos] = bit
+	g.pos = (g.pos + 1) % 80
+	return bit
+}
+
+// nextBit discards the pairs of bits starting with 0 and outputs the second
+// bit of the others
+func (g *grain) nextBit() byte {
+	for g.update() == 0 {
+		g.update()
+	}
+	return g.update()
+}
+
+// nextInt reads an n bit integer, most significant bit first
+func (g *grain) nextInt(n int) *big.Int {
+	res := new(big.Int)
+	for i := 0; i < n; i++ {
+		res.Lsh(res, 1)
+		if g.nextBit() == 1 {
+			res.SetBit(res, 0, 1)
+		}
+	}
+	return res
+}
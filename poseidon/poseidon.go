@@ -0,0 +1,103 @@
+// Package poseidon implements the Poseidon permutation and a sponge hash
+// built on it. Poseidon is cheap to express as an arithmetic circuit, so
+// commitments and Merkle trees hashed with it can be opened inside SNARKs.
+//
+// The standard instances work over the BN254 scalar field and over the
+// Ristretto scalar field, with states of 3 and 5 elements. Their constants
+// are generated like in the reference implementation, and the BN254
+// instances match its test vectors
+package poseidon
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Permute applies the permutation to a state of p.T field elements and
+// returns the new state
+func (p *Params) Permute(state []*big.Int) ([]*big.Int, error) {
+	if len(state) != p.T {
+		return nil, errors.New("state has the wrong width")
+	}
+
+	s := make([]*big.Int, p.T)
+	for i := range state {
+		if state[i] == nil || state[i].Sign() < 0 || state[i].Cmp(p.Modulus) >= 0 {
+			return nil, errors.New("state element is not a field element")
+		}
+		s[i] = new(big.Int).Set(state[i])
+	}
+
+	p.permute(s)
+	return s, nil
+}
+
+// permute runs RF/2 full rounds, RP partial rounds and RF/2 full rounds on
+// the state in place. A round adds the round constants, applies the S-box to
+// every element in full rounds and to the first one in partial rounds, and
+// multiplies by the MDS matrix
+func (p *Params) permute(s []*big.Int) {
+	alpha := big.NewInt(Alpha)
+	next := make([]*big.Int, p.T)
+	for i := range next {
+		next[i] = new(big.Int)
+	}
+	tmp := new(big.Int)
+
+	c := 0
+	for r := 0; r < p.RF+p.RP; r++ {
+		for i := range s {
+			s[i].Add(s[i], p.RoundConstants[c])
+			s[i].Mod(s[i], p.Modulus)
+			c++
+		}
+
+		if r < p.RF/2 || r >= p.RF/2+p.RP {
+			for i := range s {
+				s[i].Exp(s[i], alpha, p.Modulus)
+			}
+		} else {
+			s[0].Exp(s[0], alpha, p.Modulus)
+		}
+
+		for i := range next {
+			next[i].SetInt64(0)
+			for j := range s {
+				tmp.Mul(p.MDS[i][j], s[j])
+				next[i].Add(next[i], tmp)
+			}
+			next[i].Mod(next[i], p.Modulus)
+		}
+		for i := range s {
+			s[i].Set(next[i])
+		}
+	}
+}
+
+// Hash returns the sponge hash of the inputs. The first element of the
+// state is the capacity, initialized to the number of inputs so that inputs
+// of different lengths never collide. The inputs are absorbed p.T-1 at a
+// time, the last block being padded with zeros, and the output is the second
+// element of the state
+func (p *Params) Hash(inputs []*big.Int) (*big.Int, error) {
+	s := make([]*big.Int, p.T)
+	for i := range s {
+		s[i] = new(big.Int)
+	}
+	s[0].SetInt64(int64(len(inputs)))
+
+	rate := p.T - 1
+	for i := 0; i == 0 || i < len(inputs); i += rate {
+		for j := 0; j < rate && i+j < len(inputs); j++ {
+			in := inputs[i+j]
+			if in == nil || in.Sign() < 0 || in.Cmp(p.Modulus) >= 0 {
+				return nil, errors.New("input is not a field element")
+			}
+			s[j+1].Add(s[j+1], in)
+			s[j+1].Mod(s[j+1], p.Modulus)
+		}
+		p.permute(s)
+	}
+
+	return s[1], nil
+}
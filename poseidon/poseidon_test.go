@@ -0,0 +1,118 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fromHex(t *testing.T, s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	require.True(t, ok)
+	return v
+}
+
+func counting(t int) []*big.Int {
+	s := make([]*big.Int, t)
+	for i := range s {
+		s[i] = big.NewInt(int64(i))
+	}
+	return s
+}
+
+// Test vectors of the Poseidon reference implementation
+func TestReferenceVectors(t *testing.T) {
+	tests := []struct {
+		params   *Params
+		expected []string
+	}{
+		{BN254T3, []string{
+			"115cc0f5e7d690413df64c6b9662e9cf2a3617f2743245519e19607a4417189a",
+			"0fca49b798923ab0239de1c9e7a4a9a2210312b6a2f616d18b5a87f9b628ae29",
+			"0e7ae82e40091e63cbd4f16a6d16310b3729d4b6e138fcf54110e2867045a30c",
+		}},
+		{BN254T5, []string{
+			"299c867db6c1fdd79dcefa40e4510b9837e60ebb1ce0663dbaa525df65250465",
+			"1148aaef609aa338b27dafd89bb98862d8bb2b429aceac47d86206154ffe053d",
+			"24febb87fed7462e23f6665ff9a0111f4044c38ee1672c1ac6b0637d34f24907",
+			"0eb08f6d809668a981c186beaf6110060707059576406b248e5d9cf6e78b3d3e",
+			"07748bc6877c9b82c8b98666ee9d0626ec7f5be4205f79ee8528ef1c4a376fc7",
+		}},
+	}
+
+	for _, test := range tests {
+		out, err := test.params.Permute(counting(test.params.T))
+		require.Nil(t, err)
+		for i := range out {
+			assert.Equal(t, 0, fromHex(t, test.expected[i]).Cmp(out[i]))
+		}
+	}
+}
+
+func TestReferenceConstants(t *testing.T) {
+	assert.Equal(t, 0, fromHex(t, "0ee9a592ba9a9518d05986d656f40c2114c4993c11bb29938d21d47304cd8e6e").Cmp(BN254T3.RoundConstants[0]))
+	assert.Equal(t, 0, fromHex(t, "109b7f411ba0e4c9b2b70caf5c36a7b194be7c11ad24378bfedb68592ba8118b").Cmp(BN254T3.MDS[0][0]))
+	assert.Len(t, BN254T3.RoundConstants, 3*(8+57))
+}
+
+func TestPermuteInvalid(t *testing.T) {
+	_, err := RistrettoT3.Permute(counting(2))
+	assert.NotNil(t, err)
+
+	state := counting(3)
+	state[1] = RistrettoT3.Modulus
+	_, err = RistrettoT3.Permute(state)
+	assert.NotNil(t, err)
+
+	// the input is left untouched
+	state = counting(3)
+	_, err = RistrettoT3.Permute(state)
+	require.Nil(t, err)
+	assert.Equal(t, 0, big.NewInt(2).Cmp(state[2]))
+}
+
+func TestHash(t *testing.T) {
+	for _, params := range []*Params{BN254T3, BN254T5, RistrettoT3, RistrettoT5} {
+		h1, err := params.Hash(counting(4))
+		require.Nil(t, err)
+		h2, err := params.Hash(counting(4))
+		require.Nil(t, err)
+		assert.Equal(t, 0, h1.Cmp(h2))
+		assert.True(t, h1.Cmp(params.Modulus) < 0)
+
+		// zero padding does not collide with explicit zeros
+		padded := append(counting(4), big.NewInt(0))
+		h3, err := params.Hash(padded)
+		require.Nil(t, err)
+		assert.NotEqual(t, 0, h1.Cmp(h3))
+
+		empty, err := params.Hash(nil)
+		require.Nil(t, err)
+		zero, err := params.Hash([]*big.Int{big.NewInt(0)})
+		require.Nil(t, err)
+		assert.NotEqual(t, 0, empty.Cmp(zero))
+
+		_, err = params.Hash([]*big.Int{params.Modulus})
+		assert.NotNil(t, err)
+	}
+}
+
+func TestNewParamsInvalid(t *testing.T) {
+	_, err := NewParams(BN254T3.Modulus, 1, 8, 57)
+	assert.NotNil(t, err)
+	_, err = NewParams(BN254T3.Modulus, 3, 7, 57)
+	assert.NotNil(t, err)
+
+	// x^5 is not a permutation when 5 divides p-1
+	_, err = NewParams(big.NewInt(11), 3, 8, 57)
+	assert.NotNil(t, err)
+}
+
+func BenchmarkHash(b *testing.B) {
+	inputs := counting(2)
+	for i := 0; i < b.N; i++ {
+		_, _ = BN254T3.Hash(inputs)
+	}
+}
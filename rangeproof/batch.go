@@ -0,0 +1,146 @@
+package rangeproof
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/rangeproof/vector"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// expBucketSize is the number of generators multiplied between two checks of
+// the context in VerifyBatch
+const expBucketSize = 128
+
+// VerifyBatch verifies several proofs at once. The verification equations are
+// combined with random weights, so that the multi-exponentiation over the
+// vector generators is shared by all the proofs.
+// ctx is checked between proofs and between the buckets of the
+// multi-exponentiation: once it is cancelled, VerifyBatch stops and returns
+// ctx.Err(). A failed batch does not tell which proof is invalid
+func VerifyBatch(ctx context.Context, proofs []Proof) (bool, error) {
+	return verifyBatch(ctx, proofs, nil)
+}
+
+// VerifyBatchWithExtraData works like VerifyBatch, for proofs created with
+// ProveWithExtraData. extraData[i] is the data bound to proofs[i]
+func VerifyBatchWithExtraData(ctx context.Context, proofs []Proof, extraData [][]byte) (bool, error) {
+	if len(extraData) != len(proofs) {
+		return false, errors.Errorf("[VerifyBatch] - got extra data for %d proofs out of %d", len(extraData), len(proofs))
+	}
+	return verifyBatch(ctx, proofs, extraData)
+}
+
+// verifyBatch verifies the proofs, binding extraData[i] to proofs[i] unless
+// extraData is nil
+func verifyBatch(ctx context.Context, proofs []Proof, extraData [][]byte) (bool, error) {
+	if len(proofs) == 0 {
+		return true, nil
+	}
+
+	n := 0
+	for i := range proofs {
+		if err := proofs[i].checkLengths(); err != nil {
+			return false, errors.Wrapf(err, "[VerifyBatch] - proof %d", i)
+		}
+		if err := proofs[i].checkPoints(); err != nil {
+			return false, errors.Wrapf(err, "[VerifyBatch] - proof %d", i)
+		}
+		if N*len(proofs[i].V) > n {
+			n = N * len(proofs[i].V)
+		}
+	}
+
+	// the generators of a smaller proof are a prefix of the generators of
+	// a bigger one
+	ped, G, H := verifierGenerators(n)
+
+	gSum := make([]ristretto.Scalar, n)
+	hSum := make([]ristretto.Scalar, n)
+	for i := 0; i < n; i++ {
+		gSum[i].SetZero()
+		hSum[i].SetZero()
+	}
+	var gBP, hBP ristretto.Scalar
+	gBP.SetZero()
+	hBP.SetZero()
+	var rest ristretto.Point
+	rest.SetZero()
+
+	for i := range proofs {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		p := proofs[i]
+		var data []byte
+		if extraData != nil {
+			data = extraData[i]
+		}
+		y, z, x, w, ts := p.challenges(data, nil)
+
+		var c, weight ristretto.Scalar
+		rng.Scalar(&c)
+		rng.Scalar(&weight)
+
+		terms, err := computeMegacheckTerms(len(p.V), ts, p.IPProof, p.mu, x, y, z, p.t, p.taux, w, c, p.A, p.S, p.T1, p.T2, p.V)
+		if err != nil {
+			return false, errors.Wrapf(err, "[VerifyBatch] - proof %d", i)
+		}
+		terms.scale(weight)
+
+		for j := range terms.g {
+			gSum[j].Add(&gSum[j], &terms.g[j])
+			hSum[j].Add(&hSum[j], &terms.h[j])
+		}
+		gBP.Add(&gBP, &terms.gBP)
+		hBP.Add(&hBP, &terms.hBP)
+		rest.Add(&rest, &terms.rest)
+	}
+
+	scalars := append(append(append([]ristretto.Scalar{}, gSum...), hSum...), gBP, hBP)
+	points := append(append(append([]ristretto.Point{}, G...), H...), ped.BasePoint, ped.BlindPoint)
+
+	sum, err := bucketedExp(ctx, scalars, points)
+	if err != nil {
+		return false, err
+	}
+	sum.Sub(&sum, &rest)
+
+	var zero ristretto.Point
+	zero.SetZero()
+	if !zero.Equals(&sum) {
+		return false, cryptoerrors.New(cryptoerrors.ErrVerificationFailed, "[VerifyBatch] - batch megacheck failed")
+	}
+
+	return true, nil
+}
+
+// bucketedExp computes <scalars, points> in buckets of expBucketSize,
+// aborting between buckets if ctx is done
+func bucketedExp(ctx context.Context, scalars []ristretto.Scalar, points []ristretto.Point) (ristretto.Point, error) {
+	var sum ristretto.Point
+	sum.SetZero()
+
+	for i := 0; i < len(points); i += expBucketSize {
+		if err := ctx.Err(); err != nil {
+			return sum, err
+		}
+
+		end := i + expBucketSize
+		if end > len(points) {
+			end = len(points)
+		}
+
+		bucket, err := vector.Exp(scalars[i:end], points[i:end], end-i, 1)
+		if err != nil {
+			return sum, err
+		}
+		sum.Add(&sum, &bucket)
+	}
+
+	return sum, nil
+}
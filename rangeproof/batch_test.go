@@ -0,0 +1,64 @@
+package rangeproof
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyBatch(t *testing.T) {
+	proofs := []Proof{*generateProof(1, t), *generateProof(4, t), *generateProof(2, t)}
+
+	ok, err := VerifyBatch(context.Background(), proofs)
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	ok, err = VerifyBatch(context.Background(), nil)
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	// a single invalid proof makes the batch fail
+	bad := *generateProof(2, t)
+	bad.t.Add(&bad.t, &bad.mu)
+	ok, err = VerifyBatch(context.Background(), append(proofs, bad))
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+}
+
+func TestVerifyBatchWithExtraData(t *testing.T) {
+	data := [][]byte{[]byte("tx1"), nil, []byte("tx3")}
+	var proofs []Proof
+	for i := range data {
+		proofs = append(proofs, *generateProofWithExtraData(2, data[i], t))
+	}
+
+	ok, err := VerifyBatchWithExtraData(context.Background(), proofs, data)
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	// the data of the proofs is not interchangeable
+	data[0], data[2] = data[2], data[0]
+	ok, err = VerifyBatchWithExtraData(context.Background(), proofs, data)
+	assert.NotNil(t, err)
+	assert.False(t, ok)
+
+	_, err = VerifyBatchWithExtraData(context.Background(), proofs, data[:2])
+	assert.NotNil(t, err)
+
+	// nor can it be left out
+	ok, _ = VerifyBatch(context.Background(), proofs)
+	assert.False(t, ok)
+}
+
+func TestVerifyBatchCancelled(t *testing.T) {
+	proofs := []Proof{*generateProof(2, t)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ok, err := VerifyBatch(ctx, proofs)
+	assert.Equal(t, context.Canceled, err)
+	assert.False(t, ok)
+}
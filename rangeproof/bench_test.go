@@ -0,0 +1,26 @@
+package rangeproof
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+func benchmarkProveM(m int, b *testing.B) {
+	amounts := make([]ristretto.Scalar, m)
+	for i := range amounts {
+		amounts[i] = scalarFromUint64(uint64(100000 + i))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Prove(amounts, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProveM1(b *testing.B)  { benchmarkProveM(1, b) }
+func BenchmarkProveM4(b *testing.B)  { benchmarkProveM(4, b) }
+func BenchmarkProveM16(b *testing.B) { benchmarkProveM(16, b) }
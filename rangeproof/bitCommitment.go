@@ -1,10 +1,12 @@
 package rangeproof
 
 import (
+	"encoding/binary"
 	"errors"
 	"math/big"
 
 	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/ct"
 )
 
 // BitCommitment will be a struct used to hold the values aL and aR
@@ -14,37 +16,37 @@ type BitCommitment struct {
 
 // BitCommit will take the value v producing aL and aR
 // N.B. This has been specialised for N <= 64
+// The conversion from big.Int is not constant-time, the prover uses
+// BitCommitScalar instead
 func BitCommit(v *big.Int) BitCommitment {
+	return bitCommitUint64(v.Uint64())
+}
+
+// BitCommitScalar will take the scalar v producing aL and aR.
+// Only the low N bits of v are used. The decomposition does not branch on
+// the bits of v, nor does it index memory with them
+func BitCommitScalar(v ristretto.Scalar) BitCommitment {
+	var buf [32]byte
+	v.BytesInto(&buf)
+	return bitCommitUint64(binary.LittleEndian.Uint64(buf[:8]))
+}
+
+func bitCommitUint64(num uint64) BitCommitment {
 
 	bc := BitCommitment{
 		AL: make([]ristretto.Scalar, N),
 		AR: make([]ristretto.Scalar, N),
 	}
 
-	var zero ristretto.Scalar
+	var zero, one ristretto.Scalar
 	zero.SetZero()
-	var one ristretto.Scalar
 	one.SetOne()
-	var minusOne ristretto.Scalar
-	minusOne.Neg(&one)
-
-	num := v.Uint64()
 
 	for i := 0; i < N; i++ {
 
-		var rem uint64
-
-		rem = num % 2
-		num = num >> 1
-
-		if rem == 0 {
-			bc.AL[i] = zero
-			bc.AR[i] = minusOne
-		} else {
-			bc.AL[i] = one
-			bc.AR[i] = zero
-		}
-
+		// aL_i = bit, aR_i = bit - 1
+		bc.AL[i] = ct.SelectScalar(int((num>>uint(i))&1), &one, &zero)
+		bc.AR[i].Sub(&bc.AL[i], &one)
 	}
 
 	return bc
@@ -2,8 +2,10 @@ package rangeproof_test
 
 import (
 	"math/big"
+	"math/rand"
 	"testing"
 
+	ristretto "github.com/bwesterb/go-ristretto"
 	"github.com/dusk-network/dusk-crypto/rangeproof"
 	"github.com/stretchr/testify/assert"
 )
@@ -45,3 +47,21 @@ func TestEnsure(t *testing.T) {
 
 	}
 }
+
+func TestBitCommitScalar(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		value := new(big.Int).SetUint64(rand.Uint64())
+
+		var v ristretto.Scalar
+		v.SetBigInt(value)
+
+		Commitment := rangeproof.BitCommitScalar(v)
+		assert.Nil(t, Commitment.Debug(value))
+
+		expected := rangeproof.BitCommit(value)
+		for j := range expected.AL {
+			assert.True(t, expected.AL[j].Equals(&Commitment.AL[j]))
+			assert.True(t, expected.AR[j].Equals(&Commitment.AR[j]))
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package rangeproof
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/dusk-network/dusk-crypto/hash"
+)
+
+// VerificationCache remembers the proofs which have been verified, so that a
+// proof seen in the mempool is not verified again when it is included in a
+// block. Entries are keyed by Proof.Hash, which covers both the commitments
+// and the proof, together with the extra data bound to the proof, and are
+// evicted when they expire or when the cache is full,
+// least recently used first. It is safe for concurrent use
+type VerificationCache struct {
+	lock    sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[[32]byte]*list.Element
+	lru     *list.List
+
+	// now is overridden in tests
+	now func() time.Time
+}
+
+type cacheEntry struct {
+	key     [32]byte
+	expires time.Time
+}
+
+// NewVerificationCache returns a cache holding at most size proofs, each for
+// at most ttl. A ttl of zero means entries never expire
+func NewVerificationCache(size int, ttl time.Duration) *VerificationCache {
+	return &VerificationCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[[32]byte]*list.Element),
+		lru:     list.New(),
+		now:     time.Now,
+	}
+}
+
+// Verify returns true if the proof is in the cache. Otherwise it verifies
+// the proof and caches it if it is valid. Invalid proofs are never cached
+func (c *VerificationCache) Verify(p Proof) (bool, error) {
+	return c.VerifyWithExtraData(p, nil)
+}
+
+// VerifyWithExtraData works like Verify, for a proof created with
+// ProveWithExtraData. The proof is cached along with extraData, so that it
+// is not found in the cache for other data
+func (c *VerificationCache) VerifyWithExtraData(p Proof, extraData []byte) (bool, error) {
+	key, err := cacheKey(p, extraData)
+	if err != nil {
+		return false, err
+	}
+
+	if c.contains(key) {
+		return true, nil
+	}
+
+	ok, err := VerifyWithExtraData(p, extraData)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	c.add(key)
+	return true, nil
+}
+
+// Contains returns true if the proof has been verified and is still cached
+func (c *VerificationCache) Contains(p Proof) bool {
+	return c.ContainsWithExtraData(p, nil)
+}
+
+// ContainsWithExtraData returns true if the proof has been verified with
+// extraData and is still cached
+func (c *VerificationCache) ContainsWithExtraData(p Proof, extraData []byte) bool {
+	key, err := cacheKey(p, extraData)
+	if err != nil {
+		return false
+	}
+	return c.contains(key)
+}
+
+// cacheKey returns Proof.Hash for proofs without extra data, as they are
+// verified without it, and binds the data to the hash otherwise
+func cacheKey(p Proof, extraData []byte) ([32]byte, error) {
+	key, err := p.Hash()
+	if err != nil || len(extraData) == 0 {
+		return key, err
+	}
+
+	copy(key[:], hash.Blake2b256WithDomain("dusk.rangeproof.cache", key[:], extraData))
+	return key, nil
+}
+
+// Len returns the number of cached proofs, expired ones included
+func (c *VerificationCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.Len()
+}
+
+func (c *VerificationCache) contains(key [32]byte) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if c.ttl > 0 && c.now().After(entry.expires) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		return false
+	}
+
+	c.lru.MoveToFront(elem)
+	return true
+}
+
+func (c *VerificationCache) add(key [32]byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.size <= 0 {
+		return
+	}
+
+	expires := c.now().Add(c.ttl)
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cacheEntry).expires = expires
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	for c.lru.Len() >= c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, expires: expires})
+}
@@ -0,0 +1,63 @@
+package rangeproof
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationCache(t *testing.T) {
+	now := time.Now()
+	c := NewVerificationCache(2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	p1, p2, p3 := generateProof(1, t), generateProof(1, t), generateProof(1, t)
+
+	for _, p := range []*Proof{p1, p2} {
+		ok, err := c.Verify(*p)
+		require.Nil(t, err)
+		assert.True(t, ok)
+	}
+	assert.True(t, c.Contains(*p1))
+	assert.True(t, c.Contains(*p2))
+
+	// p1 was used last, so p2 is evicted
+	assert.True(t, c.Contains(*p1))
+	ok, err := c.Verify(*p3)
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.Len())
+	assert.False(t, c.Contains(*p2))
+	assert.True(t, c.Contains(*p1))
+
+	// entries expire
+	now = now.Add(2 * time.Minute)
+	assert.False(t, c.Contains(*p1))
+	assert.Equal(t, 1, c.Len())
+
+	// invalid proofs are not cached
+	bad := generateProof(1, t)
+	bad.t.Add(&bad.t, &bad.mu)
+	ok, _ = c.Verify(*bad)
+	assert.False(t, ok)
+	assert.False(t, c.Contains(*bad))
+}
+
+func TestVerificationCacheExtraData(t *testing.T) {
+	c := NewVerificationCache(4, 0)
+
+	p := *generateProofWithExtraData(2, []byte("tx"), t)
+
+	ok, err := c.VerifyWithExtraData(p, []byte("tx"))
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.True(t, c.ContainsWithExtraData(p, []byte("tx")))
+
+	// the proof is cached for its own data only
+	assert.False(t, c.Contains(p))
+	assert.False(t, c.ContainsWithExtraData(p, []byte("other")))
+	ok, _ = c.VerifyWithExtraData(p, []byte("other"))
+	assert.False(t, ok)
+}
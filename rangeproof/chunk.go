@@ -0,0 +1,224 @@
+package rangeproof
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/bits"
+
+	"github.com/pkg/errors"
+
+	"github.com/dusk-network/dusk-crypto/hash"
+)
+
+// chunkVersion is the version byte of the chunk framing format
+const chunkVersion = 1
+
+// maxChunkPayload is the largest payload a single chunk can carry
+const maxChunkPayload = 1<<16 - 1
+
+// maxProofSize is the size of the encoding of the largest valid proof,
+// commitments included. Nothing larger is ever buffered by a Reassembler
+var maxProofSize = encodedSize(maxM)
+
+// encodedSize returns the size of the encoding of a proof aggregating m
+// values, commitments included: the commitments with their count, A, S, T1,
+// T2, taux, mu, t, the inner product scalars and one L and R per round
+func encodedSize(m int) int {
+	return 4 + 32*m + 7*32 + 2*32 + 64*bits.TrailingZeros(uint(N*m))
+}
+
+// Chunk is a frame of an encoded proof, used to transfer large aggregated
+// proofs over constrained transports.
+// The wire format is: version (1 byte) | index (2 bytes) | total (2 bytes) |
+// payload length (2 bytes) | payload
+type Chunk struct {
+	Index   uint16
+	Total   uint16
+	Payload []byte
+}
+
+// Chunks splits the encoding of the proof, commitments included, in chunks
+// carrying at most size bytes each
+func (p *Proof) Chunks(size int) ([]Chunk, error) {
+	if size <= 0 || size > maxChunkPayload {
+		return nil, errors.Errorf("chunk size must be between 1 and %d", maxChunkPayload)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := p.Encode(buf, true); err != nil {
+		return nil, err
+	}
+	encoded := buf.Bytes()
+
+	total := (len(encoded) + size - 1) / size
+	if total > maxChunkPayload {
+		return nil, errors.New("proof is too large for the requested chunk size")
+	}
+
+	chunks := make([]Chunk, 0, total)
+	for i := 0; i < total; i++ {
+		end := (i + 1) * size
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+		chunks = append(chunks, Chunk{
+			Index:   uint16(i),
+			Total:   uint16(total),
+			Payload: encoded[i*size : end],
+		})
+	}
+
+	return chunks, nil
+}
+
+// Encode a Chunk
+func (c *Chunk) Encode(w io.Writer) error {
+	if len(c.Payload) > maxChunkPayload {
+		return errors.New("chunk payload is too large")
+	}
+
+	header := []interface{}{uint8(chunkVersion), c.Index, c.Total, uint16(len(c.Payload))}
+	for _, field := range header {
+		if err := binary.Write(w, binary.BigEndian, field); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write(c.Payload)
+	return err
+}
+
+// Decode a Chunk
+func (c *Chunk) Decode(r io.Reader) error {
+	if c == nil {
+		return errors.New("struct is nil")
+	}
+
+	var version uint8
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return err
+	}
+	if version != chunkVersion {
+		return errors.Errorf("unsupported chunk version %d", version)
+	}
+
+	var length uint16
+	for _, field := range []interface{}{&c.Index, &c.Total, &length} {
+		if err := binary.Read(r, binary.BigEndian, field); err != nil {
+			return err
+		}
+	}
+
+	c.Payload = make([]byte, length)
+	_, err := io.ReadFull(r, c.Payload)
+	return err
+}
+
+// Reassembler rehydrates a proof from its chunks, hashing the encoding
+// incrementally as chunks arrive. Chunks must be added in order
+type Reassembler struct {
+	total uint16
+	next  uint16
+	buf   bytes.Buffer
+	h     hash.Hash
+}
+
+// NewReassembler returns an empty Reassembler
+func NewReassembler() *Reassembler {
+	return &Reassembler{h: hash.NewBlake2b256WithDomain(proofHashDomain)}
+}
+
+// Add appends the next chunk
+func (r *Reassembler) Add(c Chunk) error {
+	if c.Total == 0 {
+		return errors.New("chunk announces zero chunks")
+	}
+
+	if r.next == 0 {
+		if int(c.Total) > maxProofSize {
+			return errors.Errorf("chunk announces %d chunks, a proof is at most %d bytes", c.Total, maxProofSize)
+		}
+		r.total = c.Total
+	}
+
+	if r.Done() {
+		return errors.New("all chunks have already been received")
+	}
+
+	if c.Total != r.total {
+		return errors.Errorf("chunk announces %d chunks, expected %d", c.Total, r.total)
+	}
+
+	if c.Index != r.next {
+		return errors.Errorf("expected chunk %d, got chunk %d", r.next, c.Index)
+	}
+
+	if r.buf.Len()+len(c.Payload) > maxProofSize {
+		return errors.Errorf("chunks carry more than %d bytes, the size of the largest proof", maxProofSize)
+	}
+
+	r.buf.Write(c.Payload)
+	_, _ = r.h.Write(c.Payload)
+	r.next++
+	return nil
+}
+
+// Done returns true once every chunk has been received
+func (r *Reassembler) Done() bool {
+	return r.next > 0 && r.next == r.total
+}
+
+// Digest returns the digest of the encoded proof received so far, which is
+// Proof.Hash once Done. A light client can compare it against a digest it trusts before
+// decoding or verifying the proof
+func (r *Reassembler) Digest() []byte {
+	return r.h.Sum(nil)
+}
+
+// Proof decodes the reassembled proof
+func (r *Reassembler) Proof() (Proof, error) {
+	if !r.Done() {
+		return Proof{}, errors.Errorf("received %d out of %d chunks", r.next, r.total)
+	}
+
+	var p Proof
+	err := p.Decode(bytes.NewReader(r.buf.Bytes()), true)
+	return p, err
+}
+
+// WriteChunks streams the proof to w as a sequence of encoded chunks
+func WriteChunks(w io.Writer, p Proof, size int) error {
+	chunks, err := p.Chunks(size)
+	if err != nil {
+		return err
+	}
+
+	for i := range chunks {
+		if err := chunks[i].Encode(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadChunks reads encoded chunks from r until the proof is complete. It
+// returns the proof together with the digest of its encoding
+func ReadChunks(r io.Reader) (Proof, []byte, error) {
+	ra := NewReassembler()
+	for !ra.Done() {
+		var c Chunk
+		if err := c.Decode(r); err != nil {
+			return Proof{}, nil, err
+		}
+		if err := ra.Add(c); err != nil {
+			return Proof{}, nil, err
+		}
+	}
+
+	p, err := ra.Proof()
+	if err != nil {
+		return Proof{}, nil, err
+	}
+	return p, ra.Digest(), nil
+}
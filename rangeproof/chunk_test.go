@@ -0,0 +1,72 @@
+package rangeproof
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunks(t *testing.T) {
+	p := generateProof(maxM, t)
+
+	encoded := &bytes.Buffer{}
+	require.Nil(t, p.Encode(encoded, true))
+	assert.Equal(t, maxProofSize, encoded.Len())
+	digest, err := p.Hash()
+	require.Nil(t, err)
+
+	for _, size := range []int{1, 100, 256, encoded.Len(), 2 * encoded.Len()} {
+		buf := &bytes.Buffer{}
+		require.Nil(t, WriteChunks(buf, *p, size))
+
+		decoded, d, err := ReadChunks(buf)
+		require.Nil(t, err)
+		assert.Equal(t, digest[:], d)
+		assert.True(t, decoded.Equals(*p, true))
+
+		ok, err := Verify(decoded)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestReassemblerRejectsOutOfOrder(t *testing.T) {
+	p := generateProof(2, t)
+	chunks, err := p.Chunks(64)
+	require.Nil(t, err)
+	require.True(t, len(chunks) > 2)
+
+	ra := NewReassembler()
+	require.Nil(t, ra.Add(chunks[0]))
+	assert.NotNil(t, ra.Add(chunks[2]))
+
+	wrongTotal := chunks[1]
+	wrongTotal.Total++
+	assert.NotNil(t, ra.Add(wrongTotal))
+
+	_, err = ra.Proof()
+	assert.NotNil(t, err)
+
+	for _, c := range chunks[1:] {
+		require.Nil(t, ra.Add(c))
+	}
+	assert.NotNil(t, ra.Add(chunks[0]))
+
+	_, err = ra.Proof()
+	assert.Nil(t, err)
+}
+
+func TestReassemblerRejectsOversizedProofs(t *testing.T) {
+	// a peer announcing more chunks than the largest proof has bytes is
+	// rejected on the first header
+	ra := NewReassembler()
+	assert.NotNil(t, ra.Add(Chunk{Index: 0, Total: maxChunkPayload, Payload: []byte{0}}))
+
+	// as is one sending more bytes than the largest proof has
+	ra = NewReassembler()
+	payload := make([]byte, maxProofSize/2+1)
+	require.Nil(t, ra.Add(Chunk{Index: 0, Total: 2, Payload: payload}))
+	assert.NotNil(t, ra.Add(Chunk{Index: 1, Total: 2, Payload: payload}))
+}
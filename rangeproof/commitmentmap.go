@@ -0,0 +1,83 @@
+package rangeproof
+
+import (
+	"github.com/pkg/errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rangeproof/innerproduct"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
+)
+
+// CommitmentMap tells where the commitment to every amount of an aggregated
+// proof is stored in Proof.V, and which commitments were added as padding
+type CommitmentMap struct {
+	// Inputs[i] is the position in Proof.V of the commitment to the i-th amount
+	Inputs []int
+	// Padding lists the positions of the commitments to the zero values
+	// added to reach a power of two
+	Padding []int
+}
+
+// MapCommitments returns the CommitmentMap of an aggregated proof over
+// numAmounts amounts
+func MapCommitments(numAmounts int) (CommitmentMap, error) {
+	if numAmounts < 1 || numAmounts > maxM {
+		return CommitmentMap{}, errors.Errorf("number of amounts must be between 1 and %d", maxM)
+	}
+
+	padAmount := int(innerproduct.DiffNextPow2(uint32(numAmounts)))
+
+	cm := CommitmentMap{
+		Inputs:  make([]int, numAmounts),
+		Padding: make([]int, padAmount),
+	}
+	// the prover commits to the amounts in order, then to the padding
+	for i := range cm.Inputs {
+		cm.Inputs[i] = i
+	}
+	for i := range cm.Padding {
+		cm.Padding[i] = numAmounts + i
+	}
+
+	return cm, nil
+}
+
+// ProveWithMap works like ProveUint64, and also returns the CommitmentMap of
+// the proof
+func ProveWithMap(amounts []uint64, blinds []ristretto.Scalar) (Proof, CommitmentMap, error) {
+	cm, err := MapCommitments(len(amounts))
+	if err != nil {
+		return Proof{}, CommitmentMap{}, err
+	}
+
+	p, err := ProveUint64(amounts, blinds)
+	if err != nil {
+		return Proof{}, CommitmentMap{}, err
+	}
+
+	return p, cm, nil
+}
+
+// Commitment returns the commitment to the i-th amount of the proof
+func (cm CommitmentMap) Commitment(p Proof, i int) (pedersen.Commitment, error) {
+	if i < 0 || i >= len(cm.Inputs) {
+		return pedersen.Commitment{}, errors.Errorf("no amount with index %d", i)
+	}
+
+	if len(p.V) != len(cm.Inputs)+len(cm.Padding) {
+		return pedersen.Commitment{}, errors.Errorf("proof has %d commitments, the map expects %d", len(p.V), len(cm.Inputs)+len(cm.Padding))
+	}
+
+	return p.V[cm.Inputs[i]], nil
+}
+
+// IsPadding returns true if the commitment at position pos of Proof.V was
+// added as padding
+func (cm CommitmentMap) IsPadding(pos int) bool {
+	for _, p := range cm.Padding {
+		if p == pos {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package rangeproof
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapCommitments(t *testing.T) {
+	cm, err := MapCommitments(3)
+	require.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 2}, cm.Inputs)
+	assert.Equal(t, []int{3}, cm.Padding)
+	assert.True(t, cm.IsPadding(3))
+	assert.False(t, cm.IsPadding(2))
+
+	cm, err = MapCommitments(4)
+	require.Nil(t, err)
+	assert.Empty(t, cm.Padding)
+
+	_, err = MapCommitments(0)
+	assert.NotNil(t, err)
+	_, err = MapCommitments(maxM + 1)
+	assert.NotNil(t, err)
+}
+
+func TestProveWithMap(t *testing.T) {
+	amounts := []uint64{10, 20, 30, 40, 50}
+	blinds := make([]ristretto.Scalar, len(amounts))
+	for i := range blinds {
+		blinds[i].Rand()
+	}
+
+	p, cm, err := ProveWithMap(amounts, blinds)
+	require.Nil(t, err)
+	require.Len(t, p.V, 8)
+	assert.Len(t, cm.Padding, 3)
+
+	ped := pedersen.New(nil)
+	for i := range amounts {
+		c, err := cm.Commitment(p, i)
+		require.Nil(t, err)
+		want := ped.CommitToScalarWithBlind(scalarFromUint64(amounts[i]), blinds[i])
+		assert.True(t, want.EqualValue(c))
+	}
+
+	_, err = cm.Commitment(p, len(amounts))
+	assert.NotNil(t, err)
+
+	ok, err := Verify(p)
+	require.Nil(t, err)
+	assert.True(t, ok)
+}
@@ -1,3 +1,9 @@
+// Package fiatshamir caches the values a Fiat-Shamir challenge is derived
+// from.
+//
+// Deprecated: the proofs of this module derive their challenges with the
+// transcript package, which labels and domain separates every entry, and
+// can trace them with Transcript.SetTrace
 package fiatshamir
 
 import ristretto "github.com/bwesterb/go-ristretto"
@@ -0,0 +1,38 @@
+//go:build gofuzz
+// +build gofuzz
+
+package rangeproof
+
+import "bytes"
+
+// Fuzz is the go-fuzz entry point for proofs arriving off the network.
+// Decoding and verifying arbitrary bytes must never panic
+func Fuzz(data []byte) int {
+	var p Proof
+	if err := p.Decode(bytes.NewReader(data), true); err != nil {
+		return 0
+	}
+
+	if ok, _ := Verify(p); ok {
+		return 1
+	}
+	return 0
+}
+
+// FuzzDecode exercises only the decoder, including the round trip of
+// anything it accepts
+func FuzzDecode(data []byte) int {
+	var p Proof
+	if err := p.Decode(bytes.NewReader(data), true); err != nil {
+		return 0
+	}
+
+	buf := &bytes.Buffer{}
+	if err := p.Encode(buf, true); err != nil {
+		panic(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		panic("decoded proof does not encode to the same bytes")
+	}
+	return 1
+}
@@ -50,6 +50,13 @@ func (g *Generator) Iterate() ristretto.Point {
 // using the initial data.
 func (g *Generator) Compute(num uint32) {
 
+	// grow the slice once instead of on every append
+	if need := len(g.Bases) + int(num); need > cap(g.Bases) {
+		bases := make([]ristretto.Point, len(g.Bases), need)
+		copy(bases, g.Bases)
+		g.Bases = bases
+	}
+
 	for i := uint32(0); i < num; i++ {
 		g.Bases = append(g.Bases, g.Iterate())
 	}
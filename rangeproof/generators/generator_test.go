@@ -40,3 +40,10 @@ func TestGeneratorsClear(t *testing.T) {
 	assert.Equal(t, expected, actual)
 
 }
+
+func BenchmarkCompute(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		generator.New([]byte("bench")).Compute(64 * 16)
+	}
+}
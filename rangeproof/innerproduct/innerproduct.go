@@ -8,8 +8,9 @@ import (
 	"math/bits"
 
 	ristretto "github.com/bwesterb/go-ristretto"
-	"github.com/dusk-network/dusk-crypto/rangeproof/fiatshamir"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
 	"github.com/dusk-network/dusk-crypto/rangeproof/vector"
+	"github.com/dusk-network/dusk-crypto/transcript"
 )
 
 // This is a reference of the innerProduct implementation at rust
@@ -21,8 +22,9 @@ type Proof struct {
 }
 
 // Generate generates an inner product proof or an error
-// if proof cannot be constucted
-func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristretto.Scalar, Q ristretto.Point) (*Proof, error) {
+// if proof cannot be constucted. The challenges of every round are squeezed
+// from t, which binds the proof to whatever the caller absorbed before
+func Generate(t *transcript.Transcript, GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristretto.Scalar, Q ristretto.Point) (*Proof, error) {
 	n := uint32(len(GVec))
 
 	// XXX : When n is not a power of two, will the bulletproof struct pad it
@@ -40,133 +42,38 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 	H := make([]ristretto.Point, len(HVec))
 	copy(H, HVec)
 
-	hs := fiatshamir.HashCacher{Cache: []byte{}}
-
 	lgN := bits.TrailingZeros(nextPow2(uint(n)))
 
 	Lj := make([]ristretto.Point, 0, lgN)
 	Rj := make([]ristretto.Point, 0, lgN)
 
-	if n != 1 {
-		n = n / 2
-
-		aL, aR, err := vector.SplitScalars(a, n)
-		bL, bR, err := vector.SplitScalars(b, n)
-		GL, GR, err := vector.SplitPoints(G, n)
-		HL, HR, err := vector.SplitPoints(H, n)
-
-		cL, err := vector.InnerProduct(aL, bR)
-		if err != nil {
+	// The copies above are folded in place on every round. scratch holds the
+	// halves of b scaled by the H' factors, which are only applied in the
+	// first round: after it, H has been folded into H'
+	scratch := make([]ristretto.Scalar, n/2)
+	factors := HprimeFactors
 
-		}
-		cR, err := vector.InnerProduct(aR, bL)
-		if err != nil {
-			return nil, err
-		}
+	for n > 1 {
 
-		// L = aL * GR + bR * HL * HPrime[0..n] + cL * Q = e1 + e2 + e3
+		n = n / 2
 
-		e1, err := vector.Exp(aL, GR, int(n), 1)
+		aL, aR, err := vector.SplitScalars(a, n)
 		if err != nil {
 			return nil, err
-
-		}
-
-		bRYi := make([]ristretto.Scalar, len(bR))
-		copy(bRYi, bR)
-
-		for i := range bRYi {
-			bRYi[i].Mul(&bRYi[i], &HprimeFactors[i])
 		}
-
-		e2, err := vector.Exp(bRYi, HL, int(n), 1)
+		bL, bR, err := vector.SplitScalars(b, n)
 		if err != nil {
 			return nil, err
 		}
-
-		var e3 ristretto.Point
-		e3.ScalarMult(&Q, &cL)
-
-		var L ristretto.Point
-		L.SetZero()
-		L.Add(&e1, &e2)
-		L.Add(&L, &e3)
-
-		Lj = append(Lj, L)
-
-		// R = aR * GL + bL * HR * HPrimeFactors[n .. 2n] + cR * Q = e4 + e5 + e6
-
-		e4, err := vector.Exp(aR, GL, int(n), 1)
+		GL, GR, err := vector.SplitPoints(G, n)
 		if err != nil {
 			return nil, err
 		}
-
-		bLYi := make([]ristretto.Scalar, len(bL))
-		copy(bLYi, bL)
-
-		for i := range bLYi {
-			bLYi[i].Mul(&bLYi[i], &HprimeFactors[uint32(i)+n])
-		}
-
-		e5, err := vector.Exp(bLYi, HR, int(n), 1)
+		HL, HR, err := vector.SplitPoints(H, n)
 		if err != nil {
 			return nil, err
 		}
 
-		var e6 ristretto.Point
-		e6.ScalarMult(&Q, &cR)
-
-		var R ristretto.Point
-		R.SetZero()
-		R.Add(&e4, &e5)
-		R.Add(&R, &e6)
-		Rj = append(Rj, R)
-
-		hs.Append(L.Bytes(), R.Bytes())
-
-		u := hs.Derive()
-		var uinv ristretto.Scalar
-		uinv.Inverse(&u)
-
-		var a1, a2, b1, b2, h1a, h2a ristretto.Scalar
-		var g1, g2, h1, h2 ristretto.Point
-
-		for i := uint32(0); i < n; i++ {
-
-			a1.Mul(&aL[i], &u)
-			a2.Mul(&aR[i], &uinv)
-			aL[i].Add(&a1, &a2)
-
-			b1.Mul(&bL[i], &uinv)
-			b2.Mul(&bR[i], &u)
-			bL[i].Add(&b1, &b2)
-
-			g1.ScalarMult(&GL[i], &uinv)
-			g2.ScalarMult(&GR[i], &u)
-			GL[i].Add(&g1, &g2)
-
-			h1a.Mul(&HprimeFactors[i], &u)
-			h1.ScalarMult(&HL[i], &h1a)
-			h2a.Mul(&HprimeFactors[i+n], &uinv)
-			h2.ScalarMult(&HR[i], &h2a)
-			HL[i].Add(&h1, &h2)
-		}
-
-		a = aL
-		b = bL
-		G = GL
-		H = HL
-	}
-
-	for n > 1 {
-
-		n = n / 2
-
-		aL, aR, err := vector.SplitScalars(a, n)
-		bL, bR, err := vector.SplitScalars(b, n)
-		GL, GR, err := vector.SplitPoints(G, n)
-		HL, HR, err := vector.SplitPoints(H, n)
-
 		cL, err := vector.InnerProduct(aL, bR)
 		if err != nil {
 			return nil, err
@@ -176,13 +83,13 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 			return nil, err
 		}
 
-		// L = aL * GR + bR * HL + cL * Q = e1 + e2 + e3
+		// L = aL * GR + bR * HL * HPrime[0..n] + cL * Q = e1 + e2 + e3
 
 		e1, err := vector.Exp(aL, GR, int(n), 1)
 		if err != nil {
 			return nil, err
 		}
-		e2, err := vector.Exp(bR, HL, int(n), 1)
+		e2, err := vector.Exp(scale(scratch, bR, factors, 0), HL, int(n), 1)
 		if err != nil {
 			return nil, err
 		}
@@ -196,13 +103,13 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 
 		Lj = append(Lj, L)
 
-		// R = aR * GL + bL * HR + cR * Q = e4 + e5 + e6
+		// R = aR * GL + bL * HR * HPrime[n .. 2n] + cR * Q = e4 + e5 + e6
 
 		e4, err := vector.Exp(aR, GL, int(n), 1)
 		if err != nil {
 			return nil, err
 		}
-		e5, err := vector.Exp(bL, HR, int(n), 1)
+		e5, err := vector.Exp(scale(scratch, bL, factors, n), HR, int(n), 1)
 		if err != nil {
 			return nil, err
 		}
@@ -215,9 +122,10 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 		R.Add(&R, &e6)
 		Rj = append(Rj, R)
 
-		hs.Append(L.Bytes(), R.Bytes())
+		t.AppendPoint("L", L)
+		t.AppendPoint("R", R)
 
-		u := hs.Derive()
+		u := t.ChallengeScalar("u")
 		var uinv ristretto.Scalar
 		uinv.Inverse(&u)
 
@@ -226,9 +134,11 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 		// GL = GL * uinv + GR * u = g1 + g2 - gprime
 		// HL = HL * u + HR * uinv = h1 + h2 - hprime
 
-		var a1, a2, b1, b2 ristretto.Scalar
+		var a1, a2, b1, b2, h1a, h2a ristretto.Scalar
 		var g1, g2, h1, h2 ristretto.Point
 
+		h1a, h2a = u, uinv
+
 		for i := uint32(0); i < n; i++ {
 
 			a1.Mul(&aL[i], &u)
@@ -243,8 +153,12 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 			g2.ScalarMult(&GR[i], &u)
 			GL[i].Add(&g1, &g2)
 
-			h1.ScalarMult(&HL[i], &u)
-			h2.ScalarMult(&HR[i], &uinv)
+			if factors != nil {
+				h1a.Mul(&factors[i], &u)
+				h2a.Mul(&factors[i+n], &uinv)
+			}
+			h1.ScalarMult(&HL[i], &h1a)
+			h2.ScalarMult(&HR[i], &h2a)
 			HL[i].Add(&h1, &h2)
 		}
 
@@ -252,6 +166,7 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 		b = bL
 		G = GL
 		H = HL
+		factors = nil
 	}
 
 	return &Proof{
@@ -263,8 +178,9 @@ func Generate(GVec, HVec []ristretto.Point, aVec, bVec, HprimeFactors []ristrett
 }
 
 // VerifScalars generates the challenge squared, the inverse challenge squared
-// and s for a given inner product proof
-func (proof *Proof) VerifScalars() ([]ristretto.Scalar, []ristretto.Scalar, []ristretto.Scalar) {
+// and s for a given inner product proof. t must be in the state the prover's
+// transcript was in when Generate was called
+func (proof *Proof) VerifScalars(t *transcript.Transcript) ([]ristretto.Scalar, []ristretto.Scalar, []ristretto.Scalar) {
 	// generate scalars for verification
 
 	if len(proof.L) != len(proof.R) {
@@ -274,13 +190,12 @@ func (proof *Proof) VerifScalars() ([]ristretto.Scalar, []ristretto.Scalar, []ri
 	lgN := len(proof.L)
 	n := uint32(1 << uint(lgN))
 
-	hs := fiatshamir.HashCacher{Cache: []byte{}}
-
 	// 1. compute x's
 	xChals := make([]ristretto.Scalar, 0, lgN)
 	for k := range proof.L {
-		hs.Append(proof.L[k].Bytes(), proof.R[k].Bytes())
-		xChals = append(xChals, hs.Derive())
+		t.AppendPoint("L", proof.L[k])
+		t.AppendPoint("R", proof.R[k])
+		xChals = append(xChals, t.ChallengeScalar("u"))
 	}
 
 	// 2. compute inverse of x's
@@ -336,8 +251,8 @@ func (proof *Proof) VerifScalars() ([]ristretto.Scalar, []ristretto.Scalar, []ri
 }
 
 // Verify is used for unit tests and verifies that a given proof evaluates to the point P
-func (proof *Proof) Verify(G, H, L, R []ristretto.Point, HprimeFactor []ristretto.Scalar, Q, P ristretto.Point, n int) bool {
-	uSq, uInvSq, s := proof.VerifScalars()
+func (proof *Proof) Verify(t *transcript.Transcript, G, H, L, R []ristretto.Point, HprimeFactor []ristretto.Scalar, Q, P ristretto.Point, n int) bool {
+	uSq, uInvSq, s := proof.VerifScalars(t)
 
 	sInv := make([]ristretto.Scalar, len(s))
 	copy(sInv, s)
@@ -417,22 +332,21 @@ func (proof *Proof) Encode(w io.Writer) error {
 }
 
 // Decode a Proof
+// Decode consumes the remainder of the reader. Non-canonical scalars,
+// invalid point encodings and trailing bytes are rejected
 func (proof *Proof) Decode(r io.Reader) error {
 	if proof == nil {
 		return errors.New("struct is nil")
 	}
 
-	var ABytes, BBytes [32]byte
-	err := binary.Read(r, binary.BigEndian, &ABytes)
+	err := readerToScalar(r, &proof.A)
 	if err != nil {
 		return err
 	}
-	err = binary.Read(r, binary.BigEndian, &BBytes)
+	err = readerToScalar(r, &proof.B)
 	if err != nil {
 		return err
 	}
-	proof.A.SetBytes(&ABytes)
-	proof.B.SetBytes(&BBytes)
 
 	buf := &bytes.Buffer{}
 	_, err = buf.ReadFrom(r)
@@ -440,8 +354,8 @@ func (proof *Proof) Decode(r io.Reader) error {
 		return err
 	}
 	numBytes := len(buf.Bytes())
-	if numBytes%32 != 0 {
-		return errors.New("proof was not formatted correctly")
+	if numBytes%64 != 0 {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "proof was not formatted correctly")
 	}
 	lenL := uint32(numBytes / 64)
 
@@ -449,17 +363,14 @@ func (proof *Proof) Decode(r io.Reader) error {
 	proof.R = make([]ristretto.Point, lenL)
 
 	for i := uint32(0); i < lenL; i++ {
-		var LBytes, RBytes [32]byte
-		err = binary.Read(buf, binary.BigEndian, &LBytes)
+		err = readerToPoint(buf, &proof.L[i])
 		if err != nil {
 			return err
 		}
-		err = binary.Read(buf, binary.BigEndian, &RBytes)
+		err = readerToPoint(buf, &proof.R[i])
 		if err != nil {
 			return err
 		}
-		proof.L[i].SetBytes(&LBytes)
-		proof.R[i].SetBytes(&RBytes)
 	}
 
 	return nil
@@ -475,6 +386,10 @@ func (proof *Proof) Equals(other Proof) bool {
 		return false
 	}
 
+	if len(proof.L) != len(other.L) || len(proof.R) != len(other.R) {
+		return false
+	}
+
 	for i := range proof.L {
 		if ok := proof.L[i].Equals(&other.L[i]); !ok {
 			return false
@@ -488,6 +403,20 @@ func (proof *Proof) Equals(other Proof) bool {
 	return true
 }
 
+// scale multiplies v by factors[offset:] into buf. It returns v unchanged
+// when there are no factors
+func scale(buf, v, factors []ristretto.Scalar, offset uint32) []ristretto.Scalar {
+	if factors == nil {
+		return v
+	}
+
+	buf = buf[:len(v)]
+	for i := range v {
+		buf[i].Mul(&v[i], &factors[uint32(i)+offset])
+	}
+	return buf
+}
+
 func nextPow2(n uint) uint {
 	n--
 	n |= n >> 1
@@ -509,3 +438,31 @@ func DiffNextPow2(n uint32) uint32 {
 	padAmount := uint32(pow2) - n + 1
 	return padAmount
 }
+
+func readerToPoint(r io.Reader, p *ristretto.Point) error {
+	var x [32]byte
+	err := binary.Read(r, binary.BigEndian, &x)
+	if err != nil {
+		return err
+	}
+	ok := p.SetBytes(&x)
+	if !ok {
+		return cryptoerrors.New(cryptoerrors.ErrMalformedPoint, "point not encodable")
+	}
+	return nil
+}
+
+// readerToScalar reads a scalar, rejecting encodings which are not reduced
+// modulo the group order
+func readerToScalar(r io.Reader, s *ristretto.Scalar) error {
+	var x [32]byte
+	err := binary.Read(r, binary.BigEndian, &x)
+	if err != nil {
+		return err
+	}
+	s.SetBytes(&x)
+	if !bytes.Equal(s.Bytes(), x[:]) {
+		return cryptoerrors.New(cryptoerrors.ErrNotCanonical, "scalar is not canonically encoded")
+	}
+	return nil
+}
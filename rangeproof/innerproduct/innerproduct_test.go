@@ -7,7 +7,9 @@ import (
 	ristretto "github.com/bwesterb/go-ristretto"
 	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
 	"github.com/dusk-network/dusk-crypto/rangeproof/vector"
+	"github.com/dusk-network/dusk-crypto/transcript"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProofCreation(t *testing.T) {
@@ -18,12 +20,18 @@ func TestProofCreation(t *testing.T) {
 
 		P, G, H, Hpf, a, b, Q := testHelpCreate(n, t)
 
-		proof, err := Generate(G, H, a, b, Hpf, Q)
+		proof, err := Generate(transcript.New("test"), G, H, a, b, Hpf, Q)
 		assert.Equal(t, nil, err)
 
-		ok := proof.Verify(G, H, proof.L, proof.R, Hpf, Q, P, int(n))
+		ok := proof.Verify(transcript.New("test"), G, H, proof.L, proof.R, Hpf, Q, P, int(n))
 		assert.True(t, ok)
 
+		// the proof is bound to the transcript it was created with
+		if n > 1 {
+			ok = proof.Verify(transcript.New("other"), G, H, proof.L, proof.R, Hpf, Q, P, int(n))
+			assert.False(t, ok)
+		}
+
 		buf := &bytes.Buffer{}
 
 		err = proof.Encode(buf)
@@ -41,12 +49,11 @@ func TestProofCreation(t *testing.T) {
 }
 
 // given an n returns P, G,H,HprimeFactors a, b, Q
-func testHelpCreate(n uint32, t *testing.T) (ristretto.Point, []ristretto.Point, []ristretto.Point, []ristretto.Scalar, []ristretto.Scalar, []ristretto.Scalar, ristretto.Point) {
+func testHelpCreate(n uint32, t testing.TB) (ristretto.Point, []ristretto.Point, []ristretto.Point, []ristretto.Scalar, []ristretto.Scalar, []ristretto.Scalar, ristretto.Point) {
 	a := randomScalarArr(n)
 	b := randomScalarArr(n)
 	c, err := vector.InnerProduct(a, b)
-
-	assert.Equal(t, nil, err)
+	require.Nil(t, err)
 
 	var y ristretto.Scalar
 	y.Rand()
@@ -85,7 +92,9 @@ func testHelpCreate(n uint32, t *testing.T) (ristretto.Point, []ristretto.Point,
 	G := ped.BaseVector.Bases
 
 	k1, err = vector.Exp(aPrime, G, int(n), 1)
+	require.Nil(t, err)
 	k2, err = vector.Exp(bPrime, H, int(n), 1)
+	require.Nil(t, err)
 	k3.ScalarMult(&Q, &c)
 
 	var P ristretto.Point
@@ -106,3 +115,17 @@ func randomScalarArr(n uint32) []ristretto.Scalar {
 	}
 	return res
 }
+
+func BenchmarkGenerate(b *testing.B) {
+	// n = N * M for the largest aggregated range proof
+	n := uint32(64 * 64)
+	_, G, H, Hpf, aVec, bVec, Q := testHelpCreate(n, b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Generate(transcript.New("bench"), G, H, aVec, bVec, Hpf, Q); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
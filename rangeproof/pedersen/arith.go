@@ -0,0 +1,140 @@
+package pedersen
+
+import (
+	"errors"
+	"math/big"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// Commitment arithmetic. Commitments are additively homomorphic:
+// Commit(a, r) + Commit(b, s) = Commit(a + b, r + s). The functions below
+// combine the points and the blinding factors together, so that a prover
+// always holds the blinding factor of the commitment it ends up with. A
+// verifier only ever looks at the Value, whatever the blinding factors hold
+
+// Add returns a + b
+func Add(a, b Commitment) Commitment {
+	var c Commitment
+	c.Value.Add(&a.Value, &b.Value)
+	c.BlindingFactor.Add(&a.BlindingFactor, &b.BlindingFactor)
+	return c
+}
+
+// Sub returns a - b
+func Sub(a, b Commitment) Commitment {
+	var c Commitment
+	c.Value.Sub(&a.Value, &b.Value)
+	c.BlindingFactor.Sub(&a.BlindingFactor, &b.BlindingFactor)
+	return c
+}
+
+// ScalarMul returns k * c, a commitment to k times the value of c
+func ScalarMul(c Commitment, k ristretto.Scalar) Commitment {
+	var res Commitment
+	res.Value.ScalarMult(&c.Value, &k)
+	res.BlindingFactor.Mul(&c.BlindingFactor, &k)
+	return res
+}
+
+// Sum returns the sum of the commitments, the commitment to zero with a zero
+// blinding factor if there are none
+func Sum(cs []Commitment) Commitment {
+	var res Commitment
+	res.Value.SetZero()
+	res.BlindingFactor.SetZero()
+	for i := range cs {
+		res = Add(res, cs[i])
+	}
+	return res
+}
+
+// Opening is the prover's view of a commitment: the commitment, with its
+// blinding factor, and the amount it hides. Transaction builders combine
+// openings and let the amounts and blinding factors follow the points
+type Opening struct {
+	Commitment
+	Amount ristretto.Scalar
+}
+
+// CommitAmount commits to amount with a random blinding factor
+func (p *Pedersen) CommitAmount(amount uint64) Opening {
+	v := scalarFromUint64(amount)
+	return Opening{Commitment: p.CommitToScalar(v), Amount: v}
+}
+
+// Open returns the opening of a commitment to amount with the given
+// blinding factor
+func (p *Pedersen) Open(amount, blind ristretto.Scalar) Opening {
+	return Opening{Commitment: p.CommitToScalarWithBlind(amount, blind), Amount: amount}
+}
+
+// Check returns whether the opening matches its commitment
+func (p *Pedersen) Check(o Opening) bool {
+	expected := p.CommitToScalarWithBlind(o.Amount, o.BlindingFactor)
+	return expected.Value.Equals(&o.Value)
+}
+
+// AddAmount returns the opening of o plus a public amount, e.g. a fee,
+// committed to without blinding
+func (p *Pedersen) AddAmount(o Opening, amount uint64) Opening {
+	v := scalarFromUint64(amount)
+	var vBase ristretto.Point
+	vBase.ScalarMult(&p.BasePoint, &v)
+
+	res := o
+	res.Value.Add(&o.Value, &vBase)
+	res.Amount.Add(&o.Amount, &v)
+	return res
+}
+
+// Add returns o + other
+func (o Opening) Add(other Opening) Opening {
+	res := Opening{Commitment: Add(o.Commitment, other.Commitment)}
+	res.Amount.Add(&o.Amount, &other.Amount)
+	return res
+}
+
+// Sub returns o - other
+func (o Opening) Sub(other Opening) Opening {
+	res := Opening{Commitment: Sub(o.Commitment, other.Commitment)}
+	res.Amount.Sub(&o.Amount, &other.Amount)
+	return res
+}
+
+// ScalarMul returns k * o
+func (o Opening) ScalarMul(k ristretto.Scalar) Opening {
+	res := Opening{Commitment: ScalarMul(o.Commitment, k)}
+	res.Amount.Mul(&o.Amount, &k)
+	return res
+}
+
+// SumOpenings returns the sum of the openings
+func SumOpenings(os []Opening) Opening {
+	var res Opening
+	res.Value.SetZero()
+	res.BlindingFactor.SetZero()
+	res.Amount.SetZero()
+	for i := range os {
+		res = res.Add(os[i])
+	}
+	return res
+}
+
+// ProveZero proves that the opening hides zero, typically the inputs minus
+// the outputs and the fee of a transaction. It fails if the amounts do not
+// balance, instead of producing a proof that cannot verify
+func (o Opening) ProveZero() (ZeroProof, error) {
+	var zero ristretto.Scalar
+	zero.SetZero()
+	if !o.Amount.Equals(&zero) {
+		return ZeroProof{}, errors.New("the commitment does not hide zero")
+	}
+	return ProveZero(o.Value, o.BlindingFactor), nil
+}
+
+func scalarFromUint64(n uint64) ristretto.Scalar {
+	var s ristretto.Scalar
+	s.SetBigInt(new(big.Int).SetUint64(n))
+	return s
+}
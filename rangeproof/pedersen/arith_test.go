@@ -0,0 +1,75 @@
+package pedersen_test
+
+import (
+	"math/big"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitmentArithmetic(t *testing.T) {
+	ped := pedersen.New([]byte("dusk.arith"))
+
+	a := ped.CommitAmount(30)
+	b := ped.CommitAmount(12)
+	var k ristretto.Scalar
+	k.SetBigInt(big.NewInt(3))
+
+	for _, o := range []pedersen.Opening{
+		a.Add(b),
+		a.Sub(b),
+		a.ScalarMul(k),
+		pedersen.SumOpenings([]pedersen.Opening{a, b, a}),
+		pedersen.SumOpenings(nil),
+		ped.AddAmount(a, 5),
+	} {
+		assert.True(t, ped.Check(o))
+	}
+
+	// the plain commitment functions track the same points and blinds
+	sum := pedersen.Sum([]pedersen.Commitment{a.Commitment, b.Commitment})
+	assert.True(t, sum.Equals(a.Add(b).Commitment))
+	diff := pedersen.Sub(a.Commitment, b.Commitment)
+	assert.True(t, diff.Equals(a.Sub(b).Commitment))
+	scaled := pedersen.ScalarMul(a.Commitment, k)
+	assert.True(t, scaled.Equals(a.ScalarMul(k).Commitment))
+
+	expected := ped.Open(a.Amount, a.BlindingFactor)
+	assert.True(t, expected.Equals(a.Commitment))
+
+	// a wrong amount is caught
+	bad := a
+	bad.Amount = b.Amount
+	assert.False(t, ped.Check(bad))
+}
+
+func TestBalanceProof(t *testing.T) {
+	ped := pedersen.New([]byte("dusk.arith"))
+
+	inputs := []pedersen.Opening{ped.CommitAmount(100), ped.CommitAmount(50)}
+	outputs := []pedersen.Opening{ped.CommitAmount(120), ped.CommitAmount(27)}
+
+	// inputs = outputs + fee
+	balance := pedersen.SumOpenings(inputs).Sub(ped.AddAmount(pedersen.SumOpenings(outputs), 3))
+	proof, err := balance.ProveZero()
+	require.Nil(t, err)
+
+	// the verifier only sees the points
+	var inSum, outSum []pedersen.Commitment
+	for _, o := range inputs {
+		inSum = append(inSum, pedersen.Commitment{Value: o.Value})
+	}
+	for _, o := range outputs {
+		outSum = append(outSum, pedersen.Commitment{Value: o.Value})
+	}
+	fee := ped.AddAmount(pedersen.SumOpenings(nil), 3)
+	c := pedersen.Sub(pedersen.Sum(inSum), pedersen.Add(pedersen.Sum(outSum), fee.Commitment))
+	assert.True(t, pedersen.VerifyZero(c.Value, proof))
+
+	// unbalanced amounts are refused
+	_, err = pedersen.SumOpenings(inputs).Sub(pedersen.SumOpenings(outputs)).ProveZero()
+	assert.NotNil(t, err)
+}
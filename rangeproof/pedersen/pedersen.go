@@ -6,7 +6,9 @@ import (
 	"io"
 
 	ristretto "github.com/bwesterb/go-ristretto"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
 	generator "github.com/dusk-network/dusk-crypto/rangeproof/generators"
+	"github.com/dusk-network/dusk-crypto/rng"
 )
 
 // Pedersen represents a pedersen struct which holds
@@ -80,7 +82,7 @@ func (c *Commitment) Decode(r io.Reader) error {
 	}
 	ok := c.Value.SetBytes(&cBytes)
 	if !ok {
-		return errors.New("could not set bytes for commitment, not an encodable point")
+		return cryptoerrors.New(cryptoerrors.ErrMalformedPoint, "could not set bytes for commitment, not an encodable point")
 	}
 	return nil
 }
@@ -93,13 +95,17 @@ func DecodeCommitments(r io.Reader) ([]Commitment, error) {
 		return nil, err
 	}
 
-	comms := make([]Commitment, lenV)
+	// lenV comes straight off the wire, so the slice is grown as the
+	// commitments are read instead of being allocated upfront
+	var comms []Commitment
 
 	for i := uint32(0); i < lenV; i++ {
-		err := comms[i].Decode(r)
+		var c Commitment
+		err := c.Decode(r)
 		if err != nil {
 			return nil, err
 		}
+		comms = append(comms, c)
 	}
 
 	return comms, nil
@@ -156,7 +162,14 @@ func (p *Pedersen) CommitToScalar(v ristretto.Scalar) Commitment {
 
 	// generate random blinder
 	blind := ristretto.Scalar{}
-	blind.Rand()
+	rng.Scalar(&blind)
+
+	return p.CommitToScalarWithBlind(v, blind)
+}
+
+// CommitToScalarWithBlind generates a Commitment to a scalar v using a blinding
+// factor chosen by the caller, s.t. V = v * Base + blind * BlindingPoint
+func (p *Pedersen) CommitToScalarWithBlind(v, blind ristretto.Scalar) Commitment {
 
 	// v * Base
 	var vBase ristretto.Point
@@ -182,7 +195,7 @@ func (p *Pedersen) CommitToVectors(vectors ...[]ristretto.Scalar) Commitment {
 
 	// Generate random blinding factor
 	blind := ristretto.Scalar{}
-	blind.Rand()
+	rng.Scalar(&blind)
 
 	// For each vector, we can use the commitToScalars, because a vector is just a slice of scalars
 
@@ -0,0 +1,85 @@
+package pedersen
+
+import (
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// Re-blinding. Adding delta * BlindPoint to a commitment yields a commitment
+// to the same value, which cannot be linked to the original without delta.
+// Relayers re-blind the commitments they forward, so that observers on both
+// sides cannot match them, and hand the delta to whoever must keep opening
+// the commitment or prove that both hide the same value
+
+// Reblind returns c re-blinded with a random delta, and the delta. The
+// blinding factor of the result is the one of c plus delta, so that a
+// holder of the opening of c holds the opening of the result
+func (p *Pedersen) Reblind(c Commitment) (Commitment, ristretto.Scalar) {
+	var delta ristretto.Scalar
+	rng.Scalar(&delta)
+	return p.ReblindWith(c, delta), delta
+}
+
+// ReblindWith returns c re-blinded with delta
+func (p *Pedersen) ReblindWith(c Commitment, delta ristretto.Scalar) Commitment {
+	var d ristretto.Point
+	d.ScalarMult(&p.BlindPoint, &delta)
+
+	var res Commitment
+	res.Value.Add(&c.Value, &d)
+	res.BlindingFactor.Add(&c.BlindingFactor, &delta)
+	return res
+}
+
+// Reblind returns the opening of o re-blinded with delta, e.g. with the
+// delta a relayer handed over
+func (o Opening) Reblind(p *Pedersen, delta ristretto.Scalar) Opening {
+	return Opening{Commitment: p.ReblindWith(o.Commitment, delta), Amount: o.Amount}
+}
+
+// Trail tracks the deltas of the successive re-blindings of a commitment,
+// hop after hop, so that they can be handed over as a single delta
+type Trail struct {
+	// Delta is the sum of the deltas, the difference between the blinding
+	// factors of the last commitment and of the first
+	Delta ristretto.Scalar
+	// Hops is the number of re-blindings
+	Hops int
+}
+
+// NewTrail returns an empty trail
+func NewTrail() *Trail {
+	t := &Trail{}
+	t.Delta.SetZero()
+	return t
+}
+
+// Reblind re-blinds c with a random delta, which it adds to the trail
+func (t *Trail) Reblind(p *Pedersen, c Commitment) Commitment {
+	res, delta := p.Reblind(c)
+	t.Add(delta)
+	return res
+}
+
+// Add records a re-blinding by delta
+func (t *Trail) Add(delta ristretto.Scalar) {
+	t.Delta.Add(&t.Delta, &delta)
+	t.Hops++
+}
+
+// ProveReblind proves that to is from re-blinded, i.e. that both hide the
+// same value, given the delta between them. It is a ZeroProof of to - from:
+// only the parties the proof is shown to along with both commitments learn
+// that they are linked
+func ProveReblind(from, to ristretto.Point, delta ristretto.Scalar) ZeroProof {
+	var diff ristretto.Point
+	diff.Sub(&to, &from)
+	return ProveZero(diff, delta)
+}
+
+// VerifyReblind checks a proof made by ProveReblind
+func VerifyReblind(from, to ristretto.Point, proof ZeroProof) bool {
+	var diff ristretto.Point
+	diff.Sub(&to, &from)
+	return VerifyZero(diff, proof)
+}
@@ -0,0 +1,59 @@
+package pedersen
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReblind(t *testing.T) {
+	p := New([]byte("rand"))
+	o := p.CommitAmount(42)
+
+	c, delta := p.Reblind(o.Commitment)
+	assert.False(t, c.Value.Equals(&o.Value))
+	assert.True(t, c.EqualValue(p.ReblindWith(o.Commitment, delta)))
+
+	// the holder of the opening keeps opening the re-blinded commitment
+	reblinded := o.Reblind(p, delta)
+	assert.True(t, p.Check(reblinded))
+	assert.True(t, reblinded.Value.Equals(&c.Value))
+
+	proof := ProveReblind(o.Value, c.Value, delta)
+	assert.True(t, VerifyReblind(o.Value, c.Value, proof))
+
+	// a commitment to another value is not a re-blinding
+	other := p.CommitAmount(43)
+	assert.False(t, VerifyReblind(o.Value, other.Value, proof))
+	var wrong ristretto.Scalar
+	wrong.Rand()
+	assert.False(t, VerifyReblind(o.Value, c.Value, ProveReblind(o.Value, c.Value, wrong)))
+}
+
+func TestTrail(t *testing.T) {
+	p := New([]byte("rand"))
+	o := p.CommitAmount(7)
+
+	trail := NewTrail()
+	c := o.Commitment
+	for i := 0; i < 3; i++ {
+		c = trail.Reblind(p, c)
+	}
+	assert.Equal(t, 3, trail.Hops)
+
+	// a single delta links the ends, and keeps the opening valid
+	reblinded := o.Reblind(p, trail.Delta)
+	assert.True(t, reblinded.Value.Equals(&c.Value))
+	assert.True(t, p.Check(reblinded))
+	assert.True(t, VerifyReblind(o.Value, c.Value, ProveReblind(o.Value, c.Value, trail.Delta)))
+
+	// the balance of a transaction survives the re-blinding of its outputs
+	in := p.CommitAmount(10)
+	out := p.CommitAmount(10)
+	out = out.Reblind(p, trail.Delta)
+	proof, err := in.Sub(out).ProveZero()
+	assert.Nil(t, err)
+	diff := Sub(in.Commitment, out.Commitment)
+	assert.True(t, VerifyZero(diff.Value, proof))
+}
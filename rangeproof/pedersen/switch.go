@@ -0,0 +1,143 @@
+package pedersen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// Generators is the pair of bases a commitment is computed over, s.t.
+// C = v * Value + blind * Blind. The discrete log relation between the
+// bases must be unknown
+type Generators struct {
+	Value ristretto.Point
+	Blind ristretto.Point
+}
+
+// Generators returns the bases used by CommitToScalar
+func (p *Pedersen) Generators() Generators {
+	return Generators{Value: p.BasePoint, Blind: p.BlindPoint}
+}
+
+// Commit returns v * Value + blind * Blind
+func (g Generators) Commit(v, blind ristretto.Scalar) ristretto.Point {
+	var res, tmp ristretto.Point
+	res.ScalarMult(&g.Value, &v)
+	tmp.ScalarMult(&g.Blind, &blind)
+	res.Add(&res, &tmp)
+	return res
+}
+
+// SwitchProof proves that two commitments computed over different generator
+// pairs hide the same value, without revealing it. It is used to migrate
+// commitments after a rotation of the generators
+type SwitchProof struct {
+	RFrom, RTo ristretto.Point
+	SV         ristretto.Scalar
+	SFrom, STo ristretto.Scalar
+}
+
+// ProveSwitch proves that from.Commit(v, blindFrom) and to.Commit(v, blindTo)
+// commit to the same value v
+func ProveSwitch(from, to Generators, v, blindFrom, blindTo ristretto.Scalar) SwitchProof {
+	var kV, kFrom, kTo ristretto.Scalar
+	rng.Scalar(&kV)
+	rng.Scalar(&kFrom)
+	rng.Scalar(&kTo)
+
+	cFrom := from.Commit(v, blindFrom)
+	cTo := to.Commit(v, blindTo)
+
+	proof := SwitchProof{
+		RFrom: from.Commit(kV, kFrom),
+		RTo:   to.Commit(kV, kTo),
+	}
+
+	c := switchChallenge(from, to, cFrom, cTo, proof.RFrom, proof.RTo)
+
+	proof.SV.MulAdd(&c, &v, &kV)
+	proof.SFrom.MulAdd(&c, &blindFrom, &kFrom)
+	proof.STo.MulAdd(&c, &blindTo, &kTo)
+
+	return proof
+}
+
+// Verify checks that cFrom, over the from generators, and cTo, over the to
+// generators, hide the same value
+func (p *SwitchProof) Verify(from, to Generators, cFrom, cTo ristretto.Point) bool {
+	c := switchChallenge(from, to, cFrom, cTo, p.RFrom, p.RTo)
+
+	// SV * from.Value + SFrom * from.Blind == RFrom + c * cFrom
+	var want, tmp ristretto.Point
+	have := from.Commit(p.SV, p.SFrom)
+	tmp.ScalarMult(&cFrom, &c)
+	want.Add(&p.RFrom, &tmp)
+	if !have.Equals(&want) {
+		return false
+	}
+
+	// SV * to.Value + STo * to.Blind == RTo + c * cTo
+	have = to.Commit(p.SV, p.STo)
+	tmp.ScalarMult(&cTo, &c)
+	want.Add(&p.RTo, &tmp)
+	return have.Equals(&want)
+}
+
+func switchChallenge(from, to Generators, cFrom, cTo, rFrom, rTo ristretto.Point) ristretto.Scalar {
+	ts := transcript.New("dusk.pedersen.switch")
+	ts.AppendPoint("from_value", from.Value)
+	ts.AppendPoint("from_blind", from.Blind)
+	ts.AppendPoint("to_value", to.Value)
+	ts.AppendPoint("to_blind", to.Blind)
+	ts.AppendPoint("c_from", cFrom)
+	ts.AppendPoint("c_to", cTo)
+	ts.AppendPoint("r_from", rFrom)
+	ts.AppendPoint("r_to", rTo)
+	return ts.ChallengeScalar("c")
+}
+
+// Encode a SwitchProof
+func (p *SwitchProof) Encode(w io.Writer) error {
+	for _, b := range [][]byte{p.RFrom.Bytes(), p.RTo.Bytes(), p.SV.Bytes(), p.SFrom.Bytes(), p.STo.Bytes()} {
+		if err := binary.Write(w, binary.BigEndian, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode a SwitchProof
+func (p *SwitchProof) Decode(r io.Reader) error {
+	if p == nil {
+		return errors.New("struct is nil")
+	}
+
+	for _, point := range []*ristretto.Point{&p.RFrom, &p.RTo} {
+		var x [32]byte
+		if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+			return err
+		}
+		if !point.SetBytes(&x) {
+			return cryptoerrors.New(cryptoerrors.ErrMalformedPoint, "point not encodable")
+		}
+	}
+
+	for _, s := range []*ristretto.Scalar{&p.SV, &p.SFrom, &p.STo} {
+		var x [32]byte
+		if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+			return err
+		}
+		s.SetBytes(&x)
+		if !bytes.Equal(s.Bytes(), x[:]) {
+			return cryptoerrors.New(cryptoerrors.ErrNotCanonical, "scalar is not canonically encoded")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package pedersen_test
+
+import (
+	"bytes"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwitchProof(t *testing.T) {
+	from := pedersen.New([]byte("switch")).Generators()
+
+	// rotated value base
+	var to pedersen.Generators
+	to.Value.Derive([]byte("rotated value base"))
+	to.Blind = from.Blind
+
+	var v, blindFrom, blindTo ristretto.Scalar
+	v.Rand()
+	blindFrom.Rand()
+	blindTo.Rand()
+
+	cFrom := from.Commit(v, blindFrom)
+	cTo := to.Commit(v, blindTo)
+
+	proof := pedersen.ProveSwitch(from, to, v, blindFrom, blindTo)
+	assert.True(t, proof.Verify(from, to, cFrom, cTo))
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, proof.Encode(buf))
+	var decoded pedersen.SwitchProof
+	require.Nil(t, decoded.Decode(buf))
+	assert.True(t, decoded.Verify(from, to, cFrom, cTo))
+
+	// the generators are swapped
+	assert.False(t, proof.Verify(to, from, cTo, cFrom))
+
+	// cTo hides a different value
+	var other ristretto.Scalar
+	other.Rand()
+	assert.False(t, proof.Verify(from, to, cFrom, to.Commit(other, blindTo)))
+
+	bad := pedersen.ProveSwitch(from, to, v, blindFrom, blindTo)
+	assert.False(t, bad.Verify(from, to, cFrom, to.Commit(other, blindTo)))
+}
@@ -0,0 +1,94 @@
+package pedersen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// ZeroProof is a Schnorr proof that a commitment opens to zero, i.e. that
+// the prover knows blind s.t. C = blind * BlindPoint
+type ZeroProof struct {
+	R ristretto.Point
+	S ristretto.Scalar
+}
+
+// ProveZero proves that commitment hides the value zero with the blinding
+// factor blinding
+func ProveZero(commitment ristretto.Point, blinding ristretto.Scalar) ZeroProof {
+	var blindPoint ristretto.Point
+	blindPoint.SetBase()
+
+	var k ristretto.Scalar
+	rng.Scalar(&k)
+
+	var proof ZeroProof
+	proof.R.ScalarMult(&blindPoint, &k)
+
+	c := zeroChallenge(commitment, proof.R)
+	proof.S.MulAdd(&c, &blinding, &k)
+
+	return proof
+}
+
+// VerifyZero checks that commitment opens to zero
+func VerifyZero(commitment ristretto.Point, proof ZeroProof) bool {
+	var blindPoint ristretto.Point
+	blindPoint.SetBase()
+
+	c := zeroChallenge(commitment, proof.R)
+
+	// S * BlindPoint == R + c * C
+	var lhs, rhs ristretto.Point
+	lhs.ScalarMult(&blindPoint, &proof.S)
+	rhs.ScalarMult(&commitment, &c)
+	rhs.Add(&rhs, &proof.R)
+
+	return lhs.Equals(&rhs)
+}
+
+func zeroChallenge(commitment, R ristretto.Point) ristretto.Scalar {
+	ts := transcript.New("dusk.pedersen.zero")
+	ts.AppendPoint("commitment", commitment)
+	ts.AppendPoint("R", R)
+	return ts.ChallengeScalar("c")
+}
+
+// Encode a ZeroProof
+func (p *ZeroProof) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, p.R.Bytes()); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, p.S.Bytes())
+}
+
+// Decode a ZeroProof
+func (p *ZeroProof) Decode(r io.Reader) error {
+	if p == nil {
+		return errors.New("struct is nil")
+	}
+
+	var x [32]byte
+	if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+		return err
+	}
+	if !p.R.SetBytes(&x) {
+		return cryptoerrors.New(cryptoerrors.ErrMalformedPoint, "point not encodable")
+	}
+
+	if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+		return err
+	}
+	p.S.SetBytes(&x)
+	if !bytes.Equal(p.S.Bytes(), x[:]) {
+		return cryptoerrors.New(cryptoerrors.ErrNotCanonical, "scalar is not canonically encoded")
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package pedersen_test
+
+import (
+	"bytes"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZeroProof(t *testing.T) {
+	ped := pedersen.New([]byte("zero"))
+
+	var zero, blind ristretto.Scalar
+	zero.SetZero()
+	blind.Rand()
+
+	// e.g. inputs minus outputs minus fee
+	c := ped.CommitToScalarWithBlind(zero, blind)
+
+	proof := pedersen.ProveZero(c.Value, blind)
+	assert.True(t, pedersen.VerifyZero(c.Value, proof))
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, proof.Encode(buf))
+	var decoded pedersen.ZeroProof
+	require.Nil(t, decoded.Decode(buf))
+	assert.True(t, pedersen.VerifyZero(c.Value, decoded))
+
+	// a commitment to a non-zero value cannot be proven
+	var one ristretto.Scalar
+	one.SetOne()
+	nonZero := ped.CommitToScalarWithBlind(one, blind)
+	proof = pedersen.ProveZero(nonZero.Value, blind)
+	assert.False(t, pedersen.VerifyZero(nonZero.Value, proof))
+}
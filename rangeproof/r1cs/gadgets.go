@@ -0,0 +1,89 @@
+package r1cs
+
+import (
+	"errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// Shuffle constrains y to be a permutation of x.
+// Using a challenge c bound to the commitments, it checks that
+// prod(x_i - c) == prod(y_i - c), which for a random c only holds if the
+// multisets are equal. x and y should be committed variables, as the challenge
+// only binds the values committed before it was drawn
+func Shuffle(cs ConstraintSystem, x, y []Variable) error {
+	if len(x) != len(y) {
+		return errors.New("shuffle inputs and outputs must have the same length")
+	}
+
+	if len(x) == 0 {
+		return nil
+	}
+
+	if len(x) == 1 {
+		cs.Constrain(x[0].LC().Sub(y[0].LC()))
+		return nil
+	}
+
+	c := Constant(cs.Challenge())
+
+	xProd := productMinus(cs, x, c)
+	yProd := productMinus(cs, y, c)
+	cs.Constrain(xProd.Sub(yProd))
+	return nil
+}
+
+// productMinus returns the output of the multiplication chain prod(v_i - c)
+func productMinus(cs ConstraintSystem, v []Variable, c LinearCombination) LinearCombination {
+	_, _, o := cs.Multiply(v[0].LC().Sub(c), v[1].LC().Sub(c))
+	for i := 2; i < len(v); i++ {
+		_, _, o = cs.Multiply(o.LC(), v[i].LC().Sub(c))
+	}
+	return o.LC()
+}
+
+// SetMembership constrains v to be equal to one of the public values in set,
+// by enforcing prod(v - s_i) == 0
+func SetMembership(cs ConstraintSystem, v Variable, set []ristretto.Scalar) error {
+	if len(set) == 0 {
+		return errors.New("cannot prove membership of an empty set")
+	}
+
+	if len(set) == 1 {
+		cs.Constrain(v.LC().Sub(Constant(set[0])))
+		return nil
+	}
+
+	_, _, o := cs.Multiply(v.LC().Sub(Constant(set[0])), v.LC().Sub(Constant(set[1])))
+	for i := 2; i < len(set); i++ {
+		_, _, o = cs.Multiply(o.LC(), v.LC().Sub(Constant(set[i])))
+	}
+	cs.Constrain(o.LC())
+	return nil
+}
+
+// ProveMembership proves that the commitment to v with blinding factor blind
+// opens to one of the values in set. It returns the proof and the commitment
+func ProveMembership(v, blind ristretto.Scalar, set []ristretto.Scalar) (*Proof, ristretto.Point, error) {
+	prover := NewProver()
+	vVar, V := prover.Commit(v, blind)
+
+	if err := SetMembership(prover, vVar, set); err != nil {
+		return nil, V, err
+	}
+
+	proof, err := prover.Prove()
+	return proof, V, err
+}
+
+// VerifyMembership checks that the commitment V opens to one of the values in set
+func VerifyMembership(V ristretto.Point, set []ristretto.Scalar, proof *Proof) error {
+	verifier := NewVerifier()
+	vVar := verifier.Commit(V)
+
+	if err := SetMembership(verifier, vVar, set); err != nil {
+		return err
+	}
+
+	return verifier.Verify(proof)
+}
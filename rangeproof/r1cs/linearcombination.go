@@ -0,0 +1,92 @@
+package r1cs
+
+import (
+	"math/big"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// VariableType tells which vector of the constraint system a Variable lives in
+type VariableType uint8
+
+const (
+	// Committed is a variable bound to a high-level Pedersen commitment
+	Committed VariableType = iota
+	// MultiplierLeft is the left input of a multiplication gate
+	MultiplierLeft
+	// MultiplierRight is the right input of a multiplication gate
+	MultiplierRight
+	// MultiplierOutput is the output of a multiplication gate
+	MultiplierOutput
+	// One is the constant variable with value 1
+	One
+)
+
+// Variable is a handle to a value inside the constraint system
+type Variable struct {
+	Type  VariableType
+	Index int
+}
+
+// OneVar is the variable which always has the value 1
+var OneVar = Variable{Type: One}
+
+// Term is a variable multiplied by a coefficient
+type Term struct {
+	Variable Variable
+	Coeff    ristretto.Scalar
+}
+
+// LinearCombination is a sum of terms
+type LinearCombination []Term
+
+// LC turns a variable into a linear combination with coefficient 1
+func (v Variable) LC() LinearCombination {
+	var one ristretto.Scalar
+	one.SetOne()
+	return LinearCombination{{Variable: v, Coeff: one}}
+}
+
+// Constant returns the linear combination equal to the scalar c
+func Constant(c ristretto.Scalar) LinearCombination {
+	return LinearCombination{{Variable: OneVar, Coeff: c}}
+}
+
+// ConstantInt returns the linear combination equal to the integer c
+func ConstantInt(c int64) LinearCombination {
+	var s ristretto.Scalar
+	s.SetBigInt(big.NewInt(c))
+	return Constant(s)
+}
+
+// Add returns lc + other
+func (lc LinearCombination) Add(other LinearCombination) LinearCombination {
+	res := make(LinearCombination, 0, len(lc)+len(other))
+	res = append(res, lc...)
+	return append(res, other...)
+}
+
+// Sub returns lc - other
+func (lc LinearCombination) Sub(other LinearCombination) LinearCombination {
+	return lc.Add(other.Neg())
+}
+
+// Neg returns -lc
+func (lc LinearCombination) Neg() LinearCombination {
+	res := make(LinearCombination, len(lc))
+	for i := range lc {
+		res[i].Variable = lc[i].Variable
+		res[i].Coeff.Neg(&lc[i].Coeff)
+	}
+	return res
+}
+
+// Mul returns lc scaled by c
+func (lc LinearCombination) Mul(c ristretto.Scalar) LinearCombination {
+	res := make(LinearCombination, len(lc))
+	for i := range lc {
+		res[i].Variable = lc[i].Variable
+		res[i].Coeff.Mul(&lc[i].Coeff, &c)
+	}
+	return res
+}
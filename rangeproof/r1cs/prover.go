@@ -0,0 +1,331 @@
+package r1cs
+
+import (
+	"github.com/pkg/errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rangeproof/innerproduct"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
+	"github.com/dusk-network/dusk-crypto/rangeproof/vector"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// Prover builds the witness of a constraint system and proves it
+type Prover struct {
+	proofTranscript
+
+	ped *pedersen.Pedersen
+
+	// values and blinding factors of the high-level commitments
+	v, vBlinds []ristretto.Scalar
+
+	// multiplier assignments
+	aL, aR, aO []ristretto.Scalar
+
+	constraints []LinearCombination
+
+	// err records the first misuse of the constraint system, it is
+	// returned by Prove
+	err error
+}
+
+// NewProver returns a Prover with an empty constraint system
+func NewProver() *Prover {
+	return &Prover{
+		proofTranscript: newTranscript(),
+		ped:             pedersen.New(genData),
+	}
+}
+
+// Commit creates a Pedersen commitment to v with the blinding factor blind,
+// returning the variable bound to it and the commitment the verifier needs
+func (p *Prover) Commit(v, blind ristretto.Scalar) (Variable, ristretto.Point) {
+	if p.challenged {
+		p.err = errors.New("[Commit] - cannot commit after a challenge was drawn")
+	}
+
+	V := p.ped.CommitToScalarWithBlind(v, blind)
+	p.ts.AppendPoint("V", V.Value)
+
+	p.v = append(p.v, v)
+	p.vBlinds = append(p.vBlinds, blind)
+
+	return Variable{Type: Committed, Index: len(p.v) - 1}, V.Value
+}
+
+// Multiply implements ConstraintSystem
+func (p *Prover) Multiply(left, right LinearCombination) (Variable, Variable, Variable) {
+	l := p.eval(left)
+	r := p.eval(right)
+	var o ristretto.Scalar
+	o.Mul(&l, &r)
+
+	lVar, rVar, oVar := p.allocateMultiplier(l, r, o)
+
+	p.Constrain(left.Sub(lVar.LC()))
+	p.Constrain(right.Sub(rVar.LC()))
+
+	return lVar, rVar, oVar
+}
+
+// Allocate implements ConstraintSystem. The variable is the left input of
+// a multiplier whose right input and output are zero
+func (p *Prover) Allocate(assignment *ristretto.Scalar) Variable {
+	var zero ristretto.Scalar
+	zero.SetZero()
+
+	if assignment == nil {
+		p.err = errors.New("[Allocate] - the prover needs an assignment for every variable")
+		l, _, _ := p.allocateMultiplier(zero, zero, zero)
+		return l
+	}
+
+	l, _, _ := p.allocateMultiplier(*assignment, zero, zero)
+	return l
+}
+
+// Constrain implements ConstraintSystem
+func (p *Prover) Constrain(lc LinearCombination) {
+	p.constraints = append(p.constraints, lc)
+}
+
+// Challenge implements ConstraintSystem
+func (p *Prover) Challenge() ristretto.Scalar {
+	return p.challenge("c")
+}
+
+func (p *Prover) allocateMultiplier(l, r, o ristretto.Scalar) (Variable, Variable, Variable) {
+	i := len(p.aL)
+	p.aL = append(p.aL, l)
+	p.aR = append(p.aR, r)
+	p.aO = append(p.aO, o)
+
+	return Variable{Type: MultiplierLeft, Index: i},
+		Variable{Type: MultiplierRight, Index: i},
+		Variable{Type: MultiplierOutput, Index: i}
+}
+
+// eval evaluates a linear combination on the current assignment
+func (p *Prover) eval(lc LinearCombination) ristretto.Scalar {
+	var res ristretto.Scalar
+	res.SetZero()
+
+	for _, term := range lc {
+		var val ristretto.Scalar
+		switch term.Variable.Type {
+		case Committed:
+			val = p.v[term.Variable.Index]
+		case MultiplierLeft:
+			val = p.aL[term.Variable.Index]
+		case MultiplierRight:
+			val = p.aR[term.Variable.Index]
+		case MultiplierOutput:
+			val = p.aO[term.Variable.Index]
+		case One:
+			val.SetOne()
+		}
+		res.MulAdd(&term.Coeff, &val, &res)
+	}
+
+	return res
+}
+
+// Prove creates the proof that the assignment satisfies all the constraints
+func (p *Prover) Prove() (*Proof, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	for i := range p.constraints {
+		val := p.eval(p.constraints[i])
+		if val.IsNonZeroI() == 1 {
+			return nil, errors.Errorf("[Prove] - constraint %d is not satisfied", i)
+		}
+	}
+
+	n := padLength(len(p.aL))
+	m := len(p.v)
+	B, BBlind, G, H := generators(n)
+
+	aL := padScalars(p.aL, n)
+	aR := padScalars(p.aR, n)
+	aO := padScalars(p.aO, n)
+
+	sL, sR := randomScalars(n), randomScalars(n)
+
+	var iBlind, oBlind, sBlind ristretto.Scalar
+	rng.Scalar(&iBlind)
+	rng.Scalar(&oBlind)
+	rng.Scalar(&sBlind)
+
+	// AI = iBlind * BBlind + <aL, G> + <aR, H>
+	AI, err := commitVectors(BBlind, iBlind, G, aL, H, aR)
+	if err != nil {
+		return nil, errors.Wrap(err, "[Prove] - AI")
+	}
+	// AO = oBlind * BBlind + <aO, G>
+	AO, err := commitVectors(BBlind, oBlind, G, aO, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "[Prove] - AO")
+	}
+	// S = sBlind * BBlind + <sL, G> + <sR, H>
+	S, err := commitVectors(BBlind, sBlind, G, sL, H, sR)
+	if err != nil {
+		return nil, errors.Wrap(err, "[Prove] - S")
+	}
+
+	p.ts.AppendUint64("m", uint64(m))
+	p.ts.AppendUint64("n", uint64(n))
+	p.ts.AppendPoint("AI", AI)
+	p.ts.AppendPoint("AO", AO)
+	p.ts.AppendPoint("S", S)
+	y := p.challenge("y")
+	z := p.challenge("z")
+
+	fw := flatten(p.constraints, z, n, m)
+
+	yN := vector.ScalarPowers(y, uint32(n))
+	var yInv ristretto.Scalar
+	yInv.Inverse(&y)
+	yInvN := vector.ScalarPowers(yInv, uint32(n))
+
+	// l(x) = l1 x + l2 x^2 + l3 x^3
+	// r(x) = r0 + r1 x + r3 x^3
+	l1 := make([]ristretto.Scalar, n)
+	r0 := make([]ristretto.Scalar, n)
+	r1 := make([]ristretto.Scalar, n)
+	r3 := make([]ristretto.Scalar, n)
+	for i := 0; i < n; i++ {
+		// l1 = aL + y^-n o wR
+		l1[i].MulAdd(&yInvN[i], &fw.wR[i], &aL[i])
+		// r0 = wO - y^n
+		r0[i].Sub(&fw.wO[i], &yN[i])
+		// r1 = y^n o aR + wL
+		r1[i].MulAdd(&yN[i], &aR[i], &fw.wL[i])
+		// r3 = y^n o sR
+		r3[i].Mul(&yN[i], &sR[i])
+	}
+	l2, l3 := aO, sL
+
+	var t1, t3, t4, t5, t6 ristretto.Scalar
+	t1 = innerProduct(l1, r0)
+	t3 = innerProduct(l2, r1)
+	t3Right := innerProduct(l3, r0)
+	t3.Add(&t3, &t3Right)
+	t4 = innerProduct(l1, r3)
+	t4Right := innerProduct(l3, r1)
+	t4.Add(&t4, &t4Right)
+	t5 = innerProduct(l2, r3)
+	t6 = innerProduct(l3, r3)
+
+	tBlinds := randomScalars(5)
+	tCoeffs := []ristretto.Scalar{t1, t3, t4, t5, t6}
+	T := make([]ristretto.Point, 5)
+	for i := range T {
+		T[i] = p.ped.CommitToScalarWithBlind(tCoeffs[i], tBlinds[i]).Value
+		p.ts.AppendPoint("T", T[i])
+	}
+
+	x := p.challenge("x")
+	xPows := vector.ScalarPowers(x, 7)
+
+	l := make([]ristretto.Scalar, n)
+	r := make([]ristretto.Scalar, n)
+	for i := 0; i < n; i++ {
+		l[i].Mul(&l1[i], &xPows[1])
+		l[i].MulAdd(&l2[i], &xPows[2], &l[i])
+		l[i].MulAdd(&l3[i], &xPows[3], &l[i])
+
+		r[i].MulAdd(&r1[i], &xPows[1], &r0[i])
+		r[i].MulAdd(&r3[i], &xPows[3], &r[i])
+	}
+	tx := innerProduct(l, r)
+
+	// txBlinding = sum(tBlind_i * x^i) + x^2 <wV, vBlinds>
+	var txBlinding ristretto.Scalar
+	txBlinding.SetZero()
+	for i, pow := range []int{1, 3, 4, 5, 6} {
+		txBlinding.MulAdd(&tBlinds[i], &xPows[pow], &txBlinding)
+	}
+	wVBlinds := innerProduct(fw.wV, p.vBlinds)
+	txBlinding.MulAdd(&wVBlinds, &xPows[2], &txBlinding)
+
+	// eBlinding = iBlind x + oBlind x^2 + sBlind x^3
+	var eBlinding ristretto.Scalar
+	eBlinding.Mul(&iBlind, &xPows[1])
+	eBlinding.MulAdd(&oBlind, &xPows[2], &eBlinding)
+	eBlinding.MulAdd(&sBlind, &xPows[3], &eBlinding)
+
+	p.ts.AppendScalar("tx", tx)
+	p.ts.AppendScalar("tx_blinding", txBlinding)
+	p.ts.AppendScalar("e_blinding", eBlinding)
+	w := p.challenge("w")
+	var Q ristretto.Point
+	Q.ScalarMult(&B, &w)
+
+	ip, err := innerproduct.Generate(p.ts, G, H, l, r, yInvN, Q)
+	if err != nil {
+		return nil, errors.Wrap(err, "[Prove] - ipproof")
+	}
+
+	return &Proof{
+		AI:         AI,
+		AO:         AO,
+		S:          S,
+		T1:         T[0],
+		T3:         T[1],
+		T4:         T[2],
+		T5:         T[3],
+		T6:         T[4],
+		TX:         tx,
+		TXBlinding: txBlinding,
+		EBlinding:  eBlinding,
+		IPProof:    ip,
+	}, nil
+}
+
+// commitVectors computes blind * BBlind + <a, G> + <b, H>. b and H can be nil
+func commitVectors(BBlind ristretto.Point, blind ristretto.Scalar, G []ristretto.Point, a []ristretto.Scalar, H []ristretto.Point, b []ristretto.Scalar) (ristretto.Point, error) {
+	var res ristretto.Point
+	res.ScalarMult(&BBlind, &blind)
+
+	aG, err := vector.Exp(a, G, len(G), 1)
+	if err != nil {
+		return res, err
+	}
+	res.Add(&res, &aG)
+
+	if H == nil {
+		return res, nil
+	}
+
+	bH, err := vector.Exp(b, H, len(H), 1)
+	if err != nil {
+		return res, err
+	}
+	res.Add(&res, &bH)
+	return res, nil
+}
+
+// innerProduct is vector.InnerProduct for vectors of known equal length
+func innerProduct(a, b []ristretto.Scalar) ristretto.Scalar {
+	res, _ := vector.InnerProduct(a, b)
+	return res
+}
+
+func padScalars(a []ristretto.Scalar, n int) []ristretto.Scalar {
+	res := make([]ristretto.Scalar, n)
+	copy(res, a)
+	for i := len(a); i < n; i++ {
+		res[i].SetZero()
+	}
+	return res
+}
+
+func randomScalars(n int) []ristretto.Scalar {
+	res := make([]ristretto.Scalar, n)
+	for i := range res {
+		rng.Scalar(&res[i])
+	}
+	return res
+}
@@ -0,0 +1,196 @@
+// Package r1cs implements the Bulletproofs arithmetic circuit protocol
+// (section 5 of https://eprint.iacr.org/2017/1066.pdf) over a rank-1
+// constraint system. Statements are expressed as gadgets which only talk to
+// the ConstraintSystem interface, so the same gadget code drives both the
+// Prover and the Verifier.
+package r1cs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rangeproof/innerproduct"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// genData is the seed for the vector generators used by the constraint system.
+// The value and blinding bases are the same as the ones used by the range proof,
+// so commitments can be shared between the two
+var genData = []byte("dusk.R1CS.vec1")
+
+// ConstraintSystem is the interface gadgets are written against.
+// It is implemented by the Prover and by the Verifier
+type ConstraintSystem interface {
+	// Multiply adds a multiplication gate for left * right, constraining the
+	// gate inputs to the linear combinations and returning the left, right
+	// and output variables
+	Multiply(left, right LinearCombination) (Variable, Variable, Variable)
+	// Allocate creates a free variable. The Prover needs the assignment,
+	// the Verifier ignores it and can be passed nil
+	Allocate(assignment *ristretto.Scalar) Variable
+	// Constrain enforces that the linear combination evaluates to zero
+	Constrain(lc LinearCombination)
+	// Challenge returns a scalar bound to all the commitments made so far.
+	// No commitment can be added once a challenge has been drawn
+	Challenge() ristretto.Scalar
+}
+
+// Proof is an arithmetic circuit proof
+type Proof struct {
+	AI ristretto.Point // commitment to the left and right multiplier inputs
+	AO ristretto.Point // commitment to the multiplier outputs
+	S  ristretto.Point // commitment to the blinding vectors
+
+	T1, T3, T4, T5, T6 ristretto.Point // commitments to the coefficients of t(x)
+
+	TX         ristretto.Scalar // t(x)
+	TXBlinding ristretto.Scalar // blinding factor of t(x)
+	EBlinding  ristretto.Scalar // blinding factor of the l(x) and r(x) vectors
+
+	IPProof *innerproduct.Proof
+}
+
+// proofTranscript is the Fiat-Shamir state shared by the prover and verifier
+type proofTranscript struct {
+	ts         *transcript.Transcript
+	challenged bool
+}
+
+func newTranscript() proofTranscript {
+	return proofTranscript{ts: transcript.New("dusk.R1CS")}
+}
+
+func (t *proofTranscript) challenge(label string) ristretto.Scalar {
+	t.challenged = true
+	return t.ts.ChallengeScalar(label)
+}
+
+// flattenedWeights holds the constraints collapsed with powers of z, s.t.
+// <wL, aL> + <wR, aR> + <wO, aO> = <wV, v> + wc
+type flattenedWeights struct {
+	wL, wR, wO, wV []ristretto.Scalar
+	wc             ristretto.Scalar
+}
+
+// flatten combines the constraints using the challenge z, padding the
+// multiplier weights to n entries
+func flatten(constraints []LinearCombination, z ristretto.Scalar, n, m int) flattenedWeights {
+	fw := flattenedWeights{
+		wL: make([]ristretto.Scalar, n),
+		wR: make([]ristretto.Scalar, n),
+		wO: make([]ristretto.Scalar, n),
+		wV: make([]ristretto.Scalar, m),
+	}
+	fw.wc.SetZero()
+	for i := 0; i < n; i++ {
+		fw.wL[i].SetZero()
+		fw.wR[i].SetZero()
+		fw.wO[i].SetZero()
+	}
+	for i := 0; i < m; i++ {
+		fw.wV[i].SetZero()
+	}
+
+	var zq ristretto.Scalar
+	zq.Set(&z)
+	for _, lc := range constraints {
+		for _, term := range lc {
+			var w ristretto.Scalar
+			w.Mul(&zq, &term.Coeff)
+
+			switch term.Variable.Type {
+			case MultiplierLeft:
+				fw.wL[term.Variable.Index].Add(&fw.wL[term.Variable.Index], &w)
+			case MultiplierRight:
+				fw.wR[term.Variable.Index].Add(&fw.wR[term.Variable.Index], &w)
+			case MultiplierOutput:
+				fw.wO[term.Variable.Index].Add(&fw.wO[term.Variable.Index], &w)
+			case Committed:
+				fw.wV[term.Variable.Index].Sub(&fw.wV[term.Variable.Index], &w)
+			case One:
+				fw.wc.Sub(&fw.wc, &w)
+			}
+		}
+		zq.Mul(&zq, &z)
+	}
+
+	return fw
+}
+
+// generators returns the value base, the blinding base and the n G and H
+// vector generators
+func generators(n int) (ristretto.Point, ristretto.Point, []ristretto.Point, []ristretto.Point) {
+	ped := pedersen.New(genData)
+	ped.BaseVector.Compute(uint32(n))
+
+	ped2 := pedersen.New(append(append([]byte{}, genData...), uint8(1)))
+	ped2.BaseVector.Compute(uint32(n))
+
+	return ped.BasePoint, ped.BlindPoint, ped.BaseVector.Bases, ped2.BaseVector.Bases
+}
+
+// padLength returns the number of multipliers rounded up to a power of two
+func padLength(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// Encode a Proof
+func (p *Proof) Encode(w io.Writer) error {
+	points := []ristretto.Point{p.AI, p.AO, p.S, p.T1, p.T3, p.T4, p.T5, p.T6}
+	for i := range points {
+		if err := binary.Write(w, binary.BigEndian, points[i].Bytes()); err != nil {
+			return err
+		}
+	}
+
+	scalars := []ristretto.Scalar{p.TX, p.TXBlinding, p.EBlinding}
+	for i := range scalars {
+		if err := binary.Write(w, binary.BigEndian, scalars[i].Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return p.IPProof.Encode(w)
+}
+
+// Decode a Proof. The inner product proof consumes the remainder of the reader
+func (p *Proof) Decode(r io.Reader) error {
+	if p == nil {
+		return errors.New("struct is nil")
+	}
+
+	points := []*ristretto.Point{&p.AI, &p.AO, &p.S, &p.T1, &p.T3, &p.T4, &p.T5, &p.T6}
+	for _, point := range points {
+		var x [32]byte
+		if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+			return err
+		}
+		if !point.SetBytes(&x) {
+			return errors.New("point not encodable")
+		}
+	}
+
+	scalars := []*ristretto.Scalar{&p.TX, &p.TXBlinding, &p.EBlinding}
+	for _, s := range scalars {
+		var x [32]byte
+		if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+			return err
+		}
+		s.SetBytes(&x)
+		if !bytes.Equal(s.Bytes(), x[:]) {
+			return errors.New("scalar is not canonically encoded")
+		}
+	}
+
+	p.IPProof = &innerproduct.Proof{}
+	return p.IPProof.Decode(r)
+}
@@ -0,0 +1,169 @@
+package r1cs
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scalar(n int64) ristretto.Scalar {
+	var s ristretto.Scalar
+	s.SetBigInt(big.NewInt(n))
+	return s
+}
+
+func randScalar() ristretto.Scalar {
+	var s ristretto.Scalar
+	s.Rand()
+	return s
+}
+
+// mulGadget constrains a * b = c
+func mulGadget(cs ConstraintSystem, a, b, c Variable) {
+	_, _, o := cs.Multiply(a.LC(), b.LC())
+	cs.Constrain(o.LC().Sub(c.LC()))
+}
+
+func proveMul(t *testing.T, a, b, c int64) (*Proof, []ristretto.Point) {
+	prover := NewProver()
+	aVar, A := prover.Commit(scalar(a), randScalar())
+	bVar, B := prover.Commit(scalar(b), randScalar())
+	cVar, C := prover.Commit(scalar(c), randScalar())
+	mulGadget(prover, aVar, bVar, cVar)
+
+	proof, err := prover.Prove()
+	if err != nil {
+		return nil, nil
+	}
+	return proof, []ristretto.Point{A, B, C}
+}
+
+func verifyMul(proof *Proof, commitments []ristretto.Point) error {
+	verifier := NewVerifier()
+	aVar := verifier.Commit(commitments[0])
+	bVar := verifier.Commit(commitments[1])
+	cVar := verifier.Commit(commitments[2])
+	mulGadget(verifier, aVar, bVar, cVar)
+	return verifier.Verify(proof)
+}
+
+func TestMultiplication(t *testing.T) {
+	proof, comms := proveMul(t, 3, 7, 21)
+	require.NotNil(t, proof)
+	assert.Nil(t, verifyMul(proof, comms))
+
+	// swapping a commitment breaks the proof
+	comms[2] = comms[0]
+	assert.NotNil(t, verifyMul(proof, comms))
+
+	// an unsatisfied constraint system cannot be proven
+	proof, _ = proveMul(t, 3, 7, 22)
+	assert.Nil(t, proof)
+}
+
+func TestLinearConstraintsOnly(t *testing.T) {
+	// a + b = 10
+	prover := NewProver()
+	a, A := prover.Commit(scalar(4), randScalar())
+	b, B := prover.Commit(scalar(6), randScalar())
+	prover.Constrain(a.LC().Add(b.LC()).Sub(ConstantInt(10)))
+	proof, err := prover.Prove()
+	require.Nil(t, err)
+
+	verifier := NewVerifier()
+	a = verifier.Commit(A)
+	b = verifier.Commit(B)
+	verifier.Constrain(a.LC().Add(b.LC()).Sub(ConstantInt(10)))
+	assert.Nil(t, verifier.Verify(proof))
+
+	verifier = NewVerifier()
+	a = verifier.Commit(A)
+	b = verifier.Commit(B)
+	verifier.Constrain(a.LC().Add(b.LC()).Sub(ConstantInt(11)))
+	assert.NotNil(t, verifier.Verify(proof))
+}
+
+func shuffleProof(t *testing.T, x, y []int64) (*Proof, []ristretto.Point, []ristretto.Point, error) {
+	prover := NewProver()
+	xVars, yVars := make([]Variable, len(x)), make([]Variable, len(y))
+	X, Y := make([]ristretto.Point, len(x)), make([]ristretto.Point, len(y))
+	for i := range x {
+		xVars[i], X[i] = prover.Commit(scalar(x[i]), randScalar())
+	}
+	for i := range y {
+		yVars[i], Y[i] = prover.Commit(scalar(y[i]), randScalar())
+	}
+	require.Nil(t, Shuffle(prover, xVars, yVars))
+	proof, err := prover.Prove()
+	return proof, X, Y, err
+}
+
+func verifyShuffle(t *testing.T, proof *Proof, X, Y []ristretto.Point) error {
+	verifier := NewVerifier()
+	xVars, yVars := make([]Variable, len(X)), make([]Variable, len(Y))
+	for i := range X {
+		xVars[i] = verifier.Commit(X[i])
+	}
+	for i := range Y {
+		yVars[i] = verifier.Commit(Y[i])
+	}
+	require.Nil(t, Shuffle(verifier, xVars, yVars))
+	return verifier.Verify(proof)
+}
+
+func TestShuffle(t *testing.T) {
+	for _, k := range []int{1, 2, 3, 4, 7} {
+		x := make([]int64, k)
+		y := make([]int64, k)
+		for i := range x {
+			x[i] = int64(i * 11)
+			y[k-1-i] = int64(i * 11)
+		}
+
+		proof, X, Y, err := shuffleProof(t, x, y)
+		require.Nil(t, err)
+		assert.Nil(t, verifyShuffle(t, proof, X, Y))
+
+		buf := &bytes.Buffer{}
+		require.Nil(t, proof.Encode(buf))
+		var decoded Proof
+		require.Nil(t, decoded.Decode(buf))
+		assert.Nil(t, verifyShuffle(t, &decoded, X, Y))
+	}
+
+	_, _, _, err := shuffleProof(t, []int64{1, 2, 3}, []int64{1, 2, 4})
+	assert.NotNil(t, err)
+}
+
+func TestSetMembership(t *testing.T) {
+	denominations := []ristretto.Scalar{scalar(1), scalar(5), scalar(10), scalar(50), scalar(100)}
+
+	for _, set := range [][]ristretto.Scalar{denominations[:1], denominations} {
+		proof, V, err := ProveMembership(set[len(set)-1], randScalar(), set)
+		require.Nil(t, err)
+		assert.Nil(t, VerifyMembership(V, set, proof))
+
+		// the proof does not transfer to another set
+		other := append([]ristretto.Scalar{scalar(2)}, set[1:]...)
+		assert.NotNil(t, VerifyMembership(V, other, proof))
+	}
+
+	_, _, err := ProveMembership(scalar(7), randScalar(), denominations)
+	assert.NotNil(t, err)
+
+	_, _, err = ProveMembership(scalar(7), randScalar(), nil)
+	assert.NotNil(t, err)
+}
+
+func TestCommitAfterChallenge(t *testing.T) {
+	prover := NewProver()
+	prover.Commit(scalar(1), randScalar())
+	prover.Challenge()
+	prover.Commit(scalar(2), randScalar())
+	_, err := prover.Prove()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,202 @@
+package r1cs
+
+import (
+	"github.com/pkg/errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rangeproof/vector"
+)
+
+// Verifier rebuilds the constraint system from the public commitments and
+// checks a proof against it
+type Verifier struct {
+	proofTranscript
+
+	V []ristretto.Point
+
+	// number of multipliers allocated by the gadgets
+	numMultipliers int
+
+	constraints []LinearCombination
+
+	err error
+}
+
+// NewVerifier returns a Verifier with an empty constraint system
+func NewVerifier() *Verifier {
+	return &Verifier{
+		proofTranscript: newTranscript(),
+	}
+}
+
+// Commit adds a high-level commitment received from the prover and returns
+// the variable bound to it. Commitments must be added in the prover's order
+func (v *Verifier) Commit(V ristretto.Point) Variable {
+	if v.challenged {
+		v.err = errors.New("[Commit] - cannot commit after a challenge was drawn")
+	}
+
+	v.ts.AppendPoint("V", V)
+	v.V = append(v.V, V)
+	return Variable{Type: Committed, Index: len(v.V) - 1}
+}
+
+// Multiply implements ConstraintSystem
+func (v *Verifier) Multiply(left, right LinearCombination) (Variable, Variable, Variable) {
+	lVar, rVar, oVar := v.allocateMultiplier()
+
+	v.Constrain(left.Sub(lVar.LC()))
+	v.Constrain(right.Sub(rVar.LC()))
+
+	return lVar, rVar, oVar
+}
+
+// Allocate implements ConstraintSystem. The assignment is ignored
+func (v *Verifier) Allocate(_ *ristretto.Scalar) Variable {
+	l, _, _ := v.allocateMultiplier()
+	return l
+}
+
+// Constrain implements ConstraintSystem
+func (v *Verifier) Constrain(lc LinearCombination) {
+	v.constraints = append(v.constraints, lc)
+}
+
+// Challenge implements ConstraintSystem
+func (v *Verifier) Challenge() ristretto.Scalar {
+	return v.challenge("c")
+}
+
+func (v *Verifier) allocateMultiplier() (Variable, Variable, Variable) {
+	i := v.numMultipliers
+	v.numMultipliers++
+
+	return Variable{Type: MultiplierLeft, Index: i},
+		Variable{Type: MultiplierRight, Index: i},
+		Variable{Type: MultiplierOutput, Index: i}
+}
+
+// Verify checks the proof against the constraints added by the gadgets.
+// It returns nil only if the proof is valid. A Verifier checks a single proof,
+// as verifying advances its transcript
+func (v *Verifier) Verify(proof *Proof) error {
+	if v.err != nil {
+		return v.err
+	}
+
+	if proof == nil || proof.IPProof == nil {
+		return errors.New("[Verify] - proof is incomplete")
+	}
+
+	n := padLength(v.numMultipliers)
+	m := len(v.V)
+
+	if len(proof.IPProof.L) != len(proof.IPProof.R) || 1<<uint(len(proof.IPProof.L)) != n {
+		return errors.Errorf("[Verify] - expected an inner product proof for %d multipliers", n)
+	}
+
+	B, BBlind, G, H := generators(n)
+
+	v.ts.AppendUint64("m", uint64(m))
+	v.ts.AppendUint64("n", uint64(n))
+	v.ts.AppendPoint("AI", proof.AI)
+	v.ts.AppendPoint("AO", proof.AO)
+	v.ts.AppendPoint("S", proof.S)
+	y := v.challenge("y")
+	z := v.challenge("z")
+
+	T := []ristretto.Point{proof.T1, proof.T3, proof.T4, proof.T5, proof.T6}
+	for i := range T {
+		v.ts.AppendPoint("T", T[i])
+	}
+	x := v.challenge("x")
+
+	v.ts.AppendScalar("tx", proof.TX)
+	v.ts.AppendScalar("tx_blinding", proof.TXBlinding)
+	v.ts.AppendScalar("e_blinding", proof.EBlinding)
+	w := v.challenge("w")
+
+	fw := flatten(v.constraints, z, n, m)
+
+	var yInv ristretto.Scalar
+	yInv.Inverse(&y)
+	yInvN := vector.ScalarPowers(yInv, uint32(n))
+	xPows := vector.ScalarPowers(x, 7)
+
+	// delta = <y^-n o wR, wL>
+	yInvWR, err := vector.Hadamard(yInvN, fw.wR)
+	if err != nil {
+		return errors.Wrap(err, "[Verify] - delta")
+	}
+	delta := innerProduct(yInvWR, fw.wL)
+
+	// Check that t(x) is consistent with the commitments:
+	// tx B + txBlinding BBlind = x^2 (<wV, V> + (delta + wc) B) + sum(x^i T_i)
+	var lhs, rhs, tmp ristretto.Point
+	lhs.ScalarMult(&B, &proof.TX)
+	tmp.ScalarMult(&BBlind, &proof.TXBlinding)
+	lhs.Add(&lhs, &tmp)
+
+	wVV, err := vector.Exp(fw.wV, v.V, m, 1)
+	if err != nil {
+		return errors.Wrap(err, "[Verify] - wV")
+	}
+	var deltaWc ristretto.Scalar
+	deltaWc.Add(&delta, &fw.wc)
+	tmp.ScalarMult(&B, &deltaWc)
+	rhs.Add(&wVV, &tmp)
+	rhs.ScalarMult(&rhs, &xPows[2])
+
+	for i, pow := range []int{1, 3, 4, 5, 6} {
+		tmp.ScalarMult(&T[i], &xPows[pow])
+		rhs.Add(&rhs, &tmp)
+	}
+
+	if !lhs.Equals(&rhs) {
+		return errors.New("[Verify] - t(x) does not match the commitments")
+	}
+
+	// Rebuild the commitment to l(x) and r(x):
+	// P = x AI + x^2 AO + x^3 S - eBlinding BBlind + <x y^-n o wR, G>
+	//     + <y^-n o (x wL + wO) - 1, H> + tx Q
+	var P ristretto.Point
+	P.ScalarMult(&proof.AI, &xPows[1])
+	tmp.ScalarMult(&proof.AO, &xPows[2])
+	P.Add(&P, &tmp)
+	tmp.ScalarMult(&proof.S, &xPows[3])
+	P.Add(&P, &tmp)
+	tmp.ScalarMult(&BBlind, &proof.EBlinding)
+	P.Sub(&P, &tmp)
+
+	gScalars := vector.MulScalar(yInvWR, x)
+	hScalars := make([]ristretto.Scalar, n)
+	var one ristretto.Scalar
+	one.SetOne()
+	for i := 0; i < n; i++ {
+		hScalars[i].MulAdd(&x, &fw.wL[i], &fw.wO[i])
+		hScalars[i].Mul(&hScalars[i], &yInvN[i])
+		hScalars[i].Sub(&hScalars[i], &one)
+	}
+
+	gSum, err := vector.Exp(gScalars, G, n, 1)
+	if err != nil {
+		return errors.Wrap(err, "[Verify] - G")
+	}
+	hSum, err := vector.Exp(hScalars, H, n, 1)
+	if err != nil {
+		return errors.Wrap(err, "[Verify] - H")
+	}
+	P.Add(&P, &gSum)
+	P.Add(&P, &hSum)
+
+	var Q ristretto.Point
+	Q.ScalarMult(&B, &w)
+	tmp.ScalarMult(&Q, &proof.TX)
+	P.Add(&P, &tmp)
+
+	if !proof.IPProof.Verify(v.ts, G, H, proof.IPProof.L, proof.IPProof.R, yInvN, Q, P, n) {
+		return errors.New("[Verify] - inner product proof failed")
+	}
+
+	return nil
+}
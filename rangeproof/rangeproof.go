@@ -1,29 +1,31 @@
 package rangeproof
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
 	"math/big"
+	"math/bits"
 
 	"github.com/pkg/errors"
 
 	ristretto "github.com/bwesterb/go-ristretto"
-	"github.com/dusk-network/dusk-crypto/rangeproof/fiatshamir"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/hash"
 	"github.com/dusk-network/dusk-crypto/rangeproof/innerproduct"
 	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
 	"github.com/dusk-network/dusk-crypto/rangeproof/vector"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/transcript"
 )
 
 // N is number of bits in range
 // So amount will be between 0...2^(N-1)
 const N = 64
 
-// M is the number of outputs for one bulletproof
-var M = 1
-
-// M is the maximum number of values allowed per rangeproof
-const maxM = 16
+// maxM is the maximum number of values allowed per rangeproof
+const maxM = 64
 
 // Proof is the constructed BulletProof
 type Proof struct {
@@ -43,19 +45,57 @@ type Proof struct {
 
 // Prove will take a set of scalars as a parameter and prove that it is [0, 2^N)
 func Prove(v []ristretto.Scalar, debug bool) (Proof, error) {
+	return prove(v, nil, nil, nil, debug)
+}
+
+// ProveUint64 proves that every amount is in [0, 2^N), committing to the
+// amounts with the given blinding factors. If blinds is nil, random
+// blinding factors are used instead.
+// The amounts are converted to scalars directly, without going through big.Int
+func ProveUint64(amounts []uint64, blinds []ristretto.Scalar) (Proof, error) {
+	if blinds != nil && len(blinds) != len(amounts) {
+		return Proof{}, fmt.Errorf("got %d blinding factors for %d amounts", len(blinds), len(amounts))
+	}
+
+	v := make([]ristretto.Scalar, len(amounts))
+	for i := range amounts {
+		v[i] = scalarFromUint64(amounts[i])
+	}
+
+	return prove(v, blinds, nil, nil, false)
+}
+
+// ProveWithExtraData works like Prove, but also binds extraData (e.g. the
+// transaction hash or the output index) to the Fiat-Shamir transcript.
+// The resulting proof only verifies through VerifyWithExtraData with the same data
+func ProveWithExtraData(v []ristretto.Scalar, extraData []byte, debug bool) (Proof, error) {
+	return prove(v, nil, extraData, nil, debug)
+}
+
+// ProveWithTrace works like ProveWithExtraData, writing a labeled trace of the
+// Fiat-Shamir transcript to w, see transcript.Transcript.SetTrace. Comparing
+// it with the trace of a verifier shows which challenge diverged
+func ProveWithTrace(v []ristretto.Scalar, extraData []byte, w io.Writer) (Proof, error) {
+	return prove(v, nil, extraData, w, false)
+}
+
+// prove creates the proof. blinds may be nil, in which case every
+// commitment gets a random blinding factor. The transcript is traced to
+// trace, unless it is nil
+func prove(v, blinds []ristretto.Scalar, extraData []byte, trace io.Writer, debug bool) (Proof, error) {
 
 	if len(v) < 1 {
 		return Proof{}, errors.New("length of slice v is zero")
 	}
 
-	M = len(v)
-	if M > maxM {
-		return Proof{}, fmt.Errorf("maximum amount of values must be less than %d", maxM)
+	m := len(v)
+	if m > maxM {
+		return Proof{}, fmt.Errorf("maximum amount of values must be at most %d", maxM)
 	}
 
 	// Pad zero values until we have power of two
-	padAmount := innerproduct.DiffNextPow2(uint32(M))
-	M = M + int(padAmount)
+	padAmount := innerproduct.DiffNextPow2(uint32(m))
+	m += int(padAmount)
 	for i := uint32(0); i < padAmount; i++ {
 		var zeroScalar ristretto.Scalar
 		zeroScalar.SetZero()
@@ -63,30 +103,41 @@ func Prove(v []ristretto.Scalar, debug bool) (Proof, error) {
 	}
 
 	// commitment to values v
-	Vs := make([]pedersen.Commitment, 0, M)
+	Vs := make([]pedersen.Commitment, 0, m)
 	genData := []byte("dusk.BulletProof.vec1")
 	ped := pedersen.New(genData)
-	ped.BaseVector.Compute(uint32((N * M)))
+	ped.BaseVector.Compute(uint32((N * m)))
 
-	// Hash for Fiat-Shamir
-	hs := fiatshamir.HashCacher{Cache: []byte{}}
+	// Fiat-Shamir transcript
+	ts := newTranscript(m, extraData, trace)
 
-	for _, amount := range v {
+	for i, amount := range v {
 		// compute commmitment to v
-		V := ped.CommitToScalar(amount)
+		var V pedersen.Commitment
+		if i < len(blinds) {
+			V = ped.CommitToScalarWithBlind(amount, blinds[i])
+		} else {
+			V = ped.CommitToScalar(amount)
+		}
 
 		Vs = append(Vs, V)
 
 		// update Fiat-Shamir
-		hs.Append(V.Value.Bytes())
+		ts.AppendPoint("V", V.Value)
 	}
 
-	aLs := make([]ristretto.Scalar, 0, N*M)
-	aRs := make([]ristretto.Scalar, 0, N*M)
+	// The bits of the amounts and their blinding vectors are kept in
+	// locked memory, wiped once the proof is done
+	secrets, wipe := secretScalars(4 * N * m)
+	defer wipe()
+	aLs := secrets[0 : 0 : N*m]
+	aRs := secrets[N*m : N*m : 2*N*m]
+	sL := secrets[2*N*m : 3*N*m : 3*N*m]
+	sR := secrets[3*N*m : 4*N*m : 4*N*m]
 
 	for i := range v {
 		// Compute Bitcommits aL and aR to v
-		BC := BitCommit(v[i].BigInt())
+		BC := BitCommitScalar(v[i])
 		aLs = append(aLs, BC.AL...)
 		aRs = append(aRs, BC.AR...)
 	}
@@ -95,16 +146,17 @@ func Prove(v []ristretto.Scalar, debug bool) (Proof, error) {
 	A := computeA(ped, aLs, aRs)
 
 	// // Compute S
-	S, sL, sR := computeS(ped)
+	S := computeS(ped, sL, sR)
 
 	// // update Fiat-Shamir
-	hs.Append(A.Value.Bytes(), S.Value.Bytes())
+	ts.AppendPoint("A", A.Value)
+	ts.AppendPoint("S", S.Value)
 
 	// compute y and z
-	y, z := computeYAndZ(hs)
+	y, z := computeYAndZ(ts)
 
 	// compute polynomial
-	poly, err := computePoly(aLs, aRs, sL, sR, y, z)
+	poly, err := computePoly(m, aLs, aRs, sL, sR, y, z)
 	if err != nil {
 		return Proof{}, errors.Wrap(err, "[Prove] - poly")
 	}
@@ -114,10 +166,11 @@ func Prove(v []ristretto.Scalar, debug bool) (Proof, error) {
 	T2 := ped.CommitToScalar(poly.t2)
 
 	// update Fiat-Shamir
-	hs.Append(z.Bytes(), T1.Value.Bytes(), T2.Value.Bytes())
+	ts.AppendPoint("T1", T1.Value)
+	ts.AppendPoint("T2", T2.Value)
 
 	// compute x
-	x := computeX(hs)
+	x := computeX(ts)
 	// compute taux which is just the polynomial for the blinding factors at a point x
 	taux := computeTaux(x, z, T1.BlindingFactor, T2.BlindingFactor, Vs)
 	// compute mu
@@ -139,13 +192,13 @@ func Prove(v []ristretto.Scalar, debug bool) (Proof, error) {
 
 	// START DEBUG
 	if debug {
-		err := debugProve(x, y, z, v, l, r, aLs, aRs, sL, sR)
+		err := debugProve(m, x, y, z, v, l, r, aLs, aRs, sL, sR)
 		if err != nil {
 			return Proof{}, errors.Wrap(err, "[Prove] - debugProve")
 		}
 
 		// DEBUG T0
-		testT0, err := debugT0(aLs, aRs, y, z)
+		testT0, err := debugT0(m, aLs, aRs, y, z)
 		if err != nil {
 			return Proof{}, errors.Wrap(err, "[Prove] - testT0")
 
@@ -154,7 +207,7 @@ func Prove(v []ristretto.Scalar, debug bool) (Proof, error) {
 			return Proof{}, errors.New("[Prove]: Test t0 value does not match the value calculated from the polynomial")
 		}
 
-		polyt0 := poly.computeT0(y, z, v, N, uint32(M))
+		polyt0 := poly.computeT0(y, z, v, N, uint32(m))
 		if !polyt0.Equals(&poly.t0) {
 			return Proof{}, errors.New("[Prove]: t0 value from delta function, does not match the polynomial t0 value(Correct)")
 		}
@@ -171,25 +224,27 @@ func Prove(v []ristretto.Scalar, debug bool) (Proof, error) {
 		return Proof{}, errors.New("[Prove] - One of the challenge scalars, x, y, or z was equal to zero. Generate proof again")
 	}
 
-	hs.Append(x.Bytes(), taux.Bytes(), mu.Bytes(), t.Bytes())
+	ts.AppendScalar("taux", taux)
+	ts.AppendScalar("mu", mu)
+	ts.AppendScalar("t", t)
 
 	// calculate inner product proof
 	Q := ristretto.Point{}
-	w := hs.Derive()
+	w := ts.ChallengeScalar("w")
 	Q.ScalarMult(&ped.BasePoint, &w)
 
 	var yinv ristretto.Scalar
 	yinv.Inverse(&y)
-	Hpf := vector.ScalarPowers(yinv, uint32(N*M))
+	Hpf := vector.ScalarPowers(yinv, uint32(N*m))
 
 	genData = append(genData, uint8(1))
 	ped2 := pedersen.New(genData)
-	ped2.BaseVector.Compute(uint32(N * M))
+	ped2.BaseVector.Compute(uint32(N * m))
 
 	H := ped2.BaseVector.Bases
 	G := ped.BaseVector.Bases
 
-	ip, err := innerproduct.Generate(G, H, l, r, Hpf, Q)
+	ip, err := innerproduct.Generate(ts, G, H, l, r, Hpf, Q)
 	if err != nil {
 		return Proof{}, errors.Wrap(err, "[Prove] -  ipproof")
 	}
@@ -207,6 +262,17 @@ func Prove(v []ristretto.Scalar, debug bool) (Proof, error) {
 	}, nil
 }
 
+// scalarFromUint64 sets a scalar from its little endian encoding, which
+// is always canonical for a uint64
+func scalarFromUint64(n uint64) ristretto.Scalar {
+	var buf [32]byte
+	binary.LittleEndian.PutUint64(buf[:8], n)
+
+	var s ristretto.Scalar
+	s.SetBytes(&buf)
+	return s
+}
+
 // A = kH + aL*G + aR*H
 func computeA(ped *pedersen.Pedersen, aLs, aRs []ristretto.Scalar) pedersen.Commitment {
 
@@ -215,40 +281,42 @@ func computeA(ped *pedersen.Pedersen, aLs, aRs []ristretto.Scalar) pedersen.Comm
 	return cA
 }
 
-// S = kH + sL*G + sR * H
-func computeS(ped *pedersen.Pedersen) (pedersen.Commitment, []ristretto.Scalar, []ristretto.Scalar) {
-
-	sL, sR := make([]ristretto.Scalar, N*M), make([]ristretto.Scalar, N*M)
-	for i := 0; i < N*M; i++ {
-		var randA ristretto.Scalar
-		randA.Rand()
-		sL[i] = randA
+// S = kH + sL*G + sR * H, for random sL and sR, which are written to the
+// given slices
+func computeS(ped *pedersen.Pedersen, sL, sR []ristretto.Scalar) pedersen.Commitment {
 
-		var randB ristretto.Scalar
-		randB.Rand()
-		sR[i] = randB
+	for i := range sL {
+		rng.Scalar(&sL[i])
+		rng.Scalar(&sR[i])
 	}
 
 	cS := ped.CommitToVectors(sL, sR)
 
-	return cS, sL, sR
+	return cS
 }
 
-func computeYAndZ(hs fiatshamir.HashCacher) (ristretto.Scalar, ristretto.Scalar) {
-
-	var y ristretto.Scalar
-	y.Derive(hs.Result())
-
-	var z ristretto.Scalar
-	z.Derive(y.Bytes())
+// newTranscript returns the transcript of a proof of m values, traced to
+// trace if it is not nil. The caller supplied extraData is absorbed before
+// the commitments; empty data leaves the transcript untouched, which keeps
+// proofs without extra data compatible with Prove/Verify
+func newTranscript(m int, extraData []byte, trace io.Writer) *transcript.Transcript {
+	ts := transcript.New("dusk.rangeproof")
+	ts.SetTrace(trace)
+	ts.AppendUint64("m", uint64(m))
+	if len(extraData) > 0 {
+		ts.Append("extra_data", extraData)
+	}
+	return ts
+}
 
+func computeYAndZ(ts *transcript.Transcript) (ristretto.Scalar, ristretto.Scalar) {
+	y := ts.ChallengeScalar("y")
+	z := ts.ChallengeScalar("z")
 	return y, z
 }
 
-func computeX(hs fiatshamir.HashCacher) ristretto.Scalar {
-	var x ristretto.Scalar
-	x.Derive(hs.Result())
-	return x
+func computeX(ts *transcript.Transcript) ristretto.Scalar {
+	return ts.ChallengeScalar("x")
 }
 
 // compute polynomial for blinding factors l61
@@ -319,45 +387,146 @@ func computeHprime(H []ristretto.Point, y ristretto.Scalar) []ristretto.Point {
 	return Hprimes
 }
 
-// Verify takes a bullet proof and returns true only if the proof was valid
+// Verify takes a bullet proof and returns true only if the proof was valid.
+// It is safe to call concurrently, with other verifications as well as with
+// proving
 func Verify(p Proof) (bool, error) {
+	return verify(p, nil, nil)
+}
+
+// VerifyWithExtraData verifies a proof created with ProveWithExtraData.
+// It fails if extraData differs from the data supplied to the prover
+func VerifyWithExtraData(p Proof, extraData []byte) (bool, error) {
+	return verify(p, extraData, nil)
+}
+
+// VerifyWithTrace works like VerifyWithExtraData, writing a labeled trace of
+// the Fiat-Shamir transcript to w, see ProveWithTrace
+func VerifyWithTrace(p Proof, extraData []byte, w io.Writer) (bool, error) {
+	return verify(p, extraData, w)
+}
+
+func verify(p Proof, extraData []byte, trace io.Writer) (bool, error) {
 
+	if err := p.checkLengths(); err != nil {
+		return false, err
+	}
+	if err := p.checkPoints(); err != nil {
+		return false, err
+	}
+	m := len(p.V)
+	ped, G, H := verifierGenerators(N * m)
+	y, z, x, w, ts := p.challenges(extraData, trace)
+
+	return megacheckWithC(m, ts, p.IPProof, p.mu, x, y, z, p.t, p.taux, w, p.A, ped.BasePoint, ped.BlindPoint, p.S, p.T1, p.T2, G, H, p.V)
+}
+
+// verifierGenerators returns the pedersen bases together with the first n
+// G and H vector generators
+func verifierGenerators(n int) (*pedersen.Pedersen, []ristretto.Point, []ristretto.Point) {
 	genData := []byte("dusk.BulletProof.vec1")
 	ped := pedersen.New(genData)
-	ped.BaseVector.Compute(uint32(N * M))
+	ped.BaseVector.Compute(uint32(n))
 
 	genData = append(genData, uint8(1))
 
 	ped2 := pedersen.New(genData)
-	ped2.BaseVector.Compute(uint32(N * M))
+	ped2.BaseVector.Compute(uint32(n))
 
-	G := ped.BaseVector.Bases
-	H := ped2.BaseVector.Bases
+	return ped, ped.BaseVector.Bases, ped2.BaseVector.Bases
+}
 
-	// Reconstruct the challenges
-	hs := fiatshamir.HashCacher{Cache: []byte{}}
+// challenges reconstructs the challenges y, z, x and w of the proof. The
+// transcript is returned in the state the inner product proof starts from
+func (p *Proof) challenges(extraData []byte, trace io.Writer) (ristretto.Scalar, ristretto.Scalar, ristretto.Scalar, ristretto.Scalar, *transcript.Transcript) {
+	ts := newTranscript(len(p.V), extraData, trace)
 	for _, V := range p.V {
-		hs.Append(V.Value.Bytes())
+		ts.AppendPoint("V", V.Value)
 	}
 
-	hs.Append(p.A.Bytes(), p.S.Bytes())
-	y, z := computeYAndZ(hs)
-	hs.Append(z.Bytes(), p.T1.Bytes(), p.T2.Bytes())
-	x := computeX(hs)
-	hs.Append(x.Bytes(), p.taux.Bytes(), p.mu.Bytes(), p.t.Bytes())
-	w := hs.Derive()
+	ts.AppendPoint("A", p.A)
+	ts.AppendPoint("S", p.S)
+	y, z := computeYAndZ(ts)
+	ts.AppendPoint("T1", p.T1)
+	ts.AppendPoint("T2", p.T2)
+	x := computeX(ts)
+	ts.AppendScalar("taux", p.taux)
+	ts.AppendScalar("mu", p.mu)
+	ts.AppendScalar("t", p.t)
+	w := ts.ChallengeScalar("w")
 
-	return megacheckWithC(p.IPProof, p.mu, x, y, z, p.t, p.taux, w, p.A, ped.BasePoint, ped.BlindPoint, p.S, p.T1, p.T2, G, H, p.V)
+	return y, z, x, w, ts
 }
 
-func megacheckWithC(ipproof *innerproduct.Proof, mu, x, y, z, t, taux, w ristretto.Scalar, A, G, H, S, T1, T2 ristretto.Point, GVec, HVec []ristretto.Point, V []pedersen.Commitment) (bool, error) {
-
-	var c1, c2, c3, c4, c5, c6, c7, c8, c9, c10, c11 ristretto.Point
+func megacheckWithC(m int, ts *transcript.Transcript, ipproof *innerproduct.Proof, mu, x, y, z, t, taux, w ristretto.Scalar, A, G, H, S, T1, T2 ristretto.Point, GVec, HVec []ristretto.Point, V []pedersen.Commitment) (bool, error) {
 
 	var c ristretto.Scalar
-	c.Rand()
+	rng.Scalar(&c)
+
+	terms, err := computeMegacheckTerms(m, ts, ipproof, mu, x, y, z, t, taux, w, c, A, S, T1, T2, V)
+	if err != nil {
+		return false, err
+	}
+
+	var c3, c4 ristretto.Point
+
+	c1, err := vector.Exp(terms.g, GVec, len(GVec), 1)
+	if err != nil {
+		return false, err
+	}
+
+	c2, err := vector.Exp(terms.h, HVec, len(HVec), 1)
+	if err != nil {
+		return false, err
+	}
+
+	c3.ScalarMult(&G, &terms.gBP)
+	c4.ScalarMult(&H, &terms.hBP)
+
+	var sum ristretto.Point
+	sum.SetZero()
+	sum.Add(&c1, &c2)
+	sum.Add(&sum, &c3)
+	sum.Add(&sum, &c4)
+	sum.Sub(&sum, &terms.rest)
+
+	var zero ristretto.Point
+	zero.SetZero()
+
+	ok := zero.Equals(&sum)
+	if !ok {
+		return false, cryptoerrors.New(cryptoerrors.ErrVerificationFailed, "megacheck failed")
+	}
+
+	return true, nil
+}
+
+// megacheckTerms is the verification equation of a proof. The proof is valid
+// iff <g, GVec> + <h, HVec> + gBP * G + hBP * H - rest = 0
+type megacheckTerms struct {
+	g, h     []ristretto.Scalar
+	gBP, hBP ristretto.Scalar
+	rest     ristretto.Point
+}
+
+// scale multiplies every term of the equation by r
+func (m *megacheckTerms) scale(r ristretto.Scalar) {
+	m.g = vector.MulScalar(m.g, r)
+	m.h = vector.MulScalar(m.h, r)
+	m.gBP.Mul(&m.gBP, &r)
+	m.hBP.Mul(&m.hBP, &r)
+	m.rest.ScalarMult(&m.rest, &r)
+}
 
-	uSq, uInvSq, s := ipproof.VerifScalars()
+// computeMegacheckTerms combines the inner product check, weighted by c,
+// with the check of t(x), for a proof of m values. It only depends on its
+// arguments, so that proofs can be verified concurrently
+func computeMegacheckTerms(m int, ts *transcript.Transcript, ipproof *innerproduct.Proof, mu, x, y, z, t, taux, w, c ristretto.Scalar, A, S, T1, T2 ristretto.Point, V []pedersen.Commitment) (megacheckTerms, error) {
+
+	var terms megacheckTerms
+	var c5, c6, c7, c8, c9, c10, c11 ristretto.Point
+
+	uSq, uInvSq, s := ipproof.VerifScalars(ts)
 	sInv := make([]ristretto.Scalar, len(s))
 	copy(sInv, s)
 
@@ -369,39 +538,29 @@ func megacheckWithC(ipproof *innerproduct.Proof, mu, x, y, z, t, taux, w ristret
 	// g vector scalars : as + z points : G
 	as := vector.MulScalar(s, ipproof.A)
 	g := vector.AddScalar(as, z)
-	g = vector.MulScalar(g, c)
-
-	c1, err := vector.Exp(g, GVec, len(GVec), 1)
-	if err != nil {
-		return false, err
-	}
+	terms.g = vector.MulScalar(g, c)
 
 	// h vector scalars : y Had (bsInv - zM2N) - z points : H
 	bs := vector.MulScalar(sInv, ipproof.B)
-	zAnd2 := sumZMTwoN(z)
+	zAnd2 := sumZMTwoN(z, m)
 	h, err := vector.Sub(bs, zAnd2)
 	if err != nil {
-		return false, errors.Wrap(err, "[h1]")
+		return terms, errors.Wrap(err, "[h1]")
 	}
 
 	var yinv ristretto.Scalar
 	yinv.Inverse(&y)
-	Hpf := vector.ScalarPowers(yinv, uint32(N*M))
+	Hpf := vector.ScalarPowers(yinv, uint32(N*m))
 
 	h, err = vector.Hadamard(h, Hpf)
 	if err != nil {
-		return false, errors.Wrap(err, "[h2]")
+		return terms, errors.Wrap(err, "[h2]")
 	}
 	h = vector.SubScalar(h, z)
-	h = vector.MulScalar(h, c)
-
-	c2, err = vector.Exp(h, HVec, len(HVec), 1)
-	if err != nil {
-		return false, err
-	}
+	terms.h = vector.MulScalar(h, c)
 
 	// G basepoint gbp : (c * w(ab-t)) + t-D(y,z) point : G
-	delta := computeDelta(y, z, N, uint32(M))
+	delta := computeDelta(y, z, N, uint32(m))
 	var tMinusDelta ristretto.Scalar
 	tMinusDelta.Sub(&t, &delta)
 
@@ -412,19 +571,13 @@ func megacheckWithC(ipproof *innerproduct.Proof, mu, x, y, z, t, taux, w ristret
 	var cw ristretto.Scalar
 	cw.Mul(&c, &w)
 
-	var gBP ristretto.Scalar
-	gBP.MulAdd(&cw, &abMinusT, &tMinusDelta)
-
-	c3.ScalarMult(&G, &gBP)
+	terms.gBP.MulAdd(&cw, &abMinusT, &tMinusDelta)
 
 	// H basepoint hbp : c * mu + taux point: H
 	var cmu ristretto.Scalar
 	cmu.Mul(&mu, &c)
 
-	var hBP ristretto.Scalar
-	hBP.Add(&cmu, &taux)
-
-	c4.ScalarMult(&H, &hBP)
+	terms.hBP.Add(&cmu, &taux)
 
 	// scalar :c point: A
 	c5.ScalarMult(&A, &c)
@@ -437,19 +590,19 @@ func megacheckWithC(ipproof *innerproduct.Proof, mu, x, y, z, t, taux, w ristret
 	// scalar: uSq challenges  points: Lj
 	c7, err = vector.Exp(uSq, ipproof.L, len(ipproof.L), 1)
 	if err != nil {
-		return false, err
+		return terms, err
 	}
 	c7.PublicScalarMult(&c7, &c)
 
 	// scalar : uInvSq challenges points: Rj
 	c8, err = vector.Exp(uInvSq, ipproof.R, len(ipproof.R), 1)
 	if err != nil {
-		return false, err
+		return terms, err
 	}
 	c8.PublicScalarMult(&c8, &c)
 
 	// scalar: z_j+2  points: Vj
-	zM := vector.ScalarPowers(z, uint32(M))
+	zM := vector.ScalarPowers(z, uint32(m))
 	var zSq ristretto.Scalar
 	zSq.Square(&z)
 	zM = vector.MulScalar(zM, zSq)
@@ -468,28 +621,15 @@ func megacheckWithC(ipproof *innerproduct.Proof, mu, x, y, z, t, taux, w ristret
 	xSq.Square(&x)
 	c11.PublicScalarMult(&T2, &xSq)
 
-	var sum ristretto.Point
-	sum.SetZero()
-	sum.Add(&c1, &c2)
-	sum.Add(&sum, &c3)
-	sum.Add(&sum, &c4)
-	sum.Sub(&sum, &c5)
-	sum.Sub(&sum, &c6)
-	sum.Sub(&sum, &c7)
-	sum.Sub(&sum, &c8)
-	sum.Sub(&sum, &c9)
-	sum.Sub(&sum, &c10)
-	sum.Sub(&sum, &c11)
-
-	var zero ristretto.Point
-	zero.SetZero()
+	terms.rest.SetZero()
+	terms.rest.Add(&c5, &c6)
+	terms.rest.Add(&terms.rest, &c7)
+	terms.rest.Add(&terms.rest, &c8)
+	terms.rest.Add(&terms.rest, &c9)
+	terms.rest.Add(&terms.rest, &c10)
+	terms.rest.Add(&terms.rest, &c11)
 
-	ok := zero.Equals(&sum)
-	if !ok {
-		return false, errors.New("megacheck failed")
-	}
-
-	return true, nil
+	return terms, nil
 }
 
 // Encode a Proof
@@ -577,19 +717,95 @@ func (p *Proof) Decode(r io.Reader, includeCommits bool) error {
 		return err
 	}
 	p.IPProof = &innerproduct.Proof{}
-	return p.IPProof.Decode(r)
+	err = p.IPProof.Decode(r)
+	if err != nil {
+		return err
+	}
+
+	if includeCommits {
+		return p.checkLengths()
+	}
+
+	// Without the commitments we can only check that the number of
+	// inner product rounds is one that a valid proof could have
+	rounds := len(p.IPProof.L)
+	if rounds < bits.TrailingZeros(N) || rounds > bits.TrailingZeros(N*maxM) {
+		return cryptoerrors.Newf(cryptoerrors.ErrInvalidLength, "unexpected number of inner product rounds %d", rounds)
+	}
+	return nil
+}
+
+// checkPoints rejects proofs where A, S, T1, T2 or any of the inner product
+// L and R points is the identity. Such points let a malicious prover cancel
+// terms in the verification equation.
+// Points are held in decoded form, and ristretto only decodes canonical
+// encodings, so this also guarantees every point has a canonical encoding
+func (p *Proof) checkPoints() error {
+	var identity ristretto.Point
+	identity.SetZero()
+
+	named := []struct {
+		name  string
+		point *ristretto.Point
+	}{
+		{"A", &p.A},
+		{"S", &p.S},
+		{"T1", &p.T1},
+		{"T2", &p.T2},
+	}
+	for _, n := range named {
+		if n.point.Equals(&identity) {
+			return cryptoerrors.Newf(cryptoerrors.ErrMalformedPoint, "proof point %s is the identity", n.name)
+		}
+	}
+
+	for i := range p.IPProof.L {
+		if p.IPProof.L[i].Equals(&identity) {
+			return cryptoerrors.Newf(cryptoerrors.ErrMalformedPoint, "inner product point L[%d] is the identity", i)
+		}
+		if p.IPProof.R[i].Equals(&identity) {
+			return cryptoerrors.Newf(cryptoerrors.ErrMalformedPoint, "inner product point R[%d] is the identity", i)
+		}
+	}
+
+	return nil
+}
+
+// checkLengths makes sure that the number of commitments and the number of
+// inner product rounds agree, before any of them is used for indexing
+func (p *Proof) checkLengths() error {
+	m := len(p.V)
+	if m < 1 || m > maxM || m&(m-1) != 0 {
+		return cryptoerrors.Newf(cryptoerrors.ErrInvalidLength, "number of commitments %d is not a power of two between 1 and %d", m, maxM)
+	}
+
+	if p.IPProof == nil {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "inner product proof is missing")
+	}
+
+	if len(p.IPProof.L) != len(p.IPProof.R) {
+		return cryptoerrors.New(cryptoerrors.ErrInvalidLength, "inner product proof has a different number of L and R points")
+	}
+
+	if len(p.IPProof.L) != bits.TrailingZeros(uint(N*m)) {
+		return cryptoerrors.Newf(cryptoerrors.ErrInvalidLength, "expected %d inner product rounds for %d commitments, got %d", bits.TrailingZeros(uint(N*m)), m, len(p.IPProof.L))
+	}
+
+	return nil
 }
 
 // Equals returns proof equality with commitments
 func (p *Proof) Equals(other Proof, includeCommits bool) bool {
-	if len(p.V) != len(other.V) && includeCommits {
-		return false
-	}
+	if includeCommits {
+		if len(p.V) != len(other.V) {
+			return false
+		}
 
-	for i := range p.V {
-		ok := p.V[i].EqualValue(other.V[i])
-		if !ok {
-			return ok
+		for i := range p.V {
+			ok := p.V[i].EqualValue(other.V[i])
+			if !ok {
+				return ok
+			}
 		}
 	}
 
@@ -621,8 +837,36 @@ func (p *Proof) Equals(other Proof, includeCommits bool) bool {
 	if !ok {
 		return ok
 	}
-	return true
-	// return p.IPProof.Equals(*other.IPProof)
+
+	if p.IPProof == nil || other.IPProof == nil {
+		return p.IPProof == other.IPProof
+	}
+	return p.IPProof.Equals(*other.IPProof)
+}
+
+// proofHashDomain is the domain of the digests of proofs
+const proofHashDomain = "dusk.rangeproof.proof"
+
+// Hash returns the domain separated Blake2b-256 digest of the canonical
+// encoding of the proof, commitments included. The encoding is streamed into
+// the hash function, so the proof is never serialized to an intermediate
+// buffer.
+// It matches the digest computed by the Reassembler on the chunks of the proof
+func (p *Proof) Hash() ([32]byte, error) {
+	var digest [32]byte
+
+	if p.IPProof == nil {
+		return digest, errors.New("[Hash] - proof is incomplete")
+	}
+
+	h := hash.NewBlake2b256WithDomain(proofHashDomain)
+
+	if err := p.Encode(h, true); err != nil {
+		return digest, err
+	}
+
+	copy(digest[:], h.Sum(nil))
+	return digest, nil
 }
 
 func readerToPoint(r io.Reader, p *ristretto.Point) error {
@@ -633,7 +877,7 @@ func readerToPoint(r io.Reader, p *ristretto.Point) error {
 	}
 	ok := p.SetBytes(&x)
 	if !ok {
-		return errors.New("point not encodable")
+		return cryptoerrors.New(cryptoerrors.ErrMalformedPoint, "point not encodable")
 	}
 	return nil
 }
@@ -644,5 +888,8 @@ func readerToScalar(r io.Reader, s *ristretto.Scalar) error {
 		return err
 	}
 	s.SetBytes(&x)
+	if !bytes.Equal(s.Bytes(), x[:]) {
+		return cryptoerrors.New(cryptoerrors.ErrNotCanonical, "scalar is not canonically encoded")
+	}
 	return nil
 }
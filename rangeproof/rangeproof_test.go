@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"math/big"
 	"math/rand"
+	"sync"
 	"testing"
 
 	ristretto "github.com/bwesterb/go-ristretto"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,6 +25,107 @@ func TestProveBulletProof(t *testing.T) {
 
 }
 
+func TestProveWithExtraData(t *testing.T) {
+	var amount ristretto.Scalar
+	amount.SetBigInt(big.NewInt(rand.Int63()))
+
+	txHash := []byte("transaction hash")
+	p, err := ProveWithExtraData([]ristretto.Scalar{amount}, txHash, false)
+	require.Nil(t, err)
+
+	ok, err := VerifyWithExtraData(p, txHash)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// A proof detached from its data must not verify
+	ok, _ = VerifyWithExtraData(p, []byte("another transaction"))
+	assert.False(t, ok)
+
+	ok, err = Verify(p)
+	assert.False(t, ok)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrVerificationFailed))
+}
+
+func TestTrace(t *testing.T) {
+	var amount ristretto.Scalar
+	amount.SetBigInt(big.NewInt(rand.Int63()))
+
+	proverTrace, verifierTrace := &bytes.Buffer{}, &bytes.Buffer{}
+	p, err := ProveWithTrace([]ristretto.Scalar{amount}, []byte("tx"), proverTrace)
+	require.Nil(t, err)
+
+	ok, err := VerifyWithTrace(p, []byte("tx"), verifierTrace)
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	// the prover and the verifier go through the same transcript, inner
+	// product rounds included
+	assert.Equal(t, proverTrace.String(), verifierTrace.String())
+	assert.Contains(t, proverTrace.String(), "append    extra_data len=2 7478\n")
+	assert.Contains(t, proverTrace.String(), "challenge u ")
+
+	// with other data, the traces diverge from the first entry
+	verifierTrace.Reset()
+	ok, _ = VerifyWithTrace(p, []byte("other"), verifierTrace)
+	assert.False(t, ok)
+	assert.NotEqual(t, proverTrace.String(), verifierTrace.String())
+}
+
+func TestProveConcurrently(t *testing.T) {
+	// proofs of different sizes do not share any state
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i, amounts := range [][]uint64{{1}, {2, 3}, {4, 5, 6, 7}} {
+		wg.Add(1)
+		go func(i int, amounts []uint64) {
+			defer wg.Done()
+			p, err := ProveUint64(amounts, nil)
+			if err == nil {
+				_, err = Verify(p)
+			}
+			errs[i] = err
+		}(i, amounts)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.Nil(t, err)
+	}
+}
+
+func TestProveUint64(t *testing.T) {
+	amounts := []uint64{0, 1, 1 << 40, ^uint64(0)}
+	blinds := make([]ristretto.Scalar, len(amounts))
+	for i := range blinds {
+		blinds[i].Rand()
+	}
+
+	p, err := ProveUint64(amounts, blinds)
+	require.Nil(t, err)
+
+	ok, err := Verify(p)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// The commitments must open to the amounts with the supplied blinds
+	ped := pedersen.New([]byte("dusk.BulletProof.vec1"))
+	for i := range amounts {
+		var v ristretto.Scalar
+		v.SetBigInt(new(big.Int).SetUint64(amounts[i]))
+		expected := ped.CommitToScalarWithBlind(v, blinds[i])
+		assert.True(t, expected.Equals(p.V[i]))
+	}
+
+	_, err = ProveUint64(amounts, blinds[:1])
+	assert.NotNil(t, err)
+
+	p, err = ProveUint64([]uint64{42}, nil)
+	require.Nil(t, err)
+	ok, err = Verify(p)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
 func TestEncodeDecode(t *testing.T) {
 	p := generateProof(4, t)
 	includeCommits := false
@@ -38,6 +142,87 @@ func TestEncodeDecode(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func TestDecodeMalformed(t *testing.T) {
+	p := generateProof(2, t)
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, p.Encode(buf, true))
+	encoded := buf.Bytes()
+
+	var decodedProof Proof
+	require.Nil(t, decodedProof.Decode(bytes.NewReader(encoded), true))
+	ok, err := Verify(decodedProof)
+	require.Nil(t, err)
+	assert.True(t, ok)
+
+	// truncated input
+	for _, n := range []int{0, 3, 40, len(encoded) - 1} {
+		assert.NotNil(t, decodedProof.Decode(bytes.NewReader(encoded[:n]), true))
+	}
+
+	// inconsistent number of commitments and inner product rounds
+	wrongLen := append([]byte{}, encoded...)
+	wrongLen[3] = 4
+	assert.NotNil(t, decodedProof.Decode(bytes.NewReader(wrongLen), true))
+
+	// huge announced number of commitments must not allocate or panic
+	huge := append([]byte{}, encoded...)
+	huge[0], huge[1], huge[2], huge[3] = 0xff, 0xff, 0xff, 0xff
+	assert.NotNil(t, decodedProof.Decode(bytes.NewReader(huge), true))
+
+	// non-canonical scalar: taux is the first scalar after V, A, S, T1, T2
+	nonCanonical := append([]byte{}, encoded...)
+	tauxOffset := 4 + 2*32 + 4*32
+	for i := 0; i < 32; i++ {
+		nonCanonical[tauxOffset+i] = 0xff
+	}
+	assert.NotNil(t, decodedProof.Decode(bytes.NewReader(nonCanonical), true))
+
+	// invalid point encoding for A
+	offCurve := append([]byte{}, encoded...)
+	for i := 0; i < 32; i++ {
+		offCurve[4+2*32+i] = 0xff
+	}
+	assert.NotNil(t, decodedProof.Decode(bytes.NewReader(offCurve), true))
+
+	// random garbage never panics
+	for i := 0; i < 100; i++ {
+		garbage := make([]byte, rand.Intn(len(encoded)+64))
+		rand.Read(garbage)
+		_ = decodedProof.Decode(bytes.NewReader(garbage), true)
+	}
+}
+
+func TestVerifyRejectsIdentityPoints(t *testing.T) {
+	p := generateProof(1, t)
+
+	corrupt := []func(p *Proof){
+		func(p *Proof) { p.A.SetZero() },
+		func(p *Proof) { p.S.SetZero() },
+		func(p *Proof) { p.T1.SetZero() },
+		func(p *Proof) { p.T2.SetZero() },
+		func(p *Proof) { p.IPProof.L[0].SetZero() },
+		func(p *Proof) { p.IPProof.R[len(p.IPProof.R)-1].SetZero() },
+	}
+
+	for _, c := range corrupt {
+		cpy := *p
+		ip := *p.IPProof
+		ip.L = append([]ristretto.Point{}, p.IPProof.L...)
+		ip.R = append([]ristretto.Point{}, p.IPProof.R...)
+		cpy.IPProof = &ip
+
+		c(&cpy)
+		ok, err := Verify(cpy)
+		assert.False(t, ok)
+		assert.NotNil(t, err)
+	}
+
+	ok, err := Verify(*p)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
 func TestComputeMu(t *testing.T) {
 	var one ristretto.Scalar
 	one.SetOne()
@@ -53,6 +238,10 @@ func TestComputeMu(t *testing.T) {
 }
 
 func generateProof(m int, t *testing.T) *Proof {
+	return generateProofWithExtraData(m, nil, t)
+}
+
+func generateProofWithExtraData(m int, extraData []byte, t *testing.T) *Proof {
 
 	// XXX: m must be a multiple of two due to inner product proof
 	amounts := []ristretto.Scalar{}
@@ -67,7 +256,7 @@ func generateProof(m int, t *testing.T) *Proof {
 	}
 
 	// Prove
-	p, err := Prove(amounts, true)
+	p, err := ProveWithExtraData(amounts, extraData, true)
 	require.Nil(t, err)
 	return &p
 }
@@ -99,3 +288,32 @@ func BenchmarkVerify(b *testing.B) {
 	}
 
 }
+
+func TestProofHash(t *testing.T) {
+	p := generateProof(2, t)
+
+	h1, err := p.Hash()
+	require.Nil(t, err)
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, p.Encode(buf, true))
+	var decoded Proof
+	require.Nil(t, decoded.Decode(buf, true))
+
+	h2, err := decoded.Hash()
+	require.Nil(t, err)
+	assert.Equal(t, h1, h2)
+	assert.True(t, decoded.Equals(*p, true))
+
+	// a different inner product proof changes both the hash and equality
+	decoded.IPProof.A.Add(&decoded.IPProof.A, &decoded.IPProof.B)
+	h3, err := decoded.Hash()
+	require.Nil(t, err)
+	assert.NotEqual(t, h1, h3)
+	assert.False(t, decoded.Equals(*p, true))
+
+	other := generateProof(2, t)
+	h4, err := other.Hash()
+	require.Nil(t, err)
+	assert.NotEqual(t, h1, h4)
+}
@@ -0,0 +1,31 @@
+package rangeproof
+
+import (
+	"unsafe"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/securemem"
+)
+
+// secretScalars returns n zero scalars for the secrets of the prover, the
+// bits of the amounts and their blinding vectors, backed by locked memory.
+// The returned function wipes and releases them. If the memory cannot be
+// allocated, the scalars are allocated on the heap and wiped all the same
+func secretScalars(n int) ([]ristretto.Scalar, func()) {
+	mem, err := securemem.New(n * int(unsafe.Sizeof(ristretto.Scalar{})))
+	if err != nil {
+		scalars := make([]ristretto.Scalar, n)
+		return scalars, func() {
+			for i := range scalars {
+				scalars[i].SetZero()
+			}
+		}
+	}
+
+	b := mem.Bytes()
+	scalars := (*[1 << 20]ristretto.Scalar)(unsafe.Pointer(&b[0]))[:n:n]
+	return scalars, func() {
+		// The canary only reports bugs, there is nothing to recover here
+		_ = mem.Destroy()
+	}
+}
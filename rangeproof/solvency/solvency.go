@@ -0,0 +1,312 @@
+// Package solvency builds a proof of liabilities on top of aggregated range proofs.
+// An exchange commits to the balance of every account, proves that every
+// balance lies in [0, 2^N) and that the balances sum up to the value hidden in
+// a public liability commitment, without revealing any of the balances
+package solvency
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rangeproof"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// accountsPerProof is the number of balances aggregated in a single range proof
+const accountsPerProof = 16
+
+// maxProofSize bounds the size of a single encoded range proof, so that
+// decoding a report never allocates more than a valid one would need
+const maxProofSize = 1 << 16
+
+// Report is the auditable artifact published by the exchange
+type Report struct {
+	// NumAccounts is the number of accounts covered by the report. Range
+	// proofs are padded, so the last proof can hold extra zero commitments
+	NumAccounts uint32
+	// Proofs are the aggregated range proofs, including the commitments
+	Proofs []rangeproof.Proof
+	// Liability is the public commitment to the total of all balances
+	Liability ristretto.Point
+	// R and S form the proof that the commitments sum to Liability
+	R ristretto.Point
+	S ristretto.Scalar
+}
+
+// Prove creates a Report for the balances. liabilityBlind is the blinding
+// factor of the liability commitment the exchange publishes.
+// It returns the blinding factor of every account commitment, which the
+// exchange hands out to its users so that they can check their own balance
+func Prove(balances []uint64, liabilityBlind ristretto.Scalar) (*Report, []ristretto.Scalar, error) {
+	if len(balances) == 0 {
+		return nil, nil, errors.New("[Prove] - no balances")
+	}
+
+	ped := pedersen.New(nil)
+
+	var total ristretto.Scalar
+	total.SetZero()
+	var sumBlinds ristretto.Scalar
+	sumBlinds.SetZero()
+
+	r := &Report{NumAccounts: uint32(len(balances))}
+	blinds := make([]ristretto.Scalar, 0, len(balances))
+
+	for i := 0; i < len(balances); i += accountsPerProof {
+		end := i + accountsPerProof
+		if end > len(balances) {
+			end = len(balances)
+		}
+
+		p, err := rangeproof.ProveUint64(balances[i:end], nil)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "[Prove] - range proof")
+		}
+
+		for j := range p.V {
+			// padding commitments hide a zero, but still take part in the sum
+			sumBlinds.Add(&sumBlinds, &p.V[j].BlindingFactor)
+			if i+j < end {
+				blinds = append(blinds, p.V[j].BlindingFactor)
+			}
+		}
+		r.Proofs = append(r.Proofs, p)
+	}
+
+	for i := range balances {
+		var b ristretto.Scalar
+		b.SetBigInt(new(big.Int).SetUint64(balances[i]))
+		total.Add(&total, &b)
+	}
+
+	liability := ped.CommitToScalarWithBlind(total, liabilityBlind)
+	r.Liability = liability.Value
+
+	// the sum of the commitments minus the liability is x * BlindPoint,
+	// prove knowledge of x
+	var x ristretto.Scalar
+	x.Sub(&sumBlinds, &liabilityBlind)
+
+	var k ristretto.Scalar
+	rng.Scalar(&k)
+	r.R.ScalarMult(&ped.BlindPoint, &k)
+
+	c := r.challenge()
+	r.S.MulAdd(&c, &x, &k)
+
+	return r, blinds, nil
+}
+
+// Verify checks every range proof and the balance between the account
+// commitments and the liability commitment
+func (r *Report) Verify() error {
+	if err := r.checkNumAccounts(); err != nil {
+		return err
+	}
+
+	for i := range r.Proofs {
+		ok, err := rangeproof.Verify(r.Proofs[i])
+		if err != nil {
+			return errors.Wrapf(err, "[Verify] - range proof %d", i)
+		}
+		if !ok {
+			return errors.Errorf("[Verify] - range proof %d is invalid", i)
+		}
+	}
+
+	// s * BlindPoint == R + c * (sum(V) - Liability)
+	ped := pedersen.New(nil)
+	D := r.commitmentSum()
+	D.Sub(&D, &r.Liability)
+
+	c := r.challenge()
+
+	var lhs, rhs ristretto.Point
+	lhs.ScalarMult(&ped.BlindPoint, &r.S)
+	rhs.ScalarMult(&D, &c)
+	rhs.Add(&rhs, &r.R)
+
+	if !lhs.Equals(&rhs) {
+		return errors.New("[Verify] - commitments do not sum to the liability")
+	}
+
+	return nil
+}
+
+// Commitment returns the balance commitment of the i-th account
+func (r *Report) Commitment(i int) (ristretto.Point, error) {
+	if i < 0 || i >= int(r.NumAccounts) || i/accountsPerProof >= len(r.Proofs) {
+		return ristretto.Point{}, errors.Errorf("no account with index %d", i)
+	}
+
+	p := r.Proofs[i/accountsPerProof]
+	if i%accountsPerProof >= len(p.V) {
+		return ristretto.Point{}, errors.Errorf("no account with index %d", i)
+	}
+	return p.V[i%accountsPerProof].Value, nil
+}
+
+// VerifyInclusion lets a user check that the report includes their balance,
+// given the blinding factor received from the exchange
+func (r *Report) VerifyInclusion(i int, balance uint64, blind ristretto.Scalar) error {
+	C, err := r.Commitment(i)
+	if err != nil {
+		return err
+	}
+
+	var v ristretto.Scalar
+	v.SetBigInt(new(big.Int).SetUint64(balance))
+	expected := pedersen.New(nil).CommitToScalarWithBlind(v, blind)
+
+	if !expected.Value.Equals(&C) {
+		return errors.Errorf("commitment of account %d does not open to %d", i, balance)
+	}
+	return nil
+}
+
+// checkNumAccounts makes sure the proofs hold exactly enough commitments for
+// NumAccounts, so no account can be hidden in additional proofs
+func (r *Report) checkNumAccounts() error {
+	expectedProofs := (int(r.NumAccounts) + accountsPerProof - 1) / accountsPerProof
+	if r.NumAccounts == 0 || len(r.Proofs) != expectedProofs {
+		return errors.Errorf("expected %d range proofs for %d accounts, got %d", expectedProofs, r.NumAccounts, len(r.Proofs))
+	}
+
+	for i := 0; i < len(r.Proofs)-1; i++ {
+		if len(r.Proofs[i].V) != accountsPerProof {
+			return errors.Errorf("range proof %d holds %d commitments instead of %d", i, len(r.Proofs[i].V), accountsPerProof)
+		}
+	}
+	return nil
+}
+
+func (r *Report) commitmentSum() ristretto.Point {
+	var sum ristretto.Point
+	sum.SetZero()
+	for i := range r.Proofs {
+		for j := range r.Proofs[i].V {
+			sum.Add(&sum, &r.Proofs[i].V[j].Value)
+		}
+	}
+	return sum
+}
+
+// challenge binds the balance proof to every commitment of the report
+func (r *Report) challenge() ristretto.Scalar {
+	t := transcript.New("dusk.solvency")
+	t.AppendUint64("accounts", uint64(r.NumAccounts))
+	for i := range r.Proofs {
+		for j := range r.Proofs[i].V {
+			t.AppendPoint("V", r.Proofs[i].V[j].Value)
+		}
+	}
+	t.AppendPoint("liability", r.Liability)
+	t.AppendPoint("R", r.R)
+	return t.ChallengeScalar("c")
+}
+
+// Encode a Report
+func (r *Report) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, r.NumAccounts); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(r.Proofs))); err != nil {
+		return err
+	}
+
+	// range proofs are length-prefixed, as their decoder consumes the whole reader
+	for i := range r.Proofs {
+		buf := &bytes.Buffer{}
+		if err := r.Proofs[i].Encode(buf, true); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, uint32(buf.Len())); err != nil {
+			return err
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if err := binary.Write(w, binary.BigEndian, r.Liability.Bytes()); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, r.R.Bytes()); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, r.S.Bytes())
+}
+
+// Decode a Report
+func (r *Report) Decode(rd io.Reader) error {
+	if r == nil {
+		return errors.New("struct is nil")
+	}
+
+	if err := binary.Read(rd, binary.BigEndian, &r.NumAccounts); err != nil {
+		return err
+	}
+
+	var numProofs uint32
+	if err := binary.Read(rd, binary.BigEndian, &numProofs); err != nil {
+		return err
+	}
+
+	r.Proofs = nil
+	for i := uint32(0); i < numProofs; i++ {
+		var size uint32
+		if err := binary.Read(rd, binary.BigEndian, &size); err != nil {
+			return err
+		}
+		if size > maxProofSize {
+			return errors.Errorf("range proof of %d bytes is too large", size)
+		}
+
+		proofBytes := make([]byte, size)
+		if _, err := io.ReadFull(rd, proofBytes); err != nil {
+			return err
+		}
+
+		var p rangeproof.Proof
+		if err := p.Decode(bytes.NewReader(proofBytes), true); err != nil {
+			return err
+		}
+		r.Proofs = append(r.Proofs, p)
+	}
+
+	if err := readPoint(rd, &r.Liability); err != nil {
+		return err
+	}
+	if err := readPoint(rd, &r.R); err != nil {
+		return err
+	}
+
+	var x [32]byte
+	if err := binary.Read(rd, binary.BigEndian, &x); err != nil {
+		return err
+	}
+	r.S.SetBytes(&x)
+	if !bytes.Equal(r.S.Bytes(), x[:]) {
+		return errors.New("scalar is not canonically encoded")
+	}
+	return nil
+}
+
+func readPoint(r io.Reader, p *ristretto.Point) error {
+	var x [32]byte
+	if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+		return err
+	}
+	if !p.SetBytes(&x) {
+		return errors.New("point not encodable")
+	}
+	return nil
+}
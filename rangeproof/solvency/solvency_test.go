@@ -0,0 +1,49 @@
+package solvency
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolvency(t *testing.T) {
+	balances := make([]uint64, 21)
+	for i := range balances {
+		balances[i] = uint64(rand.Int63n(1 << 40))
+	}
+
+	var liabilityBlind ristretto.Scalar
+	liabilityBlind.Rand()
+
+	report, blinds, err := Prove(balances, liabilityBlind)
+	require.Nil(t, err)
+	require.Equal(t, len(balances), len(blinds))
+	assert.Nil(t, report.Verify())
+
+	for i := range balances {
+		assert.Nil(t, report.VerifyInclusion(i, balances[i], blinds[i]))
+	}
+	assert.NotNil(t, report.VerifyInclusion(0, balances[0]+1, blinds[0]))
+	assert.NotNil(t, report.VerifyInclusion(len(balances), 0, blinds[0]))
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, report.Encode(buf))
+
+	var decoded Report
+	require.Nil(t, decoded.Decode(buf))
+	assert.Nil(t, decoded.Verify())
+
+	// a liability commitment to a smaller total does not verify
+	var other ristretto.Point
+	other.Rand()
+	decoded.Liability = other
+	assert.NotNil(t, decoded.Verify())
+
+	// dropping a proof hides accounts
+	report.Proofs = report.Proofs[1:]
+	assert.NotNil(t, report.Verify())
+}
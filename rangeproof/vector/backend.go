@@ -0,0 +1,59 @@
+package vector
+
+import (
+	"sync/atomic"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+)
+
+// MultiScalarMultiplier computes multi-scalar multiplications.
+// Implementations can be plugged with SetBackend to accelerate the
+// verification of proofs, for instance with assembly, SIMD or GPU code
+type MultiScalarMultiplier interface {
+	// MultiScalarMult returns the sum of scalars[i] * points[i]. Both slices
+	// have the same length
+	MultiScalarMult(scalars []ristretto.Scalar, points []ristretto.Point) ristretto.Point
+}
+
+// PureGo is the default MultiScalarMultiplier, computing every product
+// separately with go-ristretto
+type PureGo struct{}
+
+// MultiScalarMult implements MultiScalarMultiplier
+func (PureGo) MultiScalarMult(scalars []ristretto.Scalar, points []ristretto.Point) ristretto.Point {
+	var result, prod ristretto.Point
+	result.SetZero()
+
+	for i := range points {
+		prod.ScalarMult(&points[i], &scalars[i])
+		result.Add(&result, &prod)
+	}
+
+	return result
+}
+
+// backendHolder wraps the backend so atomic.Value always stores the same
+// concrete type
+type backendHolder struct {
+	m MultiScalarMultiplier
+}
+
+var backend atomic.Value
+
+func init() {
+	backend.Store(backendHolder{PureGo{}})
+}
+
+// SetBackend replaces the MultiScalarMultiplier used by Exp. Passing nil
+// restores the pure Go implementation
+func SetBackend(m MultiScalarMultiplier) {
+	if m == nil {
+		m = PureGo{}
+	}
+	backend.Store(backendHolder{m})
+}
+
+// Backend returns the MultiScalarMultiplier currently used by Exp
+func Backend() MultiScalarMultiplier {
+	return backend.Load().(backendHolder).m
+}
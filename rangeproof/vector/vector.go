@@ -67,12 +67,9 @@ func Neg(a []ristretto.Scalar) []ristretto.Scalar {
 }
 
 // SubScalar Subtracts a scalars value b, from every element in the slice a
+// It does not branch on the value of b, which can be secret on the prover side
 func SubScalar(a []ristretto.Scalar, b ristretto.Scalar) []ristretto.Scalar {
 
-	if b.IsNonZeroI() == 0 {
-		return a
-	}
-
 	res := make([]ristretto.Scalar, len(a))
 
 	for i := 0; i < len(a); i++ {
@@ -112,7 +109,8 @@ func InnerProduct(a, b []ristretto.Scalar) (ristretto.Scalar, error) {
 	return res, nil
 }
 
-// Exp exponentiates and sums a vector a to b, creating a commitment
+// Exp exponentiates and sums a vector a to b, creating a commitment.
+// The multi-scalar multiplication is delegated to the configured Backend
 func Exp(a []ristretto.Scalar, b []ristretto.Point, N, M int) (ristretto.Point, error) {
 	result := ristretto.Point{} // defaults to zero
 	result.SetZero()
@@ -125,19 +123,7 @@ func Exp(a []ristretto.Scalar, b []ristretto.Point, N, M int) (ristretto.Point,
 		return result, errors.New("length of scalar a is not less than N*M")
 	}
 
-	for i := range b {
-
-		scalar := a[i]
-		point := b[i]
-
-		var sum ristretto.Point
-		sum.ScalarMult(&point, &scalar)
-
-		result.Add(&result, &sum)
-
-	}
-
-	return result, nil
+	return Backend().MultiScalarMult(a, b), nil
 }
 
 // ScalarPowers constructs a vector of powers
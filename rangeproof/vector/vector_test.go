@@ -123,3 +123,38 @@ func TestInnerProduct(t *testing.T) {
 
 	assert.Equal(t, true, ok)
 }
+
+type countingBackend struct {
+	calls int
+}
+
+func (c *countingBackend) MultiScalarMult(scalars []ristretto.Scalar, points []ristretto.Point) ristretto.Point {
+	c.calls++
+	return PureGo{}.MultiScalarMult(scalars, points)
+}
+
+func TestSetBackend(t *testing.T) {
+	n := 8
+	a := make([]ristretto.Scalar, n)
+	b := make([]ristretto.Point, n)
+	for i := range a {
+		a[i].Rand()
+		b[i].Rand()
+	}
+
+	want, err := Exp(a, b, n, 1)
+	assert.Nil(t, err)
+
+	c := &countingBackend{}
+	SetBackend(c)
+	defer SetBackend(nil)
+
+	have, err := Exp(a, b, n, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, c.calls)
+	assert.True(t, want.Equals(&have))
+
+	SetBackend(nil)
+	_, ok := Backend().(PureGo)
+	assert.True(t, ok)
+}
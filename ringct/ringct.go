@@ -0,0 +1,593 @@
+// Package ringct composes the primitives of a ring confidential transaction:
+// every input is spent with a CLSAG signature over a ring of ledger
+// outputs, amounts are hidden in Pedersen commitments, a Bulletproof shows
+// that every output amount lies in [0, 2^64), and a balance proof shows
+// that the inputs pay for the outputs and the fee.
+//
+// Every input commits again to its amount in a pseudo output, with a fresh
+// blinding factor. The CLSAG signature of the input proves that the pseudo
+// output hides the amount of one of the ring members, without telling
+// which, and the pseudo outputs minus the outputs and the fee commit to
+// zero.
+//
+// Verify does not check that the key images are unspent, nor that the ring
+// members exist on the ledger; the caller does, see mlsag.KeyImageSet
+package ringct
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/mlsag"
+	"github.com/dusk-network/dusk-crypto/rangeproof"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
+)
+
+// MaxInputs bounds the number of inputs of a transaction
+const MaxInputs = 16
+
+// MaxOutputs is the number of outputs a single range proof covers
+const MaxOutputs = 16
+
+// MaxRingSize bounds the number of members of a ring
+const MaxRingSize = 128
+
+// maxExtraSize bounds the size of the extra data when decoding
+const maxExtraSize = 1 << 16
+
+// Member is a ledger output taking part in a ring: its one-time public key
+// and the commitment to its amount
+type Member struct {
+	Key        ristretto.Point
+	Commitment ristretto.Point
+}
+
+// Spend holds what the owner of an output needs to spend it: the ring it
+// hides in, the position of the output in the ring, its secret key and the
+// opening of its commitment
+type Spend struct {
+	Ring    []Member
+	Index   int
+	PrivKey ristretto.Scalar
+	Amount  uint64
+	Blind   ristretto.Scalar
+}
+
+// Payment is an output to create: the one-time key of the recipient and
+// the amount
+type Payment struct {
+	Key    ristretto.Point
+	Amount uint64
+}
+
+// Input is a spent output as it appears in a transaction. The ring is in
+// the order the signature uses, the real member being anywhere
+type Input struct {
+	Ring      []Member
+	PseudoOut ristretto.Point
+	KeyImage  ristretto.Point
+	Signature *mlsag.CLSAGSignature
+}
+
+// Output is a created output
+type Output struct {
+	Key        ristretto.Point
+	Commitment ristretto.Point
+}
+
+// Transaction is a ring confidential transaction
+type Transaction struct {
+	Inputs  []Input
+	Outputs []Output
+	Fee     uint64
+	// Extra is bound to the signatures, e.g. the transaction prefix of
+	// the caller
+	Extra      []byte
+	RangeProof rangeproof.Proof
+	Balance    pedersen.ZeroProof
+}
+
+// newPedersen returns the commitment scheme of the range proofs
+func newPedersen() *pedersen.Pedersen {
+	return pedersen.New([]byte("dusk.BulletProof.vec1"))
+}
+
+func scalarFromUint64(n uint64) ristretto.Scalar {
+	var s ristretto.Scalar
+	s.SetBigInt(new(big.Int).SetUint64(n))
+	return s
+}
+
+// Prove builds a transaction spending the inputs to the payments and the
+// fee. It returns the transaction and the openings of the output
+// commitments, which the sender hands to the recipients
+func Prove(spends []Spend, payments []Payment, fee uint64, extra []byte) (*Transaction, []pedersen.Opening, error) {
+	if len(spends) == 0 || len(spends) > MaxInputs {
+		return nil, nil, errors.Errorf("[Prove] - number of inputs must be between 1 and %d", MaxInputs)
+	}
+	if len(payments) == 0 || len(payments) > MaxOutputs {
+		return nil, nil, errors.Errorf("[Prove] - number of outputs must be between 1 and %d", MaxOutputs)
+	}
+	if err := checkBalance(spends, payments, fee); err != nil {
+		return nil, nil, err
+	}
+
+	ped := newPedersen()
+	for i := range spends {
+		if err := spends[i].check(ped); err != nil {
+			return nil, nil, errors.Wrapf(err, "[Prove] - input %d", i)
+		}
+	}
+
+	tx := &Transaction{
+		Inputs:  make([]Input, len(spends)),
+		Outputs: make([]Output, len(payments)),
+		Fee:     fee,
+		Extra:   extra,
+	}
+
+	// outputs and their range proof
+	outs := make([]pedersen.Opening, len(payments))
+	amounts := make([]uint64, len(payments))
+	blinds := make([]ristretto.Scalar, len(payments))
+	for i, p := range payments {
+		outs[i] = ped.CommitAmount(p.Amount)
+		amounts[i] = p.Amount
+		blinds[i] = outs[i].BlindingFactor
+		tx.Outputs[i] = Output{Key: p.Key, Commitment: outs[i].Value}
+	}
+	rp, err := rangeproof.ProveUint64(amounts, blinds)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "[Prove] - range proof")
+	}
+	tx.RangeProof = rp
+
+	// pseudo outputs, and the proof that they balance the outputs
+	pseudo := make([]pedersen.Opening, len(spends))
+	for i := range spends {
+		pseudo[i] = ped.CommitAmount(spends[i].Amount)
+		tx.Inputs[i].PseudoOut = pseudo[i].Value
+	}
+	balance := pedersen.SumOpenings(pseudo).Sub(ped.AddAmount(pedersen.SumOpenings(outs), fee))
+	if tx.Balance, err = balance.ProveZero(); err != nil {
+		return nil, nil, errors.Wrap(err, "[Prove] - balance")
+	}
+
+	msg, err := tx.message()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range spends {
+		if err := spends[i].sign(&tx.Inputs[i], pseudo[i].BlindingFactor, msg); err != nil {
+			return nil, nil, errors.Wrapf(err, "[Prove] - input %d", i)
+		}
+	}
+
+	return tx, outs, nil
+}
+
+// checkBalance checks that the amounts balance, without overflowing
+func checkBalance(spends []Spend, payments []Payment, fee uint64) error {
+	var in, out uint64
+	for _, s := range spends {
+		if in > math.MaxUint64-s.Amount {
+			return errors.New("[Prove] - input amounts overflow")
+		}
+		in += s.Amount
+	}
+	out = fee
+	for _, p := range payments {
+		if out > math.MaxUint64-p.Amount {
+			return errors.New("[Prove] - output amounts overflow")
+		}
+		out += p.Amount
+	}
+	if in != out {
+		return errors.Errorf("[Prove] - inputs (%d) do not match outputs and fee (%d)", in, out)
+	}
+	return nil
+}
+
+// check that the spend opens the member it claims to own
+func (s *Spend) check(ped *pedersen.Pedersen) error {
+	if len(s.Ring) == 0 || len(s.Ring) > MaxRingSize {
+		return errors.Errorf("ring size must be between 1 and %d", MaxRingSize)
+	}
+	if s.Index < 0 || s.Index >= len(s.Ring) {
+		return errors.Errorf("no ring member with index %d", s.Index)
+	}
+	if err := distinctKeys(s.Ring); err != nil {
+		return err
+	}
+
+	member := s.Ring[s.Index]
+	var pk ristretto.Point
+	pk.ScalarMultBase(&s.PrivKey)
+	if !pk.Equals(&member.Key) {
+		return errors.New("secret key does not match the ring member")
+	}
+	c := ped.CommitToScalarWithBlind(scalarFromUint64(s.Amount), s.Blind)
+	if !c.Value.Equals(&member.Commitment) {
+		return errors.New("amount and blinding factor do not open the ring member")
+	}
+	return nil
+}
+
+// sign the input. The commitment key of the signer is the difference of
+// the blinding factors of its commitment and of the pseudo output
+func (s *Spend) sign(in *Input, pseudoBlind ristretto.Scalar, msg []byte) error {
+	var p mlsag.Proof
+	for j, m := range s.Ring {
+		if j == s.Index {
+			continue
+		}
+		p.AddDecoy(ringKeys(m, in.PseudoOut))
+	}
+
+	var z ristretto.Scalar
+	z.Sub(&s.Blind, &pseudoBlind)
+	p.AddSecret(s.PrivKey)
+	p.AddSecret(z)
+	p.SetMsg(msg)
+
+	sig, keyImage, err := p.ProveCLSAG()
+	if err != nil {
+		return err
+	}
+
+	// the signature shuffled the ring, follow its order
+	in.Ring = make([]Member, len(sig.PubKeys))
+	for j := range sig.PubKeys {
+		key := sig.PubKeys[j].OutputKey()
+		for _, m := range s.Ring {
+			if m.Key.Equals(&key) {
+				in.Ring[j] = m
+				break
+			}
+		}
+	}
+	in.KeyImage = keyImage
+	in.Signature = sig
+	return nil
+}
+
+// ringKeys returns the keys a member takes part in the signature with: its
+// output key, and its commitment minus the pseudo output
+func ringKeys(m Member, pseudoOut ristretto.Point) mlsag.PubKeys {
+	var keys mlsag.PubKeys
+	var diff ristretto.Point
+	diff.Sub(&m.Commitment, &pseudoOut)
+	keys.AddPubKey(m.Key)
+	keys.AddPubKey(diff)
+	return keys
+}
+
+func distinctKeys(ring []Member) error {
+	seen := make(map[[32]byte]struct{}, len(ring))
+	for _, m := range ring {
+		var k [32]byte
+		copy(k[:], m.Key.Bytes())
+		if _, ok := seen[k]; ok {
+			return errors.New("duplicate key in ring")
+		}
+		seen[k] = struct{}{}
+	}
+	return nil
+}
+
+// message returns the digest every input signs: the fee, the extra data,
+// the pseudo outputs, the outputs, the range proof and the balance proof.
+// The rings and the key images are bound by the signatures themselves
+func (tx *Transaction) message() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.BigEndian, tx.Fee); err != nil {
+		return nil, err
+	}
+	for i := range tx.Inputs {
+		buf.Write(tx.Inputs[i].PseudoOut.Bytes())
+	}
+	for i := range tx.Outputs {
+		buf.Write(tx.Outputs[i].Key.Bytes())
+		buf.Write(tx.Outputs[i].Commitment.Bytes())
+	}
+	rp, err := tx.RangeProof.Hash()
+	if err != nil {
+		return nil, err
+	}
+	buf.Write(rp[:])
+	if err := tx.Balance.Encode(buf); err != nil {
+		return nil, err
+	}
+	return hash.Sha3256WithDomain("dusk.ringct", tx.Extra, buf.Bytes()), nil
+}
+
+// KeyImages returns the key images of the inputs, which the caller checks
+// against the spent ones
+func (tx *Transaction) KeyImages() []ristretto.Point {
+	images := make([]ristretto.Point, len(tx.Inputs))
+	for i := range tx.Inputs {
+		images[i] = tx.Inputs[i].KeyImage
+	}
+	return images
+}
+
+// Verify checks the range proof, the balance and the signature of every
+// input
+func (tx *Transaction) Verify() error {
+	if len(tx.Inputs) == 0 || len(tx.Inputs) > MaxInputs {
+		return errors.Errorf("[Verify] - number of inputs must be between 1 and %d", MaxInputs)
+	}
+	if len(tx.Outputs) == 0 || len(tx.Outputs) > MaxOutputs {
+		return errors.Errorf("[Verify] - number of outputs must be between 1 and %d", MaxOutputs)
+	}
+	if hasDuplicates(tx.KeyImages()) {
+		return errors.New("[Verify] - an output is spent twice")
+	}
+
+	// the range proof covers the outputs, in order, then padding
+	cm, err := rangeproof.MapCommitments(len(tx.Outputs))
+	if err != nil {
+		return errors.Wrap(err, "[Verify] - range proof")
+	}
+	for i := range tx.Outputs {
+		c, err := cm.Commitment(tx.RangeProof, i)
+		if err != nil {
+			return errors.Wrap(err, "[Verify] - range proof")
+		}
+		if !c.Value.Equals(&tx.Outputs[i].Commitment) {
+			return errors.Errorf("[Verify] - range proof does not cover output %d", i)
+		}
+	}
+	ok, err := rangeproof.Verify(tx.RangeProof)
+	if err != nil {
+		return errors.Wrap(err, "[Verify] - range proof")
+	}
+	if !ok {
+		return errors.New("[Verify] - range proof is invalid")
+	}
+
+	// pseudo outputs - outputs - fee commits to zero
+	ped := newPedersen()
+	ins := make([]pedersen.Commitment, len(tx.Inputs))
+	for i := range tx.Inputs {
+		ins[i].Value = tx.Inputs[i].PseudoOut
+	}
+	outs := make([]pedersen.Commitment, len(tx.Outputs)+1)
+	for i := range tx.Outputs {
+		outs[i].Value = tx.Outputs[i].Commitment
+	}
+	feeScalar := scalarFromUint64(tx.Fee)
+	outs[len(tx.Outputs)].Value.ScalarMult(&ped.BasePoint, &feeScalar)
+	balance := pedersen.Sub(pedersen.Sum(ins), pedersen.Sum(outs))
+	if !pedersen.VerifyZero(balance.Value, tx.Balance) {
+		return errors.New("[Verify] - inputs and outputs do not balance")
+	}
+
+	msg, err := tx.message()
+	if err != nil {
+		return err
+	}
+	for i := range tx.Inputs {
+		if err := tx.Inputs[i].verify(msg); err != nil {
+			return errors.Wrapf(err, "[Verify] - input %d", i)
+		}
+	}
+	return nil
+}
+
+func hasDuplicates(images []ristretto.Point) bool {
+	for i := range images {
+		for j := i + 1; j < len(images); j++ {
+			if images[i].Equals(&images[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verify the signature of the input over the ring it lists
+func (in *Input) verify(msg []byte) error {
+	if in.Signature == nil {
+		return errors.New("missing signature")
+	}
+	if len(in.Ring) == 0 || len(in.Ring) > MaxRingSize {
+		return errors.Errorf("ring size must be between 1 and %d", MaxRingSize)
+	}
+	if err := distinctKeys(in.Ring); err != nil {
+		return err
+	}
+
+	// the signature is checked against the ring of the transaction, not
+	// against the keys it may carry
+	sig := *in.Signature
+	sig.PubKeys = make([]mlsag.PubKeys, len(in.Ring))
+	for j, m := range in.Ring {
+		sig.PubKeys[j] = ringKeys(m, in.PseudoOut)
+	}
+	sig.Msg = msg
+
+	ok, err := sig.Verify(in.KeyImage)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// Encode a Transaction. The signatures are encoded without their keys,
+// which follow from the rings and the pseudo outputs
+func (tx *Transaction) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, tx.Fee); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(tx.Extra))); err != nil {
+		return err
+	}
+	if _, err := w.Write(tx.Extra); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(tx.Inputs))); err != nil {
+		return err
+	}
+	for i := range tx.Inputs {
+		if err := tx.Inputs[i].encode(w); err != nil {
+			return err
+		}
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(tx.Outputs))); err != nil {
+		return err
+	}
+	for i := range tx.Outputs {
+		if err := binary.Write(w, binary.BigEndian, tx.Outputs[i].Key.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Balance.Encode(w); err != nil {
+		return err
+	}
+	// the output commitments are encoded within the range proof, which
+	// comes last since it is decoded up to the end of the reader
+	return tx.RangeProof.Encode(w, true)
+}
+
+func (in *Input) encode(w io.Writer) error {
+	if in.Signature == nil {
+		return errors.New("missing signature")
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(in.Ring))); err != nil {
+		return err
+	}
+	for _, m := range in.Ring {
+		if err := binary.Write(w, binary.BigEndian, m.Key.Bytes()); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, m.Commitment.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := binary.Write(w, binary.BigEndian, in.PseudoOut.Bytes()); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, in.KeyImage.Bytes()); err != nil {
+		return err
+	}
+	return in.Signature.Encode(w, false)
+}
+
+// Decode a Transaction. Like rangeproof.Proof.Decode, it consumes the
+// remainder of the reader
+func (tx *Transaction) Decode(r io.Reader) error {
+	if tx == nil {
+		return errors.New("struct is nil")
+	}
+
+	if err := binary.Read(r, binary.BigEndian, &tx.Fee); err != nil {
+		return err
+	}
+	var lenExtra uint32
+	if err := binary.Read(r, binary.BigEndian, &lenExtra); err != nil {
+		return err
+	}
+	if lenExtra > maxExtraSize {
+		return errors.Errorf("extra data of %d bytes is too large", lenExtra)
+	}
+	tx.Extra = make([]byte, lenExtra)
+	if _, err := io.ReadFull(r, tx.Extra); err != nil {
+		return err
+	}
+
+	var lenInputs uint32
+	if err := binary.Read(r, binary.BigEndian, &lenInputs); err != nil {
+		return err
+	}
+	if lenInputs > MaxInputs {
+		return errors.Errorf("too many inputs: %d", lenInputs)
+	}
+	tx.Inputs = make([]Input, lenInputs)
+	for i := range tx.Inputs {
+		if err := tx.Inputs[i].decode(r); err != nil {
+			return err
+		}
+	}
+
+	var lenOutputs uint32
+	if err := binary.Read(r, binary.BigEndian, &lenOutputs); err != nil {
+		return err
+	}
+	if lenOutputs > MaxOutputs {
+		return errors.Errorf("too many outputs: %d", lenOutputs)
+	}
+	tx.Outputs = make([]Output, lenOutputs)
+	for i := range tx.Outputs {
+		if err := readPoint(r, &tx.Outputs[i].Key); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Balance.Decode(r); err != nil {
+		return err
+	}
+	if err := tx.RangeProof.Decode(r, true); err != nil {
+		return err
+	}
+	if len(tx.RangeProof.V) < len(tx.Outputs) {
+		return errors.New("range proof does not cover the outputs")
+	}
+	for i := range tx.Outputs {
+		tx.Outputs[i].Commitment = tx.RangeProof.V[i].Value
+	}
+	return nil
+}
+
+func (in *Input) decode(r io.Reader) error {
+	var lenRing uint32
+	if err := binary.Read(r, binary.BigEndian, &lenRing); err != nil {
+		return err
+	}
+	if lenRing > MaxRingSize {
+		return errors.Errorf("ring of %d members is too large", lenRing)
+	}
+	in.Ring = make([]Member, lenRing)
+	for j := range in.Ring {
+		if err := readPoint(r, &in.Ring[j].Key); err != nil {
+			return err
+		}
+		if err := readPoint(r, &in.Ring[j].Commitment); err != nil {
+			return err
+		}
+	}
+	if err := readPoint(r, &in.PseudoOut); err != nil {
+		return err
+	}
+	if err := readPoint(r, &in.KeyImage); err != nil {
+		return err
+	}
+
+	in.Signature = &mlsag.CLSAGSignature{}
+	return in.Signature.Decode(r, false)
+}
+
+func readPoint(r io.Reader, p *ristretto.Point) error {
+	var x [32]byte
+	if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+		return err
+	}
+	if !p.SetBytes(&x) {
+		return errors.New("point not encodable")
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package ringct
+
+import (
+	"bytes"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ownedSpend returns a spend of an output of the given amount, hidden in a
+// ring of ringSize members
+func ownedSpend(amount uint64, ringSize int) Spend {
+	ped := newPedersen()
+	s := Spend{Index: ringSize / 2, Amount: amount}
+	s.PrivKey.Rand()
+	s.Blind.Rand()
+
+	s.Ring = make([]Member, ringSize)
+	for i := range s.Ring {
+		var k ristretto.Scalar
+		k.Rand()
+		s.Ring[i].Key.ScalarMultBase(&k)
+		s.Ring[i].Commitment = ped.CommitAmount(uint64(i) * 1000).Value
+	}
+	s.Ring[s.Index].Key.ScalarMultBase(&s.PrivKey)
+	s.Ring[s.Index].Commitment = ped.CommitToScalarWithBlind(scalarFromUint64(amount), s.Blind).Value
+	return s
+}
+
+func payment(amount uint64) Payment {
+	var p Payment
+	p.Key.Rand()
+	p.Amount = amount
+	return p
+}
+
+func TestProveVerify(t *testing.T) {
+	spends := []Spend{ownedSpend(70, 8), ownedSpend(40, 5)}
+	payments := []Payment{payment(60), payment(45)}
+
+	tx, openings, err := Prove(spends, payments, 5, []byte("prefix"))
+	require.Nil(t, err)
+	require.Nil(t, tx.Verify())
+
+	// the openings are the ones of the outputs
+	ped := newPedersen()
+	for i := range openings {
+		assert.True(t, ped.Check(openings[i]))
+		assert.True(t, openings[i].Value.Equals(&tx.Outputs[i].Commitment))
+	}
+
+	buf := new(bytes.Buffer)
+	require.Nil(t, tx.Encode(buf))
+	var dec Transaction
+	require.Nil(t, dec.Decode(buf))
+	assert.Nil(t, dec.Verify())
+	for i, I := range dec.KeyImages() {
+		assert.True(t, I.Equals(&tx.Inputs[i].KeyImage))
+	}
+}
+
+func TestProveUnbalanced(t *testing.T) {
+	_, _, err := Prove([]Spend{ownedSpend(70, 4)}, []Payment{payment(70)}, 1, nil)
+	assert.NotNil(t, err)
+
+	// the secrets must open the real member
+	s := ownedSpend(70, 4)
+	s.Amount = 71
+	_, _, err = Prove([]Spend{s}, []Payment{payment(70)}, 1, nil)
+	assert.NotNil(t, err)
+}
+
+func TestTampering(t *testing.T) {
+	prove := func() *Transaction {
+		tx, _, err := Prove([]Spend{ownedSpend(100, 6)}, []Payment{payment(90)}, 10, []byte("prefix"))
+		require.Nil(t, err)
+		return tx
+	}
+
+	for name, tamper := range map[string]func(tx *Transaction){
+		"fee":         func(tx *Transaction) { tx.Fee++ },
+		"extra":       func(tx *Transaction) { tx.Extra = []byte("other") },
+		"output key":  func(tx *Transaction) { tx.Outputs[0].Key.Rand() },
+		"ring member": func(tx *Transaction) { tx.Inputs[0].Ring[0].Key.Rand() },
+		"key image":   func(tx *Transaction) { tx.Inputs[0].KeyImage.Rand() },
+		"pseudo out": func(tx *Transaction) {
+			var p ristretto.Point
+			p.Rand()
+			tx.Inputs[0].PseudoOut.Add(&tx.Inputs[0].PseudoOut, &p)
+		},
+		"spent twice": func(tx *Transaction) {
+			tx.Inputs = append(tx.Inputs, tx.Inputs[0])
+		},
+	} {
+		tx := prove()
+		tamper(tx)
+		assert.NotNil(t, tx.Verify(), name)
+	}
+}
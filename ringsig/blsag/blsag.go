@@ -2,10 +2,10 @@ package blsag
 
 import (
 	"bytes"
-	"math/rand"
-	"time"
+	"math/big"
 
 	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rng"
 )
 
 // RingSignature is the collection of signatures
@@ -24,9 +24,13 @@ func Sign(m []byte, mixin []ristretto.Point, sK ristretto.Scalar) RingSignature
 	var pK ristretto.Point
 	pK.ScalarMultBase(&sK)
 
-	// secret j index
-	rand.Seed(time.Now().UnixNano())
-	j := rand.Intn(len(mixin) + 1)
+	// secret j index, which must be unpredictable: it is the position of the
+	// signer in the ring. Like rng.Scalar, panics if the source fails
+	jBig, err := rng.Int(big.NewInt(int64(len(mixin) + 1)))
+	if err != nil {
+		panic(err)
+	}
+	j := int(jBig.Int64())
 
 	// Hp(pK)
 	var hPK ristretto.Point
@@ -38,13 +42,13 @@ func Sign(m []byte, mixin []ristretto.Point, sK ristretto.Scalar) RingSignature
 
 	// alpha E Zq , where q is G
 	var alpha ristretto.Scalar
-	alpha.Rand()
+	rng.Scalar(&alpha)
 
 	// generate s_i where i =/= j and s_i E Zq
 	sVals := make([]ristretto.Scalar, len(mixin)+1)
 	for i := 1; i < len(sVals); i++ {
 		var s ristretto.Scalar
-		s.Rand()
+		rng.Scalar(&s)
 		sVals[i] = s
 	}
 
@@ -0,0 +1,181 @@
+// Package decoy selects the decoys of a ring signature. Decoys are picked by
+// sampling output ages from a distribution matching how real outputs are
+// spent: picking decoys uniformly lets an observer guess the real input, as
+// it tends to be the most recent member of the ring
+package decoy
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// maxAttemptsPerDecoy bounds the number of samples drawn for every decoy.
+// Once exhausted, the remaining decoys are picked uniformly
+const maxAttemptsPerDecoy = 100
+
+// Distribution samples the age of a decoy, in number of outputs before the
+// most recent one
+type Distribution interface {
+	SampleAge() (float64, error)
+}
+
+// LogGamma samples ages whose logarithm is gamma distributed. Unit is the
+// number of sampled units per output, e.g. the average number of seconds
+// between two outputs when Shape and Rate are fitted on ages in seconds
+type LogGamma struct {
+	Shape float64
+	Rate  float64
+	Unit  float64
+}
+
+// DefaultDistribution is fitted on the spend ages, in seconds, observed on
+// public ledgers, assuming an output every 120 seconds
+var DefaultDistribution = LogGamma{Shape: 19.28, Rate: 1.61, Unit: 120}
+
+// SampleAge implements Distribution
+func (g LogGamma) SampleAge() (float64, error) {
+	if g.Shape <= 0 || g.Rate <= 0 || g.Unit <= 0 {
+		return 0, errors.New("shape, rate and unit must be positive")
+	}
+
+	x, err := sampleGamma(g.Shape)
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Exp(x/g.Rate) / g.Unit, nil
+}
+
+// Uniform samples ages uniformly in [0, Max)
+type Uniform struct {
+	Max float64
+}
+
+// SampleAge implements Distribution
+func (u Uniform) SampleAge() (float64, error) {
+	f, err := randFloat()
+	return f * u.Max, err
+}
+
+// Select picks ringSize-1 distinct decoys for the real output. outputs are
+// the indices of the outputs which can be used as decoys, sorted from the
+// oldest to the most recent, and may contain duplicates, which are picked at
+// most once. The real output is never picked.
+// The decoys are returned in ascending order
+func Select(outputs []uint64, real uint64, ringSize int, dist Distribution) ([]uint64, error) {
+	if ringSize < 2 {
+		return nil, errors.New("ring size must be at least 2")
+	}
+
+	numDecoys := ringSize - 1
+
+	// outputs may repeat, only distinct ones count towards the decoys
+	distinct := make(map[uint64]struct{}, len(outputs))
+	for _, o := range outputs {
+		if o != real {
+			distinct[o] = struct{}{}
+		}
+	}
+	if available := len(distinct); available < numDecoys {
+		return nil, errors.New("not enough outputs to select decoys from")
+	}
+
+	picked := make(map[uint64]struct{}, numDecoys)
+	decoys := make([]uint64, 0, numDecoys)
+	pick := func(o uint64) {
+		if _, ok := picked[o]; ok || o == real {
+			return
+		}
+		picked[o] = struct{}{}
+		decoys = append(decoys, o)
+	}
+
+	for attempts := 0; len(decoys) < numDecoys && attempts < maxAttemptsPerDecoy*numDecoys; attempts++ {
+		age, err := dist.SampleAge()
+		if err != nil {
+			return nil, err
+		}
+		if age < 0 || age >= float64(len(outputs)) {
+			continue
+		}
+		pick(outputs[len(outputs)-1-int(age)])
+	}
+
+	// the distribution keeps missing the outputs, e.g. because the chain is
+	// young, so the remaining decoys are picked uniformly
+	for len(decoys) < numDecoys {
+		f, err := randFloat()
+		if err != nil {
+			return nil, err
+		}
+		pick(outputs[int(f*float64(len(outputs)))])
+	}
+
+	sort.Slice(decoys, func(i, j int) bool { return decoys[i] < decoys[j] })
+	return decoys, nil
+}
+
+// randFloat returns a uniform float in [0, 1)
+func randFloat() (float64, error) {
+	var b [8]byte
+	if err := rng.Read(b[:]); err != nil {
+		return 0, err
+	}
+	return float64(binary.BigEndian.Uint64(b[:])>>11) / (1 << 53), nil
+}
+
+// randNormal returns a standard normal sample, using the Box-Muller transform
+func randNormal() (float64, error) {
+	u1, err := randFloat()
+	if err != nil {
+		return 0, err
+	}
+	u2, err := randFloat()
+	if err != nil {
+		return 0, err
+	}
+	return math.Sqrt(-2*math.Log(1-u1)) * math.Cos(2*math.Pi*u2), nil
+}
+
+// sampleGamma returns a sample of Gamma(shape, 1), using the method of
+// Marsaglia and Tsang
+func sampleGamma(shape float64) (float64, error) {
+	if shape < 1 {
+		// Gamma(a) = Gamma(a+1) * U^(1/a)
+		x, err := sampleGamma(shape + 1)
+		if err != nil {
+			return 0, err
+		}
+		u, err := randFloat()
+		if err != nil {
+			return 0, err
+		}
+		return x * math.Pow(1-u, 1/shape), nil
+	}
+
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x, err := randNormal()
+		if err != nil {
+			return 0, err
+		}
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+
+		u, err := randFloat()
+		if err != nil {
+			return 0, err
+		}
+		if math.Log(1-u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v, nil
+		}
+	}
+}
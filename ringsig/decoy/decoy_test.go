@@ -0,0 +1,95 @@
+package decoy
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOutputs(n int) []uint64 {
+	outputs := make([]uint64, n)
+	for i := range outputs {
+		outputs[i] = uint64(1000 + i)
+	}
+	return outputs
+}
+
+func TestSelect(t *testing.T) {
+	outputs := testOutputs(10000)
+	real := outputs[9990]
+
+	for _, dist := range []Distribution{DefaultDistribution, Uniform{Max: 10000}} {
+		decoys, err := Select(outputs, real, 11, dist)
+		require.Nil(t, err)
+		require.Len(t, decoys, 10)
+		assert.True(t, sort.SliceIsSorted(decoys, func(i, j int) bool { return decoys[i] < decoys[j] }))
+
+		seen := map[uint64]bool{}
+		for _, d := range decoys {
+			assert.NotEqual(t, real, d)
+			assert.True(t, d >= 1000 && d < 11000)
+			assert.False(t, seen[d])
+			seen[d] = true
+		}
+	}
+}
+
+func TestSelectFavoursRecentOutputs(t *testing.T) {
+	outputs := testOutputs(100000)
+
+	recent := 0
+	total := 0
+	for i := 0; i < 20; i++ {
+		decoys, err := Select(outputs, outputs[0], 11, DefaultDistribution)
+		require.Nil(t, err)
+		for _, d := range decoys {
+			total++
+			if d >= outputs[len(outputs)/2] {
+				recent++
+			}
+		}
+	}
+
+	// with the default distribution most decoys are a few days old at most
+	assert.True(t, recent > total*3/4)
+}
+
+func TestSelectSmallSet(t *testing.T) {
+	outputs := testOutputs(5)
+
+	// the whole set is needed, the fallback picks what the distribution misses
+	decoys, err := Select(outputs, outputs[2], 5, DefaultDistribution)
+	require.Nil(t, err)
+	assert.Equal(t, []uint64{1000, 1001, 1003, 1004}, decoys)
+
+	_, err = Select(outputs, outputs[2], 6, DefaultDistribution)
+	assert.NotNil(t, err)
+
+	_, err = Select(outputs, outputs[2], 1, DefaultDistribution)
+	assert.NotNil(t, err)
+
+	// duplicates do not count towards the available outputs
+	dup := append(append([]uint64{}, outputs...), outputs...)
+	_, err = Select(dup, outputs[2], 6, DefaultDistribution)
+	assert.NotNil(t, err)
+
+	decoys, err = Select(dup, outputs[2], 5, DefaultDistribution)
+	require.Nil(t, err)
+	assert.Equal(t, []uint64{1000, 1001, 1003, 1004}, decoys)
+}
+
+func TestSampleGamma(t *testing.T) {
+	for _, shape := range []float64{0.5, 2, 19.28} {
+		n := 20000
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			x, err := sampleGamma(shape)
+			require.Nil(t, err)
+			sum += x
+		}
+		// the mean of Gamma(shape, 1) is shape
+		assert.InDelta(t, shape, sum/float64(n), shape*0.05)
+	}
+}
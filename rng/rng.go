@@ -0,0 +1,118 @@
+// Package rng is the source of randomness of the repository. Key
+// generation, nonces and blinding factors are all drawn from Reader, which
+// reads from crypto/rand unless another source is installed with
+// SetSource.
+//
+// Deterministic returns a seeded source, so that fuzzers and tests can
+// reproduce the randomness of every package. It must never be installed in
+// production
+package rng
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+	"sync"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	mu     sync.RWMutex
+	source io.Reader = rand.Reader
+)
+
+// Reader reads from the current source. Packages that take an io.Reader
+// default to it
+var Reader io.Reader = reader{}
+
+type reader struct{}
+
+func (reader) Read(b []byte) (int, error) {
+	mu.RLock()
+	r := source
+	mu.RUnlock()
+	return r.Read(b)
+}
+
+// SetSource replaces the source of randomness, and returns a function which
+// restores the previous one. A nil source restores crypto/rand
+func SetSource(r io.Reader) (restore func()) {
+	if r == nil {
+		r = rand.Reader
+	}
+
+	mu.Lock()
+	prev := source
+	source = r
+	mu.Unlock()
+
+	return func() {
+		mu.Lock()
+		source = prev
+		mu.Unlock()
+	}
+}
+
+// Read fills b with random bytes
+func Read(b []byte) error {
+	_, err := io.ReadFull(Reader, b)
+	return err
+}
+
+// Scalar sets s to a uniformly random scalar, and returns s. Like
+// ristretto.Scalar.Rand, which it replaces, it panics if the source fails
+func Scalar(s *ristretto.Scalar) *ristretto.Scalar {
+	var buf [64]byte
+	if err := Read(buf[:]); err != nil {
+		panic(err)
+	}
+	return s.SetReduced(&buf)
+}
+
+// Int returns a uniformly random integer in [0, max)
+func Int(max *big.Int) (*big.Int, error) {
+	return rand.Int(Reader, max)
+}
+
+// Or returns r, or Reader if r is nil
+func Or(r io.Reader) io.Reader {
+	if r == nil {
+		return Reader
+	}
+	return r
+}
+
+// deterministic is a ChaCha20 keystream keyed with the hash of a seed
+type deterministic struct {
+	mu sync.Mutex
+	c  *chacha20.Cipher
+}
+
+// Deterministic returns a source of pseudorandom bytes derived from seed.
+// It is safe for concurrent use, but the bytes each reader gets then
+// depend on the scheduling
+func Deterministic(seed []byte) io.Reader {
+	key := sha3.Sum256(append([]byte("dusk.rng.deterministic"), seed...))
+	var nonce [chacha20.NonceSize]byte
+	c, err := chacha20.NewUnauthenticatedCipher(key[:], nonce[:])
+	if err != nil {
+		// The key and nonce sizes are fixed
+		panic(err)
+	}
+	return &deterministic{c: c}
+}
+
+func (d *deterministic) Read(b []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// The keystream lasts for 256 GiB, far more than any test draws
+	for i := range b {
+		b[i] = 0
+	}
+	d.c.XORKeyStream(b, b)
+	return len(b), nil
+}
@@ -0,0 +1,61 @@
+package rng
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeterministic(t *testing.T) {
+	a, b := make([]byte, 100), make([]byte, 100)
+	_, err := Deterministic([]byte("seed")).Read(a)
+	require.NoError(t, err)
+	_, err = Deterministic([]byte("seed")).Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	_, err = Deterministic([]byte("other seed")).Read(b)
+	require.NoError(t, err)
+	assert.NotEqual(t, a, b)
+
+	// Reads continue the stream
+	r := Deterministic([]byte("seed"))
+	c := make([]byte, 60)
+	_, _ = r.Read(c[:30])
+	_, _ = r.Read(c[30:])
+	assert.Equal(t, a[:60], c)
+}
+
+func TestSetSource(t *testing.T) {
+	restore := SetSource(Deterministic([]byte("seed")))
+	var s1 ristretto.Scalar
+	Scalar(&s1)
+	n1, err := Int(big.NewInt(1000000))
+	require.NoError(t, err)
+	restore()
+
+	restore = SetSource(Deterministic([]byte("seed")))
+	var s2 ristretto.Scalar
+	Scalar(&s2)
+	n2, err := Int(big.NewInt(1000000))
+	require.NoError(t, err)
+	restore()
+
+	assert.True(t, s1.Equals(&s2))
+	assert.Equal(t, 0, n1.Cmp(n2))
+
+	// crypto/rand is back
+	var s3 ristretto.Scalar
+	Scalar(&s3)
+	assert.False(t, s1.Equals(&s3))
+}
+
+func TestOr(t *testing.T) {
+	r := bytes.NewReader(nil)
+	assert.Equal(t, r, Or(r))
+	assert.Equal(t, Reader, Or(nil))
+}
@@ -0,0 +1,245 @@
+// Package scalarutil converts scalars between their forms in the
+// repository: big.Int, the scalars of Ristretto, ristretto.Scalar, and
+// those of bn256, which are big.Ints below bn256.Order.
+//
+// Every conversion which may not fit takes a Policy: Strict rejects the
+// values outside of the range of the target and the encodings Marshal would
+// not produce, and Reduce reduces them modulo the order of the target. The
+// conversions never reduce silently.
+//
+// Beware of byte orders: Ristretto scalars encode in little endian, while
+// bn256 scalars and big.Int encode in big endian. The functions below take
+// and return each in its own order
+package scalarutil
+
+import (
+	"math/big"
+	"strconv"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/ct"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+)
+
+// Size is the size of encoded scalars, of both groups
+const Size = 32
+
+// Policy says what to do with values out of the range of the target
+type Policy int
+
+const (
+	// Strict rejects values out of range and non canonical encodings
+	Strict Policy = iota
+	// Reduce reduces values modulo the order of the target. Encodings of
+	// Size or 2*Size bytes are accepted, the latter to reduce the output of
+	// hashes without bias
+	Reduce
+)
+
+// ristrettoOrder is l, the order of the Ristretto group
+var ristrettoOrder, _ = new(big.Int).SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+
+// RistrettoOrder returns the order of the Ristretto group
+func RistrettoOrder() *big.Int {
+	return new(big.Int).Set(ristrettoOrder)
+}
+
+// BN256Order returns the order of the bn256 groups
+func BN256Order() *big.Int {
+	return new(big.Int).Set(bn256.Order)
+}
+
+// fit returns x in [0, order) according to p. x is not modified
+func fit(x, order *big.Int, p Policy) (*big.Int, error) {
+	if x == nil {
+		return nil, cryptoerrors.New(cryptoerrors.ErrOutOfRange, "scalarutil: nil scalar")
+	}
+	switch p {
+	case Strict:
+		if x.Sign() < 0 || x.Cmp(order) >= 0 {
+			return nil, cryptoerrors.New(cryptoerrors.ErrOutOfRange, "scalarutil: scalar out of range")
+		}
+		return new(big.Int).Set(x), nil
+	case Reduce:
+		// Mod is euclidean: negative values map to [0, order) too
+		return new(big.Int).Mod(x, order), nil
+	}
+	return nil, cryptoerrors.New(cryptoerrors.ErrOutOfRange, "scalarutil: unknown policy")
+}
+
+// BigToRistretto converts x to a Ristretto scalar
+func BigToRistretto(x *big.Int, p Policy) (ristretto.Scalar, error) {
+	var s ristretto.Scalar
+	y, err := fit(x, ristrettoOrder, p)
+	if err != nil {
+		return s, err
+	}
+	s.SetBigInt(y)
+	return s, nil
+}
+
+// RistrettoToBig returns the value of s, in [0, l)
+func RistrettoToBig(s *ristretto.Scalar) *big.Int {
+	return s.BigInt()
+}
+
+// BigToBN256 converts x to a bn256 scalar
+func BigToBN256(x *big.Int, p Policy) (*big.Int, error) {
+	return fit(x, bn256.Order, p)
+}
+
+// RistrettoToBN256 converts s to a bn256 scalar. The order of Ristretto is
+// smaller than the one of bn256, so every value fits. The other way is
+// BigToRistretto
+func RistrettoToBN256(s *ristretto.Scalar) *big.Int {
+	return s.BigInt()
+}
+
+// RistrettoFromBytes decodes a little endian Ristretto scalar. Strict only
+// accepts the Size byte encodings of values below l, which SetBytes would
+// otherwise truncate or partially reduce
+func RistrettoFromBytes(b []byte, p Policy) (ristretto.Scalar, error) {
+	var s ristretto.Scalar
+	switch p {
+	case Strict:
+		if len(b) != Size {
+			return s, cryptoerrors.New(cryptoerrors.ErrInvalidLength, "scalarutil: invalid scalar length")
+		}
+		var buf [Size]byte
+		copy(buf[:], b)
+		s.SetBytes(&buf)
+		if !ct.Equal(s.Bytes(), b) {
+			return ristretto.Scalar{}, cryptoerrors.New(cryptoerrors.ErrNotCanonical, "scalarutil: scalar is not canonical")
+		}
+		return s, nil
+	case Reduce:
+		if len(b) != Size && len(b) != 2*Size {
+			return s, cryptoerrors.New(cryptoerrors.ErrInvalidLength, "scalarutil: invalid scalar length")
+		}
+		var wide [2 * Size]byte
+		copy(wide[:], b)
+		s.SetReduced(&wide)
+		return s, nil
+	}
+	return s, cryptoerrors.New(cryptoerrors.ErrOutOfRange, "scalarutil: unknown policy")
+}
+
+// BN256FromBytes decodes a big endian bn256 scalar. Strict only accepts the
+// Size byte encodings of values below the order
+func BN256FromBytes(b []byte, p Policy) (*big.Int, error) {
+	switch p {
+	case Strict:
+		if len(b) != Size {
+			return nil, cryptoerrors.New(cryptoerrors.ErrInvalidLength, "scalarutil: invalid scalar length")
+		}
+		// The range check does not depend on the value of the scalar
+		var order [Size]byte
+		ob := bn256.Order.Bytes()
+		copy(order[Size-len(ob):], ob)
+		if ct.LessThan(b, order[:]) != 1 {
+			return nil, cryptoerrors.New(cryptoerrors.ErrNotCanonical, "scalarutil: scalar is not canonical")
+		}
+		return new(big.Int).SetBytes(b), nil
+	case Reduce:
+		if len(b) != Size && len(b) != 2*Size {
+			return nil, cryptoerrors.New(cryptoerrors.ErrInvalidLength, "scalarutil: invalid scalar length")
+		}
+		x := new(big.Int).SetBytes(b)
+		return x.Mod(x, bn256.Order), nil
+	}
+	return nil, cryptoerrors.New(cryptoerrors.ErrOutOfRange, "scalarutil: unknown policy")
+}
+
+// BN256ToBytes encodes a bn256 scalar in Size big endian bytes, the way
+// bls marshals secret keys. Values out of range are rejected
+func BN256ToBytes(x *big.Int) ([Size]byte, error) {
+	var res [Size]byte
+	y, err := fit(x, bn256.Order, Strict)
+	if err != nil {
+		return res, err
+	}
+	yb := y.Bytes()
+	copy(res[Size-len(yb):], yb)
+	return res, nil
+}
+
+// element wraps the error of the i-th element of a batch, keeping its kind
+func element(i int, err error) error {
+	return cryptoerrors.Wrap(cryptoerrors.KindOf(err), err, "scalarutil: element "+strconv.Itoa(i))
+}
+
+// BigToRistrettoBatch converts xs with BigToRistretto. The error, if any,
+// is the one of the first element failing, and names its index
+func BigToRistrettoBatch(xs []*big.Int, p Policy) ([]ristretto.Scalar, error) {
+	res := make([]ristretto.Scalar, len(xs))
+	for i, x := range xs {
+		s, err := BigToRistretto(x, p)
+		if err != nil {
+			return nil, element(i, err)
+		}
+		res[i] = s
+	}
+	return res, nil
+}
+
+// RistrettoToBigBatch converts ss with RistrettoToBig
+func RistrettoToBigBatch(ss []ristretto.Scalar) []*big.Int {
+	res := make([]*big.Int, len(ss))
+	for i := range ss {
+		res[i] = RistrettoToBig(&ss[i])
+	}
+	return res
+}
+
+// BigToBN256Batch converts xs with BigToBN256
+func BigToBN256Batch(xs []*big.Int, p Policy) ([]*big.Int, error) {
+	res := make([]*big.Int, len(xs))
+	for i, x := range xs {
+		y, err := BigToBN256(x, p)
+		if err != nil {
+			return nil, element(i, err)
+		}
+		res[i] = y
+	}
+	return res, nil
+}
+
+// RistrettoFromBytesBatch decodes bs with RistrettoFromBytes
+func RistrettoFromBytesBatch(bs [][]byte, p Policy) ([]ristretto.Scalar, error) {
+	res := make([]ristretto.Scalar, len(bs))
+	for i, b := range bs {
+		s, err := RistrettoFromBytes(b, p)
+		if err != nil {
+			return nil, element(i, err)
+		}
+		res[i] = s
+	}
+	return res, nil
+}
+
+// BN256FromBytesBatch decodes bs with BN256FromBytes
+func BN256FromBytesBatch(bs [][]byte, p Policy) ([]*big.Int, error) {
+	res := make([]*big.Int, len(bs))
+	for i, b := range bs {
+		x, err := BN256FromBytes(b, p)
+		if err != nil {
+			return nil, element(i, err)
+		}
+		res[i] = x
+	}
+	return res, nil
+}
+
+// BN256ToBytesBatch encodes xs with BN256ToBytes
+func BN256ToBytesBatch(xs []*big.Int) ([][Size]byte, error) {
+	res := make([][Size]byte, len(xs))
+	for i, x := range xs {
+		b, err := BN256ToBytes(x)
+		if err != nil {
+			return nil, element(i, err)
+		}
+		res[i] = b
+	}
+	return res, nil
+}
@@ -0,0 +1,195 @@
+package scalarutil
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/bn256"
+	cryptoerrors "github.com/dusk-network/dusk-crypto/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrders(t *testing.T) {
+	var s ristretto.Scalar
+	s.SetBigInt(RistrettoOrder())
+	assert.True(t, s.Equals(new(ristretto.Scalar).SetZero()))
+	assert.Equal(t, bn256.Order, BN256Order())
+
+	// the orders are copies
+	RistrettoOrder().SetInt64(1)
+	assert.Equal(t, 253, RistrettoOrder().BitLen())
+}
+
+func TestBigToRistretto(t *testing.T) {
+	l := RistrettoOrder()
+	lMinusOne := new(big.Int).Sub(l, big.NewInt(1))
+
+	s, err := BigToRistretto(lMinusOne, Strict)
+	require.NoError(t, err)
+	assert.Equal(t, lMinusOne, RistrettoToBig(&s))
+
+	for _, x := range []*big.Int{l, big.NewInt(-1), new(big.Int).Lsh(l, 1), nil} {
+		_, err = BigToRistretto(x, Strict)
+		assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrOutOfRange))
+	}
+
+	s, err = BigToRistretto(big.NewInt(-1), Reduce)
+	require.NoError(t, err)
+	assert.Equal(t, lMinusOne, RistrettoToBig(&s))
+	s, err = BigToRistretto(new(big.Int).Add(l, big.NewInt(5)), Reduce)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), RistrettoToBig(&s))
+
+	_, err = BigToRistretto(big.NewInt(1), Policy(7))
+	assert.Error(t, err)
+}
+
+func TestBigToBN256(t *testing.T) {
+	x, err := BigToBN256(big.NewInt(42), Strict)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(42), x)
+
+	_, err = BigToBN256(bn256.Order, Strict)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrOutOfRange))
+	x, err = BigToBN256(new(big.Int).Add(bn256.Order, big.NewInt(3)), Reduce)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(3), x)
+
+	// the input is never modified
+	in := big.NewInt(-2)
+	_, err = BigToBN256(in, Reduce)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(-2), in)
+}
+
+func TestRistrettoToBN256(t *testing.T) {
+	var s ristretto.Scalar
+	s.Rand()
+	x := RistrettoToBN256(&s)
+	_, err := BigToBN256(x, Strict)
+	assert.NoError(t, err)
+
+	back, err := BigToRistretto(x, Strict)
+	require.NoError(t, err)
+	assert.True(t, s.Equals(&back))
+}
+
+func TestRistrettoFromBytes(t *testing.T) {
+	var s ristretto.Scalar
+	s.Rand()
+	dec, err := RistrettoFromBytes(s.Bytes(), Strict)
+	require.NoError(t, err)
+	assert.True(t, s.Equals(&dec))
+
+	// l itself, little endian, and a value SetBytes truncates
+	l := littleEndian(RistrettoOrder())
+	_, err = RistrettoFromBytes(l, Strict)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrNotCanonical))
+	high := make([]byte, Size)
+	high[Size-1] = 0xe0
+	_, err = RistrettoFromBytes(high, Strict)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrNotCanonical))
+	_, err = RistrettoFromBytes(s.Bytes()[1:], Strict)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrInvalidLength))
+
+	dec, err = RistrettoFromBytes(l, Reduce)
+	require.NoError(t, err)
+	assert.Equal(t, 0, RistrettoToBig(&dec).Sign())
+	dec, err = RistrettoFromBytes(high, Reduce)
+	require.NoError(t, err)
+	want := new(big.Int).Lsh(big.NewInt(7), 253)
+	assert.Equal(t, want.Mod(want, RistrettoOrder()), RistrettoToBig(&dec))
+
+	wide := bytes.Repeat([]byte{0xff}, 2*Size)
+	dec, err = RistrettoFromBytes(wide, Reduce)
+	require.NoError(t, err)
+	want = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 512), big.NewInt(1))
+	assert.Equal(t, want.Mod(want, RistrettoOrder()), RistrettoToBig(&dec))
+	_, err = RistrettoFromBytes(wide[1:], Reduce)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrInvalidLength))
+}
+
+func TestBN256Bytes(t *testing.T) {
+	x := new(big.Int).Sub(bn256.Order, big.NewInt(1))
+	b, err := BN256ToBytes(x)
+	require.NoError(t, err)
+	dec, err := BN256FromBytes(b[:], Strict)
+	require.NoError(t, err)
+	assert.Equal(t, x, dec)
+
+	// small values are padded
+	b, err = BN256ToBytes(big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, byte(1), b[Size-1])
+	assert.Equal(t, make([]byte, Size-1), b[:Size-1])
+
+	_, err = BN256ToBytes(bn256.Order)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrOutOfRange))
+
+	order := bigEndian(bn256.Order)
+	_, err = BN256FromBytes(order, Strict)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrNotCanonical))
+	dec, err = BN256FromBytes(order, Reduce)
+	require.NoError(t, err)
+	assert.Equal(t, 0, dec.Sign())
+	_, err = BN256FromBytes(order[1:], Strict)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrInvalidLength))
+}
+
+func TestBatch(t *testing.T) {
+	xs := []*big.Int{big.NewInt(1), big.NewInt(2), RistrettoOrder()}
+	_, err := BigToRistrettoBatch(xs, Strict)
+	require.Error(t, err)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrOutOfRange))
+	assert.Contains(t, err.Error(), "element 2")
+
+	ss, err := BigToRistrettoBatch(xs, Reduce)
+	require.NoError(t, err)
+	for i, x := range RistrettoToBigBatch(ss) {
+		assert.Equal(t, int64(i+1)%3, x.Int64())
+	}
+
+	ys, err := BigToBN256Batch(xs, Strict)
+	require.NoError(t, err)
+	enc, err := BN256ToBytesBatch(ys)
+	require.NoError(t, err)
+	bs := make([][]byte, len(enc))
+	for i := range enc {
+		bs[i] = enc[i][:]
+	}
+	dec, err := BN256FromBytesBatch(bs, Strict)
+	require.NoError(t, err)
+	assert.Equal(t, ys, dec)
+
+	_, err = BN256ToBytesBatch([]*big.Int{big.NewInt(-1)})
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrOutOfRange))
+	_, err = BigToBN256Batch([]*big.Int{nil}, Reduce)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrOutOfRange))
+
+	rs := [][]byte{ss[0].Bytes(), littleEndian(RistrettoOrder())}
+	_, err = RistrettoFromBytesBatch(rs, Strict)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrNotCanonical))
+	assert.Contains(t, err.Error(), "element 1")
+	_, err = RistrettoFromBytesBatch(rs, Reduce)
+	assert.NoError(t, err)
+	_, err = BN256FromBytesBatch([][]byte{{1}}, Reduce)
+	assert.True(t, cryptoerrors.Is(err, cryptoerrors.ErrInvalidLength))
+}
+
+func bigEndian(x *big.Int) []byte {
+	b := make([]byte, Size)
+	xb := x.Bytes()
+	copy(b[Size-len(xb):], xb)
+	return b
+}
+
+func littleEndian(x *big.Int) []byte {
+	b := bigEndian(x)
+	for i := 0; i < Size/2; i++ {
+		b[i], b[Size-1-i] = b[Size-1-i], b[i]
+	}
+	return b
+}
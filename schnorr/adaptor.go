@@ -0,0 +1,63 @@
+package schnorr
+
+import (
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/nonce"
+)
+
+// PreSignature is a Schnorr signature encrypted under an adaptor point T = t * G.
+// Anyone can check that it becomes a valid signature once adapted with t,
+// and whoever sees both the pre-signature and the final signature learns t.
+// This is the building block of atomic swaps and payment channels
+type PreSignature struct {
+	// R is the nonce of the final signature, R = k * G + T
+	R ristretto.Point
+	S ristretto.Scalar
+}
+
+// PreSign creates a pre-signature of msg with the secret key sk, encrypted
+// under the adaptor point T. The nonce is derived from sk, msg and T
+func PreSign(sk ristretto.Scalar, msg []byte, T ristretto.Point) PreSignature {
+	pk := PublicKey(sk)
+	k := nonce.Scalar("dusk.schnorr.adaptor", sk.Bytes(), pk.Bytes(), T.Bytes(), msg)
+
+	var pre PreSignature
+	pre.R.ScalarMultBase(&k)
+	pre.R.Add(&pre.R, &T)
+
+	c := Challenge(pre.R, pk, msg)
+
+	// s' = k + c * sk
+	pre.S.MulAdd(&c, &sk, &k)
+	return pre
+}
+
+// PreVerify checks that the pre-signature becomes a valid signature of msg
+// for pk once adapted with the discrete log of T
+func PreVerify(pk ristretto.Point, msg []byte, T ristretto.Point, pre PreSignature) bool {
+	c := Challenge(pre.R, pk, msg)
+
+	// s' * G == R - T + c * P
+	var lhs, rhs ristretto.Point
+	lhs.ScalarMultBase(&pre.S)
+	rhs.ScalarMult(&pk, &c)
+	rhs.Add(&rhs, &pre.R)
+	rhs.Sub(&rhs, &T)
+
+	return lhs.Equals(&rhs)
+}
+
+// Adapt completes the pre-signature with the adaptor secret t
+func Adapt(pre PreSignature, t ristretto.Scalar) Signature {
+	sig := Signature{R: pre.R}
+	sig.S.Add(&pre.S, &t)
+	return sig
+}
+
+// Extract recovers the adaptor secret from a pre-signature and the
+// signature it was completed into
+func Extract(sig Signature, pre PreSignature) ristretto.Scalar {
+	var t ristretto.Scalar
+	t.Sub(&sig.S, &pre.S)
+	return t
+}
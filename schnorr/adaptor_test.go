@@ -0,0 +1,29 @@
+package schnorr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptorSignature(t *testing.T) {
+	sk, pk := GenerateKey()
+	secret, T := GenerateKey()
+	msg := []byte("swap")
+
+	pre := PreSign(sk, msg, T)
+	assert.True(t, PreVerify(pk, msg, T, pre))
+
+	// the pre-signature alone is not a valid signature
+	assert.False(t, Verify(pk, msg, Signature{R: pre.R, S: pre.S}))
+
+	// nor does it verify under another adaptor point
+	_, other := GenerateKey()
+	assert.False(t, PreVerify(pk, msg, other, pre))
+
+	sig := Adapt(pre, secret)
+	assert.True(t, Verify(pk, msg, sig))
+
+	extracted := Extract(sig, pre)
+	assert.True(t, extracted.Equals(&secret))
+}
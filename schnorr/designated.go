@@ -0,0 +1,136 @@
+package schnorr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/nonce"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// DesignatedSignature convinces a single verifier that msg was signed by
+// the signer. It is a proof of knowledge of the secret key of the signer or
+// of the verifier: the verifier knows it did not produce it, but it can
+// produce indistinguishable signatures itself with Simulate, so the
+// signature proves nothing to anyone else. Receipts that must not be
+// transferable to third parties use it.
+//
+// The proof is an OR of two Schnorr proofs, one answered with the secret
+// key and the other one simulated. Their challenges must add up to
+// c = H(P_s || P_v || R_s || R_v || msg), with R = s * G - c * P
+type DesignatedSignature struct {
+	// CSigner, SSigner answer for the key of the signer, CVerifier,
+	// SVerifier for the key of the verifier
+	CSigner, SSigner     ristretto.Scalar
+	CVerifier, SVerifier ristretto.Scalar
+}
+
+func designatedChallenge(signer, verifier, Rs, Rv ristretto.Point, msg []byte) ristretto.Scalar {
+	t := transcript.New("dusk.schnorr.designated")
+	t.AppendPoint("signer", signer)
+	t.AppendPoint("verifier", verifier)
+	t.AppendPoint("Rs", Rs)
+	t.AppendPoint("Rv", Rv)
+	t.Append("msg", msg)
+	return t.ChallengeScalar("c")
+}
+
+// commitment returns s * G - c * P
+func commitment(c, s ristretto.Scalar, P ristretto.Point) ristretto.Point {
+	var R, cP ristretto.Point
+	R.ScalarMultBase(&s)
+	cP.ScalarMult(&P, &c)
+	R.Sub(&R, &cP)
+	return R
+}
+
+// proveOr answers the branch of sk, the signer branch if mine is true, and
+// simulates the branch of the public key other. The nonce and the
+// simulated answer are derived from sk and every public input
+func proveOr(sk ristretto.Scalar, other ristretto.Point, signer, verifier ristretto.Point, msg []byte, mine bool) (cMine, sMine, cOther, sOther ristretto.Scalar) {
+	n := nonce.Scalars("dusk.schnorr.designated", sk.Bytes(), 3, signer.Bytes(), verifier.Bytes(), msg)
+	k := n[0]
+	cOther, sOther = n[1], n[2]
+
+	var Rmine ristretto.Point
+	Rmine.ScalarMultBase(&k)
+	Rother := commitment(cOther, sOther, other)
+
+	var c ristretto.Scalar
+	if mine {
+		c = designatedChallenge(signer, verifier, Rmine, Rother, msg)
+	} else {
+		c = designatedChallenge(signer, verifier, Rother, Rmine, msg)
+	}
+	cMine.Sub(&c, &cOther)
+	// s = k + c * sk
+	sMine.MulAdd(&cMine, &sk, &k)
+	return cMine, sMine, cOther, sOther
+}
+
+// SignDesignated signs msg with the secret key sk for the verifier with
+// public key verifier only
+func SignDesignated(sk ristretto.Scalar, verifier ristretto.Point, msg []byte) DesignatedSignature {
+	var sig DesignatedSignature
+	sig.CSigner, sig.SSigner, sig.CVerifier, sig.SVerifier = proveOr(sk, verifier, PublicKey(sk), verifier, msg, true)
+	return sig
+}
+
+// Simulate produces, with the secret key of the verifier, a signature of
+// msg by signer that verifies like a genuine one. It is what makes
+// designated signatures non transferable
+func Simulate(verifierSK ristretto.Scalar, signer ristretto.Point, msg []byte) DesignatedSignature {
+	var sig DesignatedSignature
+	sig.CVerifier, sig.SVerifier, sig.CSigner, sig.SSigner = proveOr(verifierSK, signer, signer, PublicKey(verifierSK), msg, false)
+	return sig
+}
+
+// VerifyDesignated checks a designated signature of msg by signer for
+// verifier. It only convinces the holder of the secret key of verifier,
+// who knows it did not simulate the signature itself
+func VerifyDesignated(signer, verifier ristretto.Point, msg []byte, sig DesignatedSignature) bool {
+	// with a single key, the OR would be answered by the signer alone
+	if signer.Equals(&verifier) {
+		return false
+	}
+
+	Rs := commitment(sig.CSigner, sig.SSigner, signer)
+	Rv := commitment(sig.CVerifier, sig.SVerifier, verifier)
+	c := designatedChallenge(signer, verifier, Rs, Rv, msg)
+
+	var sum ristretto.Scalar
+	sum.Add(&sig.CSigner, &sig.CVerifier)
+	return sum.Equals(&c)
+}
+
+// Encode a DesignatedSignature
+func (sig *DesignatedSignature) Encode(w io.Writer) error {
+	for _, s := range []*ristretto.Scalar{&sig.CSigner, &sig.SSigner, &sig.CVerifier, &sig.SVerifier} {
+		if err := binary.Write(w, binary.BigEndian, s.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode a DesignatedSignature
+func (sig *DesignatedSignature) Decode(r io.Reader) error {
+	if sig == nil {
+		return errors.New("struct is nil")
+	}
+
+	var x [32]byte
+	for _, s := range []*ristretto.Scalar{&sig.CSigner, &sig.SSigner, &sig.CVerifier, &sig.SVerifier} {
+		if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+			return err
+		}
+		s.SetBytes(&x)
+		if !bytes.Equal(s.Bytes(), x[:]) {
+			return errors.New("scalar is not canonically encoded")
+		}
+	}
+	return nil
+}
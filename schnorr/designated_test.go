@@ -0,0 +1,39 @@
+package schnorr
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDesignatedSignature(t *testing.T) {
+	sk, pk := GenerateKey()
+	vsk, vpk := GenerateKey()
+	msg := []byte("receipt")
+
+	sig := SignDesignated(sk, vpk, msg)
+	assert.True(t, VerifyDesignated(pk, vpk, msg, sig))
+	assert.False(t, VerifyDesignated(pk, vpk, []byte("other receipt"), sig))
+
+	// bound to the designated verifier
+	_, other := GenerateKey()
+	assert.False(t, VerifyDesignated(pk, other, msg, sig))
+	assert.False(t, VerifyDesignated(other, vpk, msg, sig))
+
+	// the verifier can produce signatures that verify just as well
+	sim := Simulate(vsk, pk, msg)
+	assert.True(t, VerifyDesignated(pk, vpk, msg, sim))
+
+	// a signer designating itself proves nothing
+	self := SignDesignated(sk, pk, msg)
+	assert.False(t, VerifyDesignated(pk, pk, msg, self))
+
+	buf := new(bytes.Buffer)
+	require.Nil(t, sig.Encode(buf))
+	assert.Equal(t, 128, buf.Len())
+	var dec DesignatedSignature
+	require.Nil(t, dec.Decode(buf))
+	assert.True(t, VerifyDesignated(pk, vpk, msg, dec))
+}
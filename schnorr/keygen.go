@@ -0,0 +1,65 @@
+package schnorr
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// MaxKeyPairs bounds the number of key pairs of a batch
+const MaxKeyPairs = 1 << 20
+
+// GenKeyPairs generates n key pairs, like n calls to GenerateKey but reading
+// the randomness of all the keys at once. Multiplications by the base point
+// already use a precomputed table
+func GenKeyPairs(r io.Reader, n int) ([]ristretto.Scalar, []ristretto.Point, error) {
+	return GenKeyPairsParallel(r, n, 1)
+}
+
+// GenKeyPairsParallel is GenKeyPairs over workers goroutines, or
+// runtime.NumCPU() if workers is not positive. The keys only depend on the
+// randomness read, not on the number of workers
+func GenKeyPairsParallel(r io.Reader, n, workers int) ([]ristretto.Scalar, []ristretto.Point, error) {
+	if n < 1 || n > MaxKeyPairs {
+		return nil, nil, errors.New("invalid number of key pairs")
+	}
+
+	buf := make([]byte, 64*n)
+	if _, err := io.ReadFull(rng.Or(r), buf); err != nil {
+		return nil, nil, err
+	}
+	sks := make([]ristretto.Scalar, n)
+	for i := range sks {
+		var wide [64]byte
+		copy(wide[:], buf[64*i:])
+		sks[i].SetReduced(&wide)
+	}
+	for i := range buf {
+		buf[i] = 0
+	}
+
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+
+	pks := make([]ristretto.Point, n)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < n; i += workers {
+				pks[i] = PublicKey(sks[i])
+			}
+		}(w)
+	}
+	wg.Wait()
+	return sks, pks, nil
+}
@@ -0,0 +1,33 @@
+package schnorr
+
+import (
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenKeyPairs(t *testing.T) {
+	sks, pks, err := GenKeyPairs(rng.Deterministic([]byte("keys")), 20)
+	require.Nil(t, err)
+	require.Equal(t, 20, len(pks))
+	for i := range pks {
+		pk := PublicKey(sks[i])
+		assert.True(t, pk.Equals(&pks[i]))
+	}
+
+	msg := []byte("message")
+	assert.True(t, Verify(pks[7], msg, Sign(sks[7], msg)))
+
+	// the keys do not depend on the number of workers
+	psks, ppks, err := GenKeyPairsParallel(rng.Deterministic([]byte("keys")), 20, 0)
+	require.Nil(t, err)
+	for i := range pks {
+		assert.True(t, psks[i].Equals(&sks[i]))
+		assert.True(t, ppks[i].Equals(&pks[i]))
+	}
+
+	_, _, err = GenKeyPairs(nil, 0)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,189 @@
+// Package musig2 implements the MuSig2 multi-signature protocol
+// (https://eprint.iacr.org/2020/1261) on top of the schnorr package.
+// n signers aggregate their public keys into a single key, exchange two
+// nonces each in a first round, and produce partial signatures in a second
+// round which combine into a plain schnorr.Signature for the aggregated key
+package musig2
+
+import (
+	"errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/schnorr"
+)
+
+// KeyAggContext holds the aggregated public key and the coefficient of
+// every key taking part in it
+type KeyAggContext struct {
+	Keys   []ristretto.Point
+	coeffs []ristretto.Scalar
+	// X is the aggregated public key, X = sum(a_i * P_i)
+	X ristretto.Point
+}
+
+// AggregateKeys aggregates the public keys of the signers. Every signer must
+// pass the keys in the same order
+func AggregateKeys(keys []ristretto.Point) (*KeyAggContext, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no keys to aggregate")
+	}
+
+	// L = H(P_1 || ... || P_n)
+	L := []byte("dusk.musig2.keys")
+	for i := range keys {
+		L = append(L, keys[i].Bytes()...)
+	}
+
+	ctx := &KeyAggContext{
+		Keys:   keys,
+		coeffs: make([]ristretto.Scalar, len(keys)),
+	}
+	ctx.X.SetZero()
+
+	for i := range keys {
+		// a_i = H(L || P_i)
+		buf := append(append([]byte("dusk.musig2.coeff"), L...), keys[i].Bytes()...)
+		ctx.coeffs[i].Derive(buf)
+
+		var aP ristretto.Point
+		aP.ScalarMult(&keys[i], &ctx.coeffs[i])
+		ctx.X.Add(&ctx.X, &aP)
+	}
+
+	return ctx, nil
+}
+
+// Coefficient returns the coefficient of the public key pk
+func (ctx *KeyAggContext) Coefficient(pk ristretto.Point) (ristretto.Scalar, error) {
+	for i := range ctx.Keys {
+		if ctx.Keys[i].Equals(&pk) {
+			return ctx.coeffs[i], nil
+		}
+	}
+	return ristretto.Scalar{}, errors.New("public key is not part of the aggregated key")
+}
+
+// SecretNonce is the secret part of the nonces of a signer. It must only be
+// used for a single signature
+type SecretNonce struct {
+	r1, r2 ristretto.Scalar
+	used   bool
+}
+
+// PublicNonce is sent to the other signers in the first round
+type PublicNonce struct {
+	R1, R2 ristretto.Point
+}
+
+// GenerateNonce returns fresh nonces for a signing session
+func GenerateNonce() (*SecretNonce, PublicNonce) {
+	sec := &SecretNonce{}
+	rng.Scalar(&sec.r1)
+	rng.Scalar(&sec.r2)
+
+	var pub PublicNonce
+	pub.R1.ScalarMultBase(&sec.r1)
+	pub.R2.ScalarMultBase(&sec.r2)
+	return sec, pub
+}
+
+// AggregateNonces sums the public nonces of all signers
+func AggregateNonces(nonces []PublicNonce) PublicNonce {
+	var agg PublicNonce
+	agg.R1.SetZero()
+	agg.R2.SetZero()
+	for i := range nonces {
+		agg.R1.Add(&agg.R1, &nonces[i].R1)
+		agg.R2.Add(&agg.R2, &nonces[i].R2)
+	}
+	return agg
+}
+
+// Session is the second round of the protocol, once the nonces are aggregated
+type Session struct {
+	ctx *KeyAggContext
+	msg []byte
+
+	// b weights the second nonces, R = R1 + b * R2
+	b ristretto.Scalar
+	R ristretto.Point
+	c ristretto.Scalar
+}
+
+// NewSession starts the signing of msg with the aggregated nonce
+func NewSession(ctx *KeyAggContext, aggNonce PublicNonce, msg []byte) *Session {
+	s := &Session{ctx: ctx, msg: msg}
+
+	// b = H(X || R1 || R2 || msg)
+	buf := []byte("dusk.musig2.nonce")
+	buf = append(buf, ctx.X.Bytes()...)
+	buf = append(buf, aggNonce.R1.Bytes()...)
+	buf = append(buf, aggNonce.R2.Bytes()...)
+	buf = append(buf, msg...)
+	s.b.Derive(buf)
+
+	s.R.ScalarMult(&aggNonce.R2, &s.b)
+	s.R.Add(&s.R, &aggNonce.R1)
+
+	s.c = schnorr.Challenge(s.R, ctx.X, msg)
+	return s
+}
+
+// Sign returns the partial signature s_i = r1 + b * r2 + c * a_i * x_i.
+// The secret nonce is erased, signing twice with it is an error
+func (s *Session) Sign(sk ristretto.Scalar, nonce *SecretNonce) (ristretto.Scalar, error) {
+	if nonce == nil || nonce.used {
+		return ristretto.Scalar{}, errors.New("secret nonce has already been used")
+	}
+
+	a, err := s.ctx.Coefficient(schnorr.PublicKey(sk))
+	if err != nil {
+		return ristretto.Scalar{}, err
+	}
+
+	var partial, cax ristretto.Scalar
+	cax.Mul(&s.c, &a)
+	cax.Mul(&cax, &sk)
+	partial.MulAdd(&s.b, &nonce.r2, &nonce.r1)
+	partial.Add(&partial, &cax)
+
+	nonce.r1.SetZero()
+	nonce.r2.SetZero()
+	nonce.used = true
+
+	return partial, nil
+}
+
+// VerifyPartial checks the partial signature of the signer with public key
+// pk and public nonce nonce, so that a misbehaving signer can be identified
+func (s *Session) VerifyPartial(pk ristretto.Point, nonce PublicNonce, partial ristretto.Scalar) bool {
+	a, err := s.ctx.Coefficient(pk)
+	if err != nil {
+		return false
+	}
+
+	// s_i * G == R1 + b * R2 + c * a_i * P_i
+	var lhs, rhs, tmp ristretto.Point
+	lhs.ScalarMultBase(&partial)
+
+	rhs.ScalarMult(&nonce.R2, &s.b)
+	rhs.Add(&rhs, &nonce.R1)
+	var ca ristretto.Scalar
+	ca.Mul(&s.c, &a)
+	tmp.ScalarMult(&pk, &ca)
+	rhs.Add(&rhs, &tmp)
+
+	return lhs.Equals(&rhs)
+}
+
+// Combine sums the partial signatures into a signature which verifies with
+// schnorr.Verify against the aggregated key
+func (s *Session) Combine(partials []ristretto.Scalar) schnorr.Signature {
+	sig := schnorr.Signature{R: s.R}
+	sig.S.SetZero()
+	for i := range partials {
+		sig.S.Add(&sig.S, &partials[i])
+	}
+	return sig
+}
@@ -0,0 +1,78 @@
+package musig2
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/schnorr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMuSig2(t *testing.T) {
+	for _, n := range []int{1, 2, 5} {
+		sks := make([]ristretto.Scalar, n)
+		pks := make([]ristretto.Point, n)
+		for i := range sks {
+			sks[i], pks[i] = schnorr.GenerateKey()
+		}
+
+		ctx, err := AggregateKeys(pks)
+		require.Nil(t, err)
+
+		// first round
+		secNonces := make([]*SecretNonce, n)
+		pubNonces := make([]PublicNonce, n)
+		for i := range secNonces {
+			secNonces[i], pubNonces[i] = GenerateNonce()
+		}
+
+		// second round
+		msg := []byte("spend from the shared wallet")
+		session := NewSession(ctx, AggregateNonces(pubNonces), msg)
+		partials := make([]ristretto.Scalar, n)
+		for i := range partials {
+			partials[i], err = session.Sign(sks[i], secNonces[i])
+			require.Nil(t, err)
+			assert.True(t, session.VerifyPartial(pks[i], pubNonces[i], partials[i]))
+		}
+
+		sig := session.Combine(partials)
+		assert.True(t, schnorr.Verify(ctx.X, msg, sig))
+		assert.False(t, schnorr.Verify(ctx.X, []byte("something else"), sig))
+	}
+}
+
+func TestMuSig2Misuse(t *testing.T) {
+	sk1, pk1 := schnorr.GenerateKey()
+	sk2, pk2 := schnorr.GenerateKey()
+	ctx, err := AggregateKeys([]ristretto.Point{pk1, pk2})
+	require.Nil(t, err)
+
+	sec1, pub1 := GenerateNonce()
+	sec2, pub2 := GenerateNonce()
+	session := NewSession(ctx, AggregateNonces([]PublicNonce{pub1, pub2}), []byte("msg"))
+
+	p1, err := session.Sign(sk1, sec1)
+	require.Nil(t, err)
+
+	// nonces cannot be reused
+	_, err = session.Sign(sk1, sec1)
+	assert.NotNil(t, err)
+
+	// a partial signature is bound to its signer
+	assert.False(t, session.VerifyPartial(pk2, pub2, p1))
+
+	// keys outside of the aggregated key cannot sign
+	sk3, _ := schnorr.GenerateKey()
+	sec3, _ := GenerateNonce()
+	_, err = session.Sign(sk3, sec3)
+	assert.NotNil(t, err)
+
+	p2, err := session.Sign(sk2, sec2)
+	require.Nil(t, err)
+	assert.True(t, schnorr.Verify(ctx.X, []byte("msg"), session.Combine([]ristretto.Scalar{p1, p2})))
+
+	_, err = AggregateKeys(nil)
+	assert.NotNil(t, err)
+}
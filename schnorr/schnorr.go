@@ -0,0 +1,107 @@
+// Package schnorr implements Schnorr signatures over Ristretto.
+// A signature (R, s) on msg is valid for the public key P = x * G iff
+// s * G = R + c * P, with c = H(R || P || msg)
+package schnorr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/nonce"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// Signature is a Schnorr signature
+type Signature struct {
+	R ristretto.Point
+	S ristretto.Scalar
+}
+
+// GenerateKey returns a random secret key and its public key
+func GenerateKey() (ristretto.Scalar, ristretto.Point) {
+	var sk ristretto.Scalar
+	rng.Scalar(&sk)
+	return sk, PublicKey(sk)
+}
+
+// PublicKey returns sk * G
+func PublicKey(sk ristretto.Scalar) ristretto.Point {
+	var pk ristretto.Point
+	pk.ScalarMultBase(&sk)
+	return pk
+}
+
+// Challenge returns c = H(R || P || msg). It is exported so that protocols
+// producing plain Schnorr signatures, such as multi-signatures, hash the same way
+func Challenge(R, P ristretto.Point, msg []byte) ristretto.Scalar {
+	t := transcript.New("dusk.schnorr")
+	t.AppendPoint("R", R)
+	t.AppendPoint("P", P)
+	t.Append("msg", msg)
+	return t.ChallengeScalar("c")
+}
+
+// Sign signs msg with the secret key sk. The nonce is derived from sk and
+// msg, see the nonce package
+func Sign(sk ristretto.Scalar, msg []byte) Signature {
+	pk := PublicKey(sk)
+	k := nonce.Scalar("dusk.schnorr", sk.Bytes(), pk.Bytes(), msg)
+
+	var sig Signature
+	sig.R.ScalarMultBase(&k)
+
+	c := Challenge(sig.R, pk, msg)
+
+	// s = k + c * sk
+	sig.S.MulAdd(&c, &sk, &k)
+	return sig
+}
+
+// Verify checks the signature of msg against the public key pk
+func Verify(pk ristretto.Point, msg []byte, sig Signature) bool {
+	c := Challenge(sig.R, pk, msg)
+
+	// s * G == R + c * P
+	var lhs, rhs ristretto.Point
+	lhs.ScalarMultBase(&sig.S)
+	rhs.ScalarMult(&pk, &c)
+	rhs.Add(&rhs, &sig.R)
+
+	return lhs.Equals(&rhs)
+}
+
+// Encode a Signature
+func (sig *Signature) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, sig.R.Bytes()); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, sig.S.Bytes())
+}
+
+// Decode a Signature
+func (sig *Signature) Decode(r io.Reader) error {
+	if sig == nil {
+		return errors.New("struct is nil")
+	}
+
+	var x [32]byte
+	if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+		return err
+	}
+	if !sig.R.SetBytes(&x) {
+		return errors.New("point not encodable")
+	}
+
+	if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+		return err
+	}
+	sig.S.SetBytes(&x)
+	if !bytes.Equal(sig.S.Bytes(), x[:]) {
+		return errors.New("scalar is not canonically encoded")
+	}
+	return nil
+}
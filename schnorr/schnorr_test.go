@@ -0,0 +1,73 @@
+package schnorr
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/nonce"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	sk, pk := GenerateKey()
+	msg := []byte("hello world")
+
+	sig := Sign(sk, msg)
+	assert.True(t, Verify(pk, msg, sig))
+	assert.False(t, Verify(pk, []byte("something else"), sig))
+
+	_, other := GenerateKey()
+	assert.False(t, Verify(other, msg, sig))
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, sig.Encode(buf))
+	var decoded Signature
+	require.Nil(t, decoded.Decode(buf))
+	assert.True(t, Verify(pk, msg, decoded))
+
+	// non canonical scalar
+	enc := &bytes.Buffer{}
+	require.Nil(t, sig.Encode(enc))
+	b := enc.Bytes()
+	for i := 32; i < 64; i++ {
+		b[i] = 0xff
+	}
+	assert.NotNil(t, decoded.Decode(bytes.NewReader(b)))
+}
+
+func TestDeterministicSource(t *testing.T) {
+	sign := func() ([]byte, []byte) {
+		defer rng.SetSource(rng.Deterministic([]byte("schnorr")))()
+		sk, pk := GenerateKey()
+		sig := Sign(sk, []byte("msg"))
+		buf := new(bytes.Buffer)
+		require.NoError(t, sig.Encode(buf))
+		return pk.Bytes(), buf.Bytes()
+	}
+
+	pk1, sig1 := sign()
+	pk2, sig2 := sign()
+	assert.Equal(t, pk1, pk2)
+	assert.Equal(t, sig1, sig2)
+}
+
+func TestDeterministicNonce(t *testing.T) {
+	sk, pk := GenerateKey()
+
+	sig1 := Sign(sk, []byte("msg"))
+	sig2 := Sign(sk, []byte("msg"))
+	assert.True(t, sig1.R.Equals(&sig2.R))
+	assert.True(t, sig1.S.Equals(&sig2.S))
+
+	other := Sign(sk, []byte("other msg"))
+	assert.False(t, sig1.R.Equals(&other.R))
+
+	// hedged nonces are fresh but the signature stays valid
+	restore := nonce.SetEntropy(rng.Reader)
+	hedged := Sign(sk, []byte("msg"))
+	restore()
+	assert.False(t, sig1.R.Equals(&hedged.R))
+	assert.True(t, Verify(pk, []byte("msg"), hedged))
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package securemem
+
+// alloc allocates the buffer on the heap, where it can be neither locked
+// nor guarded. This is the case of js/wasm, where the memory of the module
+// is a single JavaScript array anyway
+func alloc(size int) (*Buffer, error) {
+	region := make([]byte, size)
+	return &Buffer{region: region, inner: region}, nil
+}
+
+func (b *Buffer) free() error {
+	return nil
+}
@@ -0,0 +1,43 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package securemem
+
+import (
+	"os"
+	"syscall"
+)
+
+// alloc maps a region of guard page || inner pages || guard page, and
+// returns a buffer whose inner pages hold at least size bytes
+func alloc(size int) (*Buffer, error) {
+	page := os.Getpagesize()
+	inner := (size + page - 1) / page * page
+
+	region, err := syscall.Mmap(-1, 0, inner+2*page, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANON)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Mprotect(region[:page], syscall.PROT_NONE); err != nil {
+		syscall.Munmap(region)
+		return nil, err
+	}
+	if err := syscall.Mprotect(region[page+inner:], syscall.PROT_NONE); err != nil {
+		syscall.Munmap(region)
+		return nil, err
+	}
+
+	b := &Buffer{region: region, inner: region[page : page+inner : page+inner]}
+	b.locked = syscall.Mlock(b.inner) == nil
+	dontDump(b.inner)
+	return b, nil
+}
+
+func (b *Buffer) free() error {
+	if b.locked {
+		if err := syscall.Munlock(b.inner); err != nil {
+			return err
+		}
+	}
+	return syscall.Munmap(b.region)
+}
@@ -0,0 +1,11 @@
+package securemem
+
+import "syscall"
+
+// madvDontDump is MADV_DONTDUMP, which the syscall package does not define
+const madvDontDump = 0x10
+
+// dontDump excludes b from core dumps
+func dontDump(b []byte) {
+	_ = syscall.Madvise(b, madvDontDump)
+}
@@ -0,0 +1,157 @@
+// Package securemem allocates buffers for secret material outside of the Go
+// heap. Their pages are locked in memory, so that they are not written to
+// swap, excluded from core dumps where the platform allows it, surrounded
+// by inaccessible guard pages, and preceded by a canary which reveals
+// underflows when the buffer is destroyed.
+//
+// Locking is best effort: the amount of memory a process may lock is
+// usually limited, and a buffer which could not be locked is still
+// allocated, see Locked. On platforms without mmap, buffers are allocated
+// on the heap and only get the canary and the wiping
+package securemem
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"math/big"
+	"sync"
+	"unsafe"
+)
+
+const canarySize = 32
+
+var (
+	// ErrDestroyed is returned when using a destroyed buffer
+	ErrDestroyed = errors.New("securemem: buffer destroyed")
+	// ErrCanary is returned by Destroy when the canary of the buffer was
+	// overwritten
+	ErrCanary = errors.New("securemem: canary overwritten")
+
+	canary [canarySize]byte
+)
+
+func init() {
+	if _, err := rand.Read(canary[:]); err != nil {
+		panic(err)
+	}
+}
+
+// Buffer is a fixed size buffer for secret material. Buffers must be
+// released with Destroy, as they are not collected by the garbage collector
+type Buffer struct {
+	mu        sync.Mutex
+	region    []byte
+	inner     []byte
+	canary    []byte
+	data      []byte
+	locked    bool
+	destroyed bool
+}
+
+// New allocates a buffer of size bytes, filled with zeroes
+func New(size int) (*Buffer, error) {
+	if size < 1 {
+		return nil, errors.New("securemem: invalid size")
+	}
+
+	b, err := alloc(size + canarySize)
+	if err != nil {
+		return nil, err
+	}
+
+	// The data ends where the trailing guard page starts, so that overflows
+	// fault, and is preceded by the canary
+	end := len(b.inner)
+	b.data = b.inner[end-size : end : end]
+	b.canary = b.inner[end-size-canarySize : end-size]
+	copy(b.canary, canary[:])
+	return b, nil
+}
+
+// Bytes returns the content of the buffer. The slice must not be used once
+// the buffer is destroyed
+func (b *Buffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.destroyed {
+		return nil
+	}
+	return b.data
+}
+
+// Len returns the size of the buffer
+func (b *Buffer) Len() int {
+	return len(b.data)
+}
+
+// Locked returns whether the pages of the buffer are locked in memory
+func (b *Buffer) Locked() bool {
+	return b.locked
+}
+
+// Destroyed returns whether the buffer was destroyed
+func (b *Buffer) Destroyed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.destroyed
+}
+
+// Destroy wipes the buffer and releases its memory. It returns ErrCanary if
+// the memory before the buffer was overwritten, which points to a bug
+// corrupting the memory of the secret. Destroying a buffer twice is a no-op
+func (b *Buffer) Destroy() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.destroyed {
+		return nil
+	}
+
+	intact := subtle.ConstantTimeCompare(b.canary, canary[:]) == 1
+	Wipe(b.inner)
+	if err := b.free(); err != nil {
+		return err
+	}
+	b.destroyed = true
+	b.region, b.inner, b.data, b.canary = nil, nil, nil, nil
+	if !intact {
+		return ErrCanary
+	}
+	return nil
+}
+
+// Wipe overwrites b with zeroes
+func Wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// NewInt returns a copy of x whose words are stored in a buffer, and the
+// buffer. x is wiped. The integer must only be read: operations writing it
+// would move its words back to the heap. It becomes invalid once the
+// buffer is destroyed
+func NewInt(x *big.Int) (*big.Int, *Buffer, error) {
+	words := x.Bits()
+	if len(words) == 0 {
+		// Keep a buffer even for zero, so that callers own one in all cases
+		words = []big.Word{0}
+	}
+
+	var w big.Word
+	size := int(unsafe.Sizeof(w))
+	b, err := New(len(words) * size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	locked := (*[1 << 20]big.Word)(unsafe.Pointer(&b.data[0]))[:len(words):len(words)]
+	copy(locked, words)
+	for i := range words {
+		words[i] = 0
+	}
+	x.SetInt64(0)
+
+	res := new(big.Int).SetBits(locked)
+	return res, b, nil
+}
@@ -0,0 +1,56 @@
+package securemem
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuffer(t *testing.T) {
+	b, err := New(100)
+	require.NoError(t, err)
+	assert.Equal(t, 100, b.Len())
+	assert.Equal(t, make([]byte, 100), b.Bytes())
+
+	copy(b.Bytes(), []byte("secret"))
+	assert.Equal(t, []byte("secret"), b.Bytes()[:6])
+
+	require.NoError(t, b.Destroy())
+	assert.True(t, b.Destroyed())
+	assert.Nil(t, b.Bytes())
+	assert.NoError(t, b.Destroy())
+}
+
+func TestCanary(t *testing.T) {
+	b, err := New(32)
+	require.NoError(t, err)
+
+	// Simulate an underflow
+	b.canary[canarySize-1] ^= 1
+	assert.Equal(t, ErrCanary, b.Destroy())
+	assert.True(t, b.Destroyed())
+}
+
+func TestInvalidSize(t *testing.T) {
+	_, err := New(0)
+	assert.Error(t, err)
+}
+
+func TestNewInt(t *testing.T) {
+	x, ok := new(big.Int).SetString("123456789012345678901234567890123456789", 10)
+	require.True(t, ok)
+	want := new(big.Int).Set(x)
+
+	locked, b, err := NewInt(x)
+	require.NoError(t, err)
+	assert.Equal(t, 0, x.Sign())
+	assert.Equal(t, 0, want.Cmp(locked))
+
+	// Reading the integer leaves it in the buffer
+	sum := new(big.Int).Add(locked, big.NewInt(1))
+	assert.Equal(t, 1, sum.Cmp(want))
+
+	require.NoError(t, b.Destroy())
+}
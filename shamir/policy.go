@@ -0,0 +1,320 @@
+package shamir
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// MaxPolicyShares bounds the number of shares of a node of a Policy, the sum
+// of the weights of its members
+const MaxPolicyShares = 1 << 12
+
+// MaxPolicyNodes bounds the number of nodes of a Policy
+const MaxPolicyNodes = 1 << 12
+
+// Policy is a node of an access structure, satisfied when the weights of its
+// satisfied members add up to Threshold. Weighted thresholds give a party
+// several shares of the node, and nested policies express hierarchies, e.g.
+// "2 directors or 3 managers" is AnyOf(ThresholdOf(2, directors...),
+// ThresholdOf(3, managers...)).
+//
+// Every node is a Shamir sharing of degree Threshold-1 of the secret of the
+// node. Party members get Weight evaluations of it, and nested policies get
+// one evaluation, which is the secret they share in turn. The secret of the
+// root is a linear combination of the shares of any qualified set of parties,
+// so that, like with plain Shamir, it can be recombined in the exponent
+type Policy struct {
+	Threshold int
+	Members   []Member
+}
+
+// Member is a member of a Policy: either a party, counting for Weight
+// shares, or a nested policy, counting for one
+type Member struct {
+	Party  uint32
+	Weight int
+	Policy *Policy
+}
+
+// ThresholdOf returns the policy satisfied by any threshold of the parties
+func ThresholdOf(threshold int, parties ...uint32) *Policy {
+	weights := make([]int, len(parties))
+	for i := range weights {
+		weights[i] = 1
+	}
+	return WeightedOf(threshold, parties, weights)
+}
+
+// WeightedOf returns the policy satisfied by the sets of parties whose
+// weights add up to threshold
+func WeightedOf(threshold int, parties []uint32, weights []int) *Policy {
+	p := &Policy{Threshold: threshold, Members: make([]Member, len(parties))}
+	for i := range parties {
+		p.Members[i] = Member{Party: parties[i]}
+		if i < len(weights) {
+			p.Members[i].Weight = weights[i]
+		}
+	}
+	return p
+}
+
+// AnyOf returns the policy satisfied by any of the policies
+func AnyOf(policies ...*Policy) *Policy {
+	return nestedOf(1, policies)
+}
+
+// AllOf returns the policy satisfied by all of the policies
+func AllOf(policies ...*Policy) *Policy {
+	return nestedOf(len(policies), policies)
+}
+
+func nestedOf(threshold int, policies []*Policy) *Policy {
+	p := &Policy{Threshold: threshold, Members: make([]Member, len(policies))}
+	for i := range policies {
+		p.Members[i] = Member{Policy: policies[i]}
+	}
+	return p
+}
+
+// ShareID identifies a share of a policy: the evaluation at Index of the
+// sharing of node Node, nodes being numbered depth first from the root, 0
+type ShareID struct {
+	Node  uint32
+	Index uint32
+}
+
+// PolicyShare is a share of a secret shared with a Policy, held by Party
+type PolicyShare struct {
+	Party uint32
+	ID    ShareID
+	Value *big.Int
+}
+
+// Check returns an error if the policy cannot be satisfied or is malformed
+func (p *Policy) Check() error {
+	var nodes uint32
+	return p.check(&nodes)
+}
+
+func (p *Policy) check(nodes *uint32) error {
+	if *nodes == MaxPolicyNodes {
+		return errors.New("policy has too many nodes")
+	}
+	*nodes++
+	if len(p.Members) == 0 {
+		return errors.New("policy has no members")
+	}
+
+	total := 0
+	for _, m := range p.Members {
+		if m.Policy != nil {
+			if err := m.Policy.check(nodes); err != nil {
+				return err
+			}
+			total++
+			continue
+		}
+		if m.Weight < 1 {
+			return errors.New("party weight must be positive")
+		}
+		if m.Weight > MaxPolicyShares {
+			return errors.New("party weight is too large")
+		}
+		total += m.Weight
+	}
+	if total > MaxPolicyShares {
+		return errors.New("policy node has too many shares")
+	}
+	if p.Threshold < 1 || p.Threshold > total {
+		return errors.New("the threshold must be between 1 and the total weight of the members")
+	}
+	return nil
+}
+
+// Qualified returns true if the parties satisfy the policy
+func (p *Policy) Qualified(parties []uint32) bool {
+	set := make(map[uint32]bool, len(parties))
+	for _, party := range parties {
+		set[party] = true
+	}
+	return p.qualified(set)
+}
+
+func (p *Policy) qualified(set map[uint32]bool) bool {
+	weight := 0
+	for _, m := range p.Members {
+		if m.Policy != nil {
+			if m.Policy.qualified(set) {
+				weight++
+			}
+		} else if set[m.Party] {
+			weight += m.Weight
+		}
+	}
+	return weight >= p.Threshold
+}
+
+// SplitPolicy shares the secret according to the policy. Every party keeps
+// the shares whose Party is its own. Randomness is read from r, rng.Reader
+// is used if it is nil
+func (f *Field) SplitPolicy(secret *big.Int, p *Policy, r io.Reader) ([]PolicyShare, error) {
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+	if !f.contains(secret) {
+		return nil, errors.New("the secret is not a field element")
+	}
+
+	var shares []PolicyShare
+	var node uint32
+	if err := f.splitNode(secret, p, &node, &shares, r); err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+func (f *Field) splitNode(secret *big.Int, p *Policy, node *uint32, shares *[]PolicyShare, r io.Reader) error {
+	id := *node
+	*node++
+
+	coeffs, err := f.randomPolynomial(secret, p.Threshold, r)
+	if err != nil {
+		return err
+	}
+
+	index := uint32(1)
+	for _, m := range p.Members {
+		if m.Policy != nil {
+			if err := f.splitNode(f.eval(coeffs, index), m.Policy, node, shares, r); err != nil {
+				return err
+			}
+			index++
+			continue
+		}
+		for k := 0; k < m.Weight; k++ {
+			*shares = append(*shares, PolicyShare{
+				Party: m.Party,
+				ID:    ShareID{Node: id, Index: index},
+				Value: f.eval(coeffs, index),
+			})
+			index++
+		}
+	}
+	return nil
+}
+
+// PolicyCoefficients returns the coefficients recombining the secret from
+// the shares with the given ids: the secret is the sum of the values of the
+// shares times their coefficient. Shares that are not needed get a zero
+// coefficient. It fails if the shares do not satisfy the policy
+func (f *Field) PolicyCoefficients(p *Policy, ids []ShareID) ([]*big.Int, error) {
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+
+	available := make(map[ShareID]bool, len(ids))
+	for _, id := range ids {
+		if available[id] {
+			return nil, errors.New("duplicate share")
+		}
+		available[id] = true
+	}
+
+	var node uint32
+	coeffs, ok := f.nodeCoefficients(p, &node, available)
+	if !ok {
+		return nil, errors.New("the shares do not satisfy the policy")
+	}
+
+	res := make([]*big.Int, len(ids))
+	for i, id := range ids {
+		res[i] = new(big.Int)
+		if c, ok := coeffs[id]; ok {
+			res[i].Set(c)
+		}
+	}
+	return res, nil
+}
+
+// nodeCoefficients returns the coefficients of the shares recombining the
+// secret of the node p, if the available shares satisfy it. node is the
+// number of p, and is advanced past all of its descendants
+func (f *Field) nodeCoefficients(p *Policy, node *uint32, available map[ShareID]bool) (map[ShareID]*big.Int, bool) {
+	id := *node
+	*node++
+
+	// the evaluations of the node which can be recovered, by index
+	type evaluation struct {
+		index  uint32
+		coeffs map[ShareID]*big.Int
+	}
+	var evals []evaluation
+
+	index := uint32(1)
+	for _, m := range p.Members {
+		if m.Policy != nil {
+			// children are numbered even when they are not needed
+			if coeffs, ok := f.nodeCoefficients(m.Policy, node, available); ok {
+				evals = append(evals, evaluation{index, coeffs})
+			}
+			index++
+			continue
+		}
+		for k := 0; k < m.Weight; k++ {
+			sid := ShareID{Node: id, Index: index}
+			if available[sid] {
+				evals = append(evals, evaluation{index, map[ShareID]*big.Int{sid: big.NewInt(1)}})
+			}
+			index++
+		}
+	}
+	if len(evals) < p.Threshold {
+		return nil, false
+	}
+
+	// evals are in increasing order of index
+	evals = evals[:p.Threshold]
+	indices := make([]uint32, len(evals))
+	for i := range evals {
+		indices[i] = evals[i].index
+	}
+	lambdas, err := f.Lagrange(indices)
+	if err != nil {
+		return nil, false
+	}
+
+	res := make(map[ShareID]*big.Int)
+	for i := range evals {
+		for sid, c := range evals[i].coeffs {
+			v := new(big.Int).Mul(c, lambdas[i])
+			res[sid] = v.Mod(v, f.Modulus)
+		}
+	}
+	return res, true
+}
+
+// CombinePolicy recovers the secret from shares satisfying the policy. The
+// result is only the secret if the shares belong to the same sharing, which
+// CombinePolicy cannot check
+func (f *Field) CombinePolicy(p *Policy, shares []PolicyShare) (*big.Int, error) {
+	ids := make([]ShareID, len(shares))
+	for i := range shares {
+		if !f.contains(shares[i].Value) {
+			return nil, errors.New("share value is not a field element")
+		}
+		ids[i] = shares[i].ID
+	}
+
+	coeffs, err := f.PolicyCoefficients(p, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := new(big.Int)
+	for i := range shares {
+		term := new(big.Int).Mul(coeffs[i], shares[i].Value)
+		secret.Add(secret, term)
+	}
+	return secret.Mod(secret, f.Modulus), nil
+}
@@ -0,0 +1,100 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sharesOf returns the shares held by the parties
+func sharesOf(shares []PolicyShare, parties ...uint32) []PolicyShare {
+	var res []PolicyShare
+	for _, s := range shares {
+		for _, p := range parties {
+			if s.Party == p {
+				res = append(res, s)
+			}
+		}
+	}
+	return res
+}
+
+func TestHierarchicalPolicy(t *testing.T) {
+	// 2 directors (1, 2, 3) or 3 managers (10, 11, 12, 13)
+	policy := AnyOf(ThresholdOf(2, 1, 2, 3), ThresholdOf(3, 10, 11, 12, 13))
+	require.Nil(t, policy.Check())
+
+	secret, err := rand.Int(rand.Reader, BN256.Modulus)
+	require.Nil(t, err)
+	shares, err := BN256.SplitPolicy(secret, policy, nil)
+	require.Nil(t, err)
+	assert.Len(t, shares, 7)
+
+	for _, parties := range [][]uint32{{1, 3}, {10, 12, 13}, {2, 11, 12, 13}, {1, 2, 3, 10, 11, 12, 13}} {
+		assert.True(t, policy.Qualified(parties))
+		recovered, err := BN256.CombinePolicy(policy, sharesOf(shares, parties...))
+		require.Nil(t, err)
+		assert.Equal(t, 0, secret.Cmp(recovered))
+	}
+
+	for _, parties := range [][]uint32{{1}, {10, 11}, {1, 10, 11}} {
+		assert.False(t, policy.Qualified(parties))
+		_, err := BN256.CombinePolicy(policy, sharesOf(shares, parties...))
+		assert.NotNil(t, err)
+	}
+}
+
+func TestWeightedPolicy(t *testing.T) {
+	// weights 3, 2, 1, 1 and threshold 4
+	policy := WeightedOf(4, []uint32{1, 2, 3, 4}, []int{3, 2, 1, 1})
+
+	secret, err := rand.Int(rand.Reader, Ristretto.Modulus)
+	require.Nil(t, err)
+	shares, err := Ristretto.SplitPolicy(secret, policy, nil)
+	require.Nil(t, err)
+	assert.Len(t, sharesOf(shares, 1), 3)
+
+	for _, parties := range [][]uint32{{1, 3}, {2, 3, 4}, {1, 2}} {
+		recovered, err := Ristretto.CombinePolicy(policy, sharesOf(shares, parties...))
+		require.Nil(t, err)
+		assert.Equal(t, 0, secret.Cmp(recovered))
+	}
+
+	_, err = Ristretto.CombinePolicy(policy, sharesOf(shares, 2, 3))
+	assert.NotNil(t, err)
+	_, err = Ristretto.CombinePolicy(policy, sharesOf(shares, 1))
+	assert.NotNil(t, err)
+}
+
+func TestPolicyCoefficients(t *testing.T) {
+	policy := AllOf(ThresholdOf(1, 1, 2), ThresholdOf(2, 3, 4, 5))
+	shares, err := BN256.SplitPolicy(BN256.Modulus, policy, nil)
+	assert.NotNil(t, err)
+
+	shares, err = BN256.SplitPolicy(new(big.Int).SetUint64(42), policy, nil)
+	require.Nil(t, err)
+
+	// unneeded shares get a zero coefficient
+	picked := sharesOf(shares, 1, 2, 3, 4)
+	ids := make([]ShareID, len(picked))
+	for i := range picked {
+		ids[i] = picked[i].ID
+	}
+	coeffs, err := BN256.PolicyCoefficients(policy, ids)
+	require.Nil(t, err)
+	assert.Equal(t, 0, coeffs[1].Sign())
+
+	_, err = BN256.PolicyCoefficients(policy, append(ids, ids[0]))
+	assert.NotNil(t, err)
+}
+
+func TestPolicyCheck(t *testing.T) {
+	assert.NotNil(t, ThresholdOf(3, 1, 2).Check())
+	assert.NotNil(t, ThresholdOf(0, 1, 2).Check())
+	assert.NotNil(t, WeightedOf(1, []uint32{1, 2}, []int{1}).Check())
+	assert.NotNil(t, AnyOf().Check())
+	assert.Nil(t, AllOf(ThresholdOf(1, 1), ThresholdOf(1, 2)).Check())
+}
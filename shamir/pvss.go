@@ -0,0 +1,317 @@
+package shamir
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/sigma"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// Publicly verifiable secret sharing, after Schoenmakers. The shareholders
+// have key pairs y_i = x_i * G, and the dealer publishes, besides Feldman
+// commitments C_k = a_k * H to the sharing polynomial, every share encrypted
+// to its holder as Y_i = p(i) * y_i, with a DLEQ proof that
+// log_H(sum(i^k C_k)) = log_y_i(Y_i). Anyone checks the whole dealing without
+// interaction, so a dealer cannot hand out bad shares and blame the
+// shareholders, nor the other way around.
+//
+// The shared secret is the point s * G, for s = p(0). A shareholder decrypts
+// its share as S_i = x_i^-1 * Y_i = p(i) * G and proves it correct with a
+// DLEQ proof, and any threshold of decrypted shares recover s * G in the
+// exponent. The dealer may also reveal s, which anyone checks against C_0:
+// this is what makes randomness beacons and key escrow dealer accountable
+
+// PVSSDealing is the publishable part of a publicly verifiable sharing. The
+// share at index i is encrypted to the i-th key of the dealing
+type PVSSDealing struct {
+	Commitments []ristretto.Point
+	Shares      []ristretto.Point
+	Proofs      []sigma.Proof
+}
+
+// PVSSShare is a share decrypted by its holder, S = p(Index) * G, with a
+// proof that it matches the encrypted share
+type PVSSShare struct {
+	Index uint32
+	S     ristretto.Point
+	Proof sigma.Proof
+}
+
+// DealPVSS shares the secret among the holders of keys, threshold of which
+// are needed to recover secret * G. Randomness is read from r, rng.Reader
+// is used if it is nil
+func DealPVSS(secret *big.Int, threshold int, keys []ristretto.Point, r io.Reader) (*PVSSDealing, error) {
+	f := Ristretto
+	if threshold < 1 || threshold > len(keys) || threshold > MaxThreshold {
+		return nil, errors.New("the threshold must be between 1 and the number of shares")
+	}
+	if len(keys) > MaxThreshold {
+		return nil, errors.New("too many shares")
+	}
+	if !f.contains(secret) {
+		return nil, errors.New("the secret is not a field element")
+	}
+
+	coeffs, err := f.randomPolynomial(secret, threshold, r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &PVSSDealing{
+		Commitments: make([]ristretto.Point, threshold),
+		Shares:      make([]ristretto.Point, len(keys)),
+		Proofs:      make([]sigma.Proof, len(keys)),
+	}
+	for k := range coeffs {
+		var a ristretto.Scalar
+		a.SetBigInt(coeffs[k])
+		d.Commitments[k].ScalarMult(&vssGenerators.Value, &a)
+	}
+
+	t := d.transcript(keys)
+	for i := range keys {
+		idx := uint32(i + 1)
+		var v ristretto.Scalar
+		v.SetBigInt(f.eval(coeffs, idx))
+		d.Shares[i].ScalarMult(&keys[i], &v)
+
+		d.Proofs[i], err = sigma.Prove(shareProofTranscript(t, idx), d.shareStatement(keys[i], idx), []ristretto.Scalar{v})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// transcript binds the proofs of the dealing to its commitments and to the
+// keys of the shareholders
+func (d *PVSSDealing) transcript(keys []ristretto.Point) *transcript.Transcript {
+	t := transcript.New("dusk.pvss")
+	t.AppendUint64("threshold", uint64(len(d.Commitments)))
+	for i := range d.Commitments {
+		t.AppendPoint("C", d.Commitments[i])
+	}
+	t.AppendUint64("shares", uint64(len(keys)))
+	for i := range keys {
+		t.AppendPoint("y", keys[i])
+	}
+	return t
+}
+
+func shareProofTranscript(t *transcript.Transcript, index uint32) *transcript.Transcript {
+	t = t.Clone()
+	t.AppendUint64("index", uint64(index))
+	return t
+}
+
+// shareStatement states that the encrypted share at index has the same
+// discrete log with respect to the key of its holder as the committed share
+// with respect to H
+func (d *PVSSDealing) shareStatement(key ristretto.Point, index uint32) sigma.Statement {
+	dealing := Dealing{Commitments: d.Commitments}
+	return sigma.DLEQ(vssGenerators.Value, dealing.eval(index), key, d.Shares[index-1])
+}
+
+// Threshold returns the number of shares needed to recover the secret
+func (d *PVSSDealing) Threshold() int {
+	return len(d.Commitments)
+}
+
+// Verify checks that every encrypted share matches the commitments, and is
+// encrypted to its holder in keys
+func (d *PVSSDealing) Verify(keys []ristretto.Point) error {
+	if len(d.Commitments) == 0 || len(d.Commitments) > len(keys) {
+		return errors.New("invalid threshold")
+	}
+	if len(d.Shares) != len(keys) || len(d.Proofs) != len(keys) {
+		return errors.New("number of shares and keys do not match")
+	}
+
+	t := d.transcript(keys)
+	for i := range keys {
+		idx := uint32(i + 1)
+		if !sigma.Verify(shareProofTranscript(t, idx), d.shareStatement(keys[i], idx), d.Proofs[i]) {
+			return errors.New("encrypted share does not match the commitments")
+		}
+	}
+	return nil
+}
+
+func decryptionTranscript(index uint32) *transcript.Transcript {
+	t := transcript.New("dusk.pvss.decryption")
+	t.AppendUint64("index", uint64(index))
+	return t
+}
+
+// DecryptShare decrypts the share at index with the secret key of its
+// holder, and proves the decryption correct
+func (d *PVSSDealing) DecryptShare(index uint32, sk ristretto.Scalar) (PVSSShare, error) {
+	if index == 0 || int(index) > len(d.Shares) {
+		return PVSSShare{}, errors.New("no share with this index")
+	}
+	if sk.IsNonZeroI() == 0 {
+		return PVSSShare{}, errors.New("secret key is zero")
+	}
+
+	var G, y ristretto.Point
+	G.SetBase()
+	y.ScalarMult(&G, &sk)
+
+	// S = x^-1 * Y
+	var inv ristretto.Scalar
+	inv.Inverse(&sk)
+	s := PVSSShare{Index: index}
+	s.S.ScalarMult(&d.Shares[index-1], &inv)
+
+	// y = x * G and Y = x * S
+	proof, err := sigma.Prove(decryptionTranscript(index), sigma.DLEQ(G, y, s.S, d.Shares[index-1]), []ristretto.Scalar{sk})
+	if err != nil {
+		return PVSSShare{}, err
+	}
+	s.Proof = proof
+	return s, nil
+}
+
+// VerifyShare checks a decrypted share against the key of its holder
+func (d *PVSSDealing) VerifyShare(key ristretto.Point, s PVSSShare) error {
+	if s.Index == 0 || int(s.Index) > len(d.Shares) {
+		return errors.New("no share with this index")
+	}
+	var G ristretto.Point
+	G.SetBase()
+	if !sigma.Verify(decryptionTranscript(s.Index), sigma.DLEQ(G, key, s.S, d.Shares[s.Index-1]), s.Proof) {
+		return errors.New("invalid decrypted share")
+	}
+	return nil
+}
+
+// Recover checks the decrypted shares against the keys of the dealing, and
+// combines a threshold of them into secret * G
+func (d *PVSSDealing) Recover(keys []ristretto.Point, shares []PVSSShare) (ristretto.Point, error) {
+	if len(keys) != len(d.Shares) {
+		return ristretto.Point{}, errors.New("number of shares and keys do not match")
+	}
+	if len(shares) < d.Threshold() {
+		return ristretto.Point{}, errors.New("not enough decrypted shares")
+	}
+	shares = shares[:d.Threshold()]
+
+	indices := make([]uint32, len(shares))
+	for i := range shares {
+		if shares[i].Index == 0 || int(shares[i].Index) > len(keys) {
+			return ristretto.Point{}, errors.New("no share with this index")
+		}
+		if err := d.VerifyShare(keys[shares[i].Index-1], shares[i]); err != nil {
+			return ristretto.Point{}, err
+		}
+		indices[i] = shares[i].Index
+	}
+
+	coeffs, err := Ristretto.Lagrange(indices)
+	if err != nil {
+		return ristretto.Point{}, err
+	}
+
+	var res ristretto.Point
+	res.SetZero()
+	for i := range shares {
+		var c ristretto.Scalar
+		c.SetBigInt(coeffs[i])
+		var term ristretto.Point
+		term.ScalarMult(&shares[i].S, &c)
+		res.Add(&res, &term)
+	}
+	return res, nil
+}
+
+// VerifySecret checks a secret revealed by the dealer against the dealing
+func (d *PVSSDealing) VerifySecret(secret *big.Int) bool {
+	if len(d.Commitments) == 0 || !Ristretto.contains(secret) {
+		return false
+	}
+	var s ristretto.Scalar
+	s.SetBigInt(secret)
+	var C ristretto.Point
+	C.ScalarMult(&vssGenerators.Value, &s)
+	return C.Equals(&d.Commitments[0])
+}
+
+// Encode a PVSSDealing
+func (d *PVSSDealing) Encode(w io.Writer) error {
+	if len(d.Proofs) != len(d.Shares) {
+		return errors.New("number of shares and proofs do not match")
+	}
+	if err := writePointList(w, d.Commitments); err != nil {
+		return err
+	}
+	if err := writePointList(w, d.Shares); err != nil {
+		return err
+	}
+	for i := range d.Proofs {
+		if err := d.Proofs[i].Encode(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode a PVSSDealing
+func (d *PVSSDealing) Decode(r io.Reader) error {
+	if d == nil {
+		return errors.New("struct is nil")
+	}
+
+	commitments, err := readPointList(r)
+	if err != nil {
+		return err
+	}
+	shares, err := readPointList(r)
+	if err != nil {
+		return err
+	}
+	proofs := make([]sigma.Proof, len(shares))
+	for i := range proofs {
+		if err := proofs[i].Decode(r); err != nil {
+			return err
+		}
+	}
+
+	d.Commitments = commitments
+	d.Shares = shares
+	d.Proofs = proofs
+	return nil
+}
+
+func writePointList(w io.Writer, points []ristretto.Point) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(points))); err != nil {
+		return err
+	}
+	for i := range points {
+		if err := binary.Write(w, binary.BigEndian, points[i].Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readPointList(r io.Reader) ([]ristretto.Point, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+	if n == 0 || n > MaxThreshold {
+		return nil, errors.New("invalid number of points")
+	}
+
+	points := make([]ristretto.Point, n)
+	for i := range points {
+		if err := readerToPoint(r, &points[i]); err != nil {
+			return nil, err
+		}
+	}
+	return points, nil
+}
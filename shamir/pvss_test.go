@@ -0,0 +1,85 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pvssKeys(n int) ([]ristretto.Scalar, []ristretto.Point) {
+	sks := make([]ristretto.Scalar, n)
+	pks := make([]ristretto.Point, n)
+	for i := range sks {
+		sks[i].Rand()
+		pks[i].ScalarMultBase(&sks[i])
+	}
+	return sks, pks
+}
+
+func TestPVSS(t *testing.T) {
+	sks, pks := pvssKeys(5)
+	secret, err := rand.Int(rand.Reader, Ristretto.Modulus)
+	require.Nil(t, err)
+
+	d, err := DealPVSS(secret, 3, pks, nil)
+	require.Nil(t, err)
+	require.Nil(t, d.Verify(pks))
+	assert.True(t, d.VerifySecret(secret))
+
+	var expected ristretto.Point
+	var s ristretto.Scalar
+	s.SetBigInt(secret)
+	expected.ScalarMultBase(&s)
+
+	var shares []PVSSShare
+	for _, i := range []uint32{5, 2, 4} {
+		share, err := d.DecryptShare(i, sks[i-1])
+		require.Nil(t, err)
+		require.Nil(t, d.VerifyShare(pks[i-1], share))
+		shares = append(shares, share)
+	}
+	recovered, err := d.Recover(pks, shares)
+	require.Nil(t, err)
+	assert.True(t, expected.Equals(&recovered))
+
+	_, err = d.Recover(pks, shares[:2])
+	assert.NotNil(t, err)
+
+	// a share decrypted with the wrong key is rejected
+	bad, err := d.DecryptShare(1, sks[1])
+	require.Nil(t, err)
+	assert.NotNil(t, d.VerifyShare(pks[0], bad))
+	_, err = d.Recover(pks, append([]PVSSShare{bad}, shares...))
+	assert.NotNil(t, err)
+}
+
+func TestPVSSBadDealing(t *testing.T) {
+	_, pks := pvssKeys(4)
+	d, err := DealPVSS(big.NewInt(42), 2, pks, nil)
+	require.Nil(t, err)
+
+	// a share encrypted to the wrong holder
+	d.Shares[0], d.Shares[1] = d.Shares[1], d.Shares[0]
+	assert.NotNil(t, d.Verify(pks))
+	d.Shares[0], d.Shares[1] = d.Shares[1], d.Shares[0]
+	require.Nil(t, d.Verify(pks))
+
+	// the proofs are bound to the keys
+	_, other := pvssKeys(4)
+	assert.NotNil(t, d.Verify(other))
+	assert.False(t, d.VerifySecret(big.NewInt(43)))
+
+	buf := new(bytes.Buffer)
+	require.Nil(t, d.Encode(buf))
+	var dec PVSSDealing
+	require.Nil(t, dec.Decode(buf))
+	assert.Nil(t, dec.Verify(pks))
+
+	_, err = DealPVSS(big.NewInt(42), 5, pks, nil)
+	assert.NotNil(t, err)
+}
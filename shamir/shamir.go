@@ -0,0 +1,310 @@
+// Package shamir implements Shamir secret sharing over prime fields.
+// A secret is the constant term of a random polynomial of degree
+// threshold-1 and the shares are its evaluations at distinct non-zero points.
+// Any threshold shares recover the secret by Lagrange interpolation, fewer
+// reveal nothing about it
+package shamir
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// Field is a prime field the secrets and the shares live in
+type Field struct {
+	Modulus *big.Int
+}
+
+var (
+	// BN256 is the scalar field of the bn256 curve, used by the BLS keys
+	BN256 = NewField(bn256.Order)
+
+	// Ristretto is the scalar field of the Ristretto group,
+	// 2^252 + 27742317777372353535851937790883648493
+	Ristretto = NewField(ristrettoOrder())
+)
+
+func ristrettoOrder() *big.Int {
+	l, _ := new(big.Int).SetString("27742317777372353535851937790883648493", 10)
+	return l.Add(l, new(big.Int).Lsh(big.NewInt(1), 252))
+}
+
+// NewField returns the field of integers modulo the prime p
+func NewField(p *big.Int) *Field {
+	return &Field{Modulus: new(big.Int).Set(p)}
+}
+
+// Share is the evaluation of the sharing polynomial at Index
+type Share struct {
+	Index uint32
+	Value *big.Int
+}
+
+// Split shares the secret into n shares, threshold of which are needed to
+// recover it. Randomness is read from r, rng.Reader is used if it is nil
+func (f *Field) Split(secret *big.Int, threshold, n int, r io.Reader) ([]Share, error) {
+	if threshold < 1 || threshold > n {
+		return nil, errors.New("the threshold must be between 1 and the number of shares")
+	}
+	if int64(n) > int64(^uint32(0)) {
+		return nil, errors.New("too many shares")
+	}
+	if !f.contains(secret) {
+		return nil, errors.New("the secret is not a field element")
+	}
+
+	coeffs, err := f.randomPolynomial(secret, threshold, r)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i].Index = uint32(i + 1)
+		shares[i].Value = f.eval(coeffs, shares[i].Index)
+	}
+	return shares, nil
+}
+
+// Combine recovers the secret from the shares. The result is only the
+// secret if at least threshold shares of the same sharing are given, which
+// Combine cannot check
+func (f *Field) Combine(shares []Share) (*big.Int, error) {
+	if err := f.check(shares); err != nil {
+		return nil, err
+	}
+
+	secret := new(big.Int)
+	for i := range shares {
+		term := f.lagrange(shares, i)
+		term.Mul(term, shares[i].Value)
+		secret.Add(secret, term)
+	}
+	return secret.Mod(secret, f.Modulus), nil
+}
+
+// Lagrange returns the Lagrange coefficients at zero of the given indices.
+// They recombine secrets that are shared in the exponent, e.g. threshold
+// signatures, where Combine cannot be applied to the shares directly
+func (f *Field) Lagrange(indices []uint32) ([]*big.Int, error) {
+	shares := make([]Share, len(indices))
+	for i, idx := range indices {
+		shares[i] = Share{Index: idx, Value: new(big.Int)}
+	}
+	if err := f.check(shares); err != nil {
+		return nil, err
+	}
+
+	coeffs := make([]*big.Int, len(shares))
+	for i := range shares {
+		coeffs[i] = f.lagrange(shares, i)
+	}
+	return coeffs, nil
+}
+
+// Refresh re-randomizes the shares without changing the secret, so that
+// shares leaked before the refresh cannot be combined with shares leaked
+// after it. Every party can instead generate its own Updates and add the
+// ones it receives, so that no one learns the full update polynomial
+func (f *Field) Refresh(shares []Share, threshold int, r io.Reader) ([]Share, error) {
+	if err := f.check(shares); err != nil {
+		return nil, err
+	}
+
+	indices := make([]uint32, len(shares))
+	for i := range shares {
+		indices[i] = shares[i].Index
+	}
+
+	updates, err := f.Updates(indices, threshold, r)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Share, len(shares))
+	for i := range shares {
+		res[i], err = f.Add(shares[i], updates[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// Updates returns a sharing of zero for the given indices. Adding it to a
+// sharing of a secret refreshes the shares
+func (f *Field) Updates(indices []uint32, threshold int, r io.Reader) ([]Share, error) {
+	if threshold < 1 || threshold > len(indices) {
+		return nil, errors.New("the threshold must be between 1 and the number of shares")
+	}
+
+	coeffs, err := f.randomPolynomial(new(big.Int), threshold, r)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make([]Share, len(indices))
+	for i, idx := range indices {
+		if idx == 0 {
+			return nil, errors.New("share index is zero")
+		}
+		updates[i] = Share{Index: idx, Value: f.eval(coeffs, idx)}
+	}
+	return updates, nil
+}
+
+// Add returns the share of the sum of two secrets, given shares of both at
+// the same index
+func (f *Field) Add(a, b Share) (Share, error) {
+	if a.Index != b.Index {
+		return Share{}, errors.New("shares have different indices")
+	}
+	if !f.contains(a.Value) || !f.contains(b.Value) {
+		return Share{}, errors.New("share value is not a field element")
+	}
+
+	v := new(big.Int).Add(a.Value, b.Value)
+	return Share{Index: a.Index, Value: v.Mod(v, f.Modulus)}, nil
+}
+
+func (f *Field) contains(v *big.Int) bool {
+	return v != nil && v.Sign() >= 0 && v.Cmp(f.Modulus) < 0
+}
+
+// check verifies that the shares are field elements at distinct non-zero
+// indices
+func (f *Field) check(shares []Share) error {
+	if len(shares) == 0 {
+		return errors.New("no shares")
+	}
+
+	seen := make(map[uint32]struct{}, len(shares))
+	for _, s := range shares {
+		if s.Index == 0 {
+			return errors.New("share index is zero")
+		}
+		if _, ok := seen[s.Index]; ok {
+			return errors.New("duplicate share index")
+		}
+		seen[s.Index] = struct{}{}
+
+		if !f.contains(s.Value) {
+			return errors.New("share value is not a field element")
+		}
+	}
+	return nil
+}
+
+// randomPolynomial returns the coefficients of a random polynomial of
+// degree threshold-1 with the given constant term
+func (f *Field) randomPolynomial(constant *big.Int, threshold int, r io.Reader) ([]*big.Int, error) {
+	coeffs := make([]*big.Int, threshold)
+	coeffs[0] = new(big.Int).Set(constant)
+	for i := 1; i < threshold; i++ {
+		c, err := f.randomElement(r)
+		if err != nil {
+			return nil, err
+		}
+		coeffs[i] = c
+	}
+	return coeffs, nil
+}
+
+// randomElement returns a uniformly random field element
+func (f *Field) randomElement(r io.Reader) (*big.Int, error) {
+	if r == nil {
+		r = rng.Reader
+	}
+	return rand.Int(r, f.Modulus)
+}
+
+// eval evaluates the polynomial at x with Horner's method
+func (f *Field) eval(coeffs []*big.Int, x uint32) *big.Int {
+	bx := new(big.Int).SetUint64(uint64(x))
+	res := new(big.Int)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		res.Mul(res, bx)
+		res.Add(res, coeffs[i])
+		res.Mod(res, f.Modulus)
+	}
+	return res
+}
+
+// lagrange returns the Lagrange coefficient of the i-th share at zero:
+// prod(x_j / (x_j - x_i)) for j != i
+func (f *Field) lagrange(shares []Share, i int) *big.Int {
+	num := big.NewInt(1)
+	den := big.NewInt(1)
+	xi := new(big.Int).SetUint64(uint64(shares[i].Index))
+
+	for j := range shares {
+		if j == i {
+			continue
+		}
+		xj := new(big.Int).SetUint64(uint64(shares[j].Index))
+		num.Mul(num, xj)
+		num.Mod(num, f.Modulus)
+
+		diff := new(big.Int).Sub(xj, xi)
+		den.Mul(den, diff)
+		den.Mod(den, f.Modulus)
+	}
+
+	den.ModInverse(den, f.Modulus)
+	num.Mul(num, den)
+	return num.Mod(num, f.Modulus)
+}
+
+// Encode a Share
+func (s *Share) Encode(w io.Writer) error {
+	if s.Value == nil || s.Value.Sign() < 0 {
+		return errors.New("share has no value")
+	}
+
+	value := s.Value.Bytes()
+	if len(value) > 0xffff {
+		return errors.New("share value is too large")
+	}
+
+	if err := binary.Write(w, binary.BigEndian, s.Index); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint16(len(value))); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, value)
+}
+
+// Decode a Share
+func (s *Share) Decode(r io.Reader) error {
+	if s == nil {
+		return errors.New("struct is nil")
+	}
+
+	var index uint32
+	if err := binary.Read(r, binary.BigEndian, &index); err != nil {
+		return err
+	}
+	if index == 0 {
+		return errors.New("share index is zero")
+	}
+
+	var l uint16
+	if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+		return err
+	}
+	value := make([]byte, l)
+	if _, err := io.ReadFull(r, value); err != nil {
+		return err
+	}
+
+	s.Index = index
+	s.Value = new(big.Int).SetBytes(value)
+	return nil
+}
@@ -0,0 +1,169 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCombine(t *testing.T) {
+	for _, f := range []*Field{BN256, Ristretto} {
+		secret, err := rand.Int(rand.Reader, f.Modulus)
+		require.Nil(t, err)
+
+		shares, err := f.Split(secret, 3, 5, nil)
+		require.Nil(t, err)
+		require.Len(t, shares, 5)
+
+		// any 3 shares recover the secret
+		for _, subset := range [][]int{{0, 1, 2}, {0, 2, 4}, {4, 3, 1}, {0, 1, 2, 3, 4}} {
+			var picked []Share
+			for _, i := range subset {
+				picked = append(picked, shares[i])
+			}
+			recovered, err := f.Combine(picked)
+			require.Nil(t, err)
+			assert.Equal(t, 0, secret.Cmp(recovered))
+		}
+
+		// 2 shares do not
+		recovered, err := f.Combine(shares[:2])
+		require.Nil(t, err)
+		assert.NotEqual(t, 0, secret.Cmp(recovered))
+	}
+}
+
+func TestSplitInvalid(t *testing.T) {
+	f := Ristretto
+	_, err := f.Split(big.NewInt(1), 0, 3, nil)
+	assert.NotNil(t, err)
+	_, err = f.Split(big.NewInt(1), 4, 3, nil)
+	assert.NotNil(t, err)
+	_, err = f.Split(f.Modulus, 2, 3, nil)
+	assert.NotNil(t, err)
+	_, err = f.Split(big.NewInt(-1), 2, 3, nil)
+	assert.NotNil(t, err)
+}
+
+func TestCombineInvalid(t *testing.T) {
+	f := BN256
+	shares, err := f.Split(big.NewInt(42), 2, 3, nil)
+	require.Nil(t, err)
+
+	_, err = f.Combine(nil)
+	assert.NotNil(t, err)
+
+	_, err = f.Combine([]Share{shares[0], shares[0]})
+	assert.NotNil(t, err)
+
+	_, err = f.Combine([]Share{{Index: 0, Value: big.NewInt(1)}, shares[1]})
+	assert.NotNil(t, err)
+
+	_, err = f.Combine([]Share{{Index: 1, Value: f.Modulus}, shares[1]})
+	assert.NotNil(t, err)
+}
+
+func TestLagrange(t *testing.T) {
+	f := BN256
+	secret := big.NewInt(42)
+	shares, err := f.Split(secret, 3, 5, nil)
+	require.Nil(t, err)
+
+	picked := []Share{shares[4], shares[1], shares[2]}
+	coeffs, err := f.Lagrange([]uint32{picked[0].Index, picked[1].Index, picked[2].Index})
+	require.Nil(t, err)
+
+	sum := new(big.Int)
+	for i := range picked {
+		sum.Add(sum, new(big.Int).Mul(coeffs[i], picked[i].Value))
+	}
+	assert.Equal(t, 0, secret.Cmp(sum.Mod(sum, f.Modulus)))
+
+	_, err = f.Lagrange([]uint32{1, 1})
+	assert.NotNil(t, err)
+	_, err = f.Lagrange([]uint32{0, 1})
+	assert.NotNil(t, err)
+}
+
+func TestRefresh(t *testing.T) {
+	f := Ristretto
+	secret := big.NewInt(1234567)
+
+	shares, err := f.Split(secret, 3, 5, nil)
+	require.Nil(t, err)
+
+	refreshed, err := f.Refresh(shares, 3, nil)
+	require.Nil(t, err)
+
+	for i := range shares {
+		assert.Equal(t, shares[i].Index, refreshed[i].Index)
+		assert.NotEqual(t, 0, shares[i].Value.Cmp(refreshed[i].Value))
+	}
+
+	recovered, err := f.Combine(refreshed[1:4])
+	require.Nil(t, err)
+	assert.Equal(t, 0, secret.Cmp(recovered))
+
+	// old and new shares do not mix
+	mixed := []Share{shares[0], refreshed[1], refreshed[2]}
+	recovered, err = f.Combine(mixed)
+	require.Nil(t, err)
+	assert.NotEqual(t, 0, secret.Cmp(recovered))
+}
+
+func TestDistributedRefresh(t *testing.T) {
+	f := BN256
+	secret := big.NewInt(99)
+
+	shares, err := f.Split(secret, 2, 3, nil)
+	require.Nil(t, err)
+	indices := []uint32{1, 2, 3}
+
+	// every party deals a sharing of zero and adds what it receives
+	refreshed := append([]Share{}, shares...)
+	for party := 0; party < 3; party++ {
+		updates, err := f.Updates(indices, 2, nil)
+		require.Nil(t, err)
+		for i := range refreshed {
+			refreshed[i], err = f.Add(refreshed[i], updates[i])
+			require.Nil(t, err)
+		}
+	}
+
+	recovered, err := f.Combine(refreshed[1:])
+	require.Nil(t, err)
+	assert.Equal(t, 0, secret.Cmp(recovered))
+
+	_, err = f.Add(shares[0], shares[1])
+	assert.NotNil(t, err)
+}
+
+func TestShareEncodeDecode(t *testing.T) {
+	shares, err := BN256.Split(big.NewInt(7), 2, 3, nil)
+	require.Nil(t, err)
+
+	for _, s := range shares {
+		buf := &bytes.Buffer{}
+		require.Nil(t, s.Encode(buf))
+
+		var decoded Share
+		require.Nil(t, decoded.Decode(buf))
+		assert.Equal(t, s.Index, decoded.Index)
+		assert.Equal(t, 0, s.Value.Cmp(decoded.Value))
+	}
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, shares[0].Encode(buf))
+	encoded := buf.Bytes()
+
+	var decoded Share
+	assert.NotNil(t, decoded.Decode(bytes.NewReader(encoded[:len(encoded)-1])))
+
+	zeroIndex := append([]byte{}, encoded...)
+	zeroIndex[0], zeroIndex[1], zeroIndex[2], zeroIndex[3] = 0, 0, 0, 0
+	assert.NotNil(t, decoded.Decode(bytes.NewReader(zeroIndex)))
+}
@@ -0,0 +1,305 @@
+package shamir
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
+)
+
+// Verifiable secret sharing over the Ristretto scalar field. The dealer
+// publishes commitments to the coefficients of the sharing polynomial, which
+// every shareholder checks its share against:
+//
+//   Feldman:  C_k = a_k * G             s_i * G = sum(i^k C_k)
+//   Pedersen: C_k = a_k * G + b_k * H   s_i * G + t_i * H = sum(i^k C_k)
+//
+// Feldman commitments reveal secret * G, Pedersen commitments hide the secret
+// unconditionally
+
+// MaxThreshold bounds the number of commitments accepted when decoding a
+// Dealing
+const MaxThreshold = 1 << 16
+
+var vssGenerators = pedersen.New([]byte("dusk.vss")).Generators()
+
+// Dealing is the public part of a verifiable sharing
+type Dealing struct {
+	// Hiding is true for Pedersen commitments and false for Feldman ones
+	Hiding      bool
+	Commitments []ristretto.Point
+}
+
+// VerifiableShare is a share together with the share of the blinding
+// polynomial. Blind is nil for Feldman sharings
+type VerifiableShare struct {
+	Share
+	Blind *big.Int
+}
+
+// DealFeldman shares the secret like Split and returns the Feldman
+// commitments to the sharing polynomial
+func DealFeldman(secret *big.Int, threshold, n int, r io.Reader) (Dealing, []VerifiableShare, error) {
+	return deal(secret, threshold, n, false, r)
+}
+
+// DealPedersen shares the secret like Split and returns the Pedersen
+// commitments to the sharing polynomial
+func DealPedersen(secret *big.Int, threshold, n int, r io.Reader) (Dealing, []VerifiableShare, error) {
+	return deal(secret, threshold, n, true, r)
+}
+
+func deal(secret *big.Int, threshold, n int, hiding bool, r io.Reader) (Dealing, []VerifiableShare, error) {
+	f := Ristretto
+	if threshold < 1 || threshold > n || threshold > MaxThreshold {
+		return Dealing{}, nil, errors.New("the threshold must be between 1 and the number of shares")
+	}
+	if !f.contains(secret) {
+		return Dealing{}, nil, errors.New("the secret is not a field element")
+	}
+
+	coeffs, err := f.randomPolynomial(secret, threshold, r)
+	if err != nil {
+		return Dealing{}, nil, err
+	}
+
+	var blinds []*big.Int
+	if hiding {
+		blind, err := f.randomElement(r)
+		if err != nil {
+			return Dealing{}, nil, err
+		}
+		blinds, err = f.randomPolynomial(blind, threshold, r)
+		if err != nil {
+			return Dealing{}, nil, err
+		}
+	}
+
+	d := Dealing{Hiding: hiding, Commitments: make([]ristretto.Point, threshold)}
+	for k := range coeffs {
+		var a ristretto.Scalar
+		a.SetBigInt(coeffs[k])
+		if !hiding {
+			d.Commitments[k].ScalarMult(&vssGenerators.Value, &a)
+			continue
+		}
+
+		var b ristretto.Scalar
+		b.SetBigInt(blinds[k])
+		d.Commitments[k] = vssGenerators.Commit(a, b)
+	}
+
+	shares := make([]VerifiableShare, n)
+	for i := range shares {
+		idx := uint32(i + 1)
+		shares[i].Share = Share{Index: idx, Value: f.eval(coeffs, idx)}
+		if hiding {
+			shares[i].Blind = f.eval(blinds, idx)
+		}
+	}
+
+	return d, shares, nil
+}
+
+// Verify checks a share against the commitments of the dealer
+func (d *Dealing) Verify(s VerifiableShare) error {
+	if len(d.Commitments) == 0 {
+		return errors.New("dealing has no commitments")
+	}
+	if s.Index == 0 {
+		return errors.New("share index is zero")
+	}
+	if !Ristretto.contains(s.Value) {
+		return errors.New("share value is not a field element")
+	}
+	if d.Hiding != (s.Blind != nil) {
+		return errors.New("share does not match the commitment scheme")
+	}
+
+	var v ristretto.Scalar
+	v.SetBigInt(s.Value)
+
+	var lhs ristretto.Point
+	if d.Hiding {
+		if !Ristretto.contains(s.Blind) {
+			return errors.New("share blind is not a field element")
+		}
+		var b ristretto.Scalar
+		b.SetBigInt(s.Blind)
+		lhs = vssGenerators.Commit(v, b)
+	} else {
+		lhs.ScalarMult(&vssGenerators.Value, &v)
+	}
+
+	rhs := d.eval(s.Index)
+	if !lhs.Equals(&rhs) {
+		return errors.New("share does not match the commitments")
+	}
+	return nil
+}
+
+// Threshold returns the number of shares needed to recover the secret
+func (d *Dealing) Threshold() int {
+	return len(d.Commitments)
+}
+
+// eval evaluates the committed polynomial at index with Horner's method
+func (d *Dealing) eval(index uint32) ristretto.Point {
+	var x ristretto.Scalar
+	x.SetBigInt(new(big.Int).SetUint64(uint64(index)))
+	var res ristretto.Point
+	res.SetZero()
+	for k := len(d.Commitments) - 1; k >= 0; k-- {
+		res.ScalarMult(&res, &x)
+		res.Add(&res, &d.Commitments[k])
+	}
+	return res
+}
+
+// FeldmanBase returns the base Feldman commitments are computed over
+func FeldmanBase() ristretto.Point {
+	return vssGenerators.Value
+}
+
+// PublicKey returns secret * FeldmanBase for a Feldman dealing
+func (d *Dealing) PublicKey() (ristretto.Point, error) {
+	if d.Hiding || len(d.Commitments) == 0 {
+		return ristretto.Point{}, errors.New("dealing does not reveal public keys")
+	}
+	return d.Commitments[0], nil
+}
+
+// PublicShare returns s_i * FeldmanBase, the public counterpart of the share
+// at index, for a Feldman dealing
+func (d *Dealing) PublicShare(index uint32) (ristretto.Point, error) {
+	if d.Hiding || len(d.Commitments) == 0 {
+		return ristretto.Point{}, errors.New("dealing does not reveal public keys")
+	}
+	if index == 0 {
+		return ristretto.Point{}, errors.New("share index is zero")
+	}
+	return d.eval(index), nil
+}
+
+// Complaint is raised by the shareholder at Index against the dealer at
+// Dealer, when its share does not verify or was never received
+type Complaint struct {
+	Dealer int
+	Index  uint32
+}
+
+// Qualified returns the indices of the dealers that are not disqualified.
+// Every complaint must be answered by the dealer revealing the share of the
+// complaining shareholder, responses[dealer]. A dealer is disqualified when a
+// complaint against it is left unanswered or answered with an invalid share,
+// so that the complaining shareholder can take the revealed share instead.
+// A dealer is also disqualified when its dealing does not commit to a
+// polynomial of the expected threshold, since a higher degree would leave the
+// secret unrecoverable by threshold shareholders
+func Qualified(dealings []Dealing, threshold int, complaints []Complaint, responses map[int][]VerifiableShare) []int {
+	disqualified := make(map[int]bool)
+
+	for i := range dealings {
+		if dealings[i].Threshold() != threshold {
+			disqualified[i] = true
+		}
+	}
+
+	for _, c := range complaints {
+		if c.Dealer < 0 || c.Dealer >= len(dealings) || disqualified[c.Dealer] {
+			continue
+		}
+
+		answered := false
+		for _, s := range responses[c.Dealer] {
+			if s.Index != c.Index {
+				continue
+			}
+			answered = dealings[c.Dealer].Verify(s) == nil
+			break
+		}
+
+		if !answered {
+			disqualified[c.Dealer] = true
+		}
+	}
+
+	var qualified []int
+	for i := range dealings {
+		if !disqualified[i] {
+			qualified = append(qualified, i)
+		}
+	}
+	return qualified
+}
+
+// Encode a Dealing
+func (d *Dealing) Encode(w io.Writer) error {
+	var hiding uint8
+	if d.Hiding {
+		hiding = 1
+	}
+	if err := binary.Write(w, binary.BigEndian, hiding); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(d.Commitments))); err != nil {
+		return err
+	}
+	for i := range d.Commitments {
+		if err := binary.Write(w, binary.BigEndian, d.Commitments[i].Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode a Dealing
+func (d *Dealing) Decode(r io.Reader) error {
+	if d == nil {
+		return errors.New("struct is nil")
+	}
+
+	var hiding uint8
+	if err := binary.Read(r, binary.BigEndian, &hiding); err != nil {
+		return err
+	}
+	if hiding > 1 {
+		return errors.New("unknown commitment scheme")
+	}
+
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return err
+	}
+	if n == 0 || n > MaxThreshold {
+		return errors.New("invalid number of commitments")
+	}
+
+	commitments := make([]ristretto.Point, n)
+	for i := range commitments {
+		if err := readerToPoint(r, &commitments[i]); err != nil {
+			return err
+		}
+	}
+
+	d.Hiding = hiding == 1
+	d.Commitments = commitments
+	return nil
+}
+
+func readerToPoint(r io.Reader, p *ristretto.Point) error {
+	var x [32]byte
+	err := binary.Read(r, binary.BigEndian, &x)
+	if err != nil {
+		return err
+	}
+	ok := p.SetBytes(&x)
+	if !ok {
+		return errors.New("point not encodable")
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package shamir
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVSS(t *testing.T) {
+	for _, deal := range []func(*big.Int, int, int, io.Reader) (Dealing, []VerifiableShare, error){DealFeldman, DealPedersen} {
+		secret := big.NewInt(31337)
+		d, shares, err := deal(secret, 3, 5, nil)
+		require.Nil(t, err)
+		assert.Equal(t, 3, d.Threshold())
+
+		for _, s := range shares {
+			assert.Nil(t, d.Verify(s))
+		}
+
+		// a tampered share is detected
+		bad := shares[2]
+		bad.Value = new(big.Int).Add(bad.Value, big.NewInt(1))
+		assert.NotNil(t, d.Verify(bad))
+
+		// a share moved to another index is detected
+		moved := shares[2]
+		moved.Index = 4
+		assert.NotNil(t, d.Verify(moved))
+
+		plain := make([]Share, 3)
+		for i := range plain {
+			plain[i] = shares[i+2].Share
+		}
+		recovered, err := Ristretto.Combine(plain)
+		require.Nil(t, err)
+		assert.Equal(t, 0, secret.Cmp(recovered))
+	}
+}
+
+func TestVSSSchemeMismatch(t *testing.T) {
+	feldman, fShares, err := DealFeldman(big.NewInt(1), 2, 3, nil)
+	require.Nil(t, err)
+	pedersen, pShares, err := DealPedersen(big.NewInt(1), 2, 3, nil)
+	require.Nil(t, err)
+
+	assert.NotNil(t, feldman.Verify(pShares[0]))
+	assert.NotNil(t, pedersen.Verify(fShares[0]))
+
+	// the blind is bound by the commitments as well
+	bad := pShares[0]
+	bad.Blind = new(big.Int).Add(bad.Blind, big.NewInt(1))
+	assert.NotNil(t, pedersen.Verify(bad))
+}
+
+func TestVSSPublicShares(t *testing.T) {
+	secret := big.NewInt(42)
+	d, shares, err := DealFeldman(secret, 2, 3, nil)
+	require.Nil(t, err)
+
+	base := FeldmanBase()
+	pk, err := d.PublicKey()
+	require.Nil(t, err)
+	var x ristretto.Scalar
+	x.SetBigInt(secret)
+	var expected ristretto.Point
+	expected.ScalarMult(&base, &x)
+	assert.True(t, expected.Equals(&pk))
+
+	for _, s := range shares {
+		pub, err := d.PublicShare(s.Index)
+		require.Nil(t, err)
+		x.SetBigInt(s.Value)
+		expected.ScalarMult(&base, &x)
+		assert.True(t, expected.Equals(&pub))
+	}
+	_, err = d.PublicShare(0)
+	assert.NotNil(t, err)
+
+	// Pedersen commitments hide the public keys
+	pedersen, _, err := DealPedersen(secret, 2, 3, nil)
+	require.Nil(t, err)
+	_, err = pedersen.PublicKey()
+	assert.NotNil(t, err)
+	_, err = pedersen.PublicShare(1)
+	assert.NotNil(t, err)
+}
+
+func TestQualified(t *testing.T) {
+	var dealings []Dealing
+	var shares [][]VerifiableShare
+	for i := 0; i < 3; i++ {
+		d, s, err := DealPedersen(big.NewInt(int64(i)), 2, 3, nil)
+		require.Nil(t, err)
+		dealings = append(dealings, d)
+		shares = append(shares, s)
+	}
+
+	// dealer 0 answers its complaint with the right share, dealer 1 with a
+	// wrong one and dealer 2 does not answer
+	wrong := shares[1][0]
+	wrong.Value = new(big.Int).Add(wrong.Value, big.NewInt(1))
+
+	complaints := []Complaint{
+		{Dealer: 0, Index: 2},
+		{Dealer: 1, Index: 1},
+		{Dealer: 2, Index: 3},
+	}
+	responses := map[int][]VerifiableShare{
+		0: {shares[0][1]},
+		1: {wrong},
+	}
+
+	assert.Equal(t, []int{0}, Qualified(dealings, 2, complaints, responses))
+	assert.Equal(t, []int{0, 1, 2}, Qualified(dealings, 2, nil, nil))
+
+	// a dealing of the wrong threshold is disqualified even without
+	// complaints
+	d, _, err := DealPedersen(big.NewInt(3), 3, 3, nil)
+	require.Nil(t, err)
+	dealings = append(dealings, d)
+	assert.Equal(t, []int{0, 1, 2}, Qualified(dealings, 2, nil, nil))
+	assert.Equal(t, []int{3}, Qualified(dealings, 3, nil, nil))
+}
+
+func TestDealingEncodeDecode(t *testing.T) {
+	for _, hiding := range []bool{false, true} {
+		d, shares, err := deal(big.NewInt(5), 4, 6, hiding, nil)
+		require.Nil(t, err)
+
+		buf := &bytes.Buffer{}
+		require.Nil(t, d.Encode(buf))
+		encoded := append([]byte{}, buf.Bytes()...)
+
+		var decoded Dealing
+		require.Nil(t, decoded.Decode(buf))
+		assert.Equal(t, d.Hiding, decoded.Hiding)
+		require.Len(t, decoded.Commitments, 4)
+		for i := range d.Commitments {
+			assert.True(t, d.Commitments[i].Equals(&decoded.Commitments[i]))
+		}
+		assert.Nil(t, decoded.Verify(shares[5]))
+
+		assert.NotNil(t, decoded.Decode(bytes.NewReader(encoded[:len(encoded)-1])))
+
+		huge := append([]byte{}, encoded...)
+		huge[1] = 0xff
+		assert.NotNil(t, decoded.Decode(bytes.NewReader(huge)))
+	}
+}
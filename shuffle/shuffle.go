@@ -0,0 +1,219 @@
+// Package shuffle assigns validators to committees with a swap-or-not
+// shuffle seeded by a beacon or VRF output. The permutation is a function of
+// the seed only, so that every node derives the same committees, and the
+// position of a single validator takes Rounds hashes to compute, so that a
+// light client checks the assignment of a validator without shuffling the
+// whole list.
+//
+// In every round, a pivot is drawn from the seed and every index i is paired
+// with pivot - i. A bit of the seed keyed by the larger of the two decides
+// whether they swap, which makes every round an involution, and the shuffle
+// easy to invert by running the rounds backwards
+package shuffle
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/dusk-network/dusk-crypto/hash"
+)
+
+// Rounds is the number of swap-or-not rounds
+const Rounds = 90
+
+// MaxCount bounds the number of validators of a shuffle
+const MaxCount = 1 << 24
+
+// Seed drives a shuffle
+type Seed [32]byte
+
+// NewSeed derives the seed of an epoch from randomness, e.g. a beacon value
+// or a VRF output. role separates the committees drawn from the same
+// randomness
+func NewSeed(randomness []byte, epoch uint64, role []byte) Seed {
+	var e [8]byte
+	binary.BigEndian.PutUint64(e[:], epoch)
+
+	var s Seed
+	copy(s[:], hash.Sha3256WithDomain("dusk.shuffle.seed", randomness, e[:], role))
+	return s
+}
+
+// pivot returns the pivot of round r
+func (s Seed) pivot(r uint8, count uint64) uint64 {
+	h := hash.Sha3256WithDomain("dusk.shuffle.pivot", s[:], []byte{r})
+	return binary.BigEndian.Uint64(h[:8]) % count
+}
+
+// source returns the 256 swap bits of round r for the indices in
+// [256 * chunk, 256 * (chunk + 1))
+func (s Seed) source(r uint8, chunk uint64) []byte {
+	var c [8]byte
+	binary.BigEndian.PutUint64(c[:], chunk)
+	return hash.Sha3256WithDomain("dusk.shuffle.source", s[:], []byte{r}, c[:])
+}
+
+func bit(source []byte, i uint64) bool {
+	return (source[(i%256)/8]>>(i%8))&1 == 1
+}
+
+func checkCount(index, count uint64) error {
+	if count == 0 {
+		return errors.New("shuffle: no validators")
+	}
+	if count > MaxCount {
+		return errors.New("shuffle: too many validators")
+	}
+	if index >= count {
+		return errors.New("shuffle: index out of range")
+	}
+	return nil
+}
+
+// step applies round r to index
+func (s Seed) step(r uint8, index, count uint64) uint64 {
+	flip := (s.pivot(r, count) + count - index) % count
+	max := index
+	if flip > max {
+		max = flip
+	}
+	if bit(s.source(r, max/256), max) {
+		return flip
+	}
+	return index
+}
+
+// Position returns the position of the validator index in the shuffled
+// list of count validators
+func Position(seed Seed, index, count uint64) (uint64, error) {
+	if err := checkCount(index, count); err != nil {
+		return 0, err
+	}
+	for r := 0; r < Rounds; r++ {
+		index = seed.step(uint8(r), index, count)
+	}
+	return index, nil
+}
+
+// Index returns the validator at position in the shuffled list of count
+// validators. It inverts Position
+func Index(seed Seed, position, count uint64) (uint64, error) {
+	if err := checkCount(position, count); err != nil {
+		return 0, err
+	}
+	for r := Rounds - 1; r >= 0; r-- {
+		position = seed.step(uint8(r), position, count)
+	}
+	return position, nil
+}
+
+// Shuffle returns the shuffled list of count validators: the validator at
+// position p is Shuffle(seed, count)[p]. It hashes every swap bit once per
+// round, instead of once per validator and round
+func Shuffle(seed Seed, count uint64) ([]uint64, error) {
+	if err := checkCount(0, count); err != nil {
+		return nil, err
+	}
+
+	list := make([]uint64, count)
+	for i := range list {
+		list[i] = uint64(i)
+	}
+
+	sources := make([][]byte, (count+255)/256)
+	for r := 0; r < Rounds; r++ {
+		for c := range sources {
+			sources[c] = seed.source(uint8(r), uint64(c))
+		}
+		pivot := seed.pivot(uint8(r), count)
+		// every pair {i, flip} is visited once, from its smaller index
+		for i := uint64(0); i < count; i++ {
+			flip := (pivot + count - i) % count
+			if flip > i && bit(sources[flip/256], flip) {
+				list[i], list[flip] = list[flip], list[i]
+			}
+		}
+	}
+	return list, nil
+}
+
+// committeeBounds returns the positions [start, end) of committee k out
+// of n in a list of count validators
+func committeeBounds(k, n, count uint64) (uint64, uint64) {
+	return count * k / n, count * (k + 1) / n
+}
+
+// Committees splits the shuffled list of count validators into n
+// committees of sizes differing by at most one
+func Committees(seed Seed, count, n uint64) ([][]uint64, error) {
+	if n == 0 || n > count {
+		return nil, errors.New("shuffle: invalid number of committees")
+	}
+	list, err := Shuffle(seed, count)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([][]uint64, n)
+	for k := range res {
+		start, end := committeeBounds(uint64(k), n, count)
+		res[k] = list[start:end]
+	}
+	return res, nil
+}
+
+// Assignment is the place of a validator in the shuffle: its position in
+// the shuffled list, and the committee and the seat in the committee this
+// position falls in. Anyone holding the seed verifies it in Rounds steps
+type Assignment struct {
+	Index      uint64
+	Position   uint64
+	Committee  uint64
+	Seat       uint64
+	Count      uint64
+	Committees uint64
+}
+
+// Assign returns the assignment of the validator index, out of count
+// validators split into n committees
+func Assign(seed Seed, index, count, n uint64) (Assignment, error) {
+	if n == 0 || n > count {
+		return Assignment{}, errors.New("shuffle: invalid number of committees")
+	}
+	pos, err := Position(seed, index, count)
+	if err != nil {
+		return Assignment{}, err
+	}
+
+	// the committee k holds the positions [count*k/n, count*(k+1)/n)
+	k := ((pos+1)*n - 1) / count
+	start, _ := committeeBounds(k, n, count)
+	return Assignment{
+		Index:      index,
+		Position:   pos,
+		Committee:  k,
+		Seat:       pos - start,
+		Count:      count,
+		Committees: n,
+	}, nil
+}
+
+// Verify checks the assignment against the seed
+func (a Assignment) Verify(seed Seed) bool {
+	expected, err := Assign(seed, a.Index, a.Count, a.Committees)
+	return err == nil && expected == a
+}
+
+// Encode an Assignment
+func (a *Assignment) Encode(w io.Writer) error {
+	return binary.Write(w, binary.BigEndian, a)
+}
+
+// Decode an Assignment
+func (a *Assignment) Decode(r io.Reader) error {
+	if a == nil {
+		return errors.New("struct is nil")
+	}
+	return binary.Read(r, binary.BigEndian, a)
+}
@@ -0,0 +1,77 @@
+package shuffle
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShuffle(t *testing.T) {
+	seed := NewSeed([]byte("beacon value"), 7, []byte("block generation"))
+
+	for _, count := range []uint64{1, 2, 3, 100, 257, 1000} {
+		list, err := Shuffle(seed, count)
+		require.Nil(t, err)
+
+		// a permutation, matching the single index computations
+		seen := make(map[uint64]bool)
+		for pos, index := range list {
+			assert.False(t, seen[index])
+			seen[index] = true
+
+			p, err := Position(seed, index, count)
+			require.Nil(t, err)
+			assert.Equal(t, uint64(pos), p)
+			i, err := Index(seed, uint64(pos), count)
+			require.Nil(t, err)
+			assert.Equal(t, index, i)
+		}
+		assert.Equal(t, int(count), len(seen))
+	}
+
+	// reproducible, and the seed matters
+	a, _ := Shuffle(seed, 100)
+	b, _ := Shuffle(seed, 100)
+	assert.Equal(t, a, b)
+	c, _ := Shuffle(NewSeed([]byte("beacon value"), 8, []byte("block generation")), 100)
+	assert.NotEqual(t, a, c)
+
+	_, err := Shuffle(seed, 0)
+	assert.NotNil(t, err)
+	_, err = Position(seed, 10, 10)
+	assert.NotNil(t, err)
+}
+
+func TestAssignment(t *testing.T) {
+	seed := NewSeed([]byte("vrf output"), 1, nil)
+	count, n := uint64(103), uint64(4)
+
+	committees, err := Committees(seed, count, n)
+	require.Nil(t, err)
+	total := 0
+	for k := range committees {
+		total += len(committees[k])
+		for seat, index := range committees[k] {
+			a, err := Assign(seed, index, count, n)
+			require.Nil(t, err)
+			assert.Equal(t, uint64(k), a.Committee)
+			assert.Equal(t, uint64(seat), a.Seat)
+			assert.True(t, a.Verify(seed))
+		}
+	}
+	assert.Equal(t, int(count), total)
+
+	a, err := Assign(seed, 42, count, n)
+	require.Nil(t, err)
+	buf := new(bytes.Buffer)
+	require.Nil(t, a.Encode(buf))
+	var dec Assignment
+	require.Nil(t, dec.Decode(buf))
+	assert.Equal(t, a, dec)
+
+	dec.Committee = (dec.Committee + 1) % n
+	assert.False(t, dec.Verify(seed))
+	assert.False(t, a.Verify(NewSeed([]byte("other"), 1, nil)))
+}
@@ -0,0 +1,180 @@
+package sigma
+
+import (
+	"errors"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// OrProof proves knowledge of a witness for one statement out of several.
+// The proofs of the other statements are simulated, with challenges chosen
+// in advance; only the sum of all the challenges is fixed by the transcript
+type OrProof struct {
+	Commitments [][]ristretto.Point
+	Challenges  []ristretto.Scalar
+	Responses   [][]ristretto.Scalar
+}
+
+func appendBranches(t *transcript.Transcript, branches []Statement) {
+	t.Append("dom-sep", []byte("dusk.sigma.or"))
+	t.AppendUint64("branches", uint64(len(branches)))
+	for _, st := range branches {
+		appendStatement(t, st)
+	}
+}
+
+// ProveOr proves knowledge of witness for branches[index], without revealing index
+func ProveOr(t *transcript.Transcript, branches []Statement, index int, witness []ristretto.Scalar) (OrProof, error) {
+	if index < 0 || index >= len(branches) {
+		return OrProof{}, errors.New("branch index out of range")
+	}
+	for _, st := range branches {
+		if err := st.Check(); err != nil {
+			return OrProof{}, err
+		}
+	}
+	if !branches[index].Holds(witness) {
+		return OrProof{}, errors.New("the witness does not satisfy the statement")
+	}
+
+	appendBranches(t, branches)
+
+	n := 0
+	for _, st := range branches {
+		n += st.Vars + 1
+	}
+	random, err := randomScalars(t, witness, n)
+	if err != nil {
+		return OrProof{}, err
+	}
+
+	proof := OrProof{
+		Commitments: make([][]ristretto.Point, len(branches)),
+		Challenges:  make([]ristretto.Scalar, len(branches)),
+		Responses:   make([][]ristretto.Scalar, len(branches)),
+	}
+
+	var nonces []ristretto.Scalar
+	var others ristretto.Scalar
+	others.SetZero()
+	for i, st := range branches {
+		proof.Commitments[i] = make([]ristretto.Point, len(st.Equations))
+		proof.Challenges[i] = random[0]
+		proof.Responses[i] = random[1 : 1+st.Vars]
+		random = random[1+st.Vars:]
+
+		if i == index {
+			nonces = proof.Responses[i]
+			for k, eq := range st.Equations {
+				proof.Commitments[i][k] = eval(eq.Terms, nonces)
+			}
+			continue
+		}
+
+		// A_k = sum(s_j * B_kj) - c * Y_k
+		others.Add(&others, &proof.Challenges[i])
+		for k, eq := range st.Equations {
+			var cY ristretto.Point
+			cY.ScalarMult(&eq.Image, &proof.Challenges[i])
+			a := eval(eq.Terms, proof.Responses[i])
+			proof.Commitments[i][k].Sub(&a, &cY)
+		}
+	}
+
+	for _, commitments := range proof.Commitments {
+		for _, a := range commitments {
+			t.AppendPoint("commitment", a)
+		}
+	}
+	c := t.ChallengeScalar("c")
+
+	proof.Challenges[index].Sub(&c, &others)
+	responses := make([]ristretto.Scalar, len(witness))
+	for j := range responses {
+		responses[j].MulAdd(&proof.Challenges[index], &witness[j], &nonces[j])
+	}
+	proof.Responses[index] = responses
+	return proof, nil
+}
+
+// VerifyOr checks that the prover knows a witness for one of the branches
+func VerifyOr(t *transcript.Transcript, branches []Statement, proof OrProof) bool {
+	if len(branches) == 0 || len(proof.Commitments) != len(branches) ||
+		len(proof.Challenges) != len(branches) || len(proof.Responses) != len(branches) {
+		return false
+	}
+	for i, st := range branches {
+		if st.Check() != nil {
+			return false
+		}
+		if len(proof.Commitments[i]) != len(st.Equations) || len(proof.Responses[i]) != st.Vars {
+			return false
+		}
+	}
+
+	appendBranches(t, branches)
+	for _, commitments := range proof.Commitments {
+		for _, a := range commitments {
+			t.AppendPoint("commitment", a)
+		}
+	}
+	c := t.ChallengeScalar("c")
+
+	var sum ristretto.Scalar
+	sum.SetZero()
+	for i, st := range branches {
+		sum.Add(&sum, &proof.Challenges[i])
+		if !checkEquations(st, proof.Commitments[i], proof.Responses[i], proof.Challenges[i]) {
+			return false
+		}
+	}
+	return sum.Equals(&c)
+}
+
+// Encode an OrProof
+func (p *OrProof) Encode(w io.Writer) error {
+	if err := writeScalars(w, p.Challenges); err != nil {
+		return err
+	}
+	if len(p.Commitments) != len(p.Challenges) || len(p.Responses) != len(p.Challenges) {
+		return errors.New("malformed proof")
+	}
+	for i := range p.Challenges {
+		if err := writePoints(w, p.Commitments[i]); err != nil {
+			return err
+		}
+		if err := writeScalars(w, p.Responses[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode an OrProof
+func (p *OrProof) Decode(r io.Reader) error {
+	if p == nil {
+		return errors.New("struct is nil")
+	}
+
+	challenges, err := readScalars(r)
+	if err != nil {
+		return err
+	}
+	commitments := make([][]ristretto.Point, len(challenges))
+	responses := make([][]ristretto.Scalar, len(challenges))
+	for i := range challenges {
+		if commitments[i], err = readPoints(r); err != nil {
+			return err
+		}
+		if responses[i], err = readScalars(r); err != nil {
+			return err
+		}
+	}
+
+	p.Challenges = challenges
+	p.Commitments = commitments
+	p.Responses = responses
+	return nil
+}
@@ -0,0 +1,348 @@
+// Package sigma implements zero knowledge proofs of knowledge for linear
+// relations over Ristretto, made non interactive with the transcript
+// package.
+//
+// A Statement is a system of equations Y_k = sum(x_j * B_kj) over secret
+// scalars x_j. Knowledge of a discrete log, equality of discrete logs and
+// knowledge of a representation are all such systems, and so is the
+// conjunction of any of them. ProveOr proves that one statement out of several
+// holds, without revealing which one.
+//
+// The transcript passed to the prover and the verifier is where callers
+// bind the context of a proof, e.g. the message it authorizes. The
+// statement itself is always absorbed before the commitments
+package sigma
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/transcript"
+)
+
+// Term is the product of a secret scalar and a public base
+type Term struct {
+	// Var is the index of the secret scalar in the witness
+	Var  int
+	Base ristretto.Point
+}
+
+// Equation states that Image = sum(witness[t.Var] * t.Base)
+type Equation struct {
+	Image ristretto.Point
+	Terms []Term
+}
+
+// Statement is a system of equations over Vars secret scalars
+type Statement struct {
+	Vars      int
+	Equations []Equation
+}
+
+// DLog states knowledge of x such that Y = x * G
+func DLog(G, Y ristretto.Point) Statement {
+	return Statement{
+		Vars:      1,
+		Equations: []Equation{{Image: Y, Terms: []Term{{0, G}}}},
+	}
+}
+
+// DLEQ states knowledge of x such that Y = x * G and Z = x * H
+func DLEQ(G, Y, H, Z ristretto.Point) Statement {
+	return Statement{
+		Vars: 1,
+		Equations: []Equation{
+			{Image: Y, Terms: []Term{{0, G}}},
+			{Image: Z, Terms: []Term{{0, H}}},
+		},
+	}
+}
+
+// Representation states knowledge of x_i such that Y = sum(x_i * bases[i])
+func Representation(Y ristretto.Point, bases ...ristretto.Point) Statement {
+	terms := make([]Term, len(bases))
+	for i, b := range bases {
+		terms[i] = Term{i, b}
+	}
+	return Statement{
+		Vars:      len(bases),
+		Equations: []Equation{{Image: Y, Terms: terms}},
+	}
+}
+
+// And returns the conjunction of statements over independent witnesses.
+// The witness of the result is the concatenation of their witnesses. To
+// prove relations sharing a secret, write them as one Statement instead
+func And(statements ...Statement) Statement {
+	var res Statement
+	for _, st := range statements {
+		for _, eq := range st.Equations {
+			terms := make([]Term, len(eq.Terms))
+			for i, t := range eq.Terms {
+				terms[i] = Term{t.Var + res.Vars, t.Base}
+			}
+			res.Equations = append(res.Equations, Equation{Image: eq.Image, Terms: terms})
+		}
+		res.Vars += st.Vars
+	}
+	return res
+}
+
+// Check returns an error if the statement is malformed
+func (st Statement) Check() error {
+	if st.Vars < 1 || len(st.Equations) == 0 {
+		return errors.New("empty statement")
+	}
+	for _, eq := range st.Equations {
+		if len(eq.Terms) == 0 {
+			return errors.New("equation without terms")
+		}
+		for _, t := range eq.Terms {
+			if t.Var < 0 || t.Var >= st.Vars {
+				return errors.New("term refers to an unknown variable")
+			}
+		}
+	}
+	return nil
+}
+
+// Holds returns whether witness satisfies the statement
+func (st Statement) Holds(witness []ristretto.Scalar) bool {
+	if st.Check() != nil || len(witness) != st.Vars {
+		return false
+	}
+	for _, eq := range st.Equations {
+		y := eval(eq.Terms, witness)
+		if !y.Equals(&eq.Image) {
+			return false
+		}
+	}
+	return true
+}
+
+// eval returns sum(scalars[t.Var] * t.Base)
+func eval(terms []Term, scalars []ristretto.Scalar) ristretto.Point {
+	var res, tmp ristretto.Point
+	res.SetZero()
+	for _, t := range terms {
+		tmp.ScalarMult(&t.Base, &scalars[t.Var])
+		res.Add(&res, &tmp)
+	}
+	return res
+}
+
+// appendStatement absorbs the shape, the bases and the images of st
+func appendStatement(t *transcript.Transcript, st Statement) {
+	t.AppendUint64("vars", uint64(st.Vars))
+	t.AppendUint64("equations", uint64(len(st.Equations)))
+	for _, eq := range st.Equations {
+		t.AppendPoint("image", eq.Image)
+		t.AppendUint64("terms", uint64(len(eq.Terms)))
+		for _, term := range eq.Terms {
+			t.AppendUint64("var", uint64(term.Var))
+			t.AppendPoint("base", term.Base)
+		}
+	}
+}
+
+// Proof is a proof of knowledge of a witness for a Statement
+type Proof struct {
+	// Commitments holds one point per equation
+	Commitments []ristretto.Point
+	// Responses holds one scalar per variable
+	Responses []ristretto.Scalar
+}
+
+// Prove proves knowledge of witness for st. The transcript is updated, so
+// that the proof can be followed by other proofs bound to it
+func Prove(t *transcript.Transcript, st Statement, witness []ristretto.Scalar) (Proof, error) {
+	if !st.Holds(witness) {
+		return Proof{}, errors.New("the witness does not satisfy the statement")
+	}
+
+	t.Append("dom-sep", []byte("dusk.sigma"))
+	appendStatement(t, st)
+
+	nonces, err := randomScalars(t, witness, st.Vars)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	proof := Proof{
+		Commitments: make([]ristretto.Point, len(st.Equations)),
+		Responses:   make([]ristretto.Scalar, st.Vars),
+	}
+	for k, eq := range st.Equations {
+		proof.Commitments[k] = eval(eq.Terms, nonces)
+		t.AppendPoint("commitment", proof.Commitments[k])
+	}
+
+	c := t.ChallengeScalar("c")
+	for j := range proof.Responses {
+		// s = k + c * x
+		proof.Responses[j].MulAdd(&c, &witness[j], &nonces[j])
+	}
+	return proof, nil
+}
+
+// Verify checks a proof of knowledge of a witness for st. The transcript
+// must be in the state the prover's was in
+func Verify(t *transcript.Transcript, st Statement, proof Proof) bool {
+	if st.Check() != nil {
+		return false
+	}
+	if len(proof.Commitments) != len(st.Equations) || len(proof.Responses) != st.Vars {
+		return false
+	}
+
+	t.Append("dom-sep", []byte("dusk.sigma"))
+	appendStatement(t, st)
+	for _, a := range proof.Commitments {
+		t.AppendPoint("commitment", a)
+	}
+	c := t.ChallengeScalar("c")
+
+	return checkEquations(st, proof.Commitments, proof.Responses, c)
+}
+
+// checkEquations returns whether sum(s_j * B_kj) = A_k + c * Y_k for every k
+func checkEquations(st Statement, commitments []ristretto.Point, responses []ristretto.Scalar, c ristretto.Scalar) bool {
+	for k, eq := range st.Equations {
+		lhs := eval(eq.Terms, responses)
+
+		var rhs ristretto.Point
+		rhs.ScalarMult(&eq.Image, &c)
+		rhs.Add(&rhs, &commitments[k])
+		if !lhs.Equals(&rhs) {
+			return false
+		}
+	}
+	return true
+}
+
+// randomScalars returns n nonces from the transcript RNG
+func randomScalars(t *transcript.Transcript, witness []ristretto.Scalar, n int) ([]ristretto.Scalar, error) {
+	var w []byte
+	for _, x := range witness {
+		w = append(w, x.Bytes()...)
+	}
+	rng, err := t.RNG(w)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]ristretto.Scalar, n)
+	var wide [64]byte
+	for i := range res {
+		if _, err := io.ReadFull(rng, wide[:]); err != nil {
+			return nil, err
+		}
+		res[i].SetReduced(&wide)
+	}
+	return res, nil
+}
+
+// Encode a Proof
+func (p *Proof) Encode(w io.Writer) error {
+	if err := writePoints(w, p.Commitments); err != nil {
+		return err
+	}
+	return writeScalars(w, p.Responses)
+}
+
+// Decode a Proof
+func (p *Proof) Decode(r io.Reader) error {
+	if p == nil {
+		return errors.New("struct is nil")
+	}
+
+	commitments, err := readPoints(r)
+	if err != nil {
+		return err
+	}
+	responses, err := readScalars(r)
+	if err != nil {
+		return err
+	}
+	p.Commitments = commitments
+	p.Responses = responses
+	return nil
+}
+
+// maxLen bounds the length of the lists read by Decode
+const maxLen = 1 << 16
+
+func writePoints(w io.Writer, points []ristretto.Point) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(points))); err != nil {
+		return err
+	}
+	for _, p := range points {
+		if err := binary.Write(w, binary.BigEndian, p.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeScalars(w io.Writer, scalars []ristretto.Scalar) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(scalars))); err != nil {
+		return err
+	}
+	for _, s := range scalars {
+		if err := binary.Write(w, binary.BigEndian, s.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readLen(r io.Reader) (int, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return 0, err
+	}
+	if n > maxLen {
+		return 0, errors.New("list too long")
+	}
+	return int(n), nil
+}
+
+func readPoints(r io.Reader) ([]ristretto.Point, error) {
+	n, err := readLen(r)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]ristretto.Point, n)
+	var x [32]byte
+	for i := range points {
+		if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+			return nil, err
+		}
+		if !points[i].SetBytes(&x) {
+			return nil, errors.New("point not encodable")
+		}
+	}
+	return points, nil
+}
+
+func readScalars(r io.Reader) ([]ristretto.Scalar, error) {
+	n, err := readLen(r)
+	if err != nil {
+		return nil, err
+	}
+	scalars := make([]ristretto.Scalar, n)
+	var x [32]byte
+	for i := range scalars {
+		if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+			return nil, err
+		}
+		scalars[i].SetBytes(&x)
+		if !bytes.Equal(scalars[i].Bytes(), x[:]) {
+			return nil, errors.New("scalar is not canonically encoded")
+		}
+	}
+	return scalars, nil
+}
@@ -0,0 +1,160 @@
+package sigma
+
+import (
+	"bytes"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/transcript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomPoint() ristretto.Point {
+	var p ristretto.Point
+	p.Rand()
+	return p
+}
+
+func keyPair(G ristretto.Point) (ristretto.Scalar, ristretto.Point) {
+	var x ristretto.Scalar
+	x.Rand()
+	var Y ristretto.Point
+	Y.ScalarMult(&G, &x)
+	return x, Y
+}
+
+func TestDLog(t *testing.T) {
+	var G ristretto.Point
+	G.SetBase()
+	x, Y := keyPair(G)
+	st := DLog(G, Y)
+
+	proof, err := Prove(transcript.New("test"), st, []ristretto.Scalar{x})
+	require.NoError(t, err)
+	assert.True(t, Verify(transcript.New("test"), st, proof))
+
+	// Bound to the transcript and to the statement
+	assert.False(t, Verify(transcript.New("other"), st, proof))
+	assert.False(t, Verify(transcript.New("test"), DLog(G, randomPoint()), proof))
+
+	// Wrong witness
+	var y ristretto.Scalar
+	y.Rand()
+	_, err = Prove(transcript.New("test"), st, []ristretto.Scalar{y})
+	assert.Error(t, err)
+}
+
+func TestDLEQ(t *testing.T) {
+	G, H := randomPoint(), randomPoint()
+	var x ristretto.Scalar
+	x.Rand()
+	var Y, Z ristretto.Point
+	Y.ScalarMult(&G, &x)
+	Z.ScalarMult(&H, &x)
+
+	proof, err := Prove(transcript.New("test"), DLEQ(G, Y, H, Z), []ristretto.Scalar{x})
+	require.NoError(t, err)
+	assert.True(t, Verify(transcript.New("test"), DLEQ(G, Y, H, Z), proof))
+
+	// Different discrete logs
+	_, Z2 := keyPair(H)
+	assert.False(t, Verify(transcript.New("test"), DLEQ(G, Y, H, Z2), proof))
+}
+
+func TestRepresentationAndComposition(t *testing.T) {
+	G, H := randomPoint(), randomPoint()
+	var a, b ristretto.Scalar
+	a.Rand()
+	b.Rand()
+	var C, tmp ristretto.Point
+	C.ScalarMult(&G, &a)
+	tmp.ScalarMult(&H, &b)
+	C.Add(&C, &tmp)
+
+	x, Y := keyPair(G)
+	st := And(Representation(C, G, H), DLog(G, Y))
+	assert.Equal(t, 3, st.Vars)
+
+	witness := []ristretto.Scalar{a, b, x}
+	assert.True(t, st.Holds(witness))
+
+	tr := transcript.New("test")
+	tr.Append("msg", []byte("hello"))
+	proof, err := Prove(tr, st, witness)
+	require.NoError(t, err)
+
+	tr = transcript.New("test")
+	tr.Append("msg", []byte("hello"))
+	assert.True(t, Verify(tr, st, proof))
+
+	tr = transcript.New("test")
+	tr.Append("msg", []byte("bye"))
+	assert.False(t, Verify(tr, st, proof))
+
+	// Proofs chain on the same transcript
+	prover, verifier := transcript.New("test"), transcript.New("test")
+	p1, err := Prove(prover, DLog(G, Y), []ristretto.Scalar{x})
+	require.NoError(t, err)
+	p2, err := Prove(prover, st, witness)
+	require.NoError(t, err)
+	assert.True(t, Verify(verifier, DLog(G, Y), p1))
+	assert.True(t, Verify(verifier, st, p2))
+	assert.False(t, Verify(transcript.New("test"), st, p2))
+}
+
+func TestOr(t *testing.T) {
+	var G ristretto.Point
+	G.SetBase()
+	x, Y := keyPair(G)
+	branches := []Statement{DLog(G, randomPoint()), DLog(G, Y), DLEQ(G, randomPoint(), randomPoint(), randomPoint())}
+
+	proof, err := ProveOr(transcript.New("test"), branches, 1, []ristretto.Scalar{x})
+	require.NoError(t, err)
+	assert.True(t, VerifyOr(transcript.New("test"), branches, proof))
+	assert.False(t, VerifyOr(transcript.New("other"), branches, proof))
+
+	// The proof does not hold for other branches
+	other := append([]Statement{}, branches...)
+	other[1] = DLog(G, randomPoint())
+	assert.False(t, VerifyOr(transcript.New("test"), other, proof))
+
+	// Challenges must add up
+	proof.Challenges[0], proof.Challenges[2] = proof.Challenges[2], proof.Challenges[0]
+	assert.False(t, VerifyOr(transcript.New("test"), branches, proof))
+
+	_, err = ProveOr(transcript.New("test"), branches, 0, []ristretto.Scalar{x})
+	assert.Error(t, err)
+	_, err = ProveOr(transcript.New("test"), branches, 3, []ristretto.Scalar{x})
+	assert.Error(t, err)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	G, H := randomPoint(), randomPoint()
+	var x ristretto.Scalar
+	x.Rand()
+	var Y, Z ristretto.Point
+	Y.ScalarMult(&G, &x)
+	Z.ScalarMult(&H, &x)
+	st := DLEQ(G, Y, H, Z)
+
+	proof, err := Prove(transcript.New("test"), st, []ristretto.Scalar{x})
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	require.NoError(t, proof.Encode(buf))
+	var dec Proof
+	require.NoError(t, dec.Decode(buf))
+	assert.True(t, Verify(transcript.New("test"), st, dec))
+
+	branches := []Statement{st, DLog(G, randomPoint())}
+	orProof, err := ProveOr(transcript.New("test"), branches, 0, []ristretto.Scalar{x})
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, orProof.Encode(buf))
+	var decOr OrProof
+	require.NoError(t, decOr.Decode(buf))
+	assert.True(t, VerifyOr(transcript.New("test"), branches, decOr))
+
+	var nilProof *Proof
+	assert.Error(t, nilProof.Decode(buf))
+}
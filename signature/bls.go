@@ -0,0 +1,134 @@
+package signature
+
+import (
+	"github.com/dusk-network/dusk-crypto/bls"
+)
+
+func init() {
+	Register(BLS, Registration{
+		Name: "bls",
+		DecodeVerifier: func(b []byte) (Verifier, error) {
+			pk, err := bls.UnmarshalPk(b)
+			if err != nil {
+				return nil, err
+			}
+			return &BLSVerifier{Key: pk}, nil
+		},
+		DecodeSignature: func(b []byte) (Signature, error) {
+			sig, err := bls.UnmarshalSignature(b)
+			if err != nil {
+				return nil, err
+			}
+			return &BLSSignature{Sig: sig}, nil
+		},
+		VerifyAggregate: verifyBLSAggregate,
+	})
+}
+
+// BLSSigner signs with a BLS secret key
+type BLSSigner struct {
+	Secret *bls.SecretKey
+	Key    *bls.PublicKey
+}
+
+// NewBLSSigner returns a Signer for sk
+func NewBLSSigner(sk *bls.SecretKey) *BLSSigner {
+	return &BLSSigner{Secret: sk, Key: sk.PublicKey()}
+}
+
+// Scheme returns BLS
+func (s *BLSSigner) Scheme() Scheme { return BLS }
+
+// Public returns the verifier of the public key
+func (s *BLSSigner) Public() Verifier {
+	return &BLSVerifier{Key: s.Key}
+}
+
+// Sign signs msg
+func (s *BLSSigner) Sign(msg []byte) (Signature, error) {
+	sig, err := bls.Sign(s.Secret, s.Key, msg)
+	if err != nil {
+		return nil, err
+	}
+	return &BLSSignature{Sig: sig}, nil
+}
+
+// BLSVerifier verifies with a BLS public key
+type BLSVerifier struct {
+	Key *bls.PublicKey
+}
+
+// Scheme returns BLS
+func (v *BLSVerifier) Scheme() Scheme { return BLS }
+
+// Marshal returns the public key
+func (v *BLSVerifier) Marshal() []byte { return v.Key.Marshal() }
+
+// Verify checks the signature of msg
+func (v *BLSVerifier) Verify(msg []byte, sig Signature) error {
+	s, ok := sig.(*BLSSignature)
+	if !ok {
+		return ErrSchemeMismatch
+	}
+	if bls.Verify(bls.NewApk(v.Key), msg, s.Sig) != nil {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// BLSSignature is a BLS signature
+type BLSSignature struct {
+	Sig *bls.Signature
+}
+
+// Scheme returns BLS
+func (s *BLSSignature) Scheme() Scheme { return BLS }
+
+// Marshal returns the signature
+func (s *BLSSignature) Marshal() []byte { return s.Sig.Marshal() }
+
+// Aggregate returns the aggregation of the signature and other
+func (s *BLSSignature) Aggregate(other Signature) (AggregatableSignature, error) {
+	o, ok := other.(*BLSSignature)
+	if !ok {
+		return nil, ErrSchemeMismatch
+	}
+	return &BLSSignature{Sig: s.Sig.Copy().Aggregate(o.Sig)}, nil
+}
+
+// verifyBLSAggregate groups the keys by message, aggregates each group into
+// a single apk, and checks the signature against all the groups at once
+func verifyBLSAggregate(verifiers []Verifier, msgs [][]byte, sig Signature) error {
+	s, ok := sig.(*BLSSignature)
+	if !ok {
+		return ErrSchemeMismatch
+	}
+
+	var groups [][]byte
+	keys := make(map[string][]*bls.PublicKey)
+	for i, v := range verifiers {
+		bv, ok := v.(*BLSVerifier)
+		if !ok {
+			return ErrSchemeMismatch
+		}
+		m := string(msgs[i])
+		if _, ok := keys[m]; !ok {
+			groups = append(groups, msgs[i])
+		}
+		keys[m] = append(keys[m], bv.Key)
+	}
+
+	apks := make([]*bls.Apk, len(groups))
+	for i, m := range groups {
+		apk, err := bls.AggregateApk(keys[string(m)])
+		if err != nil {
+			return err
+		}
+		apks[i] = apk
+	}
+
+	if bls.VerifyBatch(apks, groups, s.Sig) != nil {
+		return ErrInvalid
+	}
+	return nil
+}
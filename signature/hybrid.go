@@ -0,0 +1,201 @@
+package signature
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/wots"
+)
+
+func init() {
+	Register(Hybrid, Registration{
+		Name:            "hybrid",
+		DecodeVerifier:  decodeHybridVerifier,
+		DecodeSignature: decodeHybridSignature,
+	})
+}
+
+// errNestedHybrid is returned when decoding a hybrid key or signature whose
+// classical part is hybrid itself
+var errNestedHybrid = errors.New("signature: nested hybrid encoding")
+
+// HybridSigner signs with both a classical key, Schnorr or BLS, and a
+// stateful hash-based key, so that its signatures stay unforgeable as long
+// as either scheme is secure. Every signature uses up a one-time key of the
+// hash-based signer: hybrid signatures are meant for rare messages, e.g.
+// governance decisions
+type HybridSigner struct {
+	Classical Signer
+	HashBased *wots.Signer
+}
+
+// NewHybridSigner returns a Signer for the classical signer and the
+// hash-based signer
+func NewHybridSigner(classical Signer, hashBased *wots.Signer) *HybridSigner {
+	return &HybridSigner{Classical: classical, HashBased: hashBased}
+}
+
+// Scheme returns Hybrid
+func (s *HybridSigner) Scheme() Scheme { return Hybrid }
+
+// Public returns the verifier of both public keys
+func (s *HybridSigner) Public() Verifier {
+	return &HybridVerifier{Classical: s.Classical.Public(), HashBased: s.HashBased.PublicKey()}
+}
+
+// Sign signs msg with both keys. The classical signature comes first, so
+// that its failure does not waste a one-time key
+func (s *HybridSigner) Sign(msg []byte) (Signature, error) {
+	bound := hybridMessage(s.Public().(*HybridVerifier), msg)
+	classical, err := s.Classical.Sign(bound)
+	if err != nil {
+		return nil, err
+	}
+	hashBased, err := s.HashBased.Sign(bound)
+	if err != nil {
+		return nil, err
+	}
+	return &HybridSignature{
+		Classical: classical,
+		Params:    s.HashBased.PublicKey().Params,
+		HashBased: hashBased,
+	}, nil
+}
+
+// hybridMessage is what both parts of a hybrid signature sign. Binding both
+// keys prevents stripping either signature off and passing the other as a
+// standalone signature of msg
+func hybridMessage(v *HybridVerifier, msg []byte) []byte {
+	return hash.Sha3256WithDomain("dusk.signature.hybrid", v.Marshal(), msg)
+}
+
+// HybridVerifier verifies with a classical key and a hash-based key
+type HybridVerifier struct {
+	Classical Verifier
+	HashBased *wots.TreePublicKey
+}
+
+// Scheme returns Hybrid
+func (v *HybridVerifier) Scheme() Scheme { return Hybrid }
+
+// Marshal returns the length prefixed classical key, with its scheme byte,
+// followed by the hash-based key
+func (v *HybridVerifier) Marshal() []byte {
+	buf := new(bytes.Buffer)
+	writePrefixed(buf, Encode(v.Classical))
+	_ = v.HashBased.Encode(buf)
+	return buf.Bytes()
+}
+
+// Verify checks both signatures of msg. Either failing fails the
+// verification
+func (v *HybridVerifier) Verify(msg []byte, sig Signature) error {
+	s, ok := sig.(*HybridSignature)
+	if !ok {
+		return ErrSchemeMismatch
+	}
+	bound := hybridMessage(v, msg)
+	if err := v.Classical.Verify(bound, s.Classical); err != nil {
+		return err
+	}
+	if s.Params != v.HashBased.Params || !v.HashBased.Verify(bound, s.HashBased) {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// HybridSignature is a classical signature and a hash-based signature of the
+// same message
+type HybridSignature struct {
+	Classical Signature
+	// Params of the hash-based signature, to decode it
+	Params    wots.Params
+	HashBased *wots.TreeSignature
+}
+
+// Scheme returns Hybrid
+func (s *HybridSignature) Scheme() Scheme { return Hybrid }
+
+// Marshal returns the length prefixed classical signature, with its scheme
+// byte, followed by W, the height of the tree and the hash-based signature
+func (s *HybridSignature) Marshal() []byte {
+	buf := new(bytes.Buffer)
+	writePrefixed(buf, Encode(s.Classical))
+	_ = binary.Write(buf, binary.BigEndian, uint16(s.Params.W))
+	buf.WriteByte(byte(len(s.HashBased.Path)))
+	_ = s.HashBased.Encode(buf)
+	return buf.Bytes()
+}
+
+func writePrefixed(buf *bytes.Buffer, b []byte) {
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(b)))
+	buf.Write(b)
+}
+
+func readPrefixed(r io.Reader) ([]byte, error) {
+	var n uint16
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	if len(b) > 0 && Scheme(b[0]) == Hybrid {
+		return nil, errNestedHybrid
+	}
+	return b, nil
+}
+
+func decodeHybridVerifier(b []byte) (Verifier, error) {
+	r := bytes.NewReader(b)
+	classical, err := readPrefixed(r)
+	if err != nil {
+		return nil, err
+	}
+	v := &HybridVerifier{HashBased: &wots.TreePublicKey{}}
+	if v.Classical, err = DecodeVerifier(classical); err != nil {
+		return nil, err
+	}
+	if err := v.HashBased.Decode(r); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("signature: trailing data in hybrid key")
+	}
+	return v, nil
+}
+
+func decodeHybridSignature(b []byte) (Signature, error) {
+	r := bytes.NewReader(b)
+	classical, err := readPrefixed(r)
+	if err != nil {
+		return nil, err
+	}
+	s := &HybridSignature{HashBased: &wots.TreeSignature{}}
+	if s.Classical, err = DecodeSignature(classical); err != nil {
+		return nil, err
+	}
+
+	// the hash-based signature decodes against a key of its shape
+	var w uint16
+	if err := binary.Read(r, binary.BigEndian, &w); err != nil {
+		return nil, err
+	}
+	height, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	s.Params = wots.Params{W: int(w)}
+	shape := &wots.TreePublicKey{Params: s.Params, Height: int(height)}
+	if err := s.HashBased.Decode(shape, r); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("signature: trailing data in hybrid signature")
+	}
+	return s, nil
+}
@@ -0,0 +1,111 @@
+package signature
+
+import (
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/wots"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	next uint64
+}
+
+func (m *memoryStore) Load() (uint64, error) { return m.next, nil }
+
+func (m *memoryStore) Save(next uint64) error {
+	m.next = next
+	return nil
+}
+
+func hybridSigners(t *testing.T) []*HybridSigner {
+	var res []*HybridSigner
+	for _, classical := range signers(t) {
+		hashBased, _, err := wots.NewSigner(wots.Params{W: 16}, 2, &memoryStore{}, nil)
+		require.NoError(t, err)
+		res = append(res, NewHybridSigner(classical, hashBased))
+	}
+	return res
+}
+
+func TestHybrid(t *testing.T) {
+	msg := []byte("raise the block size")
+	for _, s := range hybridSigners(t) {
+		sig, err := s.Sign(msg)
+		require.NoError(t, err)
+		assert.Equal(t, Hybrid, sig.Scheme())
+		assert.NoError(t, s.Public().Verify(msg, sig))
+		assert.Equal(t, ErrInvalid, s.Public().Verify([]byte("other"), sig))
+
+		// Through the registry
+		key, encSig := Encode(s.Public()), Encode(sig)
+		assert.NoError(t, Verify(key, msg, encSig))
+		v, err := DecodeVerifier(key)
+		require.NoError(t, err)
+		assert.Equal(t, s.Public().Marshal(), v.Marshal())
+		dec, err := DecodeSignature(encSig)
+		require.NoError(t, err)
+		assert.Equal(t, sig.Marshal(), dec.Marshal())
+
+		// Neither part is a signature of msg on its own
+		h := sig.(*HybridSignature)
+		assert.Equal(t, ErrInvalid, s.Classical.Public().Verify(msg, h.Classical))
+	}
+}
+
+func TestHybridRequiresBoth(t *testing.T) {
+	msg := []byte("raise the block size")
+	all := hybridSigners(t)
+	s := all[0]
+	sig, err := s.Sign(msg)
+	require.NoError(t, err)
+	other, err := all[1].Sign(msg)
+	require.NoError(t, err)
+	h, o := sig.(*HybridSignature), other.(*HybridSignature)
+
+	// a valid hash-based signature with a forged classical one
+	forged := &HybridSignature{Classical: h.Classical, Params: h.Params, HashBased: o.HashBased}
+	assert.Equal(t, ErrInvalid, s.Public().Verify(msg, forged))
+
+	// a valid classical signature with a tampered hash-based one
+	tampered, err := DecodeSignature(Encode(sig))
+	require.NoError(t, err)
+	tampered.(*HybridSignature).HashBased.Signature[0][0] ^= 1
+	assert.Equal(t, ErrInvalid, s.Public().Verify(msg, tampered))
+
+	// a classical signature of another scheme
+	mixed := &HybridSignature{Classical: o.Classical, Params: h.Params, HashBased: h.HashBased}
+	assert.Equal(t, ErrSchemeMismatch, s.Public().Verify(msg, mixed))
+}
+
+func TestHybridExhausted(t *testing.T) {
+	s := hybridSigners(t)[0]
+	for i := 0; i < 4; i++ {
+		_, err := s.Sign([]byte("msg"))
+		require.NoError(t, err)
+	}
+	_, err := s.Sign([]byte("msg"))
+	assert.Equal(t, wots.ErrExhausted, err)
+}
+
+func TestHybridDecodeErrors(t *testing.T) {
+	s := hybridSigners(t)[1]
+	sig, err := s.Sign([]byte("msg"))
+	require.NoError(t, err)
+	key, encSig := Encode(s.Public()), Encode(sig)
+
+	_, err = DecodeVerifier(append(key, 0))
+	assert.Error(t, err)
+	_, err = DecodeVerifier(key[:len(key)-1])
+	assert.Error(t, err)
+	_, err = DecodeSignature(append(encSig, 0))
+	assert.Error(t, err)
+	_, err = DecodeSignature(encSig[:len(encSig)-1])
+	assert.Error(t, err)
+
+	// hybrid keys do not nest
+	nested := append([]byte{byte(Hybrid), 0, byte(len(key))}, key...)
+	_, err = DecodeVerifier(nested)
+	assert.Equal(t, errNestedHybrid, err)
+}
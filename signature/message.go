@@ -0,0 +1,66 @@
+package signature
+
+import (
+	"errors"
+	"strconv"
+)
+
+// MessagePrefix starts every signed message. The leading 0x19 byte is not
+// valid UTF-8 text on its own nor the start of the protocol encodings of the
+// repository, and the rest tells humans what they sign
+const MessagePrefix = "\x19Dusk Signed Message:\n"
+
+// MaxDomainSize bounds the size of the domains of signed messages
+const MaxDomainSize = 255
+
+// ErrInvalidDomain is returned for empty or oversized domains, and for the
+// domains holding control characters
+var ErrInvalidDomain = errors.New("signature: invalid message domain")
+
+// FrameMessage returns what SignMessage signs:
+//
+//	MessagePrefix || domain || "\n" || decimal length of msg || "\n" || msg
+//
+// The domain names the application or the purpose of the message, e.g.
+// "example.org login", so that a message signed for one can not be replayed
+// to another. The length makes the frame unambiguous whatever msg holds
+func FrameMessage(domain string, msg []byte) ([]byte, error) {
+	if len(domain) == 0 || len(domain) > MaxDomainSize {
+		return nil, ErrInvalidDomain
+	}
+	for i := 0; i < len(domain); i++ {
+		if domain[i] < 0x20 || domain[i] == 0x7f {
+			return nil, ErrInvalidDomain
+		}
+	}
+
+	length := strconv.Itoa(len(msg))
+	b := make([]byte, 0, len(MessagePrefix)+len(domain)+len(length)+2+len(msg))
+	b = append(b, MessagePrefix...)
+	b = append(b, domain...)
+	b = append(b, '\n')
+	b = append(b, length...)
+	b = append(b, '\n')
+	return append(b, msg...), nil
+}
+
+// SignMessage signs a human readable message, e.g. to prove the ownership
+// of a key to a wallet, with any Signer. The signature is of the frame of
+// the message, never of msg itself, so it can not be replayed as the
+// signature of a protocol message
+func SignMessage(s Signer, domain string, msg []byte) (Signature, error) {
+	framed, err := FrameMessage(domain, msg)
+	if err != nil {
+		return nil, err
+	}
+	return s.Sign(framed)
+}
+
+// VerifyMessage checks a signature made with SignMessage
+func VerifyMessage(v Verifier, domain string, msg []byte, sig Signature) error {
+	framed, err := FrameMessage(domain, msg)
+	if err != nil {
+		return err
+	}
+	return v.Verify(framed, sig)
+}
@@ -0,0 +1,49 @@
+package signature
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameMessage(t *testing.T) {
+	framed, err := FrameMessage("example.org login", []byte("I own this key"))
+	require.NoError(t, err)
+	assert.Equal(t, "\x19Dusk Signed Message:\nexample.org login\n14\nI own this key", string(framed))
+
+	framed, err = FrameMessage("d", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "\x19Dusk Signed Message:\nd\n0\n", string(framed))
+
+	for _, domain := range []string{"", "a\nb", "tab\t", "del\x7f", strings.Repeat("a", MaxDomainSize+1)} {
+		_, err := FrameMessage(domain, []byte("msg"))
+		assert.Equal(t, ErrInvalidDomain, err)
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	msg := []byte("I own this key")
+	for _, s := range signers(t) {
+		sig, err := SignMessage(s, "example.org login", msg)
+		require.NoError(t, err)
+		assert.NoError(t, VerifyMessage(s.Public(), "example.org login", msg, sig))
+
+		// bound to the domain and the message
+		assert.Equal(t, ErrInvalid, VerifyMessage(s.Public(), "example.com login", msg, sig))
+		assert.Equal(t, ErrInvalid, VerifyMessage(s.Public(), "example.org login", []byte("I own that key"), sig))
+		assert.Equal(t, ErrInvalidDomain, VerifyMessage(s.Public(), "", msg, sig))
+
+		// not a signature of the raw message
+		assert.Equal(t, ErrInvalid, s.Public().Verify(msg, sig))
+
+		// and raw signatures are not signed messages
+		raw, err := s.Sign(msg)
+		require.NoError(t, err)
+		assert.Equal(t, ErrInvalid, VerifyMessage(s.Public(), "example.org login", msg, raw))
+	}
+
+	_, err := SignMessage(signers(t)[0], "a\nb", msg)
+	assert.Equal(t, ErrInvalidDomain, err)
+}
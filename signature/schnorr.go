@@ -0,0 +1,98 @@
+package signature
+
+import (
+	"bytes"
+	"errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/schnorr"
+)
+
+func init() {
+	Register(Schnorr, Registration{
+		Name: "schnorr",
+		DecodeVerifier: func(b []byte) (Verifier, error) {
+			if len(b) != 32 {
+				return nil, errors.New("signature: invalid schnorr key size")
+			}
+			var buf [32]byte
+			copy(buf[:], b)
+			v := &SchnorrVerifier{}
+			if !v.Key.SetBytes(&buf) {
+				return nil, errors.New("point not encodable")
+			}
+			return v, nil
+		},
+		DecodeSignature: func(b []byte) (Signature, error) {
+			if len(b) != 64 {
+				return nil, errors.New("signature: invalid schnorr signature size")
+			}
+			s := &SchnorrSignature{}
+			if err := s.Sig.Decode(bytes.NewReader(b)); err != nil {
+				return nil, err
+			}
+			return s, nil
+		},
+	})
+}
+
+// SchnorrSigner signs with a Schnorr secret key
+type SchnorrSigner struct {
+	Key ristretto.Scalar
+}
+
+// NewSchnorrSigner returns a Signer for sk
+func NewSchnorrSigner(sk ristretto.Scalar) *SchnorrSigner {
+	return &SchnorrSigner{Key: sk}
+}
+
+// Scheme returns Schnorr
+func (s *SchnorrSigner) Scheme() Scheme { return Schnorr }
+
+// Public returns the verifier of the public key
+func (s *SchnorrSigner) Public() Verifier {
+	return &SchnorrVerifier{Key: schnorr.PublicKey(s.Key)}
+}
+
+// Sign signs msg
+func (s *SchnorrSigner) Sign(msg []byte) (Signature, error) {
+	return &SchnorrSignature{Sig: schnorr.Sign(s.Key, msg)}, nil
+}
+
+// SchnorrVerifier verifies with a Schnorr public key
+type SchnorrVerifier struct {
+	Key ristretto.Point
+}
+
+// Scheme returns Schnorr
+func (v *SchnorrVerifier) Scheme() Scheme { return Schnorr }
+
+// Marshal returns the 32 byte public key
+func (v *SchnorrVerifier) Marshal() []byte { return v.Key.Bytes() }
+
+// Verify checks the signature of msg
+func (v *SchnorrVerifier) Verify(msg []byte, sig Signature) error {
+	s, ok := sig.(*SchnorrSignature)
+	if !ok {
+		return ErrSchemeMismatch
+	}
+	if !schnorr.Verify(v.Key, msg, s.Sig) {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// SchnorrSignature is a Schnorr signature
+type SchnorrSignature struct {
+	Sig schnorr.Signature
+}
+
+// Scheme returns Schnorr
+func (s *SchnorrSignature) Scheme() Scheme { return Schnorr }
+
+// Marshal returns the 64 byte signature
+func (s *SchnorrSignature) Marshal() []byte {
+	buf := new(bytes.Buffer)
+	_ = s.Sig.Encode(buf)
+	return buf.Bytes()
+}
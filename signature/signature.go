@@ -0,0 +1,183 @@
+// Package signature lets transaction code handle several signature
+// schemes uniformly. Signers, Verifiers and Signatures of every scheme
+// implement the interfaces below, and encode with a leading scheme byte, so
+// that they can be decoded through a registry without knowing the scheme
+// in advance.
+//
+// Schnorr, BLS and Hybrid are registered by this package. Other schemes register
+// themselves with Register, from an init function
+package signature
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Scheme identifies a signature scheme on the wire
+type Scheme byte
+
+const (
+	// Schnorr signatures over Ristretto
+	Schnorr Scheme = 0x01
+	// BLS signatures over bn256, resilient to rogue key attacks
+	BLS Scheme = 0x02
+	// Hybrid pairs a signature of another scheme with a hash-based one
+	Hybrid Scheme = 0x03
+)
+
+var (
+	// ErrUnknownScheme is returned when decoding a scheme nobody registered
+	ErrUnknownScheme = errors.New("signature: unknown scheme")
+	// ErrSchemeMismatch is returned when a signature is checked against a
+	// key of another scheme
+	ErrSchemeMismatch = errors.New("signature: scheme mismatch")
+	// ErrInvalid is returned for signatures that do not verify
+	ErrInvalid = errors.New("signature: invalid signature")
+	// ErrNotAggregatable is returned when aggregating signatures of a scheme
+	// that does not support it
+	ErrNotAggregatable = errors.New("signature: scheme does not aggregate")
+)
+
+// Signature is a signature of any scheme
+type Signature interface {
+	Scheme() Scheme
+	// Marshal returns the encoding of the signature, without scheme byte
+	Marshal() []byte
+}
+
+// AggregatableSignature is a signature that other signatures of the same
+// scheme can be folded into
+type AggregatableSignature interface {
+	Signature
+	// Aggregate returns the aggregation of the signature and other, without
+	// modifying either
+	Aggregate(other Signature) (AggregatableSignature, error)
+}
+
+// Verifier is a public key of any scheme
+type Verifier interface {
+	Scheme() Scheme
+	// Marshal returns the encoding of the key, without scheme byte
+	Marshal() []byte
+	// Verify checks the signature of msg
+	Verify(msg []byte, sig Signature) error
+}
+
+// Signer is a secret key of any scheme
+type Signer interface {
+	Scheme() Scheme
+	Public() Verifier
+	Sign(msg []byte) (Signature, error)
+}
+
+// Registration describes how to decode the keys and signatures of a scheme
+type Registration struct {
+	Name            string
+	DecodeVerifier  func([]byte) (Verifier, error)
+	DecodeSignature func([]byte) (Signature, error)
+	// VerifyAggregate checks a signature aggregated from the signatures of
+	// msgs[i] by verifiers[i]. It is nil for schemes that do not aggregate
+	VerifyAggregate func(verifiers []Verifier, msgs [][]byte, sig Signature) error
+}
+
+var (
+	mu       sync.RWMutex
+	registry = make(map[Scheme]Registration)
+)
+
+// Register makes a scheme available to the decoding functions. It panics
+// if the scheme is registered twice or the registration is incomplete
+func Register(s Scheme, r Registration) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if r.DecodeVerifier == nil || r.DecodeSignature == nil {
+		panic("signature: incomplete registration")
+	}
+	if _, ok := registry[s]; ok {
+		panic(fmt.Sprintf("signature: scheme %d registered twice", s))
+	}
+	registry[s] = r
+}
+
+// Lookup returns the registration of a scheme
+func Lookup(s Scheme) (Registration, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	r, ok := registry[s]
+	return r, ok
+}
+
+// Encode returns the scheme byte of a key or signature followed by its encoding
+func Encode(v interface {
+	Scheme() Scheme
+	Marshal() []byte
+}) []byte {
+	return append([]byte{byte(v.Scheme())}, v.Marshal()...)
+}
+
+func split(b []byte) (Registration, []byte, error) {
+	if len(b) == 0 {
+		return Registration{}, nil, errors.New("signature: empty encoding")
+	}
+	r, ok := Lookup(Scheme(b[0]))
+	if !ok {
+		return Registration{}, nil, ErrUnknownScheme
+	}
+	return r, b[1:], nil
+}
+
+// DecodeVerifier decodes a key encoded with Encode
+func DecodeVerifier(b []byte) (Verifier, error) {
+	r, body, err := split(b)
+	if err != nil {
+		return nil, err
+	}
+	return r.DecodeVerifier(body)
+}
+
+// DecodeSignature decodes a signature encoded with Encode
+func DecodeSignature(b []byte) (Signature, error) {
+	r, body, err := split(b)
+	if err != nil {
+		return nil, err
+	}
+	return r.DecodeSignature(body)
+}
+
+// Verify decodes a key and a signature encoded with Encode, and checks the
+// signature of msg
+func Verify(key, msg, sig []byte) error {
+	v, err := DecodeVerifier(key)
+	if err != nil {
+		return err
+	}
+	s, err := DecodeSignature(sig)
+	if err != nil {
+		return err
+	}
+	return v.Verify(msg, s)
+}
+
+// VerifyAggregate checks a signature aggregated from the signatures of
+// msgs[i] by verifiers[i], which must all belong to its scheme
+func VerifyAggregate(verifiers []Verifier, msgs [][]byte, sig Signature) error {
+	if len(verifiers) == 0 || len(verifiers) != len(msgs) {
+		return errors.New("signature: invalid number of verifiers")
+	}
+	for _, v := range verifiers {
+		if v.Scheme() != sig.Scheme() {
+			return ErrSchemeMismatch
+		}
+	}
+
+	r, ok := Lookup(sig.Scheme())
+	if !ok {
+		return ErrUnknownScheme
+	}
+	if r.VerifyAggregate == nil {
+		return ErrNotAggregatable
+	}
+	return r.VerifyAggregate(verifiers, msgs, sig)
+}
@@ -0,0 +1,119 @@
+package signature
+
+import (
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/schnorr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signers(t *testing.T) []Signer {
+	sk, _ := schnorr.GenerateKey()
+	_, blsSk, err := bls.GenKeyPair(nil)
+	require.NoError(t, err)
+	return []Signer{NewSchnorrSigner(sk), NewBLSSigner(blsSk)}
+}
+
+func TestSignVerify(t *testing.T) {
+	msg := []byte("transaction")
+	for _, s := range signers(t) {
+		sig, err := s.Sign(msg)
+		require.NoError(t, err)
+		assert.Equal(t, s.Scheme(), sig.Scheme())
+		assert.NoError(t, s.Public().Verify(msg, sig))
+		assert.Equal(t, ErrInvalid, s.Public().Verify([]byte("other"), sig))
+
+		// Through the registry
+		key, encSig := Encode(s.Public()), Encode(sig)
+		assert.Equal(t, byte(s.Scheme()), key[0])
+		assert.NoError(t, Verify(key, msg, encSig))
+		assert.Error(t, Verify(key, []byte("other"), encSig))
+
+		v, err := DecodeVerifier(key)
+		require.NoError(t, err)
+		assert.Equal(t, s.Public().Marshal(), v.Marshal())
+		dec, err := DecodeSignature(encSig)
+		require.NoError(t, err)
+		assert.Equal(t, sig.Marshal(), dec.Marshal())
+	}
+}
+
+func TestSchemeMismatch(t *testing.T) {
+	msg := []byte("transaction")
+	all := signers(t)
+	schnorrSig, err := all[0].Sign(msg)
+	require.NoError(t, err)
+	blsSig, err := all[1].Sign(msg)
+	require.NoError(t, err)
+
+	assert.Equal(t, ErrSchemeMismatch, all[0].Public().Verify(msg, blsSig))
+	assert.Equal(t, ErrSchemeMismatch, all[1].Public().Verify(msg, schnorrSig))
+	assert.Equal(t, ErrSchemeMismatch, Verify(Encode(all[0].Public()), msg, Encode(blsSig)))
+}
+
+func TestDecodeErrors(t *testing.T) {
+	_, err := DecodeVerifier(nil)
+	assert.Error(t, err)
+	_, err = DecodeVerifier([]byte{0x7f, 1, 2})
+	assert.Equal(t, ErrUnknownScheme, err)
+	_, err = DecodeSignature([]byte{byte(Schnorr), 1, 2})
+	assert.Error(t, err)
+	_, err = DecodeVerifier(append([]byte{byte(Schnorr)}, make([]byte, 31)...))
+	assert.Error(t, err)
+}
+
+func TestRegister(t *testing.T) {
+	assert.Panics(t, func() { Register(Schnorr, Registration{}) })
+	assert.Panics(t, func() {
+		Register(Schnorr, Registration{
+			DecodeVerifier:  func([]byte) (Verifier, error) { return nil, nil },
+			DecodeSignature: func([]byte) (Signature, error) { return nil, nil },
+		})
+	})
+
+	r, ok := Lookup(BLS)
+	assert.True(t, ok)
+	assert.Equal(t, "bls", r.Name)
+}
+
+func TestAggregate(t *testing.T) {
+	msgs := [][]byte{[]byte("block"), []byte("block"), []byte("vote"), []byte("block")}
+
+	var verifiers []Verifier
+	var agg AggregatableSignature
+	for _, msg := range msgs {
+		_, sk, err := bls.GenKeyPair(nil)
+		require.NoError(t, err)
+		s := NewBLSSigner(sk)
+		verifiers = append(verifiers, s.Public())
+
+		sig, err := s.Sign(msg)
+		require.NoError(t, err)
+		if agg == nil {
+			agg = sig.(AggregatableSignature)
+			continue
+		}
+		before := agg.Marshal()
+		agg, err = agg.Aggregate(sig)
+		require.NoError(t, err)
+		assert.NotEqual(t, before, agg.Marshal())
+	}
+
+	assert.NoError(t, VerifyAggregate(verifiers, msgs, agg))
+
+	msgs[2] = []byte("block")
+	assert.Equal(t, ErrInvalid, VerifyAggregate(verifiers, msgs, agg))
+	assert.Error(t, VerifyAggregate(verifiers[:2], msgs, agg))
+
+	// Schnorr signatures do not aggregate
+	sk, _ := schnorr.GenerateKey()
+	s := NewSchnorrSigner(sk)
+	sig, err := s.Sign(msgs[0])
+	require.NoError(t, err)
+	_, ok := sig.(AggregatableSignature)
+	assert.False(t, ok)
+	assert.Equal(t, ErrNotAggregatable, VerifyAggregate([]Verifier{s.Public()}, msgs[:1], sig))
+	assert.Equal(t, ErrSchemeMismatch, VerifyAggregate([]Verifier{s.Public()}, msgs[:1], agg))
+}
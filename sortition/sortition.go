@@ -0,0 +1,151 @@
+// Package sortition implements cryptographic sortition on top of the VRF.
+// A participant holding stake units out of totalStake is selected once per
+// unit with probability committeeSize / totalStake, so that committees have
+// committeeSize members on average. The number of selections is drawn from
+// the binomial distribution by inverting its CDF at the VRF output, which
+// makes it private until revealed and verifiable by everyone afterwards.
+//
+// The computation uses arbitrary precision floats so that every node derives
+// the same result regardless of the platform
+package sortition
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/dusk-network/dusk-crypto/vrf"
+)
+
+const precision = 256
+
+// Params are the public inputs of a sortition round
+type Params struct {
+	// Seed is the round seed, which must be unpredictable before the round
+	Seed []byte
+	// Role separates the committees drawn in the same round
+	Role []byte
+
+	Stake         uint64
+	TotalStake    uint64
+	CommitteeSize uint64
+}
+
+// Ticket is the claim of a participant to be selected in a round
+type Ticket struct {
+	Proof      vrf.Proof
+	Selections uint64
+}
+
+func (p Params) input() []byte {
+	buf := make([]byte, 0, 16+len(p.Seed)+len(p.Role))
+	buf = appendBytes(buf, p.Seed)
+	buf = appendBytes(buf, p.Role)
+	return buf
+}
+
+func appendBytes(buf, b []byte) []byte {
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(b)))
+	buf = append(buf, l[:]...)
+	return append(buf, b...)
+}
+
+// Run evaluates the VRF on the round inputs and returns the ticket of the
+// participant. The participant is selected if the ticket has at least one
+// selection
+func Run(sk vrf.SecretKey, p Params) (Ticket, error) {
+	pi, err := vrf.Prove(sk, p.input())
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	beta, err := vrf.ProofToHash(pi)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	n, err := Selections(beta, p.Stake, p.TotalStake, p.CommitteeSize)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	return Ticket{Proof: pi, Selections: n}, nil
+}
+
+// Verify checks the ticket of the participant holding pk against the round
+// inputs, including the claimed number of selections
+func Verify(pk vrf.PublicKey, p Params, t Ticket) bool {
+	beta, ok := vrf.Verify(pk, t.Proof, p.input())
+	if !ok {
+		return false
+	}
+
+	n, err := Selections(beta, p.Stake, p.TotalStake, p.CommitteeSize)
+	if err != nil {
+		return false
+	}
+	return n == t.Selections
+}
+
+// Selections returns the number of times a participant with the given stake
+// is selected for the VRF output beta. It is the smallest j such that
+// beta / 2^512 < sum(B(k; stake, committeeSize/totalStake)) for k <= j
+func Selections(beta [vrf.OutputSize]byte, stake, totalStake, committeeSize uint64) (uint64, error) {
+	if totalStake == 0 {
+		return 0, errors.New("total stake is zero")
+	}
+	if stake > totalStake {
+		return 0, errors.New("stake exceeds the total stake")
+	}
+	if committeeSize > totalStake {
+		return 0, errors.New("committee size exceeds the total stake")
+	}
+	if stake == 0 || committeeSize == 0 {
+		return 0, nil
+	}
+	if committeeSize == totalStake {
+		return stake, nil
+	}
+
+	ratio := newFloat().SetInt(new(big.Int).SetBytes(beta[:]))
+	ratio.SetMantExp(ratio, -8*vrf.OutputSize)
+
+	p := newFloat().Quo(newFloat().SetUint64(committeeSize), newFloat().SetUint64(totalStake))
+	q := newFloat().Sub(newFloat().SetInt64(1), p)
+	odds := newFloat().Quo(p, q)
+
+	// B(0) = q^stake, B(k+1) = B(k) * (stake-k)/(k+1) * p/q
+	pmf := pow(q, stake)
+	cdf := newFloat().Set(pmf)
+
+	for j := uint64(0); j < stake; j++ {
+		if ratio.Cmp(cdf) < 0 {
+			return j, nil
+		}
+
+		pmf.Mul(pmf, newFloat().SetUint64(stake-j))
+		pmf.Quo(pmf, newFloat().SetUint64(j+1))
+		pmf.Mul(pmf, odds)
+		cdf.Add(cdf, pmf)
+	}
+
+	return stake, nil
+}
+
+func newFloat() *big.Float {
+	return new(big.Float).SetPrec(precision)
+}
+
+// pow returns x^n by square and multiply
+func pow(x *big.Float, n uint64) *big.Float {
+	res := newFloat().SetInt64(1)
+	base := newFloat().Set(x)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			res.Mul(res, base)
+		}
+		base.Mul(base, base)
+	}
+	return res
+}
@@ -0,0 +1,105 @@
+package sortition
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/vrf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunVerify(t *testing.T) {
+	sk, pk, err := vrf.GenerateKey()
+	require.Nil(t, err)
+
+	p := Params{
+		Seed:          []byte("round seed"),
+		Role:          []byte("proposer"),
+		Stake:         500,
+		TotalStake:    1000,
+		CommitteeSize: 100,
+	}
+
+	ticket, err := Run(sk, p)
+	require.Nil(t, err)
+	assert.True(t, Verify(pk, p, ticket))
+
+	// a different claim is rejected
+	wrong := ticket
+	wrong.Selections++
+	assert.False(t, Verify(pk, p, wrong))
+
+	// the ticket is bound to the role, seed and stake
+	other := p
+	other.Role = []byte("voter")
+	assert.False(t, Verify(pk, other, ticket))
+
+	other = p
+	other.Seed = []byte("another seed")
+	assert.False(t, Verify(pk, other, ticket))
+
+	_, otherPk, err := vrf.GenerateKey()
+	require.Nil(t, err)
+	assert.False(t, Verify(otherPk, p, ticket))
+}
+
+func TestSelectionsBounds(t *testing.T) {
+	var zero, max [vrf.OutputSize]byte
+	for i := range max {
+		max[i] = 0xff
+	}
+
+	n, err := Selections(zero, 10, 100, 10)
+	require.Nil(t, err)
+	assert.Equal(t, uint64(0), n)
+
+	n, err = Selections(max, 10, 100, 10)
+	require.Nil(t, err)
+	assert.Equal(t, uint64(10), n)
+
+	// everyone is selected for every unit of stake
+	n, err = Selections(zero, 10, 100, 100)
+	require.Nil(t, err)
+	assert.Equal(t, uint64(10), n)
+
+	n, err = Selections(max, 0, 100, 10)
+	require.Nil(t, err)
+	assert.Equal(t, uint64(0), n)
+
+	_, err = Selections(zero, 10, 0, 0)
+	assert.NotNil(t, err)
+	_, err = Selections(zero, 101, 100, 10)
+	assert.NotNil(t, err)
+	_, err = Selections(zero, 10, 100, 101)
+	assert.NotNil(t, err)
+}
+
+func TestSelectionsDistribution(t *testing.T) {
+	// with p = 0.1 and 100 units of stake, the mean is 10
+	const trials = 2000
+	var total uint64
+	for i := 0; i < trials; i++ {
+		var beta [vrf.OutputSize]byte
+		_, err := rand.Read(beta[:])
+		require.Nil(t, err)
+
+		n, err := Selections(beta, 100, 1000, 100)
+		require.Nil(t, err)
+		assert.True(t, n <= 100)
+		total += n
+	}
+
+	mean := float64(total) / trials
+	assert.InDelta(t, 10, mean, 0.5)
+}
+
+func TestSelectionsLargeStake(t *testing.T) {
+	var beta [vrf.OutputSize]byte
+	beta[0] = 0x80
+
+	// the median of B(2^40, 10^-9) is close to its mean of about 1100
+	n, err := Selections(beta, 1<<40, 1000000000000000, 1000000)
+	require.Nil(t, err)
+	assert.InDelta(t, 1100, float64(n), 5)
+}
@@ -0,0 +1,229 @@
+package stealth
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rangeproof/pedersen"
+)
+
+// TxOutput is a stealth output carrying a Pedersen commitment to its amount.
+// The blinding factor of the commitment is derived from the shared secret and
+// the amount is encrypted with it, so the receiver recovers both with the
+// view key. The commitments can be used as they are in a range proof, by
+// passing the derived blinding factors to rangeproof.ProveUint64.
+// ViewTag lets wallets skip most of the outputs they do not own, see ViewTag
+type TxOutput struct {
+	Output
+	Commitment      ristretto.Point
+	EncryptedAmount [8]byte
+	ViewTag         byte
+}
+
+// ScannedOutput is an output recognised by ScanOutputs
+type ScannedOutput struct {
+	// Position is the index of the output in the scanned slice
+	Position int
+	// Subaddress is the subaddress the output was sent to, or nil if it
+	// was sent to the main address
+	Subaddress *SubaddressIndex
+	Amount     uint64
+	Blind      ristretto.Scalar
+}
+
+// OutputBlind returns the blinding factor of the commitment of an output
+// whose shared secret is shared
+func OutputBlind(shared ristretto.Scalar) ristretto.Scalar {
+	var blind ristretto.Scalar
+	blind.Derive(append([]byte("dusk.stealth.blind"), shared.Bytes()...))
+	return blind
+}
+
+// EncryptAmount encrypts or decrypts an amount with the shared secret
+func EncryptAmount(shared ristretto.Scalar, amount [8]byte) [8]byte {
+	var mask ristretto.Scalar
+	mask.Derive(append([]byte("dusk.stealth.amount"), shared.Bytes()...))
+	m := mask.Bytes()
+
+	var res [8]byte
+	for i := range res {
+		res[i] = amount[i] ^ m[i]
+	}
+	return res
+}
+
+// NewTxOutput derives the output with the given index of a transaction whose
+// secret key is r, committing to amount
+func (addr PublicAddress) NewTxOutput(r ristretto.Scalar, index uint32, amount uint64) TxOutput {
+	var rA ristretto.Point
+	rA.ScalarMult(&addr.View, &r)
+	return newTxOutput(addr.DeriveOutput(r, index), rA, amount)
+}
+
+// NewTxOutput derives the output with the given index of a transaction whose
+// secret key is r, committing to amount
+func (sub Subaddress) NewTxOutput(r ristretto.Scalar, index uint32, amount uint64) TxOutput {
+	var rC ristretto.Point
+	rC.ScalarMult(&sub.View, &r)
+	return newTxOutput(sub.DeriveOutput(r, index), rC, amount)
+}
+
+func newTxOutput(out Output, dh ristretto.Point, amount uint64) TxOutput {
+	shared := SharedSecret(dh, out.Index)
+	var plain [8]byte
+	binary.BigEndian.PutUint64(plain[:], amount)
+
+	return TxOutput{
+		Output:          out,
+		Commitment:      commitAmount(amount, OutputBlind(shared)),
+		EncryptedAmount: EncryptAmount(shared, plain),
+		ViewTag:         ViewTag(dh, out.Index),
+	}
+}
+
+func commitAmount(amount uint64, blind ristretto.Scalar) ristretto.Point {
+	var b [32]byte
+	binary.LittleEndian.PutUint64(b[:8], amount)
+	var v ristretto.Scalar
+	v.SetBytes(&b)
+
+	return pedersen.New(nil).CommitToScalarWithBlind(v, blind).Value
+}
+
+// ScanOutputs returns the outputs which belong to the view key, either to its
+// main address or to a subaddress of table, which can be nil.
+// The outputs are grouped by transaction key and the groups are scanned in
+// parallel, each worker computing the Diffie-Hellman point once per group, as
+// the outputs of a transaction share it.
+// Outputs whose view tag does not match are skipped before any other
+// derivation, and outputs whose commitment does not match the recovered
+// amount are skipped
+func ScanOutputs(vk ViewKey, outputs []TxOutput, table *SubaddressTable) []ScannedOutput {
+	groups := groupByR(outputs)
+
+	workers := runtime.NumCPU()
+	if workers > len(groups) {
+		workers = len(groups)
+	}
+
+	results := make([]*ScannedOutput, len(outputs))
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for g := w; g < len(groups); g += workers {
+				// a * R
+				var dh ristretto.Point
+				dh.ScalarMult(&outputs[groups[g][0]].R, &vk.View)
+				for _, i := range groups[g] {
+					results[i] = scanOutput(vk, &outputs[i], dh, table)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	var scanned []ScannedOutput
+	for i := range results {
+		if results[i] != nil {
+			results[i].Position = i
+			scanned = append(scanned, *results[i])
+		}
+	}
+	return scanned
+}
+
+// groupByR returns the positions of the outputs, grouped by R
+func groupByR(outputs []TxOutput) [][]int {
+	var groups [][]int
+	index := make(map[[32]byte]int)
+
+	for i := range outputs {
+		var key [32]byte
+		outputs[i].R.BytesInto(&key)
+
+		g, ok := index[key]
+		if !ok {
+			g = len(groups)
+			index[key] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+func scanOutput(vk ViewKey, out *TxOutput, dh ristretto.Point, table *SubaddressTable) *ScannedOutput {
+	if ViewTag(dh, out.Index) != out.ViewTag {
+		return nil
+	}
+
+	shared := SharedSecret(dh, out.Index)
+
+	// D = P - Hs(a * R || i) * G
+	var D, hsG ristretto.Point
+	hsG.ScalarMultBase(&shared)
+	D.Sub(&out.P, &hsG)
+
+	res := &ScannedOutput{}
+	if !D.Equals(&vk.Spend) {
+		if table == nil {
+			return nil
+		}
+
+		var key [32]byte
+		D.BytesInto(&key)
+		idx, ok := table.keys[key]
+		if !ok {
+			return nil
+		}
+		res.Subaddress = &idx
+	}
+
+	plain := EncryptAmount(shared, out.EncryptedAmount)
+	res.Amount = binary.BigEndian.Uint64(plain[:])
+	res.Blind = OutputBlind(shared)
+
+	C := commitAmount(res.Amount, res.Blind)
+	if !C.Equals(&out.Commitment) {
+		return nil
+	}
+
+	return res
+}
+
+// Encode a TxOutput
+func (out *TxOutput) Encode(w io.Writer) error {
+	if err := out.Output.Encode(w); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, out.Commitment.Bytes()); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, out.EncryptedAmount); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, out.ViewTag)
+}
+
+// Decode a TxOutput
+func (out *TxOutput) Decode(r io.Reader) error {
+	if out == nil {
+		return errors.New("struct is nil")
+	}
+	if err := out.Output.Decode(r); err != nil {
+		return err
+	}
+	if err := readerToPoint(r, &out.Commitment); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.BigEndian, &out.EncryptedAmount); err != nil {
+		return err
+	}
+	return binary.Read(r, binary.BigEndian, &out.ViewTag)
+}
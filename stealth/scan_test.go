@@ -0,0 +1,91 @@
+package stealth
+
+import (
+	"bytes"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rangeproof"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanOutputs(t *testing.T) {
+	sk := GenerateKey()
+	vk := sk.ViewKey()
+	table := vk.NewSubaddressTable(1, 5)
+	idx := SubaddressIndex{Account: 0, Index: 3}
+
+	other := GenerateKey().PublicAddress()
+
+	// a transaction paying the main address, a subaddress and someone else
+	var r ristretto.Scalar
+	r.Rand()
+	outputs := []TxOutput{
+		other.NewTxOutput(r, 0, 5),
+		sk.PublicAddress().NewTxOutput(r, 1, 1000),
+		other.NewTxOutput(r, 2, 7),
+	}
+	var r2 ristretto.Scalar
+	r2.Rand()
+	outputs = append(outputs, sk.Subaddress(idx).NewTxOutput(r2, 0, 42))
+
+	// the Diffie-Hellman point is computed once per transaction
+	assert.Equal(t, [][]int{{0, 1, 2}, {3}}, groupByR(outputs))
+
+	scanned := ScanOutputs(vk, outputs, table)
+	require.Len(t, scanned, 2)
+
+	assert.Equal(t, 1, scanned[0].Position)
+	assert.Nil(t, scanned[0].Subaddress)
+	assert.Equal(t, uint64(1000), scanned[0].Amount)
+
+	assert.Equal(t, 3, scanned[1].Position)
+	require.NotNil(t, scanned[1].Subaddress)
+	assert.Equal(t, idx, *scanned[1].Subaddress)
+	assert.Equal(t, uint64(42), scanned[1].Amount)
+
+	// without the table only the main address is found
+	assert.Len(t, ScanOutputs(vk, outputs, nil), 1)
+
+	// a tampered amount is skipped
+	outputs[1].EncryptedAmount[7] ^= 1
+	assert.Len(t, ScanOutputs(vk, outputs, table), 1)
+
+	assert.Empty(t, ScanOutputs(vk, nil, table))
+}
+
+func TestScannedBlindsOpenRangeProofCommitments(t *testing.T) {
+	sk := GenerateKey()
+	addr := sk.PublicAddress()
+
+	var r ristretto.Scalar
+	r.Rand()
+	amounts := []uint64{10, 20}
+	outputs := []TxOutput{addr.NewTxOutput(r, 0, amounts[0]), addr.NewTxOutput(r, 1, amounts[1])}
+
+	scanned := ScanOutputs(sk.ViewKey(), outputs, nil)
+	require.Len(t, scanned, 2)
+
+	blinds := []ristretto.Scalar{scanned[0].Blind, scanned[1].Blind}
+	p, err := rangeproof.ProveUint64(amounts, blinds)
+	require.Nil(t, err)
+	for i := range outputs {
+		assert.True(t, p.V[i].Value.Equals(&outputs[i].Commitment))
+	}
+}
+
+func TestTxOutputEncodeDecode(t *testing.T) {
+	sk := GenerateKey()
+	var r ristretto.Scalar
+	r.Rand()
+	out := sk.PublicAddress().NewTxOutput(r, 0, 99)
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, out.Encode(buf))
+	var decoded TxOutput
+	require.Nil(t, decoded.Decode(buf))
+	assert.Equal(t, out.EncryptedAmount, decoded.EncryptedAmount)
+	assert.Equal(t, out.ViewTag, decoded.ViewTag)
+	assert.True(t, decoded.Commitment.Equals(&out.Commitment))
+}
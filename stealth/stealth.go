@@ -0,0 +1,201 @@
+// Package stealth implements dual-key stealth addresses over Ristretto.
+// A receiver publishes a view key A = a * G and a spend key B = b * G. For
+// every output, the sender picks r, publishes R = r * G and derives the one
+// time key P = Hs(r * A || i) * G + B. Only the receiver recognises P, by
+// computing the same shared secret as Hs(a * R || i), and only the owner of
+// b can spend it, with the one time secret key x = Hs(a * R || i) + b
+package stealth
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// SecretKey holds the private view and spend keys
+type SecretKey struct {
+	View  ristretto.Scalar
+	Spend ristretto.Scalar
+}
+
+// PublicAddress is the address senders derive outputs from
+type PublicAddress struct {
+	View  ristretto.Point
+	Spend ristretto.Point
+}
+
+// ViewKey holds the private view key and the public spend key. It lets a
+// wallet find its outputs without being able to spend them
+type ViewKey struct {
+	View  ristretto.Scalar
+	Spend ristretto.Point
+}
+
+// Output is a stealth output, as published on chain
+type Output struct {
+	// R is the public key of the transaction, R = r * G
+	R ristretto.Point
+	// P is the one time public key of the output
+	P ristretto.Point
+	// Index is the position of the output in the transaction
+	Index uint32
+}
+
+// GenerateKey returns a random SecretKey
+func GenerateKey() *SecretKey {
+	sk := &SecretKey{}
+	rng.Scalar(&sk.View)
+	rng.Scalar(&sk.Spend)
+	return sk
+}
+
+// PublicAddress returns the public address of the key
+func (sk *SecretKey) PublicAddress() PublicAddress {
+	var addr PublicAddress
+	addr.View.ScalarMultBase(&sk.View)
+	addr.Spend.ScalarMultBase(&sk.Spend)
+	return addr
+}
+
+// ViewKey returns the view key of the key
+func (sk *SecretKey) ViewKey() ViewKey {
+	vk := ViewKey{View: sk.View}
+	vk.Spend.ScalarMultBase(&sk.Spend)
+	return vk
+}
+
+// DeriveOutput returns the output with the given index of a transaction
+// whose secret key is r. The outputs of a transaction share r
+func (addr PublicAddress) DeriveOutput(r ristretto.Scalar, index uint32) Output {
+	out := Output{Index: index}
+	out.R.ScalarMultBase(&r)
+
+	// r * A
+	var rA ristretto.Point
+	rA.ScalarMult(&addr.View, &r)
+
+	out.P = oneTimeKey(SharedSecret(rA, index), addr.Spend)
+	return out
+}
+
+// NewOutput derives an output with a fresh transaction key. It returns the
+// output and the transaction secret key
+func (addr PublicAddress) NewOutput(index uint32) (Output, ristretto.Scalar) {
+	var r ristretto.Scalar
+	rng.Scalar(&r)
+	return addr.DeriveOutput(r, index), r
+}
+
+// Owns returns true if the output was sent to the address of the key
+func (vk ViewKey) Owns(out Output) bool {
+	P := oneTimeKey(vk.sharedSecret(out), vk.Spend)
+	return P.Equals(&out.P)
+}
+
+// Owns returns true if the output was sent to the address of the key
+func (sk *SecretKey) Owns(out Output) bool {
+	return sk.ViewKey().Owns(out)
+}
+
+// OneTimeKey returns the secret key x of an owned output, s.t. P = x * G
+func (sk *SecretKey) OneTimeKey(out Output) (ristretto.Scalar, error) {
+	vk := sk.ViewKey()
+	if !vk.Owns(out) {
+		return ristretto.Scalar{}, errors.New("output does not belong to this key")
+	}
+
+	var x ristretto.Scalar
+	hs := vk.sharedSecret(out)
+	x.Add(&hs, &sk.Spend)
+	return x, nil
+}
+
+func (vk ViewKey) sharedSecret(out Output) ristretto.Scalar {
+	// a * R = r * A
+	var aR ristretto.Point
+	aR.ScalarMult(&out.R, &vk.View)
+	return SharedSecret(aR, out.Index)
+}
+
+// SharedSecret hashes the Diffie-Hellman point shared by the sender and the
+// receiver, together with the output index, to a scalar
+func SharedSecret(dh ristretto.Point, index uint32) ristretto.Scalar {
+	buf := make([]byte, 0, 12+32+4)
+	buf = append(buf, []byte("dusk.stealth")...)
+	buf = append(buf, dh.Bytes()...)
+	var idx [4]byte
+	binary.BigEndian.PutUint32(idx[:], index)
+	buf = append(buf, idx[:]...)
+
+	var hs ristretto.Scalar
+	hs.Derive(buf)
+	return hs
+}
+
+// oneTimeKey returns hs * G + B
+func oneTimeKey(hs ristretto.Scalar, B ristretto.Point) ristretto.Point {
+	var P ristretto.Point
+	P.ScalarMultBase(&hs)
+	P.Add(&P, &B)
+	return P
+}
+
+// Encode a PublicAddress
+func (addr *PublicAddress) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, addr.View.Bytes()); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, addr.Spend.Bytes())
+}
+
+// Decode a PublicAddress
+func (addr *PublicAddress) Decode(r io.Reader) error {
+	if addr == nil {
+		return errors.New("struct is nil")
+	}
+	if err := readerToPoint(r, &addr.View); err != nil {
+		return err
+	}
+	return readerToPoint(r, &addr.Spend)
+}
+
+// Encode an Output
+func (out *Output) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, out.R.Bytes()); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, out.P.Bytes()); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, out.Index)
+}
+
+// Decode an Output
+func (out *Output) Decode(r io.Reader) error {
+	if out == nil {
+		return errors.New("struct is nil")
+	}
+	if err := readerToPoint(r, &out.R); err != nil {
+		return err
+	}
+	if err := readerToPoint(r, &out.P); err != nil {
+		return err
+	}
+	return binary.Read(r, binary.BigEndian, &out.Index)
+}
+
+func readerToPoint(r io.Reader, p *ristretto.Point) error {
+	var x [32]byte
+	err := binary.Read(r, binary.BigEndian, &x)
+	if err != nil {
+		return err
+	}
+	ok := p.SetBytes(&x)
+	if !ok {
+		return errors.New("point not encodable")
+	}
+	return nil
+}
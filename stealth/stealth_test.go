@@ -0,0 +1,68 @@
+package stealth
+
+import (
+	"bytes"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputOwnership(t *testing.T) {
+	sk := GenerateKey()
+	addr := sk.PublicAddress()
+
+	out, _ := addr.NewOutput(3)
+	assert.True(t, sk.Owns(out))
+	assert.True(t, sk.ViewKey().Owns(out))
+
+	x, err := sk.OneTimeKey(out)
+	require.Nil(t, err)
+	var P ristretto.Point
+	P.ScalarMultBase(&x)
+	assert.True(t, P.Equals(&out.P))
+
+	// another wallet does not recognise the output
+	other := GenerateKey()
+	assert.False(t, other.Owns(out))
+	_, err = other.OneTimeKey(out)
+	assert.NotNil(t, err)
+
+	// the index is bound to the one time key
+	out.Index++
+	assert.False(t, sk.Owns(out))
+}
+
+func TestOutputsAreUnlinkable(t *testing.T) {
+	addr := GenerateKey().PublicAddress()
+
+	var r ristretto.Scalar
+	r.Rand()
+	out0 := addr.DeriveOutput(r, 0)
+	out1 := addr.DeriveOutput(r, 1)
+
+	assert.True(t, out0.R.Equals(&out1.R))
+	assert.False(t, out0.P.Equals(&out1.P))
+	assert.False(t, out0.P.Equals(&addr.Spend))
+}
+
+func TestEncodeDecode(t *testing.T) {
+	sk := GenerateKey()
+	addr := sk.PublicAddress()
+	out, _ := addr.NewOutput(7)
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, addr.Encode(buf))
+	require.Nil(t, out.Encode(buf))
+
+	var decodedAddr PublicAddress
+	require.Nil(t, decodedAddr.Decode(buf))
+	var decodedOut Output
+	require.Nil(t, decodedOut.Decode(buf))
+
+	assert.True(t, decodedAddr.View.Equals(&addr.View))
+	assert.True(t, decodedAddr.Spend.Equals(&addr.Spend))
+	assert.Equal(t, out.Index, decodedOut.Index)
+	assert.True(t, sk.Owns(decodedOut))
+}
@@ -0,0 +1,149 @@
+package stealth
+
+import (
+	"encoding/binary"
+	"errors"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// SubaddressIndex identifies a subaddress of a wallet
+type SubaddressIndex struct {
+	Account uint32
+	Index   uint32
+}
+
+// Subaddress is a receiving address derived from the wallet key for an
+// account and an index. Subaddresses of the same wallet cannot be linked
+// together, nor to the main address.
+// For subaddress (m, D = B + m * G, C = a * D), the sender publishes R = r * D
+// instead of r * G and derives P = Hs(r * C || i) * G + D
+type Subaddress struct {
+	PublicAddress
+}
+
+// subaddressSecret returns m = Hs(a || account || index)
+func subaddressSecret(view ristretto.Scalar, idx SubaddressIndex) ristretto.Scalar {
+	buf := make([]byte, 0, 15+32+8)
+	buf = append(buf, []byte("dusk.subaddress")...)
+	buf = append(buf, view.Bytes()...)
+	var b [8]byte
+	binary.BigEndian.PutUint32(b[:4], idx.Account)
+	binary.BigEndian.PutUint32(b[4:], idx.Index)
+	buf = append(buf, b[:]...)
+
+	var m ristretto.Scalar
+	m.Derive(buf)
+	return m
+}
+
+// subaddressSpend returns D = B + m * G
+func (vk ViewKey) subaddressSpend(idx SubaddressIndex) ristretto.Point {
+	m := subaddressSecret(vk.View, idx)
+	var D ristretto.Point
+	D.ScalarMultBase(&m)
+	D.Add(&D, &vk.Spend)
+	return D
+}
+
+// Subaddress returns the subaddress with the given index
+func (vk ViewKey) Subaddress(idx SubaddressIndex) Subaddress {
+	var sub Subaddress
+	sub.Spend = vk.subaddressSpend(idx)
+	sub.View.ScalarMult(&sub.Spend, &vk.View)
+	return sub
+}
+
+// Subaddress returns the subaddress with the given index
+func (sk *SecretKey) Subaddress(idx SubaddressIndex) Subaddress {
+	return sk.ViewKey().Subaddress(idx)
+}
+
+// DeriveOutput returns the output with the given index of a transaction
+// whose secret key is r
+func (sub Subaddress) DeriveOutput(r ristretto.Scalar, index uint32) Output {
+	out := Output{Index: index}
+
+	// R = r * D
+	out.R.ScalarMult(&sub.Spend, &r)
+
+	// r * C
+	var rC ristretto.Point
+	rC.ScalarMult(&sub.View, &r)
+
+	out.P = oneTimeKey(SharedSecret(rC, index), sub.Spend)
+	return out
+}
+
+// NewOutput derives an output with a fresh transaction key. It returns the
+// output and the transaction secret key
+func (sub Subaddress) NewOutput(index uint32) (Output, ristretto.Scalar) {
+	var r ristretto.Scalar
+	rng.Scalar(&r)
+	return sub.DeriveOutput(r, index), r
+}
+
+// SubaddressTable recognises the outputs sent to any of the subaddresses it
+// holds, at the cost of a single lookup per output
+type SubaddressTable struct {
+	vk   ViewKey
+	keys map[[32]byte]SubaddressIndex
+}
+
+// NewSubaddressTable returns a table holding the first indices subaddresses
+// of the first accounts
+func (vk ViewKey) NewSubaddressTable(accounts, indices uint32) *SubaddressTable {
+	t := &SubaddressTable{
+		vk:   vk,
+		keys: make(map[[32]byte]SubaddressIndex),
+	}
+	for a := uint32(0); a < accounts; a++ {
+		for i := uint32(0); i < indices; i++ {
+			t.Add(SubaddressIndex{Account: a, Index: i})
+		}
+	}
+	return t
+}
+
+// Add adds a subaddress to the table
+func (t *SubaddressTable) Add(idx SubaddressIndex) {
+	D := t.vk.subaddressSpend(idx)
+	var key [32]byte
+	D.BytesInto(&key)
+	t.keys[key] = idx
+}
+
+// Lookup returns the index of the subaddress the output was sent to.
+// As D = P - Hs(a * R || i) * G, a single lookup covers all the subaddresses
+func (t *SubaddressTable) Lookup(out Output) (SubaddressIndex, bool) {
+	hs := t.vk.sharedSecret(out)
+
+	var D, hsG ristretto.Point
+	hsG.ScalarMultBase(&hs)
+	D.Sub(&out.P, &hsG)
+
+	var key [32]byte
+	D.BytesInto(&key)
+	idx, ok := t.keys[key]
+	return idx, ok
+}
+
+// SubaddressOneTimeKey returns the secret key x = Hs(a * R || i) + b + m of
+// an output sent to the subaddress idx
+func (sk *SecretKey) SubaddressOneTimeKey(out Output, idx SubaddressIndex) (ristretto.Scalar, error) {
+	vk := sk.ViewKey()
+	hs := vk.sharedSecret(out)
+	m := subaddressSecret(sk.View, idx)
+
+	var x ristretto.Scalar
+	x.Add(&hs, &sk.Spend)
+	x.Add(&x, &m)
+
+	var P ristretto.Point
+	P.ScalarMultBase(&x)
+	if !P.Equals(&out.P) {
+		return ristretto.Scalar{}, errors.New("output does not belong to this subaddress")
+	}
+	return x, nil
+}
@@ -0,0 +1,54 @@
+package stealth
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubaddress(t *testing.T) {
+	sk := GenerateKey()
+	table := sk.ViewKey().NewSubaddressTable(2, 10)
+
+	idx := SubaddressIndex{Account: 1, Index: 7}
+	sub := sk.Subaddress(idx)
+
+	out, _ := sub.NewOutput(2)
+
+	found, ok := table.Lookup(out)
+	require.True(t, ok)
+	assert.Equal(t, idx, found)
+
+	x, err := sk.SubaddressOneTimeKey(out, idx)
+	require.Nil(t, err)
+	var P ristretto.Point
+	P.ScalarMultBase(&x)
+	assert.True(t, P.Equals(&out.P))
+
+	_, err = sk.SubaddressOneTimeKey(out, SubaddressIndex{Account: 1, Index: 6})
+	assert.NotNil(t, err)
+
+	// outputs to a subaddress outside of the table are not found
+	out, _ = sk.Subaddress(SubaddressIndex{Account: 5}).NewOutput(0)
+	_, ok = table.Lookup(out)
+	assert.False(t, ok)
+
+	// nor are outputs to another wallet
+	out, _ = GenerateKey().Subaddress(idx).NewOutput(0)
+	_, ok = table.Lookup(out)
+	assert.False(t, ok)
+}
+
+func TestSubaddressesAreUnlinkable(t *testing.T) {
+	sk := GenerateKey()
+	main := sk.PublicAddress()
+	sub0 := sk.Subaddress(SubaddressIndex{Account: 0, Index: 0})
+	sub1 := sk.Subaddress(SubaddressIndex{Account: 0, Index: 1})
+
+	assert.False(t, sub0.Spend.Equals(&main.Spend))
+	assert.False(t, sub0.View.Equals(&main.View))
+	assert.False(t, sub0.Spend.Equals(&sub1.Spend))
+	assert.False(t, sub0.View.Equals(&sub1.View))
+}
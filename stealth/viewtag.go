@@ -0,0 +1,49 @@
+package stealth
+
+import (
+	"encoding/binary"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/hash"
+)
+
+// A view tag is the first byte of a hash of the Diffie-Hellman point and the
+// output index, published with the output. A wallet computes the tag of
+// every output from a * R, which it needs anyway, and only derives the one
+// time key and opens the commitment of the outputs whose tag matches: about
+// one output out of 256 that is not its own. The tag is a different hash of
+// the same secret as the one time key, so it tells nothing to whoever does
+// not hold the view key, beyond the byte itself
+
+// ViewTag returns the view tag of the output with the given index, from the
+// Diffie-Hellman point shared by the sender and the receiver
+func ViewTag(dh ristretto.Point, index uint32) byte {
+	var idx [4]byte
+	binary.BigEndian.PutUint32(idx[:], index)
+	return hash.Sha3256WithDomain("dusk.stealth.viewtag", dh.Bytes(), idx[:])[0]
+}
+
+// ViewTag returns the view tag of the output with the given index of a
+// transaction whose secret key is r
+func (addr PublicAddress) ViewTag(r ristretto.Scalar, index uint32) byte {
+	var rA ristretto.Point
+	rA.ScalarMult(&addr.View, &r)
+	return ViewTag(rA, index)
+}
+
+// ViewTag returns the view tag of the output with the given index of a
+// transaction whose secret key is r
+func (sub Subaddress) ViewTag(r ristretto.Scalar, index uint32) byte {
+	var rC ristretto.Point
+	rC.ScalarMult(&sub.View, &r)
+	return ViewTag(rC, index)
+}
+
+// MatchesViewTag returns false if the output certainly does not belong to
+// the view key, to its main address or to any of its subaddresses. Outputs
+// that match still have to be checked with Owns or ScanOutputs
+func (vk ViewKey) MatchesViewTag(out *TxOutput) bool {
+	var aR ristretto.Point
+	aR.ScalarMult(&out.R, &vk.View)
+	return ViewTag(aR, out.Index) == out.ViewTag
+}
@@ -0,0 +1,43 @@
+package stealth
+
+import (
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestViewTags(t *testing.T) {
+	sk := GenerateKey()
+	vk := sk.ViewKey()
+	idx := SubaddressIndex{Account: 1, Index: 2}
+
+	var r ristretto.Scalar
+	r.Rand()
+	out := sk.PublicAddress().NewTxOutput(r, 4, 10)
+	assert.Equal(t, sk.PublicAddress().ViewTag(r, 4), out.ViewTag)
+	assert.True(t, vk.MatchesViewTag(&out))
+
+	sub := sk.Subaddress(idx).NewTxOutput(r, 0, 10)
+	assert.Equal(t, sk.Subaddress(idx).ViewTag(r, 0), sub.ViewTag)
+	assert.True(t, vk.MatchesViewTag(&sub))
+
+	// most outputs of other wallets are filtered out by the tag alone
+	other := GenerateKey().PublicAddress()
+	matches := 0
+	for i := uint32(0); i < 512; i++ {
+		o := other.NewTxOutput(r, i, 1)
+		if vk.MatchesViewTag(&o) {
+			matches++
+		}
+	}
+	assert.True(t, matches < 16)
+
+	// a wrong tag hides the output from the scan
+	out.ViewTag ^= 1
+	assert.False(t, vk.MatchesViewTag(&out))
+	assert.Empty(t, ScanOutputs(vk, []TxOutput{out}, nil))
+	out.ViewTag ^= 1
+	require.Len(t, ScanOutputs(vk, []TxOutput{out}, nil), 1)
+}
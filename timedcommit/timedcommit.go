@@ -0,0 +1,286 @@
+// Package timedcommit implements timed commitments: a message is sealed
+// now, and can be opened by anybody after t sequential squarings in an RSA
+// group, with no help from the committer. The committer, who knows the
+// factorization of the modulus, can open it at once instead. Sealed bid
+// auctions use it so that bids are revealed even if bidders walk away.
+//
+// The committer draws N = p * q and derives the key of the message from
+// y = g^(2^t) mod N, g being hashed from the commitment parameters. Knowing
+// p and q, it computes y with a single exponentiation modulo phi(N);
+// everybody else needs t squarings.
+//
+// Early openings reveal p, which lets anybody recompute y quickly. Forced
+// openings come with a Wesolowski proof of y, so that the work is done
+// once. Since the ciphertext is key committing, an opening yields the
+// message the commitment was made to, or fails for everybody
+package timedcommit
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/dusk-network/dusk-crypto/cipher"
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/vdf"
+)
+
+const (
+	// ModulusBits is the recommended size of the moduli
+	ModulusBits = 2048
+	// MinModulusBits is the smallest modulus accepted
+	MinModulusBits = 512
+	// MaxModulusBits is the largest modulus accepted
+	MaxModulusBits = 8192
+	// MaxMessageSize bounds the size of committed messages
+	MaxMessageSize = 1 << 20
+)
+
+var (
+	// ErrMalformed is returned when the ciphertext of a commitment does not
+	// open under the key derived from its puzzle. The committer is to blame
+	ErrMalformed = errors.New("timedcommit: malformed commitment")
+	// ErrTrapdoor is returned for an early opening that does not factor N
+	ErrTrapdoor = errors.New("timedcommit: invalid trapdoor")
+	// ErrSolution is returned for a forced opening with an invalid proof
+	ErrSolution = errors.New("timedcommit: invalid solution")
+)
+
+// Commitment is a timed commitment to a message
+type Commitment struct {
+	// N is the modulus of the puzzle
+	N *big.Int
+	// T is the number of squarings needed to open the commitment
+	T uint64
+	// Ciphertext is the message sealed under the key derived from the puzzle
+	Ciphertext []byte
+}
+
+// Trapdoor is the factorization of N, which the committer keeps until it
+// opens the commitment
+type Trapdoor struct {
+	P, Q *big.Int
+}
+
+// Solution is the solution of the puzzle of a commitment, found by
+// squaring, with the proof that it is correct
+type Solution struct {
+	Y     *big.Int
+	Proof *big.Int
+}
+
+// Commit commits to msg, so that it can be opened after t squarings
+// modulo a fresh modulus of the given size
+func Commit(msg []byte, t uint64, bits int) (*Commitment, *Trapdoor, error) {
+	if bits < MinModulusBits || bits > MaxModulusBits {
+		return nil, nil, errors.New("invalid modulus size")
+	}
+	if len(msg) > MaxMessageSize {
+		return nil, nil, errors.New("message too long")
+	}
+
+	td, err := generateTrapdoor(bits)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := &Commitment{N: new(big.Int).Mul(td.P, td.Q), T: t}
+
+	y := c.solveWithTrapdoor(td)
+	aead, err := cipher.New(cipher.ChaCha20Poly1305, c.key(y))
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.Ciphertext, err = aead.SealRandom(msg, c.header()); err != nil {
+		return nil, nil, err
+	}
+	return c, td, nil
+}
+
+func generateTrapdoor(bits int) (*Trapdoor, error) {
+	for {
+		p, err := rand.Prime(rng.Reader, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(rng.Reader, bits-bits/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) != 0 {
+			return &Trapdoor{P: p, Q: q}, nil
+		}
+	}
+}
+
+// header returns the parameters the ciphertext is bound to
+func (c *Commitment) header() []byte {
+	var t [8]byte
+	binary.BigEndian.PutUint64(t[:], c.T)
+	return hash.Sha3256WithDomain("dusk.timedcommit", c.N.Bytes(), t[:])
+}
+
+// base returns g, the element squared by the puzzle
+func (c *Commitment) base() *big.Int {
+	return vdf.NewGroup(c.N).HashToElement(c.header())
+}
+
+func (c *Commitment) key(y *big.Int) []byte {
+	return hash.Sha3256WithDomain("dusk.timedcommit.key", c.N.Bytes(), y.Bytes())
+}
+
+// solveWithTrapdoor returns g^(2^T) computed as g^(2^T mod phi(N)),
+// represented like the vdf package does, as the smaller of y and N - y
+func (c *Commitment) solveWithTrapdoor(td *Trapdoor) *big.Int {
+	one := big.NewInt(1)
+	phi := new(big.Int).Mul(new(big.Int).Sub(td.P, one), new(big.Int).Sub(td.Q, one))
+	e := new(big.Int).Exp(big.NewInt(2), new(big.Int).SetUint64(c.T), phi)
+
+	y := new(big.Int).Exp(c.base(), e, c.N)
+	if neg := new(big.Int).Sub(c.N, y); neg.Cmp(y) < 0 {
+		return neg
+	}
+	return y
+}
+
+func (c *Commitment) check() error {
+	if c.N == nil || c.N.BitLen() < MinModulusBits || c.N.BitLen() > MaxModulusBits {
+		return errors.New("invalid modulus")
+	}
+	if len(c.Ciphertext) < cipher.NonceSize+cipher.Overhead {
+		return errors.New("ciphertext too short")
+	}
+	return nil
+}
+
+func (c *Commitment) open(y *big.Int) ([]byte, error) {
+	aead, err := cipher.New(cipher.ChaCha20Poly1305, c.key(y))
+	if err != nil {
+		return nil, err
+	}
+	msg, err := aead.OpenRandom(c.Ciphertext, c.header())
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	return msg, nil
+}
+
+// Open opens the commitment with its trapdoor. The committer publishes the
+// trapdoor to open the commitment early
+func (c *Commitment) Open(td *Trapdoor) ([]byte, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	if td == nil || td.P == nil || td.Q == nil || new(big.Int).Mul(td.P, td.Q).Cmp(c.N) != 0 {
+		return nil, ErrTrapdoor
+	}
+
+	// phi(N) is only (p - 1)(q - 1) if both factors are prime. A committer
+	// lying about phi(N) would derive a key forced openings cannot find
+	if td.P.Cmp(big.NewInt(1)) <= 0 || td.Q.Cmp(big.NewInt(1)) <= 0 ||
+		!td.P.ProbablyPrime(20) || !td.Q.ProbablyPrime(20) {
+		return nil, ErrTrapdoor
+	}
+	return c.open(c.solveWithTrapdoor(td))
+}
+
+// ForceOpen opens the commitment without the trapdoor, by squaring T times,
+// and proves the solution so that others can open it at once with
+// OpenWithSolution. The proof takes about as long as the squarings. A
+// commitment that cannot be opened returns ErrMalformed along with the
+// solution proving it
+func (c *Commitment) ForceOpen() ([]byte, *Solution, error) {
+	if err := c.check(); err != nil {
+		return nil, nil, err
+	}
+
+	g := vdf.NewGroup(c.N)
+	x := c.base()
+	y, err := g.Evaluate(x, c.T)
+	if err != nil {
+		return nil, nil, err
+	}
+	pi, err := g.Prove(x, y, c.T)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sol := &Solution{Y: y, Proof: pi}
+	msg, err := c.open(y)
+	return msg, sol, err
+}
+
+// OpenWithSolution opens the commitment with a solution found by ForceOpen
+func (c *Commitment) OpenWithSolution(sol *Solution) ([]byte, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	if sol == nil || sol.Y == nil || sol.Proof == nil ||
+		!vdf.NewGroup(c.N).Verify(c.base(), sol.Y, sol.Proof, c.T) {
+		return nil, ErrSolution
+	}
+	return c.open(sol.Y)
+}
+
+// Encode a Commitment
+func (c *Commitment) Encode(w io.Writer) error {
+	if err := writeBytes(w, c.N.Bytes()); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, c.T); err != nil {
+		return err
+	}
+	return writeBytes(w, c.Ciphertext)
+}
+
+// Decode a Commitment
+func (c *Commitment) Decode(r io.Reader) error {
+	if c == nil {
+		return errors.New("struct is nil")
+	}
+
+	n, err := readBytes(r, MaxModulusBits/8)
+	if err != nil {
+		return err
+	}
+	var t uint64
+	if err := binary.Read(r, binary.BigEndian, &t); err != nil {
+		return err
+	}
+	ct, err := readBytes(r, MaxMessageSize+cipher.NonceSize+cipher.Overhead)
+	if err != nil {
+		return err
+	}
+
+	dec := Commitment{N: new(big.Int).SetBytes(n), T: t, Ciphertext: ct}
+	if err := dec.check(); err != nil {
+		return err
+	}
+	*c = dec
+	return nil
+}
+
+func writeBytes(w io.Writer, b []byte) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(b))); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func readBytes(r io.Reader, max int) ([]byte, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+	if int(n) > max {
+		return nil, errors.New("field too long")
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,92 @@
+package timedcommit
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/cipher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testT = 2000
+
+func TestOpen(t *testing.T) {
+	msg := []byte("bid: 1000 DUSK")
+	c, td, err := Commit(msg, testT, MinModulusBits)
+	require.NoError(t, err)
+
+	opened, err := c.Open(td)
+	require.NoError(t, err)
+	assert.Equal(t, msg, opened)
+
+	// A wrong factorization
+	_, err = c.Open(&Trapdoor{P: big.NewInt(1), Q: c.N})
+	assert.Equal(t, ErrTrapdoor, err)
+	_, err = c.Open(&Trapdoor{P: td.P, Q: new(big.Int).Add(td.Q, big.NewInt(1))})
+	assert.Equal(t, ErrTrapdoor, err)
+}
+
+func TestForceOpen(t *testing.T) {
+	msg := []byte("bid: 42 DUSK")
+	c, td, err := Commit(msg, testT, MinModulusBits)
+	require.NoError(t, err)
+
+	opened, sol, err := c.ForceOpen()
+	require.NoError(t, err)
+	assert.Equal(t, msg, opened)
+
+	// The trapdoor and the squarings find the same solution
+	assert.Equal(t, 0, c.solveWithTrapdoor(td).Cmp(sol.Y))
+
+	opened, err = c.OpenWithSolution(sol)
+	require.NoError(t, err)
+	assert.Equal(t, msg, opened)
+
+	bad := &Solution{Y: new(big.Int).Add(sol.Y, big.NewInt(1)), Proof: sol.Proof}
+	_, err = c.OpenWithSolution(bad)
+	assert.Equal(t, ErrSolution, err)
+}
+
+func TestMalformed(t *testing.T) {
+	c, _, err := Commit([]byte("bid"), testT, MinModulusBits)
+	require.NoError(t, err)
+
+	// A ciphertext under a key unrelated to the puzzle
+	aead, err := cipher.New(cipher.ChaCha20Poly1305, make([]byte, cipher.KeySize))
+	require.NoError(t, err)
+	c.Ciphertext, err = aead.SealRandom([]byte("bid"), c.header())
+	require.NoError(t, err)
+
+	_, sol, err := c.ForceOpen()
+	assert.Equal(t, ErrMalformed, err)
+	_, err = c.OpenWithSolution(sol)
+	assert.Equal(t, ErrMalformed, err)
+}
+
+func TestCommitParameters(t *testing.T) {
+	_, _, err := Commit(nil, testT, MinModulusBits-1)
+	assert.Error(t, err)
+	_, _, err = Commit(make([]byte, MaxMessageSize+1), testT, MinModulusBits)
+	assert.Error(t, err)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	msg := []byte("sealed")
+	c, td, err := Commit(msg, testT, MinModulusBits)
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, c.Encode(buf))
+	var dec Commitment
+	require.NoError(t, dec.Decode(buf))
+	assert.Equal(t, c, &dec)
+
+	opened, err := dec.Open(td)
+	require.NoError(t, err)
+	assert.Equal(t, msg, opened)
+
+	var nilC *Commitment
+	assert.Error(t, nilC.Decode(buf))
+}
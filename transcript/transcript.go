@@ -0,0 +1,158 @@
+// Package transcript implements a Fiat-Shamir transcript on top of a
+// cSHAKE256 sponge. Provers and verifiers absorb the same labeled messages
+// in the same order and squeeze the same challenges, which are bound to
+// everything absorbed before them.
+//
+// Every operation absorbs an operation tag, its label and the length of its
+// data before the data itself, so that transcripts made of different
+// operations, labels or message boundaries never produce the same challenge
+package transcript
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	opInit byte = iota
+	opAppend
+	opChallenge
+	opFork
+	opRNG
+)
+
+// Transcript is the state of a Fiat-Shamir transcript
+type Transcript struct {
+	state sha3.ShakeHash
+	trace io.Writer
+}
+
+// New returns a transcript for the protocol identified by label
+func New(label string) *Transcript {
+	t := &Transcript{state: sha3.NewCShake256(nil, []byte("dusk.transcript"))}
+	t.absorb(opInit, label, nil)
+	return t
+}
+
+func (t *Transcript) absorb(op byte, label string, data []byte) {
+	absorb(t.state, op, label, data)
+}
+
+// absorb writes the operation tag, the length prefixed label and the length
+// prefixed data to state
+func absorb(state sha3.ShakeHash, op byte, label string, data []byte) {
+	var l [8]byte
+	_, _ = state.Write([]byte{op})
+
+	binary.BigEndian.PutUint64(l[:], uint64(len(label)))
+	_, _ = state.Write(l[:])
+	_, _ = state.Write([]byte(label))
+
+	binary.BigEndian.PutUint64(l[:], uint64(len(data)))
+	_, _ = state.Write(l[:])
+	_, _ = state.Write(data)
+}
+
+// Append absorbs a labeled message
+func (t *Transcript) Append(label string, msg []byte) {
+	if t.trace != nil {
+		fmt.Fprintf(t.trace, "append    %s len=%d %x\n", label, len(msg), msg)
+	}
+	t.absorb(opAppend, label, msg)
+}
+
+// SetTrace makes the transcript write a line to w for each message appended
+// and each challenge squeezed, along with their labels. Running the prover
+// and the verifier with tracing enabled shows the first entry on which two
+// implementations diverge. Clones and forks inherit the writer, the output of
+// RNG is never traced. Passing nil disables tracing.
+// The line format is:
+//
+//	append    <label> len=<length> <hex message>
+//	challenge <label> <hex challenge>
+//	fork      <label>
+func (t *Transcript) SetTrace(w io.Writer) {
+	t.trace = w
+}
+
+// AppendUint64 absorbs a labeled integer
+func (t *Transcript) AppendUint64(label string, n uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	t.Append(label, b[:])
+}
+
+// AppendPoint absorbs a labeled Ristretto point
+func (t *Transcript) AppendPoint(label string, p ristretto.Point) {
+	t.Append(label, p.Bytes())
+}
+
+// AppendScalar absorbs a labeled Ristretto scalar
+func (t *Transcript) AppendScalar(label string, s ristretto.Scalar) {
+	t.Append(label, s.Bytes())
+}
+
+// ChallengeBytes squeezes n bytes bound to the transcript so far. The
+// request itself is absorbed, so that successive challenges differ
+func (t *Transcript) ChallengeBytes(label string, n int) []byte {
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(n))
+	t.absorb(opChallenge, label, l[:])
+
+	out := make([]byte, n)
+	_, _ = t.state.Clone().Read(out)
+	if t.trace != nil {
+		fmt.Fprintf(t.trace, "challenge %s %x\n", label, out)
+	}
+	return out
+}
+
+// ChallengeScalar squeezes a uniform Ristretto scalar
+func (t *Transcript) ChallengeScalar(label string) ristretto.Scalar {
+	var wide [64]byte
+	copy(wide[:], t.ChallengeBytes(label, 64))
+
+	var s ristretto.Scalar
+	s.SetReduced(&wide)
+	return s
+}
+
+// Clone returns an independent copy of the transcript. The copy and the
+// original produce the same challenges as long as they absorb the same
+// messages
+func (t *Transcript) Clone() *Transcript {
+	return &Transcript{state: t.state.Clone(), trace: t.trace}
+}
+
+// Fork returns a copy of the transcript bound to label, e.g. to run
+// subprotocols for several parties from a common prefix. Forks with
+// different labels diverge, and no fork collides with the original
+func (t *Transcript) Fork(label string) *Transcript {
+	f := t.Clone()
+	if f.trace != nil {
+		fmt.Fprintf(f.trace, "fork      %s\n", label)
+	}
+	f.absorb(opFork, label, nil)
+	return f
+}
+
+// RNG returns a source of nonces for the prover, seeded with the transcript,
+// the prover's secret witness and fresh randomness. The nonces stay secret
+// if either the witness or the system randomness is, and are bound to the
+// statement being proven. The transcript itself is not modified
+func (t *Transcript) RNG(witness []byte) (io.Reader, error) {
+	var seed [32]byte
+	if _, err := io.ReadFull(rng.Reader, seed[:]); err != nil {
+		return nil, err
+	}
+
+	state := t.state.Clone()
+	absorb(state, opRNG, "witness", witness)
+	absorb(state, opRNG, "rand", seed[:])
+	return state, nil
+}
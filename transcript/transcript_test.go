@@ -0,0 +1,127 @@
+package transcript
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeterministic(t *testing.T) {
+	var p ristretto.Point
+	p.Rand()
+	var s ristretto.Scalar
+	s.Rand()
+
+	run := func() ristretto.Scalar {
+		tr := New("test")
+		tr.Append("msg", []byte("hello"))
+		tr.AppendUint64("n", 42)
+		tr.AppendPoint("P", p)
+		tr.AppendScalar("s", s)
+		return tr.ChallengeScalar("c")
+	}
+
+	c1, c2 := run(), run()
+	assert.True(t, c1.Equals(&c2))
+}
+
+func TestSeparation(t *testing.T) {
+	base := New("test")
+	base.Append("a", []byte("bc"))
+	expected := base.ChallengeBytes("c", 32)
+
+	variants := []*Transcript{New("other"), New("test"), New("test"), New("test"), New("test")}
+	variants[0].Append("a", []byte("bc"))
+	variants[1].Append("ab", []byte("c"))
+	variants[2].Append("a", []byte("b"))
+	variants[2].Append("", []byte("c"))
+	variants[3].Append("a", []byte("bc"))
+	variants[4].Append("a", []byte("bc"))
+
+	for i, v := range variants[:3] {
+		assert.NotEqual(t, expected, v.ChallengeBytes("c", 32), "variant %d", i)
+	}
+	assert.NotEqual(t, expected, variants[3].ChallengeBytes("d", 32))
+	assert.Equal(t, expected, variants[4].ChallengeBytes("c", 32))
+}
+
+func TestSuccessiveChallenges(t *testing.T) {
+	tr := New("test")
+	c1 := tr.ChallengeBytes("c", 32)
+	c2 := tr.ChallengeBytes("c", 32)
+	assert.NotEqual(t, c1, c2)
+
+	// a longer challenge is not an extension of a shorter one
+	a := New("test").ChallengeBytes("c", 16)
+	b := New("test").ChallengeBytes("c", 32)
+	assert.NotEqual(t, a, b[:16])
+}
+
+func TestCloneFork(t *testing.T) {
+	tr := New("test")
+	tr.Append("m", []byte("prefix"))
+
+	clone := tr.Clone()
+	assert.Equal(t, tr.ChallengeBytes("c", 32), clone.ChallengeBytes("c", 32))
+
+	// the clone is independent
+	clone.Append("m", []byte("more"))
+	assert.NotEqual(t, tr.ChallengeBytes("c", 32), clone.ChallengeBytes("c", 32))
+
+	f1 := tr.Fork("party 1")
+	f2 := tr.Fork("party 2")
+	f1again := tr.Fork("party 1")
+	c1 := f1.ChallengeBytes("c", 32)
+	assert.NotEqual(t, c1, f2.ChallengeBytes("c", 32))
+	assert.NotEqual(t, c1, tr.Clone().ChallengeBytes("c", 32))
+	assert.Equal(t, c1, f1again.ChallengeBytes("c", 32))
+}
+
+func TestRNG(t *testing.T) {
+	tr := New("test")
+	before := tr.Clone().ChallengeBytes("c", 32)
+
+	rng1, err := tr.RNG([]byte("witness"))
+	require.Nil(t, err)
+	rng2, err := tr.RNG([]byte("witness"))
+	require.Nil(t, err)
+
+	a := make([]byte, 32)
+	b := make([]byte, 32)
+	_, err = io.ReadFull(rng1, a)
+	require.Nil(t, err)
+	_, err = io.ReadFull(rng2, b)
+	require.Nil(t, err)
+	assert.NotEqual(t, a, b)
+
+	// the transcript is unaffected
+	assert.Equal(t, before, tr.ChallengeBytes("c", 32))
+}
+
+func TestTrace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tr := New("test")
+	tr.SetTrace(buf)
+	tr.Append("msg", []byte{0xaa, 0xbb})
+	c := tr.ChallengeBytes("c", 4)
+	tr.Fork("f")
+
+	want := "append    msg len=2 aabb\n" +
+		"challenge c " + hex.EncodeToString(c) + "\n" +
+		"fork      f\n"
+	assert.Equal(t, want, buf.String())
+
+	// tracing does not change the challenges
+	untraced := New("test")
+	untraced.Append("msg", []byte{0xaa, 0xbb})
+	assert.Equal(t, c, untraced.ChallengeBytes("c", 4))
+
+	tr.SetTrace(nil)
+	tr.Append("msg", nil)
+	assert.Equal(t, want, buf.String())
+}
@@ -0,0 +1,163 @@
+// Package vdf implements Wesolowski's verifiable delay function in an RSA
+// group of unknown order. Evaluating it takes t sequential squarings, which
+// cannot be parallelized, while the proof of the evaluation is a single
+// group element checked with two short exponentiations.
+//
+// Feeding the output of the beacon into the VDF, with a delay longer than a
+// round, hardens it against the last member to reveal a partial signature:
+// by the time the member could learn the final value, it is too late to
+// withhold the signature
+package vdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/dusk-network/dusk-crypto/hash"
+	"golang.org/x/crypto/sha3"
+)
+
+// primeSize is the size in bytes of the Fiat-Shamir challenge prime
+const primeSize = 16
+
+// rsa2048 is the RSA-2048 challenge modulus, which nobody is known to have
+// factored
+const rsa2048 = "C7970CEEDCC3B0754490201A7AA613CD73911081C790F5F1A8726F463550BB5B" +
+	"7FF0DB8E1EA1189EC72F93D1650011BD721AEEACC2ACDE32A04107F0648C2813" +
+	"A31F5B0B7765FF8B44B4B6FFC93384B646EB09C7CF5E8592D40EA33C80039F35" +
+	"B4F14A04B51F7BFD781BE4D1673164BA8EB991C2C4D730BBBE35F592BDEF524A" +
+	"F7E8DAEFD26C66FC02C479AF89D64D373F442709439DE66CEB955F3EA37D5159" +
+	"F6135809F85334B5CB1813ADDC80CD05609F10AC6A95AD65872C909525BDAD32" +
+	"BC729592642920F24C61DC5B3C3B7923E56B16A4D9D373D8721F24A3FC0F1B31" +
+	"31F55615172866BCCC30F95054C824E733A5EB6817F7BC16399D48C6361CC7E5"
+
+// RSA2048 is the group modulo the RSA-2048 challenge number
+var RSA2048 = rsaGroup()
+
+func rsaGroup() *Group {
+	n, _ := new(big.Int).SetString(rsa2048, 16)
+	return NewGroup(n)
+}
+
+// Group is the multiplicative group modulo N, quotiented by {1, -1} so that
+// -1 cannot be used to forge proofs. Elements are represented by the smaller
+// of x and N-x. The factorization of N must be unknown to everybody
+type Group struct {
+	N *big.Int
+}
+
+// NewGroup returns the group modulo n
+func NewGroup(n *big.Int) *Group {
+	return &Group{N: new(big.Int).Set(n)}
+}
+
+// normalize maps x to the representative of {x, -x}
+func (g *Group) normalize(x *big.Int) *big.Int {
+	neg := new(big.Int).Sub(g.N, x)
+	if neg.Cmp(x) < 0 {
+		return x.Set(neg)
+	}
+	return x
+}
+
+// contains checks that x is the representative of a group element
+func (g *Group) contains(x *big.Int) bool {
+	if x == nil || x.Sign() <= 0 || x.Cmp(g.N) >= 0 {
+		return false
+	}
+	if new(big.Int).Sub(g.N, x).Cmp(x) < 0 {
+		return false
+	}
+	return new(big.Int).GCD(nil, nil, x, g.N).Cmp(big.NewInt(1)) == 0
+}
+
+// HashToElement maps an input, e.g. a beacon value, to a group element
+func (g *Group) HashToElement(input []byte) *big.Int {
+	shake := sha3.NewCShake256(nil, []byte("dusk.vdf"))
+	_, _ = shake.Write(g.N.Bytes())
+	_, _ = shake.Write(input)
+
+	// 128 extra bits make the reduction uniform
+	buf := make([]byte, (g.N.BitLen()+7)/8+16)
+	one := big.NewInt(1)
+	for {
+		_, _ = shake.Read(buf)
+		x := new(big.Int).SetBytes(buf)
+		x.Mod(x, g.N)
+		if x.Sign() > 0 && new(big.Int).GCD(nil, nil, x, g.N).Cmp(one) == 0 {
+			return g.normalize(x)
+		}
+	}
+}
+
+// Evaluate returns x^(2^t)
+func (g *Group) Evaluate(x *big.Int, t uint64) (*big.Int, error) {
+	if !g.contains(x) {
+		return nil, errors.New("input is not a group element")
+	}
+
+	y := new(big.Int).Set(x)
+	for i := uint64(0); i < t; i++ {
+		y.Mul(y, y)
+		y.Mod(y, g.N)
+	}
+	return g.normalize(y), nil
+}
+
+// Prove returns the proof that y = x^(2^t): pi = x^floor(2^t / l), l being a
+// prime derived from the statement. The quotient is computed bit by bit by
+// long division, so that 2^t is never materialized
+func (g *Group) Prove(x, y *big.Int, t uint64) (*big.Int, error) {
+	if !g.contains(x) || !g.contains(y) {
+		return nil, errors.New("input is not a group element")
+	}
+
+	l := g.challenge(x, y, t)
+	pi := big.NewInt(1)
+	r := big.NewInt(1)
+	for i := uint64(0); i < t; i++ {
+		// the next bit of the quotient is floor(2r / l)
+		r.Lsh(r, 1)
+		pi.Mul(pi, pi)
+		if r.Cmp(l) >= 0 {
+			r.Sub(r, l)
+			pi.Mul(pi, x)
+		}
+		pi.Mod(pi, g.N)
+	}
+	return g.normalize(pi), nil
+}
+
+// Verify checks that y = x^(2^t) given the proof pi, by testing that
+// pi^l * x^(2^t mod l) = y
+func (g *Group) Verify(x, y, pi *big.Int, t uint64) bool {
+	if !g.contains(x) || !g.contains(y) || !g.contains(pi) {
+		return false
+	}
+
+	l := g.challenge(x, y, t)
+	r := new(big.Int).Exp(big.NewInt(2), new(big.Int).SetUint64(t), l)
+
+	lhs := new(big.Int).Exp(pi, l, g.N)
+	lhs.Mul(lhs, new(big.Int).Exp(x, r, g.N))
+	lhs.Mod(lhs, g.N)
+	return g.normalize(lhs).Cmp(y) == 0
+}
+
+// challenge derives the 128 bit prime l from the statement
+func (g *Group) challenge(x, y *big.Int, t uint64) *big.Int {
+	var tb, ctr [8]byte
+	binary.BigEndian.PutUint64(tb[:], t)
+
+	l := new(big.Int)
+	for i := uint64(0); ; i++ {
+		binary.BigEndian.PutUint64(ctr[:], i)
+		h := hash.Sha3256WithDomain("dusk.vdf.prime", g.N.Bytes(), x.Bytes(), y.Bytes(), tb[:], ctr[:])
+		h[0] |= 0x80
+		l.SetBytes(h[:primeSize])
+		if l.ProbablyPrime(20) {
+			return l
+		}
+	}
+}
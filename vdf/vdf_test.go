@@ -0,0 +1,64 @@
+package vdf
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateProveVerify(t *testing.T) {
+	g := RSA2048
+	x := g.HashToElement([]byte("beacon value"))
+
+	for _, iterations := range []uint64{0, 1, 2, 1000} {
+		y, err := g.Evaluate(x, iterations)
+		require.Nil(t, err)
+		pi, err := g.Prove(x, y, iterations)
+		require.Nil(t, err)
+		assert.True(t, g.Verify(x, y, pi, iterations))
+
+		assert.False(t, g.Verify(x, y, pi, iterations+1))
+		other := g.HashToElement([]byte("other"))
+		assert.False(t, g.Verify(other, y, pi, iterations))
+		assert.False(t, g.Verify(x, other, pi, iterations))
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	// a toy group where the result can be computed with the group order
+	p, q := big.NewInt(1019), big.NewInt(1031)
+	g := NewGroup(new(big.Int).Mul(p, q))
+	x := big.NewInt(5)
+	iterations := uint64(300)
+
+	y, err := g.Evaluate(x, iterations)
+	require.Nil(t, err)
+
+	phi := new(big.Int).Mul(big.NewInt(1018), big.NewInt(1030))
+	e := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(iterations)), phi)
+	expected := g.normalize(new(big.Int).Exp(x, e, g.N))
+	assert.Equal(t, 0, expected.Cmp(y))
+}
+
+func TestInvalidElements(t *testing.T) {
+	g := RSA2048
+	x := g.HashToElement([]byte("input"))
+	y, err := g.Evaluate(x, 10)
+	require.Nil(t, err)
+	pi, err := g.Prove(x, y, 10)
+	require.Nil(t, err)
+
+	// -y represents the same element, but is not a canonical encoding
+	negY := new(big.Int).Sub(g.N, y)
+	assert.False(t, g.Verify(x, negY, pi, 10))
+	assert.False(t, g.Verify(x, y, new(big.Int).Sub(g.N, pi), 10))
+	assert.False(t, g.Verify(x, y, big.NewInt(0), 10))
+	assert.False(t, g.Verify(x, y, nil, 10))
+
+	_, err = g.Evaluate(g.N, 10)
+	assert.NotNil(t, err)
+	_, err = g.Prove(x, negY, 10)
+	assert.NotNil(t, err)
+}
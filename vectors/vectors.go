@@ -0,0 +1,529 @@
+// Package vectors generates and checks test vectors covering several
+// packages of the repository, so that other implementations, e.g. the Rust
+// one, can be tested against this one and the other way around.
+//
+// A corpus is generated deterministically from a seed: the randomness of
+// every package is drawn from rng.Deterministic while Generate runs, so the
+// same seed always yields the same corpus. Verify checks a corpus, wherever
+// it comes from: deterministic values (BLS signatures, VRF proofs, Merkle
+// roots, public keys) are recomputed and compared, randomized ones (Schnorr
+// signatures, range proofs) are verified. All byte strings are hex encoded
+package vectors
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/dusk-network/dusk-crypto/bls"
+	"github.com/dusk-network/dusk-crypto/merkletree"
+	"github.com/dusk-network/dusk-crypto/rangeproof"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"github.com/dusk-network/dusk-crypto/schnorr"
+	"github.com/dusk-network/dusk-crypto/vrf"
+)
+
+// Version is the version of the corpus schema
+const Version = 1
+
+// Corpus is a set of test vectors
+type Corpus struct {
+	Version int `json:"version"`
+	// Seed is the seed the corpus was generated from, informative only
+	Seed         string               `json:"seed,omitempty"`
+	BLS          []BLSVector          `json:"bls"`
+	BLSAggregate []BLSAggregateVector `json:"bls_aggregate"`
+	BLSKeySet    []BLSKeySetVector    `json:"bls_keyset"`
+	Schnorr      []SchnorrVector      `json:"schnorr"`
+	RangeProof   []RangeProofVector   `json:"rangeproof"`
+	Merkle       []MerkleVector       `json:"merkle"`
+	VRF          []VRFVector          `json:"vrf"`
+}
+
+// BLSVector is a BLS signature of the plain public key model. Keys and
+// signatures are in the uncompressed form of bls.Marshal
+type BLSVector struct {
+	Secret    string `json:"secret"`
+	Public    string `json:"public"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+// BLSAggregateVector is the aggregated signature of several keys on one
+// message
+type BLSAggregateVector struct {
+	Publics   []string `json:"publics"`
+	Message   string   `json:"message"`
+	Signature string   `json:"signature"`
+}
+
+// BLSKeySetVector is the digest of a set of keys by bls.HashKeys. The keys
+// are not in the canonical order, which the digest does not depend on
+type BLSKeySetVector struct {
+	Publics []string `json:"publics"`
+	Hash    string   `json:"hash"`
+}
+
+// SchnorrVector is a Schnorr signature, encoded as R || S
+type SchnorrVector struct {
+	Secret    string `json:"secret"`
+	Public    string `json:"public"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+// RangeProofVector is a range proof encoded with its commitments
+type RangeProofVector struct {
+	Amounts []uint64 `json:"amounts"`
+	Proof   string   `json:"proof"`
+}
+
+// MerkleVector is the root of an incremental Merkle tree of the given depth
+type MerkleVector struct {
+	Depth  int      `json:"depth"`
+	Leaves []string `json:"leaves"`
+	Root   string   `json:"root"`
+}
+
+// VRFVector is a VRF evaluation
+type VRFVector struct {
+	Secret string `json:"secret"`
+	Public string `json:"public"`
+	Alpha  string `json:"alpha"`
+	Proof  string `json:"proof"`
+	Output string `json:"output"`
+}
+
+// Generate returns the corpus of seed. The randomness of the whole process
+// is replaced while it runs, so it must not run concurrently with code
+// which needs real randomness
+func Generate(seed []byte) (*Corpus, error) {
+	defer rng.SetSource(rng.Deterministic(seed))()
+
+	c := &Corpus{Version: Version, Seed: hex.EncodeToString(seed)}
+	messages := [][]byte{{}, []byte("dusk"), bytes.Repeat([]byte{0xab}, 100)}
+
+	for _, msg := range messages {
+		pk, sk, err := bls.GenKeyPair(rng.Reader)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := bls.Sign(sk, pk, msg)
+		if err != nil {
+			return nil, err
+		}
+		c.BLS = append(c.BLS, BLSVector{
+			Secret:    hex.EncodeToString(sk.Marshal()),
+			Public:    hex.EncodeToString(pk.Marshal()),
+			Message:   hex.EncodeToString(msg),
+			Signature: hex.EncodeToString(sig.Marshal()),
+		})
+	}
+
+	for _, n := range []int{2, 5} {
+		msg := []byte(fmt.Sprintf("committee of %d", n))
+		v := BLSAggregateVector{Message: hex.EncodeToString(msg)}
+		var agg *bls.Signature
+		for i := 0; i < n; i++ {
+			pk, sk, err := bls.GenKeyPair(rng.Reader)
+			if err != nil {
+				return nil, err
+			}
+			sig, err := bls.Sign(sk, pk, msg)
+			if err != nil {
+				return nil, err
+			}
+			if agg == nil {
+				agg = sig
+			} else {
+				agg.Aggregate(sig)
+			}
+			v.Publics = append(v.Publics, hex.EncodeToString(pk.Marshal()))
+		}
+		v.Signature = hex.EncodeToString(agg.Marshal())
+		c.BLSAggregate = append(c.BLSAggregate, v)
+	}
+
+	for _, msg := range messages {
+		sk, pk := schnorr.GenerateKey()
+		sig := schnorr.Sign(sk, msg)
+		buf := new(bytes.Buffer)
+		if err := sig.Encode(buf); err != nil {
+			return nil, err
+		}
+		c.Schnorr = append(c.Schnorr, SchnorrVector{
+			Secret:    hex.EncodeToString(sk.Bytes()),
+			Public:    hex.EncodeToString(pk.Bytes()),
+			Message:   hex.EncodeToString(msg),
+			Signature: hex.EncodeToString(buf.Bytes()),
+		})
+	}
+
+	for _, amounts := range [][]uint64{{0}, {1<<64 - 1}, {1, 2, 3}} {
+		p, err := rangeproof.ProveUint64(amounts, nil)
+		if err != nil {
+			return nil, err
+		}
+		buf := new(bytes.Buffer)
+		if err := p.Encode(buf, true); err != nil {
+			return nil, err
+		}
+		c.RangeProof = append(c.RangeProof, RangeProofVector{
+			Amounts: amounts,
+			Proof:   hex.EncodeToString(buf.Bytes()),
+		})
+	}
+
+	for _, n := range []int{0, 1, 5} {
+		v := MerkleVector{Depth: 4}
+		t, err := merkletree.NewIncrementalTree(v.Depth)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			var leaf [32]byte
+			if err := rng.Read(leaf[:]); err != nil {
+				return nil, err
+			}
+			if _, err := t.Append(leaf); err != nil {
+				return nil, err
+			}
+			v.Leaves = append(v.Leaves, hex.EncodeToString(leaf[:]))
+		}
+		root := t.Root()
+		v.Root = hex.EncodeToString(root[:])
+		c.Merkle = append(c.Merkle, v)
+	}
+
+	for _, alpha := range messages {
+		sk, pk, err := vrf.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		pi, err := vrf.Prove(sk, alpha)
+		if err != nil {
+			return nil, err
+		}
+		beta, err := vrf.ProofToHash(pi)
+		if err != nil {
+			return nil, err
+		}
+		c.VRF = append(c.VRF, VRFVector{
+			Secret: hex.EncodeToString(sk[:]),
+			Public: hex.EncodeToString(pk[:]),
+			Alpha:  hex.EncodeToString(alpha),
+			Proof:  hex.EncodeToString(pi[:]),
+			Output: hex.EncodeToString(beta[:]),
+		})
+	}
+
+	for _, n := range []int{1, 4} {
+		var v BLSKeySetVector
+		pks := make([]*bls.PublicKey, n)
+		for i := range pks {
+			pk, _, err := bls.GenKeyPair(rng.Reader)
+			if err != nil {
+				return nil, err
+			}
+			pks[i] = pk
+			v.Publics = append(v.Publics, hex.EncodeToString(pk.Marshal()))
+		}
+		h, err := bls.HashKeys(pks)
+		if err != nil {
+			return nil, err
+		}
+		v.Hash = hex.EncodeToString(h[:])
+		c.BLSKeySet = append(c.BLSKeySet, v)
+	}
+	return c, nil
+}
+
+// Verify checks every vector of the corpus, and returns an error naming the
+// first one that fails
+func Verify(c *Corpus) error {
+	if c.Version != Version {
+		return fmt.Errorf("unsupported corpus version %d", c.Version)
+	}
+	for i, v := range c.BLS {
+		if err := verifyBLS(v); err != nil {
+			return fmt.Errorf("bls vector %d: %v", i, err)
+		}
+	}
+	for i, v := range c.BLSAggregate {
+		if err := verifyBLSAggregate(v); err != nil {
+			return fmt.Errorf("bls aggregate vector %d: %v", i, err)
+		}
+	}
+	for i, v := range c.BLSKeySet {
+		if err := verifyBLSKeySet(v); err != nil {
+			return fmt.Errorf("bls keyset vector %d: %v", i, err)
+		}
+	}
+	for i, v := range c.Schnorr {
+		if err := verifySchnorr(v); err != nil {
+			return fmt.Errorf("schnorr vector %d: %v", i, err)
+		}
+	}
+	for i, v := range c.RangeProof {
+		if err := verifyRangeProof(v); err != nil {
+			return fmt.Errorf("rangeproof vector %d: %v", i, err)
+		}
+	}
+	for i, v := range c.Merkle {
+		if err := verifyMerkle(v); err != nil {
+			return fmt.Errorf("merkle vector %d: %v", i, err)
+		}
+	}
+	for i, v := range c.VRF {
+		if err := verifyVRF(v); err != nil {
+			return fmt.Errorf("vrf vector %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// Read decodes a corpus
+func Read(r io.Reader) (*Corpus, error) {
+	var c Corpus
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Write encodes a corpus
+func Write(w io.Writer, c *Corpus) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+// decoder decodes hex fields, keeping the first error
+type decoder struct {
+	err error
+}
+
+func (d *decoder) hex(field, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil && d.err == nil {
+		d.err = fmt.Errorf("%s: %v", field, err)
+	}
+	return b
+}
+
+func (d *decoder) fixed(field, s string, size int) []byte {
+	b := d.hex(field, s)
+	if d.err == nil && len(b) != size {
+		d.err = fmt.Errorf("%s: expected %d bytes, got %d", field, size, len(b))
+	}
+	return b
+}
+
+func verifyBLS(v BLSVector) error {
+	var d decoder
+	secret, public := d.hex("secret", v.Secret), d.hex("public", v.Public)
+	msg, sig := d.hex("message", v.Message), d.hex("signature", v.Signature)
+	if d.err != nil {
+		return d.err
+	}
+
+	sk, err := bls.UnmarshalSk(secret)
+	if err != nil {
+		return err
+	}
+	pk := sk.PublicKey()
+	if !bytes.Equal(pk.Marshal(), public) {
+		return errors.New("public key does not match the secret key")
+	}
+	// BLS signatures are deterministic
+	s, err := bls.Sign(sk, pk, msg)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(s.Marshal(), sig) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+func verifyBLSAggregate(v BLSAggregateVector) error {
+	var d decoder
+	msg, sigBytes := d.hex("message", v.Message), d.hex("signature", v.Signature)
+	pks := make([]*bls.PublicKey, len(v.Publics))
+	for i, p := range v.Publics {
+		b := d.hex("publics", p)
+		if d.err != nil {
+			return d.err
+		}
+		pk, err := bls.UnmarshalPk(b)
+		if err != nil {
+			return err
+		}
+		pks[i] = pk
+	}
+	if d.err != nil {
+		return d.err
+	}
+
+	sig, err := bls.UnmarshalSignature(sigBytes)
+	if err != nil {
+		return err
+	}
+	apk, err := bls.AggregateApk(pks)
+	if err != nil {
+		return err
+	}
+	return bls.Verify(apk, msg, sig)
+}
+
+func verifyBLSKeySet(v BLSKeySetVector) error {
+	var d decoder
+	pks := make([]*bls.PublicKey, len(v.Publics))
+	for i, p := range v.Publics {
+		b := d.hex("publics", p)
+		if d.err != nil {
+			return d.err
+		}
+		pk, err := bls.UnmarshalPk(b)
+		if err != nil {
+			return err
+		}
+		pks[i] = pk
+	}
+	want := d.fixed("hash", v.Hash, 32)
+	if d.err != nil {
+		return d.err
+	}
+
+	got, err := bls.HashKeys(pks)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got[:], want) {
+		return errors.New("hash mismatch")
+	}
+	return nil
+}
+
+func verifySchnorr(v SchnorrVector) error {
+	var d decoder
+	secret, public := d.fixed("secret", v.Secret, 32), d.fixed("public", v.Public, 32)
+	msg, sigBytes := d.hex("message", v.Message), d.hex("signature", v.Signature)
+	if d.err != nil {
+		return d.err
+	}
+
+	var buf [32]byte
+	copy(buf[:], secret)
+	var sk ristretto.Scalar
+	sk.SetBytes(&buf)
+	pk := schnorr.PublicKey(sk)
+	if !bytes.Equal(pk.Bytes(), public) {
+		return errors.New("public key does not match the secret key")
+	}
+
+	var sig schnorr.Signature
+	r := bytes.NewReader(sigBytes)
+	if err := sig.Decode(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return errors.New("trailing bytes in signature")
+	}
+	if !schnorr.Verify(pk, msg, sig) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func verifyRangeProof(v RangeProofVector) error {
+	var d decoder
+	b := d.hex("proof", v.Proof)
+	if d.err != nil {
+		return d.err
+	}
+
+	var p rangeproof.Proof
+	if err := p.Decode(bytes.NewReader(b), true); err != nil {
+		return err
+	}
+	if len(p.V) < len(v.Amounts) {
+		return errors.New("fewer commitments than amounts")
+	}
+	ok, err := rangeproof.Verify(p)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("invalid proof")
+	}
+	return nil
+}
+
+func verifyMerkle(v MerkleVector) error {
+	t, err := merkletree.NewIncrementalTree(v.Depth)
+	if err != nil {
+		return err
+	}
+	var d decoder
+	for _, l := range v.Leaves {
+		var leaf [32]byte
+		copy(leaf[:], d.fixed("leaves", l, 32))
+		if d.err != nil {
+			return d.err
+		}
+		if _, err := t.Append(leaf); err != nil {
+			return err
+		}
+	}
+	root := d.fixed("root", v.Root, 32)
+	if d.err != nil {
+		return d.err
+	}
+	got := t.Root()
+	if !bytes.Equal(got[:], root) {
+		return errors.New("root mismatch")
+	}
+	return nil
+}
+
+func verifyVRF(v VRFVector) error {
+	var d decoder
+	secret := d.fixed("secret", v.Secret, vrf.SecretKeySize)
+	public := d.fixed("public", v.Public, vrf.PublicKeySize)
+	alpha := d.hex("alpha", v.Alpha)
+	proof := d.fixed("proof", v.Proof, vrf.ProofSize)
+	output := d.fixed("output", v.Output, vrf.OutputSize)
+	if d.err != nil {
+		return d.err
+	}
+
+	var sk vrf.SecretKey
+	copy(sk[:], secret)
+	pk := sk.Public()
+	if !bytes.Equal(pk[:], public) {
+		return errors.New("public key does not match the secret key")
+	}
+	// VRF proofs are deterministic
+	pi, err := vrf.Prove(sk, alpha)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(pi[:], proof) {
+		return errors.New("proof mismatch")
+	}
+	beta, ok := vrf.Verify(pk, pi, alpha)
+	if !ok {
+		return errors.New("invalid proof")
+	}
+	if !bytes.Equal(beta[:], output) {
+		return errors.New("output mismatch")
+	}
+	return nil
+}
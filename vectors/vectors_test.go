@@ -0,0 +1,89 @@
+package vectors
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateVerify(t *testing.T) {
+	c, err := Generate([]byte("dusk"))
+	require.NoError(t, err)
+	require.NoError(t, Verify(c))
+
+	again, err := Generate([]byte("dusk"))
+	require.NoError(t, err)
+	assert.Equal(t, c, again)
+
+	other, err := Generate([]byte("other"))
+	require.NoError(t, err)
+	assert.NotEqual(t, c.BLS, other.BLS)
+}
+
+func TestReadWrite(t *testing.T) {
+	c, err := Generate([]byte("dusk"))
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, Write(buf, c))
+	decoded, err := Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, c, decoded)
+
+	_, err = Read(bytes.NewReader([]byte(`{"version": 1, "unknown": 1}`)))
+	assert.Error(t, err)
+}
+
+func TestTampered(t *testing.T) {
+	c, err := Generate([]byte("dusk"))
+	require.NoError(t, err)
+
+	flip := func(s string) string {
+		b := []byte(s)
+		if b[len(b)-1] == '0' {
+			b[len(b)-1] = '1'
+		} else {
+			b[len(b)-1] = '0'
+		}
+		return string(b)
+	}
+
+	tampered := *c
+	tampered.BLS = append([]BLSVector{}, c.BLS...)
+	tampered.BLS[1].Message = flip(c.BLS[1].Message)
+	assert.Error(t, Verify(&tampered))
+
+	tampered = *c
+	tampered.Merkle = append([]MerkleVector{}, c.Merkle...)
+	tampered.Merkle[2].Root = flip(c.Merkle[2].Root)
+	assert.Error(t, Verify(&tampered))
+
+	tampered = *c
+	tampered.BLSKeySet = append([]BLSKeySetVector{}, c.BLSKeySet...)
+	tampered.BLSKeySet[1].Hash = flip(c.BLSKeySet[1].Hash)
+	assert.Error(t, Verify(&tampered))
+
+	tampered = *c
+	tampered.VRF = append([]VRFVector{}, c.VRF...)
+	tampered.VRF[0].Output = flip(c.VRF[0].Output)
+	assert.Error(t, Verify(&tampered))
+
+	tampered = *c
+	tampered.Version = 2
+	assert.Error(t, Verify(&tampered))
+}
+
+// TestCorpus checks the committed corpus, which other implementations test
+// against
+func TestCorpus(t *testing.T) {
+	f, err := os.Open("testdata/corpus.json")
+	require.NoError(t, err)
+	defer f.Close()
+
+	c, err := Read(f)
+	require.NoError(t, err)
+	assert.NoError(t, Verify(c))
+}
@@ -0,0 +1,407 @@
+// Package verkle is an experimental Verkle tree: a key-value tree whose wide
+// nodes are committed to with KZG instead of hashing their children, so that
+// a proof only carries one commitment and one opening per level instead of
+// all the siblings of the path.
+//
+// Keys are 32 bytes, walked a nibble per level. Every node has Width
+// children and commits to the vector of their field values: zero for an
+// empty slot, a hash of the key and value for a leaf, and a hash of the
+// commitment for an inner node. Leaves sit at the shallowest level where
+// their prefix is unique. The tree is insert only
+package verkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/dusk-network/bn256"
+	"github.com/dusk-network/dusk-crypto/kzg"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// Width is the number of children of a node
+	Width = 16
+	// KeySize is the size of the keys
+	KeySize = 32
+	// MaxDepth is the maximum number of levels of a path
+	MaxDepth = 2 * KeySize
+)
+
+const g1Size = 64
+
+var (
+	leafDomain = []byte("dusk.verkle.leaf")
+	nodeDomain = []byte("dusk.verkle.node")
+)
+
+// domain[i] is the evaluation point of the i-th child
+var domain = func() []*big.Int {
+	d := make([]*big.Int, Width)
+	for i := range d {
+		d[i] = big.NewInt(int64(i))
+	}
+	return d
+}()
+
+type node struct {
+	// inner node
+	children [Width]*node
+	poly     kzg.Polynomial
+	c        *bn256.G1
+	dirty    bool
+
+	// leaf
+	leaf  bool
+	key   [KeySize]byte
+	value []byte
+}
+
+// Tree is a Verkle tree committed to with an SRS of degree at least Width-1
+type Tree struct {
+	srs  *kzg.SRS
+	root *node
+}
+
+// New returns an empty tree
+func New(srs *kzg.SRS) (*Tree, error) {
+	if srs == nil || srs.Degree() < Width-1 || len(srs.G2) < 2 {
+		return nil, errors.New("SRS is too small")
+	}
+	return &Tree{srs: srs, root: &node{dirty: true}}, nil
+}
+
+func nibble(key []byte, depth int) int {
+	b := key[depth/2]
+	if depth%2 == 0 {
+		return int(b >> 4)
+	}
+	return int(b & 0x0f)
+}
+
+// Insert sets the value of key, replacing the previous one if any
+func (t *Tree) Insert(key [KeySize]byte, value []byte) {
+	leaf := &node{leaf: true, key: key, value: append([]byte{}, value...)}
+
+	n := t.root
+	for depth := 0; ; depth++ {
+		n.dirty = true
+		idx := nibble(key[:], depth)
+		child := n.children[idx]
+
+		switch {
+		case child == nil || (child.leaf && child.key == key):
+			n.children[idx] = leaf
+			return
+		case child.leaf:
+			// split the slot: both keys share this nibble
+			inner := &node{dirty: true}
+			inner.children[nibble(child.key[:], depth+1)] = child
+			n.children[idx] = inner
+			n = inner
+		default:
+			n = child
+		}
+	}
+}
+
+// Get returns the value of key
+func (t *Tree) Get(key [KeySize]byte) ([]byte, bool) {
+	n := t.root
+	for depth := 0; ; depth++ {
+		child := n.children[nibble(key[:], depth)]
+		if child == nil {
+			return nil, false
+		}
+		if child.leaf {
+			if child.key != key {
+				return nil, false
+			}
+			return append([]byte{}, child.value...), true
+		}
+		n = child
+	}
+}
+
+// Root returns the commitment to the root node
+func (t *Tree) Root() (*bn256.G1, error) {
+	if err := t.commit(t.root); err != nil {
+		return nil, err
+	}
+	return new(bn256.G1).Set(t.root.c), nil
+}
+
+// commit recomputes the commitments of the dirty nodes under n
+func (t *Tree) commit(n *node) error {
+	if !n.dirty {
+		return nil
+	}
+
+	values := make([]*big.Int, Width)
+	for i, child := range n.children {
+		switch {
+		case child == nil:
+			values[i] = new(big.Int)
+		case child.leaf:
+			values[i] = leafValue(child.key[:], valueHash(child.value))
+		default:
+			if err := t.commit(child); err != nil {
+				return err
+			}
+			values[i] = nodeValue(child.c)
+		}
+	}
+
+	poly, err := kzg.Interpolate(domain, values)
+	if err != nil {
+		return err
+	}
+	c, err := kzg.Commit(t.srs, poly)
+	if err != nil {
+		return err
+	}
+
+	n.poly, n.c, n.dirty = poly, c, false
+	return nil
+}
+
+func valueHash(value []byte) []byte {
+	h := sha3.Sum256(value)
+	return h[:]
+}
+
+// leafValue is the field value of a leaf holding a value with the given hash
+func leafValue(key, valueHash []byte) *big.Int {
+	return hashToField(leafDomain, key, valueHash)
+}
+
+// nodeValue is the field value of an inner node
+func nodeValue(c *bn256.G1) *big.Int {
+	return hashToField(nodeDomain, c.Marshal())
+}
+
+func hashToField(data ...[]byte) *big.Int {
+	h := sha3.New512()
+	for _, d := range data {
+		_, _ = h.Write(d)
+	}
+	x := new(big.Int).SetBytes(h.Sum(nil))
+	return x.Mod(x, bn256.Order)
+}
+
+// Proof proves the value of a key, or its absence, against a root
+type Proof struct {
+	// Commitments are the commitments of the inner nodes of the path,
+	// below the root
+	Commitments []*bn256.G1
+	// Openings open each node of the path, from the root down, at the
+	// position of the next one
+	Openings []*kzg.Proof
+	// OtherKey and OtherValueHash describe the leaf found at the end of the
+	// path of an absent key, if any
+	OtherKey       []byte
+	OtherValueHash []byte
+}
+
+// Prove returns the proof of the value of key, or of its absence
+func (t *Tree) Prove(key [KeySize]byte) (*Proof, error) {
+	if err := t.commit(t.root); err != nil {
+		return nil, err
+	}
+
+	proof := &Proof{}
+	n := t.root
+	for depth := 0; ; depth++ {
+		idx := nibble(key[:], depth)
+		_, opening, err := kzg.Open(t.srs, n.poly, domain[idx])
+		if err != nil {
+			return nil, err
+		}
+		proof.Openings = append(proof.Openings, opening)
+
+		child := n.children[idx]
+		if child == nil {
+			return proof, nil
+		}
+		if child.leaf {
+			if child.key != key {
+				proof.OtherKey = append([]byte{}, child.key[:]...)
+				proof.OtherValueHash = valueHash(child.value)
+			}
+			return proof, nil
+		}
+		proof.Commitments = append(proof.Commitments, child.c)
+		n = child
+	}
+}
+
+// Verify checks that key holds value in the tree with the given root. A nil
+// value checks that the key is absent instead
+func Verify(srs *kzg.SRS, root *bn256.G1, key [KeySize]byte, value []byte, proof *Proof) bool {
+	if srs == nil || root == nil || proof == nil {
+		return false
+	}
+	depth := len(proof.Openings)
+	if depth == 0 || depth > MaxDepth || len(proof.Commitments) != depth-1 {
+		return false
+	}
+
+	// the value of the last slot of the path
+	var last *big.Int
+	switch {
+	case value != nil:
+		if proof.OtherKey != nil || proof.OtherValueHash != nil {
+			return false
+		}
+		last = leafValue(key[:], valueHash(value))
+	case proof.OtherKey == nil:
+		if proof.OtherValueHash != nil {
+			return false
+		}
+		last = new(big.Int)
+	default:
+		// another key living where key would be must share its path
+		if len(proof.OtherKey) != KeySize || len(proof.OtherValueHash) != 32 ||
+			bytes.Equal(proof.OtherKey, key[:]) {
+			return false
+		}
+		for d := 0; d < depth; d++ {
+			if nibble(proof.OtherKey, d) != nibble(key[:], d) {
+				return false
+			}
+		}
+		last = leafValue(proof.OtherKey, proof.OtherValueHash)
+	}
+
+	c := root
+	for d := 0; d < depth; d++ {
+		y := last
+		if d < depth-1 {
+			if proof.Commitments[d] == nil {
+				return false
+			}
+			y = nodeValue(proof.Commitments[d])
+		}
+		if !kzg.Verify(srs, c, domain[nibble(key[:], d)], y, proof.Openings[d]) {
+			return false
+		}
+		if d < depth-1 {
+			c = proof.Commitments[d]
+		}
+	}
+	return true
+}
+
+// Encode a Proof
+func (p *Proof) Encode(w io.Writer) error {
+	if len(p.Openings) == 0 || len(p.Commitments) != len(p.Openings)-1 {
+		return errors.New("malformed proof")
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint8(len(p.Openings))); err != nil {
+		return err
+	}
+	for _, c := range p.Commitments {
+		if err := binary.Write(w, binary.BigEndian, c.Marshal()); err != nil {
+			return err
+		}
+	}
+	for _, o := range p.Openings {
+		if err := binary.Write(w, binary.BigEndian, o.W.Marshal()); err != nil {
+			return err
+		}
+	}
+
+	var other uint8
+	if p.OtherKey != nil {
+		if len(p.OtherKey) != KeySize || len(p.OtherValueHash) != 32 {
+			return errors.New("malformed proof")
+		}
+		other = 1
+	}
+	if err := binary.Write(w, binary.BigEndian, other); err != nil {
+		return err
+	}
+	if other == 1 {
+		if err := binary.Write(w, binary.BigEndian, p.OtherKey); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, p.OtherValueHash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode a Proof
+func (p *Proof) Decode(r io.Reader) error {
+	if p == nil {
+		return errors.New("struct is nil")
+	}
+
+	var depth uint8
+	if err := binary.Read(r, binary.BigEndian, &depth); err != nil {
+		return err
+	}
+	if depth == 0 || int(depth) > MaxDepth {
+		return errors.New("invalid proof depth")
+	}
+
+	buf := make([]byte, g1Size)
+	readPoint := func() (*bn256.G1, error) {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		g := new(bn256.G1)
+		if _, err := g.Unmarshal(buf); err != nil {
+			return nil, err
+		}
+		return g, nil
+	}
+
+	commitments := make([]*bn256.G1, depth-1)
+	for i := range commitments {
+		c, err := readPoint()
+		if err != nil {
+			return err
+		}
+		commitments[i] = c
+	}
+	openings := make([]*kzg.Proof, depth)
+	for i := range openings {
+		w, err := readPoint()
+		if err != nil {
+			return err
+		}
+		openings[i] = &kzg.Proof{W: w}
+	}
+
+	var other uint8
+	if err := binary.Read(r, binary.BigEndian, &other); err != nil {
+		return err
+	}
+	var otherKey, otherValueHash []byte
+	switch other {
+	case 0:
+	case 1:
+		otherKey = make([]byte, KeySize)
+		otherValueHash = make([]byte, 32)
+		if _, err := io.ReadFull(r, otherKey); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(r, otherValueHash); err != nil {
+			return err
+		}
+	default:
+		return errors.New("invalid proof")
+	}
+
+	p.Commitments = commitments
+	p.Openings = openings
+	p.OtherKey = otherKey
+	p.OtherValueHash = otherValueHash
+	return nil
+}
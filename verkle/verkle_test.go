@@ -0,0 +1,145 @@
+package verkle
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/dusk-network/dusk-crypto/kzg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTree(t *testing.T) (*kzg.SRS, *Tree) {
+	srs, err := kzg.NewInsecureSRS(Width-1, 1, nil)
+	require.Nil(t, err)
+	tree, err := New(srs)
+	require.Nil(t, err)
+	return srs, tree
+}
+
+func randomKey(t *testing.T) [KeySize]byte {
+	var key [KeySize]byte
+	_, err := rand.Read(key[:])
+	require.Nil(t, err)
+	return key
+}
+
+func TestProveVerify(t *testing.T) {
+	srs, tree := newTree(t)
+
+	keys := make([][KeySize]byte, 24)
+	for i := range keys {
+		keys[i] = randomKey(t)
+		tree.Insert(keys[i], []byte{byte(i)})
+	}
+	// a key sharing a long prefix with another one
+	deep := keys[0]
+	deep[KeySize-1] ^= 1
+	tree.Insert(deep, []byte("deep"))
+
+	root, err := tree.Root()
+	require.Nil(t, err)
+
+	for i, key := range keys {
+		v, ok := tree.Get(key)
+		require.True(t, ok)
+		proof, err := tree.Prove(key)
+		require.Nil(t, err)
+		assert.True(t, Verify(srs, root, key, v, proof))
+		assert.False(t, Verify(srs, root, key, []byte{byte(i + 1)}, proof))
+		assert.False(t, Verify(srs, root, key, nil, proof))
+	}
+
+	proof, err := tree.Prove(deep)
+	require.Nil(t, err)
+	assert.Equal(t, MaxDepth, len(proof.Openings))
+	assert.True(t, Verify(srs, root, deep, []byte("deep"), proof))
+
+	// updating a value changes the root
+	tree.Insert(keys[3], []byte("updated"))
+	newRoot, err := tree.Root()
+	require.Nil(t, err)
+	assert.NotEqual(t, root.Marshal(), newRoot.Marshal())
+	proof, err = tree.Prove(keys[3])
+	require.Nil(t, err)
+	assert.True(t, Verify(srs, newRoot, keys[3], []byte("updated"), proof))
+	assert.False(t, Verify(srs, root, keys[3], []byte("updated"), proof))
+}
+
+func TestAbsence(t *testing.T) {
+	srs, tree := newTree(t)
+
+	// the empty tree proves any absence
+	root, err := tree.Root()
+	require.Nil(t, err)
+	key := randomKey(t)
+	proof, err := tree.Prove(key)
+	require.Nil(t, err)
+	assert.True(t, Verify(srs, root, key, nil, proof))
+
+	present := randomKey(t)
+	tree.Insert(present, []byte("value"))
+	root, err = tree.Root()
+	require.Nil(t, err)
+
+	// the path of an absent key ends in a leaf of another key
+	absent := present
+	absent[10] ^= 0xff
+	_, ok := tree.Get(absent)
+	assert.False(t, ok)
+	proof, err = tree.Prove(absent)
+	require.Nil(t, err)
+	assert.Equal(t, present[:], proof.OtherKey)
+	assert.True(t, Verify(srs, root, absent, nil, proof))
+	assert.False(t, Verify(srs, root, absent, []byte("value"), proof))
+
+	// the other leaf must lie on the path of the key
+	proof.OtherKey = append([]byte{}, proof.OtherKey...)
+	proof.OtherKey[0] ^= 0xff
+	assert.False(t, Verify(srs, root, absent, nil, proof))
+
+	// nor can a present key be proven absent
+	proof, err = tree.Prove(present)
+	require.Nil(t, err)
+	assert.False(t, Verify(srs, root, present, nil, proof))
+}
+
+func TestProofEncoding(t *testing.T) {
+	srs, tree := newTree(t)
+
+	key := randomKey(t)
+	other := key
+	other[0] ^= 0x01
+	tree.Insert(key, []byte("a"))
+	tree.Insert(other, []byte("b"))
+	root, err := tree.Root()
+	require.Nil(t, err)
+
+	absent := key
+	absent[5] ^= 0x10
+	for _, k := range [][KeySize]byte{key, absent} {
+		proof, err := tree.Prove(k)
+		require.Nil(t, err)
+
+		buf := new(bytes.Buffer)
+		require.Nil(t, proof.Encode(buf))
+		decoded := &Proof{}
+		require.Nil(t, decoded.Decode(buf))
+		assert.Equal(t, 0, buf.Len())
+
+		v, _ := tree.Get(k)
+		assert.True(t, Verify(srs, root, k, v, decoded))
+	}
+
+	var p *Proof
+	assert.NotNil(t, p.Decode(bytes.NewReader([]byte{1})))
+	assert.NotNil(t, (&Proof{}).Decode(bytes.NewReader([]byte{0})))
+}
+
+func TestNewSRSTooSmall(t *testing.T) {
+	srs, err := kzg.NewInsecureSRS(Width-2, 1, nil)
+	require.Nil(t, err)
+	_, err = New(srs)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,102 @@
+package vrf
+
+import (
+	"math/big"
+
+	"github.com/bwesterb/go-ristretto/edwards25519"
+)
+
+// The suite works on edwards25519 points with their RFC 8032 encoding, which
+// the ristretto package does not expose. The helpers below add it on top of
+// the edwards25519 field and curve arithmetic
+
+var (
+	feD edwards25519.FieldElement
+
+	// basePoint is the RFC 8032 base point. The ristretto package's base is
+	// an equivalent point of the ristretto group, but not the same curve point
+	basePoint edwards25519.ExtendedPoint
+)
+
+func init() {
+	// d = -121665 / 121666 mod p
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	d := new(big.Int).ModInverse(big.NewInt(121666), p)
+	d.Mul(d, big.NewInt(-121665))
+	d.Mod(d, p)
+	feD.SetBigInt(d)
+
+	x, _ := new(big.Int).SetString("15112221349535400772501151409588531511454012693041857206046113283949847762202", 10)
+	y, _ := new(big.Int).SetString("46316835694926478169428394003475163141307993866256225615783033603165251855960", 10)
+	basePoint.X.SetBigInt(x)
+	basePoint.Y.SetBigInt(y)
+	basePoint.Z.SetOne()
+	basePoint.T.Mul(&basePoint.X, &basePoint.Y)
+}
+
+// encodePoint returns the RFC 8032 encoding of p: the little endian y
+// coordinate with the sign of x in the most significant bit
+func encodePoint(p *edwards25519.ExtendedPoint) [32]byte {
+	var zInv, x, y edwards25519.FieldElement
+	zInv.Inverse(&p.Z)
+	x.Mul(&p.X, &zInv)
+	y.Mul(&p.Y, &zInv)
+
+	var buf [32]byte
+	y.BytesInto(&buf)
+	buf[31] |= byte(x.IsNegativeI() << 7)
+	return buf
+}
+
+// decodePoint decodes an RFC 8032 point encoding. It rejects non canonical
+// y coordinates and encodings that are not on the curve
+func decodePoint(p *edwards25519.ExtendedPoint, buf [32]byte) bool {
+	sign := int32(buf[31] >> 7)
+	buf[31] &= 0x7f
+
+	var y edwards25519.FieldElement
+	y.SetBytes(&buf)
+	if y.Bytes() != buf {
+		return false
+	}
+
+	// x^2 = (y^2 - 1) / (d y^2 + 1)
+	var one, y2, u, v, x2, x, check edwards25519.FieldElement
+	one.SetOne()
+	y2.Square(&y)
+	u.Sub(&y2, &one)
+	v.Mul(&y2, &feD)
+	v.Add(&v, &one)
+	v.Inverse(&v)
+	x2.Mul(&u, &v)
+
+	x.Sqrt(&x2)
+	check.Square(&x)
+	if !check.Equals(&x2) {
+		return false
+	}
+	if x.IsNonZeroI() == 0 && sign == 1 {
+		return false
+	}
+	if x.IsNegativeI() != sign {
+		x.Neg(&x)
+	}
+
+	p.X.Set(&x)
+	p.Y.Set(&y)
+	p.Z.SetOne()
+	p.T.Mul(&x, &y)
+	return true
+}
+
+// mulByCofactor sets p to 8 * q
+func mulByCofactor(p, q *edwards25519.ExtendedPoint) {
+	p.Double(q)
+	p.Double(p)
+	p.Double(p)
+}
+
+// isIdentity reports whether p is the neutral element
+func isIdentity(p *edwards25519.ExtendedPoint) bool {
+	return p.X.IsNonZeroI() == 0 && p.Y.Equals(&p.Z)
+}
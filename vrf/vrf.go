@@ -0,0 +1,269 @@
+// Package vrf implements the ECVRF-EDWARDS25519-SHA512-TAI verifiable
+// random function of RFC 9381. The holder of a secret key computes, for any
+// input alpha, a pseudorandom output beta together with a proof pi that
+// anyone holding the public key can check. The output is unique for a given
+// key and input, which makes it suitable for leader election and sortition.
+//
+// Keys follow RFC 8032: the secret key is a 32 byte seed and the public key
+// the encoding of the clamped, hashed seed times the edwards25519 base point
+package vrf
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+	"io"
+
+	ristretto "github.com/bwesterb/go-ristretto"
+	"github.com/bwesterb/go-ristretto/edwards25519"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+const (
+	// SecretKeySize is the size of a secret key seed
+	SecretKeySize = 32
+	// PublicKeySize is the size of an encoded public key
+	PublicKeySize = 32
+	// ProofSize is the size of a proof: Gamma, c and s
+	ProofSize = 32 + challengeSize + 32
+	// OutputSize is the size of the VRF output beta
+	OutputSize = sha512.Size
+
+	challengeSize = 16
+	suite         = 0x03
+)
+
+// SecretKey is the seed a key pair is derived from
+type SecretKey [SecretKeySize]byte
+
+// PublicKey is an encoded edwards25519 point
+type PublicKey [PublicKeySize]byte
+
+// Proof is the proof pi = (Gamma, c, s) of a VRF evaluation
+type Proof [ProofSize]byte
+
+// GenerateKey returns a random key pair
+func GenerateKey() (SecretKey, PublicKey, error) {
+	var sk SecretKey
+	if _, err := io.ReadFull(rng.Reader, sk[:]); err != nil {
+		return sk, PublicKey{}, err
+	}
+	return sk, sk.Public(), nil
+}
+
+// Public returns the public key of sk
+func (sk SecretKey) Public() PublicKey {
+	x, _ := sk.expand()
+
+	var Y edwards25519.ExtendedPoint
+	Y.ScalarMult(base(), scalarBytes(&x))
+	return PublicKey(encodePoint(&Y))
+}
+
+// expand derives the secret scalar and the nonce prefix from the seed
+func (sk SecretKey) expand() (ristretto.Scalar, [32]byte) {
+	h := sha512.Sum512(sk[:])
+	var buf [64]byte
+	var prefix [32]byte
+	copy(buf[:32], h[:32])
+	copy(prefix[:], h[32:])
+
+	buf[0] &= 248
+	buf[31] &= 127
+	buf[31] |= 64
+
+	// the clamped scalar acts on the prime order subgroup only, so it can be
+	// reduced. SetBytes would drop its top bits
+	var x ristretto.Scalar
+	x.SetReduced(&buf)
+	return x, prefix
+}
+
+// Prove evaluates the VRF on alpha and returns the proof of the evaluation.
+// The output is recovered from the proof with ProofToHash
+func Prove(sk SecretKey, alpha []byte) (Proof, error) {
+	var pi Proof
+
+	x, prefix := sk.expand()
+	pk := sk.Public()
+
+	H, err := encodeToCurve(pk, alpha)
+	if err != nil {
+		return pi, err
+	}
+	hString := encodePoint(&H)
+
+	var Gamma edwards25519.ExtendedPoint
+	Gamma.ScalarMult(&H, scalarBytes(&x))
+
+	// k = SHA512(prefix || H) mod q
+	nonce := sha512.New()
+	nonce.Write(prefix[:])
+	nonce.Write(hString[:])
+	var kHash [64]byte
+	copy(kHash[:], nonce.Sum(nil))
+	var k ristretto.Scalar
+	k.SetReduced(&kHash)
+
+	var U, V edwards25519.ExtendedPoint
+	U.ScalarMult(base(), scalarBytes(&k))
+	V.ScalarMult(&H, scalarBytes(&k))
+
+	c := challenge(pk, hString, &Gamma, &U, &V)
+
+	// s = k + c * x mod q
+	var s ristretto.Scalar
+	s.MulAdd(&c, &x, &k)
+
+	gammaString := encodePoint(&Gamma)
+	copy(pi[:32], gammaString[:])
+	copy(pi[32:32+challengeSize], c.Bytes()[:challengeSize])
+	copy(pi[32+challengeSize:], s.Bytes())
+	return pi, nil
+}
+
+// Verify checks the proof of the evaluation of alpha under pk. It returns
+// the VRF output and true if the proof is valid
+func Verify(pk PublicKey, pi Proof, alpha []byte) ([OutputSize]byte, bool) {
+	var beta [OutputSize]byte
+
+	var Y edwards25519.ExtendedPoint
+	if !decodePoint(&Y, pk) {
+		return beta, false
+	}
+	// reject keys of small order, for which proofs are not unique
+	var Y8 edwards25519.ExtendedPoint
+	mulByCofactor(&Y8, &Y)
+	if isIdentity(&Y8) {
+		return beta, false
+	}
+
+	Gamma, c, s, ok := decodeProof(pi)
+	if !ok {
+		return beta, false
+	}
+
+	H, err := encodeToCurve(pk, alpha)
+	if err != nil {
+		return beta, false
+	}
+	hString := encodePoint(&H)
+
+	// U = s * B - c * Y
+	// V = s * H - c * Gamma
+	var U, V, tmp edwards25519.ExtendedPoint
+	U.ScalarMult(base(), scalarBytes(&s))
+	tmp.ScalarMult(&Y, scalarBytes(&c))
+	U.Sub(&U, &tmp)
+	V.ScalarMult(&H, scalarBytes(&s))
+	tmp.ScalarMult(&Gamma, scalarBytes(&c))
+	V.Sub(&V, &tmp)
+
+	expected := challenge(pk, hString, &Gamma, &U, &V)
+	if !expected.Equals(&c) {
+		return beta, false
+	}
+
+	return gammaToHash(&Gamma), true
+}
+
+// ProofToHash returns the VRF output beta of a proof. It does not verify the
+// proof, which callers must do with Verify before trusting the output
+func ProofToHash(pi Proof) ([OutputSize]byte, error) {
+	Gamma, _, _, ok := decodeProof(pi)
+	if !ok {
+		return [OutputSize]byte{}, errors.New("invalid proof encoding")
+	}
+	return gammaToHash(&Gamma), nil
+}
+
+func decodeProof(pi Proof) (edwards25519.ExtendedPoint, ristretto.Scalar, ristretto.Scalar, bool) {
+	var Gamma edwards25519.ExtendedPoint
+	var c, s ristretto.Scalar
+
+	var buf [32]byte
+	copy(buf[:], pi[:32])
+	if !decodePoint(&Gamma, buf) {
+		return Gamma, c, s, false
+	}
+
+	buf = [32]byte{}
+	copy(buf[:], pi[32:32+challengeSize])
+	c.SetBytes(&buf)
+
+	copy(buf[:], pi[32+challengeSize:])
+	s.SetBytes(&buf)
+	if !bytes.Equal(s.Bytes(), buf[:]) {
+		return Gamma, c, s, false
+	}
+
+	return Gamma, c, s, true
+}
+
+// encodeToCurve hashes the public key and alpha to a point of the prime
+// order subgroup, using the try-and-increment method of RFC 9381 5.4.1.1
+func encodeToCurve(pk PublicKey, alpha []byte) (edwards25519.ExtendedPoint, error) {
+	var H edwards25519.ExtendedPoint
+	for ctr := 0; ctr < 256; ctr++ {
+		h := sha512.New()
+		h.Write([]byte{suite, 0x01})
+		h.Write(pk[:])
+		h.Write(alpha)
+		h.Write([]byte{byte(ctr), 0x00})
+
+		var buf [32]byte
+		copy(buf[:], h.Sum(nil))
+		if decodePoint(&H, buf) {
+			mulByCofactor(&H, &H)
+			return H, nil
+		}
+	}
+	return H, errors.New("could not hash the input to a curve point")
+}
+
+// challenge computes c from the public key, H, Gamma, U and V, truncated to
+// 16 bytes
+func challenge(pk PublicKey, hString [32]byte, Gamma, U, V *edwards25519.ExtendedPoint) ristretto.Scalar {
+	h := sha512.New()
+	h.Write([]byte{suite, 0x02})
+	h.Write(pk[:])
+	h.Write(hString[:])
+	for _, p := range []*edwards25519.ExtendedPoint{Gamma, U, V} {
+		enc := encodePoint(p)
+		h.Write(enc[:])
+	}
+	h.Write([]byte{0x00})
+
+	var buf [32]byte
+	copy(buf[:challengeSize], h.Sum(nil))
+	var c ristretto.Scalar
+	c.SetBytes(&buf)
+	return c
+}
+
+func gammaToHash(Gamma *edwards25519.ExtendedPoint) [OutputSize]byte {
+	var G8 edwards25519.ExtendedPoint
+	mulByCofactor(&G8, Gamma)
+	enc := encodePoint(&G8)
+
+	h := sha512.New()
+	h.Write([]byte{suite, 0x03})
+	h.Write(enc[:])
+	h.Write([]byte{0x00})
+
+	var beta [OutputSize]byte
+	copy(beta[:], h.Sum(nil))
+	return beta
+}
+
+func base() *edwards25519.ExtendedPoint {
+	var B edwards25519.ExtendedPoint
+	B.Set(&basePoint)
+	return &B
+}
+
+func scalarBytes(s *ristretto.Scalar) *[32]byte {
+	var buf [32]byte
+	s.BytesInto(&buf)
+	return &buf
+}
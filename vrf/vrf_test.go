@@ -0,0 +1,131 @@
+package vrf
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors of RFC 9381, appendix B.3
+var rfcVectors = []struct {
+	sk, pk, alpha, pi, beta string
+}{
+	{
+		sk:    "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+		pk:    "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+		alpha: "",
+		pi:    "8657106690b5526245a92b003bb079ccd1a92130477671f6fc01ad16f26f723f26f8a57ccaed74ee1b190bed1f479d9727d2d0f9b005a6e456a35d4fb0daab1268a1b0db10836d9826a528ca76567805",
+		beta:  "90cf1df3b703cce59e2a35b925d411164068269d7b2d29f3301c03dd757876ff66b71dda49d2de59d03450451af026798e8f81cd2e333de5cdf4f3e140fdd8ae",
+	},
+	{
+		sk:    "4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+		pk:    "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+		alpha: "72",
+		pi:    "f3141cd382dc42909d19ec5110469e4feae18300e94f304590abdced48aed5933bf0864a62558b3ed7f2fea45c92a465301b3bbf5e3e54ddf2d935be3b67926da3ef39226bbc355bdc9850112c8f4b02",
+		beta:  "eb4440665d3891d668e7e0fcaf587f1b4bd7fbfe99d0eb2211ccec90496310eb5e33821bc613efb94db5e5b54c70a848a0bef4553a41befc57663b56373a5031",
+	},
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.Nil(t, err)
+	return b
+}
+
+func TestRFCVectors(t *testing.T) {
+	for _, v := range rfcVectors {
+		var sk SecretKey
+		copy(sk[:], decodeHex(t, v.sk))
+		alpha := decodeHex(t, v.alpha)
+
+		pk := sk.Public()
+		assert.Equal(t, v.pk, hex.EncodeToString(pk[:]))
+
+		pi, err := Prove(sk, alpha)
+		require.Nil(t, err)
+		assert.Equal(t, v.pi, hex.EncodeToString(pi[:]))
+
+		beta, err := ProofToHash(pi)
+		require.Nil(t, err)
+		assert.Equal(t, v.beta, hex.EncodeToString(beta[:]))
+
+		verified, ok := Verify(pk, pi, alpha)
+		assert.True(t, ok)
+		assert.Equal(t, beta, verified)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	sk, pk, err := GenerateKey()
+	require.Nil(t, err)
+
+	alpha := []byte("round 42")
+	pi, err := Prove(sk, alpha)
+	require.Nil(t, err)
+
+	_, ok := Verify(pk, pi, alpha)
+	require.True(t, ok)
+
+	// another input
+	_, ok = Verify(pk, pi, []byte("round 43"))
+	assert.False(t, ok)
+
+	// another key
+	_, otherPk, err := GenerateKey()
+	require.Nil(t, err)
+	_, ok = Verify(otherPk, pi, alpha)
+	assert.False(t, ok)
+
+	// tampered Gamma, c and s
+	for _, i := range []int{0, 32, ProofSize - 1} {
+		bad := pi
+		bad[i] ^= 1
+		_, ok = Verify(pk, bad, alpha)
+		assert.False(t, ok)
+	}
+
+	// non canonical s
+	bad := pi
+	for i := 32 + challengeSize; i < ProofSize; i++ {
+		bad[i] = 0xff
+	}
+	_, ok = Verify(pk, bad, alpha)
+	assert.False(t, ok)
+
+	// small order public key: the identity
+	var identity PublicKey
+	identity[0] = 1
+	_, ok = Verify(identity, pi, alpha)
+	assert.False(t, ok)
+}
+
+func TestOutputIsDeterministic(t *testing.T) {
+	sk, _, err := GenerateKey()
+	require.Nil(t, err)
+
+	pi1, err := Prove(sk, []byte("seed"))
+	require.Nil(t, err)
+	pi2, err := Prove(sk, []byte("seed"))
+	require.Nil(t, err)
+	assert.Equal(t, pi1, pi2)
+
+	pi3, err := Prove(sk, []byte("other seed"))
+	require.Nil(t, err)
+	beta1, _ := ProofToHash(pi1)
+	beta3, _ := ProofToHash(pi3)
+	assert.NotEqual(t, beta1, beta3)
+}
+
+func TestPointEncoding(t *testing.T) {
+	var p Proof
+	// y = p is not canonical
+	for i := range p[:32] {
+		p[i] = 0xff
+	}
+	p[0] = 0xed
+	p[31] = 0x7f
+	_, err := ProofToHash(p)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,300 @@
+// Package wots implements WOTS+, the Winternitz one-time signature scheme,
+// and a stateful many-time scheme on top of it after XMSS, for the rare and
+// high-value messages which must stay unforgeable should discrete logs be
+// broken, e.g. key rotation statements. Their security only rests on the
+// hash function, SHA3-256.
+//
+// A WOTS+ key signs a single message: a second signature with the same key
+// reveals enough of its chains to forge others. PrivateKey refuses to sign
+// twice, and Signer, the XMSS-like scheme, never signs twice with the key of
+// the same leaf, as long as its state is never rolled back; see StateStore.
+//
+// The hashes are tweaked with the public seed of the key and the address of
+// the hash in the structure, like in SPHINCS+, so that the attacks on one key
+// do not carry over to the others
+package wots
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/rng"
+	"golang.org/x/crypto/sha3"
+)
+
+// N is the size of the hashes, the seeds and the chain values
+const N = 32
+
+var (
+	// ErrKeyUsed is returned when signing twice with a one-time key
+	ErrKeyUsed = errors.New("wots: one-time key already used")
+	// ErrInvalidParams is returned for a Winternitz parameter other than 4,
+	// 16 or 256
+	ErrInvalidParams = errors.New("wots: the Winternitz parameter must be 4, 16 or 256")
+)
+
+// Params are the parameters of WOTS+. W, the Winternitz parameter, trades
+// the size of signatures for the time to sign and verify: signatures have
+// about 256/log2(W) chain values, and signing and verifying each take about
+// W/2 hashes per chain value
+type Params struct {
+	W int
+}
+
+// Check returns ErrInvalidParams unless W is 4, 16 or 256
+func (p Params) Check() error {
+	switch p.W {
+	case 4, 16, 256:
+		return nil
+	}
+	return ErrInvalidParams
+}
+
+// logW returns log2(W)
+func (p Params) logW() uint {
+	switch p.W {
+	case 4:
+		return 2
+	case 16:
+		return 4
+	}
+	return 8
+}
+
+// len1 is the number of chains signing the message digest
+func (p Params) len1() int {
+	return 8 * N / int(p.logW())
+}
+
+// len2 is the number of chains signing the checksum, the number of digits
+// of its largest value
+func (p Params) len2() int {
+	n := 1
+	for max := p.len1() * (p.W - 1); max >= p.W; max /= p.W {
+		n++
+	}
+	return n
+}
+
+// Len returns the number of chain values of a signature
+func (p Params) Len() int {
+	return p.len1() + p.len2()
+}
+
+// digits returns the base W digits of the digest followed by those of the
+// checksum, which is what prevents advancing the chains of a signature to
+// sign another digest
+func (p Params) digits(digest []byte) []int {
+	res := make([]int, 0, p.Len())
+	logW := p.logW()
+	mask := byte(p.W - 1)
+	for _, b := range digest {
+		for shift := int(8 - logW); shift >= 0; shift -= int(logW) {
+			res = append(res, int((b>>uint(shift))&mask))
+		}
+	}
+
+	csum := 0
+	for _, d := range res {
+		csum += p.W - 1 - d
+	}
+	checksum := make([]int, p.len2())
+	for i := len(checksum) - 1; i >= 0; i-- {
+		checksum[i] = csum % p.W
+		csum /= p.W
+	}
+	return append(res, checksum...)
+}
+
+// Hash types of the addresses
+const (
+	addrChain uint32 = iota
+	addrSecret
+	addrLeaf
+	addrNode
+)
+
+// address locates a hash: the key, the chain and the step within it for
+// WOTS+, and the level and index of a node for the tree
+type address struct {
+	typ   uint32
+	key   uint64
+	chain uint32
+	step  uint32
+	level uint32
+	node  uint64
+}
+
+func (a *address) bytes() [32]byte {
+	var b [32]byte
+	binary.BigEndian.PutUint32(b[0:4], a.typ)
+	binary.BigEndian.PutUint64(b[4:12], a.key)
+	binary.BigEndian.PutUint32(b[12:16], a.chain)
+	binary.BigEndian.PutUint32(b[16:20], a.step)
+	binary.BigEndian.PutUint32(b[20:24], a.level)
+	binary.BigEndian.PutUint64(b[24:32], a.node)
+	return b
+}
+
+// tweak returns SHA3-256(seed || address || data...). Every input but data
+// has a fixed size
+func tweak(seed *[N]byte, a *address, data ...[]byte) [N]byte {
+	h := sha3.New256()
+	ab := a.bytes()
+	_, _ = h.Write(seed[:])
+	_, _ = h.Write(ab[:])
+	for _, d := range data {
+		_, _ = h.Write(d)
+	}
+	var res [N]byte
+	copy(res[:], h.Sum(nil))
+	return res
+}
+
+// chain advances x by steps from start along chain i of key
+func chain(pubSeed *[N]byte, key uint64, i, start, steps int, x [N]byte) [N]byte {
+	a := address{typ: addrChain, key: key, chain: uint32(i)}
+	for j := start; j < start+steps; j++ {
+		a.step = uint32(j)
+		x = tweak(pubSeed, &a, x[:])
+	}
+	return x
+}
+
+// chainStart returns the secret start of chain i of key
+func chainStart(seed, pubSeed *[N]byte, key uint64, i int) [N]byte {
+	a := address{typ: addrSecret, key: key, chain: uint32(i)}
+	return tweak(seed, &a, pubSeed[:])
+}
+
+// compress hashes the ends of the chains of key into its public key
+func compress(pubSeed *[N]byte, key uint64, ends [][N]byte) [N]byte {
+	a := address{typ: addrLeaf, key: key}
+	data := make([][]byte, len(ends))
+	for i := range ends {
+		data[i] = ends[i][:]
+	}
+	return tweak(pubSeed, &a, data...)
+}
+
+// keyGen returns the public key of the one-time key at index key
+func keyGen(p Params, seed, pubSeed *[N]byte, key uint64) [N]byte {
+	ends := make([][N]byte, p.Len())
+	for i := range ends {
+		ends[i] = chain(pubSeed, key, i, 0, p.W-1, chainStart(seed, pubSeed, key, i))
+	}
+	return compress(pubSeed, key, ends)
+}
+
+// sign signs digest with the one-time key at index key
+func sign(p Params, seed, pubSeed *[N]byte, key uint64, digest []byte) [][N]byte {
+	d := p.digits(digest)
+	sig := make([][N]byte, len(d))
+	for i := range sig {
+		sig[i] = chain(pubSeed, key, i, 0, d[i], chainStart(seed, pubSeed, key, i))
+	}
+	return sig
+}
+
+// publicFromSignature returns the public key the signature of digest is
+// valid for
+func publicFromSignature(p Params, pubSeed *[N]byte, key uint64, digest []byte, sig [][N]byte) [N]byte {
+	d := p.digits(digest)
+	ends := make([][N]byte, len(d))
+	for i := range ends {
+		ends[i] = chain(pubSeed, key, i, d[i], p.W-1-d[i], sig[i])
+	}
+	return compress(pubSeed, key, ends)
+}
+
+// PublicKey is a WOTS+ public key
+type PublicKey struct {
+	Params  Params
+	PubSeed [N]byte
+	Key     [N]byte
+}
+
+// PrivateKey is a WOTS+ one-time private key
+type PrivateKey struct {
+	mu     sync.Mutex
+	params Params
+	seed   [N]byte
+	public PublicKey
+	used   bool
+}
+
+// Signature is a WOTS+ signature, Params.Len() chain values
+type Signature [][N]byte
+
+// GenerateKey returns a one-time key pair. Randomness is read from r,
+// rng.Reader is used if it is nil
+func GenerateKey(p Params, r io.Reader) (*PrivateKey, *PublicKey, error) {
+	if err := p.Check(); err != nil {
+		return nil, nil, err
+	}
+	sk := &PrivateKey{params: p}
+	if _, err := io.ReadFull(rng.Or(r), sk.seed[:]); err != nil {
+		return nil, nil, err
+	}
+	if _, err := io.ReadFull(rng.Or(r), sk.public.PubSeed[:]); err != nil {
+		return nil, nil, err
+	}
+	sk.public.Params = p
+	sk.public.Key = keyGen(p, &sk.seed, &sk.public.PubSeed, 0)
+	pk := sk.public
+	return sk, &pk, nil
+}
+
+// messageDigest binds the message to the public key signing it
+func (pk *PublicKey) messageDigest(msg []byte) []byte {
+	return hash.Sha3256WithDomain("dusk.wots.message", pk.PubSeed[:], pk.Key[:], msg)
+}
+
+// Sign signs msg. A one-time key signs once: Sign returns ErrKeyUsed
+// afterwards, and wipes the key
+func (sk *PrivateKey) Sign(msg []byte) (Signature, error) {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+	if sk.used {
+		return nil, ErrKeyUsed
+	}
+	sk.used = true
+	sig := sign(sk.params, &sk.seed, &sk.public.PubSeed, 0, sk.public.messageDigest(msg))
+	sk.seed = [N]byte{}
+	return sig, nil
+}
+
+// Verify checks a signature of msg
+func (pk *PublicKey) Verify(msg []byte, sig Signature) bool {
+	if pk.Params.Check() != nil || len(sig) != pk.Params.Len() {
+		return false
+	}
+	return publicFromSignature(pk.Params, &pk.PubSeed, 0, pk.messageDigest(msg), sig) == pk.Key
+}
+
+// Encode a Signature as its chain values
+func (sig Signature) Encode(w io.Writer) error {
+	for i := range sig {
+		if _, err := w.Write(sig[i][:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeSignature decodes a signature for the parameters p
+func DecodeSignature(p Params, r io.Reader) (Signature, error) {
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+	sig := make(Signature, p.Len())
+	for i := range sig {
+		if _, err := io.ReadFull(r, sig[i][:]); err != nil {
+			return nil, err
+		}
+	}
+	return sig, nil
+}
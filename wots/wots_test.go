@@ -0,0 +1,78 @@
+package wots
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParams(t *testing.T) {
+	for _, tc := range []struct{ w, len int }{{4, 133}, {16, 67}, {256, 34}} {
+		assert.Nil(t, Params{tc.w}.Check())
+		assert.Equal(t, tc.len, Params{tc.w}.Len())
+	}
+	for _, w := range []int{0, 2, 8, 15, 512} {
+		assert.Equal(t, ErrInvalidParams, Params{w}.Check())
+	}
+}
+
+func TestDigits(t *testing.T) {
+	p := Params{16}
+	// the checksum of the all zero digest is the largest
+	d := p.digits(make([]byte, N))
+	require.Equal(t, p.Len(), len(d))
+	assert.Equal(t, []int{3, 12, 0}, d[p.len1():])
+
+	digest := bytes.Repeat([]byte{0xff}, N)
+	d = p.digits(digest)
+	assert.Equal(t, []int{0, 0, 0}, d[p.len1():])
+}
+
+func TestSignVerify(t *testing.T) {
+	for _, w := range []int{4, 16, 256} {
+		sk, pk, err := GenerateKey(Params{w}, nil)
+		require.Nil(t, err)
+		msg := []byte("rotate to key 2")
+
+		sig, err := sk.Sign(msg)
+		require.Nil(t, err)
+		assert.True(t, pk.Verify(msg, sig))
+		assert.False(t, pk.Verify([]byte("rotate to key 3"), sig))
+
+		sig[1][0] ^= 1
+		assert.False(t, pk.Verify(msg, sig))
+		assert.False(t, pk.Verify(msg, sig[1:]))
+	}
+}
+
+func TestOneTime(t *testing.T) {
+	sk, _, err := GenerateKey(Params{16}, nil)
+	require.Nil(t, err)
+	_, err = sk.Sign([]byte("first"))
+	require.Nil(t, err)
+	_, err = sk.Sign([]byte("second"))
+	assert.Equal(t, ErrKeyUsed, err)
+}
+
+func TestSignatureEncode(t *testing.T) {
+	p := Params{16}
+	sk, pk, err := GenerateKey(p, nil)
+	require.Nil(t, err)
+	msg := []byte("msg")
+	sig, err := sk.Sign(msg)
+	require.Nil(t, err)
+
+	buf := new(bytes.Buffer)
+	require.Nil(t, sig.Encode(buf))
+	assert.Equal(t, p.Len()*N, buf.Len())
+	b := buf.Bytes()
+
+	dec, err := DecodeSignature(p, bytes.NewReader(b))
+	require.Nil(t, err)
+	assert.True(t, pk.Verify(msg, dec))
+
+	_, err = DecodeSignature(p, bytes.NewReader(b[1:]))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,409 @@
+package wots
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dusk-network/dusk-crypto/hash"
+	"github.com/dusk-network/dusk-crypto/rng"
+)
+
+// MaxHeight is the height of the largest trees, 65536 one-time keys. The
+// signer keeps the whole tree in memory, and builds it in about W/2 * Len()
+// hashes per leaf
+const MaxHeight = 16
+
+var (
+	// ErrExhausted is returned when all the one-time keys of a Signer have
+	// been used
+	ErrExhausted = errors.New("wots: all the one-time keys have been used")
+	// ErrStateRollback is returned when the state of a Signer is behind its
+	// store, i.e. restoring it would reuse one-time keys
+	ErrStateRollback = errors.New("wots: signer state is behind its store")
+	// ErrNoStore is returned when creating a Signer without a StateStore
+	ErrNoStore = errors.New("wots: a signer needs a state store")
+)
+
+// StateStore persists the index of the next one-time key of a Signer. It is
+// the only thing standing between a Signer and the reuse of its keys, after
+// a crash or a restore from a backup: it must keep the values Save returned
+// nil for across restarts, and never go back.
+//
+// A state store must not be shared between signers
+type StateStore interface {
+	// Load returns the last index saved, or 0 if none was
+	Load() (uint64, error)
+	// Save durably stores next. It is called with increasing values, before
+	// any signature with a key below next is released
+	Save(next uint64) error
+}
+
+// TreePublicKey is the public key of a Signer, the root of the Merkle tree
+// of its one-time public keys
+type TreePublicKey struct {
+	Params  Params
+	Height  int
+	PubSeed [N]byte
+	Root    [N]byte
+}
+
+// TreeSignature is a signature by a Signer: the index of the one-time key,
+// its WOTS+ signature and the authentication path of its public key
+type TreeSignature struct {
+	Index     uint64
+	Signature Signature
+	Path      [][N]byte
+}
+
+// Signer signs with the 2^height one-time keys of a Merkle tree, each at
+// most once. It is safe for concurrent use
+type Signer struct {
+	mu     sync.Mutex
+	params Params
+	height int
+	seed   [N]byte
+	public TreePublicKey
+	next   uint64
+	store  StateStore
+	// levels[0] are the one-time public keys, levels[height] the root
+	levels [][][N]byte
+}
+
+// NewSigner returns a signer with 2^height one-time keys, which persists its
+// state to store. The store must be empty: it is checked to hold 0.
+// Randomness is read from r, rng.Reader is used if it is nil
+func NewSigner(p Params, height int, store StateStore, r io.Reader) (*Signer, *TreePublicKey, error) {
+	if err := p.Check(); err != nil {
+		return nil, nil, err
+	}
+	if height < 1 || height > MaxHeight {
+		return nil, nil, errors.New("wots: the height must be between 1 and " + strconv.Itoa(MaxHeight))
+	}
+	if store == nil {
+		return nil, nil, ErrNoStore
+	}
+	if next, err := store.Load(); err != nil {
+		return nil, nil, err
+	} else if next != 0 {
+		return nil, nil, ErrStateRollback
+	}
+
+	s := &Signer{params: p, height: height, store: store}
+	if _, err := io.ReadFull(rng.Or(r), s.seed[:]); err != nil {
+		return nil, nil, err
+	}
+	if _, err := io.ReadFull(rng.Or(r), s.public.PubSeed[:]); err != nil {
+		return nil, nil, err
+	}
+	s.build()
+	pk := s.public
+	return s, &pk, nil
+}
+
+// build computes the tree and the public key
+func (s *Signer) build() {
+	s.levels = make([][][N]byte, s.height+1)
+	leaves := make([][N]byte, 1<<uint(s.height))
+	for i := range leaves {
+		leaves[i] = keyGen(s.params, &s.seed, &s.public.PubSeed, uint64(i))
+	}
+	s.levels[0] = leaves
+	for l := 1; l <= s.height; l++ {
+		below := s.levels[l-1]
+		level := make([][N]byte, len(below)/2)
+		for i := range level {
+			level[i] = node(&s.public.PubSeed, l, uint64(i), below[2*i], below[2*i+1])
+		}
+		s.levels[l] = level
+	}
+	s.public.Params = s.params
+	s.public.Height = s.height
+	s.public.Root = s.levels[s.height][0]
+}
+
+// node hashes the children of the node at index i of level l
+func node(pubSeed *[N]byte, l int, i uint64, left, right [N]byte) [N]byte {
+	a := address{typ: addrNode, level: uint32(l), node: i}
+	return tweak(pubSeed, &a, left[:], right[:])
+}
+
+// PublicKey returns the public key of the signer
+func (s *Signer) PublicKey() *TreePublicKey {
+	pk := s.public
+	return &pk
+}
+
+// Remaining returns the number of signatures left
+func (s *Signer) Remaining() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return uint64(1)<<uint(s.height) - s.next
+}
+
+// messageDigest binds the message to the public key and the index of the
+// one-time key signing it
+func (pk *TreePublicKey) messageDigest(index uint64, msg []byte) []byte {
+	var idx [8]byte
+	binary.BigEndian.PutUint64(idx[:], index)
+	return hash.Sha3256WithDomain("dusk.wots.tree.message", pk.PubSeed[:], pk.Root[:], idx[:], msg)
+}
+
+// Sign signs msg with the next one-time key. The key is marked used in the
+// store before the signature is computed: if Save fails, no signature is
+// returned and the key is skipped, since it is unknown whether the store
+// holds it
+func (s *Signer) Sign(msg []byte) (*TreeSignature, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.next >= uint64(1)<<uint(s.height) {
+		return nil, ErrExhausted
+	}
+	index := s.next
+	s.next++
+	if err := s.store.Save(s.next); err != nil {
+		return nil, err
+	}
+
+	sig := &TreeSignature{
+		Index:     index,
+		Signature: sign(s.params, &s.seed, &s.public.PubSeed, index, s.public.messageDigest(index, msg)),
+		Path:      make([][N]byte, s.height),
+	}
+	for l := range sig.Path {
+		sig.Path[l] = s.levels[l][(index>>uint(l))^1]
+	}
+	return sig, nil
+}
+
+// Verify checks a signature of msg
+func (pk *TreePublicKey) Verify(msg []byte, sig *TreeSignature) bool {
+	if pk.Params.Check() != nil || pk.Height < 1 || pk.Height > MaxHeight {
+		return false
+	}
+	if sig == nil || sig.Index >= uint64(1)<<uint(pk.Height) ||
+		len(sig.Signature) != pk.Params.Len() || len(sig.Path) != pk.Height {
+		return false
+	}
+
+	cur := publicFromSignature(pk.Params, &pk.PubSeed, sig.Index, pk.messageDigest(sig.Index, msg), sig.Signature)
+	for l, sibling := range sig.Path {
+		i := sig.Index >> uint(l)
+		if i&1 == 0 {
+			cur = node(&pk.PubSeed, l+1, i>>1, cur, sibling)
+		} else {
+			cur = node(&pk.PubSeed, l+1, i>>1, sibling, cur)
+		}
+	}
+	return cur == pk.Root
+}
+
+// stateSize is the size of the secret state of a signer: W, the height, the
+// next index and the seeds
+const stateSize = 2 + 1 + 8 + 2*N
+
+// MarshalState returns the secret state of the signer, to back it up. The
+// index in the state is only a lower bound: restoring it checks the store,
+// which remains authoritative
+func (s *Signer) MarshalState() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := make([]byte, stateSize)
+	binary.BigEndian.PutUint16(b[0:2], uint16(s.params.W))
+	b[2] = byte(s.height)
+	binary.BigEndian.PutUint64(b[3:11], s.next)
+	copy(b[11:11+N], s.seed[:])
+	copy(b[11+N:], s.public.PubSeed[:])
+	return b
+}
+
+// RestoreSigner restores a signer from its state and its store. The signer
+// resumes at the greater of the indices of the state and of the store, so a
+// stale backup never reuses the keys the store knows were used. A store
+// behind the state returns ErrStateRollback: it lost writes, and keys it
+// does not know about may have been used since
+func RestoreSigner(state []byte, store StateStore) (*Signer, error) {
+	if len(state) != stateSize {
+		return nil, errors.New("wots: invalid signer state length")
+	}
+	if store == nil {
+		return nil, ErrNoStore
+	}
+	s := &Signer{
+		params: Params{W: int(binary.BigEndian.Uint16(state[0:2]))},
+		height: int(state[2]),
+		next:   binary.BigEndian.Uint64(state[3:11]),
+		store:  store,
+	}
+	if err := s.params.Check(); err != nil {
+		return nil, err
+	}
+	if s.height < 1 || s.height > MaxHeight || s.next > uint64(1)<<uint(s.height) {
+		return nil, errors.New("wots: invalid signer state")
+	}
+
+	stored, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if stored < s.next {
+		return nil, ErrStateRollback
+	}
+	s.next = stored
+
+	copy(s.seed[:], state[11:11+N])
+	copy(s.public.PubSeed[:], state[11+N:])
+	s.build()
+	return s, nil
+}
+
+// Encode a TreePublicKey as W, the height, the public seed and the root
+func (pk *TreePublicKey) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, uint16(pk.Params.W)); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{byte(pk.Height)}); err != nil {
+		return err
+	}
+	if _, err := w.Write(pk.PubSeed[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(pk.Root[:])
+	return err
+}
+
+// Decode a TreePublicKey
+func (pk *TreePublicKey) Decode(r io.Reader) error {
+	if pk == nil {
+		return errors.New("struct is nil")
+	}
+	var w uint16
+	if err := binary.Read(r, binary.BigEndian, &w); err != nil {
+		return err
+	}
+	var height [1]byte
+	if _, err := io.ReadFull(r, height[:]); err != nil {
+		return err
+	}
+	pk.Params = Params{W: int(w)}
+	if err := pk.Params.Check(); err != nil {
+		return err
+	}
+	pk.Height = int(height[0])
+	if pk.Height < 1 || pk.Height > MaxHeight {
+		return errors.New("wots: invalid height")
+	}
+	if _, err := io.ReadFull(r, pk.PubSeed[:]); err != nil {
+		return err
+	}
+	_, err := io.ReadFull(r, pk.Root[:])
+	return err
+}
+
+// Encode a TreeSignature as the index, the WOTS+ signature and the
+// authentication path
+func (sig *TreeSignature) Encode(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, sig.Index); err != nil {
+		return err
+	}
+	if err := sig.Signature.Encode(w); err != nil {
+		return err
+	}
+	for i := range sig.Path {
+		if _, err := w.Write(sig.Path[i][:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode a TreeSignature of the public key pk
+func (sig *TreeSignature) Decode(pk *TreePublicKey, r io.Reader) error {
+	if sig == nil {
+		return errors.New("struct is nil")
+	}
+	if pk.Height < 1 || pk.Height > MaxHeight {
+		return errors.New("wots: invalid height")
+	}
+	if err := binary.Read(r, binary.BigEndian, &sig.Index); err != nil {
+		return err
+	}
+	s, err := DecodeSignature(pk.Params, r)
+	if err != nil {
+		return err
+	}
+	sig.Signature = s
+	sig.Path = make([][N]byte, pk.Height)
+	for i := range sig.Path {
+		if _, err := io.ReadFull(r, sig.Path[i][:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FileStore is a StateStore keeping the index in a file, which it replaces
+// atomically and syncs on every Save
+type FileStore struct {
+	path string
+}
+
+// NewFileStore returns a store keeping the index in the file at path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path}
+}
+
+// Load returns the index in the file, or 0 if it does not exist
+func (f *FileStore) Load() (uint64, error) {
+	b, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+}
+
+// Save replaces the index in the file. Going back is refused
+func (f *FileStore) Save(next uint64) error {
+	cur, err := f.Load()
+	if err != nil {
+		return err
+	}
+	if next < cur {
+		return ErrStateRollback
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(strconv.FormatUint(next, 10) + "\n"); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return err
+	}
+	// persist the rename, where directories can be synced
+	if dir, err := os.Open(filepath.Dir(f.path)); err == nil {
+		_ = dir.Sync()
+		_ = dir.Close()
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package wots
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	next uint64
+	fail bool
+}
+
+func (m *memoryStore) Load() (uint64, error) {
+	return m.next, nil
+}
+
+func (m *memoryStore) Save(next uint64) error {
+	if m.fail {
+		return errors.New("disk full")
+	}
+	m.next = next
+	return nil
+}
+
+func TestTreeSignVerify(t *testing.T) {
+	const height = 3
+	store := &memoryStore{}
+	s, pk, err := NewSigner(Params{16}, height, store, nil)
+	require.Nil(t, err)
+	assert.Equal(t, uint64(1<<height), s.Remaining())
+
+	msg := []byte("rotate to key 2")
+	for i := uint64(0); i < 1<<height; i++ {
+		sig, err := s.Sign(msg)
+		require.Nil(t, err)
+		assert.Equal(t, i, sig.Index)
+		assert.Equal(t, i+1, store.next)
+		assert.True(t, pk.Verify(msg, sig))
+		assert.False(t, pk.Verify([]byte("rotate to key 3"), sig))
+
+		// the signature is bound to its index
+		sig.Index ^= 1
+		assert.False(t, pk.Verify(msg, sig))
+	}
+
+	_, err = s.Sign(msg)
+	assert.Equal(t, ErrExhausted, err)
+	assert.Equal(t, uint64(0), s.Remaining())
+}
+
+func TestTreeStoreFailure(t *testing.T) {
+	store := &memoryStore{}
+	s, _, err := NewSigner(Params{16}, 2, store, nil)
+	require.Nil(t, err)
+
+	// no signature is released unless the store saved the index
+	store.fail = true
+	sig, err := s.Sign([]byte("msg"))
+	assert.NotNil(t, err)
+	assert.Nil(t, sig)
+
+	// and the key is never used
+	store.fail = false
+	sig, err = s.Sign([]byte("msg"))
+	require.Nil(t, err)
+	assert.Equal(t, uint64(1), sig.Index)
+}
+
+func TestTreeGuards(t *testing.T) {
+	_, _, err := NewSigner(Params{16}, 2, nil, nil)
+	assert.Equal(t, ErrNoStore, err)
+	_, _, err = NewSigner(Params{16}, 0, &memoryStore{}, nil)
+	assert.NotNil(t, err)
+	_, _, err = NewSigner(Params{16}, MaxHeight+1, &memoryStore{}, nil)
+	assert.NotNil(t, err)
+	_, _, err = NewSigner(Params{5}, 2, &memoryStore{}, nil)
+	assert.Equal(t, ErrInvalidParams, err)
+
+	// a used store is not reused for a new key
+	_, _, err = NewSigner(Params{16}, 2, &memoryStore{next: 1}, nil)
+	assert.Equal(t, ErrStateRollback, err)
+}
+
+func TestRestoreSigner(t *testing.T) {
+	store := &memoryStore{}
+	s, pk, err := NewSigner(Params{4}, 3, store, nil)
+	require.Nil(t, err)
+	backup := s.MarshalState()
+	for i := 0; i < 3; i++ {
+		_, err = s.Sign([]byte("msg"))
+		require.Nil(t, err)
+	}
+
+	// a stale backup resumes where the store is
+	restored, err := RestoreSigner(backup, store)
+	require.Nil(t, err)
+	assert.Equal(t, *pk, *restored.PublicKey())
+	sig, err := restored.Sign([]byte("msg"))
+	require.Nil(t, err)
+	assert.Equal(t, uint64(3), sig.Index)
+	assert.True(t, pk.Verify([]byte("msg"), sig))
+
+	// a store behind the state lost writes
+	_, err = RestoreSigner(restored.MarshalState(), &memoryStore{next: 2})
+	assert.Equal(t, ErrStateRollback, err)
+
+	_, err = RestoreSigner(backup[1:], store)
+	assert.NotNil(t, err)
+	_, err = RestoreSigner(backup, nil)
+	assert.Equal(t, ErrNoStore, err)
+}
+
+func TestTreeSignatureEncode(t *testing.T) {
+	s, pk, err := NewSigner(Params{256}, 2, &memoryStore{}, nil)
+	require.Nil(t, err)
+	msg := []byte("msg")
+	sig, err := s.Sign(msg)
+	require.Nil(t, err)
+
+	buf := new(bytes.Buffer)
+	require.Nil(t, sig.Encode(buf))
+	b := buf.Bytes()
+
+	pkBuf := new(bytes.Buffer)
+	require.Nil(t, pk.Encode(pkBuf))
+	decPk := &TreePublicKey{}
+	require.Nil(t, decPk.Decode(pkBuf))
+	assert.Equal(t, pk, decPk)
+
+	dec := &TreeSignature{}
+	require.Nil(t, dec.Decode(pk, bytes.NewReader(b)))
+	assert.Equal(t, sig, dec)
+	assert.True(t, pk.Verify(msg, dec))
+
+	assert.NotNil(t, dec.Decode(pk, bytes.NewReader(b[:len(b)-1])))
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wots")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	f := NewFileStore(filepath.Join(dir, "state"))
+	next, err := f.Load()
+	require.Nil(t, err)
+	assert.Equal(t, uint64(0), next)
+
+	s, pk, err := NewSigner(Params{16}, 2, f, nil)
+	require.Nil(t, err)
+	_, err = s.Sign([]byte("msg"))
+	require.Nil(t, err)
+
+	next, err = NewFileStore(filepath.Join(dir, "state")).Load()
+	require.Nil(t, err)
+	assert.Equal(t, uint64(1), next)
+	assert.Equal(t, ErrStateRollback, f.Save(0))
+
+	restored, err := RestoreSigner(s.MarshalState(), f)
+	require.Nil(t, err)
+	sig, err := restored.Sign([]byte("msg"))
+	require.Nil(t, err)
+	assert.Equal(t, uint64(1), sig.Index)
+	assert.True(t, pk.Verify([]byte("msg"), sig))
+}